@@ -17,10 +17,14 @@ import (
 	"github.com/nurdsoft/nurd-commerce-core/internal/cart/cartclient"
 	"github.com/nurdsoft/nurd-commerce-core/internal/customer"
 	"github.com/nurdsoft/nurd-commerce-core/internal/customer/customerclient"
+	fakepaymentModule "github.com/nurdsoft/nurd-commerce-core/internal/fakepayment"
 	"github.com/nurdsoft/nurd-commerce-core/internal/orders"
 	"github.com/nurdsoft/nurd-commerce-core/internal/orders/ordersclient"
+	paymentModule "github.com/nurdsoft/nurd-commerce-core/internal/payment"
 	"github.com/nurdsoft/nurd-commerce-core/internal/product"
 	"github.com/nurdsoft/nurd-commerce-core/internal/product/productclient"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/promotionsclient"
 	stripeModule "github.com/nurdsoft/nurd-commerce-core/internal/stripe"
 	"github.com/nurdsoft/nurd-commerce-core/internal/swagger"
 	"github.com/nurdsoft/nurd-commerce-core/internal/transport"
@@ -68,9 +72,14 @@ var apiCommand = &cobra.Command{
 			wishlistclient.ModuleClient,
 			address.ModuleHttpAPI,
 			addressclient.ModuleClient,
+			promotions.ModuleHttpAPI,
+			promotionsclient.ModuleClient,
 			cart.ModuleHttpAPI,
 			cartclient.ModuleClient,
 			orders.ModuleHttpAPI,
+			orders.ModuleService,
+			orders.ModuleOutboxDispatcher,
+			orders.ModulePendingOrderSweeper,
 			ordersclient.ModuleClient,
 			webhook.Module,
 			inventory.Module,
@@ -78,6 +87,8 @@ var apiCommand = &cobra.Command{
 			swagger.ModuleServeSwagger,
 			stripeModule.ModuleHttpAPI,
 			authorizenetModule.ModuleHttpAPI,
+			fakepaymentModule.ModuleWebhooks,
+			paymentModule.ModuleHttpAPI,
 			fx.NopLogger,
 			fx.StartTimeout(time.Second*60),
 		)
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nurdsoft/nurd-commerce-core/config"
+	"github.com/nurdsoft/nurd-commerce-core/internal/address/addressclient"
+	"github.com/nurdsoft/nurd-commerce-core/internal/cart/cartclient"
+	"github.com/nurdsoft/nurd-commerce-core/internal/customer/customerclient"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/ordersclient"
+	"github.com/nurdsoft/nurd-commerce-core/internal/product/productclient"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/promotionsclient"
+	"github.com/nurdsoft/nurd-commerce-core/internal/transport"
+	"github.com/nurdsoft/nurd-commerce-core/internal/webhook"
+	"github.com/nurdsoft/nurd-commerce-core/internal/wishlist/wishlistclient"
+	"github.com/nurdsoft/nurd-commerce-core/shared/db"
+	"github.com/nurdsoft/nurd-commerce-core/shared/log"
+	httpTransport "github.com/nurdsoft/nurd-commerce-core/shared/transport/http"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory/salesforce"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/authorizenet"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/taxes"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+)
+
+const reconcileDateLayout = "2006-01-02"
+
+var (
+	reconcileFrom   string
+	reconcileTo     string
+	reconcileFormat string
+	reconcileOutput string
+	reconcileFix    bool
+)
+
+var reconcileCommand = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Reconcile orders with the payment provider",
+	Long: "Compares the orders created in the date range with the payments and refunds at the payment provider " +
+		"and reports the mismatches in totals, statuses and refund totals. With --fix the orders whose payment " +
+		"or refund webhooks were missed are settled, every other mismatch is left for someone to look at.",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := newReconcileOrdersRequest(time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		writeReport, err := reportWriter(reconcileFormat)
+		if err != nil {
+			return err
+		}
+
+		var ordersClient ordersclient.Client
+		app := fx.New(
+			fx.Provide(config.New(cfgFile, version)),
+			db.Module,
+			httpTransport.ModuleWithoutLifecycle,
+			transport.ModuleAPI,
+			shipping.Module,
+			stripe.Module,
+			authorizenet.Module,
+			payment.Module,
+			taxes.Module,
+			log.Module,
+			customerclient.ModuleClient,
+			productclient.ModuleClient,
+			wishlistclient.ModuleClient,
+			addressclient.ModuleClient,
+			promotionsclient.ModuleClient,
+			cartclient.ModuleClient,
+			ordersclient.ModuleClient,
+			webhook.Module,
+			inventory.Module,
+			salesforce.Module,
+			fx.NopLogger,
+			fx.Populate(&ordersClient),
+		)
+		if err := app.Err(); err != nil {
+			return err
+		}
+
+		report, err := ordersClient.ReconcileOrders(cmd.Context(), req)
+		if err != nil {
+			return errors.Wrap(err, "failed to reconcile orders")
+		}
+
+		out := cmd.OutOrStdout()
+		if reconcileOutput != "" {
+			file, err := os.Create(reconcileOutput)
+			if err != nil {
+				return errors.Wrap(err, "failed to create report file")
+			}
+			defer file.Close()
+			out = file
+		}
+
+		return writeReport(out, report)
+	},
+}
+
+// newReconcileOrdersRequest covers the from and to days, both included, the last 30 days by default.
+func newReconcileOrdersRequest(now time.Time) (*entities.ReconcileOrdersRequest, error) {
+	today := now.Truncate(24 * time.Hour)
+	from, to := today.AddDate(0, 0, -30), today
+
+	var err error
+	if reconcileFrom != "" {
+		if from, err = time.Parse(reconcileDateLayout, reconcileFrom); err != nil {
+			return nil, errors.Wrap(err, "invalid --from date")
+		}
+	}
+	if reconcileTo != "" {
+		if to, err = time.Parse(reconcileDateLayout, reconcileTo); err != nil {
+			return nil, errors.Wrap(err, "invalid --to date")
+		}
+	}
+
+	if to.Before(from) {
+		return nil, errors.New("--to can't be before --from")
+	}
+
+	return &entities.ReconcileOrdersRequest{
+		From: from,
+		To:   to.AddDate(0, 0, 1),
+		Fix:  reconcileFix,
+	}, nil
+}
+
+func reportWriter(format string) (func(io.Writer, *entities.ReconciliationReport) error, error) {
+	switch format {
+	case "text":
+		return writeTextReport, nil
+	case "csv":
+		return writeCSVReport, nil
+	case "json":
+		return writeJSONReport, nil
+	default:
+		return nil, errors.Errorf("unknown report format: %s", format)
+	}
+}
+
+func writeTextReport(w io.Writer, report *entities.ReconciliationReport) error {
+	fmt.Fprintf(w, "Orders created from %s to %s: %d checked, %d mismatches\n\n", report.From.Format(reconcileDateLayout),
+		report.To.AddDate(0, 0, -1).Format(reconcileDateLayout), report.OrdersChecked, len(report.Mismatches))
+
+	if len(report.Mismatches) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ORDER\tFIELD\tREFUND\tORDER VALUE\tPROVIDER VALUE\tFIXABLE\tFIXED\tERROR")
+	for _, m := range report.Mismatches {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%t\t%t\t%s\n", m.OrderReference, m.Field, m.RefundID,
+			m.OrderValue, m.ProviderValue, m.Fixable, m.Fixed, m.Error)
+	}
+
+	return tw.Flush()
+}
+
+func writeCSVReport(w io.Writer, report *entities.ReconciliationReport) error {
+	cw := csv.NewWriter(w)
+	records := [][]string{{"order_id", "order_reference", "field", "refund_id", "order_value", "provider_value", "fixable", "fixed", "error"}}
+	for _, m := range report.Mismatches {
+		records = append(records, []string{m.OrderID.String(), m.OrderReference, m.Field.String(), m.RefundID,
+			m.OrderValue, m.ProviderValue, strconv.FormatBool(m.Fixable), strconv.FormatBool(m.Fixed), m.Error})
+	}
+
+	return cw.WriteAll(records)
+}
+
+func writeJSONReport(w io.Writer, report *entities.ReconciliationReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
+
+func init() {
+	reconcileCommand.Flags().StringVar(&reconcileFrom, "from", "", "first day of the orders to reconcile, YYYY-MM-DD (default 30 days ago)")
+	reconcileCommand.Flags().StringVar(&reconcileTo, "to", "", "last day of the orders to reconcile, YYYY-MM-DD (default today)")
+	reconcileCommand.Flags().StringVar(&reconcileFormat, "format", "text", "report format: text, csv or json")
+	reconcileCommand.Flags().StringVar(&reconcileOutput, "output", "", "file to write the report to (default stdout)")
+	reconcileCommand.Flags().BoolVar(&reconcileFix, "fix", false, "settle the orders and refunds whose webhooks were missed")
+
+	rootCmd.AddCommand(reconcileCommand)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping"
 
+	orders "github.com/nurdsoft/nurd-commerce-core/internal/orders/config"
 	svcTransport "github.com/nurdsoft/nurd-commerce-core/internal/transport"
 	webhook "github.com/nurdsoft/nurd-commerce-core/internal/webhook/config"
 	"github.com/nurdsoft/nurd-commerce-core/shared/cfg"
@@ -35,6 +36,7 @@ type Config struct {
 	Shipping                  shipping.Config
 	Taxes                     taxes.Config
 	Webhook                   webhook.Config
+	Orders                    orders.Config
 }
 
 // Validate config
@@ -49,6 +51,7 @@ func (c *Config) Validate() error {
 		&c.Shipping,
 		&c.Taxes,
 		&c.Webhook,
+		&c.Orders,
 	}
 
 	if err := cfg.ValidateConfigs(validatables...); err != nil {
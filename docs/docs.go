@@ -14,12 +14,17 @@
 //
 //	 Security:
 //	 - ApiKeyAuth: []
+//	 - CartTokenAuth: []
 //
 //	SecurityDefinitions:
 //	  ApiKeyAuth:
 //	    type: apiKey
 //	    in: header
 //	    name: x-customer-id
+//	  CartTokenAuth:
+//	    type: apiKey
+//	    in: header
+//	    name: x-cart-token
 //
 // swagger:meta
 package docs
@@ -9,16 +9,20 @@ import (
 )
 
 type Endpoints struct {
-	GetPaymentProfilesEndpoint   endpoint.Endpoint
-	CreatePaymentProfileEndpoint endpoint.Endpoint
-	WebhookEndpoint              endpoint.Endpoint
+	GetPaymentProfilesEndpoint       endpoint.Endpoint
+	CreatePaymentProfileEndpoint     endpoint.Endpoint
+	DeletePaymentProfileEndpoint     endpoint.Endpoint
+	SetDefaultPaymentProfileEndpoint endpoint.Endpoint
+	WebhookEndpoint                  endpoint.Endpoint
 }
 
 func New(svc service.Service) *Endpoints {
 	return &Endpoints{
-		GetPaymentProfilesEndpoint:   makeGetPaymentProfiles(svc),
-		CreatePaymentProfileEndpoint: makeCreatePaymentProfile(svc),
-		WebhookEndpoint:              makeWebhookEndpoint(svc),
+		GetPaymentProfilesEndpoint:       makeGetPaymentProfiles(svc),
+		CreatePaymentProfileEndpoint:     makeCreatePaymentProfile(svc),
+		DeletePaymentProfileEndpoint:     makeDeletePaymentProfile(svc),
+		SetDefaultPaymentProfileEndpoint: makeSetDefaultPaymentProfile(svc),
+		WebhookEndpoint:                  makeWebhookEndpoint(svc),
 	}
 }
 
@@ -35,6 +39,20 @@ func makeCreatePaymentProfile(svc service.Service) endpoint.Endpoint {
 	}
 }
 
+func makeDeletePaymentProfile(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(entities.DeletePaymentProfileRequest)
+		return nil, svc.DeletePaymentProfile(ctx, req)
+	}
+}
+
+func makeSetDefaultPaymentProfile(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(entities.SetDefaultPaymentProfileRequest)
+		return svc.SetDefaultPaymentProfile(ctx, req)
+	}
+}
+
 func makeWebhookEndpoint(svc service.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(entities.WebhookRequestBody)
@@ -17,6 +17,24 @@ type CreatePaymentProfileRequest struct {
 	Body CreatePaymentProfileRequestBody `json:"body"`
 }
 
+// swagger:parameters authorizenet DeletePaymentProfileRequest
+type DeletePaymentProfileRequest struct {
+	// Payment profile ID to be deleted
+	//
+	// in:path
+	// required: true
+	PaymentProfileID string `json:"payment_profile_id"`
+}
+
+// swagger:parameters authorizenet SetDefaultPaymentProfileRequest
+type SetDefaultPaymentProfileRequest struct {
+	// Payment profile ID to be used by default
+	//
+	// in:path
+	// required: true
+	PaymentProfileID string `json:"payment_profile_id"`
+}
+
 // swagger:parameters authorizenet WebhookRequest
 // WebhookRequestBody wraps the request body for webhook events
 // required: true
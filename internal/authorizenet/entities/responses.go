@@ -11,6 +11,7 @@ type PaymentProfile struct {
 	CardNumber     string `json:"card_number"`
 	CardType       string `json:"card_type"`
 	ExpirationDate string `json:"expiration_date"`
+	Default        bool   `json:"default"`
 }
 
 // swagger:model CreatePaymentProfileResponse
@@ -18,3 +19,8 @@ type CreatePaymentProfileResponse struct {
 	ProfileID        string `json:"profile_id"`
 	PaymentProfileID string `json:"payment_profile_id"`
 }
+
+// swagger:model SetDefaultPaymentProfileResponse
+type SetDefaultPaymentProfileResponse struct {
+	PaymentProfile PaymentProfile `json:"payment_profile"`
+}
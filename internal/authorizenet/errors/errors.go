@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"net/http"
+
+	"github.com/nurdsoft/nurd-commerce-core/shared/errors"
+)
+
+// Module-specific errors
+var moduleErrors = map[string]struct {
+	StatusCode int
+	Message    string
+}{
+	"AUTHORIZENET_SIGNATURE_VERIFICATION_FAILED": {StatusCode: http.StatusBadRequest, Message: "Authorize.net webhook signature verification failed"},
+	"AUTHORIZENET_PAYMENT_PROFILE_NOT_FOUND":     {StatusCode: http.StatusNotFound, Message: "Payment profile not found"},
+	"AUTHORIZENET_EVENT_PROCESSING_FAILED":       {StatusCode: http.StatusInternalServerError, Message: "Authorize.net event could not be processed"},
+}
+
+func NewAPIError(errorCode string, customMessage ...string) *errors.APIError {
+	if err, exists := moduleErrors[errorCode]; exists {
+		message := err.Message
+		if len(customMessage) > 0 {
+			message = customMessage[0]
+		}
+
+		return &errors.APIError{
+			ErrorCode:  errorCode, // Set dynamically
+			StatusCode: err.StatusCode,
+			Message:    message,
+		}
+	}
+
+	// Fallback to global/common errors
+	return errors.NewAPIError(errorCode, customMessage...)
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/nurdsoft/nurd-commerce-core/internal/authorizenet/entities"
+	authorizenetErrors "github.com/nurdsoft/nurd-commerce-core/internal/authorizenet/errors"
 	"github.com/nurdsoft/nurd-commerce-core/internal/customer/customerclient"
 	"github.com/nurdsoft/nurd-commerce-core/internal/orders/ordersclient"
 	"github.com/nurdsoft/nurd-commerce-core/shared/cfg"
@@ -12,12 +13,23 @@ import (
 	sharedMeta "github.com/nurdsoft/nurd-commerce-core/shared/meta"
 	authorizenetClient "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/authorizenet/client"
 	authorizenetEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/authorizenet/entities"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
 
+// Response codes of the transaction in the webhook payload
+const (
+	responseCodeApproved = 1
+	responseCodeDeclined = 2
+	responseCodeError    = 3
+)
+
 type Service interface {
 	GetPaymentProfiles(ctx context.Context) (entities.GetPaymentProfileResponse, error)
 	CreatePaymentProfile(ctx context.Context, req entities.CreatePaymentProfileRequestBody) (entities.CreatePaymentProfileResponse, error)
+	DeletePaymentProfile(ctx context.Context, req entities.DeletePaymentProfileRequest) error
+	SetDefaultPaymentProfile(ctx context.Context, req entities.SetDefaultPaymentProfileRequest) (entities.SetDefaultPaymentProfileResponse, error)
 	HandleWebhook(ctx context.Context, req entities.WebhookRequestBody) error
 }
 
@@ -92,6 +104,7 @@ func (s *service) GetPaymentProfiles(ctx context.Context) (entities.GetPaymentPr
 			CardType:       pm.CardType,
 			CardNumber:     pm.CardNumber,
 			ExpirationDate: pm.ExpirationDate,
+			Default:        pm.Default,
 		}
 	}
 	resp := entities.GetPaymentProfileResponse{
@@ -137,6 +150,115 @@ func (s *service) CreatePaymentProfile(ctx context.Context, req entities.CreateP
 	}, nil
 }
 
+// swagger:route DELETE /authorizenet/payment-profiles/{payment_profile_id} authorizenet DeletePaymentProfileRequest
+//
+// # Delete Payment Profile
+// ### Delete a saved payment profile of the customer
+//
+// Produces:
+//   - application/json
+//
+// Responses:
+//
+//	200: DefaultResponse Payment profile deleted successfully
+//	400: DefaultError Bad Request
+//	404: DefaultError Not Found
+//	500: DefaultError Internal Server Error
+func (s *service) DeletePaymentProfile(ctx context.Context, req entities.DeletePaymentProfileRequest) error {
+	customerID := sharedMeta.XCustomerID(ctx)
+
+	if customerID == "" {
+		return moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	}
+
+	profileID, _, err := s.getPaymentProfile(ctx, customerID, req.PaymentProfileID)
+	if err != nil {
+		return err
+	}
+
+	return s.authorizeNetClient.DeleteCustomerPaymentProfile(ctx, authorizenetEntities.DeleteCustomerPaymentProfileRequest{
+		ProfileID:        profileID,
+		PaymentProfileID: req.PaymentProfileID,
+	})
+}
+
+// swagger:route PUT /authorizenet/payment-profiles/{payment_profile_id}/default authorizenet SetDefaultPaymentProfileRequest
+//
+// # Set Default Payment Profile
+// ### Set the payment profile used by default for the customer
+//
+// Produces:
+//   - application/json
+//
+// Responses:
+//
+//	200: SetDefaultPaymentProfileResponse Default payment profile set successfully
+//	400: DefaultError Bad Request
+//	404: DefaultError Not Found
+//	500: DefaultError Internal Server Error
+func (s *service) SetDefaultPaymentProfile(ctx context.Context, req entities.SetDefaultPaymentProfileRequest) (entities.SetDefaultPaymentProfileResponse, error) {
+	customerID := sharedMeta.XCustomerID(ctx)
+
+	if customerID == "" {
+		return entities.SetDefaultPaymentProfileResponse{}, moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	}
+
+	profileID, paymentProfile, err := s.getPaymentProfile(ctx, customerID, req.PaymentProfileID)
+	if err != nil {
+		return entities.SetDefaultPaymentProfileResponse{}, err
+	}
+
+	// Authorize.net replaces the card of the profile on update, sending back the masked card keeps it unchanged
+	err = s.authorizeNetClient.UpdateCustomerPaymentProfile(ctx, authorizenetEntities.UpdateCustomerPaymentProfileRequest{
+		ProfileID:        profileID,
+		PaymentProfileID: paymentProfile.ID,
+		CardNumber:       paymentProfile.CardNumber,
+		ExpirationDate:   paymentProfile.ExpirationDate,
+		Default:          true,
+	})
+	if err != nil {
+		return entities.SetDefaultPaymentProfileResponse{}, err
+	}
+
+	return entities.SetDefaultPaymentProfileResponse{
+		PaymentProfile: entities.PaymentProfile{
+			ID:             paymentProfile.ID,
+			CardType:       paymentProfile.CardType,
+			CardNumber:     paymentProfile.CardNumber,
+			ExpirationDate: paymentProfile.ExpirationDate,
+			Default:        true,
+		},
+	}, nil
+}
+
+// getPaymentProfile returns the Authorize.net profile ID of the customer and their payment profile,
+// a payment profile of another customer is reported as not found
+func (s *service) getPaymentProfile(ctx context.Context, customerID, paymentProfileID string) (string, authorizenetEntities.PaymentProfile, error) {
+	customer, err := s.customerClient.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		return "", authorizenetEntities.PaymentProfile{}, err
+	}
+
+	if customer.AuthorizeNetID == nil {
+		return "", authorizenetEntities.PaymentProfile{}, authorizenetErrors.NewAPIError("AUTHORIZENET_PAYMENT_PROFILE_NOT_FOUND")
+	}
+
+	result, err := s.authorizeNetClient.GetCustomerPaymentMethods(ctx, authorizenetEntities.GetPaymentProfilesRequest{
+		ProfileID: *customer.AuthorizeNetID,
+	})
+	if err != nil {
+		return "", authorizenetEntities.PaymentProfile{}, err
+	}
+
+	for _, pm := range result.PaymentProfiles {
+		if pm.ID == paymentProfileID {
+			return *customer.AuthorizeNetID, pm, nil
+		}
+	}
+
+	return "", authorizenetEntities.PaymentProfile{}, authorizenetErrors.NewAPIError("AUTHORIZENET_PAYMENT_PROFILE_NOT_FOUND")
+}
+
 // Helper function to get the customer's stripe id or create it if it doesn't exist
 // Returns the stripe id, a boolean indicating if the stripe id was created and an error
 func (s *service) getProfileID(ctx context.Context, customerID string) (string, bool, error) {
@@ -188,25 +310,46 @@ func (s *service) getProfileID(ctx context.Context, customerID string) (string,
 //	400: DefaultError Bad Request
 //	500: DefaultError Internal Server Error
 func (s *service) HandleWebhook(ctx context.Context, req entities.WebhookRequestBody) error {
+	// keep track of the notification that triggered the order changes
+	ctx = sharedMeta.WithSourceEventID(ctx, req.NotificationID)
+
+	var err error
 	switch req.EventType {
 	case "net.authorize.payment.fraud.approved":
 		s.log.Info("Payment fraud approved ", "transaction_id ", req.Payload.ID, "fraud_action", req.Payload.FraudList)
-		err := s.ordersClient.ProcessPaymentSucceeded(ctx, req.Payload.ID)
-		if err != nil {
-			s.log.Errorf("Error processing payment succeeded: %v", err)
-			return nil
-		}
+		err = s.ordersClient.ProcessPaymentSucceeded(ctx, providers.ProviderAuthorizeNet, req.Payload.ID)
 	case "net.authorize.payment.fraud.declined":
 		s.log.Info("Payment fraud declined ", "transaction_id", req.Payload.ID, "fraud_action", req.Payload.FraudList)
-		err := s.ordersClient.ProcessPaymentFailed(ctx, req.Payload.ID)
-		if err != nil {
-			s.log.Errorf("Error processing payment failed: %v", err)
-			return nil
+		err = s.ordersClient.ProcessPaymentFailed(ctx, providers.ProviderAuthorizeNet, req.Payload.ID)
+	case "net.authorize.payment.authcapture.created":
+		s.log.Info("Payment authorized and captured ", "transaction_id ", req.Payload.ID, " response_code ", req.Payload.ResponseCode)
+		switch req.Payload.ResponseCode {
+		case responseCodeApproved:
+			err = s.ordersClient.ProcessPaymentSucceeded(ctx, providers.ProviderAuthorizeNet, req.Payload.ID)
+		case responseCodeDeclined, responseCodeError:
+			err = s.ordersClient.ProcessPaymentFailed(ctx, providers.ProviderAuthorizeNet, req.Payload.ID)
+		default:
+			// held for review, the fraud approved or declined event settles the payment
+			s.log.Infof("Payment %s is held for review", req.Payload.ID)
 		}
+	// voids are used to refund transactions that haven't settled yet, the voided transaction keeps its ID
+	case "net.authorize.payment.refund.created", "net.authorize.payment.void.created":
+		s.log.Info("Refund created ", "event_type ", req.EventType, " transaction_id ", req.Payload.ID)
+		err = s.ordersClient.ProcessRefundSucceeded(ctx, req.Payload.ID, decimal.NewFromFloat(req.Payload.AuthAmount))
 	default:
 		s.log.Warnf("Unhandled event type: %s", req.EventType)
+	}
+
+	if err == nil {
 		return nil
 	}
 
+	s.log.Errorf("Error processing Authorize.net notification %s (%s): %v", req.NotificationID, req.EventType, err)
+	if moduleErrors.IsTransientError(err) {
+		// a 5xx makes Authorize.net deliver the notification again later
+		return authorizenetErrors.NewAPIError("AUTHORIZENET_EVENT_PROCESSING_FAILED")
+	}
+
+	// the other failures won't go away on a redelivery, e.g. transactions that weren't made by this service
 	return nil
 }
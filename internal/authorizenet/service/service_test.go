@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/nurdsoft/nurd-commerce-core/internal/authorizenet/entities"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/ordersclient"
+	appErrors "github.com/nurdsoft/nurd-commerce-core/shared/errors"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+)
+
+func Test_service_HandleWebhook(t *testing.T) {
+	setup := func(t *testing.T) (*service, *ordersclient.MockClient) {
+		ctrl := gomock.NewController(t)
+		mockOrdersClient := ordersclient.NewMockClient(ctrl)
+		svc := &service{
+			log:          zap.NewExample().Sugar(),
+			ordersClient: mockOrdersClient,
+		}
+		return svc, mockOrdersClient
+	}
+
+	authCapture := func(responseCode int) entities.WebhookRequestBody {
+		return entities.WebhookRequestBody{
+			NotificationID: "n_123",
+			EventType:      "net.authorize.payment.authcapture.created",
+			Payload:        entities.Payload{ResponseCode: responseCode, ID: "60123"},
+		}
+	}
+
+	t.Run("approved payment", func(t *testing.T) {
+		svc, mockOrdersClient := setup(t)
+
+		mockOrdersClient.EXPECT().
+			ProcessPaymentSucceeded(gomock.Any(), providers.ProviderAuthorizeNet, "60123").
+			Return(nil)
+
+		assert.NoError(t, svc.HandleWebhook(context.Background(), authCapture(responseCodeApproved)))
+	})
+
+	t.Run("processing failure is reported so the notification is delivered again", func(t *testing.T) {
+		svc, mockOrdersClient := setup(t)
+
+		mockOrdersClient.EXPECT().
+			ProcessPaymentFailed(gomock.Any(), providers.ProviderAuthorizeNet, "60123").
+			Return(errors.New("database unavailable"))
+
+		err := svc.HandleWebhook(context.Background(), authCapture(responseCodeDeclined))
+
+		apiErr, ok := appErrors.IsAPIError(err)
+		assert.True(t, ok)
+		assert.Equal(t, "AUTHORIZENET_EVENT_PROCESSING_FAILED", apiErr.ErrorCode)
+		assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	})
+
+	t.Run("unknown transaction is acknowledged", func(t *testing.T) {
+		svc, mockOrdersClient := setup(t)
+
+		// the notifications cover every transaction of the merchant, not only the ones of the orders
+		mockOrdersClient.EXPECT().
+			ProcessPaymentSucceeded(gomock.Any(), providers.ProviderAuthorizeNet, "60123").
+			Return(&appErrors.APIError{ErrorCode: "ORDER_NOT_FOUND_BY_PAYMENT_ID", StatusCode: http.StatusNotFound, Message: "Order not found by payment ID."})
+
+		assert.NoError(t, svc.HandleWebhook(context.Background(), authCapture(responseCodeApproved)))
+	})
+
+	t.Run("payment held for review", func(t *testing.T) {
+		svc, _ := setup(t)
+
+		assert.NoError(t, svc.HandleWebhook(context.Background(), authCapture(4)))
+	})
+
+	t.Run("unhandled event type", func(t *testing.T) {
+		svc, _ := setup(t)
+
+		assert.NoError(t, svc.HandleWebhook(context.Background(), entities.WebhookRequestBody{EventType: "net.authorize.customer.created"}))
+	})
+}
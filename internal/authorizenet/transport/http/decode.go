@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/nurdsoft/nurd-commerce-core/internal/authorizenet/entities"
+	authorizenetErrors "github.com/nurdsoft/nurd-commerce-core/internal/authorizenet/errors"
 	moduleErrors "github.com/nurdsoft/nurd-commerce-core/shared/errors"
 	sharedMeta "github.com/nurdsoft/nurd-commerce-core/shared/meta"
 
@@ -17,6 +18,7 @@ import (
 	"encoding/hex"
 
 	goKitHTTPTransport "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
 	httpError "github.com/nurdsoft/nurd-commerce-core/shared/errors/http"
 	"github.com/pkg/errors"
 )
@@ -62,14 +64,52 @@ func decodeCreatePaymentProfileRequest(c context.Context, r *http.Request) (inte
 	return req, nil
 }
 
-// NewDecodeWebhookRequest returns a decode function with the signature key injected
+func decodeDeletePaymentProfileRequest(c context.Context, r *http.Request) (interface{}, error) {
+	if err := validateCustomerID(sharedMeta.XCustomerID(c)); err != nil {
+		return nil, err
+	}
+
+	paymentProfileID, err := paymentProfileIDFromPath(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return entities.DeletePaymentProfileRequest{PaymentProfileID: paymentProfileID}, nil
+}
+
+func decodeSetDefaultPaymentProfileRequest(c context.Context, r *http.Request) (interface{}, error) {
+	if err := validateCustomerID(sharedMeta.XCustomerID(c)); err != nil {
+		return nil, err
+	}
+
+	paymentProfileID, err := paymentProfileIDFromPath(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return entities.SetDefaultPaymentProfileRequest{PaymentProfileID: paymentProfileID}, nil
+}
+
+func paymentProfileIDFromPath(r *http.Request) (string, error) {
+	paymentProfileID := strings.TrimSpace(mux.Vars(r)["payment_profile_id"])
+	if paymentProfileID == "" {
+		return "", moduleErrors.NewAPIError("VALIDATION_ERROR", "Payment profile ID is required")
+	}
+
+	return paymentProfileID, nil
+}
+
+// NewDecodeWebhookRequest returns a decode function with the signature key injected.
+// The X-ANET-Signature header is verified against the raw body before anything is decoded.
 func NewDecodeWebhookRequest(signatureKey string) goKitHTTPTransport.DecodeRequestFunc {
 	return func(_ context.Context, r *http.Request) (interface{}, error) {
 		signature := r.Header.Get("X-Anet-Signature")
 		if signature == "" {
-			return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "missing X-Anet-Signature header")
+			return nil, authorizenetErrors.NewAPIError("AUTHORIZENET_SIGNATURE_VERIFICATION_FAILED", "missing X-Anet-Signature header")
 		}
 
+		defer r.Body.Close()
+
 		bodyBytes, err := io.ReadAll(r.Body)
 		if err != nil {
 			return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "unable to read request body")
@@ -103,15 +143,22 @@ func validateCustomerID(customerIDStr string) error {
 	return nil
 }
 
+// validateSignature checks the HMAC-SHA512 of the body computed with the signature key.
+// Authorize.net sends it as sha512=<hex>, in uppercase.
 func validateSignature(signature string, body []byte, signatureKey string) error {
+	// without a key any sender could compute a valid signature
+	if signatureKey == "" {
+		return authorizenetErrors.NewAPIError("AUTHORIZENET_SIGNATURE_VERIFICATION_FAILED", "webhook signature key is not configured")
+	}
+
 	parts := strings.SplitN(signature, "=", 2)
-	if len(parts) != 2 || parts[0] != "sha512" {
-		return moduleErrors.NewAPIError("VALIDATION_ERROR", "invalid signature format")
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "sha512") {
+		return authorizenetErrors.NewAPIError("AUTHORIZENET_SIGNATURE_VERIFICATION_FAILED", "invalid signature format")
 	}
 
 	expectedSignature, err := hex.DecodeString(parts[1])
 	if err != nil {
-		return moduleErrors.NewAPIError("VALIDATION_ERROR", "invalid signature format")
+		return authorizenetErrors.NewAPIError("AUTHORIZENET_SIGNATURE_VERIFICATION_FAILED", "invalid signature format")
 	}
 
 	h := hmac.New(sha512.New, []byte(signatureKey))
@@ -119,7 +166,7 @@ func validateSignature(signature string, body []byte, signatureKey string) error
 	computedSignature := h.Sum(nil)
 
 	if !hmac.Equal(expectedSignature, computedSignature) {
-		return moduleErrors.NewAPIError("VALIDATION_ERROR", "invalid webhook signature")
+		return authorizenetErrors.NewAPIError("AUTHORIZENET_SIGNATURE_VERIFICATION_FAILED", "invalid webhook signature")
 	}
 
 	return nil
@@ -1,11 +1,21 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha512"
 	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/nurdsoft/nurd-commerce-core/internal/authorizenet/entities"
+	appErrors "github.com/nurdsoft/nurd-commerce-core/shared/errors"
+	sharedMeta "github.com/nurdsoft/nurd-commerce-core/shared/meta"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -47,3 +57,81 @@ func TestValidateSignature(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestDecodeWebhookRequest(t *testing.T) {
+	body := []byte(`{"notificationId":"n_123","eventType":"net.authorize.payment.authcapture.created","payload":{"responseCode":1,"id":"60123"}}`)
+	key := "00112233445566778899AABBCCDDEEFF"
+
+	h := hmac.New(sha512.New, []byte(key))
+	h.Write(body)
+	// Authorize.net sends the signature in uppercase
+	signature := "sha512=" + strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+
+	newRequest := func(signature string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/authorizenet/webhook", bytes.NewReader(body))
+		if signature != "" {
+			r.Header.Set("X-ANET-Signature", signature)
+		}
+		return r
+	}
+
+	t.Run("valid signature", func(t *testing.T) {
+		req, err := NewDecodeWebhookRequest(key)(context.Background(), newRequest(signature))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "net.authorize.payment.authcapture.created", req.(entities.WebhookRequestBody).EventType)
+		assert.Equal(t, "60123", req.(entities.WebhookRequestBody).Payload.ID)
+	})
+
+	t.Run("unsigned request", func(t *testing.T) {
+		_, err := NewDecodeWebhookRequest(key)(context.Background(), newRequest(""))
+
+		assert.ErrorContains(t, err, "missing X-Anet-Signature header")
+	})
+
+	t.Run("signature mismatch", func(t *testing.T) {
+		_, err := NewDecodeWebhookRequest("another-key")(context.Background(), newRequest(signature))
+
+		apiErr, ok := appErrors.IsAPIError(err)
+		assert.True(t, ok)
+		assert.Equal(t, "AUTHORIZENET_SIGNATURE_VERIFICATION_FAILED", apiErr.ErrorCode)
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	})
+
+	t.Run("signature key not configured", func(t *testing.T) {
+		h := hmac.New(sha512.New, []byte(""))
+		h.Write(body)
+
+		_, err := NewDecodeWebhookRequest("")(context.Background(), newRequest("sha512="+hex.EncodeToString(h.Sum(nil))))
+
+		assert.ErrorContains(t, err, "webhook signature key is not configured")
+	})
+}
+
+func TestDecodeSetDefaultPaymentProfileRequest(t *testing.T) {
+	ctx := sharedMeta.WithXCustomerID(context.Background(), uuid.NewString())
+
+	newRequest := func(paymentProfileID string) *http.Request {
+		r := httptest.NewRequest(http.MethodPut, "/authorizenet/payment-profiles/"+paymentProfileID+"/default", nil)
+		return mux.SetURLVars(r, map[string]string{"payment_profile_id": paymentProfileID})
+	}
+
+	t.Run("valid request", func(t *testing.T) {
+		req, err := decodeSetDefaultPaymentProfileRequest(ctx, newRequest("pp_123"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.SetDefaultPaymentProfileRequest{PaymentProfileID: "pp_123"}, req)
+	})
+
+	t.Run("missing payment profile ID", func(t *testing.T) {
+		_, err := decodeSetDefaultPaymentProfileRequest(ctx, newRequest(""))
+
+		assert.ErrorContains(t, err, "Payment profile ID is required")
+	})
+
+	t.Run("missing customer ID", func(t *testing.T) {
+		_, err := decodeDeletePaymentProfileRequest(context.Background(), newRequest("pp_123"))
+
+		assert.Error(t, err)
+	})
+}
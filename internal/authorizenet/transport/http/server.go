@@ -18,6 +18,8 @@ func RegisterTransport(
 ) {
 	registerGetPaymentProfiles(server, ep.GetPaymentProfilesEndpoint, svcTransportClient)
 	registerCreatePaymentProfile(server, ep.CreatePaymentProfileEndpoint, svcTransportClient)
+	registerDeletePaymentProfile(server, ep.DeletePaymentProfileEndpoint, svcTransportClient)
+	registerSetDefaultPaymentProfile(server, ep.SetDefaultPaymentProfileEndpoint, svcTransportClient)
 	registerWebhook(server, ep.WebhookEndpoint, svcTransportClient, signatureKey)
 }
 
@@ -53,6 +55,38 @@ func registerCreatePaymentProfile(server *httpTransport.Server, ep goKitEndpoint
 	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
 }
 
+func registerDeletePaymentProfile(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "DELETE"
+	path := "/authorizenet/payment-profiles/{payment_profile_id}"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeDeletePaymentProfileRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
+
+func registerSetDefaultPaymentProfile(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "PUT"
+	path := "/authorizenet/payment-profiles/{payment_profile_id}/default"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeSetDefaultPaymentProfileRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
+
 func registerWebhook(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client, signatureKey string) {
 	method := "POST"
 	path := "/authorizenet/webhook"
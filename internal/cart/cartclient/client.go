@@ -12,6 +12,7 @@ type Client interface {
 	GetCartItems(ctx context.Context) (*entities.GetCartItemsResponse, error)
 	GetShippingRateByID(ctx context.Context, shippingRateID uuid.UUID) (*entities.CartShippingRate, error)
 	GetCart(ctx context.Context) (*entities.Cart, error)
+	GetCartSummary(ctx context.Context) (*entities.CartSummary, error)
 }
 
 func NewClient(svc service.Service) Client {
@@ -33,3 +34,7 @@ func (c *localClient) GetShippingRateByID(ctx context.Context, shippingRateID uu
 func (c *localClient) GetCart(ctx context.Context) (*entities.Cart, error) {
 	return c.svc.GetCart(ctx)
 }
+
+func (c *localClient) GetCartSummary(ctx context.Context) (*entities.CartSummary, error) {
+	return c.svc.GetCartSummary(ctx)
+}
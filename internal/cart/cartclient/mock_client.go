@@ -66,6 +66,21 @@ func (mr *MockClientMockRecorder) GetCartItems(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCartItems", reflect.TypeOf((*MockClient)(nil).GetCartItems), ctx)
 }
 
+// GetCartSummary mocks base method.
+func (m *MockClient) GetCartSummary(ctx context.Context) (*entities.CartSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCartSummary", ctx)
+	ret0, _ := ret[0].(*entities.CartSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCartSummary indicates an expected call of GetCartSummary.
+func (mr *MockClientMockRecorder) GetCartSummary(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCartSummary", reflect.TypeOf((*MockClient)(nil).GetCartSummary), ctx)
+}
+
 // GetShippingRateByID mocks base method.
 func (m *MockClient) GetShippingRateByID(ctx context.Context, shippingRateID uuid.UUID) (*entities.CartShippingRate, error) {
 	m.ctrl.T.Helper()
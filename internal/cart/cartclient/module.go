@@ -13,11 +13,13 @@ import (
 	"github.com/nurdsoft/nurd-commerce-core/internal/address/addressclient"
 	"github.com/nurdsoft/nurd-commerce-core/internal/cart/repository"
 	"github.com/nurdsoft/nurd-commerce-core/internal/product/productclient"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/promotionsclient"
 	svcTransport "github.com/nurdsoft/nurd-commerce-core/internal/transport"
 	httpTransport "github.com/nurdsoft/nurd-commerce-core/shared/transport/http"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory"
 	salesforce "github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory/salesforce/client"
 	shipping "github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/client"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/packaging"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/taxes"
 )
 
@@ -37,6 +39,8 @@ type ModuleParams struct {
 	AddressClient    addressclient.Client
 	SalesforceClient salesforce.Client
 	InventoryClient  inventory.Client
+	PromotionsClient promotionsclient.Client
+	Packer           packaging.Packer
 }
 
 // NewClientModule
@@ -44,7 +48,7 @@ type ModuleParams struct {
 func NewClientModule(p ModuleParams) Client {
 	repo := repository.New(p.DB, p.GormDB)
 	cacheClient := cache.NewMemoryCache()
-	svc := service.New(repo, p.Logger, p.ShippingClient, p.TaxesClient, cacheClient, p.ProductClient, p.AddressClient, p.InventoryClient, p.SalesforceClient)
+	svc := service.New(repo, p.Logger, p.ShippingClient, p.TaxesClient, cacheClient, p.ProductClient, p.AddressClient, p.InventoryClient, p.SalesforceClient, p.PromotionsClient, p.Packer)
 
 	client := NewClient(svc)
 
@@ -17,6 +17,12 @@ type Endpoints struct {
 	SetCartItemShippingRateEndpoint endpoint.Endpoint
 	GetTaxRateEndpoint              endpoint.Endpoint
 	CreateCartShippingRatesEndpoint endpoint.Endpoint
+	ApplyCouponEndpoint             endpoint.Endpoint
+	RemoveCouponEndpoint            endpoint.Endpoint
+	GetCartSummaryEndpoint          endpoint.Endpoint
+	AcceptCartPricesEndpoint        endpoint.Endpoint
+	CreateGuestCartEndpoint         endpoint.Endpoint
+	MergeGuestCartEndpoint          endpoint.Endpoint
 }
 
 func New(svc service.Service) *Endpoints {
@@ -29,6 +35,12 @@ func New(svc service.Service) *Endpoints {
 		SetCartItemShippingRateEndpoint: makeSetCartItemShippingRate(svc),
 		GetTaxRateEndpoint:              makeGetTaxRate(svc),
 		CreateCartShippingRatesEndpoint: makeCreateCartShippingRates(svc),
+		ApplyCouponEndpoint:             makeApplyCoupon(svc),
+		RemoveCouponEndpoint:            makeRemoveCoupon(svc),
+		GetCartSummaryEndpoint:          makeGetCartSummary(svc),
+		AcceptCartPricesEndpoint:        makeAcceptCartPrices(svc),
+		CreateGuestCartEndpoint:         makeCreateGuestCart(svc),
+		MergeGuestCartEndpoint:          makeMergeGuestCart(svc),
 	}
 }
 
@@ -85,3 +97,40 @@ func makeSetCartItemShippingRate(svc service.Service) endpoint.Endpoint {
 		return nil, svc.SetCartItemShippingRate(ctx, req)
 	}
 }
+
+func makeApplyCoupon(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(*entities.ApplyCouponRequest)
+		return svc.ApplyCoupon(ctx, req)
+	}
+}
+
+func makeRemoveCoupon(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return nil, svc.RemoveCoupon(ctx)
+	}
+}
+
+func makeGetCartSummary(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return svc.GetCartSummary(ctx)
+	}
+}
+
+func makeAcceptCartPrices(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return svc.AcceptCartPrices(ctx)
+	}
+}
+
+func makeCreateGuestCart(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return svc.CreateGuestCart(ctx)
+	}
+}
+
+func makeMergeGuestCart(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return svc.MergeGuestCart(ctx)
+	}
+}
@@ -14,15 +14,18 @@ const (
 	Active    CartStatus = "active"
 	Purchased CartStatus = "purchased"
 	Cleared   CartStatus = "cleared"
+	Merged    CartStatus = "merged"
 )
 
 type Cart struct {
 	Id           uuid.UUID       `json:"id" gorm:"column:id"`
-	CustomerID   uuid.UUID       `json:"customer_id" gorm:"column:customer_id"`
+	CustomerID   *uuid.UUID      `json:"customer_id" gorm:"column:customer_id"`
+	GuestToken   *uuid.UUID      `json:"-" gorm:"column:guest_token"`
 	Status       CartStatus      `db:"cart_status"`
 	TaxAmount    decimal.Decimal `json:"tax_amount" gorm:"column:tax_amount"`
 	TaxCurrency  string          `json:"tax_currency" gorm:"column:tax_currency"`
 	TaxBreakdown json.JSON       `json:"tax_breakdown" gorm:"column:tax_breakdown"`
+	CouponCode   *string         `json:"coupon_code" gorm:"column:coupon_code"`
 	CreatedAt    time.Time       `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt    time.Time       `json:"updated_at" gorm:"column:updated_at"`
 }
@@ -9,15 +9,20 @@ import (
 	"github.com/google/uuid"
 )
 
+// MaxItemQuantity is the largest quantity of an item a cart can hold.
+const MaxItemQuantity = 100
+
 // swagger:model CartItem
 type CartItem struct {
-	ID               uuid.UUID  `json:"id" gorm:"column:id"`
-	CartID           uuid.UUID  `json:"-" gorm:"column:cart_id"`
-	ProductVariantID uuid.UUID  `json:"product_variant_id" gorm:"column:product_variant_id"`
-	ShippingRateID   *uuid.UUID `json:"shipping_rate_id" gorm:"column:shipping_rate_id"`
-	Quantity         int        `json:"quantity" gorm:"column:quantity"`
-	CreatedAt        time.Time  `json:"added_at" gorm:"column:created_at"`
-	UpdatedAt        time.Time  `json:"updated_at" gorm:"column:updated_at"`
+	ID               uuid.UUID       `json:"id" gorm:"column:id"`
+	CartID           uuid.UUID       `json:"-" gorm:"column:cart_id"`
+	ProductVariantID uuid.UUID       `json:"product_variant_id" gorm:"column:product_variant_id"`
+	ShippingRateID   *uuid.UUID      `json:"shipping_rate_id" gorm:"column:shipping_rate_id"`
+	Quantity         int             `json:"quantity" gorm:"column:quantity"`
+	Price            decimal.Decimal `json:"price" gorm:"column:price"`
+	Currency         string          `json:"currency" gorm:"column:currency"`
+	CreatedAt        time.Time       `json:"added_at" gorm:"column:created_at"`
+	UpdatedAt        time.Time       `json:"updated_at" gorm:"column:updated_at"`
 }
 
 type CartItemDetail struct {
@@ -32,6 +37,8 @@ type CartItemDetail struct {
 	ShippingRateID   *uuid.UUID       `json:"shipping_rate_id" gorm:"column:shipping_rate_id"`
 	Price            decimal.Decimal  `json:"price" gorm:"column:price"`
 	Currency         string           `json:"currency" gorm:"column:currency"`
+	CurrentPrice     decimal.Decimal  `json:"current_price" gorm:"column:current_price"`
+	CurrentCurrency  string           `json:"current_currency" gorm:"column:current_currency"`
 	Attributes       *json.JSON       `json:"attributes" db:"attributes"`
 	Length           *decimal.Decimal `json:"-" gorm:"column:length"`
 	Width            *decimal.Decimal `json:"-" gorm:"column:width"`
@@ -43,6 +50,12 @@ type CartItemDetail struct {
 	UpdatedAt        time.Time        `json:"updated_at" gorm:"column:updated_at"`
 }
 
+// PriceChanged tells if the variant price changed since the item was added to the cart.
+// The item is charged the Price it was added with, CurrentPrice is the price of the variant now.
+func (i *CartItemDetail) PriceChanged() bool {
+	return !i.Price.Equal(i.CurrentPrice) || i.Currency != i.CurrentCurrency
+}
+
 func (CartItem) TableName() string {
 	return "cart_items"
 }
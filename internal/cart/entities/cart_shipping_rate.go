@@ -10,7 +10,7 @@ import (
 type CartShippingRate struct {
 	Id                    uuid.UUID       `json:"id" gorm:"column:id"`
 	CartID                uuid.UUID       `json:"-" gorm:"column:cart_id"`
-	AddressID             uuid.UUID       `json:"-" gorm:"column:address_id"`
+	AddressID             *uuid.UUID      `json:"-" gorm:"column:address_id"`
 	Amount                decimal.Decimal `json:"amount" gorm:"column:amount"`
 	Currency              string          `json:"currency" gorm:"column:currency"`
 	CarrierName           string          `json:"carrier_name" gorm:"column:carrier_name"`
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	addressEntities "github.com/nurdsoft/nurd-commerce-core/internal/address/entities"
 	"github.com/nurdsoft/nurd-commerce-core/shared/json"
 	"github.com/shopspring/decimal"
 )
@@ -50,9 +51,12 @@ type GetShippingRateRequest struct {
 type GetShippingRateRequestBody struct {
 	// Shipping Address UUID
 	//
-	// required: true
 	// in:body
 	AddressID uuid.UUID `json:"address_id"`
+	// Shipping address of a guest, used when no address_id is given
+	//
+	// in:body
+	Address *addressEntities.AddressRequestBody `json:"address,omitempty"`
 	// Warehouse address
 	//
 	// required: true
@@ -79,10 +83,13 @@ type GetTaxRateRequest struct {
 type GetTaxRateRequestBody struct {
 	// Shipping Address ID
 	//
-	// required: true
 	// in:body
 	// example: 123e4567-e89b-12d3-a456-426614174000
 	AddressID uuid.UUID `json:"address_id"`
+	// Shipping address of a guest, used when no address_id is given
+	//
+	// in:body
+	Address *addressEntities.AddressRequestBody `json:"address,omitempty"`
 	// Shipping Rate ID selected by the customer
 	//
 	// required: true
@@ -97,6 +104,23 @@ type GetTaxRateRequestBody struct {
 	WarehouseAddress *WarehouseAddress `json:"warehouse_address"`
 }
 
+// swagger:parameters carts ApplyCouponRequest
+type ApplyCouponRequest struct {
+	// Body of the request
+	//
+	// required: true
+	// in:body
+	Body *ApplyCouponRequestBody
+}
+
+type ApplyCouponRequestBody struct {
+	// Coupon code, not case sensitive
+	//
+	// required: true
+	// example: SUMMER10
+	Code string `json:"code"`
+}
+
 type WarehouseAddress struct {
 	Street      string `json:"street"`
 	City        string `json:"city"`
@@ -116,10 +140,13 @@ type CreateCartShippingRatesRequest struct {
 type CreateCartShippingRatesRequestBody struct {
 	// Address ID
 	//
-	// required: true
 	// in:body
 	// example: "123e4567-e89b-12d3-a456-426614174000"
 	AddressID uuid.UUID `json:"address_id"`
+	// Shipping address of a guest, used when no address_id is given
+	//
+	// in:body
+	Address *addressEntities.AddressRequestBody `json:"address,omitempty"`
 	// Cart Shipping Rates
 	//
 	// required: true
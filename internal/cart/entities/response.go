@@ -3,6 +3,9 @@ package entities
 import (
 	"time"
 
+	"github.com/google/uuid"
+	promotionsEntities "github.com/nurdsoft/nurd-commerce-core/internal/promotions/entities"
+	"github.com/nurdsoft/nurd-commerce-core/shared/json"
 	"github.com/shopspring/decimal"
 )
 
@@ -35,6 +38,76 @@ type GetTaxRateResponse struct {
 	Subtotal decimal.Decimal `json:"subtotal"`
 	// Shipping Rate
 	ShippingRate decimal.Decimal `json:"shipping_rate"`
+	// Discount taken off the subtotal and the shipping rate by the promotions
+	Discount decimal.Decimal `json:"discount"`
+	// Promotions applied to the cart
+	Discounts []*promotionsEntities.Discount `json:"discounts,omitempty"`
 	// Currency of the total amount
 	Currency string `json:"currency"`
 }
+
+// swagger:model CreateGuestCartResponse
+type CreateGuestCartResponse struct {
+	// Token of the guest cart, sent in the x-cart-token header
+	CartToken uuid.UUID `json:"cart_token"`
+}
+
+// swagger:model ApplyCouponResponse
+type ApplyCouponResponse struct {
+	// Coupon code applied to the cart
+	Code string `json:"code"`
+	// Discount taken off the cart by the promotions, the coupon code included
+	Discount decimal.Decimal `json:"discount"`
+	// Promotions applied to the cart
+	Discounts []*promotionsEntities.Discount `json:"discounts"`
+}
+
+// swagger:model CartSummary
+type CartSummary struct {
+	CartID uuid.UUID `json:"cart_id"`
+	// Coupon code applied to the cart
+	CouponCode *string            `json:"coupon_code,omitempty"`
+	Items      []*CartSummaryItem `json:"items"`
+	// Subtotal amount. Subtotal = Price * Quantity of every item
+	Subtotal decimal.Decimal `json:"subtotal"`
+	// Discount taken off the items by the promotions
+	ItemsDiscount decimal.Decimal `json:"items_discount"`
+	// Shipping of the rates picked for the items, a rate shared by several items counts once
+	ShippingAmount decimal.Decimal `json:"shipping_amount"`
+	// Discount taken off the shipping by the promotions
+	ShippingDiscount decimal.Decimal `json:"shipping_discount"`
+	// Discount = Items Discount + Shipping Discount
+	Discount decimal.Decimal `json:"discount"`
+	// Promotions applied to the cart
+	Discounts []*promotionsEntities.Discount `json:"discounts"`
+	// Tax calculated by the last POST /cart/tax-rate
+	Tax          decimal.Decimal `json:"tax"`
+	TaxBreakdown json.JSON       `json:"tax_breakdown,omitempty"`
+	// Total amount. Total = Subtotal - Discount + Shipping Amount + Tax
+	Total    decimal.Decimal `json:"total"`
+	Currency string          `json:"currency"`
+}
+
+type CartSummaryItem struct {
+	CartItemDetail
+	// Line subtotal. Line Subtotal = Price * Quantity
+	LineSubtotal decimal.Decimal `json:"line_subtotal"`
+	// Discount taken off the line by the promotions
+	Discount decimal.Decimal `json:"discount"`
+	// Line total. Line Total = Line Subtotal - Discount
+	LineTotal decimal.Decimal `json:"line_total"`
+	// Shipping rate picked for the item
+	ShippingRate *CartShippingRate `json:"shipping_rate,omitempty"`
+}
+
+// swagger:model CartPriceChange
+type CartPriceChange struct {
+	ItemID uuid.UUID `json:"item_id"`
+	SKU    string    `json:"sku"`
+	// Price the item was added to the cart with
+	OldPrice    decimal.Decimal `json:"old_price"`
+	OldCurrency string          `json:"old_currency"`
+	// Price of the variant now, the item is charged this price once accepted
+	NewPrice    decimal.Decimal `json:"new_price"`
+	NewCurrency string          `json:"new_currency"`
+}
@@ -24,6 +24,18 @@ var moduleErrors = map[string]struct {
 	"CART_NO_SHIPPING_RATES_FOUND":      {StatusCode: http.StatusInternalServerError, Message: "No shipping rates found."},
 	"CART_SHIPPING_RATE_NOT_FOUND":      {StatusCode: http.StatusNotFound, Message: "Shipping rate not found."},
 	"CART_ERROR_GETTING_SHIPPING_RATE":  {StatusCode: http.StatusInternalServerError, Message: "Error getting shipping rate."},
+	"CART_ERROR_UPDATING_COUPON":        {StatusCode: http.StatusInternalServerError, Message: "Error updating coupon code."},
+	"CART_COUPON_CODE_REQUIRED":         {StatusCode: http.StatusBadRequest, Message: "Coupon code is required."},
+	"CART_PRICES_CHANGED":               {StatusCode: http.StatusConflict, Message: "The prices of some items in the cart changed."},
+	"CART_ERROR_ACCEPTING_PRICES":       {StatusCode: http.StatusInternalServerError, Message: "Error accepting the prices of the cart items."},
+	"CART_TAX_NOT_CALCULATED":           {StatusCode: http.StatusConflict, Message: "The cart changed since its tax was calculated, calculate the tax again."},
+	"CART_OWNER_REQUIRED":               {StatusCode: http.StatusBadRequest, Message: "Customer ID or cart token is required."},
+	"CART_TOKEN_REQUIRED":               {StatusCode: http.StatusBadRequest, Message: "Cart token is required."},
+	"CART_ADDRESS_REQUIRED":             {StatusCode: http.StatusBadRequest, Message: "Address ID or address is required."},
+	"CART_ERROR_CREATING_CART":          {StatusCode: http.StatusInternalServerError, Message: "Error creating cart."},
+	"CART_ERROR_MERGING_CARTS":          {StatusCode: http.StatusInternalServerError, Message: "Error merging the guest cart."},
+	"CART_INVALID_QUANTITY":             {StatusCode: http.StatusBadRequest, Message: "Invalid cart item quantity."},
+	"CART_TOO_MANY_PACKAGES":            {StatusCode: http.StatusBadRequest, Message: "The cart items ship in too many packages to be quoted."},
 }
 
 func NewAPIError(errorCode string, customMessage ...string) *errors.APIError {
@@ -10,6 +10,7 @@ import (
 	"github.com/nurdsoft/nurd-commerce-core/shared/cfg"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory"
 	shipping "github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/client"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/packaging"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -17,6 +18,7 @@ import (
 	"github.com/nurdsoft/nurd-commerce-core/internal/cart/endpoints"
 	"github.com/nurdsoft/nurd-commerce-core/internal/cart/repository"
 	"github.com/nurdsoft/nurd-commerce-core/internal/product/productclient"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/promotionsclient"
 	svcTransport "github.com/nurdsoft/nurd-commerce-core/internal/transport"
 	httpTransport "github.com/nurdsoft/nurd-commerce-core/shared/transport/http"
 	salesforce "github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory/salesforce/client"
@@ -39,6 +41,8 @@ type ModuleParams struct {
 	AddressClient    addressclient.Client
 	InventoryClient  inventory.Client
 	SalesforceClient salesforce.Client
+	PromotionsClient promotionsclient.Client
+	Packer           packaging.Packer
 }
 
 // NewModule
@@ -46,7 +50,7 @@ type ModuleParams struct {
 func NewModule(p ModuleParams) error {
 	repo := repository.New(p.DB, p.GormDB)
 	cacheClient := cache.New()
-	svc := service.New(repo, p.Logger, p.ShippingClient, p.TaxesClient, cacheClient, p.ProductClient, p.AddressClient, p.InventoryClient, p.SalesforceClient)
+	svc := service.New(repo, p.Logger, p.ShippingClient, p.TaxesClient, cacheClient, p.ProductClient, p.AddressClient, p.InventoryClient, p.SalesforceClient, p.PromotionsClient, p.Packer)
 	eps := endpoints.New(svc)
 
 	http.RegisterTransport(p.HTTPServer, eps, p.APPTransport)
@@ -105,18 +105,18 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 }
 
 // AddCartItem mocks base method.
-func (m *MockRepository) AddCartItem(ctx context.Context, tx Transaction, cartId, productVariantID string, quantity int) (*entities.CartItem, error) {
+func (m *MockRepository) AddCartItem(ctx context.Context, tx Transaction, cartId, productVariantID string, quantity int, price decimal.Decimal, currency string) (*entities.CartItem, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddCartItem", ctx, tx, cartId, productVariantID, quantity)
+	ret := m.ctrl.Call(m, "AddCartItem", ctx, tx, cartId, productVariantID, quantity, price, currency)
 	ret0, _ := ret[0].(*entities.CartItem)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddCartItem indicates an expected call of AddCartItem.
-func (mr *MockRepositoryMockRecorder) AddCartItem(ctx, tx, cartId, productVariantID, quantity interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) AddCartItem(ctx, tx, cartId, productVariantID, quantity, price, currency interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCartItem", reflect.TypeOf((*MockRepository)(nil).AddCartItem), ctx, tx, cartId, productVariantID, quantity)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCartItem", reflect.TypeOf((*MockRepository)(nil).AddCartItem), ctx, tx, cartId, productVariantID, quantity, price, currency)
 }
 
 // BeginTransaction mocks base method.
@@ -148,6 +148,21 @@ func (mr *MockRepositoryMockRecorder) CreateCartShippingRates(ctx, shippingRate
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCartShippingRates", reflect.TypeOf((*MockRepository)(nil).CreateCartShippingRates), ctx, shippingRate)
 }
 
+// CreateGuestCart mocks base method.
+func (m *MockRepository) CreateGuestCart(ctx context.Context, cartToken uuid.UUID) (*entities.Cart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGuestCart", ctx, cartToken)
+	ret0, _ := ret[0].(*entities.Cart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGuestCart indicates an expected call of CreateGuestCart.
+func (mr *MockRepositoryMockRecorder) CreateGuestCart(ctx, cartToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGuestCart", reflect.TypeOf((*MockRepository)(nil).CreateGuestCart), ctx, cartToken)
+}
+
 // CreateNewCart mocks base method.
 func (m *MockRepository) CreateNewCart(ctx context.Context, tx Transaction, customerID string) (*entities.Cart, error) {
 	m.ctrl.T.Helper()
@@ -238,6 +253,21 @@ func (mr *MockRepositoryMockRecorder) GetCartItems(ctx, cartID interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCartItems", reflect.TypeOf((*MockRepository)(nil).GetCartItems), ctx, cartID)
 }
 
+// GetGuestCart mocks base method.
+func (m *MockRepository) GetGuestCart(ctx context.Context, cartToken string) (*entities.Cart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGuestCart", ctx, cartToken)
+	ret0, _ := ret[0].(*entities.Cart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGuestCart indicates an expected call of GetGuestCart.
+func (mr *MockRepositoryMockRecorder) GetGuestCart(ctx, cartToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGuestCart", reflect.TypeOf((*MockRepository)(nil).GetGuestCart), ctx, cartToken)
+}
+
 // GetShippingRate mocks base method.
 func (m *MockRepository) GetShippingRate(ctx context.Context, shippingRateID uuid.UUID) (*entities.CartShippingRate, error) {
 	m.ctrl.T.Helper()
@@ -253,6 +283,20 @@ func (mr *MockRepositoryMockRecorder) GetShippingRate(ctx, shippingRateID interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShippingRate", reflect.TypeOf((*MockRepository)(nil).GetShippingRate), ctx, shippingRateID)
 }
 
+// MergeCarts mocks base method.
+func (m *MockRepository) MergeCarts(ctx context.Context, guestCartID, cartID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeCarts", ctx, guestCartID, cartID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeCarts indicates an expected call of MergeCarts.
+func (mr *MockRepositoryMockRecorder) MergeCarts(ctx, guestCartID, cartID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeCarts", reflect.TypeOf((*MockRepository)(nil).MergeCarts), ctx, guestCartID, cartID)
+}
+
 // RemoveCartItem mocks base method.
 func (m *MockRepository) RemoveCartItem(ctx context.Context, cartID, itemID string) error {
 	m.ctrl.T.Helper()
@@ -281,18 +325,60 @@ func (mr *MockRepositoryMockRecorder) SetCartItemShippingRate(ctx, cartItemID, s
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCartItemShippingRate", reflect.TypeOf((*MockRepository)(nil).SetCartItemShippingRate), ctx, cartItemID, shippingRateID)
 }
 
+// UpdateCartCouponCode mocks base method.
+func (m *MockRepository) UpdateCartCouponCode(ctx context.Context, cartID uuid.UUID, couponCode *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCartCouponCode", ctx, cartID, couponCode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCartCouponCode indicates an expected call of UpdateCartCouponCode.
+func (mr *MockRepositoryMockRecorder) UpdateCartCouponCode(ctx, cartID, couponCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCartCouponCode", reflect.TypeOf((*MockRepository)(nil).UpdateCartCouponCode), ctx, cartID, couponCode)
+}
+
+// UpdateCartCustomer mocks base method.
+func (m *MockRepository) UpdateCartCustomer(ctx context.Context, cartID uuid.UUID, customerID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCartCustomer", ctx, cartID, customerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCartCustomer indicates an expected call of UpdateCartCustomer.
+func (mr *MockRepositoryMockRecorder) UpdateCartCustomer(ctx, cartID, customerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCartCustomer", reflect.TypeOf((*MockRepository)(nil).UpdateCartCustomer), ctx, cartID, customerID)
+}
+
 // UpdateCartItem mocks base method.
-func (m *MockRepository) UpdateCartItem(ctx context.Context, tx Transaction, itemID string, quantity int) error {
+func (m *MockRepository) UpdateCartItem(ctx context.Context, tx Transaction, itemID string, quantity int, price decimal.Decimal, currency string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateCartItem", ctx, tx, itemID, quantity)
+	ret := m.ctrl.Call(m, "UpdateCartItem", ctx, tx, itemID, quantity, price, currency)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateCartItem indicates an expected call of UpdateCartItem.
-func (mr *MockRepositoryMockRecorder) UpdateCartItem(ctx, tx, itemID, quantity interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) UpdateCartItem(ctx, tx, itemID, quantity, price, currency interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCartItem", reflect.TypeOf((*MockRepository)(nil).UpdateCartItem), ctx, tx, itemID, quantity, price, currency)
+}
+
+// UpdateCartItemPrices mocks base method.
+func (m *MockRepository) UpdateCartItemPrices(ctx context.Context, cartID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCartItemPrices", ctx, cartID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCartItemPrices indicates an expected call of UpdateCartItemPrices.
+func (mr *MockRepositoryMockRecorder) UpdateCartItemPrices(ctx, cartID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCartItem", reflect.TypeOf((*MockRepository)(nil).UpdateCartItem), ctx, tx, itemID, quantity)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCartItemPrices", reflect.TypeOf((*MockRepository)(nil).UpdateCartItemPrices), ctx, cartID)
 }
 
 // UpdateCartStatus mocks base method.
@@ -21,12 +21,16 @@ type Transaction interface {
 type Repository interface {
 	BeginTransaction(ctx context.Context) (Transaction, error)
 	GetActiveCart(ctx context.Context, customerID string) (*entities.Cart, error)
+	GetGuestCart(ctx context.Context, cartToken string) (*entities.Cart, error)
 	CreateNewCart(ctx context.Context, tx Transaction, customerID string) (*entities.Cart, error)
+	CreateGuestCart(ctx context.Context, cartToken uuid.UUID) (*entities.Cart, error)
+	UpdateCartCustomer(ctx context.Context, cartID uuid.UUID, customerID string) error
+	MergeCarts(ctx context.Context, guestCartID, cartID uuid.UUID) error
 	UpdateCartStatus(ctx context.Context, tx Transaction, cartID string, status string) error
 	GetCartItem(ctx context.Context, cartID, productVariantID string) (*entities.CartItem, error)
 	GetCartItemByID(ctx context.Context, cartItemID uuid.UUID) (*entities.CartItem, error)
-	AddCartItem(ctx context.Context, tx Transaction, cartId, productVariantID string, quantity int) (*entities.CartItem, error)
-	UpdateCartItem(ctx context.Context, tx Transaction, itemID string, quantity int) error
+	AddCartItem(ctx context.Context, tx Transaction, cartId, productVariantID string, quantity int, price decimal.Decimal, currency string) (*entities.CartItem, error)
+	UpdateCartItem(ctx context.Context, tx Transaction, itemID string, quantity int, price decimal.Decimal, currency string) error
 	GetCartItems(ctx context.Context, cartID string) ([]entities.CartItemDetail, error)
 	RemoveCartItem(ctx context.Context, cartID, itemID string) error
 	CreateCartShippingRates(ctx context.Context, shippingRate []entities.CartShippingRate) error
@@ -34,6 +38,8 @@ type Repository interface {
 	SetCartItemShippingRate(ctx context.Context, cartItemID uuid.UUID, shippingRateID uuid.UUID) error
 	UpdateCartTaxRate(ctx context.Context, cartID string, taxAmount decimal.Decimal, taxCurrency string, taxBreakdown json.JSON) error
 	GetCartByID(ctx context.Context, cartID uuid.UUID) (*entities.Cart, error)
+	UpdateCartCouponCode(ctx context.Context, cartID uuid.UUID, couponCode *string) error
+	UpdateCartItemPrices(ctx context.Context, cartID uuid.UUID) error
 }
 
 func New(_ *sql.DB, gormDB *gorm.DB) Repository {
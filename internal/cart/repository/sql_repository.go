@@ -18,6 +18,14 @@ type sqlRepository struct {
 	gormDB *gorm.DB
 }
 
+// The tax of the cart is cleared whenever what's taxed changes, it has to be calculated again before checkout.
+const (
+	resetCartTaxQuery = `UPDATE carts SET tax_amount = NULL, tax_currency = NULL, tax_breakdown = NULL, updated_at = now()
+		WHERE id = ?`
+	resetItemCartTaxQuery = `UPDATE carts SET tax_amount = NULL, tax_currency = NULL, tax_breakdown = NULL, updated_at = now()
+		WHERE id = (SELECT cart_id FROM cart_items WHERE id = ?)`
+)
+
 func (r *sqlRepository) BeginTransaction(ctx context.Context) (Transaction, error) {
 	tx := r.gormDB.WithContext(ctx).Begin()
 	if tx.Error != nil {
@@ -35,10 +43,21 @@ func (r *sqlRepository) GetActiveCart(ctx context.Context, customerID string) (*
 	return cart, err
 }
 
+// GetGuestCart returns the active cart of the cart token, nil when the cart was merged, purchased or cleared.
+func (r *sqlRepository) GetGuestCart(ctx context.Context, cartToken string) (*entities.Cart, error) {
+	cart := &entities.Cart{}
+	err := r.gormDB.WithContext(ctx).Where("guest_token = ? AND customer_id IS NULL AND status = ?", cartToken, entities.Active).First(cart).Error
+	if err != nil && dbErrors.IsNotFoundError(err) {
+		return nil, nil
+	}
+	return cart, err
+}
+
 func (r *sqlRepository) CreateNewCart(ctx context.Context, tx Transaction, customerID string) (*entities.Cart, error) {
+	id := uuid.MustParse(customerID)
 	newCart := entities.Cart{
 		Id:         uuid.New(),
-		CustomerID: uuid.MustParse(customerID),
+		CustomerID: &id,
 		Status:     entities.Active,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
@@ -49,6 +68,96 @@ func (r *sqlRepository) CreateNewCart(ctx context.Context, tx Transaction, custo
 	return &newCart, nil
 }
 
+func (r *sqlRepository) CreateGuestCart(ctx context.Context, cartToken uuid.UUID) (*entities.Cart, error) {
+	newCart := entities.Cart{
+		Id:         uuid.New(),
+		GuestToken: &cartToken,
+		Status:     entities.Active,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	if err := r.gormDB.WithContext(ctx).Create(&newCart).Error; err != nil {
+		return nil, err
+	}
+	return &newCart, nil
+}
+
+// UpdateCartCustomer hands the guest cart over to the customer, the cart token doesn't find it anymore.
+// The promotions of the customer may discount the cart differently, its tax is cleared.
+func (r *sqlRepository) UpdateCartCustomer(ctx context.Context, cartID uuid.UUID, customerID string) error {
+	return r.gormDB.WithContext(ctx).
+		Model(&entities.Cart{}).
+		Where("id = ?", cartID).
+		Updates(map[string]interface{}{
+			"customer_id":   customerID,
+			"guest_token":   nil,
+			"tax_amount":    nil,
+			"tax_currency":  nil,
+			"tax_breakdown": nil,
+			"updated_at":    time.Now(),
+		}).Error
+}
+
+// MergeCarts folds the guest cart into the cart of the customer. The quantities of a variant in both carts are
+// summed, the item keeps the shipping rate of the customer and takes the one of the guest when it has none.
+// The other items move over with their shipping rates, and the coupon code of the guest cart is kept when the
+// customer didn't apply one. The tax of the cart of the customer is cleared.
+func (r *sqlRepository) MergeCarts(ctx context.Context, guestCartID, cartID uuid.UUID) error {
+	return r.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			UPDATE cart_items
+			SET quantity = LEAST(cart_items.quantity + guest_items.quantity, ?),
+			    shipping_rate_id = COALESCE(cart_items.shipping_rate_id, guest_items.shipping_rate_id),
+			    updated_at = now()
+			FROM cart_items AS guest_items
+			WHERE cart_items.cart_id = ? AND guest_items.cart_id = ?
+			  AND guest_items.product_variant_id = cart_items.product_variant_id
+		`, entities.MaxItemQuantity, cartID, guestCartID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec(`
+			DELETE FROM cart_items AS guest_items
+			USING cart_items
+			WHERE guest_items.cart_id = ? AND cart_items.cart_id = ?
+			  AND cart_items.product_variant_id = guest_items.product_variant_id
+		`, guestCartID, cartID).Error
+		if err != nil {
+			return err
+		}
+
+		if err = tx.Exec(`UPDATE cart_items SET cart_id = ?, updated_at = now() WHERE cart_id = ?`, cartID, guestCartID).Error; err != nil {
+			return err
+		}
+
+		if err = tx.Exec(`UPDATE cart_shipping_rates SET cart_id = ? WHERE cart_id = ?`, cartID, guestCartID).Error; err != nil {
+			return err
+		}
+
+		err = tx.Exec(`
+			UPDATE carts
+			SET coupon_code = guest_carts.coupon_code, updated_at = now()
+			FROM carts AS guest_carts
+			WHERE carts.id = ? AND guest_carts.id = ? AND carts.coupon_code IS NULL
+		`, cartID, guestCartID).Error
+		if err != nil {
+			return err
+		}
+
+		if err = tx.Exec(resetCartTaxQuery, cartID).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&entities.Cart{}).
+			Where("id = ?", guestCartID).
+			Updates(map[string]interface{}{
+				"status":     entities.Merged,
+				"updated_at": time.Now(),
+			}).Error
+	})
+}
+
 func (r *sqlRepository) UpdateCartStatus(ctx context.Context, tx Transaction, cartID string, status string) error {
 	dbCtx := r.gormDB.WithContext(ctx)
 	if tx != nil {
@@ -82,29 +191,42 @@ func (r *sqlRepository) GetCartItem(ctx context.Context, cartID, productVariantI
 	return &item, err
 }
 
-// AddCartItem adds a new item to the cart.
-func (r *sqlRepository) AddCartItem(ctx context.Context, tx Transaction, cartId, productVariantID string, quantity int) (*entities.CartItem, error) {
+// AddCartItem adds a new item to the cart, at the price of the variant now, and clears the tax of the cart.
+func (r *sqlRepository) AddCartItem(ctx context.Context, tx Transaction, cartId, productVariantID string, quantity int, price decimal.Decimal, currency string) (*entities.CartItem, error) {
 	newItem := entities.CartItem{
 		ID:               uuid.New(),
 		CartID:           uuid.MustParse(cartId),
 		ProductVariantID: uuid.MustParse(productVariantID),
 		Quantity:         quantity,
+		Price:            price,
+		Currency:         currency,
 		CreatedAt:        time.Now(),
 		UpdatedAt:        time.Now(),
 	}
 	if err := tx.WithContext(ctx).Create(&newItem).Error; err != nil {
 		return nil, err
 	}
+	if err := tx.WithContext(ctx).Exec(resetCartTaxQuery, newItem.CartID).Error; err != nil {
+		return nil, err
+	}
 	return &newItem, nil
 }
 
-func (r *sqlRepository) UpdateCartItem(ctx context.Context, tx Transaction, itemID string, quantity int) error {
-	return tx.WithContext(ctx).
+// UpdateCartItem updates the quantity and price of the item and clears the tax of the cart.
+func (r *sqlRepository) UpdateCartItem(ctx context.Context, tx Transaction, itemID string, quantity int, price decimal.Decimal, currency string) error {
+	err := tx.WithContext(ctx).
 		Model(&entities.CartItem{}).
 		Where("id = ?", itemID).
 		Updates(map[string]interface{}{
 			"quantity": quantity,
+			"price":    price,
+			"currency": currency,
 		}).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.WithContext(ctx).Exec(resetItemCartTaxQuery, itemID).Error
 }
 
 func (r *sqlRepository) GetCartItems(ctx context.Context, cartID string) ([]entities.CartItemDetail, error) {
@@ -114,7 +236,8 @@ func (r *sqlRepository) GetCartItems(ctx context.Context, cartID string) ([]enti
 		Joins("JOIN product_variants ON cart_items.product_variant_id = product_variants.id").
 		Where("cart_id = ?", cartID).
 		Select("cart_items.id, cart_items.cart_id, product_variants.sku, product_variants.name, product_variants.product_id, cart_items.product_variant_id, " +
-			" cart_items.shipping_rate_id, product_variants.price, product_variants.currency, product_variants.attributes, product_variants.length, product_variants.width, " +
+			" cart_items.shipping_rate_id, cart_items.price, cart_items.currency, product_variants.price AS current_price, " +
+			" product_variants.currency AS current_currency, product_variants.attributes, product_variants.length, product_variants.width, " +
 			" product_variants.height, product_variants.weight, product_variants.stripe_tax_code, cart_items.quantity, product_variants.image_url, " +
 			" product_variants.description, cart_items.created_at, cart_items.updated_at").
 		Find(&items).Error
@@ -124,10 +247,17 @@ func (r *sqlRepository) GetCartItems(ctx context.Context, cartID string) ([]enti
 	return items, nil
 }
 
+// RemoveCartItem removes the item from the cart and clears the tax of the cart.
 func (r *sqlRepository) RemoveCartItem(ctx context.Context, cartID, itemID string) error {
-	return r.gormDB.WithContext(ctx).
-		Where("id = ? AND cart_id = ?", itemID, cartID).
-		Delete(&entities.CartItem{}).Error
+	return r.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("id = ? AND cart_id = ?", itemID, cartID).
+			Delete(&entities.CartItem{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(resetCartTaxQuery, cartID).Error
+	})
 }
 
 func (r *sqlRepository) CreateCartShippingRates(ctx context.Context, shippingRates []entities.CartShippingRate) error {
@@ -150,11 +280,18 @@ func (r *sqlRepository) GetShippingRate(ctx context.Context, shippingRate uuid.U
 	return &rate, err
 }
 
+// SetCartItemShippingRate sets the shipping rate of the item and clears the tax of the cart.
 func (r *sqlRepository) SetCartItemShippingRate(ctx context.Context, cartItemID uuid.UUID, shippingRateID uuid.UUID) error {
-	return r.gormDB.WithContext(ctx).
-		Model(&entities.CartItem{}).
-		Where("id = ?", cartItemID).
-		Update("shipping_rate_id", shippingRateID).Error
+	return r.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entities.CartItem{}).
+			Where("id = ?", cartItemID).
+			Update("shipping_rate_id", shippingRateID).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(resetItemCartTaxQuery, cartItemID).Error
+	})
 }
 
 func (r *sqlRepository) UpdateCartTaxRate(ctx context.Context, cartID string, taxAmount decimal.Decimal, taxCurrency string, taxBreakdown json.JSON) error {
@@ -178,3 +315,34 @@ func (r *sqlRepository) GetCartByID(ctx context.Context, cartID uuid.UUID) (*ent
 	}
 	return &cart, err
 }
+
+// UpdateCartCouponCode applies the coupon code to the cart, nil removes it, and clears the tax of the cart.
+func (r *sqlRepository) UpdateCartCouponCode(ctx context.Context, cartID uuid.UUID, couponCode *string) error {
+	return r.gormDB.WithContext(ctx).
+		Model(&entities.Cart{}).
+		Where("id = ?", cartID).
+		Updates(map[string]interface{}{
+			"coupon_code":   couponCode,
+			"tax_amount":    nil,
+			"tax_currency":  nil,
+			"tax_breakdown": nil,
+		}).Error
+}
+
+// UpdateCartItemPrices takes the prices of the variants now for the items of the cart and clears the tax of the
+// cart, it was calculated on the old prices.
+func (r *sqlRepository) UpdateCartItemPrices(ctx context.Context, cartID uuid.UUID) error {
+	return r.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			UPDATE cart_items
+			SET price = product_variants.price, currency = product_variants.currency, updated_at = now()
+			FROM product_variants
+			WHERE cart_items.product_variant_id = product_variants.id AND cart_items.cart_id = ?
+		`, cartID).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(resetCartTaxQuery, cartID).Error
+	})
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	addressEntities "github.com/nurdsoft/nurd-commerce-core/internal/address/entities"
+	"github.com/nurdsoft/nurd-commerce-core/internal/cart/entities"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/cart/errors"
+	sharedMeta "github.com/nurdsoft/nurd-commerce-core/shared/meta"
+)
+
+// cartOwner is who the cart belongs to, a customer or a guest holding a cart token.
+// A signed in customer is preferred over the cart token sent with it.
+type cartOwner struct {
+	customerID string
+	cartToken  string
+}
+
+func ownerFromContext(ctx context.Context) (cartOwner, error) {
+	owner := cartOwner{customerID: sharedMeta.XCustomerID(ctx)}
+	if owner.customerID != "" {
+		return owner, nil
+	}
+
+	owner.cartToken = sharedMeta.XCartToken(ctx)
+	if owner.cartToken == "" {
+		return owner, moduleErrors.NewAPIError("CART_OWNER_REQUIRED")
+	}
+
+	return owner, nil
+}
+
+// IsGuest reports whether the cart is identified by a cart token.
+func (o cartOwner) IsGuest() bool {
+	return o.customerID == ""
+}
+
+// ID identifies the owner in the cache keys.
+func (o cartOwner) ID() string {
+	if o.IsGuest() {
+		return o.cartToken
+	}
+	return o.customerID
+}
+
+// getActiveCart returns the active cart of the owner, nil when it has none.
+func (s *service) getActiveCart(ctx context.Context, owner cartOwner) (*entities.Cart, error) {
+	if owner.IsGuest() {
+		return s.repo.GetGuestCart(ctx, owner.cartToken)
+	}
+	return s.repo.GetActiveCart(ctx, owner.customerID)
+}
+
+// getAddress returns the address the cart is shipped to. Customers send the ID of a saved address, guests send
+// the address itself. The address given inline has no ID.
+func (s *service) getAddress(ctx context.Context, owner cartOwner, addressID uuid.UUID, address *addressEntities.AddressRequestBody) (*addressEntities.Address, error) {
+	if addressID != uuid.Nil {
+		return s.addressClient.GetAddress(ctx, &addressEntities.GetAddressRequest{
+			AddressID: addressID,
+		})
+	}
+
+	if address != nil {
+		return &addressEntities.Address{
+			FullName:    address.FullName,
+			Address:     address.Address,
+			Apartment:   address.Apartment,
+			City:        address.City,
+			PhoneNumber: address.PhoneNumber,
+			StateCode:   address.StateCode,
+			CountryCode: address.CountryCode,
+			PostalCode:  address.PostalCode,
+		}, nil
+	}
+
+	if owner.IsGuest() {
+		return nil, moduleErrors.NewAPIError("CART_ADDRESS_REQUIRED")
+	}
+
+	// rely on default address in case provided address is empty (possible for digital goods)
+	return s.addressClient.GetDefaultAddress(ctx)
+}
+
+// addressCacheKey identifies the address in the cache keys, an address given inline is identified by its fields.
+func addressCacheKey(addressID uuid.UUID, address *addressEntities.AddressRequestBody) string {
+	if addressID != uuid.Nil || address == nil {
+		return addressID.String()
+	}
+
+	var city string
+	if address.City != nil {
+		city = *address.City
+	}
+	fields := strings.Join([]string{address.Address, city, address.StateCode, address.PostalCode, address.CountryCode}, "|")
+
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(strings.ToLower(fields))).String()
+}
+
+// savedAddressID returns the ID kept with the shipping rates, nil for an address given inline.
+func savedAddressID(address *addressEntities.Address) *uuid.UUID {
+	if address.ID == uuid.Nil {
+		return nil
+	}
+	id := address.ID
+	return &id
+}
+
+// swagger:route POST /cart/guest carts CreateGuestCart
+//
+// # Create Guest Cart
+// ### Create a cart for a guest, the cart token returned is sent in the x-cart-token header to use it
+//
+// Produces:
+//   - application/json
+//
+// Responses:
+//
+//	200: CreateGuestCartResponse Guest cart created successfully
+//	500: DefaultError Internal Server Error
+func (s *service) CreateGuestCart(ctx context.Context) (*entities.CreateGuestCartResponse, error) {
+	cart, err := s.repo.CreateGuestCart(ctx, uuid.New())
+	if err != nil {
+		s.log.Errorf("Error creating guest cart: %v", err)
+		return nil, moduleErrors.NewAPIError("CART_ERROR_CREATING_CART")
+	}
+
+	return &entities.CreateGuestCartResponse{CartToken: *cart.GuestToken}, nil
+}
+
+// swagger:route POST /cart/merge carts MergeGuestCart
+//
+// # Merge Guest Cart
+// ### Fold the guest cart of the x-cart-token header into the active cart of the customer once they sign in, the quantities of the items in both carts are summed
+//
+// Produces:
+//   - application/json
+//
+// Responses:
+//
+//	200: GetCartItemsResponse Guest cart merged successfully
+//	400: DefaultError Customer ID and cart token are required
+//	404: DefaultError Guest cart not found
+//	500: DefaultError Internal Server Error
+func (s *service) MergeGuestCart(ctx context.Context) (*entities.GetCartItemsResponse, error) {
+	customerID := sharedMeta.XCustomerID(ctx)
+	if customerID == "" {
+		return nil, moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	}
+
+	cartToken := sharedMeta.XCartToken(ctx)
+	if cartToken == "" {
+		return nil, moduleErrors.NewAPIError("CART_TOKEN_REQUIRED")
+	}
+
+	guestCart, err := s.repo.GetGuestCart(ctx, cartToken)
+	if err != nil {
+		s.log.Errorf("Error retrieving guest cart: %v", err)
+		return nil, moduleErrors.NewAPIError("CART_ERROR_GETTING_CART")
+	}
+	if guestCart == nil {
+		return nil, moduleErrors.NewAPIError("CART_NOT_FOUND")
+	}
+
+	cart, err := s.repo.GetActiveCart(ctx, customerID)
+	if err != nil {
+		s.log.Errorf("Error retrieving active cart: %v", err)
+		return nil, moduleErrors.NewAPIError("CART_ERROR_GETTING_CART")
+	}
+
+	if cart == nil {
+		// nothing to merge into, the guest cart becomes the cart of the customer
+		if err = s.repo.UpdateCartCustomer(ctx, guestCart.Id, customerID); err != nil {
+			s.log.Errorf("Error handing guest cart %s over to the customer: %v", guestCart.Id, err)
+			return nil, moduleErrors.NewAPIError("CART_ERROR_MERGING_CARTS")
+		}
+		cart = guestCart
+	} else if err = s.repo.MergeCarts(ctx, guestCart.Id, cart.Id); err != nil {
+		s.log.Errorf("Error merging guest cart %s into cart %s: %v", guestCart.Id, cart.Id, err)
+		return nil, moduleErrors.NewAPIError("CART_ERROR_MERGING_CARTS")
+	}
+
+	// the rates and taxes were worked out for the items before the merge
+	go func() {
+		// delete by pattern shipping_rate_<any_address_id>_<customer_id>_<cart_id>
+		if err := s.cache.DeleteByPattern(context.Background(), fmt.Sprintf("^shipping_rate_[^_]+_%s_%s$", customerID, cart.Id.String())); err != nil {
+			s.log.Errorf("Error deleting shipping rate cache: %v", err)
+		}
+	}()
+	s.evictTaxRates(ctx, customerID, cart.Id)
+
+	items, err := s.repo.GetCartItems(ctx, cart.Id.String())
+	if err != nil {
+		s.log.Errorf("Error retrieving cart items: %v", err)
+		return nil, moduleErrors.NewAPIError("CART_ERROR_GETTING_CART_ITEMS")
+	}
+
+	return &entities.GetCartItemsResponse{Items: items}, nil
+}
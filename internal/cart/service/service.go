@@ -8,27 +8,28 @@ import (
 	"strings"
 	"time"
 
-	sharedDecimal "github.com/nurdsoft/nurd-commerce-core/shared/decimal"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory/providers"
 	salesforce "github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory/salesforce/client"
 	salesforceEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory/salesforce/entities"
 	shippingEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/entities"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/packaging"
 	taxesProviders "github.com/nurdsoft/nurd-commerce-core/shared/vendors/taxes/providers"
 
 	"github.com/nurdsoft/nurd-commerce-core/internal/address/addressclient"
-	addressEntities "github.com/nurdsoft/nurd-commerce-core/internal/address/entities"
 	"github.com/nurdsoft/nurd-commerce-core/internal/cart/entities"
 	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/cart/errors"
 	"github.com/nurdsoft/nurd-commerce-core/internal/cart/repository"
 	productEntities "github.com/nurdsoft/nurd-commerce-core/internal/product/entities"
 	"github.com/nurdsoft/nurd-commerce-core/internal/product/productclient"
+	promotionsEntities "github.com/nurdsoft/nurd-commerce-core/internal/promotions/entities"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/promotionsclient"
 	taxesEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/taxes/entities"
 
 	"github.com/google/uuid"
 	"github.com/nurdsoft/nurd-commerce-core/shared/cache"
 	dbErrors "github.com/nurdsoft/nurd-commerce-core/shared/db"
-	sharedMeta "github.com/nurdsoft/nurd-commerce-core/shared/meta"
+	sharedJson "github.com/nurdsoft/nurd-commerce-core/shared/json"
 	shipping "github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/client"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/taxes"
 	"github.com/shopspring/decimal"
@@ -46,6 +47,12 @@ type Service interface {
 	CreateCartShippingRates(ctx context.Context, req *entities.CreateCartShippingRatesRequest) (*entities.GetShippingRateResponse, error)
 	SetCartItemShippingRate(ctx context.Context, req *entities.SetCartItemShippingRateRequest) error
 	GetCart(ctx context.Context) (*entities.Cart, error)
+	ApplyCoupon(ctx context.Context, req *entities.ApplyCouponRequest) (*entities.ApplyCouponResponse, error)
+	RemoveCoupon(ctx context.Context) error
+	GetCartSummary(ctx context.Context) (*entities.CartSummary, error)
+	AcceptCartPrices(ctx context.Context) (*entities.GetCartItemsResponse, error)
+	CreateGuestCart(ctx context.Context) (*entities.CreateGuestCartResponse, error)
+	MergeGuestCart(ctx context.Context) (*entities.GetCartItemsResponse, error)
 }
 
 type service struct {
@@ -58,6 +65,8 @@ type service struct {
 	addressClient    addressclient.Client
 	inventoryClient  inventory.Client
 	salesforceClient salesforce.Client
+	promotionsClient promotionsclient.Client
+	packer           packaging.Packer
 }
 
 func New(
@@ -70,6 +79,8 @@ func New(
 	addressClient addressclient.Client,
 	inventoryClient inventory.Client,
 	salesforceClient salesforce.Client,
+	promotionsClient promotionsclient.Client,
+	packer packaging.Packer,
 ) Service {
 	return &service{
 		repo:             repo,
@@ -81,6 +92,8 @@ func New(
 		addressClient:    addressClient,
 		inventoryClient:  inventoryClient,
 		salesforceClient: salesforceClient,
+		promotionsClient: promotionsClient,
+		packer:           packer,
 	}
 }
 
@@ -98,10 +111,14 @@ func New(
 //	400: DefaultError Bad Request
 //	500: DefaultError Internal Server Error
 func (s *service) UpdateCartItem(ctx context.Context, req *entities.UpdateCartItemRequest) (*entities.CartItem, error) {
-	customerID := sharedMeta.XCustomerID(ctx)
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	if customerID == "" {
-		return nil, moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	if req.Item.Quantity < 0 || req.Item.Quantity > entities.MaxItemQuantity {
+		return nil, moduleErrors.NewAPIError("CART_INVALID_QUANTITY",
+			fmt.Sprintf("Quantity should be between 0 and %d.", entities.MaxItemQuantity))
 	}
 
 	// Start a transaction
@@ -124,7 +141,7 @@ func (s *service) UpdateCartItem(ctx context.Context, req *entities.UpdateCartIt
 	}()
 
 	// Step 1: Retrieve the active cart for the customer
-	cart, err := s.repo.GetActiveCart(ctx, customerID)
+	cart, err := s.getActiveCart(ctx, owner)
 	if err != nil {
 		s.log.Errorf("Error retrieving active cart: %v", err)
 		return nil, moduleErrors.NewAPIError("CART_ERROR_GETTING_CART")
@@ -132,8 +149,14 @@ func (s *service) UpdateCartItem(ctx context.Context, req *entities.UpdateCartIt
 
 	// Step 2: If cart does not exist, create a new one
 	if cart == nil {
+		// guest carts are created by POST /cart/guest, the token of a merged or purchased cart finds no cart
+		if owner.IsGuest() {
+			err = moduleErrors.NewAPIError("CART_NOT_FOUND")
+			return nil, err
+		}
+
 		// No active cart found, create a new cart
-		cart, err = s.repo.CreateNewCart(ctx, tx, customerID)
+		cart, err = s.repo.CreateNewCart(ctx, tx, owner.customerID)
 		if err != nil {
 			s.log.Errorf("Error creating new cart: %v", err)
 			return nil, moduleErrors.NewAPIError("CART_ERROR_UPDATING_CART_ITEM")
@@ -223,7 +246,9 @@ func (s *service) UpdateCartItem(ctx context.Context, req *entities.UpdateCartIt
 		} else {
 			// Item already exists, replace quantity and price
 			item.Quantity = req.Item.Quantity
-			if err = s.repo.UpdateCartItem(ctx, tx, item.ID.String(), item.Quantity); err != nil {
+			item.Price = productVariant.Price
+			item.Currency = productVariant.Currency
+			if err = s.repo.UpdateCartItem(ctx, tx, item.ID.String(), item.Quantity, item.Price, item.Currency); err != nil {
 				s.log.Errorf("Error updating cart item quantity: %v", err)
 				return nil, moduleErrors.NewAPIError("CART_ERROR_UPDATING_CART_ITEM")
 			}
@@ -231,7 +256,7 @@ func (s *service) UpdateCartItem(ctx context.Context, req *entities.UpdateCartIt
 		}
 	} else if req.Item.Quantity > 0 {
 		// Item does not exist, prepare to add
-		resultItem, err = s.repo.AddCartItem(ctx, tx, cart.Id.String(), productVariant.ID.String(), req.Item.Quantity)
+		resultItem, err = s.repo.AddCartItem(ctx, tx, cart.Id.String(), productVariant.ID.String(), req.Item.Quantity, productVariant.Price, productVariant.Currency)
 		if err != nil {
 			s.log.Errorf("Error adding item to cart: %v", err)
 			return nil, moduleErrors.NewAPIError("CART_ERROR_UPDATING_CART_ITEM")
@@ -251,10 +276,11 @@ func (s *service) UpdateCartItem(ctx context.Context, req *entities.UpdateCartIt
 	// evict the cache for the shipping rates
 	go func() {
 		// delete by pattern shipping_rate_<any_address_id>_<customer_id>_<cart_id>
-		if err := s.cache.DeleteByPattern(context.Background(), fmt.Sprintf("^shipping_rate_[^_]+_%s_%s$", customerID, cart.Id.String())); err != nil {
+		if err := s.cache.DeleteByPattern(context.Background(), fmt.Sprintf("^shipping_rate_[^_]+_%s_%s$", owner.ID(), cart.Id.String())); err != nil {
 			s.log.Errorf("Error deleting shipping rate cache: %v", err)
 		}
 	}()
+	s.evictTaxRates(ctx, owner.ID(), cart.Id)
 
 	return resultItem, nil
 
@@ -321,13 +347,13 @@ func (s *service) syncSalesforceProduct(ctx context.Context, product *productEnt
 //	400: DefaultError Bad Request
 //	500: DefaultError Internal Server Error
 func (s *service) GetCartItems(ctx context.Context) (*entities.GetCartItemsResponse, error) {
-	customerID := sharedMeta.XCustomerID(ctx)
-	if customerID == "" {
-		return nil, moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// Retrieve active cart
-	cart, err := s.repo.GetActiveCart(ctx, customerID)
+	cart, err := s.getActiveCart(ctx, owner)
 	if err != nil || cart == nil {
 		s.log.Errorf("Error retrieving active cart: %v", err)
 		return nil, nil
@@ -366,13 +392,13 @@ func (s *service) GetCartItems(ctx context.Context) (*entities.GetCartItemsRespo
 //	404: DefaultError Not Found
 //	500: DefaultError Internal Server Error
 func (s *service) RemoveCartItem(ctx context.Context, itemID string) error {
-	customerID := sharedMeta.XCustomerID(ctx)
-	if customerID == "" {
-		return moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return err
 	}
 
 	// Retrieve active cart
-	cart, err := s.repo.GetActiveCart(ctx, customerID)
+	cart, err := s.getActiveCart(ctx, owner)
 	if err != nil {
 		s.log.Errorf("Error retrieving active cart: %v", err)
 		return moduleErrors.NewAPIError("CART_ERROR_GETTING_CART")
@@ -392,10 +418,11 @@ func (s *service) RemoveCartItem(ctx context.Context, itemID string) error {
 	} else { // evict the cache for the shipping rates
 		go func() {
 			// delete by pattern shipping_rate_<any_address_id>_<customer_id>_<cart_id>
-			if err := s.cache.DeleteByPattern(context.Background(), fmt.Sprintf("^shipping_rate_[^_]+_%s_%s$", customerID, cart.Id.String())); err != nil {
+			if err := s.cache.DeleteByPattern(context.Background(), fmt.Sprintf("^shipping_rate_[^_]+_%s_%s$", owner.ID(), cart.Id.String())); err != nil {
 				s.log.Errorf("Error deleting shipping rate cache: %v", err)
 			}
 		}()
+		s.evictTaxRates(ctx, owner.ID(), cart.Id)
 	}
 
 	return nil
@@ -415,13 +442,13 @@ func (s *service) RemoveCartItem(ctx context.Context, itemID string) error {
 //	400: DefaultError Bad Request
 //	500: DefaultError Internal Server Error
 func (s *service) ClearCartItems(ctx context.Context) error {
-	customerID := sharedMeta.XCustomerID(ctx)
-	if customerID == "" {
-		return moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return err
 	}
 
 	// Retrieve active cart
-	cart, err := s.repo.GetActiveCart(ctx, customerID)
+	cart, err := s.getActiveCart(ctx, owner)
 	if err != nil {
 		s.log.Errorf("Error retrieving active cart: %v", err)
 		return moduleErrors.NewAPIError("CART_ERROR_GETTING_CART")
@@ -452,36 +479,33 @@ func (s *service) ClearCartItems(ctx context.Context) error {
 //	400: DefaultError Bad Request
 //	500: DefaultError Internal Server Error
 func (s *service) GetTaxRate(ctx context.Context, req *entities.GetTaxRateRequest) (*entities.GetTaxRateResponse, error) {
-	customerID := sharedMeta.XCustomerID(ctx)
-	if customerID == "" {
-		return nil, moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	var address *addressEntities.Address
-	var err error
+	address, err := s.getAddress(ctx, owner, req.Body.AddressID, req.Body.Address)
+	if err != nil {
+		s.log.Errorf("Error retrieving address: %v", err)
+		return nil, err
+	}
 
-	if req.Body.AddressID != uuid.Nil {
-		address, err = s.addressClient.GetAddress(ctx, &addressEntities.GetAddressRequest{
-			AddressID: req.Body.AddressID,
-		})
-		if err != nil {
-			s.log.Errorf("Error retrieving address: %v", err)
-			return nil, err
-		}
-	} else {
-		// rely on default address in case provided address is empty (possible for digital goods)
-		address, err = s.addressClient.GetDefaultAddress(ctx)
-		if err != nil {
-			s.log.Errorf("Error retrieving default address: %v", err)
-			return nil, err
-		}
+	// get items from active cart, the coupon code applied is kept on the cart
+	cart, err := s.getActiveCart(ctx, owner)
+	if err != nil || cart == nil {
+		s.log.Errorf("Error retrieving active cart: %v", err)
+		return nil, moduleErrors.NewAPIError("CART_ERROR_GETTING_CART_ITEMS")
 	}
 
-	// get items from active cart
-	getActiveCarItems, err := s.GetCartItems(ctx)
+	items, err := s.repo.GetCartItems(ctx, cart.Id.String())
 	if err != nil {
+		s.log.Errorf("Error retrieving cart items: %v", err)
 		return nil, moduleErrors.NewAPIError("CART_ERROR_GETTING_CART_ITEMS")
 	}
+	if len(items) == 0 {
+		return nil, moduleErrors.NewAPIError("CART_IS_EMPTY")
+	}
+	getActiveCarItems := &entities.GetCartItemsResponse{Items: items}
 
 	shippingAmount := decimal.Zero
 	shippingRateIDsForCache := ""
@@ -531,25 +555,42 @@ func (s *service) GetTaxRate(ctx context.Context, req *entities.GetTaxRateReques
 		}
 	}
 
-	cacheKey := getTaxRateCacheKey(req.Body.AddressID.String(), customerID, getActiveCarItems.Items[0].CartID.String(), shippingRateIDsForCache)
+	cacheKey := getTaxRateCacheKey(addressCacheKey(req.Body.AddressID, req.Body.Address), owner.ID(), getActiveCarItems.Items[0].CartID.String(), shippingRateIDsForCache)
+	if cart.CouponCode != nil {
+		cacheKey += "_" + *cart.CouponCode
+	}
 	cachedResponse, err := s.cache.Get(ctx, cacheKey)
 	if err == nil && cachedResponse != nil {
-		var response entities.GetTaxRateResponse
-		if err := json.Unmarshal(cachedResponse.([]byte), &response); err == nil {
-			return &response, nil
+		var cached cachedTaxRate
+		// a quote without a currency was cached in another format, it's calculated again
+		if err := json.Unmarshal(cachedResponse.([]byte), &cached); err == nil && cached.Response.Currency != "" {
+			// the tax of the cart was cleared when it changed, the quote of the cart as it is now is charged
+			err = s.repo.UpdateCartTaxRate(ctx, cart.Id.String(), cached.Response.Tax, cached.Response.Currency, cached.Breakdown)
+			if err != nil {
+				s.log.Errorf("Error updating cart with tax rate: %v", err)
+				return nil, moduleErrors.NewAPIError("CART_ERROR_UPDATING_TAX_RATE")
+			}
+			return &cached.Response, nil
 		}
 	}
 
+	// the tax is calculated on the discounted amounts
+	discounts, err := s.calculateDiscounts(ctx, owner, cart, getActiveCarItems.Items, shippingAmount)
+	if err != nil {
+		return nil, err
+	}
+
 	var taxItems []taxesEntities.TaxItem
 	var totalCartPrice decimal.Decimal
 	var toAddress, fromAddress taxesEntities.Address
 
-	for _, item := range getActiveCarItems.Items {
+	for i, item := range getActiveCarItems.Items {
 		taxItem := taxesEntities.TaxItem{
 			Price:     item.Price,
 			Quantity:  item.Quantity,
 			Reference: item.SKU,
 			TaxCode:   s.getItemTaxCodeByProvider(&item),
+			Discount:  discounts.ItemDiscounts[i],
 		}
 
 		taxItems = append(taxItems, taxItem)
@@ -579,7 +620,7 @@ func (s *service) GetTaxRate(ctx context.Context, req *entities.GetTaxRateReques
 	}
 
 	res, err := s.taxesClient.CalculateTax(ctx, &taxesEntities.CalculateTaxRequest{
-		ShippingAmount: shippingAmount,
+		ShippingAmount: shippingAmount.Sub(discounts.ShippingDiscount),
 		FromAddress:    &fromAddress,
 		ToAddress:      toAddress,
 		TaxItems:       taxItems,
@@ -607,11 +648,13 @@ func (s *service) GetTaxRate(ctx context.Context, req *entities.GetTaxRateReques
 		Total:        decimal.NewFromFloat(res.TotalAmount.InexactFloat64()),
 		ShippingRate: decimal.NewFromFloat(shippingAmount.InexactFloat64()),
 		Subtotal:     totalCartPrice,
+		Discount:     discounts.Total(),
+		Discounts:    discounts.Discounts,
 		Currency:     res.Currency,
 	}
 
 	// Cache the response
-	responseBytes, err := json.Marshal(response)
+	responseBytes, err := json.Marshal(cachedTaxRate{Response: response, Breakdown: res.Breakdown})
 	if err == nil {
 		_ = s.cache.Set(ctx, cacheKey, responseBytes, 5*time.Minute)
 	}
@@ -652,18 +695,14 @@ func (s *service) getItemTaxCodeByProvider(item *entities.CartItemDetail) string
 //	400: DefaultError Bad Request
 //	500: DefaultError Internal Server Error
 func (s *service) GetShippingRate(ctx context.Context, req *entities.GetShippingRateRequest) (*entities.GetShippingRateResponse, error) {
-	var maxLength, maxWidth, totalHeight, totalWeight decimal.Decimal
-	var lengths, widths, allHeights, allWeights []decimal.Decimal
 	var cartId uuid.UUID
 
-	customerID, err := uuid.Parse(sharedMeta.XCustomerID(ctx))
+	owner, err := ownerFromContext(ctx)
 	if err != nil {
-		return nil, moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+		return nil, err
 	}
 
-	address, err := s.addressClient.GetAddress(ctx, &addressEntities.GetAddressRequest{
-		AddressID: req.Body.AddressID,
-	})
+	address, err := s.getAddress(ctx, owner, req.Body.AddressID, req.Body.Address)
 	if err != nil {
 		s.log.Errorf("Error retrieving address: %v", err)
 		return nil, err
@@ -674,30 +713,10 @@ func (s *service) GetShippingRate(ctx context.Context, req *entities.GetShipping
 		return nil, moduleErrors.NewAPIError("CART_IS_EMPTY")
 	}
 
-	for _, item := range getActiveCarItems.Items {
-		// TODO revise package calculation
-		if item.Length != nil || item.Width != nil || item.Height != nil || item.Weight != nil {
-			for i := 0; i < item.Quantity; i++ {
-				if item.Length != nil {
-					lengths = append(lengths, *item.Length)
-				}
-				if item.Width != nil {
-					widths = append(widths, *item.Width)
-				}
-				if item.Height != nil {
-					allHeights = append(allHeights, *item.Height)
-				}
-				if item.Weight != nil {
-					allWeights = append(allWeights, *item.Weight)
-				}
-			}
-		}
-	}
-
 	// assuming all items in the cart belong to the same cart
 	cartId = getActiveCarItems.Items[0].CartID
 
-	cacheKey := getShippingRateCacheKey(req.Body.AddressID.String(), customerID.String(), cartId.String())
+	cacheKey := getShippingRateCacheKey(addressCacheKey(req.Body.AddressID, req.Body.Address), owner.ID(), cartId.String())
 
 	cachedResponse, err := s.cache.Get(ctx, cacheKey)
 	if err == nil && cachedResponse != nil {
@@ -707,12 +726,15 @@ func (s *service) GetShippingRate(ctx context.Context, req *entities.GetShipping
 		}
 	}
 
-	// considering the items are packaged in a stack-wise fashion (one on top of the other)
-	// TODO: improve considering the actual packaging style
-	maxLength = sharedDecimal.MaxDecimal(lengths...)
-	maxWidth = sharedDecimal.MaxDecimal(widths...)
-	totalHeight = sharedDecimal.SumDecimals(allHeights...)
-	totalWeight = sharedDecimal.SumDecimals(allWeights...)
+	packages, err := s.packer.Pack(packageItems(getActiveCarItems.Items))
+	if err != nil {
+		s.log.Errorf("Error packing the items of cart %s: %v", cartId, err)
+		return nil, moduleErrors.NewAPIError("CART_TOO_MANY_PACKAGES")
+	}
+	if len(packages) == 0 {
+		// nothing to measure, the carriers quote at least one package
+		packages = []shippingEntities.Dimensions{{}}
+	}
 
 	toAddress := shippingEntities.Address{
 		StateCode:   address.StateCode,
@@ -733,12 +755,7 @@ func (s *service) GetShippingRate(ctx context.Context, req *entities.GetShipping
 				CountryCode: req.Body.WarehouseAddress.CountryCode,
 			},
 			Destination: toAddress,
-			Dimensions: shippingEntities.Dimensions{
-				Length: maxLength,
-				Width:  maxWidth,
-				Height: totalHeight,
-				Weight: totalWeight,
-			},
+			Packages:    packages,
 		})
 	if err != nil {
 		return nil, err
@@ -753,7 +770,7 @@ func (s *service) GetShippingRate(ctx context.Context, req *entities.GetShipping
 		shippingRates[i] = entities.CartShippingRate{
 			Id:                    uuid.New(),
 			CartID:                cartId,
-			AddressID:             req.Body.AddressID,
+			AddressID:             savedAddressID(address),
 			CarrierName:           estimate.CarrierName,
 			CarrierCode:           estimate.CarrierCode,
 			ServiceType:           estimate.ServiceType,
@@ -770,7 +787,7 @@ func (s *service) GetShippingRate(ctx context.Context, req *entities.GetShipping
 		shippingRates = append(shippingRates, entities.CartShippingRate{
 			Id:        uuid.New(),
 			CartID:    cartId,
-			AddressID: req.Body.AddressID,
+			AddressID: savedAddressID(address),
 			Amount:    decimal.Zero,
 			Currency:  "USD",
 			CreatedAt: time.Now(),
@@ -819,24 +836,22 @@ func (s *service) GetShippingRateByID(ctx context.Context, shippingRateID uuid.U
 //	400: DefaultError Bad Request
 //	500: DefaultError Internal Server Error
 func (s *service) CreateCartShippingRates(ctx context.Context, req *entities.CreateCartShippingRatesRequest) (*entities.GetShippingRateResponse, error) {
-	customerID := sharedMeta.XCustomerID(ctx)
-	if customerID == "" {
-		return nil, moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(req.Body.CartShippingRates) == 0 {
 		return nil, moduleErrors.NewAPIError("CART_SHIPPING_RATES_REQUIRED")
 	}
 
-	address, err := s.addressClient.GetAddress(ctx, &addressEntities.GetAddressRequest{
-		AddressID: req.Body.AddressID,
-	})
+	address, err := s.getAddress(ctx, owner, req.Body.AddressID, req.Body.Address)
 	if err != nil {
 		s.log.Errorf("Error retrieving address: %v", err)
 		return nil, err
 	}
 
-	activeCart, err := s.repo.GetActiveCart(ctx, customerID)
+	activeCart, err := s.getActiveCart(ctx, owner)
 	if err != nil {
 		s.log.Errorf("Error retrieving active cart: %v", err)
 		return nil, err
@@ -851,7 +866,7 @@ func (s *service) CreateCartShippingRates(ctx context.Context, req *entities.Cre
 		shippingRates[i] = entities.CartShippingRate{
 			Id:                    uuid.New(),
 			CartID:                activeCart.Id,
-			AddressID:             address.ID,
+			AddressID:             savedAddressID(address),
 			Amount:                rate.Amount,
 			Currency:              rate.Currency,
 			CarrierName:           rate.CarrierName,
@@ -884,23 +899,35 @@ func (s *service) CreateCartShippingRates(ctx context.Context, req *entities.Cre
 //
 //	200: Empty Shipping rate set successfully
 //	400: DefaultError Bad Request
+//	404: DefaultError Cart item or shipping rate not found
 //	500: DefaultError Internal Server Error
 func (s *service) SetCartItemShippingRate(ctx context.Context, req *entities.SetCartItemShippingRateRequest) error {
-	customerID := sharedMeta.XCustomerID(ctx)
-	if customerID == "" {
-		return moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return err
 	}
 
-	_, err := s.repo.GetCartItemByID(ctx, req.Body.CartItemID)
+	// Retrieve active cart
+	cart, err := s.getActiveCart(ctx, owner)
 	if err != nil {
-		s.log.Errorf("Error retrieving cart item: %v", err)
+		s.log.Errorf("Error retrieving active cart: %v", err)
+		return moduleErrors.NewAPIError("CART_ERROR_GETTING_CART")
+	}
+	if cart == nil {
+		return moduleErrors.NewAPIError("CART_NOT_FOUND")
+	}
+
+	// the item and the rate have to belong to the cart of the customer or guest
+	cartItem, err := s.repo.GetCartItemByID(ctx, req.Body.CartItemID)
+	if err != nil || cartItem.CartID != cart.Id {
+		s.log.Errorf("Error retrieving cart item %s of cart %s: %v", req.Body.CartItemID, cart.Id, err)
 		return moduleErrors.NewAPIError("CART_ITEM_NOT_FOUND")
 	}
 
 	// Validate that the shipping rate exists
-	_, err = s.repo.GetShippingRate(ctx, req.Body.ShippingRateID)
-	if err != nil {
-		s.log.Errorf("Error retrieving shipping rate %s: %v", req.Body.ShippingRateID.String(), err)
+	shippingRate, err := s.repo.GetShippingRate(ctx, req.Body.ShippingRateID)
+	if err != nil || shippingRate.CartID != cart.Id {
+		s.log.Errorf("Error retrieving shipping rate %s of cart %s: %v", req.Body.ShippingRateID.String(), cart.Id, err)
 		return moduleErrors.NewAPIError("CART_SHIPPING_RATE_NOT_FOUND")
 	}
 
@@ -911,26 +938,146 @@ func (s *service) SetCartItemShippingRate(ctx context.Context, req *entities.Set
 		return moduleErrors.NewAPIError("CART_ERROR_UPDATING_SHIPPING_RATE")
 	}
 
-	// Clear cache for this customer since shipping rates changed
-	go func() {
-		bgCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
-		defer cancel()
-		// delete by pattern tax_rate_<any_address_id>_<customer_id>_<any_cart_id>_<any_shipping_rate_id>
-		if err := s.cache.DeleteByPattern(bgCtx, fmt.Sprintf("^tax_rate_[^_]+_%s_", customerID)); err != nil {
-			s.log.Errorf("Error deleting tax rate cache: %v", err)
-		}
-	}()
+	// the tax quotes of the cart were calculated with the shipping rates it had before
+	s.evictTaxRates(ctx, owner.ID(), cart.Id)
 
 	return nil
 }
 
+// swagger:route POST /cart/coupon carts ApplyCouponRequest
+//
+// # Apply Coupon
+// ### Apply a coupon code to the cart, it replaces the coupon code applied before
+//
+// Produces:
+//   - application/json
+//
+// Responses:
+//
+//	200: ApplyCouponResponse Coupon code applied successfully
+//	400: DefaultError Coupon code doesn't apply to the cart
+//	404: DefaultError Coupon code not found
+//	500: DefaultError Internal Server Error
+func (s *service) ApplyCoupon(ctx context.Context, req *entities.ApplyCouponRequest) (*entities.ApplyCouponResponse, error) {
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Body == nil || strings.TrimSpace(req.Body.Code) == "" {
+		return nil, moduleErrors.NewAPIError("CART_COUPON_CODE_REQUIRED")
+	}
+
+	cart, err := s.getActiveCart(ctx, owner)
+	if err != nil {
+		s.log.Errorf("Error retrieving active cart: %v", err)
+		return nil, moduleErrors.NewAPIError("CART_ERROR_GETTING_CART")
+	}
+	if cart == nil {
+		return nil, moduleErrors.NewAPIError("CART_NOT_FOUND")
+	}
+
+	items, err := s.repo.GetCartItems(ctx, cart.Id.String())
+	if err != nil {
+		s.log.Errorf("Error retrieving cart items: %v", err)
+		return nil, moduleErrors.NewAPIError("CART_ERROR_GETTING_CART_ITEMS")
+	}
+	if len(items) == 0 {
+		return nil, moduleErrors.NewAPIError("CART_IS_EMPTY")
+	}
+
+	// the code is checked against the cart before it's kept
+	code := strings.ToUpper(strings.TrimSpace(req.Body.Code))
+	cart.CouponCode = &code
+	summary, err := s.summarize(ctx, owner, cart, items)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateCartCouponCode(ctx, cart.Id, &code); err != nil {
+		s.log.Errorf("Error applying coupon code to cart %s: %v", cart.Id, err)
+		return nil, moduleErrors.NewAPIError("CART_ERROR_UPDATING_COUPON")
+	}
+	s.evictTaxRates(ctx, owner.ID(), cart.Id)
+
+	return &entities.ApplyCouponResponse{
+		Code:      code,
+		Discount:  summary.Discount,
+		Discounts: summary.Discounts,
+	}, nil
+}
+
+// swagger:route DELETE /cart/coupon carts RemoveCoupon
+//
+// # Remove Coupon
+// ### Remove the coupon code applied to the cart
+//
+// Produces:
+//   - application/json
+//
+// Responses:
+//
+//	200: description: Coupon code removed successfully
+//	404: DefaultError Cart not found
+//	500: DefaultError Internal Server Error
+func (s *service) RemoveCoupon(ctx context.Context) error {
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	cart, err := s.getActiveCart(ctx, owner)
+	if err != nil {
+		s.log.Errorf("Error retrieving active cart: %v", err)
+		return moduleErrors.NewAPIError("CART_ERROR_GETTING_CART")
+	}
+	if cart == nil {
+		return moduleErrors.NewAPIError("CART_NOT_FOUND")
+	}
+
+	if err := s.repo.UpdateCartCouponCode(ctx, cart.Id, nil); err != nil {
+		s.log.Errorf("Error removing coupon code from cart %s: %v", cart.Id, err)
+		return moduleErrors.NewAPIError("CART_ERROR_UPDATING_COUPON")
+	}
+	s.evictTaxRates(ctx, owner.ID(), cart.Id)
+
+	return nil
+}
+
+// calculateDiscounts returns what the promotions take off the cart items and the shipping.
+// Guests have no customer ID, the promotions limited per customer don't apply to them.
+func (s *service) calculateDiscounts(ctx context.Context, owner cartOwner, cart *entities.Cart, items []entities.CartItemDetail, shippingAmount decimal.Decimal) (*promotionsEntities.CalculateDiscountsResponse, error) {
+	req := &promotionsEntities.CalculateDiscountsRequest{
+		ShippingAmount: shippingAmount,
+	}
+
+	if !owner.IsGuest() {
+		req.CustomerID = uuid.MustParse(owner.customerID)
+	}
+
+	if cart.CouponCode != nil {
+		req.CouponCode = *cart.CouponCode
+	}
+
+	for _, item := range items {
+		req.Items = append(req.Items, promotionsEntities.DiscountItem{
+			SKU:        item.SKU,
+			Price:      item.Price,
+			Quantity:   item.Quantity,
+			Attributes: item.Attributes,
+		})
+	}
+
+	return s.promotionsClient.CalculateDiscounts(ctx, req)
+}
+
 func (s *service) GetCart(ctx context.Context) (*entities.Cart, error) {
-	customerID := sharedMeta.XCustomerID(ctx)
-	if customerID == "" {
-		return nil, moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	cart, err := s.repo.GetActiveCart(ctx, customerID)
+	cart, err := s.getActiveCart(ctx, owner)
 	if err != nil {
 		s.log.Errorf("Error retrieving active cart: %v", err)
 		return nil, moduleErrors.NewAPIError("CART_ERROR_GETTING_CART")
@@ -939,10 +1086,48 @@ func (s *service) GetCart(ctx context.Context) (*entities.Cart, error) {
 	return cart, nil
 }
 
+// packageItems returns the items to pack with their dimensions, a missing dimension counts as zero.
+func packageItems(items []entities.CartItemDetail) []packaging.Item {
+	packageItems := make([]packaging.Item, 0, len(items))
+	for _, item := range items {
+		packageItem := packaging.Item{Quantity: item.Quantity}
+		if item.Length != nil {
+			packageItem.Length = *item.Length
+		}
+		if item.Width != nil {
+			packageItem.Width = *item.Width
+		}
+		if item.Height != nil {
+			packageItem.Height = *item.Height
+		}
+		if item.Weight != nil {
+			packageItem.Weight = *item.Weight
+		}
+		packageItems = append(packageItems, packageItem)
+	}
+
+	return packageItems
+}
+
 func getShippingRateCacheKey(addressID, customerID, cartID string) string {
 	return fmt.Sprintf("shipping_rate_%s_%s_%s", addressID, customerID, cartID)
 }
 
+// cachedTaxRate is the tax quote kept in the cache, its breakdown is written to the cart on a cache hit.
+type cachedTaxRate struct {
+	Response  entities.GetTaxRateResponse `json:"response"`
+	Breakdown sharedJson.JSON             `json:"breakdown,omitempty"`
+}
+
+// evictTaxRates deletes the tax quotes cached for the cart, they were calculated for the cart before it changed.
+// It's done before returning so that the next quote doesn't find them.
+func (s *service) evictTaxRates(ctx context.Context, ownerID string, cartID uuid.UUID) {
+	// delete by pattern tax_rate_<any_address_id>_<owner_id>_<cart_id>, whatever the shipping rates and coupon code
+	if err := s.cache.DeleteByPattern(ctx, fmt.Sprintf("^tax_rate_[^_]+_%s_%s", ownerID, cartID.String())); err != nil {
+		s.log.Errorf("Error deleting tax rate cache: %v", err)
+	}
+}
+
 func getTaxRateCacheKey(addressID, customerID, cartID string, shippingRateIDs string) string {
 	if shippingRateIDs != "" {
 		return fmt.Sprintf("tax_rate_%s_%s_%s_%s", addressID, customerID, cartID, shippingRateIDs)
@@ -14,10 +14,18 @@ import (
 	addressclient "github.com/nurdsoft/nurd-commerce-core/internal/address/addressclient"
 	addressEntities "github.com/nurdsoft/nurd-commerce-core/internal/address/entities"
 	"github.com/nurdsoft/nurd-commerce-core/internal/cart/entities"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/cart/errors"
 	repository "github.com/nurdsoft/nurd-commerce-core/internal/cart/repository"
+	promotionsEntities "github.com/nurdsoft/nurd-commerce-core/internal/promotions/entities"
+	promotionsErrors "github.com/nurdsoft/nurd-commerce-core/internal/promotions/errors"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/promotionsclient"
 	"github.com/nurdsoft/nurd-commerce-core/shared/cache"
+	sharedErrors "github.com/nurdsoft/nurd-commerce-core/shared/errors"
 	sharedJson "github.com/nurdsoft/nurd-commerce-core/shared/json"
 	sharedMeta "github.com/nurdsoft/nurd-commerce-core/shared/meta"
+	shipping "github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/client"
+	shippingEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/entities"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/packaging"
 	taxes "github.com/nurdsoft/nurd-commerce-core/shared/vendors/taxes"
 	taxesEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/taxes/entities"
 	taxesProvider "github.com/nurdsoft/nurd-commerce-core/shared/vendors/taxes/providers"
@@ -27,36 +35,52 @@ import (
 )
 
 type testDeps struct {
-	mockRepo    *repository.MockRepository
-	mockTaxes   *taxes.MockClient
-	mockCache   *cache.MockCache
-	mockAddress *addressclient.MockClient
+	mockRepo       *repository.MockRepository
+	mockTaxes      *taxes.MockClient
+	mockCache      *cache.MockCache
+	mockAddress    *addressclient.MockClient
+	mockShipping   *shipping.MockClient
+	mockPromotions *promotionsclient.MockClient
 }
 
 func newServiceForTest(t *testing.T) (*service, *testDeps) {
 	ctrl := gomock.NewController(t)
 
 	deps := &testDeps{
-		mockRepo:    repository.NewMockRepository(ctrl),
-		mockTaxes:   taxes.NewMockClient(ctrl),
-		mockCache:   cache.NewMockCache(ctrl),
-		mockAddress: addressclient.NewMockClient(ctrl),
+		mockRepo:       repository.NewMockRepository(ctrl),
+		mockTaxes:      taxes.NewMockClient(ctrl),
+		mockCache:      cache.NewMockCache(ctrl),
+		mockAddress:    addressclient.NewMockClient(ctrl),
+		mockShipping:   shipping.NewMockClient(ctrl),
+		mockPromotions: promotionsclient.NewMockClient(ctrl),
 	}
 
 	logger, _ := zap.NewDevelopment()
 	svc := &service{
-		repo:           deps.mockRepo,
-		log:            logger.Sugar(),
-		shippingClient: nil,
-		taxesClient:    deps.mockTaxes,
-		cache:          deps.mockCache,
-		productClient:  nil,
-		addressClient:  deps.mockAddress,
+		repo:             deps.mockRepo,
+		log:              logger.Sugar(),
+		shippingClient:   deps.mockShipping,
+		taxesClient:      deps.mockTaxes,
+		cache:            deps.mockCache,
+		productClient:    nil,
+		addressClient:    deps.mockAddress,
+		promotionsClient: deps.mockPromotions,
+		packer: packaging.New(packaging.Config{
+			Boxes: []packaging.Box{
+				{Name: "small", Length: 10, Width: 10, Height: 10, MaxWeight: 20},
+				{Name: "large", Length: 20, Width: 20, Height: 20, MaxWeight: 50},
+			},
+		}),
 	}
 
 	return svc, deps
 }
 
+// noDiscounts is what the promotions return for a cart none of them applies to
+func noDiscounts(items int) *promotionsEntities.CalculateDiscountsResponse {
+	return &promotionsEntities.CalculateDiscountsResponse{ItemDiscounts: make([]decimal.Decimal, items)}
+}
+
 func TestGetTaxRate_WithOrderLevelShippingRate_UpdatesCartAndReturnsResponse(t *testing.T) {
 	s, d := newServiceForTest(t)
 
@@ -89,6 +113,8 @@ func TestGetTaxRate_WithOrderLevelShippingRate_UpdatesCartAndReturnsResponse(t *
 	d.mockRepo.EXPECT().SetCartItemShippingRate(ctx, items[0].ID, shippingRateID).Return(nil)
 	d.mockRepo.EXPECT().SetCartItemShippingRate(ctx, items[1].ID, shippingRateID).Return(nil)
 
+	d.mockPromotions.EXPECT().CalculateDiscounts(ctx, gomock.Any()).Return(noDiscounts(2), nil)
+
 	d.mockTaxes.EXPECT().GetProvider().Return(taxesProvider.ProviderTaxJar).Times(4) // 2 times per item
 
 	// Taxes call with shipping amount 10, returns tax in minor units
@@ -165,6 +191,8 @@ func TestGetTaxRate_PerItemShipping_SumsUniqueRates(t *testing.T) {
 		GetShippingRate(ctx, rateB).
 		Return(&entities.CartShippingRate{Id: rateB, Amount: decimal.NewFromInt(7)}, nil)
 
+	d.mockPromotions.EXPECT().CalculateDiscounts(ctx, gomock.Any()).Return(noDiscounts(2), nil)
+
 	d.mockTaxes.EXPECT().GetProvider().Return(taxesProvider.ProviderTaxJar).Times(4) // 2 times per item
 
 	d.mockTaxes.EXPECT().
@@ -230,6 +258,8 @@ func TestGetTaxRate_PerItemShipping_SameRateForAllItems(t *testing.T) {
 		GetShippingRate(ctx, rate).
 		Return(&entities.CartShippingRate{Id: rate, Amount: decimal.NewFromInt(5)}, nil)
 
+	d.mockPromotions.EXPECT().CalculateDiscounts(ctx, gomock.Any()).Return(noDiscounts(2), nil)
+
 	d.mockTaxes.EXPECT().GetProvider().Return(taxesProvider.ProviderTaxJar).Times(4) // 2 times per item
 
 	d.mockTaxes.EXPECT().
@@ -307,10 +337,16 @@ func TestGetTaxRate_CacheHit(t *testing.T) {
 		ShippingRate: decimal.NewFromFloat(4.44),
 		Currency:     "USD",
 	}
-	b, _ := json.Marshal(cached)
+	breakdown := sharedJson.JSON([]byte(`{"ok":true}`))
+	b, _ := json.Marshal(cachedTaxRate{Response: cached, Breakdown: breakdown})
 	expectedKey := getTaxRateCacheKey(addressID.String(), customerID, cartID.String(), shippingRateID.String())
 	d.mockCache.EXPECT().Get(ctx, expectedKey).Return(b, nil)
 
+	// the cart is charged the cached tax
+	d.mockRepo.EXPECT().
+		UpdateCartTaxRate(ctx, cartID.String(), cached.Tax, "USD", breakdown).
+		Return(nil)
+
 	req := &entities.GetTaxRateRequest{
 		Body: &entities.GetTaxRateRequestBody{
 			AddressID: addressID,
@@ -348,6 +384,8 @@ func TestGetTaxRate_NoShippingRates_ShippingZero(t *testing.T) {
 	// Cache miss
 	d.mockCache.EXPECT().Get(ctx, gomock.Any()).Return(nil, assert.AnError)
 
+	d.mockPromotions.EXPECT().CalculateDiscounts(ctx, gomock.Any()).Return(noDiscounts(2), nil)
+
 	d.mockTaxes.EXPECT().GetProvider().Return(taxesProvider.ProviderTaxJar).Times(4) // 2 times per item
 
 	// Taxes client with zero shipping
@@ -383,33 +421,383 @@ func TestGetTaxRate_NoShippingRates_ShippingZero(t *testing.T) {
 	assert.True(t, resp.ShippingRate.Equal(decimal.Zero))
 }
 
+func TestGetTaxRate_WithCoupon_TaxesDiscountedAmounts(t *testing.T) {
+	s, d := newServiceForTest(t)
+
+	customerID := uuid.New().String()
+	addressID := uuid.New()
+	cartID := uuid.New()
+	rate := uuid.New()
+	code := "SUMMER10"
+
+	ctx := sharedMeta.WithXCustomerID(context.Background(), customerID)
+
+	d.mockAddress.EXPECT().
+		GetAddress(ctx, &addressEntities.GetAddressRequest{AddressID: addressID}).
+		Return(&addressEntities.Address{StateCode: "CA", CountryCode: "US", PostalCode: "90000"}, nil)
+
+	d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(&entities.Cart{Id: cartID, CouponCode: &code}, nil)
+	items := []entities.CartItemDetail{
+		{ID: uuid.New(), CartID: cartID, SKU: "A", Quantity: 1, Price: decimal.NewFromInt(60), ShippingRateID: &rate},
+		{ID: uuid.New(), CartID: cartID, SKU: "B", Quantity: 2, Price: decimal.NewFromInt(20), ShippingRateID: &rate},
+	}
+	d.mockRepo.EXPECT().GetCartItems(ctx, cartID.String()).Return(items, nil)
+
+	// the coupon code is part of the cache key
+	expectedKey := getTaxRateCacheKey(addressID.String(), customerID, cartID.String(), rate.String()) + "_" + code
+	d.mockCache.EXPECT().Get(ctx, expectedKey).Return(nil, assert.AnError)
+
+	d.mockRepo.EXPECT().
+		GetShippingRate(ctx, rate).
+		Return(&entities.CartShippingRate{Id: rate, Amount: decimal.NewFromInt(5)}, nil)
+
+	discounts := []*promotionsEntities.Discount{
+		{PromotionID: uuid.New(), Code: &code, Name: "Summer", DiscountType: promotionsEntities.PercentOff, Amount: decimal.NewFromInt(10)},
+		{PromotionID: uuid.New(), Name: "Free shipping", DiscountType: promotionsEntities.FreeShipping, Amount: decimal.NewFromInt(5)},
+	}
+	d.mockPromotions.EXPECT().
+		CalculateDiscounts(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, req *promotionsEntities.CalculateDiscountsRequest) (*promotionsEntities.CalculateDiscountsResponse, error) {
+			assert.Equal(t, code, req.CouponCode)
+			assert.Equal(t, customerID, req.CustomerID.String())
+			assert.Len(t, req.Items, 2)
+			assert.True(t, req.ShippingAmount.Equal(decimal.NewFromInt(5)))
+
+			return &promotionsEntities.CalculateDiscountsResponse{
+				ItemDiscounts:    []decimal.Decimal{decimal.NewFromInt(6), decimal.NewFromInt(4)},
+				ItemsDiscount:    decimal.NewFromInt(10),
+				ShippingDiscount: decimal.NewFromInt(5),
+				Discounts:        discounts,
+			}, nil
+		})
+
+	d.mockTaxes.EXPECT().GetProvider().Return(taxesProvider.ProviderTaxJar).Times(4) // 2 times per item
+
+	d.mockTaxes.EXPECT().
+		CalculateTax(ctx, gomock.Any()).
+		Do(func(_ context.Context, req *taxesEntities.CalculateTaxRequest) {
+			assert.True(t, req.ShippingAmount.IsZero())
+			assert.True(t, req.TaxItems[0].Discount.Equal(decimal.NewFromInt(6)))
+			assert.True(t, req.TaxItems[1].Discount.Equal(decimal.NewFromInt(4)))
+		}).
+		Return(&taxesEntities.CalculateTaxResponse{
+			Tax:         decimal.NewFromFloat(9.00),
+			TotalAmount: decimal.NewFromFloat(90.00),
+			Currency:    "USD",
+		}, nil)
+
+	d.mockRepo.EXPECT().
+		UpdateCartTaxRate(ctx, cartID.String(), decimal.NewFromFloat(9.00), "USD", gomock.Any()).
+		Return(nil)
+
+	d.mockCache.EXPECT().Set(ctx, expectedKey, gomock.Any(), gomock.Any()).Return(nil)
+
+	req := &entities.GetTaxRateRequest{
+		Body: &entities.GetTaxRateRequestBody{
+			AddressID: addressID,
+		},
+	}
+	resp, err := s.GetTaxRate(ctx, req)
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Subtotal.Equal(decimal.NewFromInt(100)))
+	assert.True(t, resp.ShippingRate.Equal(decimal.NewFromInt(5)))
+	assert.True(t, resp.Discount.Equal(decimal.NewFromInt(15)))
+	assert.Equal(t, discounts, resp.Discounts)
+}
+
+func TestApplyCoupon(t *testing.T) {
+	customerID := uuid.New().String()
+	cartID := uuid.New()
+	ctx := sharedMeta.WithXCustomerID(context.Background(), customerID)
+	items := []entities.CartItemDetail{
+		{ID: uuid.New(), CartID: cartID, SKU: "A", Quantity: 1, Price: decimal.NewFromInt(60)},
+	}
+
+	t.Run("coupon code applied", func(t *testing.T) {
+		s, d := newServiceForTest(t)
+
+		d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(&entities.Cart{Id: cartID}, nil)
+		d.mockRepo.EXPECT().GetCartItems(ctx, cartID.String()).Return(items, nil)
+		d.mockPromotions.EXPECT().
+			CalculateDiscounts(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, req *promotionsEntities.CalculateDiscountsRequest) (*promotionsEntities.CalculateDiscountsResponse, error) {
+				assert.Equal(t, "SUMMER10", req.CouponCode)
+				return &promotionsEntities.CalculateDiscountsResponse{
+					ItemDiscounts: []decimal.Decimal{decimal.NewFromInt(6)},
+					ItemsDiscount: decimal.NewFromInt(6),
+					Discounts:     []*promotionsEntities.Discount{{Name: "Summer", Amount: decimal.NewFromInt(6)}},
+				}, nil
+			})
+		code := "SUMMER10"
+		d.mockRepo.EXPECT().UpdateCartCouponCode(ctx, cartID, &code).Return(nil)
+		// the taxes quoted without the coupon code are evicted
+		d.mockCache.EXPECT().DeleteByPattern(ctx, fmt.Sprintf("^tax_rate_[^_]+_%s_%s", customerID, cartID)).Return(nil)
+
+		resp, err := s.ApplyCoupon(ctx, &entities.ApplyCouponRequest{Body: &entities.ApplyCouponRequestBody{Code: " summer10 "}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, code, resp.Code)
+		assert.True(t, resp.Discount.Equal(decimal.NewFromInt(6)))
+		assert.Len(t, resp.Discounts, 1)
+	})
+
+	t.Run("coupon code that doesn't apply isn't kept", func(t *testing.T) {
+		s, d := newServiceForTest(t)
+
+		d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(&entities.Cart{Id: cartID}, nil)
+		d.mockRepo.EXPECT().GetCartItems(ctx, cartID.String()).Return(items, nil)
+		d.mockPromotions.EXPECT().
+			CalculateDiscounts(ctx, gomock.Any()).
+			Return(nil, promotionsErrors.NewAPIError("PROMOTION_NOT_APPLICABLE"))
+
+		_, err := s.ApplyCoupon(ctx, &entities.ApplyCouponRequest{Body: &entities.ApplyCouponRequestBody{Code: "SHOES20"}})
+
+		assert.Equal(t, promotionsErrors.NewAPIError("PROMOTION_NOT_APPLICABLE"), err)
+	})
+
+	t.Run("empty cart", func(t *testing.T) {
+		s, d := newServiceForTest(t)
+
+		d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(&entities.Cart{Id: cartID}, nil)
+		d.mockRepo.EXPECT().GetCartItems(ctx, cartID.String()).Return(nil, nil)
+
+		_, err := s.ApplyCoupon(ctx, &entities.ApplyCouponRequest{Body: &entities.ApplyCouponRequestBody{Code: "SUMMER10"}})
+
+		assert.Equal(t, moduleErrors.NewAPIError("CART_IS_EMPTY"), err)
+	})
+
+	t.Run("code required", func(t *testing.T) {
+		s, _ := newServiceForTest(t)
+
+		_, err := s.ApplyCoupon(ctx, &entities.ApplyCouponRequest{Body: &entities.ApplyCouponRequestBody{Code: " "}})
+
+		assert.Equal(t, moduleErrors.NewAPIError("CART_COUPON_CODE_REQUIRED"), err)
+	})
+}
+
+func TestRemoveCoupon(t *testing.T) {
+	s, d := newServiceForTest(t)
+
+	customerID := uuid.New().String()
+	cartID := uuid.New()
+	ctx := sharedMeta.WithXCustomerID(context.Background(), customerID)
+
+	d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(&entities.Cart{Id: cartID}, nil)
+	d.mockRepo.EXPECT().UpdateCartCouponCode(ctx, cartID, nil).Return(nil)
+	d.mockCache.EXPECT().DeleteByPattern(ctx, fmt.Sprintf("^tax_rate_[^_]+_%s_%s", customerID, cartID)).Return(nil)
+
+	err := s.RemoveCoupon(ctx)
+
+	assert.NoError(t, err)
+}
+
+func TestGetCartSummary(t *testing.T) {
+	customerID := uuid.New().String()
+	cartID := uuid.New()
+	ctx := sharedMeta.WithXCustomerID(context.Background(), customerID)
+
+	t.Run("totals the discounted items with shipping and tax", func(t *testing.T) {
+		s, d := newServiceForTest(t)
+
+		code := "SUMMER10"
+		rate := uuid.New()
+		items := []entities.CartItemDetail{
+			{ID: uuid.New(), CartID: cartID, SKU: "A", Quantity: 1, Price: decimal.NewFromInt(60), CurrentPrice: decimal.NewFromInt(60), ShippingRateID: &rate},
+			{ID: uuid.New(), CartID: cartID, SKU: "B", Quantity: 2, Price: decimal.NewFromInt(20), CurrentPrice: decimal.NewFromInt(20), ShippingRateID: &rate},
+		}
+
+		d.mockRepo.EXPECT().
+			GetActiveCart(ctx, customerID).
+			Return(&entities.Cart{Id: cartID, CouponCode: &code, TaxAmount: decimal.NewFromInt(9), TaxCurrency: "USD"}, nil)
+		d.mockRepo.EXPECT().GetCartItems(ctx, cartID.String()).Return(items, nil)
+		// the rate shared by both items is looked up and paid once
+		d.mockRepo.EXPECT().
+			GetShippingRate(ctx, rate).
+			Return(&entities.CartShippingRate{Id: rate, Amount: decimal.NewFromInt(5)}, nil)
+		d.mockPromotions.EXPECT().
+			CalculateDiscounts(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, req *promotionsEntities.CalculateDiscountsRequest) (*promotionsEntities.CalculateDiscountsResponse, error) {
+				assert.Equal(t, code, req.CouponCode)
+				assert.True(t, req.ShippingAmount.Equal(decimal.NewFromInt(5)))
+				return &promotionsEntities.CalculateDiscountsResponse{
+					ItemDiscounts: []decimal.Decimal{decimal.NewFromInt(6), decimal.NewFromInt(4)},
+					ItemsDiscount: decimal.NewFromInt(10),
+					Discounts:     []*promotionsEntities.Discount{{Code: &code, Name: "Summer", Amount: decimal.NewFromInt(10)}},
+				}, nil
+			})
+
+		summary, err := s.GetCartSummary(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, cartID, summary.CartID)
+		assert.Len(t, summary.Items, 2)
+		assert.True(t, summary.Items[0].LineTotal.Equal(decimal.NewFromInt(54)))
+		assert.True(t, summary.Items[1].LineTotal.Equal(decimal.NewFromInt(36)))
+		assert.Equal(t, rate, summary.Items[1].ShippingRate.Id)
+		assert.True(t, summary.Subtotal.Equal(decimal.NewFromInt(100)))
+		assert.True(t, summary.ShippingAmount.Equal(decimal.NewFromInt(5)))
+		assert.True(t, summary.Discount.Equal(decimal.NewFromInt(10)))
+		assert.Len(t, summary.Discounts, 1)
+		// 100 - 10 + 5 + 9
+		assert.True(t, summary.Total.Equal(decimal.NewFromInt(104)))
+		assert.Equal(t, "USD", summary.Currency)
+	})
+
+	t.Run("tax not calculated since the cart changed", func(t *testing.T) {
+		s, d := newServiceForTest(t)
+
+		items := []entities.CartItemDetail{
+			{ID: uuid.New(), CartID: cartID, SKU: "A", Quantity: 3, Price: decimal.NewFromInt(10), Currency: "EUR", CurrentPrice: decimal.NewFromInt(10), CurrentCurrency: "EUR"},
+		}
+
+		// the tax of the cart is cleared when it changes
+		d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(&entities.Cart{Id: cartID}, nil)
+		d.mockRepo.EXPECT().GetCartItems(ctx, cartID.String()).Return(items, nil)
+
+		_, err := s.GetCartSummary(ctx)
+
+		assert.Equal(t, moduleErrors.NewAPIError("CART_TAX_NOT_CALCULATED"), err)
+	})
+
+	t.Run("prices changed since the items were added", func(t *testing.T) {
+		s, d := newServiceForTest(t)
+
+		changedItemID := uuid.New()
+		items := []entities.CartItemDetail{
+			{ID: uuid.New(), CartID: cartID, SKU: "A", Quantity: 1, Price: decimal.NewFromInt(60), Currency: "USD", CurrentPrice: decimal.NewFromInt(60), CurrentCurrency: "USD"},
+			{ID: changedItemID, CartID: cartID, SKU: "B", Quantity: 2, Price: decimal.NewFromInt(20), Currency: "USD", CurrentPrice: decimal.NewFromInt(25), CurrentCurrency: "USD"},
+		}
+
+		d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(&entities.Cart{Id: cartID}, nil)
+		d.mockRepo.EXPECT().GetCartItems(ctx, cartID.String()).Return(items, nil)
+
+		_, err := s.GetCartSummary(ctx)
+
+		apiErr, ok := err.(*sharedErrors.APIError)
+		assert.True(t, ok)
+		assert.Equal(t, "CART_PRICES_CHANGED", apiErr.ErrorCode)
+		assert.Equal(t, []*entities.CartPriceChange{{
+			ItemID:      changedItemID,
+			SKU:         "B",
+			OldPrice:    decimal.NewFromInt(20),
+			OldCurrency: "USD",
+			NewPrice:    decimal.NewFromInt(25),
+			NewCurrency: "USD",
+		}}, apiErr.Details)
+	})
+
+	t.Run("empty cart", func(t *testing.T) {
+		s, d := newServiceForTest(t)
+
+		d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(nil, nil)
+
+		_, err := s.GetCartSummary(ctx)
+
+		assert.Equal(t, moduleErrors.NewAPIError("CART_IS_EMPTY"), err)
+	})
+
+	t.Run("guest cart", func(t *testing.T) {
+		s, d := newServiceForTest(t)
+		cartToken := uuid.New().String()
+		guestCtx := sharedMeta.WithXCartToken(context.Background(), cartToken)
+
+		items := []entities.CartItemDetail{
+			{ID: uuid.New(), CartID: cartID, SKU: "A", Quantity: 1, Price: decimal.NewFromInt(60), Currency: "USD", CurrentPrice: decimal.NewFromInt(60), CurrentCurrency: "USD"},
+		}
+
+		d.mockRepo.EXPECT().GetGuestCart(guestCtx, cartToken).Return(&entities.Cart{Id: cartID, TaxAmount: decimal.Zero, TaxCurrency: "USD"}, nil)
+		d.mockRepo.EXPECT().GetCartItems(guestCtx, cartID.String()).Return(items, nil)
+		d.mockPromotions.EXPECT().
+			CalculateDiscounts(guestCtx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, req *promotionsEntities.CalculateDiscountsRequest) (*promotionsEntities.CalculateDiscountsResponse, error) {
+				// the promotions limited per customer don't apply to guests
+				assert.Equal(t, uuid.Nil, req.CustomerID)
+				return noDiscounts(1), nil
+			})
+
+		summary, err := s.GetCartSummary(guestCtx)
+
+		assert.NoError(t, err)
+		assert.True(t, summary.Total.Equal(decimal.NewFromInt(60)))
+	})
+
+	t.Run("customer id or cart token required", func(t *testing.T) {
+		s, _ := newServiceForTest(t)
+
+		_, err := s.GetCartSummary(context.Background())
+
+		assert.Equal(t, moduleErrors.NewAPIError("CART_OWNER_REQUIRED"), err)
+	})
+}
+
+func TestAcceptCartPrices(t *testing.T) {
+	customerID := uuid.New().String()
+	cartID := uuid.New()
+	ctx := sharedMeta.WithXCustomerID(context.Background(), customerID)
+
+	t.Run("items take the prices of their variants now", func(t *testing.T) {
+		s, d := newServiceForTest(t)
+
+		items := []entities.CartItemDetail{
+			{ID: uuid.New(), CartID: cartID, SKU: "B", Quantity: 2, Price: decimal.NewFromInt(25), Currency: "USD", CurrentPrice: decimal.NewFromInt(25), CurrentCurrency: "USD"},
+		}
+		deleted := make(chan string, 1)
+
+		d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(&entities.Cart{Id: cartID}, nil)
+		d.mockRepo.EXPECT().UpdateCartItemPrices(ctx, cartID).Return(nil)
+		d.mockCache.EXPECT().
+			DeleteByPattern(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, pattern string) error {
+				deleted <- pattern
+				return nil
+			})
+		d.mockRepo.EXPECT().GetCartItems(ctx, cartID.String()).Return(items, nil)
+
+		resp, err := s.AcceptCartPrices(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, items, resp.Items)
+		// the taxes cached for the cart are evicted
+		assert.Equal(t, fmt.Sprintf("^tax_rate_[^_]+_%s_%s", customerID, cartID), <-deleted)
+	})
+
+	t.Run("cart not found", func(t *testing.T) {
+		s, d := newServiceForTest(t)
+
+		d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(nil, nil)
+
+		_, err := s.AcceptCartPrices(ctx)
+
+		assert.Equal(t, moduleErrors.NewAPIError("CART_NOT_FOUND"), err)
+	})
+}
+
 func TestSetCartItemShippingRate_Success(t *testing.T) {
 	s, d := newServiceForTest(t)
 
 	customerID := uuid.New().String()
+	cartID := uuid.New()
 	shippingRateID := uuid.New()
 	cartItemID := uuid.New()
 
 	ctx := sharedMeta.WithXCustomerID(context.Background(), customerID)
 
+	d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(&entities.Cart{Id: cartID}, nil)
+
 	d.mockRepo.EXPECT().
 		GetCartItemByID(ctx, cartItemID).
-		Return(&entities.CartItem{ID: cartItemID, CartID: uuid.New()}, nil)
+		Return(&entities.CartItem{ID: cartItemID, CartID: cartID}, nil)
 
 	d.mockRepo.EXPECT().GetShippingRate(ctx, shippingRateID).
-		Return(&entities.CartShippingRate{Id: shippingRateID, Amount: decimal.NewFromInt(10)}, nil)
+		Return(&entities.CartShippingRate{Id: shippingRateID, CartID: cartID, Amount: decimal.NewFromInt(10)}, nil)
 
 	d.mockRepo.EXPECT().SetCartItemShippingRate(ctx, cartItemID, shippingRateID).Return(nil)
 
-	deleteCacheCallDone := make(chan struct{})
-	// Check that the cache was cleared
+	// Check that the tax quotes of the cart were cleared
 	d.mockCache.EXPECT().
-		DeleteByPattern(gomock.Any(), gomock.Any()).
-		DoAndReturn(func(_ context.Context, pattern string) error {
-			defer close(deleteCacheCallDone)
-			assert.Equal(t, fmt.Sprintf("^tax_rate_[^_]+_%s_", customerID), pattern)
-			return nil
-		})
+		DeleteByPattern(ctx, fmt.Sprintf("^tax_rate_[^_]+_%s_%s", customerID, cartID.String())).
+		Return(nil)
 
 	req := &entities.SetCartItemShippingRateRequest{
 		Body: &entities.SetCartItemShippingRateRequestBody{
@@ -421,7 +809,145 @@ func TestSetCartItemShippingRate_Success(t *testing.T) {
 	err := s.SetCartItemShippingRate(ctx, req)
 
 	assert.NoError(t, err)
-	<-deleteCacheCallDone
+}
+
+func TestSetCartItemShippingRate_ItemOfAnotherCart(t *testing.T) {
+	s, d := newServiceForTest(t)
+
+	customerID := uuid.New().String()
+	cartItemID := uuid.New()
+
+	ctx := sharedMeta.WithXCustomerID(context.Background(), customerID)
+
+	d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(&entities.Cart{Id: uuid.New()}, nil)
+	d.mockRepo.EXPECT().
+		GetCartItemByID(ctx, cartItemID).
+		Return(&entities.CartItem{ID: cartItemID, CartID: uuid.New()}, nil)
+
+	err := s.SetCartItemShippingRate(ctx, &entities.SetCartItemShippingRateRequest{
+		Body: &entities.SetCartItemShippingRateRequestBody{
+			CartItemID:     cartItemID,
+			ShippingRateID: uuid.New(),
+		},
+	})
+
+	assert.Equal(t, moduleErrors.NewAPIError("CART_ITEM_NOT_FOUND"), err)
+}
+
+func TestSetCartItemShippingRate_RateOfAnotherCart(t *testing.T) {
+	s, d := newServiceForTest(t)
+
+	customerID := uuid.New().String()
+	cartID := uuid.New()
+	shippingRateID := uuid.New()
+	cartItemID := uuid.New()
+
+	ctx := sharedMeta.WithXCustomerID(context.Background(), customerID)
+
+	d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(&entities.Cart{Id: cartID}, nil)
+	d.mockRepo.EXPECT().
+		GetCartItemByID(ctx, cartItemID).
+		Return(&entities.CartItem{ID: cartItemID, CartID: cartID}, nil)
+	d.mockRepo.EXPECT().GetShippingRate(ctx, shippingRateID).
+		Return(&entities.CartShippingRate{Id: shippingRateID, CartID: uuid.New()}, nil)
+
+	err := s.SetCartItemShippingRate(ctx, &entities.SetCartItemShippingRateRequest{
+		Body: &entities.SetCartItemShippingRateRequestBody{
+			CartItemID:     cartItemID,
+			ShippingRateID: shippingRateID,
+		},
+	})
+
+	assert.Equal(t, moduleErrors.NewAPIError("CART_SHIPPING_RATE_NOT_FOUND"), err)
+}
+
+func TestGetShippingRate_PacksItemsInBoxes(t *testing.T) {
+	s, d := newServiceForTest(t)
+
+	customerID := uuid.New().String()
+	addressID := uuid.New()
+	cartID := uuid.New()
+
+	ctx := sharedMeta.WithXCustomerID(context.Background(), customerID)
+
+	d.mockAddress.EXPECT().
+		GetAddress(ctx, &addressEntities.GetAddressRequest{AddressID: addressID}).
+		Return(&addressEntities.Address{ID: addressID, StateCode: "NY", CountryCode: "US", PostalCode: "10001"}, nil)
+
+	d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(&entities.Cart{Id: cartID}, nil)
+	side, heavy, light := decimal.NewFromInt(10), decimal.NewFromInt(15), decimal.NewFromInt(10)
+	d.mockRepo.EXPECT().GetCartItems(ctx, cartID.String()).Return([]entities.CartItemDetail{
+		{ID: uuid.New(), CartID: cartID, SKU: "SKU1", Quantity: 3, Length: &side, Width: &side, Height: &side, Weight: &heavy},
+		{ID: uuid.New(), CartID: cartID, SKU: "SKU2", Quantity: 1, Length: &side, Width: &side, Height: &side, Weight: &light},
+		// digital goods aren't packed
+		{ID: uuid.New(), CartID: cartID, SKU: "SKU3", Quantity: 1},
+	}, nil)
+
+	d.mockCache.EXPECT().Get(ctx, getShippingRateCacheKey(addressID.String(), customerID, cartID.String())).Return(nil, assert.AnError)
+
+	// three of the heavy items fill the large box, the last item goes in a small one
+	d.mockShipping.EXPECT().
+		GetShippingRates(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, shipment shippingEntities.Shipment) ([]shippingEntities.ShippingRate, error) {
+			assert.Len(t, shipment.Packages, 2)
+			assert.True(t, shipment.Packages[0].Length.Equal(decimal.NewFromInt(20)))
+			assert.True(t, shipment.Packages[0].Weight.Equal(decimal.NewFromInt(45)))
+			assert.True(t, shipment.Packages[1].Length.Equal(decimal.NewFromInt(10)))
+			assert.True(t, shipment.Packages[1].Weight.Equal(decimal.NewFromInt(10)))
+
+			return []shippingEntities.ShippingRate{
+				{Amount: decimal.NewFromFloat(18.40), Currency: "USD", CarrierName: "UPS", ServiceCode: "ups_ground"},
+			}, nil
+		})
+
+	d.mockRepo.EXPECT().CreateCartShippingRates(ctx, gomock.Any()).Return(nil)
+	d.mockCache.EXPECT().Set(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	resp, err := s.GetShippingRate(ctx, &entities.GetShippingRateRequest{
+		Body: &entities.GetShippingRateRequestBody{
+			AddressID:        addressID,
+			WarehouseAddress: entities.WarehouseAddress{City: "La Vergne", StateCode: "TN", PostalCode: "37086", CountryCode: "US"},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Rates, 1)
+	assert.True(t, resp.Rates[0].Amount.Equal(decimal.NewFromFloat(18.40)))
+	assert.Equal(t, &addressID, resp.Rates[0].AddressID)
+}
+
+func TestGetShippingRate_TooManyPackages(t *testing.T) {
+	s, d := newServiceForTest(t)
+
+	customerID := uuid.New().String()
+	addressID := uuid.New()
+	cartID := uuid.New()
+
+	ctx := sharedMeta.WithXCustomerID(context.Background(), customerID)
+
+	d.mockAddress.EXPECT().
+		GetAddress(ctx, &addressEntities.GetAddressRequest{AddressID: addressID}).
+		Return(&addressEntities.Address{ID: addressID, StateCode: "NY", CountryCode: "US", PostalCode: "10001"}, nil)
+
+	d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(&entities.Cart{Id: cartID}, nil)
+	side, weight := decimal.NewFromInt(20), decimal.NewFromInt(50)
+	d.mockRepo.EXPECT().GetCartItems(ctx, cartID.String()).Return([]entities.CartItemDetail{
+		{ID: uuid.New(), CartID: cartID, SKU: "SKU1", Quantity: entities.MaxItemQuantity, Length: &side, Width: &side, Height: &side, Weight: &weight},
+	}, nil)
+
+	d.mockCache.EXPECT().Get(ctx, getShippingRateCacheKey(addressID.String(), customerID, cartID.String())).Return(nil, assert.AnError)
+
+	// every item fills a large box, the carriers aren't asked to quote them
+	d.mockShipping.EXPECT().GetShippingRates(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := s.GetShippingRate(ctx, &entities.GetShippingRateRequest{
+		Body: &entities.GetShippingRateRequestBody{
+			AddressID:        addressID,
+			WarehouseAddress: entities.WarehouseAddress{City: "La Vergne", StateCode: "TN", PostalCode: "37086", CountryCode: "US"},
+		},
+	})
+
+	assert.Equal(t, moduleErrors.NewAPIError("CART_TOO_MANY_PACKAGES"), err)
 }
 
 func TestCreateCartShippingRates_Success(t *testing.T) {
@@ -518,3 +1044,173 @@ func TestCreateCartShippingRates_Success(t *testing.T) {
 	assert.Equal(t, resp.Rates[1].EstimatedDeliveryDate, expectedRates[1].EstimatedDeliveryDate)
 	assert.Equal(t, resp.Rates[1].BusinessDaysInTransit, expectedRates[1].BusinessDaysInTransit)
 }
+
+func TestCreateCartShippingRates_GuestAddress(t *testing.T) {
+	s, d := newServiceForTest(t)
+
+	cartToken := uuid.New().String()
+	cartID := uuid.New()
+	ctx := sharedMeta.WithXCartToken(context.Background(), cartToken)
+
+	d.mockRepo.EXPECT().GetGuestCart(ctx, cartToken).Return(&entities.Cart{Id: cartID}, nil)
+	d.mockRepo.EXPECT().CreateCartShippingRates(ctx, gomock.Any()).Return(nil)
+
+	req := &entities.CreateCartShippingRatesRequest{
+		Body: &entities.CreateCartShippingRatesRequestBody{
+			Address: &addressEntities.AddressRequestBody{
+				FullName: "Jane Doe", Address: "1 Main St", StateCode: "CA", CountryCode: "US", PostalCode: "90000",
+			},
+			CartShippingRates: []entities.CartShippingRateRequest{
+				{Amount: decimal.NewFromInt(10), Currency: "USD", CarrierName: "UPS"},
+			},
+		},
+	}
+
+	resp, err := s.CreateCartShippingRates(ctx, req)
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Rates, 1)
+	assert.Equal(t, cartID, resp.Rates[0].CartID)
+	// the address of a guest isn't saved
+	assert.Nil(t, resp.Rates[0].AddressID)
+}
+
+func TestCreateCartShippingRates_GuestAddressRequired(t *testing.T) {
+	s, _ := newServiceForTest(t)
+
+	ctx := sharedMeta.WithXCartToken(context.Background(), uuid.New().String())
+
+	_, err := s.CreateCartShippingRates(ctx, &entities.CreateCartShippingRatesRequest{
+		Body: &entities.CreateCartShippingRatesRequestBody{
+			CartShippingRates: []entities.CartShippingRateRequest{{Amount: decimal.NewFromInt(10), Currency: "USD"}},
+		},
+	})
+
+	assert.Equal(t, moduleErrors.NewAPIError("CART_ADDRESS_REQUIRED"), err)
+}
+
+func TestUpdateCartItem_GuestCartNotFound(t *testing.T) {
+	s, d := newServiceForTest(t)
+	ctrl := gomock.NewController(t)
+
+	cartToken := uuid.New().String()
+	ctx := sharedMeta.WithXCartToken(context.Background(), cartToken)
+	tx := repository.NewMockTransaction(ctrl)
+
+	d.mockRepo.EXPECT().BeginTransaction(ctx).Return(tx, nil)
+	d.mockRepo.EXPECT().GetGuestCart(ctx, cartToken).Return(nil, nil)
+	tx.EXPECT().Rollback()
+
+	_, err := s.UpdateCartItem(ctx, &entities.UpdateCartItemRequest{
+		Item: &entities.UpdateCartItemRequestBody{ProductID: uuid.New(), SKU: "A", Quantity: 1},
+	})
+
+	assert.Equal(t, moduleErrors.NewAPIError("CART_NOT_FOUND"), err)
+}
+
+func TestUpdateCartItem_InvalidQuantity(t *testing.T) {
+	s, _ := newServiceForTest(t)
+
+	ctx := sharedMeta.WithXCustomerID(context.Background(), uuid.New().String())
+
+	for _, quantity := range []int{-1, entities.MaxItemQuantity + 1} {
+		_, err := s.UpdateCartItem(ctx, &entities.UpdateCartItemRequest{
+			Item: &entities.UpdateCartItemRequestBody{ProductID: uuid.New(), SKU: "A", Quantity: quantity},
+		})
+
+		assert.Equal(t, moduleErrors.NewAPIError("CART_INVALID_QUANTITY", "Quantity should be between 0 and 100."), err)
+	}
+}
+
+func TestCreateGuestCart(t *testing.T) {
+	s, d := newServiceForTest(t)
+
+	d.mockRepo.EXPECT().
+		CreateGuestCart(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, cartToken uuid.UUID) (*entities.Cart, error) {
+			return &entities.Cart{Id: uuid.New(), GuestToken: &cartToken, Status: entities.Active}, nil
+		})
+
+	resp, err := s.CreateGuestCart(context.Background())
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, resp.CartToken)
+}
+
+func TestMergeGuestCart(t *testing.T) {
+	customerID := uuid.New().String()
+	cartToken := uuid.New().String()
+	guestCartID := uuid.New()
+	cartID := uuid.New()
+	ctx := sharedMeta.WithXCartToken(sharedMeta.WithXCustomerID(context.Background(), customerID), cartToken)
+
+	expectCacheEviction := func(d *testDeps) chan string {
+		deleted := make(chan string, 2)
+		d.mockCache.EXPECT().
+			DeleteByPattern(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, pattern string) error {
+				deleted <- pattern
+				return nil
+			}).
+			Times(2)
+		return deleted
+	}
+
+	t.Run("folds the guest cart into the cart of the customer", func(t *testing.T) {
+		s, d := newServiceForTest(t)
+
+		items := []entities.CartItemDetail{{ID: uuid.New(), CartID: cartID, SKU: "A", Quantity: 3}}
+
+		d.mockRepo.EXPECT().GetGuestCart(ctx, cartToken).Return(&entities.Cart{Id: guestCartID}, nil)
+		d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(&entities.Cart{Id: cartID}, nil)
+		d.mockRepo.EXPECT().MergeCarts(ctx, guestCartID, cartID).Return(nil)
+		deleted := expectCacheEviction(d)
+		d.mockRepo.EXPECT().GetCartItems(ctx, cartID.String()).Return(items, nil)
+
+		resp, err := s.MergeGuestCart(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, items, resp.Items)
+		// the rates and taxes cached for the cart of the customer are evicted
+		assert.ElementsMatch(t, []string{
+			fmt.Sprintf("^shipping_rate_[^_]+_%s_%s$", customerID, cartID),
+			fmt.Sprintf("^tax_rate_[^_]+_%s_%s", customerID, cartID),
+		}, []string{<-deleted, <-deleted})
+	})
+
+	t.Run("the guest cart becomes the cart of a customer without one", func(t *testing.T) {
+		s, d := newServiceForTest(t)
+
+		d.mockRepo.EXPECT().GetGuestCart(ctx, cartToken).Return(&entities.Cart{Id: guestCartID}, nil)
+		d.mockRepo.EXPECT().GetActiveCart(ctx, customerID).Return(nil, nil)
+		d.mockRepo.EXPECT().UpdateCartCustomer(ctx, guestCartID, customerID).Return(nil)
+		deleted := expectCacheEviction(d)
+		d.mockRepo.EXPECT().GetCartItems(ctx, guestCartID.String()).Return(nil, nil)
+
+		_, err := s.MergeGuestCart(ctx)
+
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{
+			fmt.Sprintf("^shipping_rate_[^_]+_%s_%s$", customerID, guestCartID),
+			fmt.Sprintf("^tax_rate_[^_]+_%s_%s", customerID, guestCartID),
+		}, []string{<-deleted, <-deleted})
+	})
+
+	t.Run("guest cart not found", func(t *testing.T) {
+		s, d := newServiceForTest(t)
+
+		d.mockRepo.EXPECT().GetGuestCart(ctx, cartToken).Return(nil, nil)
+
+		_, err := s.MergeGuestCart(ctx)
+
+		assert.Equal(t, moduleErrors.NewAPIError("CART_NOT_FOUND"), err)
+	})
+
+	t.Run("cart token required", func(t *testing.T) {
+		s, _ := newServiceForTest(t)
+
+		_, err := s.MergeGuestCart(sharedMeta.WithXCustomerID(context.Background(), customerID))
+
+		assert.Equal(t, moduleErrors.NewAPIError("CART_TOKEN_REQUIRED"), err)
+	})
+}
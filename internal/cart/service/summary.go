@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/cart/entities"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/cart/errors"
+	"github.com/shopspring/decimal"
+)
+
+// swagger:route GET /cart/summary carts GetCartSummary
+//
+// # Get Cart Summary
+// ### Get the items, discounts, shipping, tax and total of the cart, the order placed from the cart is charged the same total
+//
+// Produces:
+//   - application/json
+//
+// Responses:
+//
+//	200: CartSummary Cart summary retrieved successfully
+//	400: DefaultError Cart is empty
+//	409: DefaultError Prices of the items changed, the details list them, or the tax has to be calculated again
+//	500: DefaultError Internal Server Error
+func (s *service) GetCartSummary(ctx context.Context) (*entities.CartSummary, error) {
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := s.getActiveCart(ctx, owner)
+	if err != nil {
+		s.log.Errorf("Error retrieving active cart: %v", err)
+		return nil, moduleErrors.NewAPIError("CART_ERROR_GETTING_CART")
+	}
+	// the cart is created with its first item
+	if cart == nil {
+		return nil, moduleErrors.NewAPIError("CART_IS_EMPTY")
+	}
+
+	items, err := s.repo.GetCartItems(ctx, cart.Id.String())
+	if err != nil {
+		s.log.Errorf("Error retrieving cart items: %v", err)
+		return nil, moduleErrors.NewAPIError("CART_ERROR_GETTING_CART_ITEMS")
+	}
+	if len(items) == 0 {
+		return nil, moduleErrors.NewAPIError("CART_IS_EMPTY")
+	}
+
+	// the customer is charged the prices they were shown, a new price has to be accepted first
+	if changes := priceChanges(items); len(changes) > 0 {
+		apiErr := moduleErrors.NewAPIError("CART_PRICES_CHANGED")
+		apiErr.Details = changes
+		return nil, apiErr
+	}
+
+	// the tax is cleared whenever the items, coupon code, prices or shipping change, it's quoted again first
+	if cart.TaxCurrency == "" {
+		return nil, moduleErrors.NewAPIError("CART_TAX_NOT_CALCULATED")
+	}
+
+	return s.summarize(ctx, owner, cart, items)
+}
+
+// swagger:route POST /cart/prices/accept carts AcceptCartPrices
+//
+// # Accept Cart Prices
+// ### Charge the items of the cart the prices of their variants now, the tax has to be calculated again before checkout
+//
+// Produces:
+//   - application/json
+//
+// Responses:
+//
+//	200: GetCartItemsResponse Prices accepted successfully
+//	404: DefaultError Cart not found
+//	500: DefaultError Internal Server Error
+func (s *service) AcceptCartPrices(ctx context.Context) (*entities.GetCartItemsResponse, error) {
+	owner, err := ownerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := s.getActiveCart(ctx, owner)
+	if err != nil {
+		s.log.Errorf("Error retrieving active cart: %v", err)
+		return nil, moduleErrors.NewAPIError("CART_ERROR_GETTING_CART")
+	}
+	if cart == nil {
+		return nil, moduleErrors.NewAPIError("CART_NOT_FOUND")
+	}
+
+	if err = s.repo.UpdateCartItemPrices(ctx, cart.Id); err != nil {
+		s.log.Errorf("Error updating cart item prices: %v", err)
+		return nil, moduleErrors.NewAPIError("CART_ERROR_ACCEPTING_PRICES")
+	}
+
+	// the tax of the cart was cleared with the new prices, the cached taxes were calculated on the old ones
+	s.evictTaxRates(ctx, owner.ID(), cart.Id)
+
+	items, err := s.repo.GetCartItems(ctx, cart.Id.String())
+	if err != nil {
+		s.log.Errorf("Error retrieving cart items: %v", err)
+		return nil, moduleErrors.NewAPIError("CART_ERROR_GETTING_CART_ITEMS")
+	}
+
+	return &entities.GetCartItemsResponse{Items: items}, nil
+}
+
+// priceChanges lists the items whose variant price changed since they were added to the cart.
+func priceChanges(items []entities.CartItemDetail) []*entities.CartPriceChange {
+	var changes []*entities.CartPriceChange
+	for _, item := range items {
+		if !item.PriceChanged() {
+			continue
+		}
+		changes = append(changes, &entities.CartPriceChange{
+			ItemID:      item.ID,
+			SKU:         item.SKU,
+			OldPrice:    item.Price,
+			OldCurrency: item.Currency,
+			NewPrice:    item.CurrentPrice,
+			NewCurrency: item.CurrentCurrency,
+		})
+	}
+	return changes
+}
+
+// summarize works out the amounts of the cart. The checkout summary and the order placed from the cart
+// both come from here, so the total shown to the customer is the total charged.
+func (s *service) summarize(ctx context.Context, owner cartOwner, cart *entities.Cart, items []entities.CartItemDetail) (*entities.CartSummary, error) {
+	summary := &entities.CartSummary{
+		CartID:         cart.Id,
+		CouponCode:     cart.CouponCode,
+		Items:          make([]*entities.CartSummaryItem, 0, len(items)),
+		Subtotal:       decimal.Zero,
+		ShippingAmount: decimal.Zero,
+		Tax:            cart.TaxAmount,
+		TaxBreakdown:   cart.TaxBreakdown,
+		Currency:       cart.TaxCurrency,
+	}
+
+	// a shipping rate shared by several items is paid once
+	shippingRates := make(map[uuid.UUID]*entities.CartShippingRate)
+
+	for _, item := range items {
+		summaryItem := &entities.CartSummaryItem{
+			CartItemDetail: item,
+			LineSubtotal:   item.Price.Mul(decimal.NewFromInt(int64(item.Quantity))),
+		}
+
+		if item.ShippingRateID != nil {
+			shippingRate, ok := shippingRates[*item.ShippingRateID]
+			if !ok {
+				var err error
+				shippingRate, err = s.repo.GetShippingRate(ctx, *item.ShippingRateID)
+				if err != nil {
+					s.log.Errorf("Error retrieving shipping rate %s: %v", item.ShippingRateID.String(), err)
+					return nil, moduleErrors.NewAPIError("CART_ERROR_GETTING_SHIPPING_RATE")
+				}
+				shippingRates[*item.ShippingRateID] = shippingRate
+				summary.ShippingAmount = summary.ShippingAmount.Add(shippingRate.Amount)
+			}
+			summaryItem.ShippingRate = shippingRate
+		}
+
+		summary.Items = append(summary.Items, summaryItem)
+		summary.Subtotal = summary.Subtotal.Add(summaryItem.LineSubtotal)
+	}
+
+	discounts, err := s.calculateDiscounts(ctx, owner, cart, items, summary.ShippingAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, summaryItem := range summary.Items {
+		summaryItem.Discount = discounts.ItemDiscounts[i]
+		summaryItem.LineTotal = summaryItem.LineSubtotal.Sub(summaryItem.Discount)
+	}
+
+	summary.ItemsDiscount = discounts.ItemsDiscount
+	summary.ShippingDiscount = discounts.ShippingDiscount
+	summary.Discount = discounts.Total()
+	summary.Discounts = discounts.Discounts
+	summary.Total = summary.Subtotal.Sub(summary.Discount).Add(summary.ShippingAmount).Add(summary.Tax)
+
+	// the tax wasn't calculated yet, the items are all priced in the same currency
+	if summary.Currency == "" {
+		summary.Currency = items[0].Currency
+	}
+
+	return summary, nil
+}
@@ -19,7 +19,8 @@ type RequestBodyType interface {
 		entities.GetTaxRateRequestBody |
 		entities.GetShippingRateRequestBody |
 		entities.CreateCartShippingRatesRequestBody |
-		entities.SetCartItemShippingRateRequestBody
+		entities.SetCartItemShippingRateRequestBody |
+		entities.ApplyCouponRequestBody
 }
 
 func decodeBodyFromRequest[T RequestBodyType](req *T, r *http.Request) error {
@@ -110,3 +111,35 @@ func decodeSetCartItemShippingRateRequest(_ context.Context, r *http.Request) (i
 		Body: reqBody,
 	}, nil
 }
+
+func decodeApplyCouponRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	reqBody := &entities.ApplyCouponRequestBody{}
+	err := decodeBodyFromRequest(reqBody, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.ApplyCouponRequest{
+		Body: reqBody,
+	}, nil
+}
+
+func decodeRemoveCouponRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return nil, nil
+}
+
+func decodeGetCartSummaryRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return nil, nil
+}
+
+func decodeAcceptCartPricesRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return nil, nil
+}
+
+func decodeCreateGuestCartRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return nil, nil
+}
+
+func decodeMergeGuestCartRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return nil, nil
+}
@@ -23,6 +23,12 @@ func RegisterTransport(
 	registerGetShippingRate(server, ep.GetShippingRateEndpoint, svcTransportClient)
 	registerCreateCartShippingRates(server, ep.CreateCartShippingRatesEndpoint, svcTransportClient)
 	registerSetCartItemShippingRate(server, ep.SetCartItemShippingRateEndpoint, svcTransportClient)
+	registerApplyCoupon(server, ep.ApplyCouponEndpoint, svcTransportClient)
+	registerRemoveCoupon(server, ep.RemoveCouponEndpoint, svcTransportClient)
+	registerGetCartSummary(server, ep.GetCartSummaryEndpoint, svcTransportClient)
+	registerAcceptCartPrices(server, ep.AcceptCartPricesEndpoint, svcTransportClient)
+	registerCreateGuestCart(server, ep.CreateGuestCartEndpoint, svcTransportClient)
+	registerMergeGuestCart(server, ep.MergeGuestCartEndpoint, svcTransportClient)
 }
 
 func registerUpdateCartItem(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
@@ -152,3 +158,99 @@ func registerSetCartItemShippingRate(server *httpTransport.Server, ep goKitEndpo
 	server.Handle(method, path, handler)
 	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
 }
+
+func registerApplyCoupon(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "POST"
+	path := "/cart/coupon"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeApplyCouponRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
+
+func registerRemoveCoupon(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "DELETE"
+	path := "/cart/coupon"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeRemoveCouponRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
+
+func registerGetCartSummary(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "GET"
+	path := "/cart/summary"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeGetCartSummaryRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
+
+func registerAcceptCartPrices(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "POST"
+	path := "/cart/prices/accept"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeAcceptCartPricesRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
+
+func registerCreateGuestCart(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "POST"
+	path := "/cart/guest"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeCreateGuestCartRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
+
+func registerMergeGuestCart(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "POST"
+	path := "/cart/merge"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeMergeGuestCartRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
@@ -10,6 +10,7 @@ import (
 type Client interface {
 	GetCustomer(ctx context.Context) (*entities.Customer, error)
 	GetCustomerByID(ctx context.Context, id string) (*entities.Customer, error)
+	GetOrCreateGuestCustomer(ctx context.Context, email, fullName string) (*entities.Customer, error)
 	UpdateCustomerAuthorizeNetID(ctx context.Context, id string, externalID string) error
 	UpdateCustomerStripeID(ctx context.Context, id string, externalID string) error
 }
@@ -30,6 +31,10 @@ func (c *localClient) GetCustomerByID(ctx context.Context, id string) (*entities
 	return c.svc.GetCustomerByID(ctx, id)
 }
 
+func (c *localClient) GetOrCreateGuestCustomer(ctx context.Context, email, fullName string) (*entities.Customer, error) {
+	return c.svc.GetOrCreateGuestCustomer(ctx, email, fullName)
+}
+
 func (c *localClient) UpdateCustomerAuthorizeNetID(ctx context.Context, id string, externalID string) error {
 	return c.svc.UpdateCustomerAuthorizeNetID(ctx, id, externalID)
 }
@@ -65,6 +65,21 @@ func (mr *MockClientMockRecorder) GetCustomerByID(ctx, id interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerByID", reflect.TypeOf((*MockClient)(nil).GetCustomerByID), ctx, id)
 }
 
+// GetOrCreateGuestCustomer mocks base method.
+func (m *MockClient) GetOrCreateGuestCustomer(ctx context.Context, email, fullName string) (*entities.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrCreateGuestCustomer", ctx, email, fullName)
+	ret0, _ := ret[0].(*entities.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrCreateGuestCustomer indicates an expected call of GetOrCreateGuestCustomer.
+func (mr *MockClientMockRecorder) GetOrCreateGuestCustomer(ctx, email, fullName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrCreateGuestCustomer", reflect.TypeOf((*MockClient)(nil).GetOrCreateGuestCustomer), ctx, email, fullName)
+}
+
 // UpdateCustomerAuthorizeNetID mocks base method.
 func (m *MockClient) UpdateCustomerAuthorizeNetID(ctx context.Context, id, externalID string) error {
 	m.ctrl.T.Helper()
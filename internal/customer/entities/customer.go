@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 )
 
 // swagger:model GetCustomerResponse
@@ -16,6 +17,7 @@ type Customer struct {
 	SalesforceID   *string    `json:"salesforce_id" db:"salesforce_id"`
 	StripeID       *string    `json:"-" db:"stripe_id"`
 	AuthorizeNetID *string    `json:"-" gorm:"column:authorizenet_id"`
+	IsGuest        bool       `json:"is_guest" db:"is_guest"`
 	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt      *time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -23,3 +25,15 @@ type Customer struct {
 func (u *Customer) TableName() string {
 	return "customers"
 }
+
+// PaymentProviderID returns the ID of the customer at the payment provider, nil when the customer has none.
+func (u *Customer) PaymentProviderID(provider providers.ProviderType) *string {
+	switch provider {
+	case providers.ProviderStripe:
+		return u.StripeID
+	case providers.ProviderAuthorizeNet:
+		return u.AuthorizeNetID
+	}
+
+	return nil
+}
@@ -80,6 +80,21 @@ func (mr *MockRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockRepository)(nil).FindByID), ctx, id)
 }
 
+// FindGuestByEmail mocks base method.
+func (m *MockRepository) FindGuestByEmail(ctx context.Context, email string) (*entities.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindGuestByEmail", ctx, email)
+	ret0, _ := ret[0].(*entities.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindGuestByEmail indicates an expected call of FindGuestByEmail.
+func (mr *MockRepositoryMockRecorder) FindGuestByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindGuestByEmail", reflect.TypeOf((*MockRepository)(nil).FindGuestByEmail), ctx, email)
+}
+
 // Update mocks base method.
 func (m *MockRepository) Update(ctx context.Context, details map[string]interface{}, id string) error {
 	m.ctrl.T.Helper()
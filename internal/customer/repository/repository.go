@@ -11,6 +11,7 @@ import (
 type Repository interface {
 	Create(ctx context.Context, customer *entities.Customer) (*entities.Customer, error)
 	FindByEmail(ctx context.Context, email string) (*entities.Customer, error)
+	FindGuestByEmail(ctx context.Context, email string) (*entities.Customer, error)
 	FindByID(ctx context.Context, id string) (*entities.Customer, error)
 	Update(ctx context.Context, details map[string]interface{}, id string) error
 }
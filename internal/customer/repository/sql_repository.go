@@ -17,7 +17,11 @@ func (r *sqlRepository) Create(ctx context.Context, customer *entities.Customer)
 	err := r.gormDB.Create(customer).Error
 	if err != nil {
 		if dbErrors.IsAlreadyExistError(err) {
-			existingCustomer, findErr := r.FindByEmail(ctx, customer.Email)
+			find := r.FindByEmail
+			if customer.IsGuest {
+				find = r.FindGuestByEmail
+			}
+			existingCustomer, findErr := find(ctx, customer.Email)
 			if findErr != nil {
 				return nil, findErr
 			}
@@ -31,7 +35,18 @@ func (r *sqlRepository) Create(ctx context.Context, customer *entities.Customer)
 
 func (r *sqlRepository) FindByEmail(_ context.Context, email string) (*entities.Customer, error) {
 	customer := &entities.Customer{}
-	err := r.gormDB.Where("email = ?", email).First(customer).Error
+	err := r.gormDB.Where("email = ? AND NOT is_guest", email).First(customer).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// FindGuestByEmail finds the customer the guest checkouts with the email are placed for.
+func (r *sqlRepository) FindGuestByEmail(_ context.Context, email string) (*entities.Customer, error) {
+	customer := &entities.Customer{}
+	err := r.gormDB.Where("email = ? AND is_guest", email).First(customer).Error
 	if err != nil {
 		return nil, err
 	}
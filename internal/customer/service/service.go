@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,18 +11,21 @@ import (
 	"github.com/nurdsoft/nurd-commerce-core/internal/customer/entities"
 	"github.com/nurdsoft/nurd-commerce-core/internal/customer/repository"
 	"github.com/nurdsoft/nurd-commerce-core/shared/cfg"
+	dbErrors "github.com/nurdsoft/nurd-commerce-core/shared/db"
 	sharedMeta "github.com/nurdsoft/nurd-commerce-core/shared/meta"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory/providers"
 	salesforce "github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory/salesforce/client"
 	salesforceEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory/salesforce/entities"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type Service interface {
 	CreateCustomer(ctx context.Context, req *entities.CreateCustomerRequest) (*entities.Customer, error)
 	GetCustomer(ctx context.Context) (*entities.Customer, error)
 	GetCustomerByID(ctx context.Context, id string) (*entities.Customer, error)
+	GetOrCreateGuestCustomer(ctx context.Context, email, fullName string) (*entities.Customer, error)
 	UpdateCustomer(ctx context.Context, req *entities.UpdateCustomerRequest) (*entities.Customer, error)
 	UpdateCustomerAuthorizeNetID(ctx context.Context, customerID string, externalID string) error
 	UpdateCustomerStripeID(ctx context.Context, customerID string, externalID string) error
@@ -247,6 +252,37 @@ func (s *service) GetCustomerByID(ctx context.Context, id string) (*entities.Cus
 	return customer, nil
 }
 
+// GetOrCreateGuestCustomer returns the guest customer of the email, the orders of a guest checkout are placed for it.
+// The guest customer is kept apart from the customer signing up with the same email.
+func (s *service) GetOrCreateGuestCustomer(ctx context.Context, email, fullName string) (*entities.Customer, error) {
+	customer, err := s.repo.FindGuestByEmail(ctx, email)
+	if err == nil {
+		return customer, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	firstName, lastName, _ := strings.Cut(strings.TrimSpace(fullName), " ")
+	customer = &entities.Customer{
+		ID:        uuid.New(),
+		Email:     email,
+		FirstName: firstName,
+		IsGuest:   true,
+	}
+	if lastName = strings.TrimSpace(lastName); lastName != "" {
+		customer.LastName = &lastName
+	}
+
+	created, err := s.repo.Create(ctx, customer)
+	if err != nil && !dbErrors.IsAlreadyExistError(err) {
+		return nil, err
+	}
+
+	// a concurrent checkout created it first
+	return created, nil
+}
+
 // Create a new user in Salesforce and update the user with the Salesforce ID
 func (s *service) createSalesforceUser(ctx context.Context, firstName, lastName, email, customerID string) (*salesforceEntities.CreateSFUserResponse, error) {
 	res, err := s.sfClient.CreateUserAccount(ctx, &salesforceEntities.CreateSFUserRequest{
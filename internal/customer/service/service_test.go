@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 
 	"github.com/nurdsoft/nurd-commerce-core/internal/customer/entities"
 	"github.com/nurdsoft/nurd-commerce-core/internal/customer/repository"
@@ -375,6 +376,63 @@ func Test_service_GetCustomerByID(t *testing.T) {
 	})
 }
 
+func Test_service_GetOrCreateGuestCustomer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	setup := func() (*service, *repository.MockRepository) {
+		mockRepo := repository.NewMockRepository(ctrl)
+		svc := &service{
+			repo: mockRepo,
+			log:  zap.NewExample().Sugar(),
+		}
+		return svc, mockRepo
+	}
+
+	t.Run("Returns the existing guest customer", func(t *testing.T) {
+		svc, mockRepo := setup()
+		ctx := context.Background()
+
+		guest := &entities.Customer{ID: uuid.New(), Email: "guest@example.com", FirstName: "Jane", IsGuest: true}
+		mockRepo.EXPECT().FindGuestByEmail(ctx, "guest@example.com").Return(guest, nil).Times(1)
+
+		result, err := svc.GetOrCreateGuestCustomer(ctx, "guest@example.com", "Jane Doe")
+		assert.NoError(t, err)
+		assert.Equal(t, guest, result)
+	})
+
+	t.Run("Creates a guest customer", func(t *testing.T) {
+		svc, mockRepo := setup()
+		ctx := context.Background()
+
+		mockRepo.EXPECT().FindGuestByEmail(ctx, "guest@example.com").Return(nil, gorm.ErrRecordNotFound).Times(1)
+		mockRepo.EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, customer *entities.Customer) (*entities.Customer, error) {
+				return customer, nil
+			}).
+			Times(1)
+
+		result, err := svc.GetOrCreateGuestCustomer(ctx, "guest@example.com", "Jane van Doe")
+		assert.NoError(t, err)
+		assert.True(t, result.IsGuest)
+		assert.Equal(t, "guest@example.com", result.Email)
+		assert.Equal(t, "Jane", result.FirstName)
+		assert.Equal(t, "van Doe", *result.LastName)
+	})
+
+	t.Run("Repository error is propagated", func(t *testing.T) {
+		svc, mockRepo := setup()
+		ctx := context.Background()
+
+		expectedErr := errors.New("db error")
+		mockRepo.EXPECT().FindGuestByEmail(ctx, "guest@example.com").Return(nil, expectedErr).Times(1)
+
+		result, err := svc.GetOrCreateGuestCustomer(ctx, "guest@example.com", "Jane Doe")
+		assert.Equal(t, expectedErr, err)
+		assert.Nil(t, result)
+	})
+}
+
 func Test_service_UpdateCustomer(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
@@ -0,0 +1,43 @@
+package fakepayment
+
+import (
+	"github.com/nurdsoft/nurd-commerce-core/internal/fakepayment/service"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/ordersclient"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/fakeprovider"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// ModuleParams for fake payments.
+type ModuleParams struct {
+	fx.In
+
+	Logger       *zap.SugaredLogger
+	Payments     payment.Registry
+	OrdersClient ordersclient.Client
+}
+
+// NewModule delivers the webhooks simulated by the fake payment provider to the orders,
+// it does nothing when the fake payment provider isn't configured.
+// nolint:gocritic
+func NewModule(p ModuleParams) {
+	paymentClient, err := p.Payments.Get(providers.ProviderFake)
+	if err != nil {
+		return
+	}
+
+	fakeClient, ok := paymentClient.(fakeprovider.Client)
+	if !ok {
+		return
+	}
+
+	svc := service.New(p.Logger, p.OrdersClient)
+	fakeClient.OnEvent(svc.HandleEvent)
+}
+
+var (
+	// ModuleWebhooks for uber fx.
+	ModuleWebhooks = fx.Options(fx.Invoke(NewModule))
+)
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/ordersclient"
+	sharedMeta "github.com/nurdsoft/nurd-commerce-core/shared/meta"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/fakeprovider"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"go.uber.org/zap"
+)
+
+type Service interface {
+	HandleEvent(ctx context.Context, event fakeprovider.Event) error
+}
+
+type service struct {
+	log          *zap.SugaredLogger
+	ordersClient ordersclient.Client
+}
+
+func New(logger *zap.SugaredLogger, ordersClient ordersclient.Client) Service {
+	return &service{
+		log:          logger,
+		ordersClient: ordersClient,
+	}
+}
+
+// HandleEvent settles the orders the way the webhooks of a real payment provider would.
+func (s *service) HandleEvent(ctx context.Context, event fakeprovider.Event) error {
+	// keep track of the event that triggered the order changes
+	ctx = sharedMeta.WithSourceEventID(ctx, event.ID)
+
+	s.log.Info("Fake payment event ", "event_type ", event.Type, " object_id ", event.ObjectID)
+
+	switch event.Type {
+	case fakeprovider.EventPaymentSucceeded:
+		return s.ordersClient.ProcessPaymentSucceeded(ctx, providers.ProviderFake, event.ObjectID)
+	case fakeprovider.EventPaymentFailed:
+		return s.ordersClient.ProcessPaymentFailed(ctx, providers.ProviderFake, event.ObjectID)
+	case fakeprovider.EventRefundSucceeded:
+		return s.ordersClient.ProcessRefundSucceeded(ctx, event.ObjectID, event.Amount)
+	case fakeprovider.EventRefundFailed:
+		return s.ordersClient.ProcessRefundFailed(ctx, event.ObjectID, event.FailureReason)
+	default:
+		s.log.Warnf("Unhandled event type: %s", event.Type)
+		return nil
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/ordersclient"
+	"github.com/nurdsoft/nurd-commerce-core/shared/meta"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/fakeprovider"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func Test_service_HandleEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	setup := func() (Service, *ordersclient.MockClient) {
+		mockOrdersClient := ordersclient.NewMockClient(ctrl)
+		return New(zap.NewExample().Sugar(), mockOrdersClient), mockOrdersClient
+	}
+
+	t.Run("payment succeeded", func(t *testing.T) {
+		svc, mockOrdersClient := setup()
+
+		mockOrdersClient.EXPECT().
+			ProcessPaymentSucceeded(gomock.Any(), providers.ProviderFake, "fake_pay_123").
+			Do(func(ctx context.Context, _ providers.ProviderType, _ string) {
+				assert.Equal(t, "fake_evt_123", meta.SourceEventID(ctx))
+			}).Return(nil).Times(1)
+
+		err := svc.HandleEvent(context.Background(), fakeprovider.Event{
+			ID:       "fake_evt_123",
+			Type:     fakeprovider.EventPaymentSucceeded,
+			ObjectID: "fake_pay_123",
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("payment failed", func(t *testing.T) {
+		svc, mockOrdersClient := setup()
+
+		mockOrdersClient.EXPECT().ProcessPaymentFailed(gomock.Any(), providers.ProviderFake, "fake_pay_123").Return(nil).Times(1)
+
+		err := svc.HandleEvent(context.Background(), fakeprovider.Event{
+			ID:       "fake_evt_123",
+			Type:     fakeprovider.EventPaymentFailed,
+			ObjectID: "fake_pay_123",
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("refund succeeded", func(t *testing.T) {
+		svc, mockOrdersClient := setup()
+
+		mockOrdersClient.EXPECT().
+			ProcessRefundSucceeded(gomock.Any(), "fake_re_123", decimal.NewFromInt(40)).Return(nil).Times(1)
+
+		err := svc.HandleEvent(context.Background(), fakeprovider.Event{
+			ID:       "fake_evt_123",
+			Type:     fakeprovider.EventRefundSucceeded,
+			ObjectID: "fake_re_123",
+			Amount:   decimal.NewFromInt(40),
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("refund failed", func(t *testing.T) {
+		svc, mockOrdersClient := setup()
+
+		mockOrdersClient.EXPECT().ProcessRefundFailed(gomock.Any(), "fake_re_123", "declined").Return(nil).Times(1)
+
+		err := svc.HandleEvent(context.Background(), fakeprovider.Event{
+			ID:            "fake_evt_123",
+			Type:          fakeprovider.EventRefundFailed,
+			ObjectID:      "fake_re_123",
+			FailureReason: "declined",
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("unhandled event type", func(t *testing.T) {
+		svc, _ := setup()
+
+		err := svc.HandleEvent(context.Background(), fakeprovider.Event{Type: "payment.disputed"})
+
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Config for the orders module
+type Config struct {
+	// PendingOrderTTL is how long an order waits for its payment webhook before the payment
+	// provider is asked for the payment state, zero disables the pending order sweeper
+	PendingOrderTTL time.Duration
+	// PendingOrderSweepInterval is how often stale pending orders are looked up
+	PendingOrderSweepInterval time.Duration
+}
+
+// Validate config
+func (c *Config) Validate() error {
+	var errs []string
+
+	if c.PendingOrderTTL < 0 {
+		errs = append(errs, "orders pendingOrderTTL shouldn't be negative")
+	}
+
+	if c.PendingOrderTTL > 0 && c.PendingOrderSweepInterval <= 0 {
+		errs = append(errs, "orders pendingOrderSweepInterval should be positive")
+	}
+
+	if len(errs) > 0 {
+		return errors.Errorf("%s", strings.Join(errs, ","))
+	}
+
+	return nil
+}
@@ -9,22 +9,32 @@ import (
 )
 
 type Endpoints struct {
-	CreateOrderEndpoint endpoint.Endpoint
-	ListOrdersEndpoint  endpoint.Endpoint
-	GetOrderEndpoint    endpoint.Endpoint
-	CancelOrderEndpoint endpoint.Endpoint
-	UpdateOrderEndpoint endpoint.Endpoint
-	RefundOrderEndpoint endpoint.Endpoint
+	CreateOrderEndpoint     endpoint.Endpoint
+	ListOrdersEndpoint      endpoint.Endpoint
+	GetOrderEndpoint        endpoint.Endpoint
+	GetOrderHistoryEndpoint endpoint.Endpoint
+	CancelOrderEndpoint     endpoint.Endpoint
+	UpdateOrderEndpoint     endpoint.Endpoint
+	RefundOrderEndpoint     endpoint.Endpoint
+	CreateReturnEndpoint    endpoint.Endpoint
+	ListReturnsEndpoint     endpoint.Endpoint
+	UpdateReturnEndpoint    endpoint.Endpoint
+	RefundReturnEndpoint    endpoint.Endpoint
 }
 
 func New(svc service.Service) *Endpoints {
 	return &Endpoints{
-		CreateOrderEndpoint: makeCreateOrderEndpoint(svc),
-		ListOrdersEndpoint:  makeListOrdersEndpoint(svc),
-		GetOrderEndpoint:    makeGetOrderEndpoint(svc),
-		CancelOrderEndpoint: makeCancelOrderEndpoint(svc),
-		UpdateOrderEndpoint: makeUpdateOrderEndpoint(svc),
-		RefundOrderEndpoint: makeRefundOrderEndpoint(svc),
+		CreateOrderEndpoint:     makeCreateOrderEndpoint(svc),
+		ListOrdersEndpoint:      makeListOrdersEndpoint(svc),
+		GetOrderEndpoint:        makeGetOrderEndpoint(svc),
+		GetOrderHistoryEndpoint: makeGetOrderHistoryEndpoint(svc),
+		CancelOrderEndpoint:     makeCancelOrderEndpoint(svc),
+		UpdateOrderEndpoint:     makeUpdateOrderEndpoint(svc),
+		RefundOrderEndpoint:     makeRefundOrderEndpoint(svc),
+		CreateReturnEndpoint:    makeCreateReturnEndpoint(svc),
+		ListReturnsEndpoint:     makeListReturnsEndpoint(svc),
+		UpdateReturnEndpoint:    makeUpdateReturnEndpoint(svc),
+		RefundReturnEndpoint:    makeRefundReturnEndpoint(svc),
 	}
 }
 
@@ -49,6 +59,13 @@ func makeGetOrderEndpoint(svc service.Service) endpoint.Endpoint {
 	}
 }
 
+func makeGetOrderHistoryEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(*entities.GetOrderHistoryRequest)
+		return svc.GetOrderHistory(ctx, req)
+	}
+}
+
 func makeCancelOrderEndpoint(svc service.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(*entities.CancelOrderRequest)
@@ -69,3 +86,31 @@ func makeRefundOrderEndpoint(svc service.Service) endpoint.Endpoint {
 		return svc.RefundOrder(ctx, req)
 	}
 }
+
+func makeCreateReturnEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(*entities.CreateReturnRequest)
+		return svc.CreateReturn(ctx, req)
+	}
+}
+
+func makeListReturnsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(*entities.ListReturnsRequest)
+		return svc.ListReturns(ctx, req)
+	}
+}
+
+func makeUpdateReturnEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(*entities.UpdateReturnRequest)
+		return svc.UpdateReturn(ctx, req)
+	}
+}
+
+func makeRefundReturnEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(*entities.RefundReturnRequest)
+		return svc.RefundReturn(ctx, req)
+	}
+}
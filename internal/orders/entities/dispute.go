@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"github.com/shopspring/decimal"
+)
+
+type DisputeStatus string
+
+func (s DisputeStatus) String() string {
+	return string(s)
+}
+
+const (
+	// Disputed is used while the cardholder's dispute is open and waiting for evidence or a decision
+	Disputed DisputeStatus = "disputed"
+	// DisputeWon is used once the dispute is closed in favor of the merchant
+	DisputeWon DisputeStatus = "dispute_won"
+	// DisputeLost is used once the dispute is closed in favor of the cardholder, the funds are not coming back
+	DisputeLost DisputeStatus = "dispute_lost"
+)
+
+// IsClosed reports whether the dispute was decided.
+func (s DisputeStatus) IsClosed() bool {
+	return s == DisputeWon || s == DisputeLost
+}
+
+// PaymentDispute is a chargeback or inquiry opened by the cardholder against the payment of an order.
+type PaymentDispute struct {
+	ID string
+	// PaymentProvider is the provider reporting the dispute
+	PaymentProvider providers.ProviderType
+	// PaymentID is the payment provider ID of the disputed payment
+	PaymentID string
+	Status    DisputeStatus
+	Reason    string
+	Amount    decimal.Decimal
+}
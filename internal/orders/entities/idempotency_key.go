@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/nurdsoft/nurd-commerce-core/shared/json"
+)
+
+// IdempotencyKey stores the outcome of a request sent with an Idempotency-Key header.
+// A record without a response belongs to a request that is still being processed, since ClaimedAt.
+type IdempotencyKey struct {
+	Scope       string    `gorm:"column:scope"`
+	Key         string    `gorm:"column:key"`
+	RequestHash string    `gorm:"column:request_hash"`
+	Response    json.JSON `gorm:"column:response"`
+	ClaimedAt   time.Time `gorm:"column:claimed_at;default:now()"`
+	CreatedAt   time.Time `gorm:"column:created_at;default:now()"`
+}
+
+func (m *IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
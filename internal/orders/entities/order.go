@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/nurdsoft/nurd-commerce-core/shared/json"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 	"github.com/shopspring/decimal"
 )
 
@@ -27,6 +28,10 @@ const (
 	ReturnRequested   OrderStatus = "return_requested"
 	Returned          OrderStatus = "returned"
 	Refunded          OrderStatus = "refunded"
+	// CancelRefundPending is a cancelled paid order waiting for the payment provider to confirm the refund
+	CancelRefundPending OrderStatus = "cancel_refund_pending"
+	// RequiresAction is an order waiting for the customer to complete the payment, e.g. 3-D Secure
+	RequiresAction OrderStatus = "requires_action"
 )
 
 type Order struct {
@@ -36,6 +41,7 @@ type Order struct {
 	OrderReference                string              `json:"order_reference" gorm:"column:order_reference"`
 	TaxAmount                     decimal.Decimal     `json:"tax_amount" gorm:"column:tax_amount"`
 	Subtotal                      decimal.Decimal     `json:"subtotal" gorm:"column:subtotal"`
+	DiscountAmount                decimal.Decimal     `json:"discount_amount" gorm:"column:discount_amount"`
 	Total                         decimal.Decimal     `json:"total" gorm:"column:total"`
 	Currency                      string              `json:"currency" gorm:"column:currency"`
 	TaxBreakdown                  json.JSON           `json:"-" gorm:"column:tax_breakdown"`
@@ -64,15 +70,50 @@ type Order struct {
 	FulfillmentTrackingNumber     *string             `json:"tracking_number,omitempty" gorm:"column:fulfillment_tracking_number"`
 	FulfillmentTrackingURL        *string             `json:"tracking_url,omitempty" gorm:"column:fulfillment_tracking_url"`
 	SalesforceID                  string              `json:"-" gorm:"column:salesforce_id"`
-	StripePaymentIntentID         *string             `json:"-" gorm:"column:stripe_payment_intent_id"`
 	StripePaymentMethodID         string              `json:"stripe_payment_method_id" gorm:"column:stripe_payment_method_id"`
-	AuthorizeNetPaymentID         *string             `json:"-" gorm:"column:authorizenet_payment_id"`
 	CreatedAt                     time.Time           `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt                     time.Time           `json:"updated_at" gorm:"column:updated_at"`
 	ItemsSummary                  []*OrderItemSummary `json:"items_summary,omitempty" gorm:"-"`
-	StripeRefundTotal             *decimal.Decimal    `json:"-" gorm:"column:stripe_refund_total"`
+	RefundTotal                   *decimal.Decimal    `json:"-" gorm:"column:refund_total"`
+	RefundID                      *string             `json:"-" gorm:"column:refund_id"`
+	// PaymentProvider created the order payment, PaymentID is the ID the provider gave it
+	PaymentProvider providers.ProviderType `json:"-" gorm:"column:payment_provider"`
+	PaymentID       *string                `json:"-" gorm:"column:payment_id"`
+	// orders placed in manual capture mode only hold the funds until the payment is captured or voided
+	PaymentCaptureMode    providers.CaptureMode `json:"-" gorm:"column:payment_capture_mode"`
+	PaymentCapturedAmount *decimal.Decimal      `json:"-" gorm:"column:payment_captured_amount"`
+	PaymentCapturedAt     *time.Time            `json:"-" gorm:"column:payment_captured_at"`
+	PaymentVoidedAt       *time.Time            `json:"-" gorm:"column:payment_voided_at"`
+	// the capture is requested along with the shipment and carried out by the outbox
+	PaymentCaptureRequestedAt *time.Time `json:"-" gorm:"column:payment_capture_requested_at"`
+	// a capture declined by the payment provider is recorded with the reason, the order keeps its status
+	PaymentCaptureFailedAt      *time.Time `json:"-" gorm:"column:payment_capture_failed_at"`
+	PaymentCaptureFailureReason *string    `json:"payment_capture_failure_reason,omitempty" gorm:"column:payment_capture_failure_reason"`
+	// chargebacks are tracked apart from the order status, the order goes on with its fulfillment
+	DisputeID     *string        `json:"-" gorm:"column:dispute_id"`
+	DisputeStatus *DisputeStatus `json:"dispute_status,omitempty" gorm:"column:dispute_status"`
+	DisputeReason *string        `json:"dispute_reason,omitempty" gorm:"column:dispute_reason"`
+	// Discounts has a line per promotion applied, they add up to DiscountAmount
+	Discounts []*OrderDiscount `json:"discounts,omitempty" gorm:"-"`
 }
 
 func (m *Order) TableName() string {
 	return "orders"
 }
+
+// AwaitingCapture reports whether the order payment was only authorized and the funds are still on hold,
+// with no capture requested yet.
+func (m *Order) AwaitingCapture() bool {
+	return m.PaymentCaptureMode == providers.CaptureModeManual && m.PaymentCaptureRequestedAt == nil &&
+		m.PaymentCapturedAt == nil && m.PaymentVoidedAt == nil
+}
+
+// CapturePending reports whether the capture of the order payment was requested but not carried out yet.
+func (m *Order) CapturePending() bool {
+	return m.PaymentCaptureRequestedAt != nil && m.PaymentCapturedAt == nil && m.PaymentCaptureFailedAt == nil
+}
+
+// CaptureFailed reports whether the payment provider declined the capture of the order payment.
+func (m *Order) CaptureFailed() bool {
+	return m.PaymentCaptureFailedAt != nil && m.PaymentCapturedAt == nil
+}
@@ -0,0 +1,25 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	promotionsEntities "github.com/nurdsoft/nurd-commerce-core/internal/promotions/entities"
+	"github.com/shopspring/decimal"
+)
+
+// OrderDiscount is a promotion applied to the order, it counts toward the promotion usage limits.
+type OrderDiscount struct {
+	ID           uuid.UUID                       `json:"id" gorm:"column:id"`
+	OrderID      uuid.UUID                       `json:"-" gorm:"column:order_id"`
+	PromotionID  uuid.UUID                       `json:"promotion_id" gorm:"column:promotion_id"`
+	Code         *string                         `json:"code,omitempty" gorm:"column:code"`
+	Name         string                          `json:"name" gorm:"column:name"`
+	DiscountType promotionsEntities.DiscountType `json:"discount_type" gorm:"column:discount_type"`
+	Amount       decimal.Decimal                 `json:"amount" gorm:"column:amount"`
+	CreatedAt    time.Time                       `json:"created_at" gorm:"column:created_at"`
+}
+
+func (m *OrderDiscount) TableName() string {
+	return "order_discounts"
+}
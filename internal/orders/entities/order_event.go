@@ -0,0 +1,54 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OrderEventActor string
+
+func (a OrderEventActor) String() string {
+	return string(a)
+}
+
+const (
+	// ActorCustomer is used for changes requested by the customer through the storefront
+	ActorCustomer OrderEventActor = "customer"
+	// ActorWebhook is used for changes triggered by inbound webhooks (Stripe, Authorize.net)
+	ActorWebhook OrderEventActor = "webhook"
+	// ActorAdmin is used for changes made through the back-office / fulfillment APIs
+	ActorAdmin OrderEventActor = "admin"
+	// ActorProvider is used for changes derived from an external provider (payment, inventory) response
+	ActorProvider OrderEventActor = "provider"
+	// ActorSystem is used for changes made by background jobs, e.g. expiring stale orders
+	ActorSystem OrderEventActor = "system"
+)
+
+// OrderEvent is a single entry of the order status history.
+// Events without an OrderItemID describe a change of the order itself.
+type OrderEvent struct {
+	ID            uuid.UUID       `json:"id" gorm:"column:id;default:gen_random_uuid()"`
+	OrderID       uuid.UUID       `json:"order_id" gorm:"column:order_id"`
+	OrderItemID   *uuid.UUID      `json:"order_item_id,omitempty" gorm:"column:order_item_id"`
+	OldStatus     *string         `json:"old_status" gorm:"column:old_status"`
+	NewStatus     string          `json:"new_status" gorm:"column:new_status"`
+	Actor         OrderEventActor `json:"actor" gorm:"column:actor"`
+	SourceEventID *string         `json:"source_event_id,omitempty" gorm:"column:source_event_id"`
+	Message       *string         `json:"message,omitempty" gorm:"column:message"`
+	CreatedAt     time.Time       `json:"created_at" gorm:"column:created_at;autoCreateTime:false;default:clock_timestamp()"`
+	// Sequence is the insertion order of the events, it breaks ties between events created at the same time
+	Sequence int64 `json:"-" gorm:"column:sequence;->"`
+}
+
+func (m *OrderEvent) TableName() string {
+	return "order_events"
+}
+
+// OrderEventSource describes who or what caused a status change.
+// It is passed along with every repository write that can change a status.
+type OrderEventSource struct {
+	Actor         OrderEventActor
+	SourceEventID *string
+	Message       *string
+}
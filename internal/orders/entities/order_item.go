@@ -54,6 +54,9 @@ type OrderItem struct {
 	ShippingServiceCode   *string          `json:"shipping_service_code" gorm:"column:shipping_service_code"`
 	EstimatedDeliveryDate *time.Time       `json:"estimated_delivery_date" gorm:"column:estimated_delivery_date"`
 	BusinessDaysInTransit *string          `json:"business_days_in_transit" gorm:"column:business_days_in_transit"`
+	TaxAmount             *decimal.Decimal `json:"tax_amount" gorm:"column:tax_amount"`
+	ShippingAmount        *decimal.Decimal `json:"shipping_amount" gorm:"column:shipping_amount"`
+	DiscountAmount        *decimal.Decimal `json:"discount_amount,omitempty" gorm:"column:discount_amount"`
 	TrackingNumber        *string          `json:"tracking_number" gorm:"column:tracking_number"`
 	TrackingURL           *string          `json:"tracking_url" gorm:"column:tracking_url"`
 	ShipmentDate          *time.Time       `json:"shipment_date" gorm:"column:shipment_date"`
@@ -65,7 +68,9 @@ type OrderItem struct {
 	UpdatedAt             time.Time        `json:"updated_at" gorm:"column:updated_at"`
 	SalesforceID          string           `json:"-" gorm:"column:salesforce_id"`
 	Status                OrderItemStatus  `json:"status" db:"status"`
-	StripeRefundID        string           `json:"-" gorm:"column:stripe_refund_id"`
+	RefundID              string           `json:"-" gorm:"column:refund_id"`
+	RefundAmount          *decimal.Decimal `json:"-" gorm:"column:refund_amount"`
+	RefundFailureReason   *string          `json:"refund_failure_reason,omitempty" gorm:"column:refund_failure_reason"`
 }
 
 func (m *OrderItem) TableName() string {
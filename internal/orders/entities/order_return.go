@@ -0,0 +1,91 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type ReturnStatus string
+
+func (s ReturnStatus) String() string {
+	return string(s)
+}
+
+const (
+	// ReturnStatusRequested is a return opened by the customer, waiting for review
+	ReturnStatusRequested ReturnStatus = "requested"
+	// ReturnStatusApproved means the customer can send the goods back
+	ReturnStatusApproved ReturnStatus = "approved"
+	ReturnStatusRejected ReturnStatus = "rejected"
+	// ReturnStatusReceived means the goods arrived back at the warehouse
+	ReturnStatusReceived ReturnStatus = "received"
+	ReturnStatusRefunded ReturnStatus = "refunded"
+)
+
+// returnTransitions is the return state machine, statuses without an entry are terminal.
+var returnTransitions = map[ReturnStatus][]ReturnStatus{
+	ReturnStatusRequested: {ReturnStatusApproved, ReturnStatusRejected},
+	ReturnStatusApproved:  {ReturnStatusReceived, ReturnStatusRejected},
+	ReturnStatusReceived:  {ReturnStatusRefunded},
+}
+
+// IsValid reports whether the status is one of the known return statuses.
+func (s ReturnStatus) IsValid() bool {
+	switch s {
+	case ReturnStatusRequested, ReturnStatusApproved, ReturnStatusRejected, ReturnStatusReceived, ReturnStatusRefunded:
+		return true
+	}
+
+	return false
+}
+
+// IsOpen reports whether the return is still in progress.
+func (s ReturnStatus) IsOpen() bool {
+	return s == ReturnStatusRequested || s == ReturnStatusApproved
+}
+
+// CanTransitionTo reports whether a return in this status may move to next.
+func (s ReturnStatus) CanTransitionTo(next ReturnStatus) bool {
+	for _, allowed := range returnTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OrderReturn is a request to send back some of the items of a delivered order (RMA).
+// swagger:model OrderReturn
+type OrderReturn struct {
+	ID              uuid.UUID          `json:"id" gorm:"column:id;default:gen_random_uuid()"`
+	OrderID         uuid.UUID          `json:"order_id" gorm:"column:order_id"`
+	CustomerID      uuid.UUID          `json:"customer_id" gorm:"column:customer_id"`
+	ReturnReference string             `json:"return_reference" gorm:"column:return_reference"`
+	Status          ReturnStatus       `json:"status" gorm:"column:status"`
+	Reason          string             `json:"reason" gorm:"column:reason"`
+	Note            *string            `json:"note" gorm:"column:note"`
+	RefundAmount    *decimal.Decimal   `json:"refund_amount" gorm:"column:refund_amount"`
+	Items           []*OrderReturnItem `json:"items" gorm:"foreignKey:ReturnID"`
+	CreatedAt       time.Time          `json:"created_at" gorm:"column:created_at;default:now()"`
+	UpdatedAt       time.Time          `json:"updated_at" gorm:"column:updated_at;default:now()"`
+}
+
+func (m *OrderReturn) TableName() string {
+	return "order_returns"
+}
+
+type OrderReturnItem struct {
+	ID          uuid.UUID `json:"id" gorm:"column:id;default:gen_random_uuid()"`
+	ReturnID    uuid.UUID `json:"return_id" gorm:"column:return_id"`
+	OrderItemID uuid.UUID `json:"order_item_id" gorm:"column:order_item_id"`
+	SKU         string    `json:"sku" gorm:"column:sku"`
+	Quantity    int       `json:"quantity" gorm:"column:quantity"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;default:now()"`
+}
+
+func (m *OrderReturnItem) TableName() string {
+	return "order_return_items"
+}
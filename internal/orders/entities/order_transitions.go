@@ -0,0 +1,118 @@
+package entities
+
+// orderTransitions is the order state machine: for every status it lists the
+// statuses an order is allowed to move to next. Statuses without an entry are terminal.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	Pending:           {PaymentSuccess, PaymentFailed, Cancelled},
+	PaymentSuccess:    {Processing, Packed, Shipped, FulfillmentFailed, Cancelled, CancelRefundPending, Refunded},
+	Processing:        {Packed, Shipped, FulfillmentFailed, Cancelled, Refunded},
+	Packed:            {Processing, Shipped, FulfillmentFailed, Cancelled, Refunded},
+	Shipped:           {Delivered, FulfillmentFailed, ReturnRequested, Refunded},
+	FulfillmentFailed: {Processing, Packed, Shipped, Cancelled, Refunded},
+	Delivered:         {ReturnRequested, Refunded},
+	ReturnRequested:   {Returned, Delivered, Refunded},
+	Returned:          {ReturnRequested, Refunded},
+	// a paid order that was cancelled still needs its payment refunded
+	Cancelled: {Refunded},
+	// the cancellation completes once the payment provider confirms the refund, a failed refund
+	// moves the order back to payment_success so it can be cancelled again
+	CancelRefundPending: {Cancelled, PaymentSuccess},
+	// the payment webhooks report whether the customer completed the payment
+	RequiresAction: {PaymentSuccess, PaymentFailed, Cancelled},
+}
+
+// orderItemTransitions is the state machine of the order items updated by fulfillment. Refunds move the
+// items through their own flow, see CanRefundTransitionTo, so no status leads to initiated_refund or refunded here.
+var orderItemTransitions = map[OrderItemStatus][]OrderItemStatus{
+	ItemPending:           {ItemProcessing, ItemShipped, ItemFulfillmentFailed, ItemCancelled},
+	ItemProcessing:        {ItemShipped, ItemFulfillmentFailed, ItemCancelled},
+	ItemFulfillmentFailed: {ItemProcessing, ItemShipped, ItemCancelled},
+	ItemShipped:           {ItemDelivered, ItemFulfillmentFailed, ItemReturnRequested},
+	ItemDelivered:         {ItemReturnRequested},
+	ItemReturnRequested:   {ItemReturned, ItemDelivered},
+	ItemReturned:          {ItemReturnRequested},
+}
+
+// IsValid reports whether the status is one of the known order statuses.
+func (o OrderStatus) IsValid() bool {
+	switch o {
+	case Pending, PaymentSuccess, PaymentFailed, Processing, Packed, Shipped,
+		FulfillmentFailed, Delivered, Cancelled, CancelRefundPending, ReturnRequested, Returned, Refunded,
+		RequiresAction:
+		return true
+	}
+
+	return false
+}
+
+// CanTransitionTo reports whether an order in this status may move to next.
+// Staying in the same status is always allowed so repeated updates are idempotent.
+func (o OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	if o == next {
+		return true
+	}
+
+	for _, allowed := range orderTransitions[o] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CanRevertRefundTo reports whether a failed refund may move a refunded order back to next. Refunded is
+// terminal for the state machine, so the order only goes back to a status it could have been refunded from.
+func (o OrderStatus) CanRevertRefundTo(next OrderStatus) bool {
+	return o == Refunded && next != Refunded && next.CanTransitionTo(Refunded)
+}
+
+// IsValid reports whether the status is one of the known order item statuses.
+func (o OrderItemStatus) IsValid() bool {
+	switch o {
+	case ItemPending, ItemProcessing, ItemShipped, ItemFulfillmentFailed, ItemDelivered,
+		ItemCancelled, ItemReturnRequested, ItemReturned, ItemRefunded, ItemInitiatedRefund:
+		return true
+	}
+
+	return false
+}
+
+// CanTransitionTo reports whether an order item in this status may move to next.
+// Staying in the same status is always allowed so repeated updates are idempotent.
+func (o OrderItemStatus) CanTransitionTo(next OrderItemStatus) bool {
+	if o == next {
+		return true
+	}
+
+	for _, allowed := range orderItemTransitions[o] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CanRefundTransitionTo reports whether the refund flow may move an order item in this status to next.
+// A refund can be initiated for any item that isn't refunded yet, once the payment provider reports the
+// outcome the item is refunded or, when the refund failed, goes back to the status it had before.
+func (o OrderItemStatus) CanRefundTransitionTo(next OrderItemStatus) bool {
+	if o == next {
+		return true
+	}
+
+	switch {
+	case next == ItemInitiatedRefund:
+		return o.refundable()
+	case o == ItemInitiatedRefund:
+		return next == ItemRefunded || next.refundable()
+	}
+
+	return false
+}
+
+// refundable reports whether a refund can be initiated for an order item in this status.
+func (o OrderItemStatus) refundable() bool {
+	return o != ItemInitiatedRefund && o != ItemRefunded
+}
@@ -0,0 +1,99 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	addressEntities "github.com/nurdsoft/nurd-commerce-core/internal/address/entities"
+	cartEntities "github.com/nurdsoft/nurd-commerce-core/internal/cart/entities"
+	sharedJSON "github.com/nurdsoft/nurd-commerce-core/shared/json"
+	"github.com/shopspring/decimal"
+)
+
+type OutboxTopic string
+
+func (t OutboxTopic) String() string {
+	return string(t)
+}
+
+const (
+	// TopicOrderStatusChanged notifies the order webhook about a status change
+	TopicOrderStatusChanged OutboxTopic = "webhook.order_status_changed"
+	// TopicInventoryCreateOrder creates the order on the inventory provider
+	TopicInventoryCreateOrder OutboxTopic = "inventory.create_order"
+	// TopicInventoryUpdateOrderStatus updates the order status on the inventory provider
+	TopicInventoryUpdateOrderStatus OutboxTopic = "inventory.update_order_status"
+	// TopicReturnStatusChanged notifies the return webhook about a return status change
+	TopicReturnStatusChanged OutboxTopic = "webhook.return_status_changed"
+	// TopicOrderDisputeChanged notifies the dispute webhook about a payment dispute being opened or closed
+	TopicOrderDisputeChanged OutboxTopic = "webhook.order_dispute_changed"
+	// TopicPaymentCaptureOrder captures the payment held for a shipped order
+	TopicPaymentCaptureOrder OutboxTopic = "payment.capture_order"
+	// TopicPaymentVoidOrder releases the payment held for a cancelled order
+	TopicPaymentVoidOrder OutboxTopic = "payment.void_order"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "pending"
+	OutboxDone    OutboxStatus = "done"
+	// OutboxDead is used for messages that ran out of delivery attempts
+	OutboxDead OutboxStatus = "dead"
+)
+
+// OutboxMessage is a side effect of an order change. It is stored in the same transaction
+// as the change itself and delivered asynchronously by the outbox dispatcher.
+type OutboxMessage struct {
+	ID            uuid.UUID       `gorm:"column:id;default:gen_random_uuid()"`
+	Topic         OutboxTopic     `gorm:"column:topic"`
+	Payload       sharedJSON.JSON `gorm:"column:payload"`
+	Status        OutboxStatus    `gorm:"column:status;default:pending"`
+	Attempts      int             `gorm:"column:attempts"`
+	NextAttemptAt time.Time       `gorm:"column:next_attempt_at;default:now()"`
+	LastError     *string         `gorm:"column:last_error"`
+	CreatedAt     time.Time       `gorm:"column:created_at;default:now()"`
+	UpdatedAt     time.Time       `gorm:"column:updated_at;default:now()"`
+}
+
+func (m *OutboxMessage) TableName() string {
+	return "order_outbox"
+}
+
+// NewOutboxMessage builds a pending message for the topic with the JSON encoded payload.
+func NewOutboxMessage(topic OutboxTopic, payload any) (*OutboxMessage, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxMessage{
+		Topic:   topic,
+		Payload: body,
+		Status:  OutboxPending,
+	}, nil
+}
+
+// InventoryCreateOrderPayload holds what can't be loaded again once the order is placed,
+// the order itself and the customer are read fresh when the message is delivered.
+type InventoryCreateOrderPayload struct {
+	OrderID   uuid.UUID                     `json:"order_id"`
+	Address   addressEntities.Address       `json:"address"`
+	CartItems []cartEntities.CartItemDetail `json:"cart_items"`
+}
+
+type InventoryUpdateOrderStatusPayload struct {
+	OrderID uuid.UUID `json:"order_id"`
+	Status  string    `json:"status"`
+}
+
+// PaymentCaptureOrderPayload holds the amount worked out when the order shipped.
+type PaymentCaptureOrderPayload struct {
+	OrderID uuid.UUID       `json:"order_id"`
+	Amount  decimal.Decimal `json:"amount"`
+}
+
+type PaymentVoidOrderPayload struct {
+	OrderID uuid.UUID `json:"order_id"`
+}
@@ -0,0 +1,54 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReconcileOrdersRequest struct {
+	// From and To bound the creation date of the orders, To is exclusive
+	From time.Time
+	To   time.Time
+	// Fix applies the fixes that are safe to make without a human looking at the order
+	Fix bool
+}
+
+type MismatchField string
+
+func (f MismatchField) String() string {
+	return string(f)
+}
+
+const (
+	// MismatchPayment is an order whose payment couldn't be found at the payment provider
+	MismatchPayment       MismatchField = "payment"
+	MismatchTotal         MismatchField = "total"
+	MismatchStatus        MismatchField = "status"
+	MismatchCapturedTotal MismatchField = "captured_amount"
+	MismatchRefundTotal   MismatchField = "refund_total"
+	// MismatchRefundStatus is a refund that isn't in the same state in the order and at the payment provider
+	MismatchRefundStatus MismatchField = "refund_status"
+)
+
+// ReconciliationMismatch is a difference between an order and its payment at the payment provider.
+type ReconciliationMismatch struct {
+	OrderID        uuid.UUID     `json:"order_id"`
+	OrderReference string        `json:"order_reference"`
+	Field          MismatchField `json:"field"`
+	// RefundID is set for refund mismatches
+	RefundID      string `json:"refund_id,omitempty"`
+	OrderValue    string `json:"order_value"`
+	ProviderValue string `json:"provider_value"`
+	// Fixable mismatches are fixed when the reconciliation runs with Fix
+	Fixable bool   `json:"fixable"`
+	Fixed   bool   `json:"fixed"`
+	Error   string `json:"error,omitempty"`
+}
+
+type ReconciliationReport struct {
+	From          time.Time                 `json:"from"`
+	To            time.Time                 `json:"to"`
+	OrdersChecked int                       `json:"orders_checked"`
+	Mismatches    []*ReconciliationMismatch `json:"mismatches"`
+}
@@ -4,13 +4,19 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	addressEntities "github.com/nurdsoft/nurd-commerce-core/internal/address/entities"
 	"github.com/nurdsoft/nurd-commerce-core/internal/customer/entities"
 	"github.com/nurdsoft/nurd-commerce-core/shared/json"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 	"github.com/shopspring/decimal"
 )
 
 // swagger:parameters orders CreateOrderRequest
 type CreateOrderRequest struct {
+	// Idempotency key, retries sent with the same key replay the original response
+	//
+	// in:header
+	IdempotencyKey string `json:"Idempotency-Key"`
 	// Body of the request
 	//
 	// in:body
@@ -18,11 +24,31 @@ type CreateOrderRequest struct {
 }
 
 type CreateOrderRequestBody struct {
-	AddressID             uuid.UUID   `json:"address_id"`
-	ShippingRateID        *uuid.UUID  `json:"shipping_rate_id"`
-	StripePaymentMethodID string      `json:"stripe_payment_method_id,omitempty"`
-	PaymentNonce          string      `json:"payment_nonce,omitempty"`
-	BillingInfo           BillingInfo `json:"billing_info,omitzero"`
+	AddressID                    uuid.UUID   `json:"address_id"`
+	ShippingRateID               *uuid.UUID  `json:"shipping_rate_id"`
+	StripePaymentMethodID        string      `json:"stripe_payment_method_id,omitempty"`
+	AuthorizeNetPaymentProfileID string      `json:"authorizenet_payment_profile_id,omitempty"`
+	PaymentNonce                 string      `json:"payment_nonce,omitempty"`
+	BillingInfo                  BillingInfo `json:"billing_info,omitzero"`
+	// PaymentProvider to pay with, one of the providers listed by GET /payment/providers. When empty it's
+	// derived from the payment details given, or picked from the providers the customer has an account with,
+	// falling back to the default provider.
+	PaymentProvider providers.ProviderType `json:"payment_provider,omitempty"`
+	// Email of a guest checking out the cart of the x-cart-token header, the order is placed for a guest customer
+	// with the email.
+	Email string `json:"email,omitempty"`
+	// Address a guest ships the order to, guests don't have saved addresses to refer to with address_id.
+	Address *addressEntities.AddressRequestBody `json:"address,omitempty"`
+}
+
+// PaymentMethodID returns the saved payment method to charge with the provider, an Authorize.net payment
+// profile is charged instead of the payment nonce. The fake provider reads the Stripe payment method ID as a nonce.
+func (b *CreateOrderRequestBody) PaymentMethodID(provider providers.ProviderType) string {
+	if provider == providers.ProviderAuthorizeNet {
+		return b.AuthorizeNetPaymentProfileID
+	}
+
+	return b.StripePaymentMethodID
 }
 
 type BillingInfo struct {
@@ -42,6 +68,7 @@ type CreatePaymentRequest struct {
 	PaymentMethodId string
 	PaymentNonce    string
 	BillingInfo     BillingInfo
+	IdempotencyKey  string
 }
 
 // swagger:parameters orders ListOrdersRequest
@@ -73,6 +100,15 @@ type GetOrderRequest struct {
 	OrderID uuid.UUID `json:"order_id"`
 }
 
+// swagger:parameters orders GetOrderHistoryRequest
+type GetOrderHistoryRequest struct {
+	// Order ID
+	//
+	// required:true
+	// in:path
+	OrderID uuid.UUID `json:"order_id"`
+}
+
 // swagger:parameters orders CancelOrderRequest
 type CancelOrderRequest struct {
 	// Order ID
@@ -121,6 +157,10 @@ type RefundOrderRequest struct {
 	// required:true
 	// in:path
 	OrderReference string `json:"order_reference"`
+	// Idempotency key, retries sent with the same key replay the original response
+	//
+	// in:header
+	IdempotencyKey string `json:"Idempotency-Key"`
 	// Body of the request
 	//
 	// in:body
@@ -129,6 +169,8 @@ type RefundOrderRequest struct {
 
 type RefundOrderRequestBody struct {
 	Items []*RefundItem `json:"items"`
+	// Also refund the items' share of the shipping, refunding the whole order always does
+	IncludeShipping bool `json:"include_shipping"`
 }
 
 type RefundItem struct {
@@ -137,3 +179,83 @@ type RefundItem struct {
 	// Quantity of the item to refund
 	Quantity int `json:"quantity"`
 }
+
+// swagger:parameters orders CreateReturnRequest
+type CreateReturnRequest struct {
+	// Order ID
+	//
+	// required:true
+	// in:path
+	OrderID uuid.UUID `json:"order_id"`
+	// Body of the request
+	//
+	// in:body
+	Body *CreateReturnRequestBody
+}
+
+type CreateReturnRequestBody struct {
+	// Why the items are sent back
+	//
+	// required:true
+	Reason string `json:"reason"`
+	// Items to return
+	//
+	// required:true
+	Items []*ReturnItem `json:"items"`
+}
+
+type ReturnItem struct {
+	// SKU of the order item to return
+	Sku string `json:"sku"`
+	// Quantity of the item to return
+	Quantity int `json:"quantity"`
+}
+
+// swagger:parameters orders ListReturnsRequest
+type ListReturnsRequest struct {
+	// Order ID
+	//
+	// required:true
+	// in:path
+	OrderID uuid.UUID `json:"order_id"`
+}
+
+// swagger:parameters orders UpdateReturnRequest
+type UpdateReturnRequest struct {
+	// Return reference
+	//
+	// required:true
+	// in:path
+	ReturnReference string `json:"return_reference"`
+	// Body of the request
+	//
+	// in:body
+	Body *UpdateReturnRequestBody
+}
+
+type UpdateReturnRequestBody struct {
+	// New status of the return: approved, rejected or received
+	//
+	// required:true
+	Status ReturnStatus `json:"status"`
+	// Note for the customer, e.g. why the return was rejected
+	Note *string `json:"note,omitempty"`
+}
+
+// swagger:parameters orders RefundReturnRequest
+type RefundReturnRequest struct {
+	// Return reference
+	//
+	// required:true
+	// in:path
+	ReturnReference string `json:"return_reference"`
+	// Body of the request
+	//
+	// in:body
+	Body *RefundReturnRequestBody
+}
+
+type RefundReturnRequestBody struct {
+	// Also refund the returned items' share of the shipping
+	IncludeShipping bool `json:"include_shipping"`
+}
@@ -10,6 +10,26 @@ type CreateOrderResponse struct {
 	OrderReference string `json:"order_reference"`
 	// Order items
 	OrderItems []*OrderItem `json:"order_items"`
+	// Order status, requires_action when the customer has to complete the payment
+	//
+	// example: pending
+	Status OrderStatus `json:"status,omitempty"`
+	// Set when the order status is requires_action
+	PaymentAction *PaymentAction `json:"payment_action,omitempty"`
+}
+
+// PaymentAction is what the storefront needs to let the customer complete the payment,
+// e.g. passing the client secret to Stripe.js handleNextAction for 3-D Secure.
+// swagger:model PaymentAction
+type PaymentAction struct {
+	// Type of the action required by the payment provider
+	//
+	// example: use_stripe_sdk
+	Type string `json:"type"`
+	// Client secret of the payment
+	ClientSecret string `json:"client_secret"`
+	// URL to redirect the customer to, when the action is a redirect
+	RedirectURL string `json:"redirect_url,omitempty"`
 }
 
 // swagger:model ListOrdersResponse
@@ -19,8 +39,9 @@ type ListOrdersResponse struct {
 }
 
 type GetOrderData struct {
-	Order      *Order       `json:"order"`
-	OrderItems []*OrderItem `json:"order_items"`
+	Order      *Order        `json:"order"`
+	OrderItems []*OrderItem  `json:"order_items"`
+	History    []*OrderEvent `json:"history"`
 }
 
 // swagger:response GetOrderResponse
@@ -31,10 +52,21 @@ type GetOrderResponse struct {
 	}
 }
 
+// swagger:model GetOrderHistoryResponse
+type GetOrderHistoryResponse struct {
+	Events []*OrderEvent `json:"events"`
+}
+
 // swagger:model RefundOrderResponse
 type RefundOrderResponse struct {
 	// Total amount that will be refunded
 	TotalRefundableAmount decimal.Decimal `json:"total_refundable_amount"`
+	// Part of the total paid for the items themselves
+	ItemsAmount decimal.Decimal `json:"items_amount"`
+	// Part of the total paid as tax on the items
+	TaxAmount decimal.Decimal `json:"tax_amount"`
+	// Part of the total paid for shipping the items
+	ShippingAmount decimal.Decimal `json:"shipping_amount"`
 	// Items that will be refunded
 	RefundableItems []*RefundableItem `json:"refundable_items"`
 }
@@ -48,6 +80,25 @@ type RefundableItem struct {
 	Quantity int `json:"quantity"`
 	// Order Item Price that will be refunded
 	Price decimal.Decimal `json:"price"`
+	// Price of the refunded quantity
+	ItemsAmount decimal.Decimal `json:"items_amount"`
+	// Share of the order tax that will be refunded
+	TaxAmount decimal.Decimal `json:"tax_amount"`
+	// Share of the order shipping that will be refunded
+	ShippingAmount decimal.Decimal `json:"shipping_amount"`
 	// Refund Initiated
 	RefundInitiated bool `json:"refund_initiated"`
 }
+
+// swagger:model ListReturnsResponse
+type ListReturnsResponse struct {
+	Returns []*OrderReturn `json:"returns"`
+}
+
+// swagger:model RefundReturnResponse
+type RefundReturnResponse struct {
+	// The refunded return
+	Return *OrderReturn `json:"return"`
+	// Refund that was initiated for the returned items
+	Refund *RefundOrderResponse `json:"refund"`
+}
@@ -11,20 +11,42 @@ var moduleErrors = map[string]struct {
 	StatusCode int
 	Message    string
 }{
-	"ORDER_NOT_FOUND":               {StatusCode: http.StatusNotFound, Message: "Order not found."},
-	"ORDER_ERROR_GETTING_ITEMS":     {StatusCode: http.StatusInternalServerError, Message: "Error getting order items."},
-	"ORDER_ERROR_CREATING":          {StatusCode: http.StatusInternalServerError, Message: "Error creating order."},
-	"ORDER_ERROR_LISTING":           {StatusCode: http.StatusInternalServerError, Message: "Error listing orders."},
-	"ORDER_ID_REQUIRED":             {StatusCode: http.StatusBadRequest, Message: "Order ID is required."},
-	"ORDER_ERROR_GETTING":           {StatusCode: http.StatusInternalServerError, Message: "Error getting order."},
-	"ORDER_ERROR_CANCELLING":        {StatusCode: http.StatusInternalServerError, Message: "Error cancelling order."},
-	"ORDER_CANNOT_BE_CANCELLED":     {StatusCode: http.StatusInternalServerError, Message: "Order cannot be cancelled."},
-	"ORDER_IS_ALREADY_CANCELLED":    {StatusCode: http.StatusNotModified, Message: "Order is already cancelled."},
-	"ORDER_NOT_FOUND_BY_PAYMENT_ID": {StatusCode: http.StatusNotFound, Message: "Order not found by payment ID."},
-	"ORDER_IS_NOT_PENDING":          {StatusCode: http.StatusBadRequest, Message: "Order is not pending."},
-	"ORDER_REFUNDING_ERROR":         {StatusCode: http.StatusInternalServerError, Message: "Error refunding order."},
-	"ORDER_INVALID_ITEMS_DATA":      {StatusCode: http.StatusBadRequest, Message: "Invalid items data in order."},
-	"ORDER_INVALID_ITEM_IDENTIFIER": {StatusCode: http.StatusBadRequest, Message: "Invalid item identifier in order."},
+	"ORDER_NOT_FOUND":                    {StatusCode: http.StatusNotFound, Message: "Order not found."},
+	"ORDER_ERROR_GETTING_ITEMS":          {StatusCode: http.StatusInternalServerError, Message: "Error getting order items."},
+	"ORDER_ERROR_GETTING_HISTORY":        {StatusCode: http.StatusInternalServerError, Message: "Error getting order history."},
+	"ORDER_ERROR_CREATING":               {StatusCode: http.StatusInternalServerError, Message: "Error creating order."},
+	"ORDER_ERROR_LISTING":                {StatusCode: http.StatusInternalServerError, Message: "Error listing orders."},
+	"ORDER_ID_REQUIRED":                  {StatusCode: http.StatusBadRequest, Message: "Order ID is required."},
+	"ORDER_ERROR_GETTING":                {StatusCode: http.StatusInternalServerError, Message: "Error getting order."},
+	"ORDER_ERROR_CANCELLING":             {StatusCode: http.StatusInternalServerError, Message: "Error cancelling order."},
+	"ORDER_CANNOT_BE_CANCELLED":          {StatusCode: http.StatusInternalServerError, Message: "Order cannot be cancelled."},
+	"ORDER_IS_ALREADY_CANCELLED":         {StatusCode: http.StatusNotModified, Message: "Order is already cancelled."},
+	"ORDER_NOT_FOUND_BY_PAYMENT_ID":      {StatusCode: http.StatusNotFound, Message: "Order not found by payment ID."},
+	"ORDER_REFUNDING_ERROR":              {StatusCode: http.StatusInternalServerError, Message: "Error refunding order."},
+	"ORDER_PAYMENT_CAPTURE_ERROR":        {StatusCode: http.StatusInternalServerError, Message: "Error capturing order payment."},
+	"ORDER_PAYMENT_NOT_CAPTURED":         {StatusCode: http.StatusBadRequest, Message: "Order payment has not been captured yet."},
+	"ORDER_PAYMENT_PROVIDER_NOT_ENABLED": {StatusCode: http.StatusBadRequest, Message: "Payment provider is not enabled."},
+	"ORDER_PAYMENT_PROVIDER_AMBIGUOUS":   {StatusCode: http.StatusBadRequest, Message: "Payment details of more than one provider were given, set payment_provider."},
+	"ORDER_INVALID_ITEMS_DATA":           {StatusCode: http.StatusBadRequest, Message: "Invalid items data in order."},
+	"ORDER_INVALID_ITEM_IDENTIFIER":      {StatusCode: http.StatusBadRequest, Message: "Invalid item identifier in order."},
+	"ORDER_INVALID_STATUS":               {StatusCode: http.StatusBadRequest, Message: "Invalid order status."},
+	"ORDER_INVALID_ITEM_STATUS":          {StatusCode: http.StatusBadRequest, Message: "Invalid order item status."},
+	"ORDER_INVALID_STATUS_TRANSITION":    {StatusCode: http.StatusConflict, Message: "Order status transition is not allowed."},
+	"ORDER_INVALID_ITEM_TRANSITION":      {StatusCode: http.StatusConflict, Message: "Order item status transition is not allowed."},
+	"ORDER_STATUS_REQUIRES_REFUND":       {StatusCode: http.StatusConflict, Message: "Order status change requires a refund."},
+	"ORDER_IDEMPOTENCY_KEY_REUSED":       {StatusCode: http.StatusConflict, Message: "Idempotency key has already been used for a different request."},
+	"ORDER_IDEMPOTENCY_KEY_IN_PROGRESS":  {StatusCode: http.StatusConflict, Message: "A request with the same idempotency key is still being processed."},
+	"ORDER_IDEMPOTENCY_ERROR":            {StatusCode: http.StatusInternalServerError, Message: "Error processing idempotency key."},
+	"ORDER_GUEST_EMAIL_REQUIRED":         {StatusCode: http.StatusBadRequest, Message: "A valid email is required to check out as a guest."},
+	"ORDER_GUEST_ADDRESS_REQUIRED":       {StatusCode: http.StatusBadRequest, Message: "An address is required to check out as a guest."},
+	"ORDER_PROMOTION_LIMIT_REACHED":      {StatusCode: http.StatusConflict, Message: "A promotion applied to the cart reached its usage limit, the order was not placed."},
+	"RETURN_NOT_FOUND":                   {StatusCode: http.StatusNotFound, Message: "Return not found."},
+	"RETURN_NOT_ALLOWED":                 {StatusCode: http.StatusBadRequest, Message: "Order is not eligible for a return."},
+	"RETURN_INVALID_ITEMS":               {StatusCode: http.StatusBadRequest, Message: "Invalid items in return."},
+	"RETURN_INVALID_STATUS_TRANSITION":   {StatusCode: http.StatusConflict, Message: "Return status transition is not allowed."},
+	"RETURN_ERROR_CREATING":              {StatusCode: http.StatusInternalServerError, Message: "Error creating return."},
+	"RETURN_ERROR_UPDATING":              {StatusCode: http.StatusInternalServerError, Message: "Error updating return."},
+	"RETURN_ERROR_GETTING":               {StatusCode: http.StatusInternalServerError, Message: "Error getting returns."},
 }
 
 func NewAPIError(errorCode string, customMessage ...string) *errors.APIError {
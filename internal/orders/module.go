@@ -36,7 +36,8 @@ type ModuleParams struct {
 	Logger          *zap.SugaredLogger
 	CustomerClient  customerclient.Client
 	CartClient      cart.Client
-	PaymentClient   payment.Client
+	Payments        payment.Registry
+	PaymentConfig   payment.Config
 	WishlistClient  wishlistclient.Client
 	InventoryClient inventory.Client
 	AddressClient   addressclient.Client
@@ -48,8 +49,8 @@ type ModuleParams struct {
 // nolint:gocritic
 func NewModule(p ModuleParams) error {
 	repo := repository.New(p.DB, p.GormDB)
-	svc := service.New(repo, p.Logger, p.CustomerClient, p.CartClient, p.PaymentClient, p.WishlistClient,
-		p.CommonConfig, p.InventoryClient, p.AddressClient, p.ProductClient, p.WebhookClient)
+	svc := service.New(repo, p.Logger, p.CustomerClient, p.CartClient, p.Payments, p.WishlistClient,
+		p.CommonConfig, p.InventoryClient, p.AddressClient, p.ProductClient, p.WebhookClient, p.PaymentConfig)
 	eps := endpoints.New(svc)
 
 	http.RegisterTransport(p.HTTPServer, eps, p.APPTransport)
@@ -57,7 +58,36 @@ func NewModule(p ModuleParams) error {
 	return nil
 }
 
+// ServiceParams for the orders service run by the background workers.
+type ServiceParams struct {
+	fx.In
+
+	DB              *sql.DB
+	GormDB          *gorm.DB
+	CommonConfig    cfg.Config
+	Logger          *zap.SugaredLogger
+	CustomerClient  customerclient.Client
+	CartClient      cart.Client
+	Payments        payment.Registry
+	PaymentConfig   payment.Config
+	WishlistClient  wishlistclient.Client
+	InventoryClient inventory.Client
+	AddressClient   addressclient.Client
+	ProductClient   productclient.Client
+	WebhookClient   webhookClient.Client
+}
+
+// NewService builds the orders service once for the workers that depend on it.
+// nolint:gocritic
+func NewService(p ServiceParams) service.Service {
+	repo := repository.New(p.DB, p.GormDB)
+	return service.New(repo, p.Logger, p.CustomerClient, p.CartClient, p.Payments, p.WishlistClient,
+		p.CommonConfig, p.InventoryClient, p.AddressClient, p.ProductClient, p.WebhookClient, p.PaymentConfig)
+}
+
 var (
 	// ModuleHttpAPI for uber fx.
 	ModuleHttpAPI = fx.Options(fx.Invoke(NewModule))
+	// ModuleService provides the orders service for uber fx.
+	ModuleService = fx.Options(fx.Provide(NewService))
 )
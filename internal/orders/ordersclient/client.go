@@ -5,14 +5,18 @@ import (
 
 	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
 	"github.com/nurdsoft/nurd-commerce-core/internal/orders/service"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 	"github.com/shopspring/decimal"
 )
 
 type Client interface {
-	ProcessPaymentSucceeded(ctx context.Context, paymentID string) error
-	ProcessPaymentFailed(ctx context.Context, paymentID string) error
+	ProcessPaymentSucceeded(ctx context.Context, provider providers.ProviderType, paymentID string) error
+	ProcessPaymentFailed(ctx context.Context, provider providers.ProviderType, paymentID string) error
 	ProcessOrderStatus(ctx context.Context, req *entities.UpdateOrderRequest) error
 	ProcessRefundSucceeded(ctx context.Context, refundId string, refundAmount decimal.Decimal) error
+	ProcessRefundFailed(ctx context.Context, refundID string, reason string) error
+	ProcessPaymentDisputed(ctx context.Context, dispute *entities.PaymentDispute) error
+	ReconcileOrders(ctx context.Context, req *entities.ReconcileOrdersRequest) (*entities.ReconciliationReport, error)
 }
 
 func NewClient(svc service.Service) Client {
@@ -23,12 +27,12 @@ type localClient struct {
 	svc service.Service
 }
 
-func (c *localClient) ProcessPaymentSucceeded(ctx context.Context, paymentID string) error {
-	return c.svc.ProcessPaymentSucceeded(ctx, paymentID)
+func (c *localClient) ProcessPaymentSucceeded(ctx context.Context, provider providers.ProviderType, paymentID string) error {
+	return c.svc.ProcessPaymentSucceeded(ctx, provider, paymentID)
 }
 
-func (c *localClient) ProcessPaymentFailed(ctx context.Context, paymentID string) error {
-	return c.svc.ProcessPaymentFailed(ctx, paymentID)
+func (c *localClient) ProcessPaymentFailed(ctx context.Context, provider providers.ProviderType, paymentID string) error {
+	return c.svc.ProcessPaymentFailed(ctx, provider, paymentID)
 }
 
 func (c *localClient) ProcessOrderStatus(ctx context.Context, req *entities.UpdateOrderRequest) error {
@@ -38,3 +42,15 @@ func (c *localClient) ProcessOrderStatus(ctx context.Context, req *entities.Upda
 func (c *localClient) ProcessRefundSucceeded(ctx context.Context, refundId string, refundAmount decimal.Decimal) error {
 	return c.svc.ProcessRefundSucceeded(ctx, refundId, refundAmount)
 }
+
+func (c *localClient) ProcessRefundFailed(ctx context.Context, refundID string, reason string) error {
+	return c.svc.ProcessRefundFailed(ctx, refundID, reason)
+}
+
+func (c *localClient) ProcessPaymentDisputed(ctx context.Context, dispute *entities.PaymentDispute) error {
+	return c.svc.ProcessPaymentDisputed(ctx, dispute)
+}
+
+func (c *localClient) ReconcileOrders(ctx context.Context, req *entities.ReconcileOrdersRequest) (*entities.ReconciliationReport, error) {
+	return c.svc.ReconcileOrders(ctx, req)
+}
@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	entities "github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	providers "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 	decimal "github.com/shopspring/decimal"
 )
 
@@ -50,32 +51,60 @@ func (mr *MockClientMockRecorder) ProcessOrderStatus(ctx, req interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessOrderStatus", reflect.TypeOf((*MockClient)(nil).ProcessOrderStatus), ctx, req)
 }
 
+// ProcessPaymentDisputed mocks base method.
+func (m *MockClient) ProcessPaymentDisputed(ctx context.Context, dispute *entities.PaymentDispute) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessPaymentDisputed", ctx, dispute)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessPaymentDisputed indicates an expected call of ProcessPaymentDisputed.
+func (mr *MockClientMockRecorder) ProcessPaymentDisputed(ctx, dispute interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessPaymentDisputed", reflect.TypeOf((*MockClient)(nil).ProcessPaymentDisputed), ctx, dispute)
+}
+
 // ProcessPaymentFailed mocks base method.
-func (m *MockClient) ProcessPaymentFailed(ctx context.Context, paymentID string) error {
+func (m *MockClient) ProcessPaymentFailed(ctx context.Context, provider providers.ProviderType, paymentID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ProcessPaymentFailed", ctx, paymentID)
+	ret := m.ctrl.Call(m, "ProcessPaymentFailed", ctx, provider, paymentID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ProcessPaymentFailed indicates an expected call of ProcessPaymentFailed.
-func (mr *MockClientMockRecorder) ProcessPaymentFailed(ctx, paymentID interface{}) *gomock.Call {
+func (mr *MockClientMockRecorder) ProcessPaymentFailed(ctx, provider, paymentID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessPaymentFailed", reflect.TypeOf((*MockClient)(nil).ProcessPaymentFailed), ctx, paymentID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessPaymentFailed", reflect.TypeOf((*MockClient)(nil).ProcessPaymentFailed), ctx, provider, paymentID)
 }
 
 // ProcessPaymentSucceeded mocks base method.
-func (m *MockClient) ProcessPaymentSucceeded(ctx context.Context, paymentID string) error {
+func (m *MockClient) ProcessPaymentSucceeded(ctx context.Context, provider providers.ProviderType, paymentID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ProcessPaymentSucceeded", ctx, paymentID)
+	ret := m.ctrl.Call(m, "ProcessPaymentSucceeded", ctx, provider, paymentID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ProcessPaymentSucceeded indicates an expected call of ProcessPaymentSucceeded.
-func (mr *MockClientMockRecorder) ProcessPaymentSucceeded(ctx, paymentID interface{}) *gomock.Call {
+func (mr *MockClientMockRecorder) ProcessPaymentSucceeded(ctx, provider, paymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessPaymentSucceeded", reflect.TypeOf((*MockClient)(nil).ProcessPaymentSucceeded), ctx, provider, paymentID)
+}
+
+// ProcessRefundFailed mocks base method.
+func (m *MockClient) ProcessRefundFailed(ctx context.Context, refundID, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessRefundFailed", ctx, refundID, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessRefundFailed indicates an expected call of ProcessRefundFailed.
+func (mr *MockClientMockRecorder) ProcessRefundFailed(ctx, refundID, reason interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessPaymentSucceeded", reflect.TypeOf((*MockClient)(nil).ProcessPaymentSucceeded), ctx, paymentID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessRefundFailed", reflect.TypeOf((*MockClient)(nil).ProcessRefundFailed), ctx, refundID, reason)
 }
 
 // ProcessRefundSucceeded mocks base method.
@@ -91,3 +120,18 @@ func (mr *MockClientMockRecorder) ProcessRefundSucceeded(ctx, refundId, refundAm
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessRefundSucceeded", reflect.TypeOf((*MockClient)(nil).ProcessRefundSucceeded), ctx, refundId, refundAmount)
 }
+
+// ReconcileOrders mocks base method.
+func (m *MockClient) ReconcileOrders(ctx context.Context, req *entities.ReconcileOrdersRequest) (*entities.ReconciliationReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileOrders", ctx, req)
+	ret0, _ := ret[0].(*entities.ReconciliationReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReconcileOrders indicates an expected call of ReconcileOrders.
+func (mr *MockClientMockRecorder) ReconcileOrders(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileOrders", reflect.TypeOf((*MockClient)(nil).ReconcileOrders), ctx, req)
+}
@@ -33,7 +33,8 @@ type ModuleParams struct {
 	CommonConfig    cfg.Config
 	Logger          *zap.SugaredLogger
 	CartClient      cart.Client
-	PaymentClient   payment.Client
+	Payments        payment.Registry
+	PaymentConfig   payment.Config
 	WishlistClient  wishlistclient.Client
 	InventoryClient inventory.Client
 	CustomerClient  customerclient.Client
@@ -47,8 +48,8 @@ type ModuleParams struct {
 func NewClientModule(p ModuleParams) Client {
 	repo := repository.New(p.DB, p.GormDB)
 	svc := service.New(
-		repo, p.Logger, p.CustomerClient, p.CartClient, p.PaymentClient,
-		p.WishlistClient, p.CommonConfig, p.InventoryClient, p.AddressClient, p.ProductClient, p.WebhookClient)
+		repo, p.Logger, p.CustomerClient, p.CartClient, p.Payments,
+		p.WishlistClient, p.CommonConfig, p.InventoryClient, p.AddressClient, p.ProductClient, p.WebhookClient, p.PaymentConfig)
 
 	client := NewClient(svc)
 
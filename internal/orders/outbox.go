@@ -0,0 +1,110 @@
+package orders
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/service"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	outboxPollInterval = 5 * time.Second
+	outboxBatchSize    = 50
+)
+
+var outboxDepthGauge = prom.NewGauge(prom.GaugeOpts{
+	Namespace: "commerce_core",
+	Name:      "order_outbox_depth",
+	Help:      "Number of order outbox messages waiting to be delivered.",
+})
+
+func init() {
+	prom.MustRegister(outboxDepthGauge)
+}
+
+// OutboxDispatcherParams for the outbox dispatcher.
+type OutboxDispatcherParams struct {
+	fx.In
+
+	Logger  *zap.SugaredLogger
+	Service service.Service
+}
+
+// NewOutboxDispatcher polls the order outbox and delivers pending side effects
+// (webhooks, inventory updates) until the application stops.
+// nolint:gocritic
+func NewOutboxDispatcher(lc fx.Lifecycle, p OutboxDispatcherParams) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			p.Logger.Info("starting order outbox dispatcher")
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				runOutboxDispatcher(ctx, p.Service, p.Logger)
+			}()
+
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			p.Logger.Info("stopping order outbox dispatcher")
+			cancel()
+
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	})
+}
+
+func runOutboxDispatcher(ctx context.Context, svc service.Service, log *zap.SugaredLogger) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// keep draining while full batches are returned
+		for ctx.Err() == nil {
+			claimed, err := svc.DispatchOutbox(ctx, outboxBatchSize)
+			if err != nil {
+				log.Errorf("Error dispatching order outbox: %v", err)
+				break
+			}
+			if claimed < outboxBatchSize {
+				break
+			}
+		}
+
+		if depth, err := svc.OutboxDepth(ctx); err == nil {
+			outboxDepthGauge.Set(float64(depth))
+		} else if ctx.Err() == nil {
+			log.Errorf("Error reading order outbox depth: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+var (
+	// ModuleOutboxDispatcher for uber fx.
+	ModuleOutboxDispatcher = fx.Options(fx.Invoke(NewOutboxDispatcher))
+)
@@ -7,10 +7,12 @@ package repository
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
 	entities "github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	providers "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 )
 
 // MockRepository is a mock of Repository interface.
@@ -50,33 +52,92 @@ func (mr *MockRepositoryMockRecorder) AddSalesforceIDPerOrderItem(ctx, ids inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSalesforceIDPerOrderItem", reflect.TypeOf((*MockRepository)(nil).AddSalesforceIDPerOrderItem), ctx, ids)
 }
 
+// ClaimIdempotencyKey mocks base method.
+func (m *MockRepository) ClaimIdempotencyKey(ctx context.Context, record *entities.IdempotencyKey, lease time.Duration) (*entities.IdempotencyKey, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimIdempotencyKey", ctx, record, lease)
+	ret0, _ := ret[0].(*entities.IdempotencyKey)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ClaimIdempotencyKey indicates an expected call of ClaimIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) ClaimIdempotencyKey(ctx, record, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).ClaimIdempotencyKey), ctx, record, lease)
+}
+
+// ClaimOutboxMessages mocks base method.
+func (m *MockRepository) ClaimOutboxMessages(ctx context.Context, limit int, lease time.Duration) ([]*entities.OutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimOutboxMessages", ctx, limit, lease)
+	ret0, _ := ret[0].([]*entities.OutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimOutboxMessages indicates an expected call of ClaimOutboxMessages.
+func (mr *MockRepositoryMockRecorder) ClaimOutboxMessages(ctx, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboxMessages", reflect.TypeOf((*MockRepository)(nil).ClaimOutboxMessages), ctx, limit, lease)
+}
+
+// CountPendingOutboxMessages mocks base method.
+func (m *MockRepository) CountPendingOutboxMessages(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPendingOutboxMessages", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPendingOutboxMessages indicates an expected call of CountPendingOutboxMessages.
+func (mr *MockRepositoryMockRecorder) CountPendingOutboxMessages(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPendingOutboxMessages", reflect.TypeOf((*MockRepository)(nil).CountPendingOutboxMessages), ctx)
+}
+
 // CreateOrder mocks base method.
-func (m *MockRepository) CreateOrder(ctx context.Context, cartID uuid.UUID, order *entities.Order, orderItems []*entities.OrderItem) error {
+func (m *MockRepository) CreateOrder(ctx context.Context, cartID uuid.UUID, order *entities.Order, orderItems []*entities.OrderItem, source entities.OrderEventSource, outbox []*entities.OutboxMessage) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateOrder", ctx, cartID, order, orderItems)
+	ret := m.ctrl.Call(m, "CreateOrder", ctx, cartID, order, orderItems, source, outbox)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateOrder indicates an expected call of CreateOrder.
-func (mr *MockRepositoryMockRecorder) CreateOrder(ctx, cartID, order, orderItems interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) CreateOrder(ctx, cartID, order, orderItems, source, outbox interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockRepository)(nil).CreateOrder), ctx, cartID, order, orderItems)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockRepository)(nil).CreateOrder), ctx, cartID, order, orderItems, source, outbox)
 }
 
-// GetOrderByAuthorizeNetPaymentID mocks base method.
-func (m *MockRepository) GetOrderByAuthorizeNetPaymentID(ctx context.Context, authorizeNetPaymentID string) (*entities.Order, error) {
+// CreateReturn mocks base method.
+func (m *MockRepository) CreateReturn(ctx context.Context, orderReturn *entities.OrderReturn, orderData, orderItemsData map[string]interface{}, source entities.OrderEventSource, outbox []*entities.OutboxMessage) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrderByAuthorizeNetPaymentID", ctx, authorizeNetPaymentID)
-	ret0, _ := ret[0].(*entities.Order)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateReturn", ctx, orderReturn, orderData, orderItemsData, source, outbox)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetOrderByAuthorizeNetPaymentID indicates an expected call of GetOrderByAuthorizeNetPaymentID.
-func (mr *MockRepositoryMockRecorder) GetOrderByAuthorizeNetPaymentID(ctx, authorizeNetPaymentID interface{}) *gomock.Call {
+// CreateReturn indicates an expected call of CreateReturn.
+func (mr *MockRepositoryMockRecorder) CreateReturn(ctx, orderReturn, orderData, orderItemsData, source, outbox interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByAuthorizeNetPaymentID", reflect.TypeOf((*MockRepository)(nil).GetOrderByAuthorizeNetPaymentID), ctx, authorizeNetPaymentID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReturn", reflect.TypeOf((*MockRepository)(nil).CreateReturn), ctx, orderReturn, orderData, orderItemsData, source, outbox)
+}
+
+// DeleteIdempotencyKey mocks base method.
+func (m *MockRepository) DeleteIdempotencyKey(ctx context.Context, scope, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", ctx, scope, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) DeleteIdempotencyKey(ctx, scope, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).DeleteIdempotencyKey), ctx, scope, key)
 }
 
 // GetOrderByID mocks base method.
@@ -94,6 +155,21 @@ func (mr *MockRepositoryMockRecorder) GetOrderByID(ctx, orderID interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByID", reflect.TypeOf((*MockRepository)(nil).GetOrderByID), ctx, orderID)
 }
 
+// GetOrderByPaymentID mocks base method.
+func (m *MockRepository) GetOrderByPaymentID(ctx context.Context, provider providers.ProviderType, paymentID string) (*entities.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderByPaymentID", ctx, provider, paymentID)
+	ret0, _ := ret[0].(*entities.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderByPaymentID indicates an expected call of GetOrderByPaymentID.
+func (mr *MockRepositoryMockRecorder) GetOrderByPaymentID(ctx, provider, paymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByPaymentID", reflect.TypeOf((*MockRepository)(nil).GetOrderByPaymentID), ctx, provider, paymentID)
+}
+
 // GetOrderByReference mocks base method.
 func (m *MockRepository) GetOrderByReference(ctx context.Context, orderReference string) (*entities.Order, error) {
 	m.ctrl.T.Helper()
@@ -109,19 +185,34 @@ func (mr *MockRepositoryMockRecorder) GetOrderByReference(ctx, orderReference in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByReference", reflect.TypeOf((*MockRepository)(nil).GetOrderByReference), ctx, orderReference)
 }
 
-// GetOrderByStripePaymentIntentID mocks base method.
-func (m *MockRepository) GetOrderByStripePaymentIntentID(ctx context.Context, stripePaymentIntentID string) (*entities.Order, error) {
+// GetOrderDiscounts mocks base method.
+func (m *MockRepository) GetOrderDiscounts(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderDiscount, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrderByStripePaymentIntentID", ctx, stripePaymentIntentID)
-	ret0, _ := ret[0].(*entities.Order)
+	ret := m.ctrl.Call(m, "GetOrderDiscounts", ctx, orderID)
+	ret0, _ := ret[0].([]*entities.OrderDiscount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderDiscounts indicates an expected call of GetOrderDiscounts.
+func (mr *MockRepositoryMockRecorder) GetOrderDiscounts(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderDiscounts", reflect.TypeOf((*MockRepository)(nil).GetOrderDiscounts), ctx, orderID)
+}
+
+// GetOrderEvents mocks base method.
+func (m *MockRepository) GetOrderEvents(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderEvents", ctx, orderID)
+	ret0, _ := ret[0].([]*entities.OrderEvent)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetOrderByStripePaymentIntentID indicates an expected call of GetOrderByStripePaymentIntentID.
-func (mr *MockRepositoryMockRecorder) GetOrderByStripePaymentIntentID(ctx, stripePaymentIntentID interface{}) *gomock.Call {
+// GetOrderEvents indicates an expected call of GetOrderEvents.
+func (mr *MockRepositoryMockRecorder) GetOrderEvents(ctx, orderID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByStripePaymentIntentID", reflect.TypeOf((*MockRepository)(nil).GetOrderByStripePaymentIntentID), ctx, stripePaymentIntentID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderEvents", reflect.TypeOf((*MockRepository)(nil).GetOrderEvents), ctx, orderID)
 }
 
 // GetOrderItemsByID mocks base method.
@@ -139,19 +230,49 @@ func (mr *MockRepositoryMockRecorder) GetOrderItemsByID(ctx, orderID interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderItemsByID", reflect.TypeOf((*MockRepository)(nil).GetOrderItemsByID), ctx, orderID)
 }
 
-// GetOrderItemsByStripeRefundID mocks base method.
-func (m *MockRepository) GetOrderItemsByStripeRefundID(ctx context.Context, stripeRefundID string) ([]*entities.OrderItem, error) {
+// GetOrderItemsByRefundID mocks base method.
+func (m *MockRepository) GetOrderItemsByRefundID(ctx context.Context, refundID string) ([]*entities.OrderItem, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrderItemsByStripeRefundID", ctx, stripeRefundID)
+	ret := m.ctrl.Call(m, "GetOrderItemsByRefundID", ctx, refundID)
 	ret0, _ := ret[0].([]*entities.OrderItem)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetOrderItemsByStripeRefundID indicates an expected call of GetOrderItemsByStripeRefundID.
-func (mr *MockRepositoryMockRecorder) GetOrderItemsByStripeRefundID(ctx, stripeRefundID interface{}) *gomock.Call {
+// GetOrderItemsByRefundID indicates an expected call of GetOrderItemsByRefundID.
+func (mr *MockRepositoryMockRecorder) GetOrderItemsByRefundID(ctx, refundID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderItemsByRefundID", reflect.TypeOf((*MockRepository)(nil).GetOrderItemsByRefundID), ctx, refundID)
+}
+
+// GetReturnByReference mocks base method.
+func (m *MockRepository) GetReturnByReference(ctx context.Context, returnReference string) (*entities.OrderReturn, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReturnByReference", ctx, returnReference)
+	ret0, _ := ret[0].(*entities.OrderReturn)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReturnByReference indicates an expected call of GetReturnByReference.
+func (mr *MockRepositoryMockRecorder) GetReturnByReference(ctx, returnReference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReturnByReference", reflect.TypeOf((*MockRepository)(nil).GetReturnByReference), ctx, returnReference)
+}
+
+// GetReturnsByOrderID mocks base method.
+func (m *MockRepository) GetReturnsByOrderID(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderReturn, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReturnsByOrderID", ctx, orderID)
+	ret0, _ := ret[0].([]*entities.OrderReturn)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReturnsByOrderID indicates an expected call of GetReturnsByOrderID.
+func (mr *MockRepositoryMockRecorder) GetReturnsByOrderID(ctx, orderID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderItemsByStripeRefundID", reflect.TypeOf((*MockRepository)(nil).GetOrderItemsByStripeRefundID), ctx, stripeRefundID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReturnsByOrderID", reflect.TypeOf((*MockRepository)(nil).GetReturnsByOrderID), ctx, orderID)
 }
 
 // ListOrders mocks base method.
@@ -170,6 +291,64 @@ func (mr *MockRepositoryMockRecorder) ListOrders(ctx, customerID, limit, cursor,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockRepository)(nil).ListOrders), ctx, customerID, limit, cursor, includeItems)
 }
 
+// ListOrdersCreatedBetween mocks base method.
+func (m *MockRepository) ListOrdersCreatedBetween(ctx context.Context, from, to, afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]*entities.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrdersCreatedBetween", ctx, from, to, afterCreatedAt, afterID, limit)
+	ret0, _ := ret[0].([]*entities.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrdersCreatedBetween indicates an expected call of ListOrdersCreatedBetween.
+func (mr *MockRepositoryMockRecorder) ListOrdersCreatedBetween(ctx, from, to, afterCreatedAt, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrdersCreatedBetween", reflect.TypeOf((*MockRepository)(nil).ListOrdersCreatedBetween), ctx, from, to, afterCreatedAt, afterID, limit)
+}
+
+// ListStaleOrders mocks base method.
+func (m *MockRepository) ListStaleOrders(ctx context.Context, statuses []entities.OrderStatus, createdBefore time.Time, limit int) ([]*entities.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStaleOrders", ctx, statuses, createdBefore, limit)
+	ret0, _ := ret[0].([]*entities.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStaleOrders indicates an expected call of ListStaleOrders.
+func (mr *MockRepositoryMockRecorder) ListStaleOrders(ctx, statuses, createdBefore, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStaleOrders", reflect.TypeOf((*MockRepository)(nil).ListStaleOrders), ctx, statuses, createdBefore, limit)
+}
+
+// MarkOutboxMessageDone mocks base method.
+func (m *MockRepository) MarkOutboxMessageDone(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxMessageDone", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxMessageDone indicates an expected call of MarkOutboxMessageDone.
+func (mr *MockRepositoryMockRecorder) MarkOutboxMessageDone(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxMessageDone", reflect.TypeOf((*MockRepository)(nil).MarkOutboxMessageDone), ctx, id)
+}
+
+// MarkOutboxMessageFailed mocks base method.
+func (m *MockRepository) MarkOutboxMessageFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxMessageFailed", ctx, id, lastError, nextAttemptAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxMessageFailed indicates an expected call of MarkOutboxMessageFailed.
+func (mr *MockRepositoryMockRecorder) MarkOutboxMessageFailed(ctx, id, lastError, nextAttemptAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxMessageFailed", reflect.TypeOf((*MockRepository)(nil).MarkOutboxMessageFailed), ctx, id, lastError, nextAttemptAt)
+}
+
 // OrderReferenceExists mocks base method.
 func (m *MockRepository) OrderReferenceExists(ctx context.Context, orderReference string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -185,30 +364,73 @@ func (mr *MockRepositoryMockRecorder) OrderReferenceExists(ctx, orderReference i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrderReferenceExists", reflect.TypeOf((*MockRepository)(nil).OrderReferenceExists), ctx, orderReference)
 }
 
+// ReturnReferenceExists mocks base method.
+func (m *MockRepository) ReturnReferenceExists(ctx context.Context, returnReference string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReturnReferenceExists", ctx, returnReference)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReturnReferenceExists indicates an expected call of ReturnReferenceExists.
+func (mr *MockRepositoryMockRecorder) ReturnReferenceExists(ctx, returnReference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReturnReferenceExists", reflect.TypeOf((*MockRepository)(nil).ReturnReferenceExists), ctx, returnReference)
+}
+
+// SaveIdempotencyResponse mocks base method.
+func (m *MockRepository) SaveIdempotencyResponse(ctx context.Context, scope, key string, response []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveIdempotencyResponse", ctx, scope, key, response)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveIdempotencyResponse indicates an expected call of SaveIdempotencyResponse.
+func (mr *MockRepositoryMockRecorder) SaveIdempotencyResponse(ctx, scope, key, response interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIdempotencyResponse", reflect.TypeOf((*MockRepository)(nil).SaveIdempotencyResponse), ctx, scope, key, response)
+}
+
 // Update mocks base method.
-func (m *MockRepository) Update(ctx context.Context, details map[string]interface{}, orderID, customerID string) error {
+func (m *MockRepository) Update(ctx context.Context, details map[string]interface{}, orderID, customerID string, source entities.OrderEventSource, outbox []*entities.OutboxMessage) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, details, orderID, customerID)
+	ret := m.ctrl.Call(m, "Update", ctx, details, orderID, customerID, source, outbox)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockRepositoryMockRecorder) Update(ctx, details, orderID, customerID interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Update(ctx, details, orderID, customerID, source, outbox interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), ctx, details, orderID, customerID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), ctx, details, orderID, customerID, source, outbox)
 }
 
 // UpdateOrderWithOrderItems mocks base method.
-func (m *MockRepository) UpdateOrderWithOrderItems(ctx context.Context, orderID uuid.UUID, orderData, orderItemsData map[string]interface{}) error {
+func (m *MockRepository) UpdateOrderWithOrderItems(ctx context.Context, orderID uuid.UUID, orderData, orderItemsData map[string]interface{}, source entities.OrderEventSource, outbox []*entities.OutboxMessage) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateOrderWithOrderItems", ctx, orderID, orderData, orderItemsData)
+	ret := m.ctrl.Call(m, "UpdateOrderWithOrderItems", ctx, orderID, orderData, orderItemsData, source, outbox)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateOrderWithOrderItems indicates an expected call of UpdateOrderWithOrderItems.
-func (mr *MockRepositoryMockRecorder) UpdateOrderWithOrderItems(ctx, orderID, orderData, orderItemsData interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) UpdateOrderWithOrderItems(ctx, orderID, orderData, orderItemsData, source, outbox interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderWithOrderItems", reflect.TypeOf((*MockRepository)(nil).UpdateOrderWithOrderItems), ctx, orderID, orderData, orderItemsData, source, outbox)
+}
+
+// UpdateReturn mocks base method.
+func (m *MockRepository) UpdateReturn(ctx context.Context, orderReturn *entities.OrderReturn, fromStatus entities.ReturnStatus, returnData, orderData, orderItemsData map[string]interface{}, source entities.OrderEventSource, outbox []*entities.OutboxMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReturn", ctx, orderReturn, fromStatus, returnData, orderData, orderItemsData, source, outbox)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReturn indicates an expected call of UpdateReturn.
+func (mr *MockRepositoryMockRecorder) UpdateReturn(ctx, orderReturn, fromStatus, returnData, orderData, orderItemsData, source, outbox interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderWithOrderItems", reflect.TypeOf((*MockRepository)(nil).UpdateOrderWithOrderItems), ctx, orderID, orderData, orderItemsData)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReturn", reflect.TypeOf((*MockRepository)(nil).UpdateReturn), ctx, orderReturn, fromStatus, returnData, orderData, orderItemsData, source, outbox)
 }
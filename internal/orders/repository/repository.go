@@ -3,25 +3,42 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 	"gorm.io/gorm"
 )
 
 type Repository interface {
-	CreateOrder(ctx context.Context, cartID uuid.UUID, order *entities.Order, orderItems []*entities.OrderItem) error
+	CreateOrder(ctx context.Context, cartID uuid.UUID, order *entities.Order, orderItems []*entities.OrderItem, source entities.OrderEventSource, outbox []*entities.OutboxMessage) error
 	ListOrders(ctx context.Context, customerID uuid.UUID, limit int, cursor string, includeItems bool) ([]*entities.Order, string, error)
-	Update(ctx context.Context, details map[string]interface{}, orderID string, customerID string) error
+	Update(ctx context.Context, details map[string]interface{}, orderID string, customerID string, source entities.OrderEventSource, outbox []*entities.OutboxMessage) error
 	GetOrderByID(ctx context.Context, orderID uuid.UUID) (*entities.Order, error)
 	GetOrderItemsByID(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderItem, error)
 	AddSalesforceIDPerOrderItem(ctx context.Context, ids map[string]string) error
 	OrderReferenceExists(ctx context.Context, orderReference string) (bool, error)
 	GetOrderByReference(ctx context.Context, orderReference string) (*entities.Order, error)
-	GetOrderByStripePaymentIntentID(ctx context.Context, stripePaymentIntentID string) (*entities.Order, error)
-	GetOrderByAuthorizeNetPaymentID(ctx context.Context, authorizeNetPaymentID string) (*entities.Order, error)
-	UpdateOrderWithOrderItems(ctx context.Context, orderID uuid.UUID, orderData map[string]interface{}, orderItemsData map[string]interface{}) error
-	GetOrderItemsByStripeRefundID(ctx context.Context, stripeRefundID string) ([]*entities.OrderItem, error)
+	GetOrderByPaymentID(ctx context.Context, provider providers.ProviderType, paymentID string) (*entities.Order, error)
+	ListStaleOrders(ctx context.Context, statuses []entities.OrderStatus, createdBefore time.Time, limit int) ([]*entities.Order, error)
+	ListOrdersCreatedBetween(ctx context.Context, from, to time.Time, afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]*entities.Order, error)
+	UpdateOrderWithOrderItems(ctx context.Context, orderID uuid.UUID, orderData map[string]interface{}, orderItemsData map[string]interface{}, source entities.OrderEventSource, outbox []*entities.OutboxMessage) error
+	GetOrderItemsByRefundID(ctx context.Context, refundID string) ([]*entities.OrderItem, error)
+	GetOrderEvents(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderEvent, error)
+	GetOrderDiscounts(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderDiscount, error)
+	ClaimIdempotencyKey(ctx context.Context, record *entities.IdempotencyKey, lease time.Duration) (*entities.IdempotencyKey, bool, error)
+	SaveIdempotencyResponse(ctx context.Context, scope, key string, response []byte) error
+	DeleteIdempotencyKey(ctx context.Context, scope, key string) error
+	ClaimOutboxMessages(ctx context.Context, limit int, lease time.Duration) ([]*entities.OutboxMessage, error)
+	MarkOutboxMessageDone(ctx context.Context, id uuid.UUID) error
+	MarkOutboxMessageFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt *time.Time) error
+	CountPendingOutboxMessages(ctx context.Context) (int64, error)
+	CreateReturn(ctx context.Context, orderReturn *entities.OrderReturn, orderData map[string]interface{}, orderItemsData map[string]interface{}, source entities.OrderEventSource, outbox []*entities.OutboxMessage) error
+	UpdateReturn(ctx context.Context, orderReturn *entities.OrderReturn, fromStatus entities.ReturnStatus, returnData map[string]interface{}, orderData map[string]interface{}, orderItemsData map[string]interface{}, source entities.OrderEventSource, outbox []*entities.OutboxMessage) error
+	GetReturnByReference(ctx context.Context, returnReference string) (*entities.OrderReturn, error)
+	GetReturnsByOrderID(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderReturn, error)
+	ReturnReferenceExists(ctx context.Context, returnReference string) (bool, error)
 }
 
 func New(_ *sql.DB, gormDB *gorm.DB) Repository {
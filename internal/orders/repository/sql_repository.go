@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	dbErrors "github.com/nurdsoft/nurd-commerce-core/shared/db"
@@ -13,14 +14,39 @@ import (
 	cartEntities "github.com/nurdsoft/nurd-commerce-core/internal/cart/entities"
 	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
 	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/orders/errors"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// The promotion counters are updated with conditions so concurrent checkouts can't redeem a promotion past its limits.
+const (
+	redeemPromotionQuery = `UPDATE promotions SET times_used = times_used + 1
+		WHERE id = ? AND (usage_limit IS NULL OR times_used < usage_limit)`
+	redeemCustomerPromotionQuery = `INSERT INTO promotion_redemptions (promotion_id, customer_id, times_used)
+		SELECT id, ?, 1 FROM promotions WHERE id = ? AND (usage_limit_per_customer IS NULL OR usage_limit_per_customer > 0)
+		ON CONFLICT (promotion_id, customer_id) DO UPDATE SET times_used = promotion_redemptions.times_used + 1
+		WHERE promotion_redemptions.times_used < COALESCE(
+			(SELECT usage_limit_per_customer FROM promotions WHERE id = EXCLUDED.promotion_id),
+			promotion_redemptions.times_used + 1)`
+	releasePromotionsQuery = `UPDATE promotions SET times_used = times_used - 1
+		WHERE id IN (SELECT promotion_id FROM order_discounts WHERE order_id = ?) AND times_used > 0`
+	releaseCustomerPromotionsQuery = `UPDATE promotion_redemptions SET times_used = times_used - 1
+		WHERE customer_id = (SELECT customer_id FROM orders WHERE id = ?)
+		AND promotion_id IN (SELECT promotion_id FROM order_discounts WHERE order_id = ?) AND times_used > 0`
+)
+
+// statuses of the orders that gave back the promotion uses they took
+var unredeemedStatuses = map[string]bool{
+	entities.PaymentFailed.String(): true,
+	entities.Cancelled.String():     true,
+}
+
 type sqlRepository struct {
 	gormDB *gorm.DB
 }
 
-func (r *sqlRepository) CreateOrder(ctx context.Context, cartID uuid.UUID, order *entities.Order, orderItems []*entities.OrderItem) error {
+func (r *sqlRepository) CreateOrder(ctx context.Context, cartID uuid.UUID, order *entities.Order, orderItems []*entities.OrderItem, source entities.OrderEventSource, outbox []*entities.OutboxMessage) error {
 	// Start a transaction
 	tx := r.gormDB.WithContext(ctx).Begin()
 
@@ -29,6 +55,12 @@ func (r *sqlRepository) CreateOrder(ctx context.Context, cartID uuid.UUID, order
 		return err
 	}
 
+	// first entry of the order history
+	if err := recordStatusChange(tx, order.ID, nil, nil, order.Status.String(), source); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// mark cart items as purchased
 	if err := tx.Model(&cartEntities.Cart{}).Where("id = ?", cartID).Update("status", cartEntities.Purchased).Error; err != nil {
 		tx.Rollback()
@@ -41,6 +73,26 @@ func (r *sqlRepository) CreateOrder(ctx context.Context, cartID uuid.UUID, order
 		return err
 	}
 
+	// promotions applied to the order
+	if len(order.Discounts) > 0 {
+		if err := tx.Create(&order.Discounts).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if !unredeemedStatuses[order.Status.String()] {
+			if err := redeemPromotions(tx, order); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	if err := createOutboxMessages(tx, outbox); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// Commit the transaction
 	if err := tx.Commit().Error; err != nil {
 		return err
@@ -125,25 +177,49 @@ func (r *sqlRepository) ListOrders(ctx context.Context, customerID uuid.UUID, li
 	return orders, nextCursor, nil
 }
 
-func (r *sqlRepository) GetOrderByStripePaymentIntentID(ctx context.Context, stripePaymentIntentID string) (*entities.Order, error) {
+// GetOrderByPaymentID returns the order paid with the payment of the provider.
+func (r *sqlRepository) GetOrderByPaymentID(ctx context.Context, provider providers.ProviderType, paymentID string) (*entities.Order, error) {
 	order := &entities.Order{}
-	if err := r.gormDB.WithContext(ctx).Where("stripe_payment_intent_id = ?", stripePaymentIntentID).First(order).Error; err != nil {
+	if err := r.gormDB.WithContext(ctx).Where("payment_provider = ? AND payment_id = ?", provider, paymentID).First(order).Error; err != nil {
 		return nil, err
 	}
 
 	return order, nil
 }
 
-func (r *sqlRepository) GetOrderByAuthorizeNetPaymentID(ctx context.Context, authorizeNetPaymentID string) (*entities.Order, error) {
-	order := &entities.Order{}
-	if err := r.gormDB.WithContext(ctx).Where("authorizenet_payment_id = ?", authorizeNetPaymentID).First(order).Error; err != nil {
+// ListStaleOrders returns up to limit orders in one of the statuses that were created before createdBefore, oldest first.
+func (r *sqlRepository) ListStaleOrders(ctx context.Context, statuses []entities.OrderStatus, createdBefore time.Time, limit int) ([]*entities.Order, error) {
+	var orders []*entities.Order
+	err := r.gormDB.WithContext(ctx).
+		Where("status IN ? AND created_at < ?", statuses, createdBefore).
+		Order("created_at").
+		Limit(limit).
+		Find(&orders).Error
+	if err != nil {
 		return nil, err
 	}
 
-	return order, nil
+	return orders, nil
+}
+
+// ListOrdersCreatedBetween pages through the orders created from from until to, ordered by creation date.
+// The next page starts after the order with afterCreatedAt and afterID, the first one after from and uuid.Nil.
+func (r *sqlRepository) ListOrdersCreatedBetween(ctx context.Context, from, to time.Time, afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]*entities.Order, error) {
+	var orders []*entities.Order
+	err := r.gormDB.WithContext(ctx).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Where("(created_at, id) > (?, ?)", afterCreatedAt, afterID).
+		Order("created_at, id").
+		Limit(limit).
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
 }
 
-func (r *sqlRepository) Update(ctx context.Context, details map[string]interface{}, orderID string, customerID string) error {
+func (r *sqlRepository) Update(ctx context.Context, details map[string]interface{}, orderID string, customerID string, source entities.OrderEventSource, outbox []*entities.OutboxMessage) error {
 	tx := r.gormDB.Begin().WithContext(ctx)
 	defer func() {
 		if r := recover(); r != nil {
@@ -179,25 +255,60 @@ func (r *sqlRepository) Update(ctx context.Context, details map[string]interface
 			}
 
 			if len(updateData) > 0 {
-				query := tx.Model(&entities.OrderItem{}).Where("order_id = ?", orderID)
-
-				// Build WHERE clause based on available identifiers
-				if hasID && itemID != "" && hasSKU && itemSKU != "" {
-					// Both ID and SKU provided
-					query = query.Where("(id = ? OR sku = ?)", itemID, itemSKU)
-				} else if hasID && itemID != "" {
-					// Only ID provided
-					query = query.Where("id = ?", itemID)
-				} else if hasSKU && itemSKU != "" {
+				matchItems := func(db *gorm.DB) *gorm.DB {
+					db = db.Where("order_id = ?", orderID)
+
+					// Build WHERE clause based on available identifiers
+					if hasID && itemID != "" && hasSKU && itemSKU != "" {
+						// Both ID and SKU provided
+						return db.Where("(id = ? OR sku = ?)", itemID, itemSKU)
+					} else if hasID && itemID != "" {
+						// Only ID provided
+						return db.Where("id = ?", itemID)
+					}
 					// Only SKU provided
-					query = query.Where("sku = ?", itemSKU)
+					return db.Where("sku = ?", itemSKU)
+				}
+
+				// keep the previous item statuses for the history
+				newStatus, hasStatus := statusValue(updateData["status"])
+				var previous []*entities.OrderItem
+				if hasStatus {
+					if err := tx.Scopes(matchItems).
+						Clauses(clause.Locking{Strength: "UPDATE"}).
+						Select("id", "order_id", "status").
+						Find(&previous).Error; err != nil {
+						tx.Rollback()
+						return err
+					}
+				}
+
+				// the items are locked, so the statuses checked here are the ones being replaced
+				for _, item := range previous {
+					if !item.Status.CanTransitionTo(entities.OrderItemStatus(newStatus)) {
+						tx.Rollback()
+						return moduleErrors.NewAPIError("ORDER_INVALID_ITEM_TRANSITION",
+							fmt.Sprintf("Order item %s cannot transition from %s to %s.", item.ID, item.Status, newStatus))
+					}
 				}
 
-				result := query.Updates(updateData)
+				result := tx.Model(&entities.OrderItem{}).Scopes(matchItems).Updates(updateData)
 				if result.Error != nil {
 					tx.Rollback()
 					return result.Error
 				}
+
+				for _, item := range previous {
+					if item.Status.String() == newStatus {
+						continue
+					}
+
+					oldStatus := item.Status.String()
+					if err := recordStatusChange(tx, item.OrderID, &item.ID, &oldStatus, newStatus, source); err != nil {
+						tx.Rollback()
+						return err
+					}
+				}
 			}
 		}
 
@@ -235,6 +346,23 @@ func (r *sqlRepository) Update(ctx context.Context, details map[string]interface
 
 	// Update other order fields if any
 	if len(details) > 0 {
+		newStatus, hasStatus := statusValue(details["status"])
+		var previous *entities.Order
+		if hasStatus {
+			var err error
+			if previous, err = lockOrder(tx, orderID); err != nil {
+				tx.Rollback()
+				return err
+			}
+
+			// the status may have changed since the caller validated the transition
+			if !previous.Status.CanTransitionTo(entities.OrderStatus(newStatus)) {
+				tx.Rollback()
+				return moduleErrors.NewAPIError("ORDER_INVALID_STATUS_TRANSITION",
+					fmt.Sprintf("Order cannot transition from %s to %s.", previous.Status, newStatus))
+			}
+		}
+
 		result := tx.Model(&entities.Order{}).Where("id = ?", orderID).Updates(details)
 		if result.Error != nil {
 			tx.Rollback()
@@ -245,6 +373,24 @@ func (r *sqlRepository) Update(ctx context.Context, details map[string]interface
 			tx.Rollback()
 			return moduleErrors.NewAPIError("ORDER_NOT_FOUND")
 		}
+
+		if previous != nil && previous.Status.String() != newStatus {
+			oldStatus := previous.Status.String()
+			if err := recordStatusChange(tx, previous.ID, nil, &oldStatus, newStatus, source); err != nil {
+				tx.Rollback()
+				return err
+			}
+
+			if err := releaseRedemptions(tx, previous.ID, oldStatus, newStatus); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	if err := createOutboxMessages(tx, outbox); err != nil {
+		tx.Rollback()
+		return err
 	}
 
 	// Commit the transaction
@@ -319,7 +465,7 @@ func (r *sqlRepository) GetOrderByReference(ctx context.Context, orderReference
 	return order, nil
 }
 
-func (r *sqlRepository) UpdateOrderWithOrderItems(ctx context.Context, orderID uuid.UUID, orderData map[string]interface{}, orderItemsData map[string]interface{}) error {
+func (r *sqlRepository) UpdateOrderWithOrderItems(ctx context.Context, orderID uuid.UUID, orderData map[string]interface{}, orderItemsData map[string]interface{}, source entities.OrderEventSource, outbox []*entities.OutboxMessage) error {
 	tx := r.gormDB.WithContext(ctx).Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -327,37 +473,441 @@ func (r *sqlRepository) UpdateOrderWithOrderItems(ctx context.Context, orderID u
 		}
 	}()
 
-	if orderData != nil {
+	if err := updateOrderWithOrderItems(tx, orderID, orderData, orderItemsData, source); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := createOutboxMessages(tx, outbox); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *sqlRepository) GetOrderItemsByRefundID(ctx context.Context, refundID string) ([]*entities.OrderItem, error) {
+	var orderItems []*entities.OrderItem
+	if err := r.gormDB.WithContext(ctx).
+		Where("refund_id = ?", refundID).
+		Find(&orderItems).Error; err != nil {
+		return nil, err
+	}
+
+	return orderItems, nil
+}
+
+func (r *sqlRepository) GetOrderEvents(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderEvent, error) {
+	events := make([]*entities.OrderEvent, 0)
+	if err := r.gormDB.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("created_at ASC, sequence ASC").
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *sqlRepository) GetOrderDiscounts(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderDiscount, error) {
+	discounts := make([]*entities.OrderDiscount, 0)
+	if err := r.gormDB.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").
+		Find(&discounts).Error; err != nil {
+		return nil, err
+	}
+
+	return discounts, nil
+}
+
+// ClaimIdempotencyKey reserves the key for the current request. When the key is already taken
+// the existing record is returned instead; keys older than a day are released and can be claimed again.
+// A claim of the same request that got no response within the lease was abandoned, e.g. the instance
+// processing it crashed, and is taken over.
+func (r *sqlRepository) ClaimIdempotencyKey(ctx context.Context, record *entities.IdempotencyKey, lease time.Duration) (*entities.IdempotencyKey, bool, error) {
+	result := r.gormDB.WithContext(ctx).Exec(`
+		INSERT INTO idempotency_keys (scope, key, request_hash)
+		VALUES (?, ?, ?)
+		ON CONFLICT (scope, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, response = NULL, claimed_at = now(), created_at = now()
+		WHERE idempotency_keys.created_at < now() - interval '24 hours'
+		OR (idempotency_keys.response IS NULL
+			AND idempotency_keys.request_hash = EXCLUDED.request_hash
+			AND idempotency_keys.claimed_at < now() - make_interval(secs => ?))
+	`, record.Scope, record.Key, record.RequestHash, lease.Seconds())
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		return record, true, nil
+	}
+
+	existing := &entities.IdempotencyKey{}
+	if err := r.gormDB.WithContext(ctx).
+		Where("scope = ? AND key = ?", record.Scope, record.Key).
+		First(existing).Error; err != nil {
+		return nil, false, err
+	}
+
+	return existing, false, nil
+}
+
+func (r *sqlRepository) SaveIdempotencyResponse(ctx context.Context, scope, key string, response []byte) error {
+	return r.gormDB.WithContext(ctx).
+		Model(&entities.IdempotencyKey{}).
+		Where("scope = ? AND key = ?", scope, key).
+		Update("response", sharedJSON.JSON(response)).Error
+}
+
+func (r *sqlRepository) DeleteIdempotencyKey(ctx context.Context, scope, key string) error {
+	return r.gormDB.WithContext(ctx).
+		Where("scope = ? AND key = ?", scope, key).
+		Delete(&entities.IdempotencyKey{}).Error
+}
+
+// ClaimOutboxMessages picks up to limit pending messages that are due and hides them from other
+// dispatchers for the lease duration. Messages that aren't marked done or failed within the lease,
+// e.g. because the instance was stopped, are picked up again once it expires.
+func (r *sqlRepository) ClaimOutboxMessages(ctx context.Context, limit int, lease time.Duration) ([]*entities.OutboxMessage, error) {
+	var messages []*entities.OutboxMessage
+	err := r.gormDB.WithContext(ctx).Raw(`
+		UPDATE order_outbox
+		SET attempts = attempts + 1, next_attempt_at = now() + make_interval(secs => ?), updated_at = now()
+		WHERE id IN (
+			SELECT id FROM order_outbox
+			WHERE status = ? AND next_attempt_at <= now()
+			ORDER BY created_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`, lease.Seconds(), entities.OutboxPending, limit).Scan(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (r *sqlRepository) MarkOutboxMessageDone(ctx context.Context, id uuid.UUID) error {
+	return r.gormDB.WithContext(ctx).
+		Model(&entities.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     entities.OutboxDone,
+			"last_error": nil,
+			"updated_at": gorm.Expr("now()"),
+		}).Error
+}
+
+// MarkOutboxMessageFailed schedules the next delivery attempt, without one the message is marked as dead.
+func (r *sqlRepository) MarkOutboxMessageFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt *time.Time) error {
+	updates := map[string]interface{}{
+		"last_error": lastError,
+		"updated_at": gorm.Expr("now()"),
+	}
+
+	if nextAttemptAt != nil {
+		updates["next_attempt_at"] = *nextAttemptAt
+	} else {
+		updates["status"] = entities.OutboxDead
+	}
+
+	return r.gormDB.WithContext(ctx).
+		Model(&entities.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *sqlRepository) CountPendingOutboxMessages(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.gormDB.WithContext(ctx).
+		Model(&entities.OutboxMessage{}).
+		Where("status = ?", entities.OutboxPending).
+		Count(&count).Error
+
+	return count, err
+}
+
+func (r *sqlRepository) CreateReturn(ctx context.Context, orderReturn *entities.OrderReturn, orderData map[string]interface{}, orderItemsData map[string]interface{}, source entities.OrderEventSource, outbox []*entities.OutboxMessage) error {
+	tx := r.gormDB.WithContext(ctx).Begin()
+
+	// the return items are created along with the return
+	if err := tx.Create(orderReturn).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := updateOrderWithOrderItems(tx, orderReturn.OrderID, orderData, orderItemsData, source); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := createOutboxMessages(tx, outbox); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *sqlRepository) UpdateReturn(ctx context.Context, orderReturn *entities.OrderReturn, fromStatus entities.ReturnStatus, returnData map[string]interface{}, orderData map[string]interface{}, orderItemsData map[string]interface{}, source entities.OrderEventSource, outbox []*entities.OutboxMessage) error {
+	tx := r.gormDB.WithContext(ctx).Begin()
+
+	// the status condition keeps concurrent updates from both moving the same return
+	result := tx.Model(&entities.OrderReturn{}).
+		Where("id = ?", orderReturn.ID).
+		Where("status = ?", fromStatus).
+		Updates(returnData)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return moduleErrors.NewAPIError("RETURN_INVALID_STATUS_TRANSITION")
+	}
+
+	if err := updateOrderWithOrderItems(tx, orderReturn.OrderID, orderData, orderItemsData, source); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := createOutboxMessages(tx, outbox); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *sqlRepository) GetReturnByReference(ctx context.Context, returnReference string) (*entities.OrderReturn, error) {
+	orderReturn := &entities.OrderReturn{}
+	if err := r.gormDB.WithContext(ctx).
+		Preload("Items").
+		Where("return_reference = ?", returnReference).
+		First(orderReturn).Error; err != nil {
+		if dbErrors.IsNotFoundError(err) {
+			return nil, moduleErrors.NewAPIError("RETURN_NOT_FOUND")
+		}
+		return nil, err
+	}
+
+	return orderReturn, nil
+}
+
+func (r *sqlRepository) GetReturnsByOrderID(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderReturn, error) {
+	var orderReturns []*entities.OrderReturn
+	if err := r.gormDB.WithContext(ctx).
+		Preload("Items").
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").
+		Find(&orderReturns).Error; err != nil {
+		return nil, err
+	}
+
+	return orderReturns, nil
+}
+
+func (r *sqlRepository) ReturnReferenceExists(ctx context.Context, returnReference string) (bool, error) {
+	var count int64
+	err := r.gormDB.WithContext(ctx).
+		Model(&entities.OrderReturn{}).
+		Where("return_reference = ?", returnReference).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// updateOrderWithOrderItems updates the order and its items within tx and records their status changes.
+func updateOrderWithOrderItems(tx *gorm.DB, orderID uuid.UUID, orderData map[string]interface{}, orderItemsData map[string]interface{}, source entities.OrderEventSource) error {
+	if len(orderData) > 0 {
+		newStatus, hasStatus := statusValue(orderData["status"])
+		var previous *entities.Order
+		if hasStatus {
+			var err error
+			if previous, err = lockOrder(tx, orderID.String()); err != nil {
+				return err
+			}
+
+			// the status may have changed since the caller validated the transition
+			next := entities.OrderStatus(newStatus)
+			if !previous.Status.CanTransitionTo(next) && !previous.Status.CanRevertRefundTo(next) {
+				return moduleErrors.NewAPIError("ORDER_INVALID_STATUS_TRANSITION",
+					fmt.Sprintf("Order cannot transition from %s to %s.", previous.Status, newStatus))
+			}
+		}
+
 		// Update order status
 		if err := tx.Model(&entities.Order{}).Where("id = ?", orderID).Updates(orderData).Error; err != nil {
-			tx.Rollback()
 			return err
 		}
+
+		if previous != nil && previous.Status.String() != newStatus {
+			oldStatus := previous.Status.String()
+			if err := recordStatusChange(tx, orderID, nil, &oldStatus, newStatus, source); err != nil {
+				return err
+			}
+
+			if err := releaseRedemptions(tx, orderID, oldStatus, newStatus); err != nil {
+				return err
+			}
+		}
 	}
 
 	if len(orderItemsData) > 0 {
-		// Update order items with refund data
+		// Update order items with refund or return data
 		for itemID, data := range orderItemsData {
+			var previous *entities.OrderItem
+			newStatus, hasStatus := "", false
+			if fields, ok := data.(map[string]interface{}); ok {
+				newStatus, hasStatus = statusValue(fields["status"])
+			}
+			if hasStatus {
+				previous = &entities.OrderItem{}
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					Select("id", "order_id", "status").
+					Where("id = ?", itemID).
+					Where("order_id = ?", orderID).
+					First(previous).Error; err != nil {
+					return err
+				}
+
+				// items without a status predate item level statuses and follow the order status
+				next := entities.OrderItemStatus(newStatus)
+				if previous.Status != "" && !previous.Status.CanTransitionTo(next) && !previous.Status.CanRefundTransitionTo(next) {
+					return moduleErrors.NewAPIError("ORDER_INVALID_ITEM_TRANSITION",
+						fmt.Sprintf("Order item %s cannot transition from %s to %s.", previous.ID, previous.Status, newStatus))
+				}
+			}
+
 			if err := tx.Model(&entities.OrderItem{}).
 				Where("id = ?", itemID).
 				Where("order_id = ?", orderID).
 				Updates(data).Error; err != nil {
-				tx.Rollback()
 				return err
 			}
+
+			if previous != nil && previous.Status.String() != newStatus {
+				oldStatus := previous.Status.String()
+				if err := recordStatusChange(tx, orderID, &previous.ID, &oldStatus, newStatus, source); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
-	return tx.Commit().Error
+	return nil
 }
 
-func (r *sqlRepository) GetOrderItemsByStripeRefundID(ctx context.Context, stripeRefundID string) ([]*entities.OrderItem, error) {
-	var orderItems []*entities.OrderItem
-	if err := r.gormDB.WithContext(ctx).
-		Where("stripe_refund_id = ?", stripeRefundID).
-		Find(&orderItems).Error; err != nil {
+// createOutboxMessages stores the side effects of an order change within tx.
+func createOutboxMessages(tx *gorm.DB, outbox []*entities.OutboxMessage) error {
+	if len(outbox) == 0 {
+		return nil
+	}
+
+	return tx.Create(&outbox).Error
+}
+
+// lockOrder loads the order inside tx and locks its row until the transaction ends,
+// so the status read for the history cannot race with a concurrent update.
+func lockOrder(tx *gorm.DB, orderID string) (*entities.Order, error) {
+	order := &entities.Order{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "status").
+		Where("id = ?", orderID).
+		First(order).Error; err != nil {
+		if dbErrors.IsNotFoundError(err) {
+			return nil, moduleErrors.NewAPIError("ORDER_NOT_FOUND")
+		}
 		return nil, err
 	}
 
-	return orderItems, nil
+	return order, nil
+}
+
+// redeemPromotions takes a use of each promotion applied to the order within tx, it fails with
+// ORDER_PROMOTION_LIMIT_REACHED when a promotion ran out since the cart was summarized.
+func redeemPromotions(tx *gorm.DB, order *entities.Order) error {
+	redeemed := make(map[uuid.UUID]bool, len(order.Discounts))
+	for _, discount := range order.Discounts {
+		if redeemed[discount.PromotionID] {
+			continue
+		}
+		redeemed[discount.PromotionID] = true
+
+		result := tx.Exec(redeemPromotionQuery, discount.PromotionID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return moduleErrors.NewAPIError("ORDER_PROMOTION_LIMIT_REACHED")
+		}
+
+		result = tx.Exec(redeemCustomerPromotionQuery, order.CustomerID, discount.PromotionID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return moduleErrors.NewAPIError("ORDER_PROMOTION_LIMIT_REACHED")
+		}
+	}
+
+	return nil
+}
+
+// releaseRedemptions gives back the promotion uses of an order once its payment failed or it was cancelled.
+func releaseRedemptions(tx *gorm.DB, orderID uuid.UUID, oldStatus, newStatus string) error {
+	if unredeemedStatuses[oldStatus] || !unredeemedStatuses[newStatus] {
+		return nil
+	}
+
+	if err := tx.Exec(releasePromotionsQuery, orderID).Error; err != nil {
+		return err
+	}
+
+	return tx.Exec(releaseCustomerPromotionsQuery, orderID, orderID).Error
+}
+
+// recordStatusChange appends an entry to the order history within tx.
+func recordStatusChange(tx *gorm.DB, orderID uuid.UUID, orderItemID *uuid.UUID, oldStatus *string, newStatus string, source entities.OrderEventSource) error {
+	return tx.Create(&entities.OrderEvent{
+		OrderID:       orderID,
+		OrderItemID:   orderItemID,
+		OldStatus:     oldStatus,
+		NewStatus:     newStatus,
+		Actor:         source.Actor,
+		SourceEventID: source.SourceEventID,
+		Message:       source.Message,
+	}).Error
+}
+
+// statusValue extracts the status from an update payload value, which depending on
+// the caller can be a typed status, a plain string or a pointer to either.
+func statusValue(v interface{}) (string, bool) {
+	switch status := v.(type) {
+	case string:
+		return status, true
+	case *string:
+		if status != nil {
+			return *status, true
+		}
+	case entities.OrderStatus:
+		return status.String(), true
+	case *entities.OrderStatus:
+		if status != nil {
+			return status.String(), true
+		}
+	case entities.OrderItemStatus:
+		return status.String(), true
+	case *entities.OrderItemStatus:
+		if status != nil {
+			return status.String(), true
+		}
+	}
+
+	return "", false
 }
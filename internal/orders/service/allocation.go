@@ -0,0 +1,174 @@
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	sharedJson "github.com/nurdsoft/nurd-commerce-core/shared/json"
+	"github.com/shopspring/decimal"
+)
+
+// allocationPlaces is the precision allocated amounts are rounded to.
+const allocationPlaces = 2
+
+// allocateOrderAmounts spreads the order tax and the shipping rates over the order items,
+// so a partial refund can give back the tax and shipping that were paid for each item.
+// Tax reported per line by the tax provider is used as is, whatever remains (e.g. tax on
+// shipping, or everything when the breakdown has no line items) is split by line subtotal.
+// A shipping rate shared by several items is split among them by line subtotal.
+// Line subtotals are net of the item discounts.
+func allocateOrderAmounts(orderItems []*entities.OrderItem, taxAmount decimal.Decimal, taxBreakdown sharedJson.JSON) {
+	if len(orderItems) == 0 {
+		return
+	}
+
+	subtotals := make([]decimal.Decimal, len(orderItems))
+	for i, item := range orderItems {
+		subtotals[i] = lineAmount(item, item.Quantity)
+	}
+
+	taxes := make([]decimal.Decimal, len(orderItems))
+	remainingTax := taxAmount
+	lineTaxes := taxBreakdownLineItems(taxBreakdown)
+	for i, item := range orderItems {
+		if lineTax, ok := lineTaxes[item.SKU]; ok {
+			taxes[i] = lineTax
+			remainingTax = remainingTax.Sub(lineTax)
+			// the breakdown is keyed by sku, a repeated sku gets a proportional share instead
+			delete(lineTaxes, item.SKU)
+		}
+	}
+	if remainingTax.IsNegative() {
+		// the breakdown doesn't add up to the order tax, don't trust it
+		taxes = make([]decimal.Decimal, len(orderItems))
+		remainingTax = taxAmount
+	}
+	for i, share := range allocateProportionally(remainingTax, subtotals) {
+		tax := taxes[i].Add(share)
+		orderItems[i].TaxAmount = &tax
+	}
+
+	shippingGroups := make(map[uuid.UUID][]int)
+	for i, item := range orderItems {
+		shipping := decimal.Zero
+		orderItems[i].ShippingAmount = &shipping
+		if item.ShippingRateID != nil && item.ShippingRate != nil {
+			shippingGroups[*item.ShippingRateID] = append(shippingGroups[*item.ShippingRateID], i)
+		}
+	}
+	for _, indexes := range shippingGroups {
+		weights := make([]decimal.Decimal, len(indexes))
+		for j, i := range indexes {
+			weights[j] = subtotals[i]
+		}
+		shares := allocateProportionally(*orderItems[indexes[0]].ShippingRate, weights)
+		for j, i := range indexes {
+			orderItems[i].ShippingAmount = &shares[j]
+		}
+	}
+}
+
+// allocateShippingDiscount takes the shipping discount of the order off the items' shipping shares.
+func allocateShippingDiscount(orderItems []*entities.OrderItem, discount decimal.Decimal) {
+	if discount.IsZero() {
+		return
+	}
+
+	shippingAmounts := make([]decimal.Decimal, len(orderItems))
+	for i, item := range orderItems {
+		if item.ShippingAmount != nil {
+			shippingAmounts[i] = *item.ShippingAmount
+		}
+	}
+
+	for i, share := range allocateProportionally(discount, shippingAmounts) {
+		shipping := shippingAmounts[i].Sub(share)
+		orderItems[i].ShippingAmount = &shipping
+	}
+}
+
+// ensureAllocations computes the tax and shipping shares of orders placed before they were
+// allocated to the items.
+func ensureAllocations(order *entities.Order, orderItems []*entities.OrderItem) {
+	for _, orderItem := range orderItems {
+		if orderItem.TaxAmount == nil || orderItem.ShippingAmount == nil {
+			allocateOrderAmounts(orderItems, order.TaxAmount, order.TaxBreakdown)
+			return
+		}
+	}
+}
+
+// allocateProportionally splits total by weight, the last share absorbs the rounding
+// difference so the shares always add up to total.
+func allocateProportionally(total decimal.Decimal, weights []decimal.Decimal) []decimal.Decimal {
+	shares := make([]decimal.Decimal, len(weights))
+	if len(weights) == 0 || total.IsZero() {
+		return shares
+	}
+
+	totalWeight := decimal.Zero
+	for _, weight := range weights {
+		totalWeight = totalWeight.Add(weight)
+	}
+
+	allocated := decimal.Zero
+	for i, weight := range weights[:len(weights)-1] {
+		if totalWeight.IsZero() {
+			// nothing to weigh by (e.g. free items), split evenly
+			shares[i] = total.Div(decimal.NewFromInt(int64(len(weights)))).Round(allocationPlaces)
+		} else {
+			shares[i] = total.Mul(weight).Div(totalWeight).Round(allocationPlaces)
+		}
+		allocated = allocated.Add(shares[i])
+	}
+	shares[len(weights)-1] = total.Sub(allocated)
+
+	return shares
+}
+
+// taxBreakdownLineItems returns the tax collected per sku when the stored tax breakdown
+// reports it per line item (TaxJar does, Stripe reports it per jurisdiction only).
+func taxBreakdownLineItems(breakdown sharedJson.JSON) map[string]decimal.Decimal {
+	lineTaxes := make(map[string]decimal.Decimal)
+	if len(breakdown) == 0 {
+		return lineTaxes
+	}
+
+	var parsed struct {
+		LineItems []struct {
+			ID             string          `json:"id"`
+			TaxCollectable decimal.Decimal `json:"tax_collectable"`
+		} `json:"line_items"`
+	}
+	if err := json.Unmarshal(breakdown, &parsed); err != nil {
+		return lineTaxes
+	}
+
+	for _, lineItem := range parsed.LineItems {
+		if lineItem.ID != "" {
+			lineTaxes[lineItem.ID] = lineTaxes[lineItem.ID].Add(lineItem.TaxCollectable)
+		}
+	}
+
+	return lineTaxes
+}
+
+// lineAmount is what was paid for quantity of the item's units, net of the item's discount.
+func lineAmount(orderItem *entities.OrderItem, quantity int) decimal.Decimal {
+	amount := orderItem.Price.Mul(decimal.NewFromInt(int64(quantity)))
+
+	return amount.Sub(refundShare(orderItem.DiscountAmount, quantity, orderItem.Quantity))
+}
+
+// refundShare is the part of an allocated amount that belongs to quantity of the item's units.
+func refundShare(amount *decimal.Decimal, quantity, itemQuantity int) decimal.Decimal {
+	if amount == nil || amount.IsZero() || itemQuantity <= 0 {
+		return decimal.Zero
+	}
+	if quantity >= itemQuantity {
+		return *amount
+	}
+
+	return amount.Mul(decimal.NewFromInt(int64(quantity))).Div(decimal.NewFromInt(int64(itemQuantity))).Round(allocationPlaces)
+}
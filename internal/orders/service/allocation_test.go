@@ -0,0 +1,94 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	sharedJson "github.com/nurdsoft/nurd-commerce-core/shared/json"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllocateOrderAmounts(t *testing.T) {
+	newItems := func() []*entities.OrderItem {
+		rateID := uuid.New()
+		rate := decimal.NewFromInt(10)
+		return []*entities.OrderItem{
+			{SKU: "SKU-1", Price: decimal.NewFromInt(10), Quantity: 1, ShippingRateID: &rateID, ShippingRate: &rate},
+			{SKU: "SKU-2", Price: decimal.NewFromInt(10), Quantity: 2, ShippingRateID: &rateID, ShippingRate: &rate},
+			{SKU: "SKU-3", Price: decimal.NewFromInt(30), Quantity: 1},
+		}
+	}
+
+	t.Run("splits tax by subtotal without line item breakdown", func(t *testing.T) {
+		items := newItems()
+
+		allocateOrderAmounts(items, decimal.NewFromInt(12), sharedJson.JSON(`[{"amount":1200}]`))
+
+		assert.Equal(t, "2", items[0].TaxAmount.String())
+		assert.Equal(t, "4", items[1].TaxAmount.String())
+		assert.Equal(t, "6", items[2].TaxAmount.String())
+		assert.Equal(t, "3.33", items[0].ShippingAmount.String())
+		assert.Equal(t, "6.67", items[1].ShippingAmount.String())
+		assert.Equal(t, "0", items[2].ShippingAmount.String())
+	})
+
+	t.Run("uses line item tax from the breakdown", func(t *testing.T) {
+		items := newItems()
+		breakdown := sharedJson.JSON(`{"line_items":[{"id":"SKU-1","tax_collectable":0.5},{"id":"SKU-2","tax_collectable":1},{"id":"SKU-3","tax_collectable":3}],"shipping":{"tax_collectable":0.6}}`)
+
+		allocateOrderAmounts(items, decimal.NewFromFloat(5.1), breakdown)
+
+		// the shipping tax is split by subtotal
+		assert.Equal(t, "0.6", items[0].TaxAmount.String())
+		assert.Equal(t, "1.2", items[1].TaxAmount.String())
+		assert.Equal(t, "3.3", items[2].TaxAmount.String())
+	})
+
+	t.Run("ignores breakdown exceeding the order tax", func(t *testing.T) {
+		items := newItems()
+		breakdown := sharedJson.JSON(`{"line_items":[{"id":"SKU-3","tax_collectable":20}]}`)
+
+		allocateOrderAmounts(items, decimal.NewFromInt(12), breakdown)
+
+		assert.Equal(t, "6", items[2].TaxAmount.String())
+	})
+}
+
+func TestAllocateProportionally(t *testing.T) {
+	shares := allocateProportionally(decimal.NewFromInt(10), []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(1), decimal.NewFromInt(1)})
+
+	assert.Equal(t, []string{"3.33", "3.33", "3.34"}, []string{shares[0].String(), shares[1].String(), shares[2].String()})
+}
+
+func TestRefundShare(t *testing.T) {
+	amount := decimal.NewFromInt(10)
+
+	assert.Equal(t, "3.33", refundShare(&amount, 1, 3).String())
+	assert.Equal(t, "10", refundShare(&amount, 3, 3).String())
+	assert.True(t, refundShare(nil, 1, 3).IsZero())
+}
+
+func TestAllocateShippingDiscount(t *testing.T) {
+	shipping := []decimal.Decimal{decimal.NewFromInt(6), decimal.NewFromInt(4), decimal.Zero}
+	items := make([]*entities.OrderItem, len(shipping))
+	for i := range shipping {
+		items[i] = &entities.OrderItem{ShippingAmount: &shipping[i]}
+	}
+
+	allocateShippingDiscount(items, decimal.NewFromInt(5))
+
+	assert.Equal(t, "3", items[0].ShippingAmount.String())
+	assert.Equal(t, "2", items[1].ShippingAmount.String())
+	assert.Equal(t, "0", items[2].ShippingAmount.String())
+}
+
+func TestLineAmount(t *testing.T) {
+	discount := decimal.NewFromInt(6)
+	item := &entities.OrderItem{Price: decimal.NewFromInt(20), Quantity: 3, DiscountAmount: &discount}
+
+	assert.Equal(t, "54", lineAmount(item, 3).String())
+	assert.Equal(t, "18", lineAmount(item, 1).String())
+	assert.Equal(t, "20", lineAmount(&entities.OrderItem{Price: decimal.NewFromInt(20), Quantity: 3}, 1).String())
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/orders/errors"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"github.com/shopspring/decimal"
+)
+
+// orderCaptureAmount works out the amount to capture when an order placed in manual capture mode ships.
+// Cancelled items are left out, including the ones being cancelled by itemUpdates, the rest of the hold
+// is released by the payment provider.
+func (s *service) orderCaptureAmount(ctx context.Context, order *entities.Order, itemUpdates []*entities.Item) (decimal.Decimal, error) {
+	orderItems, err := s.repo.GetOrderItemsByID(ctx, order.ID)
+	if err != nil {
+		s.log.Errorf("Error fetching order items: %v", err)
+		return decimal.Zero, moduleErrors.NewAPIError("ORDER_ERROR_GETTING_ITEMS")
+	}
+
+	ensureAllocations(order, orderItems)
+
+	amount := captureAmount(orderItems, itemUpdates)
+	if amount.GreaterThan(order.Total) {
+		amount = order.Total
+	}
+	if amount.IsZero() {
+		return decimal.Zero, moduleErrors.NewAPIError("ORDER_PAYMENT_CAPTURE_ERROR", "Order has no items left to capture")
+	}
+
+	if _, _, err := s.orderPayment(order); err != nil {
+		s.log.Errorf("Error capturing payment of order %s: %v", order.ID, err)
+		return decimal.Zero, moduleErrors.NewAPIError("ORDER_PAYMENT_CAPTURE_ERROR")
+	}
+
+	return amount, nil
+}
+
+func capturePayment(order *entities.Order, amount decimal.Decimal) outboxEntry {
+	return outboxEntry{
+		topic: entities.TopicPaymentCaptureOrder,
+		payload: entities.PaymentCaptureOrderPayload{
+			OrderID: order.ID,
+			Amount:  amount,
+		},
+	}
+}
+
+// captureOrderPayment captures the amount requested when the order shipped and records it. It is run by
+// the outbox, a payment found captured by an earlier delivery is recorded without being captured again.
+func (s *service) captureOrderPayment(ctx context.Context, payload entities.PaymentCaptureOrderPayload) error {
+	order, err := s.repo.GetOrderByID(ctx, payload.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if !order.CapturePending() || order.PaymentVoidedAt != nil {
+		s.log.Infof("Payment of order %s has no capture pending", order.ID)
+		return nil
+	}
+
+	paymentClient, paymentID, err := s.orderPayment(order)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUndeliverable, err)
+	}
+
+	amount := payload.Amount
+	details, err := paymentClient.GetPaymentDetails(ctx, paymentID)
+	if err != nil {
+		return err
+	}
+
+	if details.CapturedAmount.IsPositive() {
+		amount = details.CapturedAmount
+	} else {
+		// the key is derived from the order so a retried delivery doesn't capture twice
+		res, err := paymentClient.Capture(ctx, providers.CaptureRequest{
+			PaymentID:      paymentID,
+			Amount:         amount,
+			IdempotencyKey: providerIdempotencyKey(idempotencyScopeCaptureOrder, order.ID.String()),
+		})
+		if err != nil {
+			return err
+		}
+
+		// a declined capture won't go through on a retry either, it's recorded on the order for the
+		// merchant to follow up with the customer
+		if res.Status == providers.PaymentStatusFailed {
+			s.log.Errorf("Capture of order %s failed with ID: %s", order.ID, res.ID)
+			return s.recordCaptureFailure(ctx, order, fmt.Sprintf("%s declined the capture of payment %s", providerName(order.PaymentProvider), res.ID))
+		}
+	}
+
+	data := map[string]interface{}{
+		"payment_captured_amount": amount,
+		"payment_captured_at":     time.Now().UTC(),
+	}
+
+	return s.repo.Update(ctx, data, order.ID.String(), order.CustomerID.String(), eventSource(ctx, entities.ActorProvider, ""), nil)
+}
+
+// recordCaptureFailure marks the capture of the order payment as failed and notifies the order webhook,
+// the order keeps its status so the shipment it was requested with isn't undone.
+func (s *service) recordCaptureFailure(ctx context.Context, order *entities.Order, reason string) error {
+	outbox, err := newOutboxMessages(notifyStatusChange(order, order.Status.String()))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUndeliverable, err)
+	}
+
+	data := map[string]interface{}{
+		"payment_capture_failed_at":      time.Now().UTC(),
+		"payment_capture_failure_reason": reason,
+	}
+
+	return s.repo.Update(ctx, data, order.ID.String(), order.CustomerID.String(), eventSource(ctx, entities.ActorProvider, reason), outbox)
+}
+
+func voidPayment(order *entities.Order) outboxEntry {
+	return outboxEntry{
+		topic:   entities.TopicPaymentVoidOrder,
+		payload: entities.PaymentVoidOrderPayload{OrderID: order.ID},
+	}
+}
+
+// voidOrderPayment releases the funds held for a cancelled order. It is run by the outbox, the order is
+// cancelled in the same transaction the void is requested in.
+func (s *service) voidOrderPayment(ctx context.Context, payload entities.PaymentVoidOrderPayload) error {
+	order, err := s.repo.GetOrderByID(ctx, payload.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if order.PaymentCapturedAt != nil {
+		s.log.Warnf("Payment of order %s was captured, it can't be voided", order.ID)
+		return nil
+	}
+
+	if err := s.voidHeldPayment(ctx, order); err != nil {
+		s.log.Errorf("Error voiding payment of order %s: %v", order.ID, err)
+		return err
+	}
+
+	return nil
+}
+
+// voidHeldPayment releases the funds held for an order placed in manual capture mode, or the payment the
+// customer didn't complete. The key is derived from the order so a retried void is sent once.
+func (s *service) voidHeldPayment(ctx context.Context, order *entities.Order) error {
+	paymentClient, paymentID, err := s.orderPayment(order)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUndeliverable, err)
+	}
+
+	return paymentClient.Void(ctx, providers.VoidRequest{
+		PaymentID:      paymentID,
+		IdempotencyKey: providerIdempotencyKey(idempotencyScopeVoidOrder, order.ID.String()),
+	})
+}
+
+// releaseOrderPayment gives back the payment of an order that couldn't be stored. A payment that was
+// charged is refunded, one that is held or not completed yet is voided.
+func (s *service) releaseOrderPayment(ctx context.Context, order *entities.Order) error {
+	switch {
+	case order.Status == entities.PaymentFailed:
+		return nil
+	case order.Status == entities.PaymentSuccess && !order.AwaitingCapture():
+		paymentClient, paymentID, err := s.orderPayment(order)
+		if err != nil {
+			return err
+		}
+
+		_, err = paymentClient.Refund(ctx, providers.RefundRequest{
+			PaymentID:      paymentID,
+			IdempotencyKey: providerIdempotencyKey(idempotencyScopeCancelOrder, order.ID.String()),
+		})
+		return err
+	default:
+		return s.voidHeldPayment(ctx, order)
+	}
+}
+
+// captureAmount is the total of the items that are not cancelled, with their share of tax and shipping.
+// itemUpdates are the item changes that come along with the capture and take precedence over the stored statuses.
+func captureAmount(orderItems []*entities.OrderItem, itemUpdates []*entities.Item) decimal.Decimal {
+	amount := decimal.Zero
+	for _, orderItem := range orderItems {
+		status := orderItem.Status
+		for _, update := range itemUpdates {
+			if update.Status != nil && (update.ID == orderItem.ID.String() || update.Sku == orderItem.SKU) {
+				status = *update.Status
+			}
+		}
+
+		if status == entities.ItemCancelled {
+			continue
+		}
+
+		amount = amount.Add(lineAmount(orderItem, orderItem.Quantity))
+		if orderItem.TaxAmount != nil {
+			amount = amount.Add(*orderItem.TaxAmount)
+		}
+		if orderItem.ShippingAmount != nil {
+			amount = amount.Add(*orderItem.ShippingAmount)
+		}
+	}
+
+	return amount
+}
@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/orders/errors"
+	sharedMeta "github.com/nurdsoft/nurd-commerce-core/shared/meta"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func authorizedOrder(provider providers.ProviderType, paymentID string) *entities.Order {
+	return &entities.Order{
+		ID:                 uuid.New(),
+		CustomerID:         uuid.New(),
+		OrderReference:     "ORD123456",
+		Status:             entities.Processing,
+		Total:              decimal.NewFromInt(116),
+		TaxAmount:          decimal.NewFromInt(6),
+		PaymentProvider:    provider,
+		PaymentID:          &paymentID,
+		PaymentCaptureMode: providers.CaptureModeManual,
+	}
+}
+
+func authorizedOrderItems(orderID uuid.UUID) []*entities.OrderItem {
+	tax1, tax2 := decimal.NewFromInt(2), decimal.NewFromInt(4)
+	shipping1, shipping2 := decimal.NewFromInt(4), decimal.NewFromInt(6)
+
+	return []*entities.OrderItem{
+		{ID: uuid.New(), OrderID: orderID, SKU: "SKU1", Quantity: 2, Price: decimal.NewFromInt(20), Status: entities.ItemProcessing, TaxAmount: &tax1, ShippingAmount: &shipping1},
+		{ID: uuid.New(), OrderID: orderID, SKU: "SKU2", Quantity: 1, Price: decimal.NewFromInt(60), Status: entities.ItemProcessing, TaxAmount: &tax2, ShippingAmount: &shipping2},
+	}
+}
+
+func TestCaptureAmount(t *testing.T) {
+	orderItems := authorizedOrderItems(uuid.New())
+	cancelled := entities.ItemCancelled
+
+	t.Run("all items", func(t *testing.T) {
+		assert.Equal(t, "116", captureAmount(orderItems, nil).String())
+	})
+
+	t.Run("leaves out items cancelled by the update", func(t *testing.T) {
+		amount := captureAmount(orderItems, []*entities.Item{{Sku: "SKU2", Status: &cancelled}})
+		assert.Equal(t, "46", amount.String())
+	})
+
+	t.Run("leaves out items cancelled earlier", func(t *testing.T) {
+		items := authorizedOrderItems(uuid.New())
+		items[0].Status = entities.ItemCancelled
+		assert.Equal(t, "70", captureAmount(items, nil).String())
+	})
+}
+
+func TestNewPaymentRequest_ManualCapture(t *testing.T) {
+	tc := setupTestController(t)
+	s := newServiceUnderTest(tc)
+	s.captureMode = providers.CaptureModeManual
+
+	req := s.newPaymentRequest(providers.ProviderStripe, entities.CreatePaymentRequest{Amount: decimal.NewFromInt(10)})
+
+	assert.True(t, req.AuthorizeOnly)
+}
+
+func TestUpdateOrder_AuthorizedPayment(t *testing.T) {
+	shipped := entities.Shipped.String()
+	cancelledStatus := entities.Cancelled.String()
+
+	t.Run("requests the capture of the items that ship", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := authorizedOrder(providers.ProviderStripe, "pi_123")
+		orderItems := authorizedOrderItems(order.ID)
+		cancelled := entities.ItemCancelled
+
+		tc.mockRepo.EXPECT().GetOrderByReference(gomock.Any(), order.OrderReference).Return(order, nil)
+		tc.mockRepo.EXPECT().GetOrderItemsByID(gomock.Any(), order.ID).Return(orderItems, nil)
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _, _ string, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, entities.Shipped, data["status"])
+				assert.NotNil(t, data["payment_capture_requested_at"])
+				assert.NotContains(t, data, "payment_captured_at")
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicPaymentCaptureOrder, entities.TopicOrderStatusChanged}, outboxTopics(outbox))
+
+				var payload entities.PaymentCaptureOrderPayload
+				assert.NoError(t, json.Unmarshal(outbox[0].Payload, &payload))
+				assert.Equal(t, order.ID, payload.OrderID)
+				assert.Equal(t, "46", payload.Amount.String())
+			}).
+			Return(nil)
+
+		err := s.UpdateOrder(context.Background(), &entities.UpdateOrderRequest{
+			OrderReference: order.OrderReference,
+			Body: &entities.UpdateOrderRequestBody{
+				Status: &shipped,
+				Items:  []*entities.Item{{Sku: "SKU2", Status: &cancelled}},
+			},
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("order is not updated when there is nothing to capture", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := authorizedOrder(providers.ProviderAuthorizeNet, "txn_123")
+		orderItems := authorizedOrderItems(order.ID)
+		for _, item := range orderItems {
+			item.Status = entities.ItemCancelled
+		}
+
+		tc.mockRepo.EXPECT().GetOrderByReference(gomock.Any(), order.OrderReference).Return(order, nil)
+		tc.mockRepo.EXPECT().GetOrderItemsByID(gomock.Any(), order.ID).Return(orderItems, nil)
+
+		err := s.UpdateOrder(context.Background(), &entities.UpdateOrderRequest{
+			OrderReference: order.OrderReference,
+			Body:           &entities.UpdateOrderRequestBody{Status: &shipped},
+		})
+
+		assert.Equal(t, moduleErrors.NewAPIError("ORDER_PAYMENT_CAPTURE_ERROR", "Order has no items left to capture"), err)
+	})
+
+	t.Run("releases the hold when cancelled", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := authorizedOrder(providers.ProviderAuthorizeNet, "txn_123")
+
+		tc.mockRepo.EXPECT().GetOrderByReference(gomock.Any(), order.OrderReference).Return(order, nil)
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _, _ string, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, entities.Cancelled, data["status"])
+				assert.NotNil(t, data["payment_voided_at"])
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicPaymentVoidOrder, entities.TopicOrderStatusChanged}, outboxTopics(outbox))
+			}).
+			Return(nil)
+
+		err := s.UpdateOrder(context.Background(), &entities.UpdateOrderRequest{
+			OrderReference: order.OrderReference,
+			Body:           &entities.UpdateOrderRequestBody{Status: &cancelledStatus},
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("orders with a capture pending are not captured again", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := authorizedOrder(providers.ProviderStripe, "pi_123")
+		order.Status = entities.FulfillmentFailed
+		requestedAt := time.Now()
+		order.PaymentCaptureRequestedAt = &requestedAt
+
+		tc.mockRepo.EXPECT().GetOrderByReference(gomock.Any(), order.OrderReference).Return(order, nil)
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _, _ string, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.NotContains(t, data, "payment_capture_requested_at")
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged}, outboxTopics(outbox))
+			}).
+			Return(nil)
+
+		err := s.UpdateOrder(context.Background(), &entities.UpdateOrderRequest{
+			OrderReference: order.OrderReference,
+			Body:           &entities.UpdateOrderRequestBody{Status: &shipped},
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("captured orders are not captured again", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := authorizedOrder(providers.ProviderStripe, "pi_123")
+		order.Status = entities.FulfillmentFailed
+		capturedAt := time.Now()
+		order.PaymentCapturedAt = &capturedAt
+
+		tc.mockRepo.EXPECT().GetOrderByReference(gomock.Any(), order.OrderReference).Return(order, nil)
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		err := s.UpdateOrder(context.Background(), &entities.UpdateOrderRequest{
+			OrderReference: order.OrderReference,
+			Body:           &entities.UpdateOrderRequestBody{Status: &shipped},
+		})
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestCaptureOrderPayment(t *testing.T) {
+	payloadFor := func(order *entities.Order) entities.PaymentCaptureOrderPayload {
+		return entities.PaymentCaptureOrderPayload{OrderID: order.ID, Amount: decimal.NewFromInt(46)}
+	}
+	pendingOrder := func() *entities.Order {
+		order := authorizedOrder(providers.ProviderStripe, "pi_123")
+		order.Status = entities.Shipped
+		requestedAt := time.Now()
+		order.PaymentCaptureRequestedAt = &requestedAt
+		return order
+	}
+
+	t.Run("captures and records the requested amount", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := pendingOrder()
+
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), order.ID).Return(order, nil)
+		tc.mockPayment.EXPECT().GetPaymentDetails(gomock.Any(), "pi_123").Return(&providers.PaymentDetails{ID: "pi_123"}, nil)
+		tc.mockPayment.EXPECT().
+			Capture(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, captureReq providers.CaptureRequest) {
+				assert.Equal(t, "pi_123", captureReq.PaymentID)
+				assert.Equal(t, "46", captureReq.Amount.String())
+				assert.NotEmpty(t, captureReq.IdempotencyKey)
+			}).
+			Return(providers.PaymentProviderResponse{ID: "pi_123", Status: providers.PaymentStatusSuccess}, nil)
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _, _ string, _ entities.OrderEventSource, _ []*entities.OutboxMessage) {
+				assert.NotContains(t, data, "status")
+				assert.Equal(t, "46", data["payment_captured_amount"].(decimal.Decimal).String())
+				assert.NotNil(t, data["payment_captured_at"])
+			}).
+			Return(nil)
+
+		assert.NoError(t, s.captureOrderPayment(context.Background(), payloadFor(order)))
+	})
+
+	t.Run("payment captured by an earlier delivery is recorded", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := pendingOrder()
+
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), order.ID).Return(order, nil)
+		tc.mockPayment.EXPECT().
+			GetPaymentDetails(gomock.Any(), "pi_123").
+			Return(&providers.PaymentDetails{ID: "pi_123", CapturedAmount: decimal.NewFromInt(46)}, nil)
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _, _ string, _ entities.OrderEventSource, _ []*entities.OutboxMessage) {
+				assert.Equal(t, "46", data["payment_captured_amount"].(decimal.Decimal).String())
+			}).
+			Return(nil)
+
+		assert.NoError(t, s.captureOrderPayment(context.Background(), payloadFor(order)))
+	})
+
+	t.Run("failed capture is retried", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := pendingOrder()
+
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), order.ID).Return(order, nil)
+		tc.mockPayment.EXPECT().GetPaymentDetails(gomock.Any(), "pi_123").Return(&providers.PaymentDetails{ID: "pi_123"}, nil)
+		tc.mockPayment.EXPECT().Capture(gomock.Any(), gomock.Any()).Return(providers.PaymentProviderResponse{}, errors.New("timeout"))
+
+		err := s.captureOrderPayment(context.Background(), payloadFor(order))
+
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, errUndeliverable)
+	})
+
+	t.Run("declined capture is recorded on the order", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := pendingOrder()
+
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), order.ID).Return(order, nil)
+		tc.mockPayment.EXPECT().GetPaymentDetails(gomock.Any(), "pi_123").Return(&providers.PaymentDetails{ID: "pi_123"}, nil)
+		tc.mockPayment.EXPECT().
+			Capture(gomock.Any(), gomock.Any()).
+			Return(providers.PaymentProviderResponse{ID: "pi_123", Status: providers.PaymentStatusFailed}, nil)
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _, _ string, source entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.NotContains(t, data, "status")
+				assert.NotContains(t, data, "payment_captured_at")
+				assert.NotNil(t, data["payment_capture_failed_at"])
+				assert.Equal(t, "Stripe declined the capture of payment pi_123", data["payment_capture_failure_reason"])
+				assert.Equal(t, entities.ActorProvider, source.Actor)
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged}, outboxTopics(outbox))
+			}).
+			Return(nil)
+
+		assert.NoError(t, s.captureOrderPayment(context.Background(), payloadFor(order)))
+	})
+
+	t.Run("orders whose capture failed are not captured again", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := pendingOrder()
+		failedAt := time.Now()
+		order.PaymentCaptureFailedAt = &failedAt
+
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), order.ID).Return(order, nil)
+
+		assert.NoError(t, s.captureOrderPayment(context.Background(), payloadFor(order)))
+	})
+
+	t.Run("captured orders are left alone", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := pendingOrder()
+		capturedAt := time.Now()
+		order.PaymentCapturedAt = &capturedAt
+
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), order.ID).Return(order, nil)
+
+		assert.NoError(t, s.captureOrderPayment(context.Background(), payloadFor(order)))
+	})
+}
+
+func TestCancelOrder_AuthorizedPayment(t *testing.T) {
+	tc := setupTestController(t)
+	s := newServiceUnderTest(tc)
+
+	order := authorizedOrder(providers.ProviderStripe, "pi_123")
+	order.Status = entities.PaymentSuccess
+	ctx := sharedMeta.WithXCustomerID(context.Background(), order.CustomerID.String())
+
+	tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), order.ID).Return(order, nil)
+	tc.mockRepo.EXPECT().
+		Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, data map[string]interface{}, _, _ string, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+			assert.Equal(t, entities.Cancelled, data["status"])
+			assert.NotNil(t, data["payment_voided_at"])
+			assert.Equal(t, []entities.OutboxTopic{entities.TopicPaymentVoidOrder, entities.TopicOrderStatusChanged, entities.TopicInventoryUpdateOrderStatus}, outboxTopics(outbox))
+		}).
+		Return(nil)
+
+	err := s.CancelOrder(ctx, &entities.CancelOrderRequest{OrderID: order.ID})
+
+	assert.NoError(t, err)
+}
+
+func TestVoidOrderPayment(t *testing.T) {
+	t.Run("releases the hold", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := authorizedOrder(providers.ProviderStripe, "pi_123")
+		order.Status = entities.Cancelled
+
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), order.ID).Return(order, nil)
+		tc.mockPayment.EXPECT().
+			Void(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, voidReq providers.VoidRequest) {
+				assert.Equal(t, "pi_123", voidReq.PaymentID)
+				assert.NotEmpty(t, voidReq.IdempotencyKey)
+			}).
+			Return(nil)
+
+		assert.NoError(t, s.voidOrderPayment(context.Background(), entities.PaymentVoidOrderPayload{OrderID: order.ID}))
+	})
+
+	t.Run("provider errors are retried", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := authorizedOrder(providers.ProviderStripe, "pi_123")
+		order.Status = entities.Cancelled
+
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), order.ID).Return(order, nil)
+		tc.mockPayment.EXPECT().Void(gomock.Any(), gomock.Any()).Return(errors.New("stripe unavailable"))
+
+		err := s.voidOrderPayment(context.Background(), entities.PaymentVoidOrderPayload{OrderID: order.ID})
+
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, errUndeliverable)
+	})
+
+	t.Run("captured payments are left alone", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := authorizedOrder(providers.ProviderStripe, "pi_123")
+		capturedAt := time.Now()
+		order.PaymentCapturedAt = &capturedAt
+
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), order.ID).Return(order, nil)
+
+		assert.NoError(t, s.voidOrderPayment(context.Background(), entities.PaymentVoidOrderPayload{OrderID: order.ID}))
+	})
+}
+
+func TestRefundOrder_AuthorizedPayment(t *testing.T) {
+	tc := setupTestController(t)
+	s := newServiceUnderTest(tc)
+
+	order := authorizedOrder(providers.ProviderStripe, "pi_123")
+
+	tc.mockRepo.EXPECT().GetOrderByReference(gomock.Any(), order.OrderReference).Return(order, nil)
+
+	resp, err := s.refundOrder(context.Background(), &entities.RefundOrderRequest{
+		OrderReference: order.OrderReference,
+		Body:           &entities.RefundOrderRequestBody{Items: []*entities.RefundItem{{Sku: "SKU1", Quantity: 1}}},
+	}, "")
+
+	assert.Nil(t, resp)
+	assert.Equal(t, moduleErrors.NewAPIError("ORDER_PAYMENT_NOT_CAPTURED"), err)
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/orders/errors"
+)
+
+// ProcessPaymentDisputed records a dispute opened or closed against the payment of an order and
+// notifies the dispute webhook, so someone can submit evidence before the response is due.
+// The order status is left alone, a dispute doesn't stop the fulfillment.
+func (s *service) ProcessPaymentDisputed(ctx context.Context, dispute *entities.PaymentDispute) error {
+	order, err := s.getOrderByPaymentID(ctx, dispute.PaymentProvider, dispute.PaymentID)
+	if err != nil {
+		s.log.Errorf("Error fetching order of disputed payment %s: %v", dispute.PaymentID, err)
+		return moduleErrors.NewAPIError("ORDER_NOT_FOUND_BY_PAYMENT_ID")
+	}
+
+	if order.DisputeID != nil && *order.DisputeID == dispute.ID && order.DisputeStatus != nil {
+		// redeliveries and events arriving out of order can't undo the recorded outcome
+		if *order.DisputeStatus == dispute.Status || order.DisputeStatus.IsClosed() {
+			s.log.Infof("Dispute %s of order %s is already %s", dispute.ID, order.ID, *order.DisputeStatus)
+			return nil
+		}
+	}
+
+	outbox, err := newOutboxMessages(notifyDisputeChange(order, dispute))
+	if err != nil {
+		s.log.Errorf("Error preparing order side effects: %v", err)
+		return err
+	}
+
+	err = s.repo.Update(ctx, map[string]interface{}{
+		"dispute_id":     dispute.ID,
+		"dispute_status": dispute.Status,
+		"dispute_reason": dispute.Reason,
+	}, order.ID.String(), order.CustomerID.String(),
+		eventSource(ctx, entities.ActorWebhook, fmt.Sprintf("Payment dispute %s %s", dispute.ID, dispute.Status)), outbox)
+	if err != nil {
+		s.log.Errorf("Error updating dispute of order %s: %v", order.ID, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	webhookEntities "github.com/nurdsoft/nurd-commerce-core/internal/webhook/entities"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessPaymentDisputed(t *testing.T) {
+	paymentIntentID := "pi_123"
+
+	newDispute := func(status entities.DisputeStatus) *entities.PaymentDispute {
+		return &entities.PaymentDispute{
+			ID:              "dp_123",
+			PaymentProvider: providers.ProviderStripe,
+			PaymentID:       paymentIntentID,
+			Status:          status,
+			Reason:          "fraudulent",
+			Amount:          decimal.NewFromInt(100),
+		}
+	}
+
+	t.Run("dispute opened", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := &entities.Order{
+			ID:             uuid.New(),
+			CustomerID:     uuid.New(),
+			OrderReference: "ORD-123",
+			Status:         entities.Delivered,
+		}
+
+		tc.mockRepo.EXPECT().GetOrderByPaymentID(gomock.Any(), providers.ProviderStripe, paymentIntentID).Return(order, nil)
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _ string, _ string, source entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				_, hasStatus := data["status"]
+				assert.False(t, hasStatus, "a dispute doesn't change the order status")
+				assert.Equal(t, "dp_123", data["dispute_id"])
+				assert.Equal(t, entities.Disputed, data["dispute_status"])
+				assert.Equal(t, "fraudulent", data["dispute_reason"])
+				assert.Equal(t, entities.ActorWebhook, source.Actor)
+
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderDisputeChanged}, outboxTopics(outbox))
+				var payload webhookEntities.NotifyOrderDisputeChangeRequest
+				assert.NoError(t, json.Unmarshal(outbox[0].Payload, &payload))
+				assert.Equal(t, "ORD-123", payload.OrderReference)
+				assert.Equal(t, "disputed", payload.DisputeStatus)
+				assert.Equal(t, "100.00", payload.Amount)
+			}).
+			Return(nil)
+
+		err := s.ProcessPaymentDisputed(context.Background(), newDispute(entities.Disputed))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("dispute closed", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		disputeID := "dp_123"
+		disputed := entities.Disputed
+		order := &entities.Order{
+			ID:            uuid.New(),
+			CustomerID:    uuid.New(),
+			Status:        entities.Delivered,
+			DisputeID:     &disputeID,
+			DisputeStatus: &disputed,
+		}
+
+		tc.mockRepo.EXPECT().GetOrderByPaymentID(gomock.Any(), providers.ProviderStripe, paymentIntentID).Return(order, nil)
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _ string, _ string, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, entities.DisputeLost, data["dispute_status"])
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderDisputeChanged}, outboxTopics(outbox))
+			}).
+			Return(nil)
+
+		err := s.ProcessPaymentDisputed(context.Background(), newDispute(entities.DisputeLost))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("late event doesn't reopen a closed dispute", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		disputeID := "dp_123"
+		won := entities.DisputeWon
+		order := &entities.Order{
+			ID:            uuid.New(),
+			CustomerID:    uuid.New(),
+			Status:        entities.Delivered,
+			DisputeID:     &disputeID,
+			DisputeStatus: &won,
+		}
+
+		tc.mockRepo.EXPECT().GetOrderByPaymentID(gomock.Any(), providers.ProviderStripe, paymentIntentID).Return(order, nil)
+
+		err := s.ProcessPaymentDisputed(context.Background(), newDispute(entities.Disputed))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("order not found", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		tc.mockRepo.EXPECT().GetOrderByPaymentID(gomock.Any(), providers.ProviderStripe, paymentIntentID).Return(nil, assert.AnError)
+
+		err := s.ProcessPaymentDisputed(context.Background(), newDispute(entities.Disputed))
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/orders/errors"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+)
+
+// staleOrderStatuses are the statuses an order keeps until the payment provider reports the outcome of the payment
+var staleOrderStatuses = []entities.OrderStatus{entities.Pending, entities.RequiresAction}
+
+// ExpireStaleOrders settles one batch of orders that have been waiting for their payment for longer than ttl
+// and returns how many were looked at. The payment provider is asked for the real state of each payment: the
+// order is reconciled when the payment went through or failed, and cancelled when the customer never completed it.
+func (s *service) ExpireStaleOrders(ctx context.Context, ttl time.Duration, limit int) (int, error) {
+	orders, err := s.repo.ListStaleOrders(ctx, staleOrderStatuses, time.Now().Add(-ttl), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, order := range orders {
+		if err := s.expireStaleOrder(ctx, order); err != nil {
+			s.log.Errorf("Error expiring stale order %s: %v", order.ID, err)
+		}
+	}
+
+	return len(orders), nil
+}
+
+func (s *service) expireStaleOrder(ctx context.Context, order *entities.Order) error {
+	if order.PaymentID == nil {
+		return s.cancelStaleOrder(ctx, order, false)
+	}
+
+	paymentClient, paymentID, err := s.orderPayment(order)
+	if err != nil {
+		// the provider of the payment isn't configured any more, the order is left for a manual review
+		s.log.Warnf("Skipping stale order %s: %v", order.ID, err)
+		return nil
+	}
+
+	payment, err := paymentClient.GetPayment(ctx, paymentID)
+	if err != nil {
+		return err
+	}
+
+	switch payment.Status {
+	case providers.PaymentStatusSuccess:
+		s.log.Infof("Reconciling stale order %s, payment %s succeeded", order.ID, paymentID)
+		return s.ProcessPaymentSucceeded(ctx, order.PaymentProvider, paymentID)
+	case providers.PaymentStatusFailed:
+		s.log.Infof("Reconciling stale order %s, payment %s failed", order.ID, paymentID)
+		return s.ProcessPaymentFailed(ctx, order.PaymentProvider, paymentID)
+	case providers.PaymentStatusPending:
+		// the provider is still processing the payment (e.g. fraud review), its webhook settles the order
+		s.log.Infof("Payment %s of stale order %s is still pending", paymentID, order.ID)
+		return nil
+	default:
+		// the customer never completed the payment, e.g. the 3-D Secure challenge was abandoned
+		return s.cancelStaleOrder(ctx, order, true)
+	}
+}
+
+// cancelStaleOrder cancels an order whose payment was never completed, releasing the inventory held
+// for it and, with releasePayment, the payment so the customer can't complete it afterwards.
+func (s *service) cancelStaleOrder(ctx context.Context, order *entities.Order, releasePayment bool) error {
+	if err := validateStatusTransition(order.Status, entities.Cancelled); err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"status": entities.Cancelled,
+	}
+
+	sideEffects := []outboxEntry{
+		notifyStatusChange(order, entities.Cancelled.String()),
+		updateInventoryOrderStatus(order, entities.Cancelled.String()),
+	}
+
+	if releasePayment {
+		data["payment_voided_at"] = time.Now().UTC()
+		sideEffects = append(sideEffects, voidPayment(order))
+	}
+
+	outbox, err := newOutboxMessages(sideEffects...)
+	if err != nil {
+		s.log.Errorf("Error preparing order side effects: %v", err)
+		return moduleErrors.NewAPIError("ORDER_ERROR_CANCELLING")
+	}
+
+	err = s.repo.Update(ctx, data, order.ID.String(), order.CustomerID.String(), eventSource(ctx, entities.ActorSystem, "Order expired, the payment was not completed"), outbox)
+	if err != nil {
+		s.log.Errorf("Error updating expired order %s: %v", order.ID, err)
+		return moduleErrors.NewAPIError("ORDER_ERROR_CANCELLING")
+	}
+
+	return nil
+}
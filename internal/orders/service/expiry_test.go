@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"github.com/stretchr/testify/assert"
+)
+
+func staleOrder(status entities.OrderStatus, paymentID *string) *entities.Order {
+	return &entities.Order{
+		ID:              uuid.New(),
+		CustomerID:      uuid.New(),
+		OrderReference:  "ORD123456",
+		Status:          status,
+		PaymentProvider: providers.ProviderStripe,
+		PaymentID:       paymentID,
+		CreatedAt:       time.Now().Add(-2 * time.Hour),
+	}
+}
+
+func TestExpireStaleOrders(t *testing.T) {
+	paymentID := "pi_123"
+
+	expectStaleOrders := func(tc *testController, orders ...*entities.Order) {
+		tc.mockRepo.EXPECT().
+			ListStaleOrders(gomock.Any(), []entities.OrderStatus{entities.Pending, entities.RequiresAction}, gomock.Any(), 50).
+			Do(func(_ context.Context, _ []entities.OrderStatus, createdBefore time.Time, _ int) {
+				assert.WithinDuration(t, time.Now().Add(-time.Hour), createdBefore, time.Minute)
+			}).
+			Return(orders, nil)
+	}
+
+	t.Run("reconciles failed payment", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := staleOrder(entities.Pending, &paymentID)
+		expectStaleOrders(tc, order)
+
+		tc.mockPayment.EXPECT().GetPayment(gomock.Any(), paymentID).
+			Return(providers.PaymentProviderResponse{ID: paymentID, Status: providers.PaymentStatusFailed}, nil)
+		tc.mockRepo.EXPECT().GetOrderByPaymentID(gomock.Any(), order.PaymentProvider, paymentID).Return(order, nil)
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _, _ string, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, entities.PaymentFailed, data["status"])
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged, entities.TopicInventoryUpdateOrderStatus}, outboxTopics(outbox))
+			}).
+			Return(nil)
+
+		count, err := s.ExpireStaleOrders(context.Background(), time.Hour, 50)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("cancels abandoned payment", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := staleOrder(entities.RequiresAction, &paymentID)
+		expectStaleOrders(tc, order)
+
+		tc.mockPayment.EXPECT().GetPayment(gomock.Any(), paymentID).
+			Return(providers.PaymentProviderResponse{ID: paymentID, Status: providers.PaymentStatusRequiresAction}, nil)
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _, _ string, source entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, entities.Cancelled, data["status"])
+				assert.NotNil(t, data["payment_voided_at"])
+				assert.Equal(t, entities.ActorSystem, source.Actor)
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged, entities.TopicInventoryUpdateOrderStatus, entities.TopicPaymentVoidOrder}, outboxTopics(outbox))
+			}).
+			Return(nil)
+
+		count, err := s.ExpireStaleOrders(context.Background(), time.Hour, 50)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("cancels order without payment", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := staleOrder(entities.Pending, nil)
+		expectStaleOrders(tc, order)
+
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _, _ string, _ entities.OrderEventSource, _ []*entities.OutboxMessage) {
+				assert.Equal(t, map[string]interface{}{"status": entities.Cancelled}, data)
+			}).
+			Return(nil)
+
+		count, err := s.ExpireStaleOrders(context.Background(), time.Hour, 50)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("leaves payment still pending at the provider", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		expectStaleOrders(tc, staleOrder(entities.Pending, &paymentID))
+
+		tc.mockPayment.EXPECT().GetPayment(gomock.Any(), paymentID).
+			Return(providers.PaymentProviderResponse{ID: paymentID, Status: providers.PaymentStatusPending}, nil)
+
+		count, err := s.ExpireStaleOrders(context.Background(), time.Hour, 50)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("skips order when the provider can't be reached", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		otherPaymentID := "pi_456"
+		expectStaleOrders(tc, staleOrder(entities.Pending, &paymentID), staleOrder(entities.Pending, &otherPaymentID))
+
+		tc.mockPayment.EXPECT().GetPayment(gomock.Any(), paymentID).
+			Return(providers.PaymentProviderResponse{}, errors.New("stripe unavailable"))
+		tc.mockPayment.EXPECT().GetPayment(gomock.Any(), otherPaymentID).
+			Return(providers.PaymentProviderResponse{ID: otherPaymentID, Status: providers.PaymentStatusPending}, nil)
+
+		count, err := s.ExpireStaleOrders(context.Background(), time.Hour, 50)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("skips order paid with a provider that isn't configured", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := staleOrder(entities.Pending, &paymentID)
+		order.PaymentProvider = "square"
+		expectStaleOrders(tc, order)
+
+		count, err := s.ExpireStaleOrders(context.Background(), time.Hour, 50)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("error listing stale orders", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		tc.mockRepo.EXPECT().
+			ListStaleOrders(gomock.Any(), gomock.Any(), gomock.Any(), 50).
+			Return(nil, errors.New("database error"))
+
+		count, err := s.ExpireStaleOrders(context.Background(), time.Hour, 50)
+
+		assert.EqualError(t, err, "database error")
+		assert.Equal(t, 0, count)
+	})
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"net/mail"
+
+	addressEntities "github.com/nurdsoft/nurd-commerce-core/internal/address/entities"
+	customerEntities "github.com/nurdsoft/nurd-commerce-core/internal/customer/entities"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/orders/errors"
+	sharedMeta "github.com/nurdsoft/nurd-commerce-core/shared/meta"
+)
+
+// isGuestCheckout reports whether the order is placed from a guest cart, a signed in customer checks out their
+// own cart even with a cart token.
+func isGuestCheckout(ctx context.Context) bool {
+	return sharedMeta.XCustomerID(ctx) == "" && sharedMeta.XCartToken(ctx) != ""
+}
+
+// validateGuestCheckout checks a guest sent what a customer has saved, their email and the address to ship to.
+func validateGuestCheckout(body *entities.CreateOrderRequestBody) error {
+	if _, err := mail.ParseAddress(body.Email); err != nil {
+		return moduleErrors.NewAPIError("ORDER_GUEST_EMAIL_REQUIRED")
+	}
+
+	if body.Address == nil {
+		return moduleErrors.NewAPIError("ORDER_GUEST_ADDRESS_REQUIRED")
+	}
+
+	return nil
+}
+
+// deliveryAddress returns the address the order is shipped to, a saved address of the customer or the address
+// of the guest.
+func (s *service) deliveryAddress(ctx context.Context, body *entities.CreateOrderRequestBody, guest bool) (*addressEntities.Address, error) {
+	if !guest {
+		return s.addressClient.GetAddress(ctx, &addressEntities.GetAddressRequest{
+			AddressID: body.AddressID,
+		})
+	}
+
+	return &addressEntities.Address{
+		FullName:    body.Address.FullName,
+		Address:     body.Address.Address,
+		Apartment:   body.Address.Apartment,
+		City:        body.Address.City,
+		PhoneNumber: body.Address.PhoneNumber,
+		StateCode:   body.Address.StateCode,
+		CountryCode: body.Address.CountryCode,
+		PostalCode:  body.Address.PostalCode,
+	}, nil
+}
+
+// orderCustomer returns the customer the order is placed for, the orders of a guest are placed for the guest
+// customer of their email.
+func (s *service) orderCustomer(ctx context.Context, body *entities.CreateOrderRequestBody, guest bool, address *addressEntities.Address) (*customerEntities.Customer, error) {
+	if !guest {
+		return s.customerClient.GetCustomer(ctx)
+	}
+
+	customer, err := s.customerClient.GetOrCreateGuestCustomer(ctx, body.Email, address.FullName)
+	if err != nil {
+		s.log.Errorf("Error getting guest customer: %v", err)
+		return nil, moduleErrors.NewAPIError("ORDER_ERROR_CREATING")
+	}
+
+	return customer, nil
+}
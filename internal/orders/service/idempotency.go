@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/orders/errors"
+)
+
+const (
+	idempotencyScopeCreateOrder  = "create_order"
+	idempotencyScopeRefundOrder  = "refund_order"
+	idempotencyScopeCancelOrder  = "cancel_order"
+	idempotencyScopeRefundReturn = "refund_return"
+	idempotencyScopeCaptureOrder = "capture_order"
+	idempotencyScopeVoidOrder    = "void_order"
+)
+
+// idempotencyClaimLease is how long a request keeps its key without storing a response. It is well above
+// what a request takes, a claim left behind longer than that was abandoned and a retry takes the key over.
+// The retry reuses the payment provider keys derived from the client key, so the payment isn't made twice.
+const idempotencyClaimLease = 5 * time.Minute
+
+// idempotent runs fn at most once per idempotency key within the given scope.
+// The response of a successful call is stored and replayed for retries with the same key,
+// while reusing the key for a different request is rejected. Failed calls release the key
+// so the client can retry them, as do claims abandoned for longer than idempotencyClaimLease.
+// Requests without a key are executed as is.
+func idempotent[T any](ctx context.Context, s *service, scope, key string, request any, fn func() (*T, error)) (*T, error) {
+	if key == "" {
+		return fn()
+	}
+
+	requestHash, err := hashRequest(request)
+	if err != nil {
+		s.log.Errorf("Error hashing request for idempotency key %s: %v", key, err)
+		return nil, moduleErrors.NewAPIError("ORDER_IDEMPOTENCY_ERROR")
+	}
+
+	record, claimed, err := s.repo.ClaimIdempotencyKey(ctx, &entities.IdempotencyKey{
+		Scope:       scope,
+		Key:         key,
+		RequestHash: requestHash,
+	}, idempotencyClaimLease)
+	if err != nil {
+		s.log.Errorf("Error claiming idempotency key %s: %v", key, err)
+		return nil, moduleErrors.NewAPIError("ORDER_IDEMPOTENCY_ERROR")
+	}
+
+	if !claimed {
+		if record.RequestHash != requestHash {
+			return nil, moduleErrors.NewAPIError("ORDER_IDEMPOTENCY_KEY_REUSED")
+		}
+
+		if len(record.Response) == 0 {
+			return nil, moduleErrors.NewAPIError("ORDER_IDEMPOTENCY_KEY_IN_PROGRESS")
+		}
+
+		var response T
+		if err := json.Unmarshal(record.Response, &response); err != nil {
+			s.log.Errorf("Error decoding stored response for idempotency key %s: %v", key, err)
+			return nil, moduleErrors.NewAPIError("ORDER_IDEMPOTENCY_ERROR")
+		}
+
+		return &response, nil
+	}
+
+	// the outcome has to be persisted even if the client went away in the meantime
+	storeCtx := context.WithoutCancel(ctx)
+
+	response, err := fn()
+	if err != nil {
+		if releaseErr := s.repo.DeleteIdempotencyKey(storeCtx, scope, key); releaseErr != nil {
+			s.log.Errorf("Error releasing idempotency key %s: %v", key, releaseErr)
+		}
+		return nil, err
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		s.log.Errorf("Error encoding response for idempotency key %s: %v", key, err)
+		return response, nil
+	}
+
+	if err := s.repo.SaveIdempotencyResponse(storeCtx, scope, key, body); err != nil {
+		s.log.Errorf("Error storing response for idempotency key %s: %v", key, err)
+	}
+
+	return response, nil
+}
+
+// hashRequest fingerprints a request so a reused key can be matched against the original request.
+func hashRequest(request any) (string, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// providerIdempotencyKey derives the key forwarded to the payment provider from the client key.
+// It is namespaced by scope so keys picked by different customers or used for different operations
+// never collide on the provider side, and hashed to stay within the provider's length limit.
+func providerIdempotencyKey(scope, key string) string {
+	if key == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(scope + ":" + key))
+	return hex.EncodeToString(sum[:])
+}
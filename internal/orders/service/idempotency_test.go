@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotent(t *testing.T) {
+	request := map[string]string{"address_id": "123"}
+	response := &entities.CreateOrderResponse{OrderReference: "ORD123456"}
+
+	t.Run("runs without a key", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		res, err := idempotent(context.Background(), s, idempotencyScopeCreateOrder, "", request, func() (*entities.CreateOrderResponse, error) {
+			return response, nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, response, res)
+	})
+
+	t.Run("stores the response of the first request", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		tc.mockRepo.EXPECT().
+			ClaimIdempotencyKey(gomock.Any(), gomock.Any(), idempotencyClaimLease).
+			DoAndReturn(func(_ context.Context, record *entities.IdempotencyKey, _ time.Duration) (*entities.IdempotencyKey, bool, error) {
+				assert.Equal(t, idempotencyScopeCreateOrder, record.Scope)
+				assert.Equal(t, "key-1", record.Key)
+				assert.NotEmpty(t, record.RequestHash)
+				return record, true, nil
+			})
+
+		tc.mockRepo.EXPECT().
+			SaveIdempotencyResponse(gomock.Any(), idempotencyScopeCreateOrder, "key-1", gomock.Any()).
+			Do(func(_ context.Context, _, _ string, body []byte) {
+				assert.JSONEq(t, `{"order_reference":"ORD123456","order_items":null}`, string(body))
+			}).
+			Return(nil)
+
+		res, err := idempotent(context.Background(), s, idempotencyScopeCreateOrder, "key-1", request, func() (*entities.CreateOrderResponse, error) {
+			return response, nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, response, res)
+	})
+
+	t.Run("replays the stored response", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		requestHash, _ := hashRequest(request)
+		tc.mockRepo.EXPECT().
+			ClaimIdempotencyKey(gomock.Any(), gomock.Any(), idempotencyClaimLease).
+			Return(&entities.IdempotencyKey{
+				Scope:       idempotencyScopeCreateOrder,
+				Key:         "key-1",
+				RequestHash: requestHash,
+				Response:    []byte(`{"order_reference":"ORD123456"}`),
+			}, false, nil)
+
+		res, err := idempotent(context.Background(), s, idempotencyScopeCreateOrder, "key-1", request, func() (*entities.CreateOrderResponse, error) {
+			t.Fatal("request should not be executed twice")
+			return nil, nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ORD123456", res.OrderReference)
+	})
+
+	t.Run("error key reused with a different request", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		tc.mockRepo.EXPECT().
+			ClaimIdempotencyKey(gomock.Any(), gomock.Any(), idempotencyClaimLease).
+			Return(&entities.IdempotencyKey{
+				RequestHash: "another-request",
+				Response:    []byte(`{"order_reference":"ORD123456"}`),
+			}, false, nil)
+
+		res, err := idempotent(context.Background(), s, idempotencyScopeCreateOrder, "key-1", request, func() (*entities.CreateOrderResponse, error) {
+			t.Fatal("request should not be executed")
+			return nil, nil
+		})
+
+		assert.Nil(t, res)
+		assert.ErrorContains(t, err, "Idempotency key has already been used for a different request")
+	})
+
+	t.Run("error original request still in progress", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		requestHash, _ := hashRequest(request)
+		tc.mockRepo.EXPECT().
+			ClaimIdempotencyKey(gomock.Any(), gomock.Any(), idempotencyClaimLease).
+			Return(&entities.IdempotencyKey{RequestHash: requestHash}, false, nil)
+
+		res, err := idempotent(context.Background(), s, idempotencyScopeCreateOrder, "key-1", request, func() (*entities.CreateOrderResponse, error) {
+			t.Fatal("request should not be executed")
+			return nil, nil
+		})
+
+		assert.Nil(t, res)
+		assert.ErrorContains(t, err, "still being processed")
+	})
+
+	t.Run("releases the key when the request fails", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		tc.mockRepo.EXPECT().
+			ClaimIdempotencyKey(gomock.Any(), gomock.Any(), idempotencyClaimLease).
+			DoAndReturn(func(_ context.Context, record *entities.IdempotencyKey, _ time.Duration) (*entities.IdempotencyKey, bool, error) {
+				return record, true, nil
+			})
+
+		tc.mockRepo.EXPECT().
+			DeleteIdempotencyKey(gomock.Any(), idempotencyScopeCreateOrder, "key-1").
+			Return(nil)
+
+		res, err := idempotent(context.Background(), s, idempotencyScopeCreateOrder, "key-1", request, func() (*entities.CreateOrderResponse, error) {
+			return nil, errors.New("payment declined")
+		})
+
+		assert.Nil(t, res)
+		assert.EqualError(t, err, "payment declined")
+	})
+}
+
+func TestProviderIdempotencyKey(t *testing.T) {
+	assert.Empty(t, providerIdempotencyKey(idempotencyScopeRefundOrder, ""))
+
+	key := providerIdempotencyKey(idempotencyScopeRefundOrder, "key-1")
+	assert.Len(t, key, 64)
+	assert.Equal(t, key, providerIdempotencyKey(idempotencyScopeRefundOrder, "key-1"))
+	assert.NotEqual(t, key, providerIdempotencyKey(idempotencyScopeCreateOrder+":customer", "key-1"))
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	webhookEntities "github.com/nurdsoft/nurd-commerce-core/internal/webhook/entities"
+	inventoryEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory/entities"
+)
+
+const (
+	// outboxMaxAttempts is the number of deliveries before a message is marked as dead
+	outboxMaxAttempts = 10
+	// outboxBaseRetryDelay is doubled after each failed delivery, up to outboxMaxRetryDelay
+	outboxBaseRetryDelay = 30 * time.Second
+	outboxMaxRetryDelay  = time.Hour
+	// outboxLease is how long a claimed message stays hidden from other dispatchers
+	outboxLease = 5 * time.Minute
+	// outboxDeliveryTimeout bounds a single delivery, it has to stay below outboxLease
+	outboxDeliveryTimeout = 2 * time.Minute
+)
+
+// errUndeliverable marks messages that will never succeed, they are not retried
+var errUndeliverable = errors.New("undeliverable outbox message")
+
+type outboxEntry struct {
+	topic   entities.OutboxTopic
+	payload any
+}
+
+// newOutboxMessages encodes the side effects of an order change so they can be stored with it.
+func newOutboxMessages(entries ...outboxEntry) ([]*entities.OutboxMessage, error) {
+	messages := make([]*entities.OutboxMessage, 0, len(entries))
+	for _, entry := range entries {
+		message, err := entities.NewOutboxMessage(entry.topic, entry.payload)
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s outbox message: %w", entry.topic, err)
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+func notifyStatusChange(order *entities.Order, status string) outboxEntry {
+	return outboxEntry{
+		topic: entities.TopicOrderStatusChanged,
+		payload: webhookEntities.NotifyOrderStatusChangeRequest{
+			CustomerID:     order.CustomerID.String(),
+			OrderID:        order.ID.String(),
+			OrderReference: order.OrderReference,
+			Status:         status,
+		},
+	}
+}
+
+func updateInventoryOrderStatus(order *entities.Order, status string) outboxEntry {
+	return outboxEntry{
+		topic: entities.TopicInventoryUpdateOrderStatus,
+		payload: entities.InventoryUpdateOrderStatusPayload{
+			OrderID: order.ID,
+			Status:  status,
+		},
+	}
+}
+
+func notifyReturnStatusChange(order *entities.Order, orderReturn *entities.OrderReturn) outboxEntry {
+	return outboxEntry{
+		topic: entities.TopicReturnStatusChanged,
+		payload: webhookEntities.NotifyReturnStatusChangeRequest{
+			ReturnID:        orderReturn.ID.String(),
+			ReturnReference: orderReturn.ReturnReference,
+			OrderID:         order.ID.String(),
+			OrderReference:  order.OrderReference,
+			CustomerID:      order.CustomerID.String(),
+			Status:          orderReturn.Status.String(),
+		},
+	}
+}
+
+func notifyDisputeChange(order *entities.Order, dispute *entities.PaymentDispute) outboxEntry {
+	return outboxEntry{
+		topic: entities.TopicOrderDisputeChanged,
+		payload: webhookEntities.NotifyOrderDisputeChangeRequest{
+			OrderID:        order.ID.String(),
+			CustomerID:     order.CustomerID.String(),
+			OrderReference: order.OrderReference,
+			DisputeID:      dispute.ID,
+			DisputeStatus:  dispute.Status.String(),
+			Reason:         dispute.Reason,
+			Amount:         dispute.Amount.StringFixed(2),
+		},
+	}
+}
+
+// DispatchOutbox delivers one batch of due outbox messages and returns how many were claimed.
+func (s *service) DispatchOutbox(ctx context.Context, limit int) (int, error) {
+	messages, err := s.repo.ClaimOutboxMessages(ctx, limit, outboxLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, message := range messages {
+		deliveryCtx, cancel := context.WithTimeout(ctx, outboxDeliveryTimeout)
+		err := s.deliverOutboxMessage(deliveryCtx, message)
+		cancel()
+
+		if err == nil {
+			if err := s.repo.MarkOutboxMessageDone(ctx, message.ID); err != nil {
+				s.log.Errorf("Error marking outbox message %s as done: %v", message.ID, err)
+			}
+			continue
+		}
+
+		var nextAttemptAt *time.Time
+		if !errors.Is(err, errUndeliverable) && message.Attempts < outboxMaxAttempts {
+			next := time.Now().Add(outboxRetryDelay(message.Attempts))
+			nextAttemptAt = &next
+			s.log.Warnf("Delivering outbox message %s (%s) failed on attempt %d: %v", message.ID, message.Topic, message.Attempts, err)
+		} else {
+			s.log.Errorf("Giving up on outbox message %s (%s) after %d attempts: %v", message.ID, message.Topic, message.Attempts, err)
+		}
+
+		if err := s.repo.MarkOutboxMessageFailed(ctx, message.ID, err.Error(), nextAttemptAt); err != nil {
+			s.log.Errorf("Error marking outbox message %s as failed: %v", message.ID, err)
+		}
+	}
+
+	return len(messages), nil
+}
+
+// OutboxDepth returns the number of messages waiting to be delivered.
+func (s *service) OutboxDepth(ctx context.Context) (int64, error) {
+	return s.repo.CountPendingOutboxMessages(ctx)
+}
+
+func (s *service) deliverOutboxMessage(ctx context.Context, message *entities.OutboxMessage) error {
+	switch message.Topic {
+	case entities.TopicOrderStatusChanged:
+		var req webhookEntities.NotifyOrderStatusChangeRequest
+		if err := json.Unmarshal(message.Payload, &req); err != nil {
+			return fmt.Errorf("%w: %v", errUndeliverable, err)
+		}
+
+		return s.webhookClient.NotifyOrderStatusChange(ctx, &req)
+	case entities.TopicReturnStatusChanged:
+		var req webhookEntities.NotifyReturnStatusChangeRequest
+		if err := json.Unmarshal(message.Payload, &req); err != nil {
+			return fmt.Errorf("%w: %v", errUndeliverable, err)
+		}
+
+		return s.webhookClient.NotifyReturnStatusChange(ctx, &req)
+	case entities.TopicOrderDisputeChanged:
+		var req webhookEntities.NotifyOrderDisputeChangeRequest
+		if err := json.Unmarshal(message.Payload, &req); err != nil {
+			return fmt.Errorf("%w: %v", errUndeliverable, err)
+		}
+
+		return s.webhookClient.NotifyOrderDisputeChange(ctx, &req)
+	case entities.TopicInventoryCreateOrder:
+		var payload entities.InventoryCreateOrderPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return fmt.Errorf("%w: %v", errUndeliverable, err)
+		}
+
+		order, err := s.repo.GetOrderByID(ctx, payload.OrderID)
+		if err != nil {
+			return err
+		}
+
+		orderItems, err := s.repo.GetOrderItemsByID(ctx, payload.OrderID)
+		if err != nil {
+			return err
+		}
+
+		customer, err := s.customerClient.GetCustomerByID(ctx, order.CustomerID.String())
+		if err != nil {
+			return err
+		}
+
+		// retries send the order as stored, the inventory provider reuses what an earlier attempt created from it
+		_, err = s.inventoryClient.CreateOrder(ctx, inventoryEntities.CreateInventoryOrderRequest{
+			Order:      *order,
+			OrderItems: orderItems,
+			Address:    payload.Address,
+			Customer:   *customer,
+			CartItems:  payload.CartItems,
+		})
+		return err
+	case entities.TopicInventoryUpdateOrderStatus:
+		var payload entities.InventoryUpdateOrderStatusPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return fmt.Errorf("%w: %v", errUndeliverable, err)
+		}
+
+		order, err := s.repo.GetOrderByID(ctx, payload.OrderID)
+		if err != nil {
+			return err
+		}
+
+		customer, err := s.customerClient.GetCustomerByID(ctx, order.CustomerID.String())
+		if err != nil {
+			return err
+		}
+
+		return s.inventoryClient.UpdateOrderStatus(ctx, inventoryEntities.UpdateInventoryOrderStatusRequest{
+			Order:    *order,
+			Customer: *customer,
+			Status:   payload.Status,
+		})
+	case entities.TopicPaymentCaptureOrder:
+		var payload entities.PaymentCaptureOrderPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return fmt.Errorf("%w: %v", errUndeliverable, err)
+		}
+
+		return s.captureOrderPayment(ctx, payload)
+	case entities.TopicPaymentVoidOrder:
+		var payload entities.PaymentVoidOrderPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return fmt.Errorf("%w: %v", errUndeliverable, err)
+		}
+
+		return s.voidOrderPayment(ctx, payload)
+	default:
+		return fmt.Errorf("%w: unknown topic %s", errUndeliverable, message.Topic)
+	}
+}
+
+// outboxRetryDelay returns the exponential backoff delay after the given number of attempts.
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := time.Duration(float64(outboxBaseRetryDelay) * math.Pow(2, float64(attempts-1)))
+	if delay <= 0 || delay > outboxMaxRetryDelay {
+		return outboxMaxRetryDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	customerEntities "github.com/nurdsoft/nurd-commerce-core/internal/customer/entities"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	webhookEntities "github.com/nurdsoft/nurd-commerce-core/internal/webhook/entities"
+	inventoryEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory/entities"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatchOutbox(t *testing.T) {
+	order := &entities.Order{
+		ID:             uuid.New(),
+		CustomerID:     uuid.New(),
+		OrderReference: "ORD123456",
+	}
+
+	newMessage := func(t *testing.T, entry outboxEntry, attempts int) *entities.OutboxMessage {
+		messages, err := newOutboxMessages(entry)
+		assert.NoError(t, err)
+		messages[0].ID = uuid.New()
+		messages[0].Attempts = attempts
+		return messages[0]
+	}
+
+	t.Run("delivers webhook notification and marks it done", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		message := newMessage(t, notifyStatusChange(order, entities.PaymentSuccess.String()), 1)
+
+		tc.mockRepo.EXPECT().
+			ClaimOutboxMessages(gomock.Any(), 10, outboxLease).
+			Return([]*entities.OutboxMessage{message}, nil)
+
+		tc.mockWebhook.EXPECT().
+			NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, req *webhookEntities.NotifyOrderStatusChangeRequest) {
+				assert.Equal(t, order.ID.String(), req.OrderID)
+				assert.Equal(t, order.OrderReference, req.OrderReference)
+				assert.Equal(t, entities.PaymentSuccess.String(), req.Status)
+			}).
+			Return(nil)
+
+		tc.mockRepo.EXPECT().
+			MarkOutboxMessageDone(gomock.Any(), message.ID).
+			Return(nil)
+
+		claimed, err := s.DispatchOutbox(context.Background(), 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, claimed)
+	})
+
+	t.Run("delivers inventory status update with fresh order data", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		message := newMessage(t, updateInventoryOrderStatus(order, entities.Cancelled.String()), 1)
+		salesforceID := "SF123"
+
+		tc.mockRepo.EXPECT().
+			ClaimOutboxMessages(gomock.Any(), 10, outboxLease).
+			Return([]*entities.OutboxMessage{message}, nil)
+
+		tc.mockRepo.EXPECT().
+			GetOrderByID(gomock.Any(), order.ID).
+			Return(&entities.Order{ID: order.ID, CustomerID: order.CustomerID, SalesforceID: salesforceID}, nil)
+
+		tc.mockCustomer.EXPECT().
+			GetCustomerByID(gomock.Any(), order.CustomerID.String()).
+			Return(&customerEntities.Customer{ID: order.CustomerID}, nil)
+
+		tc.mockInventory.EXPECT().
+			UpdateOrderStatus(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, req inventoryEntities.UpdateInventoryOrderStatusRequest) {
+				assert.Equal(t, salesforceID, req.Order.SalesforceID)
+				assert.Equal(t, entities.Cancelled.String(), req.Status)
+			}).
+			Return(nil)
+
+		tc.mockRepo.EXPECT().
+			MarkOutboxMessageDone(gomock.Any(), message.ID).
+			Return(nil)
+
+		_, err := s.DispatchOutbox(context.Background(), 10)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("delivers return webhook notification", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderReturn := &entities.OrderReturn{ID: uuid.New(), ReturnReference: "RMA-ABC123", Status: entities.ReturnStatusApproved}
+		message := newMessage(t, notifyReturnStatusChange(order, orderReturn), 1)
+
+		tc.mockRepo.EXPECT().
+			ClaimOutboxMessages(gomock.Any(), 10, outboxLease).
+			Return([]*entities.OutboxMessage{message}, nil)
+
+		tc.mockWebhook.EXPECT().
+			NotifyReturnStatusChange(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, req *webhookEntities.NotifyReturnStatusChangeRequest) {
+				assert.Equal(t, orderReturn.ReturnReference, req.ReturnReference)
+				assert.Equal(t, order.OrderReference, req.OrderReference)
+				assert.Equal(t, entities.ReturnStatusApproved.String(), req.Status)
+			}).
+			Return(nil)
+
+		tc.mockRepo.EXPECT().
+			MarkOutboxMessageDone(gomock.Any(), message.ID).
+			Return(nil)
+
+		_, err := s.DispatchOutbox(context.Background(), 10)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("delivers dispute webhook notification", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		dispute := &entities.PaymentDispute{ID: "dp_123", Status: entities.DisputeWon, Reason: "fraudulent", Amount: decimal.NewFromInt(25)}
+		message := newMessage(t, notifyDisputeChange(order, dispute), 1)
+
+		tc.mockRepo.EXPECT().
+			ClaimOutboxMessages(gomock.Any(), 10, outboxLease).
+			Return([]*entities.OutboxMessage{message}, nil)
+
+		tc.mockWebhook.EXPECT().
+			NotifyOrderDisputeChange(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, req *webhookEntities.NotifyOrderDisputeChangeRequest) {
+				assert.Equal(t, order.OrderReference, req.OrderReference)
+				assert.Equal(t, "dp_123", req.DisputeID)
+				assert.Equal(t, entities.DisputeWon.String(), req.DisputeStatus)
+			}).
+			Return(nil)
+
+		tc.mockRepo.EXPECT().
+			MarkOutboxMessageDone(gomock.Any(), message.ID).
+			Return(nil)
+
+		_, err := s.DispatchOutbox(context.Background(), 10)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("reschedules failed delivery", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		message := newMessage(t, notifyStatusChange(order, entities.PaymentSuccess.String()), 2)
+
+		tc.mockRepo.EXPECT().
+			ClaimOutboxMessages(gomock.Any(), 10, outboxLease).
+			Return([]*entities.OutboxMessage{message}, nil)
+
+		tc.mockWebhook.EXPECT().
+			NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
+			Return(errors.New("webhook unavailable"))
+
+		before := time.Now()
+		tc.mockRepo.EXPECT().
+			MarkOutboxMessageFailed(gomock.Any(), message.ID, "webhook unavailable", gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, _ string, nextAttemptAt *time.Time) {
+				assert.NotNil(t, nextAttemptAt)
+				assert.True(t, nextAttemptAt.After(before.Add(outboxBaseRetryDelay)))
+			}).
+			Return(nil)
+
+		_, err := s.DispatchOutbox(context.Background(), 10)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		message := newMessage(t, notifyStatusChange(order, entities.PaymentSuccess.String()), outboxMaxAttempts)
+
+		tc.mockRepo.EXPECT().
+			ClaimOutboxMessages(gomock.Any(), 10, outboxLease).
+			Return([]*entities.OutboxMessage{message}, nil)
+
+		tc.mockWebhook.EXPECT().
+			NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
+			Return(errors.New("webhook unavailable"))
+
+		tc.mockRepo.EXPECT().
+			MarkOutboxMessageFailed(gomock.Any(), message.ID, "webhook unavailable", nil).
+			Return(nil)
+
+		_, err := s.DispatchOutbox(context.Background(), 10)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("does not retry unknown topics", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		message := &entities.OutboxMessage{ID: uuid.New(), Topic: "unknown", Payload: []byte(`{}`), Attempts: 1}
+
+		tc.mockRepo.EXPECT().
+			ClaimOutboxMessages(gomock.Any(), 10, outboxLease).
+			Return([]*entities.OutboxMessage{message}, nil)
+
+		tc.mockRepo.EXPECT().
+			MarkOutboxMessageFailed(gomock.Any(), message.ID, gomock.Any(), nil).
+			Return(nil)
+
+		_, err := s.DispatchOutbox(context.Background(), 10)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("error claiming messages", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		tc.mockRepo.EXPECT().
+			ClaimOutboxMessages(gomock.Any(), 10, outboxLease).
+			Return(nil, errors.New("db error"))
+
+		claimed, err := s.DispatchOutbox(context.Background(), 10)
+
+		assert.ErrorContains(t, err, "db error")
+		assert.Equal(t, 0, claimed)
+	})
+}
+
+func TestOutboxRetryDelay(t *testing.T) {
+	assert.Equal(t, outboxBaseRetryDelay, outboxRetryDelay(1))
+	assert.Equal(t, 4*outboxBaseRetryDelay, outboxRetryDelay(3))
+	assert.Equal(t, outboxMaxRetryDelay, outboxRetryDelay(20))
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"github.com/shopspring/decimal"
+)
+
+const reconcileBatchSize = 100
+
+// ReconcileOrders compares the orders created in the date range with their payment at the payment provider:
+// the total, the status, the captured amount and the refunds. With Fix the orders whose webhooks were missed
+// are settled the way the webhooks would have, every other mismatch is only reported.
+func (s *service) ReconcileOrders(ctx context.Context, req *entities.ReconcileOrdersRequest) (*entities.ReconciliationReport, error) {
+	report := &entities.ReconciliationReport{
+		From:       req.From,
+		To:         req.To,
+		Mismatches: []*entities.ReconciliationMismatch{},
+	}
+
+	afterCreatedAt, afterID := req.From, uuid.Nil
+	for {
+		orders, err := s.repo.ListOrdersCreatedBetween(ctx, req.From, req.To, afterCreatedAt, afterID, reconcileBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, order := range orders {
+			report.Mismatches = append(report.Mismatches, s.reconcileOrder(ctx, order, req.Fix)...)
+		}
+		report.OrdersChecked += len(orders)
+
+		if len(orders) < reconcileBatchSize {
+			return report, nil
+		}
+
+		last := orders[len(orders)-1]
+		afterCreatedAt, afterID = last.CreatedAt, last.ID
+	}
+}
+
+func (s *service) reconcileOrder(ctx context.Context, order *entities.Order, fix bool) []*entities.ReconciliationMismatch {
+	paymentClient, paymentID, err := s.orderPayment(order)
+	if err != nil {
+		// the order has no payment or its provider isn't configured, there is nothing to compare it with
+		return nil
+	}
+
+	payment, err := paymentClient.GetPaymentDetails(ctx, paymentID)
+	if err != nil {
+		m := newMismatch(order, entities.MismatchPayment, paymentID, "")
+		m.Error = err.Error()
+		return []*entities.ReconciliationMismatch{m}
+	}
+
+	var mismatches []*entities.ReconciliationMismatch
+
+	if !payment.Amount.Equal(order.Total) {
+		mismatches = append(mismatches, newMismatch(order, entities.MismatchTotal, order.Total.StringFixed(2), payment.Amount.StringFixed(2)))
+	}
+
+	if order.PaymentCapturedAmount != nil && !order.PaymentCapturedAmount.Equal(payment.CapturedAmount) {
+		mismatches = append(mismatches, newMismatch(order, entities.MismatchCapturedTotal,
+			order.PaymentCapturedAmount.StringFixed(2), payment.CapturedAmount.StringFixed(2)))
+	}
+
+	refundMismatches, refundedAmount := s.reconcileOrderRefunds(ctx, paymentClient, order, payment, fix)
+
+	if m := s.reconcileOrderStatus(ctx, order, paymentID, payment, refundedAmount, fix); m != nil {
+		mismatches = append(mismatches, m)
+	}
+
+	return append(mismatches, refundMismatches...)
+}
+
+// reconcileOrderStatus checks that the order status agrees with the state of its payment. Only orders still
+// waiting for their payment webhook are fixed, anything else needs someone to look at the order.
+func (s *service) reconcileOrderStatus(ctx context.Context, order *entities.Order, paymentID string, payment *providers.PaymentDetails, refundedAmount decimal.Decimal, fix bool) *entities.ReconciliationMismatch {
+	switch order.Status {
+	case entities.Pending, entities.RequiresAction:
+		if payment.Status != providers.PaymentStatusSuccess && payment.Status != providers.PaymentStatusFailed {
+			return nil
+		}
+
+		m := newMismatch(order, entities.MismatchStatus, order.Status.String(), payment.ProviderStatus)
+		m.Fixable = true
+		if fix {
+			if payment.Status == providers.PaymentStatusSuccess {
+				applyFix(m, s.ProcessPaymentSucceeded(ctx, order.PaymentProvider, paymentID))
+			} else {
+				applyFix(m, s.ProcessPaymentFailed(ctx, order.PaymentProvider, paymentID))
+			}
+		}
+
+		return m
+	case entities.PaymentFailed:
+		if payment.Status == providers.PaymentStatusSuccess {
+			return newMismatch(order, entities.MismatchStatus, order.Status.String(), payment.ProviderStatus)
+		}
+	case entities.Cancelled:
+		// nothing may be left charged for a cancelled order
+		if payment.Status == providers.PaymentStatusSuccess && payment.CapturedAmount.GreaterThan(refundedAmount) {
+			return newMismatch(order, entities.MismatchStatus, order.Status.String(), payment.ProviderStatus)
+		}
+	default:
+		// every other status comes after a successful payment
+		if payment.Status != providers.PaymentStatusSuccess {
+			return newMismatch(order, entities.MismatchStatus, order.Status.String(), payment.ProviderStatus)
+		}
+	}
+
+	return nil
+}
+
+// reconcileOrderRefunds checks the refunds recorded on the order items against the payment provider and
+// returns the amount refunded by the provider. Refunds confirmed by the provider but still initiated on the
+// order are completed the way the refund webhook would have.
+func (s *service) reconcileOrderRefunds(ctx context.Context, paymentClient payment.Client, order *entities.Order, payment *providers.PaymentDetails, fix bool) ([]*entities.ReconciliationMismatch, decimal.Decimal) {
+	orderItems, err := s.repo.GetOrderItemsByID(ctx, order.ID)
+	if err != nil {
+		m := newMismatch(order, entities.MismatchRefundStatus, "", "")
+		m.Error = err.Error()
+		return []*entities.ReconciliationMismatch{m}, decimal.Zero
+	}
+
+	// items refunded together share the refund ID
+	var refundIDs []string
+	refundStatuses := make(map[string]entities.OrderItemStatus)
+	for _, item := range orderItems {
+		if item.RefundID == "" {
+			continue
+		}
+		if _, ok := refundStatuses[item.RefundID]; !ok {
+			refundIDs = append(refundIDs, item.RefundID)
+		}
+		refundStatuses[item.RefundID] = item.Status
+	}
+
+	var mismatches []*entities.ReconciliationMismatch
+	knownRefundedAmount := decimal.Zero
+	for _, refundID := range refundIDs {
+		status := refundStatuses[refundID]
+
+		refund, err := paymentClient.GetRefundDetails(ctx, refundID)
+		if err != nil {
+			m := newMismatch(order, entities.MismatchRefundStatus, status.String(), "")
+			m.RefundID = refundID
+			m.Error = err.Error()
+			mismatches = append(mismatches, m)
+			continue
+		}
+
+		if refund.Status == providers.RefundStatusSucceeded {
+			knownRefundedAmount = knownRefundedAmount.Add(refund.Amount)
+		}
+
+		var m *entities.ReconciliationMismatch
+		switch {
+		case status == entities.ItemInitiatedRefund && refund.Status == providers.RefundStatusSucceeded:
+			m = newMismatch(order, entities.MismatchRefundStatus, status.String(), refund.Status)
+			m.Fixable = true
+			if fix {
+				applyFix(m, s.ProcessRefundSucceeded(ctx, refundID, refund.Amount))
+			}
+		case status == entities.ItemInitiatedRefund && refund.Status == providers.RefundStatusFailed,
+			status == entities.ItemRefunded && refund.Status != providers.RefundStatusSucceeded:
+			m = newMismatch(order, entities.MismatchRefundStatus, status.String(), refund.Status)
+		}
+
+		if m != nil {
+			m.RefundID = refundID
+			mismatches = append(mismatches, m)
+		}
+	}
+
+	refundedAmount := knownRefundedAmount
+	if payment.RefundedAmount != nil {
+		refundedAmount = *payment.RefundedAmount
+
+		// refunds made at the payment provider directly are unknown to the order
+		if refundedAmount.GreaterThan(knownRefundedAmount) {
+			mismatches = append(mismatches, newMismatch(order, entities.MismatchRefundTotal,
+				knownRefundedAmount.StringFixed(2), refundedAmount.StringFixed(2)))
+			return mismatches, refundedAmount
+		}
+	}
+
+	// the refund total is only recorded once the order is fully refunded
+	if order.RefundTotal == nil && order.Status != entities.Refunded {
+		return mismatches, refundedAmount
+	}
+
+	orderRefundTotal := decimal.Zero
+	if order.RefundTotal != nil {
+		orderRefundTotal = *order.RefundTotal
+	}
+
+	if !orderRefundTotal.Equal(refundedAmount) {
+		m := newMismatch(order, entities.MismatchRefundTotal, orderRefundTotal.StringFixed(2), refundedAmount.StringFixed(2))
+		// the total can only be trusted once every refund agrees with the provider
+		m.Fixable = len(mismatches) == 0
+		if fix && m.Fixable {
+			applyFix(m, s.repo.Update(ctx, map[string]interface{}{
+				"refund_total": refundedAmount,
+			}, order.ID.String(), order.CustomerID.String(), eventSource(ctx, entities.ActorSystem, "Refund total reconciled with the payment provider"), nil))
+		}
+		mismatches = append(mismatches, m)
+	}
+
+	return mismatches, refundedAmount
+}
+
+func newMismatch(order *entities.Order, field entities.MismatchField, orderValue, providerValue string) *entities.ReconciliationMismatch {
+	return &entities.ReconciliationMismatch{
+		OrderID:        order.ID,
+		OrderReference: order.OrderReference,
+		Field:          field,
+		OrderValue:     orderValue,
+		ProviderValue:  providerValue,
+	}
+}
+
+func applyFix(m *entities.ReconciliationMismatch, err error) {
+	if err != nil {
+		m.Error = err.Error()
+		return
+	}
+
+	m.Fixed = true
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcileOrders(t *testing.T) {
+	from := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	paymentID := "pi_123"
+
+	reconciledOrder := func(status entities.OrderStatus) *entities.Order {
+		return &entities.Order{
+			ID:              uuid.New(),
+			CustomerID:      uuid.New(),
+			OrderReference:  "ORD123456",
+			Status:          status,
+			Total:           decimal.NewFromInt(100),
+			PaymentProvider: providers.ProviderStripe,
+			PaymentID:       &paymentID,
+			CreatedAt:       from.Add(time.Hour),
+		}
+	}
+
+	expectOrders := func(tc *testController, orders ...*entities.Order) {
+		tc.mockRepo.EXPECT().
+			ListOrdersCreatedBetween(gomock.Any(), from, to, from, uuid.Nil, reconcileBatchSize).
+			Return(orders, nil)
+	}
+
+	paymentDetails := func(status providers.PaymentStatus, providerStatus string, amount, refunded int64) *providers.PaymentDetails {
+		refundedAmount := decimal.NewFromInt(refunded)
+		return &providers.PaymentDetails{
+			ID:             paymentID,
+			Status:         status,
+			ProviderStatus: providerStatus,
+			Amount:         decimal.NewFromInt(amount),
+			CapturedAmount: decimal.NewFromInt(amount),
+			RefundedAmount: &refundedAmount,
+		}
+	}
+
+	t.Run("no mismatches", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := reconciledOrder(entities.Shipped)
+		expectOrders(tc, order)
+		tc.mockPayment.EXPECT().GetPaymentDetails(gomock.Any(), paymentID).
+			Return(paymentDetails(providers.PaymentStatusSuccess, "succeeded", 100, 0), nil)
+		tc.mockRepo.EXPECT().GetOrderItemsByID(gomock.Any(), order.ID).Return([]*entities.OrderItem{{ID: uuid.New()}}, nil)
+
+		report, err := s.ReconcileOrders(context.Background(), &entities.ReconcileOrdersRequest{From: from, To: to})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.OrdersChecked)
+		assert.Empty(t, report.Mismatches)
+	})
+
+	t.Run("reports total and status mismatches", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := reconciledOrder(entities.Shipped)
+		expectOrders(tc, order)
+		tc.mockPayment.EXPECT().GetPaymentDetails(gomock.Any(), paymentID).
+			Return(paymentDetails(providers.PaymentStatusFailed, "requires_payment_method", 90, 0), nil)
+		tc.mockRepo.EXPECT().GetOrderItemsByID(gomock.Any(), order.ID).Return(nil, nil)
+
+		report, err := s.ReconcileOrders(context.Background(), &entities.ReconcileOrdersRequest{From: from, To: to, Fix: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*entities.ReconciliationMismatch{
+			{OrderID: order.ID, OrderReference: order.OrderReference, Field: entities.MismatchTotal, OrderValue: "100.00", ProviderValue: "90.00"},
+			{OrderID: order.ID, OrderReference: order.OrderReference, Field: entities.MismatchStatus, OrderValue: "shipped", ProviderValue: "requires_payment_method"},
+		}, report.Mismatches)
+	})
+
+	t.Run("fixes pending order whose payment succeeded", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := reconciledOrder(entities.Pending)
+		expectOrders(tc, order)
+		tc.mockPayment.EXPECT().GetPaymentDetails(gomock.Any(), paymentID).
+			Return(paymentDetails(providers.PaymentStatusSuccess, "succeeded", 100, 0), nil)
+		tc.mockRepo.EXPECT().GetOrderItemsByID(gomock.Any(), order.ID).Return(nil, nil)
+
+		// settled the way the payment_intent.succeeded webhook does
+		tc.mockRepo.EXPECT().GetOrderByPaymentID(gomock.Any(), order.PaymentProvider, paymentID).Return(order, nil)
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _, _ string, _ entities.OrderEventSource, _ []*entities.OutboxMessage) {
+				assert.Equal(t, entities.PaymentSuccess, data["status"])
+			}).
+			Return(nil)
+		tc.mockCustomer.EXPECT().GetCustomerByID(gomock.Any(), order.CustomerID.String()).Return(nil, errors.New("customer not found"))
+
+		report, err := s.ReconcileOrders(context.Background(), &entities.ReconcileOrdersRequest{From: from, To: to, Fix: true})
+
+		assert.NoError(t, err)
+		assert.Len(t, report.Mismatches, 1)
+		assert.Equal(t, entities.MismatchStatus, report.Mismatches[0].Field)
+		assert.True(t, report.Mismatches[0].Fixable)
+		assert.False(t, report.Mismatches[0].Fixed)
+		assert.Equal(t, "customer not found", report.Mismatches[0].Error)
+	})
+
+	t.Run("reports fixable mismatches without fixing them", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := reconciledOrder(entities.PaymentSuccess)
+		expectOrders(tc, order)
+		tc.mockPayment.EXPECT().GetPaymentDetails(gomock.Any(), paymentID).
+			Return(paymentDetails(providers.PaymentStatusSuccess, "succeeded", 100, 40), nil)
+		tc.mockRepo.EXPECT().GetOrderItemsByID(gomock.Any(), order.ID).Return([]*entities.OrderItem{
+			{ID: uuid.New(), Status: entities.ItemInitiatedRefund, RefundID: "re_123"},
+			{ID: uuid.New(), Status: entities.ItemInitiatedRefund, RefundID: "re_123"},
+			{ID: uuid.New(), Status: entities.ItemProcessing},
+		}, nil)
+		tc.mockPayment.EXPECT().GetRefundDetails(gomock.Any(), "re_123").
+			Return(&providers.RefundDetails{ID: "re_123", Status: providers.RefundStatusSucceeded, Amount: decimal.NewFromInt(40)}, nil)
+
+		report, err := s.ReconcileOrders(context.Background(), &entities.ReconcileOrdersRequest{From: from, To: to})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*entities.ReconciliationMismatch{
+			{OrderID: order.ID, OrderReference: order.OrderReference, Field: entities.MismatchRefundStatus, RefundID: "re_123",
+				OrderValue: "initiated_refund", ProviderValue: "succeeded", Fixable: true},
+		}, report.Mismatches)
+	})
+
+	t.Run("reports refunds made at the provider", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := reconciledOrder(entities.Delivered)
+		expectOrders(tc, order)
+		tc.mockPayment.EXPECT().GetPaymentDetails(gomock.Any(), paymentID).
+			Return(paymentDetails(providers.PaymentStatusSuccess, "succeeded", 100, 25), nil)
+		tc.mockRepo.EXPECT().GetOrderItemsByID(gomock.Any(), order.ID).Return(nil, nil)
+
+		report, err := s.ReconcileOrders(context.Background(), &entities.ReconcileOrdersRequest{From: from, To: to, Fix: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*entities.ReconciliationMismatch{
+			{OrderID: order.ID, OrderReference: order.OrderReference, Field: entities.MismatchRefundTotal, OrderValue: "0.00", ProviderValue: "25.00"},
+		}, report.Mismatches)
+	})
+
+	t.Run("fixes refund total of refunded order", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := reconciledOrder(entities.Refunded)
+		refundTotal := decimal.NewFromInt(60)
+		order.RefundTotal = &refundTotal
+		expectOrders(tc, order)
+		tc.mockPayment.EXPECT().GetPaymentDetails(gomock.Any(), paymentID).
+			Return(paymentDetails(providers.PaymentStatusSuccess, "succeeded", 100, 100), nil)
+		tc.mockRepo.EXPECT().GetOrderItemsByID(gomock.Any(), order.ID).Return([]*entities.OrderItem{
+			{ID: uuid.New(), Status: entities.ItemRefunded, RefundID: "re_1"},
+			{ID: uuid.New(), Status: entities.ItemRefunded, RefundID: "re_2"},
+		}, nil)
+		tc.mockPayment.EXPECT().GetRefundDetails(gomock.Any(), "re_1").
+			Return(&providers.RefundDetails{ID: "re_1", Status: providers.RefundStatusSucceeded, Amount: decimal.NewFromInt(40)}, nil)
+		tc.mockPayment.EXPECT().GetRefundDetails(gomock.Any(), "re_2").
+			Return(&providers.RefundDetails{ID: "re_2", Status: providers.RefundStatusSucceeded, Amount: decimal.NewFromInt(60)}, nil)
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _, _ string, source entities.OrderEventSource, _ []*entities.OutboxMessage) {
+				assert.Equal(t, map[string]interface{}{"refund_total": decimal.NewFromInt(100)}, data)
+				assert.Equal(t, entities.ActorSystem, source.Actor)
+			}).
+			Return(nil)
+
+		report, err := s.ReconcileOrders(context.Background(), &entities.ReconcileOrdersRequest{From: from, To: to, Fix: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*entities.ReconciliationMismatch{
+			{OrderID: order.ID, OrderReference: order.OrderReference, Field: entities.MismatchRefundTotal,
+				OrderValue: "60.00", ProviderValue: "100.00", Fixable: true, Fixed: true},
+		}, report.Mismatches)
+	})
+
+	t.Run("reports payment missing at the provider", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := reconciledOrder(entities.PaymentSuccess)
+		expectOrders(tc, order)
+		tc.mockPayment.EXPECT().GetPaymentDetails(gomock.Any(), paymentID).Return(nil, errors.New("no such payment_intent"))
+
+		report, err := s.ReconcileOrders(context.Background(), &entities.ReconcileOrdersRequest{From: from, To: to})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*entities.ReconciliationMismatch{
+			{OrderID: order.ID, OrderReference: order.OrderReference, Field: entities.MismatchPayment, OrderValue: paymentID, Error: "no such payment_intent"},
+		}, report.Mismatches)
+	})
+
+	t.Run("pages through the orders", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		page := make([]*entities.Order, reconcileBatchSize)
+		for i := range page {
+			page[i] = reconciledOrder(entities.Cancelled)
+			page[i].PaymentID = nil
+		}
+		last := page[len(page)-1]
+
+		gomock.InOrder(
+			tc.mockRepo.EXPECT().ListOrdersCreatedBetween(gomock.Any(), from, to, from, uuid.Nil, reconcileBatchSize).Return(page, nil),
+			tc.mockRepo.EXPECT().ListOrdersCreatedBetween(gomock.Any(), from, to, last.CreatedAt, last.ID, reconcileBatchSize).Return(nil, nil),
+		)
+
+		report, err := s.ReconcileOrders(context.Background(), &entities.ReconcileOrdersRequest{From: from, To: to})
+
+		assert.NoError(t, err)
+		assert.Equal(t, reconcileBatchSize, report.OrdersChecked)
+		assert.Empty(t, report.Mismatches)
+	})
+
+	t.Run("error listing orders", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		tc.mockRepo.EXPECT().ListOrdersCreatedBetween(gomock.Any(), from, to, from, uuid.Nil, reconcileBatchSize).
+			Return(nil, errors.New("database error"))
+
+		report, err := s.ReconcileOrders(context.Background(), &entities.ReconcileOrdersRequest{From: from, To: to})
+
+		assert.EqualError(t, err, "database error")
+		assert.Nil(t, report)
+	})
+}
@@ -0,0 +1,449 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/orders/errors"
+	sharedErrors "github.com/nurdsoft/nurd-commerce-core/shared/errors"
+	sharedMeta "github.com/nurdsoft/nurd-commerce-core/shared/meta"
+)
+
+// returnReferencePrefix tells return references apart from order references.
+const returnReferencePrefix = "RMA-"
+
+// swagger:route POST /orders/{order_id}/returns orders CreateReturnRequest
+//
+// # Create Return
+// ### Request a return for items of a delivered order
+//
+// Produces:
+// - application/json
+//
+// Responses:
+//
+//	200: OrderReturn Return requested successfully
+//	400: DefaultError Bad Request
+//	404: DefaultError Order not found
+//	409: DefaultError Order status transition is not allowed
+//	500: DefaultError Internal Server Error
+func (s *service) CreateReturn(ctx context.Context, req *entities.CreateReturnRequest) (*entities.OrderReturn, error) {
+	customerID, err := uuid.Parse(sharedMeta.XCustomerID(ctx))
+	if err != nil {
+		return nil, moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, req.OrderID)
+	if err != nil {
+		return nil, moduleErrors.NewAPIError("ORDER_ERROR_GETTING")
+	}
+
+	if order.CustomerID != customerID {
+		return nil, moduleErrors.NewAPIError("ORDER_NOT_FOUND")
+	}
+
+	// returns are only possible once the order has been delivered
+	if order.Status != entities.Delivered && order.Status != entities.ReturnRequested && order.Status != entities.Returned {
+		return nil, moduleErrors.NewAPIError("RETURN_NOT_ALLOWED")
+	}
+
+	orderItems, err := s.repo.GetOrderItemsByID(ctx, order.ID)
+	if err != nil {
+		s.log.Errorf("Error fetching order items: %v", err)
+		return nil, moduleErrors.NewAPIError("ORDER_ERROR_GETTING_ITEMS")
+	}
+
+	existingReturns, err := s.repo.GetReturnsByOrderID(ctx, order.ID)
+	if err != nil {
+		s.log.Errorf("Error fetching order returns: %v", err)
+		return nil, moduleErrors.NewAPIError("RETURN_ERROR_GETTING")
+	}
+
+	// quantities already covered by other returns can't be returned again
+	returnedQuantities := make(map[uuid.UUID]int)
+	for _, existingReturn := range existingReturns {
+		if existingReturn.Status == entities.ReturnStatusRejected {
+			continue
+		}
+		for _, item := range existingReturn.Items {
+			returnedQuantities[item.OrderItemID] += item.Quantity
+		}
+	}
+
+	requestedQuantities := make(map[string]int)
+	for _, item := range req.Body.Items {
+		requestedQuantities[item.Sku] += item.Quantity
+	}
+
+	returnID := uuid.New()
+	returnItems := make([]*entities.OrderReturnItem, 0, len(requestedQuantities))
+	orderItemsData := make(map[string]interface{})
+	for sku, quantity := range requestedQuantities {
+		orderItem := findOrderItemBySKU(orderItems, sku)
+		if orderItem == nil {
+			return nil, moduleErrors.NewAPIError("RETURN_INVALID_ITEMS", fmt.Sprintf("Item %s is not part of the order", sku))
+		}
+
+		if !isReturnableItemStatus(orderItem.Status) {
+			return nil, moduleErrors.NewAPIError("RETURN_INVALID_ITEMS", fmt.Sprintf("Item %s can't be returned in status %s", sku, orderItem.Status))
+		}
+
+		if quantity+returnedQuantities[orderItem.ID] > orderItem.Quantity {
+			return nil, moduleErrors.NewAPIError("RETURN_INVALID_ITEMS", fmt.Sprintf("Return quantity for item %s exceeds the quantity left to return", sku))
+		}
+
+		returnItems = append(returnItems, &entities.OrderReturnItem{
+			ID:          uuid.New(),
+			ReturnID:    returnID,
+			OrderItemID: orderItem.ID,
+			SKU:         orderItem.SKU,
+			Quantity:    quantity,
+		})
+		orderItemsData[orderItem.ID.String()] = map[string]interface{}{
+			"status": entities.ItemReturnRequested.String(),
+		}
+	}
+
+	returnReference, err := s.generateReturnRef(ctx, returnID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	orderReturn := &entities.OrderReturn{
+		ID:              returnID,
+		OrderID:         order.ID,
+		CustomerID:      customerID,
+		ReturnReference: returnReference,
+		Status:          entities.ReturnStatusRequested,
+		Reason:          strings.TrimSpace(req.Body.Reason),
+		Items:           returnItems,
+	}
+
+	entries := []outboxEntry{notifyReturnStatusChange(order, orderReturn)}
+	orderData := make(map[string]interface{})
+	if order.Status != entities.ReturnRequested {
+		if err := validateStatusTransition(order.Status, entities.ReturnRequested); err != nil {
+			return nil, err
+		}
+		orderData["status"] = entities.ReturnRequested
+		entries = append(entries, notifyStatusChange(order, entities.ReturnRequested.String()))
+	}
+
+	outbox, err := newOutboxMessages(entries...)
+	if err != nil {
+		s.log.Errorf("Error preparing return side effects: %v", err)
+		return nil, moduleErrors.NewAPIError("RETURN_ERROR_CREATING")
+	}
+
+	err = s.repo.CreateReturn(ctx, orderReturn, orderData, orderItemsData,
+		eventSource(ctx, entities.ActorCustomer, fmt.Sprintf("Return %s requested", returnReference)), outbox)
+	if err != nil {
+		s.log.Errorf("Error creating return: %v", err)
+		return nil, moduleErrors.NewAPIError("RETURN_ERROR_CREATING")
+	}
+
+	return orderReturn, nil
+}
+
+// swagger:route GET /orders/{order_id}/returns orders ListReturnsRequest
+//
+// # List Returns
+// ### List the returns of an order, oldest first
+//
+// Produces:
+// - application/json
+//
+// Responses:
+//
+//	200: ListReturnsResponse Returns fetched successfully
+//	400: DefaultError Bad Request
+//	404: DefaultError Order not found
+//	500: DefaultError Internal Server Error
+func (s *service) ListReturns(ctx context.Context, req *entities.ListReturnsRequest) (*entities.ListReturnsResponse, error) {
+	customerID, err := uuid.Parse(sharedMeta.XCustomerID(ctx))
+	if err != nil {
+		return nil, moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, req.OrderID)
+	if err != nil {
+		return nil, moduleErrors.NewAPIError("ORDER_ERROR_GETTING")
+	}
+
+	if order.CustomerID != customerID {
+		return nil, moduleErrors.NewAPIError("ORDER_NOT_FOUND")
+	}
+
+	returns, err := s.repo.GetReturnsByOrderID(ctx, order.ID)
+	if err != nil {
+		s.log.Errorf("Error fetching order returns: %v", err)
+		return nil, moduleErrors.NewAPIError("RETURN_ERROR_GETTING")
+	}
+
+	return &entities.ListReturnsResponse{
+		Returns: returns,
+	}, nil
+}
+
+// swagger:route PUT /returns/{return_reference} orders UpdateReturnRequest
+//
+// # Update Return
+// ### Approve, reject or mark the goods of a return as received
+//
+// Produces:
+// - application/json
+//
+// Responses:
+//
+//	200: OrderReturn Return updated successfully
+//	400: DefaultError Bad Request
+//	404: DefaultError Return not found
+//	409: DefaultError Return status transition is not allowed
+//	500: DefaultError Internal Server Error
+func (s *service) UpdateReturn(ctx context.Context, req *entities.UpdateReturnRequest) (*entities.OrderReturn, error) {
+	newStatus := req.Body.Status
+	// refunds go through RefundReturn so the payment provider is involved
+	if newStatus != entities.ReturnStatusApproved && newStatus != entities.ReturnStatusRejected && newStatus != entities.ReturnStatusReceived {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "status must be one of approved, rejected or received")
+	}
+
+	orderReturn, order, err := s.getReturnWithOrder(ctx, req.ReturnReference)
+	if err != nil {
+		return nil, err
+	}
+
+	if !orderReturn.Status.CanTransitionTo(newStatus) {
+		return nil, moduleErrors.NewAPIError("RETURN_INVALID_STATUS_TRANSITION",
+			fmt.Sprintf("Return cannot transition from %s to %s.", orderReturn.Status, newStatus))
+	}
+
+	fromStatus := orderReturn.Status
+	orderReturn.Status = newStatus
+	returnData := map[string]interface{}{"status": newStatus}
+	if req.Body.Note != nil {
+		orderReturn.Note = req.Body.Note
+		returnData["note"] = *req.Body.Note
+	}
+
+	// the goods of a rejected return stay with the customer
+	var itemStatus entities.OrderItemStatus
+	switch newStatus {
+	case entities.ReturnStatusRejected:
+		itemStatus = entities.ItemDelivered
+	case entities.ReturnStatusReceived:
+		itemStatus = entities.ItemReturned
+	}
+
+	orderItemsData := make(map[string]interface{})
+	entries := []outboxEntry{notifyReturnStatusChange(order, orderReturn)}
+	orderData := make(map[string]interface{})
+	if itemStatus != "" {
+		returns, err := s.repo.GetReturnsByOrderID(ctx, order.ID)
+		if err != nil {
+			s.log.Errorf("Error fetching order returns: %v", err)
+			return nil, moduleErrors.NewAPIError("RETURN_ERROR_GETTING")
+		}
+
+		// items that are part of another return keep the status that return gave them
+		inOtherReturn := make(map[uuid.UUID]bool)
+		for _, other := range returns {
+			if other.ID != orderReturn.ID && other.Status != entities.ReturnStatusRejected {
+				for _, item := range other.Items {
+					inOtherReturn[item.OrderItemID] = true
+				}
+			}
+		}
+
+		for _, item := range orderReturn.Items {
+			if itemStatus == entities.ItemDelivered && inOtherReturn[item.OrderItemID] {
+				continue
+			}
+			orderItemsData[item.OrderItemID.String()] = map[string]interface{}{
+				"status": itemStatus.String(),
+			}
+		}
+
+		if orderStatus := orderStatusAfterReturn(order, returns, orderReturn); orderStatus != order.Status {
+			orderData["status"] = orderStatus
+			entries = append(entries, notifyStatusChange(order, orderStatus.String()))
+		}
+	}
+
+	outbox, err := newOutboxMessages(entries...)
+	if err != nil {
+		s.log.Errorf("Error preparing return side effects: %v", err)
+		return nil, moduleErrors.NewAPIError("RETURN_ERROR_UPDATING")
+	}
+
+	err = s.repo.UpdateReturn(ctx, orderReturn, fromStatus, returnData, orderData, orderItemsData,
+		eventSource(ctx, entities.ActorAdmin, fmt.Sprintf("Return %s %s", orderReturn.ReturnReference, newStatus)), outbox)
+	if err != nil {
+		s.log.Errorf("Error updating return %s: %v", orderReturn.ReturnReference, err)
+		// a concurrent update already moved the return
+		var apiErr *sharedErrors.APIError
+		if errors.As(err, &apiErr) {
+			return nil, err
+		}
+		return nil, moduleErrors.NewAPIError("RETURN_ERROR_UPDATING")
+	}
+
+	return orderReturn, nil
+}
+
+// swagger:route POST /returns/{return_reference}/refund orders RefundReturnRequest
+//
+// # Refund Return
+// ### Refund the items of a received return through the order refund
+//
+// Produces:
+// - application/json
+//
+// Responses:
+//
+//	200: RefundReturnResponse Return refund initiated successfully
+//	400: DefaultError Bad Request
+//	404: DefaultError Return not found
+//	409: DefaultError Return status transition is not allowed
+//	500: DefaultError Internal Server Error
+func (s *service) RefundReturn(ctx context.Context, req *entities.RefundReturnRequest) (*entities.RefundReturnResponse, error) {
+	orderReturn, order, err := s.getReturnWithOrder(ctx, req.ReturnReference)
+	if err != nil {
+		return nil, err
+	}
+
+	if !orderReturn.Status.CanTransitionTo(entities.ReturnStatusRefunded) {
+		return nil, moduleErrors.NewAPIError("RETURN_INVALID_STATUS_TRANSITION",
+			fmt.Sprintf("Return cannot transition from %s to %s.", orderReturn.Status, entities.ReturnStatusRefunded))
+	}
+
+	refundItems := make([]*entities.RefundItem, 0, len(orderReturn.Items))
+	for _, item := range orderReturn.Items {
+		refundItems = append(refundItems, &entities.RefundItem{Sku: item.SKU, Quantity: item.Quantity})
+	}
+
+	// the provider key is tied to the return so a retried call can't refund it twice
+	refund, err := s.refundOrder(ctx, &entities.RefundOrderRequest{
+		OrderReference: order.OrderReference,
+		Body: &entities.RefundOrderRequestBody{
+			Items:           refundItems,
+			IncludeShipping: req.Body.IncludeShipping,
+		},
+	}, providerIdempotencyKey(idempotencyScopeRefundReturn, orderReturn.ID.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	fromStatus := orderReturn.Status
+	orderReturn.Status = entities.ReturnStatusRefunded
+	orderReturn.RefundAmount = &refund.TotalRefundableAmount
+
+	outbox, err := newOutboxMessages(notifyReturnStatusChange(order, orderReturn))
+	if err != nil {
+		s.log.Errorf("Error preparing return side effects: %v", err)
+	}
+
+	err = s.repo.UpdateReturn(ctx, orderReturn, fromStatus, map[string]interface{}{
+		"status":        entities.ReturnStatusRefunded,
+		"refund_amount": refund.TotalRefundableAmount,
+	}, nil, nil, eventSource(ctx, entities.ActorAdmin, fmt.Sprintf("Return %s refunded", orderReturn.ReturnReference)), outbox)
+	if err != nil {
+		// the refund went through, only the return bookkeeping is missing
+		s.log.Errorf("Error marking return %s as refunded: %v", orderReturn.ReturnReference, err)
+		return nil, moduleErrors.NewAPIError("RETURN_ERROR_UPDATING")
+	}
+
+	return &entities.RefundReturnResponse{
+		Return: orderReturn,
+		Refund: refund,
+	}, nil
+}
+
+func (s *service) getReturnWithOrder(ctx context.Context, returnReference string) (*entities.OrderReturn, *entities.Order, error) {
+	orderReturn, err := s.repo.GetReturnByReference(ctx, returnReference)
+	if err != nil {
+		s.log.Errorf("Error fetching return %s: %v", returnReference, err)
+		return nil, nil, moduleErrors.NewAPIError("RETURN_NOT_FOUND")
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, orderReturn.OrderID)
+	if err != nil {
+		s.log.Errorf("Error fetching order of return %s: %v", returnReference, err)
+		return nil, nil, moduleErrors.NewAPIError("ORDER_ERROR_GETTING")
+	}
+
+	return orderReturn, order, nil
+}
+
+// orderStatusAfterReturn returns the status of the order once the given return is closed.
+// The order stays in return_requested while other returns are still open, afterwards it is
+// returned when goods came back with any of its returns and delivered otherwise.
+func orderStatusAfterReturn(order *entities.Order, returns []*entities.OrderReturn, closed *entities.OrderReturn) entities.OrderStatus {
+	if order.Status != entities.ReturnRequested {
+		return order.Status
+	}
+
+	goodsReturned := closed.Status == entities.ReturnStatusReceived
+	for _, orderReturn := range returns {
+		if orderReturn.ID == closed.ID {
+			continue
+		}
+		if orderReturn.Status.IsOpen() {
+			return order.Status
+		}
+		if orderReturn.Status == entities.ReturnStatusReceived || orderReturn.Status == entities.ReturnStatusRefunded {
+			goodsReturned = true
+		}
+	}
+
+	if goodsReturned {
+		return entities.Returned
+	}
+
+	return entities.Delivered
+}
+
+// isReturnableItemStatus reports whether an order item in this status can be sent back.
+// Items without a status predate item level statuses and follow the order status.
+func isReturnableItemStatus(status entities.OrderItemStatus) bool {
+	switch status {
+	case "", entities.ItemDelivered, entities.ItemReturnRequested, entities.ItemReturned:
+		return true
+	}
+
+	return false
+}
+
+func findOrderItemBySKU(orderItems []*entities.OrderItem, sku string) *entities.OrderItem {
+	for _, orderItem := range orderItems {
+		if orderItem.SKU == sku {
+			return orderItem
+		}
+	}
+
+	return nil
+}
+
+// generateReturnRef generates a unique return reference based on the return ID.
+func (s *service) generateReturnRef(ctx context.Context, returnID string) (string, error) {
+	for {
+		ref := returnReferencePrefix + generateAlphanumericOrderRef(returnID)
+
+		exists, err := s.repo.ReturnReferenceExists(ctx, ref)
+		if err != nil {
+			s.log.Errorf("Error checking return reference existence: %v", err)
+			return "", moduleErrors.NewAPIError("RETURN_ERROR_CREATING")
+		}
+
+		if !exists {
+			return ref, nil
+		}
+
+		// Append randomness to returnID to alter the hash in case of collision
+		returnID += fmt.Sprintf("%d", rand.IntN(99999))
+	}
+}
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
+	sharedMeta "github.com/nurdsoft/nurd-commerce-core/shared/meta"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateReturn(t *testing.T) {
+	customerID := uuid.New()
+	orderID := uuid.New()
+	orderItemID := uuid.New()
+	ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
+
+	newOrder := func(status entities.OrderStatus) *entities.Order {
+		return &entities.Order{ID: orderID, CustomerID: customerID, OrderReference: "ORD123456", Status: status}
+	}
+	orderItems := []*entities.OrderItem{
+		{ID: orderItemID, OrderID: orderID, SKU: "SKU123", Price: decimal.NewFromInt(50), Quantity: 2, Status: entities.ItemDelivered},
+	}
+	newRequest := func(quantity int) *entities.CreateReturnRequest {
+		return &entities.CreateReturnRequest{
+			OrderID: orderID,
+			Body: &entities.CreateReturnRequestBody{
+				Reason: "Wrong size",
+				Items:  []*entities.ReturnItem{{Sku: "SKU123", Quantity: quantity}},
+			},
+		}
+	}
+
+	t.Run("opens a return on a delivered order", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(newOrder(entities.Delivered), nil)
+		tc.mockRepo.EXPECT().GetOrderItemsByID(gomock.Any(), orderID).Return(orderItems, nil)
+		tc.mockRepo.EXPECT().GetReturnsByOrderID(gomock.Any(), orderID).Return(nil, nil)
+		tc.mockRepo.EXPECT().ReturnReferenceExists(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		tc.mockRepo.EXPECT().
+			CreateReturn(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, orderReturn *entities.OrderReturn, orderData map[string]interface{}, itemsData map[string]interface{}, source entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, entities.ReturnStatusRequested, orderReturn.Status)
+				assert.Equal(t, "Wrong size", orderReturn.Reason)
+				assert.Len(t, orderReturn.Items, 1)
+				assert.Equal(t, orderItemID, orderReturn.Items[0].OrderItemID)
+				assert.Equal(t, 1, orderReturn.Items[0].Quantity)
+				assert.Equal(t, entities.ReturnRequested, orderData["status"])
+				assert.Equal(t, entities.ItemReturnRequested.String(), itemsData[orderItemID.String()].(map[string]interface{})["status"])
+				assert.Equal(t, entities.ActorCustomer, source.Actor)
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicReturnStatusChanged, entities.TopicOrderStatusChanged}, outboxTopics(outbox))
+			}).
+			Return(nil)
+
+		orderReturn, err := s.CreateReturn(ctx, newRequest(1))
+
+		assert.NoError(t, err)
+		assert.Contains(t, orderReturn.ReturnReference, returnReferencePrefix)
+	})
+
+	t.Run("order not delivered yet", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(newOrder(entities.Shipped), nil)
+
+		orderReturn, err := s.CreateReturn(ctx, newRequest(1))
+
+		assert.Nil(t, orderReturn)
+		assert.ErrorContains(t, err, "Order is not eligible for a return.")
+	})
+
+	t.Run("order of another customer", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := newOrder(entities.Delivered)
+		order.CustomerID = uuid.New()
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(order, nil)
+
+		_, err := s.CreateReturn(ctx, newRequest(1))
+
+		assert.ErrorContains(t, err, "Order not found.")
+	})
+
+	t.Run("quantity already covered by another return", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(newOrder(entities.ReturnRequested), nil)
+		tc.mockRepo.EXPECT().GetOrderItemsByID(gomock.Any(), orderID).Return(orderItems, nil)
+		tc.mockRepo.EXPECT().GetReturnsByOrderID(gomock.Any(), orderID).Return([]*entities.OrderReturn{
+			{ID: uuid.New(), Status: entities.ReturnStatusApproved, Items: []*entities.OrderReturnItem{{OrderItemID: orderItemID, Quantity: 1}}},
+			// rejected returns don't count
+			{ID: uuid.New(), Status: entities.ReturnStatusRejected, Items: []*entities.OrderReturnItem{{OrderItemID: orderItemID, Quantity: 2}}},
+		}, nil)
+
+		_, err := s.CreateReturn(ctx, newRequest(2))
+
+		assert.ErrorContains(t, err, "exceeds the quantity left to return")
+	})
+
+	t.Run("unknown sku", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(newOrder(entities.Delivered), nil)
+		tc.mockRepo.EXPECT().GetOrderItemsByID(gomock.Any(), orderID).Return(orderItems, nil)
+		tc.mockRepo.EXPECT().GetReturnsByOrderID(gomock.Any(), orderID).Return(nil, nil)
+
+		req := newRequest(1)
+		req.Body.Items[0].Sku = "UNKNOWN"
+		_, err := s.CreateReturn(ctx, req)
+
+		assert.ErrorContains(t, err, "Item UNKNOWN is not part of the order")
+	})
+}
+
+func TestUpdateReturn(t *testing.T) {
+	orderID := uuid.New()
+	orderItemID := uuid.New()
+	returnID := uuid.New()
+	returnRef := "RMA-ABC123"
+
+	newReturn := func(status entities.ReturnStatus) *entities.OrderReturn {
+		return &entities.OrderReturn{
+			ID:              returnID,
+			OrderID:         orderID,
+			ReturnReference: returnRef,
+			Status:          status,
+			Items:           []*entities.OrderReturnItem{{OrderItemID: orderItemID, SKU: "SKU123", Quantity: 1}},
+		}
+	}
+	order := &entities.Order{ID: orderID, OrderReference: "ORD123456", Status: entities.ReturnRequested}
+
+	t.Run("approve", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		tc.mockRepo.EXPECT().GetReturnByReference(gomock.Any(), returnRef).Return(newReturn(entities.ReturnStatusRequested), nil)
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(order, nil)
+		tc.mockRepo.EXPECT().
+			UpdateReturn(gomock.Any(), gomock.Any(), entities.ReturnStatusRequested, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *entities.OrderReturn, _ entities.ReturnStatus, returnData map[string]interface{}, orderData map[string]interface{}, itemsData map[string]interface{}, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, entities.ReturnStatusApproved, returnData["status"])
+				assert.Empty(t, orderData)
+				assert.Empty(t, itemsData)
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicReturnStatusChanged}, outboxTopics(outbox))
+			}).
+			Return(nil)
+
+		orderReturn, err := s.UpdateReturn(context.Background(), &entities.UpdateReturnRequest{
+			ReturnReference: returnRef,
+			Body:            &entities.UpdateReturnRequestBody{Status: entities.ReturnStatusApproved},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.ReturnStatusApproved, orderReturn.Status)
+	})
+
+	t.Run("receive marks items and order as returned", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		tc.mockRepo.EXPECT().GetReturnByReference(gomock.Any(), returnRef).Return(newReturn(entities.ReturnStatusApproved), nil)
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(order, nil)
+		tc.mockRepo.EXPECT().GetReturnsByOrderID(gomock.Any(), orderID).Return([]*entities.OrderReturn{newReturn(entities.ReturnStatusApproved)}, nil)
+		tc.mockRepo.EXPECT().
+			UpdateReturn(gomock.Any(), gomock.Any(), entities.ReturnStatusApproved, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *entities.OrderReturn, _ entities.ReturnStatus, _ map[string]interface{}, orderData map[string]interface{}, itemsData map[string]interface{}, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, entities.Returned, orderData["status"])
+				assert.Equal(t, entities.ItemReturned.String(), itemsData[orderItemID.String()].(map[string]interface{})["status"])
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicReturnStatusChanged, entities.TopicOrderStatusChanged}, outboxTopics(outbox))
+			}).
+			Return(nil)
+
+		_, err := s.UpdateReturn(context.Background(), &entities.UpdateReturnRequest{
+			ReturnReference: returnRef,
+			Body:            &entities.UpdateReturnRequestBody{Status: entities.ReturnStatusReceived},
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("reject keeps order open while other returns are in progress", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		note := "Outside of the return window"
+		other := &entities.OrderReturn{ID: uuid.New(), Status: entities.ReturnStatusRequested}
+
+		tc.mockRepo.EXPECT().GetReturnByReference(gomock.Any(), returnRef).Return(newReturn(entities.ReturnStatusRequested), nil)
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(order, nil)
+		tc.mockRepo.EXPECT().GetReturnsByOrderID(gomock.Any(), orderID).Return([]*entities.OrderReturn{newReturn(entities.ReturnStatusRequested), other}, nil)
+		tc.mockRepo.EXPECT().
+			UpdateReturn(gomock.Any(), gomock.Any(), entities.ReturnStatusRequested, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *entities.OrderReturn, _ entities.ReturnStatus, returnData map[string]interface{}, orderData map[string]interface{}, itemsData map[string]interface{}, _ entities.OrderEventSource, _ []*entities.OutboxMessage) {
+				assert.Equal(t, note, returnData["note"])
+				assert.Empty(t, orderData)
+				assert.Equal(t, entities.ItemDelivered.String(), itemsData[orderItemID.String()].(map[string]interface{})["status"])
+			}).
+			Return(nil)
+
+		_, err := s.UpdateReturn(context.Background(), &entities.UpdateReturnRequest{
+			ReturnReference: returnRef,
+			Body:            &entities.UpdateReturnRequestBody{Status: entities.ReturnStatusRejected, Note: &note},
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid transition", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		tc.mockRepo.EXPECT().GetReturnByReference(gomock.Any(), returnRef).Return(newReturn(entities.ReturnStatusRequested), nil)
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(order, nil)
+
+		_, err := s.UpdateReturn(context.Background(), &entities.UpdateReturnRequest{
+			ReturnReference: returnRef,
+			Body:            &entities.UpdateReturnRequestBody{Status: entities.ReturnStatusReceived},
+		})
+
+		assert.ErrorContains(t, err, "Return cannot transition from requested to received.")
+	})
+
+	t.Run("refunded status is set by the refund", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		_, err := s.UpdateReturn(context.Background(), &entities.UpdateReturnRequest{
+			ReturnReference: returnRef,
+			Body:            &entities.UpdateReturnRequestBody{Status: entities.ReturnStatusRefunded},
+		})
+
+		assert.ErrorContains(t, err, "status must be one of approved, rejected or received")
+	})
+}
+
+func TestRefundReturn(t *testing.T) {
+	orderID := uuid.New()
+	orderItemID := uuid.New()
+	returnID := uuid.New()
+	returnRef := "RMA-ABC123"
+	paymentIntentID := "pi_123"
+
+	orderReturn := &entities.OrderReturn{
+		ID:              returnID,
+		OrderID:         orderID,
+		ReturnReference: returnRef,
+		Status:          entities.ReturnStatusReceived,
+		Items:           []*entities.OrderReturnItem{{OrderItemID: orderItemID, SKU: "SKU123", Quantity: 1}},
+	}
+	order := &entities.Order{
+		ID:              orderID,
+		OrderReference:  "ORD123456",
+		Status:          entities.Returned,
+		Total:           decimal.NewFromInt(100),
+		PaymentProvider: providers.ProviderStripe,
+		PaymentID:       &paymentIntentID,
+	}
+	orderItems := []*entities.OrderItem{
+		{ID: orderItemID, OrderID: orderID, SKU: "SKU123", Price: decimal.NewFromInt(50), Quantity: 2, Status: entities.ItemReturned},
+	}
+
+	t.Run("refunds the returned items", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		tc.mockRepo.EXPECT().GetReturnByReference(gomock.Any(), returnRef).Return(orderReturn, nil)
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(order, nil)
+		tc.mockRepo.EXPECT().GetOrderByReference(gomock.Any(), order.OrderReference).Return(order, nil)
+		tc.mockRepo.EXPECT().GetOrderItemsByID(gomock.Any(), orderID).Return(orderItems, nil)
+
+		tc.mockPayment.EXPECT().
+			Refund(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, req providers.RefundRequest) {
+				assert.True(t, decimal.NewFromInt(50).Equal(req.Amount))
+				assert.Equal(t, providerIdempotencyKey(idempotencyScopeRefundReturn, returnID.String()), req.IdempotencyKey)
+			}).
+			Return(&providers.RefundResponse{ID: "re_123", Status: providers.RefundStatusSucceeded}, nil)
+
+		tc.mockRepo.EXPECT().
+			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		tc.mockRepo.EXPECT().
+			UpdateReturn(gomock.Any(), gomock.Any(), entities.ReturnStatusReceived, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *entities.OrderReturn, _ entities.ReturnStatus, returnData map[string]interface{}, _ map[string]interface{}, _ map[string]interface{}, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, entities.ReturnStatusRefunded, returnData["status"])
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicReturnStatusChanged}, outboxTopics(outbox))
+			}).
+			Return(nil)
+
+		resp, err := s.RefundReturn(context.Background(), &entities.RefundReturnRequest{
+			ReturnReference: returnRef,
+			Body:            &entities.RefundReturnRequestBody{},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.ReturnStatusRefunded, resp.Return.Status)
+		assert.True(t, decimal.NewFromInt(50).Equal(resp.Refund.TotalRefundableAmount))
+	})
+
+	t.Run("return not received yet", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		approved := *orderReturn
+		approved.Status = entities.ReturnStatusApproved
+		tc.mockRepo.EXPECT().GetReturnByReference(gomock.Any(), returnRef).Return(&approved, nil)
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(order, nil)
+
+		_, err := s.RefundReturn(context.Background(), &entities.RefundReturnRequest{
+			ReturnReference: returnRef,
+			Body:            &entities.RefundReturnRequestBody{},
+		})
+
+		assert.ErrorContains(t, err, "Return cannot transition from approved to refunded.")
+	})
+}
+
+func TestOrderStatusAfterReturn(t *testing.T) {
+	order := &entities.Order{Status: entities.ReturnRequested}
+	closed := &entities.OrderReturn{ID: uuid.New(), Status: entities.ReturnStatusRejected}
+
+	assert.Equal(t, entities.Delivered, orderStatusAfterReturn(order, []*entities.OrderReturn{closed}, closed))
+	assert.Equal(t, entities.Returned, orderStatusAfterReturn(order, []*entities.OrderReturn{
+		closed, {ID: uuid.New(), Status: entities.ReturnStatusRefunded},
+	}, closed))
+	assert.Equal(t, entities.ReturnRequested, orderStatusAfterReturn(order, []*entities.OrderReturn{
+		closed, {ID: uuid.New(), Status: entities.ReturnStatusApproved},
+	}, closed))
+}
@@ -13,25 +13,22 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/nurdsoft/nurd-commerce-core/internal/address/addressclient"
-	addressEntities "github.com/nurdsoft/nurd-commerce-core/internal/address/entities"
 	"github.com/nurdsoft/nurd-commerce-core/internal/cart/cartclient"
 	"github.com/nurdsoft/nurd-commerce-core/internal/customer/customerclient"
+	customerEntities "github.com/nurdsoft/nurd-commerce-core/internal/customer/entities"
 	"github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
 	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/orders/errors"
 	"github.com/nurdsoft/nurd-commerce-core/internal/orders/repository"
 	"github.com/nurdsoft/nurd-commerce-core/internal/product/productclient"
 	webhook "github.com/nurdsoft/nurd-commerce-core/internal/webhook/client"
-	webhookEntities "github.com/nurdsoft/nurd-commerce-core/internal/webhook/entities"
 	wishlistentities "github.com/nurdsoft/nurd-commerce-core/internal/wishlist/entities"
 	"github.com/nurdsoft/nurd-commerce-core/internal/wishlist/wishlistclient"
 	"github.com/nurdsoft/nurd-commerce-core/shared/cfg"
+	sharedErrors "github.com/nurdsoft/nurd-commerce-core/shared/errors"
 	sharedMeta "github.com/nurdsoft/nurd-commerce-core/shared/meta"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory"
-	inventoryEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory/entities"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment"
-	authorizenetEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/authorizenet/entities"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
-	stripeEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe/entities"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
@@ -40,12 +37,23 @@ type Service interface {
 	CreateOrder(ctx context.Context, req *entities.CreateOrderRequest) (*entities.CreateOrderResponse, error)
 	ListOrders(ctx context.Context, req *entities.ListOrdersRequest) (*entities.ListOrdersResponse, error)
 	GetOrder(ctx context.Context, req *entities.GetOrderRequest) (*entities.GetOrderData, error)
+	GetOrderHistory(ctx context.Context, req *entities.GetOrderHistoryRequest) (*entities.GetOrderHistoryResponse, error)
 	CancelOrder(ctx context.Context, req *entities.CancelOrderRequest) error
-	ProcessPaymentSucceeded(ctx context.Context, paymentID string) error
-	ProcessPaymentFailed(ctx context.Context, paymentID string) error
+	ProcessPaymentSucceeded(ctx context.Context, provider providers.ProviderType, paymentID string) error
+	ProcessPaymentFailed(ctx context.Context, provider providers.ProviderType, paymentID string) error
 	UpdateOrder(ctx context.Context, req *entities.UpdateOrderRequest) error
 	RefundOrder(ctx context.Context, req *entities.RefundOrderRequest) (*entities.RefundOrderResponse, error)
 	ProcessRefundSucceeded(ctx context.Context, refundId string, refundAmount decimal.Decimal) error
+	ProcessRefundFailed(ctx context.Context, refundID string, reason string) error
+	ProcessPaymentDisputed(ctx context.Context, dispute *entities.PaymentDispute) error
+	DispatchOutbox(ctx context.Context, limit int) (int, error)
+	OutboxDepth(ctx context.Context) (int64, error)
+	ExpireStaleOrders(ctx context.Context, ttl time.Duration, limit int) (int, error)
+	ReconcileOrders(ctx context.Context, req *entities.ReconcileOrdersRequest) (*entities.ReconciliationReport, error)
+	CreateReturn(ctx context.Context, req *entities.CreateReturnRequest) (*entities.OrderReturn, error)
+	ListReturns(ctx context.Context, req *entities.ListReturnsRequest) (*entities.ListReturnsResponse, error)
+	UpdateReturn(ctx context.Context, req *entities.UpdateReturnRequest) (*entities.OrderReturn, error)
+	RefundReturn(ctx context.Context, req *entities.RefundReturnRequest) (*entities.RefundReturnResponse, error)
 }
 
 type service struct {
@@ -53,34 +61,41 @@ type service struct {
 	log             *zap.SugaredLogger
 	customerClient  customerclient.Client
 	cartClient      cartclient.Client
-	paymentClient   payment.Client
+	payments        payment.Registry
 	wishlistClient  wishlistclient.Client
 	inventoryClient inventory.Client
 	addressClient   addressclient.Client
 	productClient   productclient.Client
 	webhookClient   webhook.Client
 	config          cfg.Config
+	captureMode     providers.CaptureMode
 }
 
 func New(
 	repo repository.Repository, log *zap.SugaredLogger, customerClient customerclient.Client,
-	cartClient cartclient.Client, paymentClient payment.Client,
+	cartClient cartclient.Client, payments payment.Registry,
 	wishlistClient wishlistclient.Client, config cfg.Config,
 	inventoryClient inventory.Client, addressClient addressclient.Client, productClient productclient.Client,
-	webhookClient webhook.Client,
+	webhookClient webhook.Client, paymentConfig payment.Config,
 ) Service {
+	captureMode := paymentConfig.CaptureMode
+	if captureMode == "" {
+		captureMode = providers.CaptureModeAutomatic
+	}
+
 	return &service{
 		repo:            repo,
 		log:             log,
 		customerClient:  customerClient,
 		cartClient:      cartClient,
-		paymentClient:   paymentClient,
+		payments:        payments,
 		wishlistClient:  wishlistClient,
 		inventoryClient: inventoryClient,
 		addressClient:   addressClient,
 		productClient:   productClient,
 		webhookClient:   webhookClient,
 		config:          config,
+		captureMode:     captureMode,
 	}
 }
 
@@ -96,45 +111,55 @@ func New(
 //
 //	200: CreateOrderResponse Order created successfully
 //	400: DefaultError Bad Request
+//	409: DefaultError Idempotency key conflict, prices of the cart items changed, or a promotion reached its usage limit
 //	500: DefaultError Internal Server Error
 func (s *service) CreateOrder(ctx context.Context, req *entities.CreateOrderRequest) (*entities.CreateOrderResponse, error) {
-	customerID, err := uuid.Parse(sharedMeta.XCustomerID(ctx))
-	if err != nil {
-		return nil, moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	// keys are scoped per customer so that two customers can't replay each other's orders, guests per cart token
+	owner := sharedMeta.XCustomerID(ctx)
+	if owner == "" {
+		owner = sharedMeta.XCartToken(ctx)
 	}
+	scope := idempotencyScopeCreateOrder + ":" + owner
 
-	address, err := s.addressClient.GetAddress(ctx, &addressEntities.GetAddressRequest{
-		AddressID: req.Body.AddressID,
+	return idempotent(ctx, s, scope, req.IdempotencyKey, req.Body, func() (*entities.CreateOrderResponse, error) {
+		return s.createOrder(ctx, req, providerIdempotencyKey(scope, req.IdempotencyKey))
 	})
-	if err != nil {
-		return nil, err
+}
+
+func (s *service) createOrder(ctx context.Context, req *entities.CreateOrderRequest, paymentIdempotencyKey string) (*entities.CreateOrderResponse, error) {
+	guest := isGuestCheckout(ctx)
+
+	var customerID uuid.UUID
+	if guest {
+		if err := validateGuestCheckout(req.Body); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		customerID, err = uuid.Parse(sharedMeta.XCustomerID(ctx))
+		if err != nil {
+			return nil, moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+		}
 	}
 
-	// get user's active cart via cartclient
-	cart, err := s.cartClient.GetCart(ctx)
+	address, err := s.deliveryAddress(ctx, req.Body, guest)
 	if err != nil {
 		return nil, err
 	}
 
-	// get cart items via cartclient
-	cartItems, err := s.cartClient.GetCartItems(ctx)
+	// the order is charged the total of the checkout summary
+	summary, err := s.cartClient.GetCartSummary(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate total shipping amount from cart items that have shipping rates
-	totalShippingAmount := decimal.Zero
-
 	var shippingRate *cartEntities.CartShippingRate
-	// map of shipping rate id to amount so we can properly sum all different shipping rates
-	shippingRatesValuesByID := make(map[uuid.UUID]decimal.Decimal)
 
 	orderItems := []*entities.OrderItem{}
+	cartItems := make([]cartEntities.CartItemDetail, 0, len(summary.Items))
 	orderId := uuid.New()
 
-	var subTotal decimal.Decimal
-
-	for _, item := range cartItems.Items {
+	for _, item := range summary.Items {
 		orderItem := &entities.OrderItem{
 			ID:               uuid.New(),
 			OrderID:          orderId,
@@ -154,21 +179,20 @@ func (s *service) CreateOrder(ctx context.Context, req *entities.CreateOrderRequ
 			Status:           entities.ItemPending,
 		}
 
+		if item.Discount.IsPositive() {
+			discount := item.Discount
+			orderItem.DiscountAmount = &discount
+		}
+
 		// Map shipping rate information from cart item to order item
-		if item.ShippingRateID != nil {
-			shippingRate, err = s.cartClient.GetShippingRateByID(ctx, *item.ShippingRateID)
-			if err != nil {
-				s.log.Errorf("Error retrieving shipping rate %s: %v", item.ShippingRateID.String(), err)
-				return nil, moduleErrors.NewAPIError("ORDER_ERROR_CREATING")
-			}
+		if item.ShippingRate != nil {
+			shippingRate = item.ShippingRate
 
 			// if shipping rate id is provided, it should match the shipping rate id for the all cart items
 			if req.Body.ShippingRateID != nil && *req.Body.ShippingRateID != shippingRate.Id {
 				return nil, moduleErrors.NewAPIError("ORDER_ERROR_CREATING")
 			}
 
-			shippingRatesValuesByID[shippingRate.Id] = shippingRate.Amount
-
 			// Set shipping information on order item
 			orderItem.ShippingRateID = &shippingRate.Id
 			orderItem.ShippingRate = &shippingRate.Amount
@@ -181,30 +205,42 @@ func (s *service) CreateOrder(ctx context.Context, req *entities.CreateOrderRequ
 		}
 
 		orderItems = append(orderItems, orderItem)
-		subTotal = subTotal.Add(item.Price.Mul(decimal.NewFromInt(int64(item.Quantity))))
+		cartItems = append(cartItems, item.CartItemDetail)
 	}
 
-	for _, amount := range shippingRatesValuesByID {
-		totalShippingAmount = totalShippingAmount.Add(amount)
-	}
+	total := summary.Total
+	totalShippingAmount := summary.ShippingAmount
+
+	// remember each item's share of the tax and shipping for partial refunds
+	allocateOrderAmounts(orderItems, summary.Tax, summary.TaxBreakdown)
+	allocateShippingDiscount(orderItems, summary.ShippingDiscount)
 
-	total := cart.TaxAmount.Add(subTotal).Add(totalShippingAmount)
+	customer, err := s.orderCustomer(ctx, req.Body, guest, address)
+	if err != nil {
+		return nil, err
+	}
+	if guest {
+		customerID = customer.ID
+	}
 
-	customer, err := s.customerClient.GetCustomer(ctx)
+	// the order keeps track of the provider it's paid with for refunds and captures
+	paymentClient, err := s.selectPaymentClient(req.Body, customer)
 	if err != nil {
 		return nil, err
 	}
+	paymentProvider := paymentClient.GetProvider()
 
 	paymentReq := entities.CreatePaymentRequest{
 		Amount:          total,
-		Currency:        cart.TaxCurrency,
+		Currency:        summary.Currency,
 		Customer:        *customer,
-		PaymentMethodId: req.Body.StripePaymentMethodID,
+		PaymentMethodId: req.Body.PaymentMethodID(paymentProvider),
 		PaymentNonce:    req.Body.PaymentNonce,
 		BillingInfo:     req.Body.BillingInfo,
+		IdempotencyKey:  paymentIdempotencyKey,
 	}
 
-	paymentResponse, err := s.createPaymentByProvider(ctx, paymentReq)
+	paymentResponse, err := paymentClient.CreatePayment(ctx, s.newPaymentRequest(paymentProvider, paymentReq))
 	if err != nil {
 		return nil, err
 	}
@@ -215,6 +251,8 @@ func (s *service) CreateOrder(ctx context.Context, req *entities.CreateOrderRequ
 		orderStatus = entities.PaymentSuccess
 	case providers.PaymentStatusFailed:
 		orderStatus = entities.PaymentFailed
+	case providers.PaymentStatusRequiresAction:
+		orderStatus = entities.RequiresAction
 	}
 
 	orderRef, err := s.generateOrderRef(ctx, orderId.String())
@@ -225,13 +263,14 @@ func (s *service) CreateOrder(ctx context.Context, req *entities.CreateOrderRequ
 	order := &entities.Order{
 		ID:                  orderId,
 		CustomerID:          customerID,
-		CartID:              cart.Id,
+		CartID:              summary.CartID,
 		OrderReference:      orderRef,
-		TaxAmount:           cart.TaxAmount,
-		Subtotal:            subTotal,
+		TaxAmount:           summary.Tax,
+		Subtotal:            summary.Subtotal,
+		DiscountAmount:      summary.Discount,
 		Total:               total,
-		Currency:            cart.TaxCurrency,
-		TaxBreakdown:        cart.TaxBreakdown,
+		Currency:            summary.Currency,
+		TaxBreakdown:        summary.TaxBreakdown,
 		DeliveryFullName:    address.FullName,
 		DeliveryAddress:     address.Address,
 		DeliveryCity:        address.City,
@@ -240,6 +279,22 @@ func (s *service) CreateOrder(ctx context.Context, req *entities.CreateOrderRequ
 		DeliveryPostalCode:  address.PostalCode,
 		DeliveryPhoneNumber: address.PhoneNumber,
 		Status:              orderStatus,
+		PaymentProvider:     paymentProvider,
+		PaymentID:           &paymentResponse.ID,
+		PaymentCaptureMode:  s.captureMode,
+	}
+
+	for _, discount := range summary.Discounts {
+		order.Discounts = append(order.Discounts, &entities.OrderDiscount{
+			ID:           uuid.New(),
+			OrderID:      orderId,
+			PromotionID:  discount.PromotionID,
+			Code:         discount.Code,
+			Name:         discount.Name,
+			DiscountType: discount.DiscountType,
+			Amount:       discount.Amount,
+			CreatedAt:    time.Now().UTC(),
+		})
 	}
 
 	// Set total shipping amount on order (if any items have shipping)
@@ -260,88 +315,130 @@ func (s *service) CreateOrder(ctx context.Context, req *entities.CreateOrderRequ
 		order.ShippingRate = &decimal.Zero
 	}
 
-	switch s.paymentClient.GetProvider() {
-	case providers.ProviderStripe:
-		order.StripePaymentIntentID = &paymentResponse.ID
-		order.StripePaymentMethodID = req.Body.StripePaymentMethodID
-	case providers.ProviderAuthorizeNet:
-		order.AuthorizeNetPaymentID = &paymentResponse.ID
+	// the default payment method of the customer is charged when the order doesn't name one
+	if paymentProvider == providers.ProviderStripe {
+		order.StripePaymentMethodID = paymentResponse.PaymentMethodID
 	}
 
-	// create order
-	err = s.repo.CreateOrder(ctx, cart.Id, order, orderItems)
+	// webhook and inventory are notified by the outbox dispatcher once the order is stored
+	outbox, err := newOutboxMessages(
+		notifyStatusChange(order, orderStatus.String()),
+		outboxEntry{
+			topic: entities.TopicInventoryCreateOrder,
+			payload: entities.InventoryCreateOrderPayload{
+				OrderID:   order.ID,
+				Address:   *address,
+				CartItems: cartItems,
+			},
+		},
+	)
 	if err != nil {
+		s.log.Errorf("Error preparing order side effects: %v", err)
 		return nil, moduleErrors.NewAPIError("ORDER_ERROR_CREATING")
-	} else {
-		go func() {
-			bgCtx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-			defer cancel()
-			err := s.webhookClient.NotifyOrderStatusChange(bgCtx, &webhookEntities.NotifyOrderStatusChangeRequest{
-				CustomerID:     customerID.String(),
-				OrderID:        order.ID.String(),
-				OrderReference: order.OrderReference,
-				Status:         orderStatus.String(),
-			})
-			if err != nil {
-				s.log.Errorf("Error notifying order status change: %v", err)
-			}
-		}()
-
-		go func() {
-			bgCtx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-			defer cancel()
-			_, err := s.inventoryClient.CreateOrder(bgCtx, inventoryEntities.CreateInventoryOrderRequest{
-				Order:      *order,
-				OrderItems: orderItems,
-				Address:    *address,
-				Customer:   *customer,
-				CartItems:  cartItems.Items,
-			})
-			if err != nil {
-				s.log.Errorf("Error creating order on inventory: %v", err)
+	}
+
+	// create order
+	err = s.repo.CreateOrder(ctx, summary.CartID, order, orderItems, eventSource(ctx, entities.ActorCustomer, "Order placed"), outbox)
+	if err != nil {
+		// a promotion ran out while the customer was paying, nothing was ordered so the payment is given back
+		var apiErr *sharedErrors.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode == "ORDER_PROMOTION_LIMIT_REACHED" {
+			if err := s.releaseOrderPayment(ctx, order); err != nil {
+				s.log.Errorf("Error releasing payment %s of order %s: %v", paymentResponse.ID, order.ID, err)
 			}
-		}()
+			return nil, apiErr
+		}
+		return nil, moduleErrors.NewAPIError("ORDER_ERROR_CREATING")
 	}
 
-	return &entities.CreateOrderResponse{
+	resp := &entities.CreateOrderResponse{
 		OrderReference: order.OrderReference,
 		OrderItems:     orderItems,
-	}, nil
+		Status:         order.Status,
+	}
+
+	// the customer completes the payment on the storefront, the webhooks update the order afterwards
+	if nextAction := paymentResponse.NextAction; orderStatus == entities.RequiresAction && nextAction != nil {
+		resp.PaymentAction = &entities.PaymentAction{
+			Type:         nextAction.Type,
+			ClientSecret: nextAction.ClientSecret,
+			RedirectURL:  nextAction.RedirectURL,
+		}
+	}
+
+	return resp, nil
 }
 
-func (s *service) createPaymentByProvider(ctx context.Context, paymentReq entities.CreatePaymentRequest) (providers.PaymentProviderResponse, error) {
-	var req any
+// selectPaymentClient returns the client of the provider the order asked for. Without one, the provider is
+// derived from the payment details given, e.g. a Stripe payment method ID is charged with Stripe. Orders with
+// details of more than one provider are rejected. Without details, the first enabled provider the customer
+// has an account with is picked, e.g. B2B customers only known to Authorize.net, and the default provider otherwise.
+func (s *service) selectPaymentClient(body *entities.CreateOrderRequestBody, customer *customerEntities.Customer) (payment.Client, error) {
+	if body.PaymentProvider != "" {
+		paymentClient, err := s.payments.Get(body.PaymentProvider)
+		if err != nil {
+			return nil, moduleErrors.NewAPIError("ORDER_PAYMENT_PROVIDER_NOT_ENABLED")
+		}
+
+		return paymentClient, nil
+	}
 
-	switch s.paymentClient.GetProvider() {
-	case providers.ProviderStripe:
-		req = stripeEntities.CreatePaymentIntentRequest{
-			Amount:          paymentReq.Amount,
-			Currency:        paymentReq.Currency,
-			CustomerId:      paymentReq.Customer.StripeID,
-			PaymentMethodId: paymentReq.PaymentMethodId,
+	detailsProviders := paymentDetailsProviders(body)
+	if len(detailsProviders) > 1 {
+		return nil, moduleErrors.NewAPIError("ORDER_PAYMENT_PROVIDER_AMBIGUOUS")
+	}
+
+	// details of a provider that isn't enabled are left to the enabled ones, the fake provider reads them all
+	if len(detailsProviders) == 1 {
+		if paymentClient, err := s.payments.Get(detailsProviders[0]); err == nil {
+			return paymentClient, nil
 		}
-	case providers.ProviderAuthorizeNet:
-		req = authorizenetEntities.CreatePaymentTransactionRequest{
-			Amount:       paymentReq.Amount,
-			PaymentNonce: paymentReq.PaymentNonce,
-			BillingInfo: authorizenetEntities.BillingInfo{
-				FirstName: paymentReq.BillingInfo.FirstName,
-				LastName:  paymentReq.BillingInfo.LastName,
-				Address:   paymentReq.BillingInfo.Address,
-				City:      paymentReq.BillingInfo.City,
-				State:     paymentReq.BillingInfo.State,
-				Country:   paymentReq.BillingInfo.Country,
-				Zip:       paymentReq.BillingInfo.Zip,
-			},
+	}
+
+	for _, provider := range s.payments.Providers() {
+		if customer.PaymentProviderID(provider) != nil {
+			return s.payments.Get(provider)
 		}
 	}
 
-	res, err := s.paymentClient.CreatePayment(ctx, req)
-	if err != nil {
-		return providers.PaymentProviderResponse{}, err
+	return s.payments.Default(), nil
+}
+
+// paymentDetailsProviders returns the providers the payment details of the order belong to: Stripe for a
+// payment method ID, Authorize.net for a payment profile ID or an Accept.js payment nonce.
+func paymentDetailsProviders(body *entities.CreateOrderRequestBody) []providers.ProviderType {
+	var detailsProviders []providers.ProviderType
+	if body.StripePaymentMethodID != "" {
+		detailsProviders = append(detailsProviders, providers.ProviderStripe)
 	}
+	if body.AuthorizeNetPaymentProfileID != "" || body.PaymentNonce != "" {
+		detailsProviders = append(detailsProviders, providers.ProviderAuthorizeNet)
+	}
+
+	return detailsProviders
+}
 
-	return res, nil
+// newPaymentRequest builds the request charging the order with the payment provider, the customer is
+// referred to by their ID at the provider.
+func (s *service) newPaymentRequest(provider providers.ProviderType, paymentReq entities.CreatePaymentRequest) providers.CreatePaymentRequest {
+	return providers.CreatePaymentRequest{
+		Amount:          paymentReq.Amount,
+		Currency:        paymentReq.Currency,
+		CustomerID:      paymentReq.Customer.PaymentProviderID(provider),
+		PaymentMethodID: paymentReq.PaymentMethodId,
+		PaymentNonce:    paymentReq.PaymentNonce,
+		BillingInfo: providers.BillingInfo{
+			FirstName: paymentReq.BillingInfo.FirstName,
+			LastName:  paymentReq.BillingInfo.LastName,
+			Address:   paymentReq.BillingInfo.Address,
+			City:      paymentReq.BillingInfo.City,
+			State:     paymentReq.BillingInfo.State,
+			Country:   paymentReq.BillingInfo.Country,
+			Zip:       paymentReq.BillingInfo.Zip,
+		},
+		IdempotencyKey: paymentReq.IdempotencyKey,
+		AuthorizeOnly:  s.captureMode == providers.CaptureModeManual,
+	}
 }
 
 // swagger:route GET /orders orders ListOrdersRequest
@@ -418,9 +515,64 @@ func (s *service) GetOrder(ctx context.Context, req *entities.GetOrderRequest) (
 		return nil, moduleErrors.NewAPIError("ORDER_ERROR_GETTING_ITEMS")
 	}
 
+	history, err := s.repo.GetOrderEvents(ctx, orderId)
+	if err != nil {
+		return nil, moduleErrors.NewAPIError("ORDER_ERROR_GETTING_HISTORY")
+	}
+
+	order.Discounts, err = s.repo.GetOrderDiscounts(ctx, orderId)
+	if err != nil {
+		return nil, moduleErrors.NewAPIError("ORDER_ERROR_GETTING")
+	}
+
 	return &entities.GetOrderData{
 		Order:      order,
 		OrderItems: orderItems,
+		History:    history,
+	}, nil
+}
+
+// swagger:route GET /orders/{order_id}/history orders GetOrderHistoryRequest
+//
+// # Get Order History
+// ### Get the status history of an order and its items, oldest first
+//
+// Produces:
+// - application/json
+//
+// Responses:
+//
+//	200: GetOrderHistoryResponse Order history fetched successfully
+//	400: DefaultError Bad Request
+//	404: DefaultError Order not found
+//	500: DefaultError Internal Server Error
+func (s *service) GetOrderHistory(ctx context.Context, req *entities.GetOrderHistoryRequest) (*entities.GetOrderHistoryResponse, error) {
+	customerID, err := uuid.Parse(sharedMeta.XCustomerID(ctx))
+	if err != nil {
+		return nil, moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	}
+
+	orderId, err := uuid.Parse(req.OrderID.String())
+	if err != nil {
+		return nil, moduleErrors.NewAPIError("ORDER_ID_REQUIRED")
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, orderId)
+	if err != nil {
+		return nil, moduleErrors.NewAPIError("ORDER_ERROR_GETTING")
+	}
+
+	if order.CustomerID != customerID {
+		return nil, moduleErrors.NewAPIError("ORDER_NOT_FOUND")
+	}
+
+	events, err := s.repo.GetOrderEvents(ctx, orderId)
+	if err != nil {
+		return nil, moduleErrors.NewAPIError("ORDER_ERROR_GETTING_HISTORY")
+	}
+
+	return &entities.GetOrderHistoryResponse{
+		Events: events,
 	}, nil
 }
 
@@ -460,64 +612,187 @@ func (s *service) CancelOrder(ctx context.Context, req *entities.CancelOrderRequ
 	}
 
 	switch order.Status {
-	case entities.Pending:
-		// cancel order with no refund required
-		err = s.repo.Update(ctx, map[string]interface{}{
+	case entities.Pending, entities.RequiresAction:
+		// customers can only cancel orders that haven't entered fulfillment yet
+		if err := validateStatusTransition(order.Status, entities.Cancelled); err != nil {
+			return err
+		}
+
+		data := map[string]interface{}{
 			"status": entities.Cancelled,
-		}, order.ID.String(), order.CustomerID.String())
+		}
+
+		// update order status on inventory
+		sideEffects := []outboxEntry{updateInventoryOrderStatus(order, entities.Cancelled.String())}
+
+		// the payment may have been authorized already, release the hold
+		if order.AwaitingCapture() {
+			data["payment_voided_at"] = time.Now().UTC()
+			sideEffects = append(sideEffects, voidPayment(order))
+		}
+
+		outbox, err := newOutboxMessages(sideEffects...)
 		if err != nil {
+			s.log.Errorf("Error preparing order side effects: %v", err)
 			return moduleErrors.NewAPIError("ORDER_ERROR_CANCELLING")
 		}
-	case entities.PaymentSuccess:
-		err = s.repo.Update(ctx, map[string]interface{}{
-			"status": entities.Cancelled,
-		}, order.ID.String(), order.CustomerID.String())
+
+		err = s.repo.Update(ctx, data, order.ID.String(), order.CustomerID.String(), eventSource(ctx, entities.ActorCustomer, "Order cancelled by customer"), outbox)
 		if err != nil {
 			return moduleErrors.NewAPIError("ORDER_ERROR_CANCELLING")
 		}
-		// TODO refund payment via stripe & send email to user
-	case entities.Cancelled:
+	case entities.PaymentSuccess:
+		if order.AwaitingCapture() {
+			return s.cancelAuthorizedOrder(ctx, order)
+		}
+
+		return s.cancelPaidOrder(ctx, order)
+	case entities.Cancelled, entities.CancelRefundPending:
 		return moduleErrors.NewAPIError("ORDER_IS_ALREADY_CANCELLED")
 	default:
 		return moduleErrors.NewAPIError("ORDER_CANNOT_BE_CANCELLED")
 	}
 
-	customer, err := s.customerClient.GetCustomerByID(ctx, order.CustomerID.String())
-	if err != nil {
+	return nil
+}
+
+// cancelPaidOrder refunds the whole payment of the order. The order stays in cancel_refund_pending
+// until the payment provider confirms the refund, see ProcessRefundSucceeded.
+func (s *service) cancelPaidOrder(ctx context.Context, order *entities.Order) error {
+	if err := validateStatusTransition(order.Status, entities.CancelRefundPending); err != nil {
 		return err
 	}
 
-	// update order status on inventory
-	go func() {
-		bgCtx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-		defer cancel()
+	orderItems, err := s.repo.GetOrderItemsByID(ctx, order.ID)
+	if err != nil {
+		s.log.Errorf("Error fetching order items: %v", err)
+		return moduleErrors.NewAPIError("ORDER_ERROR_GETTING_ITEMS")
+	}
 
-		err = s.inventoryClient.UpdateOrderStatus(bgCtx, inventoryEntities.UpdateInventoryOrderStatusRequest{
-			Order:    *order,
-			Customer: *customer,
-			Status:   entities.Cancelled.String(),
-		})
-		if err != nil {
-			s.log.Errorf("Error updating order status on inventory: %v", err)
+	paymentClient, paymentID, err := s.orderPayment(order)
+	if err != nil {
+		s.log.Errorf("Error refunding cancelled order %s: %v", order.ID, err)
+		return moduleErrors.NewAPIError("ORDER_REFUNDING_ERROR", "Order payment can't be refunded")
+	}
+
+	// the key is derived from the order so a retried cancellation doesn't refund twice. The last refund
+	// of the order is part of it, a cancellation whose refund failed gets a new key when it's tried again.
+	idempotencyKey := order.ID.String()
+	if order.RefundID != nil {
+		idempotencyKey += ":" + *order.RefundID
+	}
+
+	refund, err := paymentClient.Refund(ctx, providers.RefundRequest{
+		PaymentID:      paymentID,
+		IdempotencyKey: providerIdempotencyKey(idempotencyScopeCancelOrder, idempotencyKey),
+	})
+	if err != nil {
+		s.log.Errorf("Error refunding cancelled order %s: %v", order.ID, err)
+		return moduleErrors.NewAPIError("ORDER_ERROR_CANCELLING", "Error refunding order payment")
+	}
+
+	if refund.Status != providers.RefundStatusSucceeded && refund.Status != providers.RefundStatusPending {
+		s.log.Errorf("Refund of cancelled order %s failed with status: %s and ID: %s", order.ID, refund.Status, refund.ID)
+		return moduleErrors.NewAPIError("ORDER_ERROR_CANCELLING", "Error refunding order payment")
+	}
+
+	refundCreatedAt := time.Now().UTC()
+	orderItemsRefundData := make(map[string]interface{})
+	for _, item := range orderItems {
+		// items refunded earlier are not part of the remaining payment
+		if item.Status == entities.ItemRefunded || item.Status == entities.ItemInitiatedRefund {
+			continue
 		}
-	}()
+
+		orderItemsRefundData[item.ID.String()] = map[string]interface{}{
+			"status":            entities.ItemInitiatedRefund.String(),
+			"refund_id":         refund.ID,
+			"refund_amount":     lineAmount(item, item.Quantity).InexactFloat64(),
+			"refund_created_at": refundCreatedAt,
+		}
+	}
+
+	// fulfillment has to stop right away, the webhook is notified once the refund is confirmed
+	outbox, err := newOutboxMessages(updateInventoryOrderStatus(order, entities.Cancelled.String()))
+	if err != nil {
+		s.log.Errorf("Error preparing order side effects: %v", err)
+		return moduleErrors.NewAPIError("ORDER_ERROR_CANCELLING")
+	}
+
+	err = s.repo.UpdateOrderWithOrderItems(ctx, order.ID, map[string]interface{}{
+		"status":    entities.CancelRefundPending,
+		"refund_id": refund.ID,
+	}, orderItemsRefundData, eventSource(ctx, entities.ActorCustomer, "Order cancelled by customer, refund initiated"), outbox)
+	if err != nil {
+		s.log.Errorf("Error updating cancelled order %s with refund %s: %v", order.ID, refund.ID, err)
+		return moduleErrors.NewAPIError("ORDER_ERROR_CANCELLING")
+	}
+
+	return nil
+}
+
+// cancelAuthorizedOrder releases the funds held for an order that wasn't captured yet,
+// nothing was charged so the order is cancelled right away.
+func (s *service) cancelAuthorizedOrder(ctx context.Context, order *entities.Order) error {
+	if err := validateStatusTransition(order.Status, entities.Cancelled); err != nil {
+		return err
+	}
+
+	outbox, err := newOutboxMessages(
+		voidPayment(order),
+		notifyStatusChange(order, entities.Cancelled.String()),
+		updateInventoryOrderStatus(order, entities.Cancelled.String()),
+	)
+	if err != nil {
+		s.log.Errorf("Error preparing order side effects: %v", err)
+		return moduleErrors.NewAPIError("ORDER_ERROR_CANCELLING")
+	}
+
+	err = s.repo.Update(ctx, map[string]interface{}{
+		"status":            entities.Cancelled,
+		"payment_voided_at": time.Now().UTC(),
+	}, order.ID.String(), order.CustomerID.String(), eventSource(ctx, entities.ActorCustomer, "Order cancelled by customer, payment released"), outbox)
+	if err != nil {
+		s.log.Errorf("Error updating cancelled order %s: %v", order.ID, err)
+		return moduleErrors.NewAPIError("ORDER_ERROR_CANCELLING")
+	}
 
 	return nil
 }
 
-func (s *service) ProcessPaymentSucceeded(ctx context.Context, paymentID string) error {
-	order, err := s.getOrderByPaymentID(ctx, paymentID)
+func (s *service) ProcessPaymentSucceeded(ctx context.Context, provider providers.ProviderType, paymentID string) error {
+	order, err := s.getOrderByPaymentID(ctx, provider, paymentID)
 	if err != nil {
 		return moduleErrors.NewAPIError("ORDER_NOT_FOUND_BY_PAYMENT_ID")
 	}
 
-	if order.Status != entities.Pending {
-		return moduleErrors.NewAPIError("ORDER_IS_NOT_PENDING")
+	// Stripe reports the payment as succeeded again once an authorized payment is captured
+	if order.PaymentCapturedAt != nil || order.CapturePending() {
+		s.log.Infof("Payment %s of order %s was captured already", paymentID, order.ID)
+		return nil
+	}
+
+	// payments approved when the order was placed are confirmed again by the provider's webhook
+	if order.Status == entities.PaymentSuccess {
+		s.log.Infof("Payment %s of order %s succeeded already", paymentID, order.ID)
+		return nil
+	}
+
+	if err := validateStatusTransition(order.Status, entities.PaymentSuccess); err != nil {
+		return err
+	}
+
+	outbox, err := newOutboxMessages(
+		notifyStatusChange(order, entities.PaymentSuccess.String()),
+		updateInventoryOrderStatus(order, entities.PaymentSuccess.String()),
+	)
+	if err != nil {
+		return err
 	}
 
 	err = s.repo.Update(ctx, map[string]interface{}{
 		"status": entities.PaymentSuccess,
-	}, order.ID.String(), order.CustomerID.String())
+	}, order.ID.String(), order.CustomerID.String(), eventSource(ctx, entities.ActorWebhook, "Payment succeeded"), outbox)
 	if err != nil {
 		return err
 	}
@@ -532,33 +807,6 @@ func (s *service) ProcessPaymentSucceeded(ctx context.Context, paymentID string)
 		return err
 	}
 
-	go func() {
-		bgCtx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-		defer cancel()
-		err := s.webhookClient.NotifyOrderStatusChange(bgCtx, &webhookEntities.NotifyOrderStatusChangeRequest{
-			CustomerID:     order.CustomerID.String(),
-			OrderID:        order.ID.String(),
-			OrderReference: order.OrderReference,
-			Status:         entities.PaymentSuccess.String(),
-		})
-		if err != nil {
-			s.log.Errorf("Error notifying order status change: %v", err)
-		}
-	}()
-
-	go func() {
-		bgCtx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-		defer cancel()
-		err := s.inventoryClient.UpdateOrderStatus(bgCtx, inventoryEntities.UpdateInventoryOrderStatusRequest{
-			Order:    *order,
-			Customer: *customer,
-			Status:   entities.PaymentSuccess.String(),
-		})
-		if err != nil {
-			s.log.Errorf("Error updating order status on inventory: %v", err)
-		}
-	}()
-
 	var productIDs []uuid.UUID
 
 	for _, item := range items {
@@ -583,71 +831,53 @@ func (s *service) ProcessPaymentSucceeded(ctx context.Context, paymentID string)
 	return nil
 }
 
-func (s *service) ProcessPaymentFailed(ctx context.Context, paymentID string) error {
-	order, err := s.getOrderByPaymentID(ctx, paymentID)
+func (s *service) ProcessPaymentFailed(ctx context.Context, provider providers.ProviderType, paymentID string) error {
+	order, err := s.getOrderByPaymentID(ctx, provider, paymentID)
 	if err != nil {
 		return moduleErrors.NewAPIError("ORDER_NOT_FOUND_BY_PAYMENT_ID")
 	}
 
-	if order.Status != entities.Pending {
-		return moduleErrors.NewAPIError("ORDER_IS_NOT_PENDING")
+	if err := validateStatusTransition(order.Status, entities.PaymentFailed); err != nil {
+		return err
 	}
 
-	err = s.repo.Update(ctx, map[string]interface{}{
-		"status": entities.PaymentFailed,
-	}, order.ID.String(), order.CustomerID.String())
-
+	outbox, err := newOutboxMessages(
+		notifyStatusChange(order, entities.PaymentFailed.String()),
+		updateInventoryOrderStatus(order, entities.PaymentFailed.String()),
+	)
 	if err != nil {
-		s.log.Errorf("Error updating order status: %v", err)
 		return err
 	}
 
-	customer, err := s.customerClient.GetCustomerByID(ctx, order.CustomerID.String())
+	err = s.repo.Update(ctx, map[string]interface{}{
+		"status": entities.PaymentFailed,
+	}, order.ID.String(), order.CustomerID.String(), eventSource(ctx, entities.ActorWebhook, "Payment failed"), outbox)
+
 	if err != nil {
-		s.log.Errorf("Error fetching customer: %v", err)
+		s.log.Errorf("Error updating order status: %v", err)
 		return err
 	}
 
-	go func() {
-		bgCtx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-		defer cancel()
-		err := s.webhookClient.NotifyOrderStatusChange(bgCtx, &webhookEntities.NotifyOrderStatusChangeRequest{
-			CustomerID:     order.CustomerID.String(),
-			OrderID:        order.ID.String(),
-			OrderReference: order.OrderReference,
-			Status:         entities.PaymentFailed.String(),
-		})
-		if err != nil {
-			s.log.Errorf("Error notifying order status change: %v", err)
-		}
-	}()
-
-	go func() {
-		bgCtx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-		defer cancel()
-		err := s.inventoryClient.UpdateOrderStatus(bgCtx, inventoryEntities.UpdateInventoryOrderStatusRequest{
-			Order:    *order,
-			Customer: *customer,
-			Status:   entities.PaymentFailed.String(),
-		})
-		if err != nil {
-			s.log.Errorf("Error updating order status on inventory: %v", err)
-		}
-	}()
-
 	return nil
 }
 
-func (s *service) getOrderByPaymentID(ctx context.Context, paymentID string) (*entities.Order, error) {
-	switch s.paymentClient.GetProvider() {
-	case providers.ProviderStripe:
-		return s.repo.GetOrderByStripePaymentIntentID(ctx, paymentID)
-	case providers.ProviderAuthorizeNet:
-		return s.repo.GetOrderByAuthorizeNetPaymentID(ctx, paymentID)
-	default:
-		// this should never happen, but just in case
-		return nil, errors.New("payment provider not supported")
+// getOrderByPaymentID returns the order owning the payment, the webhooks of a provider only reach the orders it was paid with.
+func (s *service) getOrderByPaymentID(ctx context.Context, provider providers.ProviderType, paymentID string) (*entities.Order, error) {
+	return s.repo.GetOrderByPaymentID(ctx, provider, paymentID)
+}
+
+// orderPayment returns the client of the provider that created the order payment along with the payment ID.
+func (s *service) orderPayment(order *entities.Order) (payment.Client, string, error) {
+	if order.PaymentID == nil {
+		return nil, "", errors.New("order has no payment")
 	}
+
+	paymentClient, err := s.payments.Get(order.PaymentProvider)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return paymentClient, *order.PaymentID, nil
 }
 
 // swagger:route PUT /orders/{order_reference} orders UpdateOrderRequest
@@ -662,6 +892,7 @@ func (s *service) getOrderByPaymentID(ctx context.Context, paymentID string) (*e
 //
 //	200: Order updated successfully
 //	400: DefaultError Bad Request
+//	409: DefaultError Status transition not allowed, or the status change requires a refund
 //	500: DefaultError Internal Server Error
 func (s *service) UpdateOrder(ctx context.Context, req *entities.UpdateOrderRequest) error {
 	order, err := s.repo.GetOrderByReference(ctx, req.OrderReference)
@@ -673,8 +904,23 @@ func (s *service) UpdateOrder(ctx context.Context, req *entities.UpdateOrderRequ
 	// update order status
 	data := map[string]interface{}{}
 
+	var newStatus *entities.OrderStatus
 	if req.Body.Status != nil {
-		data["status"] = req.Body.Status
+		status := entities.OrderStatus(*req.Body.Status)
+		if !status.IsValid() {
+			return moduleErrors.NewAPIError("ORDER_INVALID_STATUS", fmt.Sprintf("Invalid order status: %s.", status))
+		}
+
+		if err := validateStatusTransition(order.Status, status); err != nil {
+			return err
+		}
+
+		if err := validateAdminStatusChange(order, status); err != nil {
+			return err
+		}
+
+		newStatus = &status
+		data["status"] = status
 	}
 
 	if req.Body.FulfillmentShipmentDate != nil {
@@ -726,6 +972,9 @@ func (s *service) UpdateOrder(ctx context.Context, req *entities.UpdateOrderRequ
 
 			// Add status if provided
 			if item.Status != nil {
+				if !item.Status.IsValid() {
+					return moduleErrors.NewAPIError("ORDER_INVALID_ITEM_STATUS", fmt.Sprintf("Invalid order item status: %s.", *item.Status))
+				}
 				itemData["status"] = item.Status
 			}
 
@@ -734,30 +983,48 @@ func (s *service) UpdateOrder(ctx context.Context, req *entities.UpdateOrderRequ
 		data["items"] = itemsData
 	}
 
-	s.log.Infof("Updating order %s with data: %v", order.ID.String(), data)
-	err = s.repo.Update(ctx, data, order.ID.String(), order.CustomerID.String())
+	var message string
+	if req.Body.FulfillmentMessage != nil {
+		message = *req.Body.FulfillmentMessage
+	}
+
+	var sideEffects []outboxEntry
+
+	// authorized payments are captured when the order ships and released when it's cancelled before that.
+	// The capture and the void are requested in the same transaction as the status change and carried out
+	// by the outbox, so the payment is never touched for an order whose update didn't go through.
+	if newStatus != nil && order.Status != *newStatus && order.AwaitingCapture() {
+		switch *newStatus {
+		case entities.Shipped:
+			amount, err := s.orderCaptureAmount(ctx, order, req.Body.Items)
+			if err != nil {
+				return err
+			}
+			data["payment_capture_requested_at"] = time.Now().UTC()
+			sideEffects = append(sideEffects, capturePayment(order, amount))
+		case entities.Cancelled:
+			data["payment_voided_at"] = time.Now().UTC()
+			sideEffects = append(sideEffects, voidPayment(order))
+		}
+	}
+
+	// Notify only when the status has changed
+	if newStatus != nil && order.Status != *newStatus {
+		sideEffects = append(sideEffects, notifyStatusChange(order, newStatus.String()))
+	}
 
+	outbox, err := newOutboxMessages(sideEffects...)
 	if err != nil {
-		s.log.Errorf("Error updating order status: %v", err)
+		s.log.Errorf("Error preparing order side effects: %v", err)
 		return err
 	}
 
-	// Notify only when the status has changed
-	if req.Body.Status != nil && order.Status.String() != *req.Body.Status {
-		go func() {
-			bgCtx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-			defer cancel()
-
-			err := s.webhookClient.NotifyOrderStatusChange(bgCtx, &webhookEntities.NotifyOrderStatusChangeRequest{
-				CustomerID:     order.CustomerID.String(),
-				OrderID:        order.ID.String(),
-				OrderReference: order.OrderReference,
-				Status:         *req.Body.Status,
-			})
-			if err != nil {
-				s.log.Errorf("Error notifying order status change: %v", err)
-			}
-		}()
+	s.log.Infof("Updating order %s with data: %v", order.ID.String(), data)
+	err = s.repo.Update(ctx, data, order.ID.String(), order.CustomerID.String(), eventSource(ctx, entities.ActorAdmin, message), outbox)
+
+	if err != nil {
+		s.log.Errorf("Error updating order status: %v", err)
+		return err
 	}
 
 	return nil
@@ -775,22 +1042,38 @@ func (s *service) UpdateOrder(ctx context.Context, req *entities.UpdateOrderRequ
 //
 //	200: RefundOrderResponse Order refund initiated successfully
 //	400: DefaultError Bad Request
+//	409: DefaultError Idempotency key conflict
 //	500: DefaultError Internal Server Error
 func (s *service) RefundOrder(ctx context.Context, req *entities.RefundOrderRequest) (*entities.RefundOrderResponse, error) {
-	provider := s.paymentClient.GetProvider()
+	// the order reference is part of the fingerprint, reusing a key for another order is a conflict
+	fingerprint := struct {
+		OrderReference string                           `json:"order_reference"`
+		Body           *entities.RefundOrderRequestBody `json:"body"`
+	}{req.OrderReference, req.Body}
+
+	return idempotent(ctx, s, idempotencyScopeRefundOrder, req.IdempotencyKey, fingerprint, func() (*entities.RefundOrderResponse, error) {
+		return s.refundOrder(ctx, req, providerIdempotencyKey(idempotencyScopeRefundOrder, req.IdempotencyKey))
+	})
+}
 
+func (s *service) refundOrder(ctx context.Context, req *entities.RefundOrderRequest, paymentIdempotencyKey string) (*entities.RefundOrderResponse, error) {
 	order, err := s.repo.GetOrderByReference(ctx, req.OrderReference)
 	if err != nil {
 		return nil, moduleErrors.NewAPIError("ORDER_NOT_FOUND")
 	}
 
-	// TODO: Improve by adding a FSM for Order State Machine
+	provider := order.PaymentProvider
 
-	// disable multiple refunds for the same order
-	if order.Status == entities.Refunded {
+	// disable multiple refunds for the same order and refunds of unpaid orders
+	if order.Status == entities.Refunded || !order.Status.CanTransitionTo(entities.Refunded) {
 		return nil, moduleErrors.NewAPIError("ORDER_REFUNDING_ERROR", "Order is not eligible for refund")
 	}
 
+	// nothing was charged yet, items have to be cancelled so they are left out of the capture
+	if order.AwaitingCapture() || order.CapturePending() || order.CaptureFailed() {
+		return nil, moduleErrors.NewAPIError("ORDER_PAYMENT_NOT_CAPTURED")
+	}
+
 	// get order items
 	orderItems, err := s.repo.GetOrderItemsByID(ctx, order.ID)
 	if err != nil {
@@ -798,8 +1081,11 @@ func (s *service) RefundOrder(ctx context.Context, req *entities.RefundOrderRequ
 		return nil, moduleErrors.NewAPIError("ORDER_ERROR_GETTING_ITEMS")
 	}
 
+	// orders placed before tax and shipping were allocated to items get their shares computed now
+	ensureAllocations(order, orderItems)
+
 	// iterate over order items and request body items to check if they match by sku
-	var refundableAmount decimal.Decimal
+	var refundableAmount, itemsAmount, taxAmount, shippingAmount decimal.Decimal
 	var shouldChangeOrderStatus bool
 	var shouldRefundWholeOrder bool
 	var totalRefundableQuantity, totalOrderQuantity int
@@ -818,27 +1104,22 @@ func (s *service) RefundOrder(ctx context.Context, req *entities.RefundOrderRequ
 		}
 	}
 
+	refundableItemsByID := make(map[string]*entities.OrderItem)
 	for _, item := range req.Body.Items {
 		if item.Sku != "" {
 			for _, orderItem := range refundableOrderItems {
 				if orderItem.SKU == item.Sku && orderItem.Quantity >= item.Quantity {
-					totalItemCost := orderItem.Price.Mul(decimal.NewFromInt(int64(item.Quantity)))
-					refundableAmount = refundableAmount.Add(totalItemCost)
 					// quantity of items that are valid for refund
 					totalRefundableQuantity += item.Quantity
 
-					switch provider {
-					case providers.ProviderStripe:
-						orderItemsRefundData[orderItem.ID.String()] = map[string]interface{}{
-							"status":               entities.ItemInitiatedRefund.String(),
-							"stripe_refund_amount": totalItemCost.InexactFloat64(),
-						}
-					}
+					refundableItemsByID[orderItem.ID.String()] = orderItem
 					refundableItems = append(refundableItems, &entities.RefundableItem{
-						ItemId:   orderItem.ID.String(),
-						Sku:      orderItem.SKU,
-						Quantity: item.Quantity,
-						Price:    orderItem.Price,
+						ItemId:      orderItem.ID.String(),
+						Sku:         orderItem.SKU,
+						Quantity:    item.Quantity,
+						Price:       orderItem.Price,
+						ItemsAmount: lineAmount(orderItem, item.Quantity),
+						TaxAmount:   refundShare(orderItem.TaxAmount, item.Quantity, orderItem.Quantity),
 					})
 					break
 				}
@@ -846,6 +1127,31 @@ func (s *service) RefundOrder(ctx context.Context, req *entities.RefundOrderRequ
 		}
 	}
 
+	// Check if we're refunding all available order items
+	if totalRefundableQuantity == totalOrderQuantity {
+		shouldChangeOrderStatus = true
+		shouldRefundWholeOrder = true
+	}
+
+	for _, item := range refundableItems {
+		// shipping is only refunded on request, unless the whole order is refunded
+		if req.Body.IncludeShipping || shouldRefundWholeOrder {
+			orderItem := refundableItemsByID[item.ItemId]
+			item.ShippingAmount = refundShare(orderItem.ShippingAmount, item.Quantity, orderItem.Quantity)
+		}
+
+		itemTotal := item.ItemsAmount.Add(item.TaxAmount).Add(item.ShippingAmount)
+		itemsAmount = itemsAmount.Add(item.ItemsAmount)
+		taxAmount = taxAmount.Add(item.TaxAmount)
+		shippingAmount = shippingAmount.Add(item.ShippingAmount)
+		refundableAmount = refundableAmount.Add(itemTotal)
+
+		orderItemsRefundData[item.ItemId] = map[string]interface{}{
+			"status":        entities.ItemInitiatedRefund.String(),
+			"refund_amount": itemTotal.InexactFloat64(),
+		}
+	}
+
 	if refundableAmount.IsZero() {
 		return nil, moduleErrors.NewAPIError("ORDER_REFUNDING_ERROR", "No refundable items found or amount is zero")
 	}
@@ -854,92 +1160,78 @@ func (s *service) RefundOrder(ctx context.Context, req *entities.RefundOrderRequ
 		return nil, moduleErrors.NewAPIError("ORDER_REFUNDING_ERROR", "Refundable amount exceeds order total")
 	}
 
-	// Check if we're refunding all available order items
-	if totalRefundableQuantity == totalOrderQuantity {
-		shouldChangeOrderStatus = true
-		shouldRefundWholeOrder = true
+	paymentClient, paymentID, err := s.orderPayment(order)
+	if err != nil {
+		s.log.Errorf("Error refunding order %s: %v", order.ID, err)
+		return nil, moduleErrors.NewAPIError("ORDER_REFUNDING_ERROR", "Order payment can't be refunded")
 	}
 
-	switch provider {
-	case providers.ProviderStripe:
-		var refundResponse *providers.RefundResponse
-		var err error
+	refundRequest := providers.RefundRequest{
+		PaymentID:      paymentID,
+		IdempotencyKey: paymentIdempotencyKey,
+	}
+	if shouldRefundWholeOrder {
+		// Everything needs to be refunded, including shipping and taxes
+		s.log.Infof("Refunding entire order amount via %s", providerName(provider))
+	} else {
+		s.log.Infof("Refunding partial order amount via %s: %s (items %s, tax %s, shipping %s)", providerName(provider),
+			refundableAmount.String(), itemsAmount.String(), taxAmount.String(), shippingAmount.String())
+		refundRequest.Amount = refundableAmount
+	}
 
-		if shouldRefundWholeOrder {
-			// Everything needs to be refunded, including shipping and taxes
-			s.log.Info("Refunding entire order amount via Stripe")
-			refundResponse, err = s.paymentClient.Refund(ctx, &stripeEntities.RefundRequest{
-				PaymentIntentId: *order.StripePaymentIntentID,
-			})
-		} else {
-			// TODO: find a way to calculate the item-level refunding amount excluding shipping and taxes
-			s.log.Infof("Refunding partial order amount via Stripe: %s", refundableAmount.String())
-			refundResponse, err = s.paymentClient.Refund(ctx, &stripeEntities.RefundRequest{
-				PaymentIntentId: *order.StripePaymentIntentID,
-				Amount:          refundableAmount,
-			})
-		}
-		if err != nil {
-			s.log.Errorf("Error processing refund via Stripe: %v", err)
-			return nil, moduleErrors.NewAPIError("ORDER_REFUNDING_ERROR", "Error processing refund via Stripe")
-		}
+	refundResponse, err := paymentClient.Refund(ctx, refundRequest)
+	if err != nil {
+		s.log.Errorf("Error processing refund via %s: %v", providerName(provider), err)
+		return nil, moduleErrors.NewAPIError("ORDER_REFUNDING_ERROR", fmt.Sprintf("Error processing refund via %s", providerName(provider)))
+	}
 
-		if refundResponse.Status != stripeEntities.StripeRefundSucceeded && refundResponse.Status != stripeEntities.StripeRefundPending {
-			s.log.Errorf("Stripe refund failed with status: %s and ID: %s", refundResponse.Status, refundResponse.ID)
-			return nil, moduleErrors.NewAPIError("ORDER_REFUNDING_ERROR", "Stripe refund failed")
-		}
-		for orderItemID, data := range orderItemsRefundData {
-			data.(map[string]interface{})["stripe_refund_id"] = refundResponse.ID
-			data.(map[string]interface{})["stripe_refund_created_at"] = time.Now().UTC()
-			orderItemsRefundData[orderItemID] = data
-		}
-		var stripeTotalRefund decimal.Decimal
+	if refundResponse.Status != providers.RefundStatusSucceeded && refundResponse.Status != providers.RefundStatusPending {
+		s.log.Errorf("%s refund failed with status: %s and ID: %s", providerName(provider), refundResponse.Status, refundResponse.ID)
+		return nil, moduleErrors.NewAPIError("ORDER_REFUNDING_ERROR", fmt.Sprintf("%s refund failed", providerName(provider)))
+	}
 
-		if shouldChangeOrderStatus {
-			// update order refund total for the whole order
-			if order.StripeRefundTotal != nil {
-				stripeTotalRefund = order.StripeRefundTotal.Add(refundableAmount)
-			} else {
-				stripeTotalRefund = refundableAmount
-			}
-			// this total represents the total amount refunded via Stripe, it can change with each initiated refund
-			orderRefundData["stripe_refund_total"] = stripeTotalRefund
-			orderRefundData["status"] = entities.Refunded
+	refundCreatedAt := time.Now().UTC()
+	for orderItemID, data := range orderItemsRefundData {
+		data.(map[string]interface{})["refund_id"] = refundResponse.ID
+		data.(map[string]interface{})["refund_created_at"] = refundCreatedAt
+		orderItemsRefundData[orderItemID] = data
+	}
+
+	if shouldChangeOrderStatus {
+		// update order refund total for the whole order
+		refundTotal := refundableAmount
+		if order.RefundTotal != nil {
+			refundTotal = order.RefundTotal.Add(refundableAmount)
 		}
+		// this total represents the total amount refunded, it can change with each initiated refund
+		orderRefundData["refund_total"] = refundTotal
+		orderRefundData["status"] = entities.Refunded
+	}
 
-		// Stripe doesn't care about individual item refunds, it just processes the amount given
-		// so its safe to mark all refundable items as refunded, assuming the above call succeeded
-		for _, item := range refundableItems {
-			if item.ItemId != "" {
-				item.RefundInitiated = true
-			}
+	// The payment providers don't care about individual item refunds, they just process the amount given
+	// so its safe to mark all refundable items as refunded, assuming the above call succeeded
+	for _, item := range refundableItems {
+		if item.ItemId != "" {
+			item.RefundInitiated = true
 		}
-	default:
-		return nil, moduleErrors.NewAPIError("ORDER_REFUNDING_ERROR", "Payment provider not supported for refunds")
+	}
+
+	outbox, err := newOutboxMessages(notifyStatusChange(order, entities.Refunded.String()))
+	if err != nil {
+		s.log.Errorf("Error preparing order side effects: %v", err)
 	}
 
 	// update order items with refund data
-	err = s.repo.UpdateOrderWithOrderItems(ctx, order.ID, orderRefundData, orderItemsRefundData)
+	err = s.repo.UpdateOrderWithOrderItems(ctx, order.ID, orderRefundData, orderItemsRefundData, eventSource(ctx, entities.ActorAdmin, "Refund initiated"), outbox)
 	if err != nil {
 		s.log.Errorf("Error updating order items with refund data: %v", err)
 	}
 
-	go func() {
-		bgCtx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-		defer cancel()
-		err := s.webhookClient.NotifyOrderStatusChange(bgCtx, &webhookEntities.NotifyOrderStatusChangeRequest{
-			CustomerID:     order.CustomerID.String(),
-			OrderID:        order.ID.String(),
-			OrderReference: order.OrderReference,
-			Status:         entities.Refunded.String(),
-		})
-		if err != nil {
-			s.log.Errorf("Error notifying order status change: %v", err)
-		}
-	}()
-
 	return &entities.RefundOrderResponse{
 		TotalRefundableAmount: refundableAmount,
+		ItemsAmount:           itemsAmount,
+		TaxAmount:             taxAmount,
+		ShippingAmount:        shippingAmount,
 		RefundableItems:       refundableItems,
 	}, nil
 }
@@ -947,7 +1239,7 @@ func (s *service) RefundOrder(ctx context.Context, req *entities.RefundOrderRequ
 func (s *service) ProcessRefundSucceeded(ctx context.Context, refundId string, refundAmount decimal.Decimal) error {
 	s.log.Info("Processing refund succeeded for refund ID: %s with amount: %s", refundId, refundAmount.String())
 
-	orderItems, err := s.repo.GetOrderItemsByStripeRefundID(ctx, refundId)
+	orderItems, err := s.repo.GetOrderItemsByRefundID(ctx, refundId)
 	if err != nil {
 		s.log.Errorf("Error fetching order items by refund ID: %v", err)
 		return moduleErrors.NewAPIError("ORDER_ITEMS_NOT_FOUND_BY_REFUND_ID")
@@ -977,6 +1269,12 @@ func (s *service) ProcessRefundSucceeded(ctx context.Context, refundId string, r
 	var itemsRefunded int
 	orderRefundData := make(map[string]interface{})
 
+	// a cancelled paid order is only waiting for this refund, once confirmed the cancellation is complete
+	finalStatus := entities.Refunded
+	if order.Status == entities.CancelRefundPending {
+		finalStatus = entities.Cancelled
+	}
+
 	// gather count of items that are already refunded
 	for _, item := range orderItems {
 		if item.Status == entities.ItemRefunded {
@@ -984,49 +1282,258 @@ func (s *service) ProcessRefundSucceeded(ctx context.Context, refundId string, r
 		}
 	}
 
-	paymentProvider := s.paymentClient.GetProvider()
-
-	switch paymentProvider {
-	case providers.ProviderStripe:
-		for _, item := range orderItems {
-			if item.StripeRefundID == refundId && item.Status == entities.ItemInitiatedRefund {
-				orderItemsRefundData[item.ID.String()] = map[string]interface{}{
-					"status":               entities.ItemRefunded,
-					"stripe_refund_amount": refundAmount.InexactFloat64(),
-				}
-				itemsRefunded++
+	for _, item := range orderItems {
+		if item.RefundID == refundId && item.Status == entities.ItemInitiatedRefund {
+			orderItemsRefundData[item.ID.String()] = map[string]interface{}{
+				"status":        entities.ItemRefunded,
+				"refund_amount": refundAmount.InexactFloat64(),
 			}
+			itemsRefunded++
 		}
+	}
+
+	// nothing left to confirm, the refund was processed already
+	if len(orderItemsRefundData) == 0 {
+		s.log.Infof("Refund %s was already processed", refundId)
+		return nil
+	}
 
-		if itemsRefunded == len(orderItems) {
-			shouldChangeOrderStatus = true
+	if itemsRefunded == len(orderItems) && order.Status.CanTransitionTo(finalStatus) {
+		shouldChangeOrderStatus = true
+	}
+
+	if shouldChangeOrderStatus {
+		// refund status for partial refunds is only available at item level
+		// the order status will be set to refunded only if all items are refunded)
+		orderRefundData["status"] = finalStatus
+
+		if order.RefundTotal != nil {
+			orderRefundData["refund_total"] = order.RefundTotal.Add(refundAmount)
+		} else {
+			orderRefundData["refund_total"] = refundAmount
+		}
+	}
+
+	// the refund webhook is notified when the refund is initiated, cancellations only once they are complete
+	var outbox []*entities.OutboxMessage
+	if shouldChangeOrderStatus && finalStatus == entities.Cancelled {
+		outbox, err = newOutboxMessages(notifyStatusChange(order, finalStatus.String()))
+		if err != nil {
+			s.log.Errorf("Error preparing order side effects: %v", err)
+			return err
+		}
+	}
+
+	// update order items with refund data
+	err = s.repo.UpdateOrderWithOrderItems(ctx, orderID, orderRefundData, orderItemsRefundData, eventSource(ctx, entities.ActorWebhook, "Refund succeeded"), outbox)
+	if err != nil {
+		s.log.Errorf("Error updating order items with refund data: %v", err)
+	}
+
+	return nil
+}
+
+// ProcessRefundFailed rolls the items of a refund the payment provider couldn't complete back to the
+// status they had before the refund was initiated, so they can be refunded again. An order marked as
+// refunded by the failed refund goes back to its previous status as well, while a cancelled order stays
+// in cancel_refund_pending until its payment is refunded.
+func (s *service) ProcessRefundFailed(ctx context.Context, refundID string, reason string) error {
+	s.log.Infof("Processing refund failed for refund ID: %s with reason: %s", refundID, reason)
+
+	orderItems, err := s.repo.GetOrderItemsByRefundID(ctx, refundID)
+	if err != nil {
+		s.log.Errorf("Error fetching order items by refund ID: %v", err)
+		return moduleErrors.NewAPIError("ORDER_ITEMS_NOT_FOUND_BY_REFUND_ID")
+	}
+
+	if len(orderItems) == 0 {
+		s.log.Errorf("No order items found for refund ID: %s", refundID)
+		return nil
+	}
+
+	orderID := orderItems[0].OrderID
+	order, err := s.repo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		s.log.Errorf("Error fetching order by ID: %v", err)
+		return moduleErrors.NewAPIError("ORDER_NOT_FOUND_BY_ID")
+	}
+
+	// the history tells which status the items and the order had before the refund
+	events, err := s.repo.GetOrderEvents(ctx, orderID)
+	if err != nil {
+		s.log.Errorf("Error fetching order history: %v", err)
+		return err
+	}
+
+	failedAmount := decimal.Zero
+	orderItemsRefundData := make(map[string]interface{})
+	for _, item := range orderItems {
+		if item.Status != entities.ItemInitiatedRefund {
+			continue
 		}
 
-		if shouldChangeOrderStatus {
-			// refund status for partial refunds is only available at item level
-			// the order status will be set to refunded only if all items are refunded)
-			orderRefundData["status"] = entities.Refunded
+		previousStatus, ok := statusBefore(events, &item.ID, entities.ItemInitiatedRefund.String())
+		if !ok {
+			s.log.Warnf("No status found for order item %s before refund %s, falling back to %s", item.ID, refundID, entities.ItemPending)
+			previousStatus = entities.ItemPending.String()
+		}
 
-			if order.StripeRefundTotal != nil {
-				orderRefundData["stripe_refund_total"] = order.StripeRefundTotal.Add(refundAmount)
+		if item.RefundAmount != nil {
+			failedAmount = failedAmount.Add(*item.RefundAmount)
+		}
+
+		orderItemsRefundData[item.ID.String()] = map[string]interface{}{
+			"status":                previousStatus,
+			"refund_amount":         nil,
+			"refund_failure_reason": reason,
+		}
+	}
+
+	// nothing left to roll back, the refund failure was processed already
+	if len(orderItemsRefundData) == 0 {
+		s.log.Infof("Refund %s failure was already processed", refundID)
+		return nil
+	}
+
+	orderRefundData := make(map[string]interface{})
+	var outbox []*entities.OutboxMessage
+	switch order.Status {
+	case entities.Refunded:
+		// refunded is terminal for the state machine, only a failed refund may undo it
+		previousStatus, ok := statusBefore(events, nil, entities.Refunded.String())
+		if !ok {
+			previousStatus = entities.PaymentSuccess.String()
+		}
+		orderRefundData["status"] = previousStatus
+
+		if order.RefundTotal != nil {
+			refundTotal := order.RefundTotal.Sub(failedAmount)
+			if refundTotal.IsPositive() {
+				orderRefundData["refund_total"] = refundTotal
 			} else {
-				orderRefundData["stripe_refund_total"] = refundAmount
+				orderRefundData["refund_total"] = nil
 			}
 		}
+
+		outbox, err = newOutboxMessages(notifyStatusChange(order, previousStatus))
+		if err != nil {
+			s.log.Errorf("Error preparing order side effects: %v", err)
+			return err
+		}
+	case entities.CancelRefundPending:
+		// the cancellation didn't go through, the order goes back to payment_success so the customer can
+		// cancel it again. Fulfillment was stopped when the order was cancelled, it's told to resume.
+		orderRefundData["status"] = entities.PaymentSuccess
+		outbox, err = newOutboxMessages(
+			notifyStatusChange(order, entities.PaymentSuccess.String()),
+			updateInventoryOrderStatus(order, entities.PaymentSuccess.String()),
+		)
+		if err != nil {
+			s.log.Errorf("Error preparing order side effects: %v", err)
+			return err
+		}
+	}
+
+	err = s.repo.UpdateOrderWithOrderItems(ctx, orderID, orderRefundData, orderItemsRefundData,
+		eventSource(ctx, entities.ActorWebhook, fmt.Sprintf("Refund failed: %s", reason)), outbox)
+	if err != nil {
+		s.log.Errorf("Error rolling back order items of failed refund %s: %v", refundID, err)
+		return err
+	}
+
+	return nil
+}
+
+// statusBefore returns the status the order, or the order item when itemID is set, had before it
+// last moved to status. The events are expected in chronological order.
+func statusBefore(events []*entities.OrderEvent, itemID *uuid.UUID, status string) (string, bool) {
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		if event.NewStatus != status || event.OldStatus == nil {
+			continue
+		}
+
+		if (itemID == nil && event.OrderItemID == nil) ||
+			(itemID != nil && event.OrderItemID != nil && *event.OrderItemID == *itemID) {
+			return *event.OldStatus, true
+		}
+	}
+
+	return "", false
+}
+
+// providerName returns the name of the payment provider used in messages.
+func providerName(provider providers.ProviderType) string {
+	switch provider {
+	case providers.ProviderStripe:
+		return "Stripe"
+	case providers.ProviderAuthorizeNet:
+		return "Authorize.net"
+	case providers.ProviderFake:
+		return "Fake payments"
 	default:
-		s.log.Errorf("Payment provider %s does not support refund processing", paymentProvider)
+		return string(provider)
+	}
+}
+
+// validateStatusTransition rejects status changes that are not allowed by the order state machine.
+func validateStatusTransition(from, to entities.OrderStatus) error {
+	if !from.CanTransitionTo(to) {
+		return moduleErrors.NewAPIError("ORDER_INVALID_STATUS_TRANSITION",
+			fmt.Sprintf("Order cannot transition from %s to %s.", from, to))
+	}
+
+	return nil
+}
+
+// validateAdminStatusChange rejects the status changes that have to move money. An order is refunded through the
+// refund endpoint and a paid order is cancelled through the cancel endpoint, which refund the payment; only an
+// order whose payment wasn't taken can be cancelled by a status update.
+func validateAdminStatusChange(order *entities.Order, to entities.OrderStatus) error {
+	if order.Status == to {
 		return nil
 	}
 
-	// update order items with refund data
-	err = s.repo.UpdateOrderWithOrderItems(ctx, orderID, orderRefundData, orderItemsRefundData)
-	if err != nil {
-		s.log.Errorf("Error updating order items with refund data: %v", err)
+	// the refund of the cancellation decides whether the order is cancelled or goes back to payment_success
+	if order.Status == entities.CancelRefundPending {
+		return moduleErrors.NewAPIError("ORDER_STATUS_REQUIRES_REFUND",
+			fmt.Sprintf("Order is waiting for its cancellation refund, it cannot be moved to %s.", to))
+	}
+
+	switch to {
+	case entities.Refunded, entities.CancelRefundPending:
+		return moduleErrors.NewAPIError("ORDER_STATUS_REQUIRES_REFUND",
+			fmt.Sprintf("Order cannot be moved to %s without a refund, use the refund or cancel endpoints.", to))
+	case entities.Cancelled:
+		switch order.Status {
+		case entities.Pending, entities.RequiresAction:
+			return nil
+		}
+		if !order.AwaitingCapture() {
+			return moduleErrors.NewAPIError("ORDER_STATUS_REQUIRES_REFUND",
+				fmt.Sprintf("Order payment was taken, it cannot be moved to %s without a refund, use the cancel endpoint.", to))
+		}
 	}
 
 	return nil
 }
 
+// eventSource describes the origin of a status change for the order history.
+// The ID of the provider event that triggered the change is taken from the context when present.
+func eventSource(ctx context.Context, actor entities.OrderEventActor, message string) entities.OrderEventSource {
+	source := entities.OrderEventSource{Actor: actor}
+
+	if eventID := sharedMeta.SourceEventID(ctx); eventID != "" {
+		source.SourceEventID = &eventID
+	}
+
+	if message != "" {
+		source.Message = &message
+	}
+
+	return source
+}
+
 // generateOrderRef generates a unique order reference based on the order ID.
 func (s *service) generateOrderRef(ctx context.Context, orderId string) (string, error) {
 	for {
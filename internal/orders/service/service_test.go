@@ -19,16 +19,16 @@ import (
 	"github.com/nurdsoft/nurd-commerce-core/internal/orders/repository"
 	productEntities "github.com/nurdsoft/nurd-commerce-core/internal/product/entities"
 	"github.com/nurdsoft/nurd-commerce-core/internal/product/productclient"
+	promotionsEntities "github.com/nurdsoft/nurd-commerce-core/internal/promotions/entities"
+	promotionsErrors "github.com/nurdsoft/nurd-commerce-core/internal/promotions/errors"
 	webhookclient "github.com/nurdsoft/nurd-commerce-core/internal/webhook/client"
-	webhookEntities "github.com/nurdsoft/nurd-commerce-core/internal/webhook/entities"
 	wishlistEntities "github.com/nurdsoft/nurd-commerce-core/internal/wishlist/entities"
 	wishlistclient "github.com/nurdsoft/nurd-commerce-core/internal/wishlist/wishlistclient"
+	sharedErrors "github.com/nurdsoft/nurd-commerce-core/shared/errors"
 	sharedMeta "github.com/nurdsoft/nurd-commerce-core/shared/meta"
 	"github.com/nurdsoft/nurd-commerce-core/shared/nullable"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory"
-	inventoryEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory/entities"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment"
-	authorizenetEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/authorizenet/entities"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 	stripeEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe/entities"
 	"github.com/shopspring/decimal"
@@ -66,12 +66,19 @@ func setupTestController(t *testing.T) *testController {
 
 func newServiceUnderTest(tc *testController) *service {
 	logger, _ := zap.NewDevelopment()
+	// every provider is served by the same mock, tests tell them apart with GetProvider
+	payments, _ := payment.NewRegistry(providers.ProviderStripe, map[providers.ProviderType]payment.Client{
+		providers.ProviderStripe:       tc.mockPayment,
+		providers.ProviderAuthorizeNet: tc.mockPayment,
+		providers.ProviderFake:         tc.mockPayment,
+	})
+
 	return &service{
 		repo:            tc.mockRepo,
 		log:             logger.Sugar(),
 		customerClient:  tc.mockCustomer,
 		cartClient:      tc.mockCart,
-		paymentClient:   tc.mockPayment,
+		payments:        payments,
 		wishlistClient:  tc.mockWishlist,
 		inventoryClient: tc.mockInventory,
 		addressClient:   tc.mockAddress,
@@ -80,6 +87,48 @@ func newServiceUnderTest(tc *testController) *service {
 	}
 }
 
+// expectCartSummary makes the cart summarize the items as the cart module does when nothing is discounted.
+func expectCartSummary(tc *testController, cart *cartEntities.Cart, items []cartEntities.CartItemDetail, shippingRates ...*cartEntities.CartShippingRate) {
+	summary := &cartEntities.CartSummary{
+		CartID:         cart.Id,
+		CouponCode:     cart.CouponCode,
+		Subtotal:       decimal.Zero,
+		ShippingAmount: decimal.Zero,
+		Tax:            cart.TaxAmount,
+		TaxBreakdown:   cart.TaxBreakdown,
+		Currency:       cart.TaxCurrency,
+	}
+
+	ratesByID := make(map[uuid.UUID]*cartEntities.CartShippingRate)
+	for _, rate := range shippingRates {
+		ratesByID[rate.Id] = rate
+		summary.ShippingAmount = summary.ShippingAmount.Add(rate.Amount)
+	}
+
+	for _, item := range items {
+		lineSubtotal := item.Price.Mul(decimal.NewFromInt(int64(item.Quantity)))
+		summaryItem := &cartEntities.CartSummaryItem{CartItemDetail: item, LineSubtotal: lineSubtotal, LineTotal: lineSubtotal}
+		if item.ShippingRateID != nil {
+			summaryItem.ShippingRate = ratesByID[*item.ShippingRateID]
+		}
+		summary.Items = append(summary.Items, summaryItem)
+		summary.Subtotal = summary.Subtotal.Add(lineSubtotal)
+	}
+	summary.Total = summary.Subtotal.Add(summary.ShippingAmount).Add(summary.Tax)
+
+	tc.mockCart.EXPECT().
+		GetCartSummary(gomock.Any()).
+		Return(summary, nil)
+}
+
+func outboxTopics(messages []*entities.OutboxMessage) []entities.OutboxTopic {
+	var topics []entities.OutboxTopic
+	for _, message := range messages {
+		topics = append(topics, message.Topic)
+	}
+	return topics
+}
+
 func TestCreateOrder_WithStripe(t *testing.T) {
 	tc := setupTestController(t)
 	s := newServiceUnderTest(tc)
@@ -110,42 +159,38 @@ func TestCreateOrder_WithStripe(t *testing.T) {
 			PhoneNumber: nullable.StringPtr("1234567890"),
 		}, nil)
 
-	tc.mockCart.EXPECT().
-		GetCart(gomock.Any()).
-		Return(&cartEntities.Cart{
-			Id:          cartID,
-			TaxAmount:   decimal.NewFromFloat(10.0),
-			TaxCurrency: "USD",
-		}, nil)
+	cart := &cartEntities.Cart{
+		Id:          cartID,
+		TaxAmount:   decimal.NewFromFloat(10.0),
+		TaxCurrency: "USD",
+	}
 
-	tc.mockCart.EXPECT().
-		GetCartItems(gomock.Any()).
-		Return(&cartEntities.GetCartItemsResponse{
-			Items: []cartEntities.CartItemDetail{
-				{
-					ProductID:        uuid.New(),
-					ProductVariantID: uuid.New(),
-					SKU:              "SKU123",
-					Name:             "Test Product",
-					Quantity:         2,
-					Price:            decimal.NewFromInt(50),
-					ShippingRateID:   &shippingRateID,
-				},
+	cartItems := &cartEntities.GetCartItemsResponse{
+		Items: []cartEntities.CartItemDetail{
+			{
+				ProductID:        uuid.New(),
+				ProductVariantID: uuid.New(),
+				SKU:              "SKU123",
+				Name:             "Test Product",
+				Quantity:         2,
+				Price:            decimal.NewFromInt(50),
+				ShippingRateID:   &shippingRateID,
 			},
-		}, nil)
+		},
+	}
 
-	tc.mockCart.EXPECT().
-		GetShippingRateByID(gomock.Any(), shippingRateID).
-		Return(&cartEntities.CartShippingRate{
-			Id:                    shippingRateID,
-			Amount:                decimal.NewFromInt(5),
-			CarrierName:           "Test Carrier",
-			CarrierCode:           "TEST",
-			ServiceType:           "Standard",
-			ServiceCode:           "STD",
-			EstimatedDeliveryDate: time.Now().Add(24 * time.Hour),
-			BusinessDaysInTransit: "2",
-		}, nil)
+	shippingRate := &cartEntities.CartShippingRate{
+		Id:                    shippingRateID,
+		Amount:                decimal.NewFromInt(5),
+		CarrierName:           "Test Carrier",
+		CarrierCode:           "TEST",
+		ServiceType:           "Standard",
+		ServiceCode:           "STD",
+		EstimatedDeliveryDate: time.Now().Add(24 * time.Hour),
+		BusinessDaysInTransit: "2",
+	}
+
+	expectCartSummary(tc, cart, cartItems.Items, shippingRate)
 
 	tc.mockCustomer.EXPECT().
 		GetCustomer(gomock.Any()).
@@ -156,18 +201,19 @@ func TestCreateOrder_WithStripe(t *testing.T) {
 
 	tc.mockPayment.EXPECT().
 		GetProvider().
-		Return(providers.ProviderStripe).Times(2)
+		Return(providers.ProviderStripe)
 
 	tc.mockPayment.EXPECT().
 		CreatePayment(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, req stripeEntities.CreatePaymentIntentRequest) {
+		Do(func(_ context.Context, req providers.CreatePaymentRequest) {
 			assert.Equal(t, expectedTotal, req.Amount)
-			assert.Equal(t, paymentMethodID, req.PaymentMethodId)
-			assert.Equal(t, customerStripeID, *req.CustomerId)
+			assert.Equal(t, paymentMethodID, req.PaymentMethodID)
+			assert.Equal(t, customerStripeID, *req.CustomerID)
 		}).
 		Return(providers.PaymentProviderResponse{
-			ID:     expectedPaymentIntentID,
-			Status: providers.PaymentStatusPending,
+			ID:              expectedPaymentIntentID,
+			Status:          providers.PaymentStatusPending,
+			PaymentMethodID: paymentMethodID,
 		}, nil)
 
 	tc.mockRepo.EXPECT().
@@ -175,36 +221,148 @@ func TestCreateOrder_WithStripe(t *testing.T) {
 		Return(false, nil)
 
 	tc.mockRepo.EXPECT().
-		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ uuid.UUID, order *entities.Order, _ []*entities.OrderItem) {
+		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ uuid.UUID, order *entities.Order, _ []*entities.OrderItem, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+			assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged, entities.TopicInventoryCreateOrder}, outboxTopics(outbox))
 			//assert order details
 			assert.Equal(t, customerID, order.CustomerID)
 			assert.Equal(t, cartID, order.CartID)
 			assert.Equal(t, expectedAddress, order.DeliveryAddress)
 			assert.Equal(t, expectedTotal, order.Total)
 			assert.Equal(t, entities.Pending, order.Status)
-			assert.Equal(t, expectedPaymentIntentID, *order.StripePaymentIntentID)
+			assert.Equal(t, providers.ProviderStripe, order.PaymentProvider)
+			assert.Equal(t, expectedPaymentIntentID, *order.PaymentID)
 			assert.Equal(t, paymentMethodID, order.StripePaymentMethodID)
 		}).
 		Return(nil)
 
-	notifyCallDone := make(chan struct{})
-	tc.mockWebhook.EXPECT().
-		NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, req *webhookEntities.NotifyOrderStatusChangeRequest) {
-			assert.Equal(t, customerID.String(), req.CustomerID)
-			assert.Equal(t, entities.Pending.String(), req.Status)
-			close(notifyCallDone)
+	req := &entities.CreateOrderRequest{
+		Body: &entities.CreateOrderRequestBody{
+			AddressID:             addressID,
+			ShippingRateID:        &shippingRateID,
+			StripePaymentMethodID: paymentMethodID,
+		},
+	}
+
+	resp, err := s.CreateOrder(ctx, req)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.NotEmpty(t, resp.OrderReference)
+	assert.Equal(t, entities.Pending, resp.Status)
+	assert.Nil(t, resp.PaymentAction)
+}
+
+func TestCreateOrder_WithStripeRequiresAction(t *testing.T) {
+	tc := setupTestController(t)
+	s := newServiceUnderTest(tc)
+
+	customerID := uuid.New()
+	addressID := uuid.New()
+	cartID := uuid.New()
+	shippingRateID := uuid.New()
+	paymentMethodID := "pm_123"
+	customerStripeID := "cus_123"
+	expectedPaymentIntentID := "pi_123"
+	expectedAddress := "123 Main St"
+	expectedTotal := decimal.NewFromInt(115)
+
+	ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
+
+	tc.mockAddress.EXPECT().
+		GetAddress(gomock.Any(), &addressEntities.GetAddressRequest{
+			AddressID: addressID,
 		}).
-		Return(nil)
+		Return(&addressEntities.Address{
+			FullName:    "John Doe",
+			Address:     expectedAddress,
+			City:        nullable.StringPtr("New York"),
+			StateCode:   "NY",
+			CountryCode: "US",
+			PostalCode:  "10001",
+			PhoneNumber: nullable.StringPtr("1234567890"),
+		}, nil)
+
+	cart := &cartEntities.Cart{
+		Id:          cartID,
+		TaxAmount:   decimal.NewFromFloat(10.0),
+		TaxCurrency: "USD",
+	}
+
+	cartItems := &cartEntities.GetCartItemsResponse{
+		Items: []cartEntities.CartItemDetail{
+			{
+				ProductID:        uuid.New(),
+				ProductVariantID: uuid.New(),
+				SKU:              "SKU123",
+				Name:             "Test Product",
+				Quantity:         2,
+				Price:            decimal.NewFromInt(50),
+				ShippingRateID:   &shippingRateID,
+			},
+		},
+	}
+
+	shippingRate := &cartEntities.CartShippingRate{
+		Id:                    shippingRateID,
+		Amount:                decimal.NewFromInt(5),
+		CarrierName:           "Test Carrier",
+		CarrierCode:           "TEST",
+		ServiceType:           "Standard",
+		ServiceCode:           "STD",
+		EstimatedDeliveryDate: time.Now().Add(24 * time.Hour),
+		BusinessDaysInTransit: "2",
+	}
+
+	expectCartSummary(tc, cart, cartItems.Items, shippingRate)
+
+	tc.mockCustomer.EXPECT().
+		GetCustomer(gomock.Any()).
+		Return(&customerEntities.Customer{
+			ID:       customerID,
+			StripeID: nullable.StringPtr(customerStripeID),
+		}, nil)
+
+	tc.mockPayment.EXPECT().
+		GetProvider().
+		Return(providers.ProviderStripe)
+
+	tc.mockPayment.EXPECT().
+		CreatePayment(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req providers.CreatePaymentRequest) {
+			assert.Equal(t, expectedTotal, req.Amount)
+			assert.Equal(t, paymentMethodID, req.PaymentMethodID)
+			assert.Equal(t, customerStripeID, *req.CustomerID)
+		}).
+		Return(providers.PaymentProviderResponse{
+			ID:              expectedPaymentIntentID,
+			Status:          providers.PaymentStatusRequiresAction,
+			PaymentMethodID: paymentMethodID,
+			NextAction: &providers.PaymentNextAction{
+				Type:         "use_stripe_sdk",
+				ClientSecret: "pi_123_secret_456",
+			},
+		}, nil)
+
+	tc.mockRepo.EXPECT().
+		OrderReferenceExists(gomock.Any(), gomock.Any()).
+		Return(false, nil)
 
-	salesforceCallDone := make(chan struct{})
-	tc.mockInventory.EXPECT().
-		CreateOrder(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ inventoryEntities.CreateInventoryOrderRequest) {
-			close(salesforceCallDone)
+	tc.mockRepo.EXPECT().
+		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ uuid.UUID, order *entities.Order, _ []*entities.OrderItem, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+			assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged, entities.TopicInventoryCreateOrder}, outboxTopics(outbox))
+			//assert order details
+			assert.Equal(t, customerID, order.CustomerID)
+			assert.Equal(t, cartID, order.CartID)
+			assert.Equal(t, expectedAddress, order.DeliveryAddress)
+			assert.Equal(t, expectedTotal, order.Total)
+			assert.Equal(t, entities.RequiresAction, order.Status)
+			assert.Equal(t, providers.ProviderStripe, order.PaymentProvider)
+			assert.Equal(t, expectedPaymentIntentID, *order.PaymentID)
+			assert.Equal(t, paymentMethodID, order.StripePaymentMethodID)
 		}).
-		Return(nil, nil)
+		Return(nil)
 
 	req := &entities.CreateOrderRequest{
 		Body: &entities.CreateOrderRequestBody{
@@ -219,9 +377,11 @@ func TestCreateOrder_WithStripe(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.NotEmpty(t, resp.OrderReference)
-	// wait for async notify call to be done
-	<-notifyCallDone
-	<-salesforceCallDone
+	assert.Equal(t, entities.RequiresAction, resp.Status)
+	assert.Equal(t, &entities.PaymentAction{
+		Type:         "use_stripe_sdk",
+		ClientSecret: "pi_123_secret_456",
+	}, resp.PaymentAction)
 }
 
 func TestCreateOrder_WithAuthorizeNet(t *testing.T) {
@@ -263,42 +423,38 @@ func TestCreateOrder_WithAuthorizeNet(t *testing.T) {
 			PhoneNumber: nullable.StringPtr("1234567890"),
 		}, nil)
 
-	tc.mockCart.EXPECT().
-		GetCart(gomock.Any()).
-		Return(&cartEntities.Cart{
-			Id:          cartID,
-			TaxAmount:   decimal.NewFromInt(10),
-			TaxCurrency: "USD",
-		}, nil)
+	cart := &cartEntities.Cart{
+		Id:          cartID,
+		TaxAmount:   decimal.NewFromInt(10),
+		TaxCurrency: "USD",
+	}
 
-	tc.mockCart.EXPECT().
-		GetCartItems(gomock.Any()).
-		Return(&cartEntities.GetCartItemsResponse{
-			Items: []cartEntities.CartItemDetail{
-				{
-					ProductID:        uuid.New(),
-					ProductVariantID: uuid.New(),
-					SKU:              "SKU123",
-					Name:             "Test Product",
-					Quantity:         2,
-					Price:            decimal.NewFromInt(50),
-					ShippingRateID:   &shippingRateID,
-				},
+	cartItems := &cartEntities.GetCartItemsResponse{
+		Items: []cartEntities.CartItemDetail{
+			{
+				ProductID:        uuid.New(),
+				ProductVariantID: uuid.New(),
+				SKU:              "SKU123",
+				Name:             "Test Product",
+				Quantity:         2,
+				Price:            decimal.NewFromInt(50),
+				ShippingRateID:   &shippingRateID,
 			},
-		}, nil)
+		},
+	}
 
-	tc.mockCart.EXPECT().
-		GetShippingRateByID(gomock.Any(), shippingRateID).
-		Return(&cartEntities.CartShippingRate{
-			Id:                    shippingRateID,
-			Amount:                decimal.NewFromInt(5),
-			CarrierName:           "Test Carrier",
-			CarrierCode:           "TEST",
-			ServiceType:           "Standard",
-			ServiceCode:           "STD",
-			EstimatedDeliveryDate: time.Now().Add(24 * time.Hour),
-			BusinessDaysInTransit: "2",
-		}, nil)
+	shippingRate := &cartEntities.CartShippingRate{
+		Id:                    shippingRateID,
+		Amount:                decimal.NewFromInt(5),
+		CarrierName:           "Test Carrier",
+		CarrierCode:           "TEST",
+		ServiceType:           "Standard",
+		ServiceCode:           "STD",
+		EstimatedDeliveryDate: time.Now().Add(24 * time.Hour),
+		BusinessDaysInTransit: "2",
+	}
+
+	expectCartSummary(tc, cart, cartItems.Items, shippingRate)
 
 	tc.mockCustomer.EXPECT().
 		GetCustomer(gomock.Any()).
@@ -309,11 +465,11 @@ func TestCreateOrder_WithAuthorizeNet(t *testing.T) {
 
 	tc.mockPayment.EXPECT().
 		GetProvider().
-		Return(providers.ProviderAuthorizeNet).Times(2)
+		Return(providers.ProviderAuthorizeNet)
 
 	tc.mockPayment.EXPECT().
 		CreatePayment(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, req authorizenetEntities.CreatePaymentTransactionRequest) {
+		Do(func(_ context.Context, req providers.CreatePaymentRequest) {
 			assert.Equal(t, expectedTotal, req.Amount)
 			assert.Equal(t, paymentNonce, req.PaymentNonce)
 			assert.EqualValues(t, expectedBillingInfo, req.BillingInfo)
@@ -328,37 +484,21 @@ func TestCreateOrder_WithAuthorizeNet(t *testing.T) {
 		Return(false, nil)
 
 	tc.mockRepo.EXPECT().
-		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ uuid.UUID, order *entities.Order, _ []*entities.OrderItem) {
+		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ uuid.UUID, order *entities.Order, _ []*entities.OrderItem, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+			assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged, entities.TopicInventoryCreateOrder}, outboxTopics(outbox))
 			//assert order details
 			assert.Equal(t, customerID, order.CustomerID)
 			assert.Equal(t, cartID, order.CartID)
 			assert.Equal(t, expectedAddress, order.DeliveryAddress)
 			assert.Equal(t, expectedTotal, order.Total)
 			assert.Equal(t, entities.PaymentSuccess, order.Status)
-			assert.Equal(t, expectedTransactionID, *order.AuthorizeNetPaymentID)
+			assert.Equal(t, providers.ProviderAuthorizeNet, order.PaymentProvider)
+			assert.Equal(t, expectedTransactionID, *order.PaymentID)
 			assert.Empty(t, order.StripePaymentMethodID)
 		}).
 		Return(nil)
 
-	notifyCallDone := make(chan struct{})
-	tc.mockWebhook.EXPECT().
-		NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, req *webhookEntities.NotifyOrderStatusChangeRequest) {
-			assert.Equal(t, customerID.String(), req.CustomerID)
-			assert.Equal(t, entities.PaymentSuccess.String(), req.Status)
-			close(notifyCallDone)
-		}).
-		Return(nil)
-
-	salesforceCallDone := make(chan struct{})
-	tc.mockInventory.EXPECT().
-		CreateOrder(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ inventoryEntities.CreateInventoryOrderRequest) {
-			close(salesforceCallDone)
-		}).
-		Return(nil, nil)
-
 	req := &entities.CreateOrderRequest{
 		Body: &entities.CreateOrderRequestBody{
 			AddressID:    addressID,
@@ -372,9 +512,6 @@ func TestCreateOrder_WithAuthorizeNet(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.NotEmpty(t, resp.OrderReference)
-	// wait for async notify call to be done
-	<-notifyCallDone
-	<-salesforceCallDone
 }
 
 func TestCreateOrder_PerItemShipping_MultipleRates_Stripe(t *testing.T) {
@@ -408,54 +545,48 @@ func TestCreateOrder_PerItemShipping_MultipleRates_Stripe(t *testing.T) {
 			PhoneNumber: nullable.StringPtr("1234567890"),
 		}, nil)
 
-	tc.mockCart.EXPECT().
-		GetCart(gomock.Any()).
-		Return(&cartEntities.Cart{Id: cartID, TaxAmount: decimal.NewFromInt(10), TaxCurrency: "USD"}, nil)
+	cart := &cartEntities.Cart{Id: cartID, TaxAmount: decimal.NewFromInt(10), TaxCurrency: "USD"}
 
-	tc.mockCart.EXPECT().
-		GetCartItems(gomock.Any()).
-		Return(&cartEntities.GetCartItemsResponse{Items: []cartEntities.CartItemDetail{
-			{
-				ProductID:        uuid.New(),
-				ProductVariantID: uuid.New(),
-				SKU:              "SKU-A",
-				Name:             "Product A",
-				Quantity:         1,
-				Price:            decimal.NewFromInt(50),
-				ShippingRateID:   &shippingRateID1,
-			},
-			{
-				ProductID:        uuid.New(),
-				ProductVariantID: uuid.New(),
-				SKU:              "SKU-B",
-				Name:             "Product B",
-				Quantity:         2,
-				Price:            decimal.NewFromInt(30),
-				ShippingRateID:   &shippingRateID2,
-			},
-		}}, nil)
+	cartItems := &cartEntities.GetCartItemsResponse{Items: []cartEntities.CartItemDetail{
+		{
+			ProductID:        uuid.New(),
+			ProductVariantID: uuid.New(),
+			SKU:              "SKU-A",
+			Name:             "Product A",
+			Quantity:         1,
+			Price:            decimal.NewFromInt(50),
+			ShippingRateID:   &shippingRateID1,
+		},
+		{
+			ProductID:        uuid.New(),
+			ProductVariantID: uuid.New(),
+			SKU:              "SKU-B",
+			Name:             "Product B",
+			Quantity:         2,
+			Price:            decimal.NewFromInt(30),
+			ShippingRateID:   &shippingRateID2,
+		},
+	}}
+
+	shippingRate1 := &cartEntities.CartShippingRate{
+		Id:          shippingRateID1,
+		Amount:      decimal.NewFromInt(5),
+		CarrierName: "Carrier 1",
+		CarrierCode: "C1",
+		ServiceType: "Standard",
+		ServiceCode: "STD",
+	}
 
-	tc.mockCart.EXPECT().
-		GetShippingRateByID(gomock.Any(), shippingRateID1).
-		Return(&cartEntities.CartShippingRate{
-			Id:          shippingRateID1,
-			Amount:      decimal.NewFromInt(5),
-			CarrierName: "Carrier 1",
-			CarrierCode: "C1",
-			ServiceType: "Standard",
-			ServiceCode: "STD",
-		}, nil)
+	shippingRate2 := &cartEntities.CartShippingRate{
+		Id:          shippingRateID2,
+		Amount:      decimal.NewFromInt(7),
+		CarrierName: "Carrier 2",
+		CarrierCode: "C2",
+		ServiceType: "Express",
+		ServiceCode: "EXP",
+	}
 
-	tc.mockCart.EXPECT().
-		GetShippingRateByID(gomock.Any(), shippingRateID2).
-		Return(&cartEntities.CartShippingRate{
-			Id:          shippingRateID2,
-			Amount:      decimal.NewFromInt(7),
-			CarrierName: "Carrier 2",
-			CarrierCode: "C2",
-			ServiceType: "Express",
-			ServiceCode: "EXP",
-		}, nil)
+	expectCartSummary(tc, cart, cartItems.Items, shippingRate1, shippingRate2)
 
 	tc.mockCustomer.EXPECT().
 		GetCustomer(gomock.Any()).
@@ -463,14 +594,14 @@ func TestCreateOrder_PerItemShipping_MultipleRates_Stripe(t *testing.T) {
 
 	tc.mockPayment.EXPECT().
 		GetProvider().
-		Return(providers.ProviderStripe).Times(2)
+		Return(providers.ProviderStripe)
 
 	tc.mockPayment.EXPECT().
 		CreatePayment(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, req stripeEntities.CreatePaymentIntentRequest) {
+		Do(func(_ context.Context, req providers.CreatePaymentRequest) {
 			assert.Equal(t, expectedTotal, req.Amount)
-			assert.Equal(t, paymentMethodID, req.PaymentMethodId)
-			assert.Equal(t, customerStripeID, *req.CustomerId)
+			assert.Equal(t, paymentMethodID, req.PaymentMethodID)
+			assert.Equal(t, customerStripeID, *req.CustomerID)
 		}).
 		Return(providers.PaymentProviderResponse{ID: "pi_multi", Status: providers.PaymentStatusPending}, nil)
 
@@ -479,8 +610,9 @@ func TestCreateOrder_PerItemShipping_MultipleRates_Stripe(t *testing.T) {
 		Return(false, nil)
 
 	tc.mockRepo.EXPECT().
-		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ uuid.UUID, order *entities.Order, items []*entities.OrderItem) {
+		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ uuid.UUID, order *entities.Order, items []*entities.OrderItem, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+			assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged, entities.TopicInventoryCreateOrder}, outboxTopics(outbox))
 			assert.Equal(t, expectedTotal, order.Total)
 			// Order-level shipping rate is set to total, but carrier fields remain nil when not provided by request
 			assert.Equal(t, decimal.NewFromInt(12), *order.ShippingRate)
@@ -510,24 +642,10 @@ func TestCreateOrder_PerItemShipping_MultipleRates_Stripe(t *testing.T) {
 		}).
 		Return(nil)
 
-	notifyDone := make(chan struct{})
-	tc.mockWebhook.EXPECT().
-		NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ *webhookEntities.NotifyOrderStatusChangeRequest) { close(notifyDone) }).
-		Return(nil)
-
-	salesforceCallDone := make(chan struct{})
-	tc.mockInventory.EXPECT().
-		CreateOrder(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ inventoryEntities.CreateInventoryOrderRequest) { close(salesforceCallDone) }).
-		Return(nil, nil)
-
 	req := &entities.CreateOrderRequest{Body: &entities.CreateOrderRequestBody{AddressID: addressID, StripePaymentMethodID: paymentMethodID}}
 	resp, err := s.CreateOrder(ctx, req)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
-	<-notifyDone
-	<-salesforceCallDone
 }
 
 func TestCreateOrder_BackCompat_OrderLevelShipping_SetsOrderFields(t *testing.T) {
@@ -558,44 +676,40 @@ func TestCreateOrder_BackCompat_OrderLevelShipping_SetsOrderFields(t *testing.T)
 			PhoneNumber: nullable.StringPtr("1234567890"),
 		}, nil)
 
-	tc.mockCart.EXPECT().
-		GetCart(gomock.Any()).
-		Return(&cartEntities.Cart{Id: cartID, TaxAmount: decimal.NewFromInt(10), TaxCurrency: "USD"}, nil)
+	cart := &cartEntities.Cart{Id: cartID, TaxAmount: decimal.NewFromInt(10), TaxCurrency: "USD"}
 
-	tc.mockCart.EXPECT().
-		GetCartItems(gomock.Any()).
-		Return(&cartEntities.GetCartItemsResponse{Items: []cartEntities.CartItemDetail{
-			{
-				ProductID:        uuid.New(),
-				ProductVariantID: uuid.New(),
-				SKU:              "SKU-X",
-				Name:             "X",
-				Quantity:         1,
-				Price:            decimal.NewFromInt(40),
-				ShippingRateID:   &shippingRateID,
-			},
-			{
-				ProductID:        uuid.New(),
-				ProductVariantID: uuid.New(),
-				SKU:              "SKU-Y",
-				Name:             "Y",
-				Quantity:         1,
-				Price:            decimal.NewFromInt(40),
-				ShippingRateID:   &shippingRateID,
-			},
-		}}, nil)
+	cartItems := &cartEntities.GetCartItemsResponse{Items: []cartEntities.CartItemDetail{
+		{
+			ProductID:        uuid.New(),
+			ProductVariantID: uuid.New(),
+			SKU:              "SKU-X",
+			Name:             "X",
+			Quantity:         1,
+			Price:            decimal.NewFromInt(40),
+			ShippingRateID:   &shippingRateID,
+		},
+		{
+			ProductID:        uuid.New(),
+			ProductVariantID: uuid.New(),
+			SKU:              "SKU-Y",
+			Name:             "Y",
+			Quantity:         1,
+			Price:            decimal.NewFromInt(40),
+			ShippingRateID:   &shippingRateID,
+		},
+	}}
+
+	// The rate is shared by both items
+	shippingRate := &cartEntities.CartShippingRate{
+		Id:          shippingRateID,
+		Amount:      decimal.NewFromInt(5),
+		CarrierName: "Carrier",
+		CarrierCode: "CARR",
+		ServiceType: "Ground",
+		ServiceCode: "GRD",
+	}
 
-	// The rate is looked up per item (twice)
-	tc.mockCart.EXPECT().
-		GetShippingRateByID(gomock.Any(), shippingRateID).
-		Return(&cartEntities.CartShippingRate{
-			Id:          shippingRateID,
-			Amount:      decimal.NewFromInt(5),
-			CarrierName: "Carrier",
-			CarrierCode: "CARR",
-			ServiceType: "Ground",
-			ServiceCode: "GRD",
-		}, nil).Times(2)
+	expectCartSummary(tc, cart, cartItems.Items, shippingRate)
 
 	tc.mockCustomer.EXPECT().
 		GetCustomer(gomock.Any()).
@@ -603,14 +717,14 @@ func TestCreateOrder_BackCompat_OrderLevelShipping_SetsOrderFields(t *testing.T)
 
 	tc.mockPayment.EXPECT().
 		GetProvider().
-		Return(providers.ProviderStripe).Times(2)
+		Return(providers.ProviderStripe)
 
 	tc.mockPayment.EXPECT().
 		CreatePayment(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, req stripeEntities.CreatePaymentIntentRequest) {
+		Do(func(_ context.Context, req providers.CreatePaymentRequest) {
 			assert.Equal(t, expectedTotal, req.Amount)
-			assert.Equal(t, paymentMethodID, req.PaymentMethodId)
-			assert.Equal(t, customerStripeID, *req.CustomerId)
+			assert.Equal(t, paymentMethodID, req.PaymentMethodID)
+			assert.Equal(t, customerStripeID, *req.CustomerID)
 		}).
 		Return(providers.PaymentProviderResponse{ID: "pi_backcompat", Status: providers.PaymentStatusPending}, nil)
 
@@ -619,8 +733,9 @@ func TestCreateOrder_BackCompat_OrderLevelShipping_SetsOrderFields(t *testing.T)
 		Return(false, nil)
 
 	tc.mockRepo.EXPECT().
-		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ uuid.UUID, order *entities.Order, items []*entities.OrderItem) {
+		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ uuid.UUID, order *entities.Order, items []*entities.OrderItem, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+			assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged, entities.TopicInventoryCreateOrder}, outboxTopics(outbox))
 			assert.Equal(t, expectedTotal, order.Total)
 			assert.Equal(t, decimal.NewFromInt(5), *order.ShippingRate)
 			// Order-level shipping fields should be set when request includes ShippingRateID
@@ -636,24 +751,10 @@ func TestCreateOrder_BackCompat_OrderLevelShipping_SetsOrderFields(t *testing.T)
 		}).
 		Return(nil)
 
-	notifyDone := make(chan struct{})
-	tc.mockWebhook.EXPECT().
-		NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ *webhookEntities.NotifyOrderStatusChangeRequest) { close(notifyDone) }).
-		Return(nil)
-
-	salesforceCallDone := make(chan struct{})
-	tc.mockInventory.EXPECT().
-		CreateOrder(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ inventoryEntities.CreateInventoryOrderRequest) { close(salesforceCallDone) }).
-		Return(nil, nil)
-
 	req := &entities.CreateOrderRequest{Body: &entities.CreateOrderRequestBody{AddressID: addressID, ShippingRateID: &shippingRateID, StripePaymentMethodID: paymentMethodID}}
 	resp, err := s.CreateOrder(ctx, req)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
-	<-notifyDone
-	<-salesforceCallDone
 }
 
 func TestCreateOrder_Error_MismatchedOrderLevelShippingRate(t *testing.T) {
@@ -680,26 +781,22 @@ func TestCreateOrder_Error_MismatchedOrderLevelShippingRate(t *testing.T) {
 			PhoneNumber: nullable.StringPtr("1234567890"),
 		}, nil)
 
-	tc.mockCart.EXPECT().
-		GetCart(gomock.Any()).
-		Return(&cartEntities.Cart{Id: cartID, TaxAmount: decimal.NewFromInt(10), TaxCurrency: "USD"}, nil)
+	cart := &cartEntities.Cart{Id: cartID, TaxAmount: decimal.NewFromInt(10), TaxCurrency: "USD"}
 
-	tc.mockCart.EXPECT().
-		GetCartItems(gomock.Any()).
-		Return(&cartEntities.GetCartItemsResponse{Items: []cartEntities.CartItemDetail{{
-			ProductID:        uuid.New(),
-			ProductVariantID: uuid.New(),
-			SKU:              "SKU-1",
-			Name:             "One",
-			Quantity:         1,
-			Price:            decimal.NewFromInt(10),
-			ShippingRateID:   &itemRateID,
-		},
-		}}, nil)
+	cartItems := &cartEntities.GetCartItemsResponse{Items: []cartEntities.CartItemDetail{{
+		ProductID:        uuid.New(),
+		ProductVariantID: uuid.New(),
+		SKU:              "SKU-1",
+		Name:             "One",
+		Quantity:         1,
+		Price:            decimal.NewFromInt(10),
+		ShippingRateID:   &itemRateID,
+	},
+	}}
 
-	tc.mockCart.EXPECT().
-		GetShippingRateByID(gomock.Any(), itemRateID).
-		Return(&cartEntities.CartShippingRate{Id: itemRateID, Amount: decimal.NewFromInt(5)}, nil)
+	itemRate := &cartEntities.CartShippingRate{Id: itemRateID, Amount: decimal.NewFromInt(5)}
+
+	expectCartSummary(tc, cart, cartItems.Items, itemRate)
 
 	req := &entities.CreateOrderRequest{Body: &entities.CreateOrderRequestBody{AddressID: addressID, ShippingRateID: &orderRateID}}
 	resp, err := s.CreateOrder(ctx, req)
@@ -735,37 +832,33 @@ func TestCreateOrder_DuplicateShippingRateIDs_SummedOnce(t *testing.T) {
 			PhoneNumber: nullable.StringPtr("1234567890"),
 		}, nil)
 
-	tc.mockCart.EXPECT().
-		GetCart(gomock.Any()).
-		Return(&cartEntities.Cart{Id: cartID, TaxAmount: decimal.NewFromInt(10), TaxCurrency: "USD"}, nil)
+	cart := &cartEntities.Cart{Id: cartID, TaxAmount: decimal.NewFromInt(10), TaxCurrency: "USD"}
 
-	tc.mockCart.EXPECT().
-		GetCartItems(gomock.Any()).
-		Return(&cartEntities.GetCartItemsResponse{Items: []cartEntities.CartItemDetail{
-			{
-				ProductID:        uuid.New(),
-				ProductVariantID: uuid.New(),
-				SKU:              "SKU-1",
-				Name:             "One",
-				Quantity:         1,
-				Price:            decimal.NewFromInt(20),
-				ShippingRateID:   &sharedRateID,
-			},
-			{
-				ProductID:        uuid.New(),
-				ProductVariantID: uuid.New(),
-				SKU:              "SKU-2",
-				Name:             "Two",
-				Quantity:         1,
-				Price:            decimal.NewFromInt(20),
-				ShippingRateID:   &sharedRateID,
-			},
-		}}, nil)
+	cartItems := &cartEntities.GetCartItemsResponse{Items: []cartEntities.CartItemDetail{
+		{
+			ProductID:        uuid.New(),
+			ProductVariantID: uuid.New(),
+			SKU:              "SKU-1",
+			Name:             "One",
+			Quantity:         1,
+			Price:            decimal.NewFromInt(20),
+			ShippingRateID:   &sharedRateID,
+		},
+		{
+			ProductID:        uuid.New(),
+			ProductVariantID: uuid.New(),
+			SKU:              "SKU-2",
+			Name:             "Two",
+			Quantity:         1,
+			Price:            decimal.NewFromInt(20),
+			ShippingRateID:   &sharedRateID,
+		},
+	}}
 
-	// Called twice (once per item), but same rate id
-	tc.mockCart.EXPECT().
-		GetShippingRateByID(gomock.Any(), sharedRateID).
-		Return(&cartEntities.CartShippingRate{Id: sharedRateID, Amount: decimal.NewFromInt(5), CarrierName: "Carrier", CarrierCode: "CARR"}, nil).Times(2)
+	// Both items share the rate id
+	sharedRate := &cartEntities.CartShippingRate{Id: sharedRateID, Amount: decimal.NewFromInt(5), CarrierName: "Carrier", CarrierCode: "CARR"}
+
+	expectCartSummary(tc, cart, cartItems.Items, sharedRate)
 
 	tc.mockCustomer.EXPECT().
 		GetCustomer(gomock.Any()).
@@ -773,11 +866,11 @@ func TestCreateOrder_DuplicateShippingRateIDs_SummedOnce(t *testing.T) {
 
 	tc.mockPayment.EXPECT().
 		GetProvider().
-		Return(providers.ProviderStripe).Times(2)
+		Return(providers.ProviderStripe)
 
 	tc.mockPayment.EXPECT().
 		CreatePayment(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, req stripeEntities.CreatePaymentIntentRequest) {
+		Do(func(_ context.Context, req providers.CreatePaymentRequest) {
 			assert.Equal(t, expectedTotal, req.Amount)
 		}).
 		Return(providers.PaymentProviderResponse{ID: "pi_dup", Status: providers.PaymentStatusPending}, nil)
@@ -787,34 +880,26 @@ func TestCreateOrder_DuplicateShippingRateIDs_SummedOnce(t *testing.T) {
 		Return(false, nil)
 
 	tc.mockRepo.EXPECT().
-		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ uuid.UUID, order *entities.Order, _ []*entities.OrderItem) {
+		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ uuid.UUID, order *entities.Order, orderItems []*entities.OrderItem, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+			assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged, entities.TopicInventoryCreateOrder}, outboxTopics(outbox))
 			assert.Equal(t, decimal.NewFromInt(5), *order.ShippingRate)
 			assert.Equal(t, expectedTotal, order.Total)
+			// the shared shipping rate and the tax are split between the items
+			for _, item := range orderItems {
+				assert.True(t, decimal.NewFromInt(5).Equal(*item.TaxAmount))
+				assert.True(t, decimal.NewFromFloat(2.5).Equal(*item.ShippingAmount))
+			}
 			// No order-level carrier fields when request ShippingRateID is not provided
 			assert.Empty(t, order.ShippingCarrierName)
 			assert.Empty(t, order.ShippingCarrierCode)
 		}).
 		Return(nil)
 
-	notifyDone := make(chan struct{})
-	tc.mockWebhook.EXPECT().
-		NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ *webhookEntities.NotifyOrderStatusChangeRequest) { close(notifyDone) }).
-		Return(nil)
-
-	salesforceCallDone := make(chan struct{})
-	tc.mockInventory.EXPECT().
-		CreateOrder(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ inventoryEntities.CreateInventoryOrderRequest) { close(salesforceCallDone) }).
-		Return(nil, nil)
-
 	req := &entities.CreateOrderRequest{Body: &entities.CreateOrderRequestBody{AddressID: addressID, StripePaymentMethodID: paymentMethodID}}
 	resp, err := s.CreateOrder(ctx, req)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
-	<-notifyDone
-	<-salesforceCallDone
 }
 
 func TestCreateOrder_NoShippingRates_TotalNoShipping(t *testing.T) {
@@ -846,44 +931,42 @@ func TestCreateOrder_NoShippingRates_TotalNoShipping(t *testing.T) {
 			PhoneNumber: nullable.StringPtr("1234567890"),
 		}, nil)
 
-	tc.mockCart.EXPECT().
-		GetCart(gomock.Any()).
-		Return(&cartEntities.Cart{Id: cartID, TaxAmount: decimal.NewFromInt(10), TaxCurrency: "USD"}, nil)
+	cart := &cartEntities.Cart{Id: cartID, TaxAmount: decimal.NewFromInt(10), TaxCurrency: "USD"}
 
-	tc.mockCart.EXPECT().
-		GetCartItems(gomock.Any()).
-		Return(&cartEntities.GetCartItemsResponse{Items: []cartEntities.CartItemDetail{
-			{
-				ProductID:        uuid.New(),
-				ProductVariantID: uuid.New(),
-				SKU:              "SKU-1",
-				Name:             "One",
-				Quantity:         2,
-				Price:            decimal.NewFromInt(25),
-			},
-			{
-				ProductID:        uuid.New(),
-				ProductVariantID: uuid.New(),
-				SKU:              "SKU-2",
-				Name:             "Two",
-				Quantity:         1,
-				Price:            decimal.NewFromInt(15),
-			},
-		}}, nil)
+	cartItems := &cartEntities.GetCartItemsResponse{Items: []cartEntities.CartItemDetail{
+		{
+			ProductID:        uuid.New(),
+			ProductVariantID: uuid.New(),
+			SKU:              "SKU-1",
+			Name:             "One",
+			Quantity:         2,
+			Price:            decimal.NewFromInt(25),
+		},
+		{
+			ProductID:        uuid.New(),
+			ProductVariantID: uuid.New(),
+			SKU:              "SKU-2",
+			Name:             "Two",
+			Quantity:         1,
+			Price:            decimal.NewFromInt(15),
+		},
+	}}
 
 	// No GetShippingRateByID expectations because there are no shipping rates
 
+	expectCartSummary(tc, cart, cartItems.Items)
+
 	tc.mockCustomer.EXPECT().
 		GetCustomer(gomock.Any()).
 		Return(&customerEntities.Customer{ID: customerID, StripeID: nullable.StringPtr(customerStripeID)}, nil)
 
 	tc.mockPayment.EXPECT().
 		GetProvider().
-		Return(providers.ProviderStripe).Times(2)
+		Return(providers.ProviderStripe)
 
 	tc.mockPayment.EXPECT().
 		CreatePayment(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, req stripeEntities.CreatePaymentIntentRequest) {
+		Do(func(_ context.Context, req providers.CreatePaymentRequest) {
 			assert.Equal(t, expectedTotal, req.Amount)
 		}).
 		Return(providers.PaymentProviderResponse{ID: "pi_noship", Status: providers.PaymentStatusPending}, nil)
@@ -893,8 +976,9 @@ func TestCreateOrder_NoShippingRates_TotalNoShipping(t *testing.T) {
 		Return(false, nil)
 
 	tc.mockRepo.EXPECT().
-		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ uuid.UUID, order *entities.Order, items []*entities.OrderItem) {
+		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ uuid.UUID, order *entities.Order, items []*entities.OrderItem, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+			assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged, entities.TopicInventoryCreateOrder}, outboxTopics(outbox))
 			assert.Equal(t, expectedTotal, order.Total)
 			// Order-level shipping not set when no shipping
 			assert.Equal(t, decimal.Zero, *order.ShippingRate)
@@ -912,24 +996,315 @@ func TestCreateOrder_NoShippingRates_TotalNoShipping(t *testing.T) {
 		}).
 		Return(nil)
 
-	notifyDone := make(chan struct{})
-	tc.mockWebhook.EXPECT().
-		NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ *webhookEntities.NotifyOrderStatusChangeRequest) { close(notifyDone) }).
-		Return(nil)
+	req := &entities.CreateOrderRequest{Body: &entities.CreateOrderRequestBody{AddressID: addressID, StripePaymentMethodID: paymentMethodID}}
+	resp, err := s.CreateOrder(ctx, req)
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+}
 
-	salesforceCallDone := make(chan struct{})
-	tc.mockInventory.EXPECT().
-		CreateOrder(gomock.Any(), gomock.Any()).
-		Do(func(_ context.Context, _ inventoryEntities.CreateInventoryOrderRequest) { close(salesforceCallDone) }).
-		Return(nil, nil)
+func TestCreateOrder_WithDiscounts(t *testing.T) {
+	tc := setupTestController(t)
+	s := newServiceUnderTest(tc)
 
-	req := &entities.CreateOrderRequest{Body: &entities.CreateOrderRequestBody{AddressID: addressID, StripePaymentMethodID: paymentMethodID}}
+	customerID := uuid.New()
+	addressID := uuid.New()
+	cartID := uuid.New()
+	shippingRateID := uuid.New()
+	promotionID := uuid.New()
+	couponCode := "SAVE10"
+
+	// subtotal: (50*2) + (20*1) = 120, minus 10 off SKU-1
+	// tax: 8, computed on the discounted items
+	// shipping: 10, waived by a free shipping promotion
+	expectedTotal := decimal.NewFromInt(118)
+
+	ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
+
+	tc.mockAddress.EXPECT().
+		GetAddress(gomock.Any(), &addressEntities.GetAddressRequest{AddressID: addressID}).
+		Return(&addressEntities.Address{FullName: "John Doe", Address: "123 Main St", StateCode: "NY", CountryCode: "US", PostalCode: "10001"}, nil)
+
+	shippingRate := &cartEntities.CartShippingRate{Id: shippingRateID, Amount: decimal.NewFromInt(10)}
+
+	tc.mockCart.EXPECT().
+		GetCartSummary(gomock.Any()).
+		Return(&cartEntities.CartSummary{
+			CartID:     cartID,
+			CouponCode: &couponCode,
+			Items: []*cartEntities.CartSummaryItem{
+				{
+					CartItemDetail: cartEntities.CartItemDetail{ProductID: uuid.New(), ProductVariantID: uuid.New(), SKU: "SKU-1", Quantity: 2, Price: decimal.NewFromInt(50), ShippingRateID: &shippingRateID},
+					LineSubtotal:   decimal.NewFromInt(100),
+					Discount:       decimal.NewFromInt(10),
+					LineTotal:      decimal.NewFromInt(90),
+					ShippingRate:   shippingRate,
+				},
+				{
+					CartItemDetail: cartEntities.CartItemDetail{ProductID: uuid.New(), ProductVariantID: uuid.New(), SKU: "SKU-2", Quantity: 1, Price: decimal.NewFromInt(20), ShippingRateID: &shippingRateID},
+					LineSubtotal:   decimal.NewFromInt(20),
+					Discount:       decimal.Zero,
+					LineTotal:      decimal.NewFromInt(20),
+					ShippingRate:   shippingRate,
+				},
+			},
+			Subtotal:         decimal.NewFromInt(120),
+			ItemsDiscount:    decimal.NewFromInt(10),
+			ShippingAmount:   decimal.NewFromInt(10),
+			ShippingDiscount: decimal.NewFromInt(10),
+			Discount:         decimal.NewFromInt(20),
+			Discounts: []*promotionsEntities.Discount{
+				{PromotionID: promotionID, Code: &couponCode, Name: "Ten off", DiscountType: promotionsEntities.FixedOff, Amount: decimal.NewFromInt(10)},
+				{PromotionID: uuid.New(), Name: "Free shipping", DiscountType: promotionsEntities.FreeShipping, Amount: decimal.NewFromInt(10)},
+			},
+			Tax:      decimal.NewFromInt(8),
+			Total:    expectedTotal,
+			Currency: "USD",
+		}, nil)
+
+	tc.mockCustomer.EXPECT().
+		GetCustomer(gomock.Any()).
+		Return(&customerEntities.Customer{ID: customerID, StripeID: nullable.StringPtr("cus_123")}, nil)
+
+	tc.mockPayment.EXPECT().
+		GetProvider().
+		Return(providers.ProviderStripe)
+
+	tc.mockPayment.EXPECT().
+		CreatePayment(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req providers.CreatePaymentRequest) {
+			assert.True(t, expectedTotal.Equal(req.Amount))
+		}).
+		Return(providers.PaymentProviderResponse{ID: "pi_123", Status: providers.PaymentStatusPending}, nil)
+
+	tc.mockRepo.EXPECT().
+		OrderReferenceExists(gomock.Any(), gomock.Any()).
+		Return(false, nil)
+
+	tc.mockRepo.EXPECT().
+		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ uuid.UUID, order *entities.Order, items []*entities.OrderItem, _ entities.OrderEventSource, _ []*entities.OutboxMessage) {
+			assert.True(t, expectedTotal.Equal(order.Total))
+			assert.True(t, decimal.NewFromInt(120).Equal(order.Subtotal))
+			assert.True(t, decimal.NewFromInt(20).Equal(order.DiscountAmount))
+			assert.Len(t, order.Discounts, 2)
+			assert.Equal(t, order.ID, order.Discounts[0].OrderID)
+			assert.Equal(t, promotionID, order.Discounts[0].PromotionID)
+
+			assert.True(t, decimal.NewFromInt(10).Equal(*items[0].DiscountAmount))
+			assert.Nil(t, items[1].DiscountAmount)
+			// the tax is split by the discounted line subtotals, 90 and 20
+			assert.Equal(t, "6.55", items[0].TaxAmount.String())
+			assert.Equal(t, "1.45", items[1].TaxAmount.String())
+			// nothing was paid for shipping
+			assert.True(t, items[0].ShippingAmount.IsZero())
+			assert.True(t, items[1].ShippingAmount.IsZero())
+		}).
+		Return(nil)
+
+	req := &entities.CreateOrderRequest{Body: &entities.CreateOrderRequestBody{AddressID: addressID, StripePaymentMethodID: "pm_123"}}
 	resp, err := s.CreateOrder(ctx, req)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
-	<-notifyDone
-	<-salesforceCallDone
+}
+
+func TestCreateOrder_CouponNoLongerValid(t *testing.T) {
+	tc := setupTestController(t)
+	s := newServiceUnderTest(tc)
+
+	addressID := uuid.New()
+	ctx := sharedMeta.WithXCustomerID(context.Background(), uuid.New().String())
+
+	tc.mockAddress.EXPECT().
+		GetAddress(gomock.Any(), gomock.Any()).
+		Return(&addressEntities.Address{}, nil)
+
+	// the cart can't be summarized while its coupon code is applied
+	tc.mockCart.EXPECT().
+		GetCartSummary(gomock.Any()).
+		Return(nil, promotionsErrors.NewAPIError("PROMOTION_USAGE_LIMIT_REACHED"))
+
+	req := &entities.CreateOrderRequest{Body: &entities.CreateOrderRequestBody{AddressID: addressID, StripePaymentMethodID: "pm_123"}}
+	resp, err := s.CreateOrder(ctx, req)
+
+	assert.Nil(t, resp)
+	assert.Equal(t, promotionsErrors.NewAPIError("PROMOTION_USAGE_LIMIT_REACHED"), err)
+}
+
+func TestCreateOrder_PromotionLimitReached(t *testing.T) {
+	tc := setupTestController(t)
+	s := newServiceUnderTest(tc)
+
+	customerID := uuid.New()
+	addressID := uuid.New()
+	cartID := uuid.New()
+	couponCode := "SAVE10"
+
+	ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
+
+	tc.mockAddress.EXPECT().
+		GetAddress(gomock.Any(), gomock.Any()).
+		Return(&addressEntities.Address{FullName: "John Doe", Address: "123 Main St", StateCode: "NY", CountryCode: "US", PostalCode: "10001"}, nil)
+
+	tc.mockCart.EXPECT().
+		GetCartSummary(gomock.Any()).
+		Return(&cartEntities.CartSummary{
+			CartID:     cartID,
+			CouponCode: &couponCode,
+			Items: []*cartEntities.CartSummaryItem{
+				{
+					CartItemDetail: cartEntities.CartItemDetail{ProductID: uuid.New(), ProductVariantID: uuid.New(), SKU: "SKU-1", Quantity: 1, Price: decimal.NewFromInt(50)},
+					LineSubtotal:   decimal.NewFromInt(50),
+					Discount:       decimal.NewFromInt(10),
+					LineTotal:      decimal.NewFromInt(40),
+				},
+			},
+			Subtotal:      decimal.NewFromInt(50),
+			ItemsDiscount: decimal.NewFromInt(10),
+			Discount:      decimal.NewFromInt(10),
+			Discounts: []*promotionsEntities.Discount{
+				{PromotionID: uuid.New(), Code: &couponCode, Name: "Ten off", DiscountType: promotionsEntities.FixedOff, Amount: decimal.NewFromInt(10)},
+			},
+			Total:    decimal.NewFromInt(40),
+			Currency: "USD",
+		}, nil)
+
+	tc.mockCustomer.EXPECT().
+		GetCustomer(gomock.Any()).
+		Return(&customerEntities.Customer{ID: customerID, StripeID: nullable.StringPtr("cus_123")}, nil)
+
+	tc.mockPayment.EXPECT().
+		GetProvider().
+		Return(providers.ProviderStripe)
+
+	tc.mockPayment.EXPECT().
+		CreatePayment(gomock.Any(), gomock.Any()).
+		Return(providers.PaymentProviderResponse{ID: "pi_123", Status: providers.PaymentStatusSuccess}, nil)
+
+	tc.mockRepo.EXPECT().
+		OrderReferenceExists(gomock.Any(), gomock.Any()).
+		Return(false, nil)
+
+	// another checkout used the last redemption of the coupon in the meantime
+	tc.mockRepo.EXPECT().
+		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(moduleErrors.NewAPIError("ORDER_PROMOTION_LIMIT_REACHED"))
+
+	tc.mockPayment.EXPECT().
+		Refund(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, refundReq providers.RefundRequest) {
+			assert.Equal(t, "pi_123", refundReq.PaymentID)
+			assert.NotEmpty(t, refundReq.IdempotencyKey)
+		}).
+		Return(&providers.RefundResponse{ID: "re_123", Status: providers.RefundStatusSucceeded}, nil)
+
+	req := &entities.CreateOrderRequest{Body: &entities.CreateOrderRequestBody{AddressID: addressID, StripePaymentMethodID: "pm_123"}}
+	resp, err := s.CreateOrder(ctx, req)
+
+	assert.Nil(t, resp)
+	assert.Equal(t, moduleErrors.NewAPIError("ORDER_PROMOTION_LIMIT_REACHED"), err)
+}
+
+func TestCreateOrder_GuestCheckout(t *testing.T) {
+	tc := setupTestController(t)
+	s := newServiceUnderTest(tc)
+
+	guestID := uuid.New()
+	cartID := uuid.New()
+	paymentMethodID := "pm_123"
+	ctx := sharedMeta.WithXCartToken(context.Background(), uuid.New().String())
+
+	cart := &cartEntities.Cart{Id: cartID, TaxAmount: decimal.NewFromInt(5), TaxCurrency: "USD"}
+	items := []cartEntities.CartItemDetail{
+		{ProductID: uuid.New(), ProductVariantID: uuid.New(), SKU: "SKU123", Name: "Test Product", Quantity: 1, Price: decimal.NewFromInt(50)},
+	}
+
+	expectCartSummary(tc, cart, items)
+
+	// the order of a guest is placed for the guest customer of their email
+	tc.mockCustomer.EXPECT().
+		GetOrCreateGuestCustomer(gomock.Any(), "guest@example.com", "Jane Doe").
+		Return(&customerEntities.Customer{ID: guestID, Email: "guest@example.com", FirstName: "Jane", IsGuest: true}, nil)
+
+	tc.mockPayment.EXPECT().
+		GetProvider().
+		Return(providers.ProviderStripe)
+
+	tc.mockPayment.EXPECT().
+		CreatePayment(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req providers.CreatePaymentRequest) {
+			// guests pay with the payment method alone
+			assert.Nil(t, req.CustomerID)
+			assert.Equal(t, paymentMethodID, req.PaymentMethodID)
+		}).
+		Return(providers.PaymentProviderResponse{ID: "pi_123", Status: providers.PaymentStatusPending}, nil)
+
+	tc.mockRepo.EXPECT().
+		OrderReferenceExists(gomock.Any(), gomock.Any()).
+		Return(false, nil)
+
+	tc.mockRepo.EXPECT().
+		CreateOrder(gomock.Any(), cartID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ uuid.UUID, order *entities.Order, _ []*entities.OrderItem, _ entities.OrderEventSource, _ []*entities.OutboxMessage) {
+			assert.Equal(t, guestID, order.CustomerID)
+			assert.Equal(t, "Jane Doe", order.DeliveryFullName)
+			assert.Equal(t, "1 Main St", order.DeliveryAddress)
+			assert.Equal(t, "90000", order.DeliveryPostalCode)
+			assert.True(t, order.Total.Equal(decimal.NewFromInt(55)))
+		}).
+		Return(nil)
+
+	req := &entities.CreateOrderRequest{
+		Body: &entities.CreateOrderRequestBody{
+			StripePaymentMethodID: paymentMethodID,
+			Email:                 "guest@example.com",
+			Address: &addressEntities.AddressRequestBody{
+				FullName: "Jane Doe", Address: "1 Main St", StateCode: "CA", CountryCode: "US", PostalCode: "90000",
+			},
+		},
+	}
+
+	resp, err := s.CreateOrder(ctx, req)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.OrderReference)
+}
+
+func TestCreateOrder_GuestCheckoutRequiresEmailAndAddress(t *testing.T) {
+	ctx := sharedMeta.WithXCartToken(context.Background(), uuid.New().String())
+	address := &addressEntities.AddressRequestBody{FullName: "Jane Doe", Address: "1 Main St", CountryCode: "US"}
+
+	tests := []struct {
+		name    string
+		body    *entities.CreateOrderRequestBody
+		wantErr error
+	}{
+		{
+			name:    "email required",
+			body:    &entities.CreateOrderRequestBody{Address: address},
+			wantErr: moduleErrors.NewAPIError("ORDER_GUEST_EMAIL_REQUIRED"),
+		},
+		{
+			name:    "email invalid",
+			body:    &entities.CreateOrderRequestBody{Email: "not-an-email", Address: address},
+			wantErr: moduleErrors.NewAPIError("ORDER_GUEST_EMAIL_REQUIRED"),
+		},
+		{
+			name:    "address required",
+			body:    &entities.CreateOrderRequestBody{Email: "guest@example.com"},
+			wantErr: moduleErrors.NewAPIError("ORDER_GUEST_ADDRESS_REQUIRED"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServiceUnderTest(setupTestController(t))
+
+			resp, err := s.CreateOrder(ctx, &entities.CreateOrderRequest{Body: tt.body})
+
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
 }
 
 func TestProcessPaymentSucceeded_WithStripe(t *testing.T) {
@@ -946,23 +1321,21 @@ func TestProcessPaymentSucceeded_WithStripe(t *testing.T) {
 
 		ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderStripe)
-
 		tc.mockRepo.EXPECT().
-			GetOrderByStripePaymentIntentID(gomock.Any(), paymentID).
+			GetOrderByPaymentID(gomock.Any(), providers.ProviderStripe, paymentID).
 			Return(&entities.Order{
-				ID:                    orderID,
-				CustomerID:            customerID,
-				StripePaymentIntentID: nullable.StringPtr(paymentID),
-				Status:                entities.Pending,
-				SalesforceID:          salesforceID,
+				ID:              orderID,
+				CustomerID:      customerID,
+				PaymentProvider: providers.ProviderStripe,
+				PaymentID:       nullable.StringPtr(paymentID),
+				Status:          entities.Pending,
+				SalesforceID:    salesforceID,
 			}, nil)
 
 		tc.mockRepo.EXPECT().
-			Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, updates map[string]interface{}, orderID string, customerID string) {
+			Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, updates map[string]interface{}, orderID string, customerID string, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged, entities.TopicInventoryUpdateOrderStatus}, outboxTopics(outbox))
 				assert.Equal(t, orderID, orderID)
 				assert.Equal(t, customerID, customerID)
 				assert.Equal(t, entities.PaymentSuccess, updates["status"])
@@ -984,27 +1357,75 @@ func TestProcessPaymentSucceeded_WithStripe(t *testing.T) {
 				},
 			}, nil)
 
-		notifyCallDone := make(chan struct{})
-		tc.mockWebhook.EXPECT().
-			NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, req *webhookEntities.NotifyOrderStatusChangeRequest) {
-				assert.Equal(t, customerID.String(), req.CustomerID)
-				assert.Equal(t, entities.PaymentSuccess.String(), req.Status)
-				close(notifyCallDone)
+		tc.mockProduct.EXPECT().
+			GetProductVariantByID(gomock.Any(), gomock.Any()).
+			Return(&productEntities.ProductVariant{
+				ID:        productVariantID,
+				ProductID: productID,
+			}, nil)
+
+		tc.mockWishlist.EXPECT().
+			BulkRemoveFromWishlist(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, req *wishlistEntities.BulkRemoveFromWishlistRequest) {
+				assert.Equal(t, customerID, req.CustomerID)
+				assert.Equal(t, []uuid.UUID{productID}, req.ProductIDs)
 			}).
 			Return(nil)
 
-		salesforceCallDone := make(chan struct{})
-		tc.mockInventory.EXPECT().
-			UpdateOrderStatus(gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, req inventoryEntities.UpdateInventoryOrderStatusRequest) {
-				assert.Equal(t, salesforceID, req.Order.SalesforceID)
-				assert.Equal(t, salesforceID, *req.Customer.SalesforceID)
-				assert.Equal(t, entities.PaymentSuccess.String(), req.Status)
-				close(salesforceCallDone)
+		err := s.ProcessPaymentSucceeded(ctx, providers.ProviderStripe, paymentID)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("success after customer action", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		customerID := uuid.New()
+		paymentID := "pi_123"
+		orderID := uuid.New()
+		salesforceID := "123456"
+		productID := uuid.New()
+		productVariantID := uuid.New()
+
+		ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
+
+		tc.mockRepo.EXPECT().
+			GetOrderByPaymentID(gomock.Any(), providers.ProviderStripe, paymentID).
+			Return(&entities.Order{
+				ID:              orderID,
+				CustomerID:      customerID,
+				PaymentProvider: providers.ProviderStripe,
+				PaymentID:       nullable.StringPtr(paymentID),
+				Status:          entities.RequiresAction,
+				SalesforceID:    salesforceID,
+			}, nil)
+
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, updates map[string]interface{}, orderID string, customerID string, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged, entities.TopicInventoryUpdateOrderStatus}, outboxTopics(outbox))
+				assert.Equal(t, orderID, orderID)
+				assert.Equal(t, customerID, customerID)
+				assert.Equal(t, entities.PaymentSuccess, updates["status"])
 			}).
 			Return(nil)
 
+		tc.mockCustomer.EXPECT().
+			GetCustomerByID(gomock.Any(), customerID.String()).
+			Return(&customerEntities.Customer{
+				ID:           customerID,
+				SalesforceID: nullable.StringPtr(salesforceID),
+			}, nil)
+
+		tc.mockRepo.EXPECT().
+			GetOrderItemsByID(gomock.Any(), orderID).
+			Return([]*entities.OrderItem{
+				{
+					ProductID: productID,
+				},
+			}, nil)
+
 		tc.mockProduct.EXPECT().
 			GetProductVariantByID(gomock.Any(), gomock.Any()).
 			Return(&productEntities.ProductVariant{
@@ -1020,12 +1441,9 @@ func TestProcessPaymentSucceeded_WithStripe(t *testing.T) {
 			}).
 			Return(nil)
 
-		err := s.ProcessPaymentSucceeded(ctx, paymentID)
+		err := s.ProcessPaymentSucceeded(ctx, providers.ProviderStripe, paymentID)
 
 		assert.NoError(t, err)
-		// wait for async calls to be done
-		<-notifyCallDone
-		<-salesforceCallDone
 	})
 
 	t.Run("error to get order by payment id", func(t *testing.T) {
@@ -1037,15 +1455,11 @@ func TestProcessPaymentSucceeded_WithStripe(t *testing.T) {
 
 		ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderStripe)
-
 		tc.mockRepo.EXPECT().
-			GetOrderByStripePaymentIntentID(gomock.Any(), paymentID).
+			GetOrderByPaymentID(gomock.Any(), providers.ProviderStripe, paymentID).
 			Return(nil, errors.New("order not found"))
 
-		err := s.ProcessPaymentSucceeded(ctx, paymentID)
+		err := s.ProcessPaymentSucceeded(ctx, providers.ProviderStripe, paymentID)
 
 		assert.ErrorContains(t, err, moduleErrors.NewAPIError("ORDER_NOT_FOUND_BY_PAYMENT_ID").Error())
 	})
@@ -1065,23 +1479,21 @@ func TestProcessPaymentSucceeded_WithAuthorizeNet(t *testing.T) {
 
 		ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderAuthorizeNet)
-
 		tc.mockRepo.EXPECT().
-			GetOrderByAuthorizeNetPaymentID(gomock.Any(), paymentID).
+			GetOrderByPaymentID(gomock.Any(), providers.ProviderAuthorizeNet, paymentID).
 			Return(&entities.Order{
-				ID:                    orderID,
-				CustomerID:            customerID,
-				AuthorizeNetPaymentID: nullable.StringPtr(paymentID),
-				Status:                entities.Pending,
-				SalesforceID:          salesforceID,
+				ID:              orderID,
+				CustomerID:      customerID,
+				PaymentProvider: providers.ProviderAuthorizeNet,
+				PaymentID:       nullable.StringPtr(paymentID),
+				Status:          entities.Pending,
+				SalesforceID:    salesforceID,
 			}, nil)
 
 		tc.mockRepo.EXPECT().
-			Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, updates map[string]interface{}, orderID string, customerID string) {
+			Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, updates map[string]interface{}, orderID string, customerID string, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged, entities.TopicInventoryUpdateOrderStatus}, outboxTopics(outbox))
 				assert.Equal(t, orderID, orderID)
 				assert.Equal(t, customerID, customerID)
 				assert.Equal(t, entities.PaymentSuccess, updates["status"])
@@ -1103,27 +1515,6 @@ func TestProcessPaymentSucceeded_WithAuthorizeNet(t *testing.T) {
 				},
 			}, nil)
 
-		notifyCallDone := make(chan struct{})
-		tc.mockWebhook.EXPECT().
-			NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, req *webhookEntities.NotifyOrderStatusChangeRequest) {
-				assert.Equal(t, customerID.String(), req.CustomerID)
-				assert.Equal(t, entities.PaymentSuccess.String(), req.Status)
-				close(notifyCallDone)
-			}).
-			Return(nil)
-
-		salesforceCallDone := make(chan struct{})
-		tc.mockInventory.EXPECT().
-			UpdateOrderStatus(gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, req inventoryEntities.UpdateInventoryOrderStatusRequest) {
-				assert.Equal(t, salesforceID, req.Order.SalesforceID)
-				assert.Equal(t, salesforceID, *req.Customer.SalesforceID)
-				assert.Equal(t, entities.PaymentSuccess.String(), req.Status)
-				close(salesforceCallDone)
-			}).
-			Return(nil)
-
 		tc.mockProduct.EXPECT().
 			GetProductVariantByID(gomock.Any(), gomock.Any()).
 			Return(&productEntities.ProductVariant{
@@ -1139,12 +1530,30 @@ func TestProcessPaymentSucceeded_WithAuthorizeNet(t *testing.T) {
 			}).
 			Return(nil)
 
-		err := s.ProcessPaymentSucceeded(ctx, paymentID)
+		err := s.ProcessPaymentSucceeded(ctx, providers.ProviderAuthorizeNet, paymentID)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("payment approved when the order was placed", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		paymentID := "123456"
+
+		tc.mockRepo.EXPECT().
+			GetOrderByPaymentID(gomock.Any(), providers.ProviderAuthorizeNet, paymentID).
+			Return(&entities.Order{
+				ID:              uuid.New(),
+				CustomerID:      uuid.New(),
+				PaymentProvider: providers.ProviderAuthorizeNet,
+				PaymentID:       nullable.StringPtr(paymentID),
+				Status:          entities.PaymentSuccess,
+			}, nil)
+
+		err := s.ProcessPaymentSucceeded(context.Background(), providers.ProviderAuthorizeNet, paymentID)
 
 		assert.NoError(t, err)
-		// wait for async calls to be done
-		<-notifyCallDone
-		<-salesforceCallDone
 	})
 
 	t.Run("error to get order by payment id", func(t *testing.T) {
@@ -1156,20 +1565,245 @@ func TestProcessPaymentSucceeded_WithAuthorizeNet(t *testing.T) {
 
 		ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderAuthorizeNet)
-
 		tc.mockRepo.EXPECT().
-			GetOrderByAuthorizeNetPaymentID(gomock.Any(), paymentID).
+			GetOrderByPaymentID(gomock.Any(), providers.ProviderAuthorizeNet, paymentID).
 			Return(nil, errors.New("order not found"))
 
-		err := s.ProcessPaymentSucceeded(ctx, paymentID)
+		err := s.ProcessPaymentSucceeded(ctx, providers.ProviderAuthorizeNet, paymentID)
 
 		assert.ErrorContains(t, err, moduleErrors.NewAPIError("ORDER_NOT_FOUND_BY_PAYMENT_ID").Error())
 	})
 }
 
+func TestCancelOrder(t *testing.T) {
+	t.Run("success cancelling pending order", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		customerID := uuid.New()
+		ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
+
+		tc.mockRepo.EXPECT().
+			GetOrderByID(gomock.Any(), orderID).
+			Return(&entities.Order{ID: orderID, CustomerID: customerID, Status: entities.Pending}, nil)
+
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), orderID.String(), customerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _, _ string, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, entities.Cancelled, data["status"])
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicInventoryUpdateOrderStatus}, outboxTopics(outbox))
+			}).
+			Return(nil)
+
+		err := s.CancelOrder(ctx, &entities.CancelOrderRequest{OrderID: orderID})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("success cancelling order requiring action", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		customerID := uuid.New()
+		ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
+
+		tc.mockRepo.EXPECT().
+			GetOrderByID(gomock.Any(), orderID).
+			Return(&entities.Order{ID: orderID, CustomerID: customerID, Status: entities.RequiresAction}, nil)
+
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), orderID.String(), customerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _, _ string, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, entities.Cancelled, data["status"])
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicInventoryUpdateOrderStatus}, outboxTopics(outbox))
+			}).
+			Return(nil)
+
+		err := s.CancelOrder(ctx, &entities.CancelOrderRequest{OrderID: orderID})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("success refunding paid order", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		customerID := uuid.New()
+		paymentIntentID := "pi_123"
+		refundID := "re_123"
+		itemID := uuid.New()
+		refundedItemID := uuid.New()
+		ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
+
+		tc.mockRepo.EXPECT().
+			GetOrderByID(gomock.Any(), orderID).
+			Return(&entities.Order{
+				ID:              orderID,
+				CustomerID:      customerID,
+				Status:          entities.PaymentSuccess,
+				PaymentProvider: providers.ProviderStripe,
+				PaymentID:       &paymentIntentID,
+			}, nil)
+
+		tc.mockRepo.EXPECT().
+			GetOrderItemsByID(gomock.Any(), orderID).
+			Return([]*entities.OrderItem{
+				{ID: itemID, OrderID: orderID, Price: decimal.NewFromInt(10), Quantity: 2},
+				{ID: refundedItemID, OrderID: orderID, Price: decimal.NewFromInt(5), Quantity: 1, Status: entities.ItemRefunded},
+			}, nil)
+
+		tc.mockPayment.EXPECT().
+			Refund(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, refundReq providers.RefundRequest) {
+				assert.Equal(t, paymentIntentID, refundReq.PaymentID)
+				// the whole remaining amount is refunded
+				assert.True(t, refundReq.Amount.IsZero())
+				assert.Equal(t, providerIdempotencyKey(idempotencyScopeCancelOrder, orderID.String()), refundReq.IdempotencyKey)
+			}).
+			Return(&providers.RefundResponse{ID: refundID, Status: stripeEntities.StripeRefundPending}, nil)
+
+		tc.mockRepo.EXPECT().
+			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}, source entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, entities.CancelRefundPending, orderData["status"])
+				assert.Equal(t, refundID, orderData["refund_id"])
+				assert.Equal(t, entities.ActorCustomer, source.Actor)
+
+				assert.Len(t, itemsData, 1)
+				itemData := itemsData[itemID.String()].(map[string]interface{})
+				assert.Equal(t, entities.ItemInitiatedRefund.String(), itemData["status"])
+				assert.Equal(t, refundID, itemData["refund_id"])
+				assert.Equal(t, float64(20), itemData["refund_amount"])
+
+				// the webhook is only notified once the refund is confirmed
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicInventoryUpdateOrderStatus}, outboxTopics(outbox))
+			}).
+			Return(nil)
+
+		err := s.CancelOrder(ctx, &entities.CancelOrderRequest{OrderID: orderID})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("error refunding paid order", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		customerID := uuid.New()
+		paymentIntentID := "pi_123"
+		ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
+
+		tc.mockRepo.EXPECT().
+			GetOrderByID(gomock.Any(), orderID).
+			Return(&entities.Order{
+				ID:              orderID,
+				CustomerID:      customerID,
+				Status:          entities.PaymentSuccess,
+				PaymentProvider: providers.ProviderStripe,
+				PaymentID:       &paymentIntentID,
+			}, nil)
+
+		tc.mockRepo.EXPECT().
+			GetOrderItemsByID(gomock.Any(), orderID).
+			Return([]*entities.OrderItem{{ID: uuid.New(), OrderID: orderID}}, nil)
+
+		tc.mockPayment.EXPECT().
+			Refund(gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("stripe error"))
+
+		err := s.CancelOrder(ctx, &entities.CancelOrderRequest{OrderID: orderID})
+
+		assert.ErrorContains(t, err, "Error refunding order payment")
+	})
+
+	t.Run("cancel again after the refund failed", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		paymentIntentID := "pi_123"
+		order := &entities.Order{
+			ID:              uuid.New(),
+			CustomerID:      uuid.New(),
+			Status:          entities.PaymentSuccess,
+			PaymentProvider: providers.ProviderStripe,
+			PaymentID:       &paymentIntentID,
+		}
+		itemID := uuid.New()
+		ctx := sharedMeta.WithXCustomerID(context.Background(), order.CustomerID.String())
+
+		var keys []string
+		expectCancel := func(refundID string) {
+			tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), order.ID).Return(order, nil)
+			tc.mockRepo.EXPECT().
+				GetOrderItemsByID(gomock.Any(), order.ID).
+				Return([]*entities.OrderItem{{ID: itemID, OrderID: order.ID, Price: decimal.NewFromInt(10), Quantity: 1, Status: entities.ItemPending}}, nil)
+			tc.mockPayment.EXPECT().
+				Refund(gomock.Any(), gomock.Any()).
+				Do(func(_ context.Context, refundReq providers.RefundRequest) {
+					keys = append(keys, refundReq.IdempotencyKey)
+				}).
+				Return(&providers.RefundResponse{ID: refundID, Status: stripeEntities.StripeRefundPending}, nil)
+			tc.mockRepo.EXPECT().
+				UpdateOrderWithOrderItems(gomock.Any(), order.ID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, _ map[string]interface{}, _ entities.OrderEventSource, _ []*entities.OutboxMessage) {
+					order.Status = orderData["status"].(entities.OrderStatus)
+					id := orderData["refund_id"].(string)
+					order.RefundID = &id
+				}).
+				Return(nil)
+		}
+
+		// cancel
+		expectCancel("re_failed")
+		assert.NoError(t, s.CancelOrder(ctx, &entities.CancelOrderRequest{OrderID: order.ID}))
+		assert.Equal(t, entities.CancelRefundPending, order.Status)
+
+		// refund.failed
+		tc.mockRepo.EXPECT().
+			GetOrderItemsByRefundID(gomock.Any(), "re_failed").
+			Return([]*entities.OrderItem{{ID: itemID, OrderID: order.ID, Status: entities.ItemInitiatedRefund, RefundID: "re_failed"}}, nil)
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), order.ID).Return(order, nil)
+		tc.mockRepo.EXPECT().GetOrderEvents(gomock.Any(), order.ID).Return([]*entities.OrderEvent{}, nil)
+		tc.mockRepo.EXPECT().
+			UpdateOrderWithOrderItems(gomock.Any(), order.ID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, _ map[string]interface{}, _ entities.OrderEventSource, _ []*entities.OutboxMessage) {
+				order.Status = orderData["status"].(entities.OrderStatus)
+			}).
+			Return(nil)
+		assert.NoError(t, s.ProcessRefundFailed(context.Background(), "re_failed", "expired_or_canceled_card"))
+		assert.Equal(t, entities.PaymentSuccess, order.Status)
+
+		// cancel again, the refund isn't replayed by the provider
+		expectCancel("re_456")
+		assert.NoError(t, s.CancelOrder(ctx, &entities.CancelOrderRequest{OrderID: order.ID}))
+		assert.Equal(t, entities.CancelRefundPending, order.Status)
+
+		assert.Len(t, keys, 2)
+		assert.NotEqual(t, keys[0], keys[1])
+	})
+
+	t.Run("error order already cancelled", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		customerID := uuid.New()
+		ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
+
+		tc.mockRepo.EXPECT().
+			GetOrderByID(gomock.Any(), orderID).
+			Return(&entities.Order{ID: orderID, CustomerID: customerID, Status: entities.CancelRefundPending}, nil)
+
+		err := s.CancelOrder(ctx, &entities.CancelOrderRequest{OrderID: orderID})
+
+		assert.ErrorContains(t, err, "Order is already cancelled.")
+	})
+}
+
 func TestUpdateOrder(t *testing.T) {
 	t.Run("success with status change", func(t *testing.T) {
 		tc := setupTestController(t)
@@ -1196,23 +1830,13 @@ func TestUpdateOrder(t *testing.T) {
 			Return(existingOrder, nil)
 
 		tc.mockRepo.EXPECT().
-			Update(gomock.Any(), gomock.Any(), orderID.String(), customerID.String()).
-			Do(func(_ context.Context, data map[string]interface{}, orderID, customerID string) {
-				status, ok := data["status"].(*string)
+			Update(gomock.Any(), gomock.Any(), orderID.String(), customerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, orderID, customerID string, source entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged}, outboxTopics(outbox))
+				status, ok := data["status"].(entities.OrderStatus)
 				assert.True(t, ok)
-				assert.Equal(t, newStatus, *status)
-			}).
-			Return(nil)
-
-		notifyCallDone := make(chan struct{})
-		tc.mockWebhook.EXPECT().
-			NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, req *webhookEntities.NotifyOrderStatusChangeRequest) {
-				assert.Equal(t, customerID.String(), req.CustomerID)
-				assert.Equal(t, orderID.String(), req.OrderID)
-				assert.Equal(t, orderRef, req.OrderReference)
-				assert.Equal(t, newStatus, req.Status)
-				close(notifyCallDone)
+				assert.Equal(t, newStatus, status.String())
+				assert.Equal(t, entities.ActorAdmin, source.Actor)
 			}).
 			Return(nil)
 
@@ -1228,8 +1852,6 @@ func TestUpdateOrder(t *testing.T) {
 		err := s.UpdateOrder(ctx, req)
 
 		assert.NoError(t, err)
-		// wait for async notify call to be done
-		<-notifyCallDone
 	})
 
 	t.Run("success without status change", func(t *testing.T) {
@@ -1256,19 +1878,15 @@ func TestUpdateOrder(t *testing.T) {
 			Return(existingOrder, nil)
 
 		tc.mockRepo.EXPECT().
-			Update(gomock.Any(), gomock.Any(), orderID.String(), customerID.String()).
-			Do(func(_ context.Context, data map[string]interface{}, orderID, customerID string) {
+			Update(gomock.Any(), gomock.Any(), orderID.String(), customerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, orderID, customerID string, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Empty(t, outbox)
 				// status should not be in the update data
 				_, hasStatus := data["status"]
 				assert.False(t, hasStatus)
 			}).
 			Return(nil)
 
-		// No webhook notification should be called since status didn't change
-		tc.mockWebhook.EXPECT().
-			NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
-			Times(0)
-
 		req := &entities.UpdateOrderRequest{
 			OrderReference: orderRef,
 			Body: &entities.UpdateOrderRequestBody{
@@ -1306,8 +1924,9 @@ func TestUpdateOrder(t *testing.T) {
 			Return(existingOrder, nil)
 
 		tc.mockRepo.EXPECT().
-			Update(gomock.Any(), gomock.Any(), orderID.String(), customerID.String()).
-			Do(func(_ context.Context, data map[string]interface{}, orderID, customerID string) {
+			Update(gomock.Any(), gomock.Any(), orderID.String(), customerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, orderID, customerID string, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Empty(t, outbox)
 				items, ok := data["items"].([]map[string]interface{})
 				assert.True(t, ok)
 				assert.Len(t, items, 1)
@@ -1322,21 +1941,84 @@ func TestUpdateOrder(t *testing.T) {
 		req := &entities.UpdateOrderRequest{
 			OrderReference: orderRef,
 			Body: &entities.UpdateOrderRequestBody{
-				Items: []*entities.Item{
-					{
-						ID:     itemID.String(),
-						Status: &itemStatus,
-					},
-				},
+				Items: []*entities.Item{
+					{
+						ID:     itemID.String(),
+						Status: &itemStatus,
+					},
+				},
+			},
+		}
+
+		err := s.UpdateOrder(ctx, req)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("error getting order by reference", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderRef := "ORD123456"
+		ctx := context.Background()
+
+		tc.mockRepo.EXPECT().
+			GetOrderByReference(gomock.Any(), orderRef).
+			Return(nil, errors.New("order not found"))
+
+		req := &entities.UpdateOrderRequest{
+			OrderReference: orderRef,
+			Body: &entities.UpdateOrderRequestBody{
+				Status: nullable.StringPtr("shipped"),
+			},
+		}
+
+		err := s.UpdateOrder(ctx, req)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "order not found")
+	})
+
+	t.Run("error updating order", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		customerID := uuid.New()
+		orderRef := "ORD123456"
+		newStatus := "shipped"
+
+		ctx := context.Background()
+
+		existingOrder := &entities.Order{
+			ID:             orderID,
+			CustomerID:     customerID,
+			OrderReference: orderRef,
+			Status:         entities.PaymentSuccess,
+		}
+
+		tc.mockRepo.EXPECT().
+			GetOrderByReference(gomock.Any(), orderRef).
+			Return(existingOrder, nil)
+
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), orderID.String(), customerID.String(), gomock.Any(), gomock.Any()).
+			Return(errors.New("database error"))
+
+		req := &entities.UpdateOrderRequest{
+			OrderReference: orderRef,
+			Body: &entities.UpdateOrderRequestBody{
+				Status: &newStatus,
 			},
 		}
 
 		err := s.UpdateOrder(ctx, req)
 
-		assert.NoError(t, err)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "database error")
 	})
 
-	t.Run("error getting order by reference", func(t *testing.T) {
+	t.Run("error invalid status", func(t *testing.T) {
 		tc := setupTestController(t)
 		s := newServiceUnderTest(tc)
 
@@ -1345,58 +2027,122 @@ func TestUpdateOrder(t *testing.T) {
 
 		tc.mockRepo.EXPECT().
 			GetOrderByReference(gomock.Any(), orderRef).
-			Return(nil, errors.New("order not found"))
+			Return(&entities.Order{
+				ID:             uuid.New(),
+				CustomerID:     uuid.New(),
+				OrderReference: orderRef,
+				Status:         entities.PaymentSuccess,
+			}, nil)
+
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(0)
 
 		req := &entities.UpdateOrderRequest{
 			OrderReference: orderRef,
 			Body: &entities.UpdateOrderRequestBody{
-				Status: nullable.StringPtr("shipped"),
+				Status: nullable.StringPtr("lost_in_space"),
 			},
 		}
 
 		err := s.UpdateOrder(ctx, req)
 
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "order not found")
+		assert.ErrorContains(t, err, "Invalid order status: lost_in_space")
 	})
 
-	t.Run("error updating order", func(t *testing.T) {
+	t.Run("error illegal status transition", func(t *testing.T) {
 		tc := setupTestController(t)
 		s := newServiceUnderTest(tc)
 
-		orderID := uuid.New()
-		customerID := uuid.New()
 		orderRef := "ORD123456"
-		newStatus := "shipped"
-
 		ctx := context.Background()
 
-		existingOrder := &entities.Order{
-			ID:             orderID,
-			CustomerID:     customerID,
-			OrderReference: orderRef,
-			Status:         entities.PaymentSuccess,
-		}
-
 		tc.mockRepo.EXPECT().
 			GetOrderByReference(gomock.Any(), orderRef).
-			Return(existingOrder, nil)
+			Return(&entities.Order{
+				ID:             uuid.New(),
+				CustomerID:     uuid.New(),
+				OrderReference: orderRef,
+				Status:         entities.Delivered,
+			}, nil)
 
 		tc.mockRepo.EXPECT().
-			Update(gomock.Any(), gomock.Any(), orderID.String(), customerID.String()).
-			Return(errors.New("database error"))
+			Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(0)
 
 		req := &entities.UpdateOrderRequest{
 			OrderReference: orderRef,
 			Body: &entities.UpdateOrderRequestBody{
-				Status: &newStatus,
+				Status: nullable.StringPtr(entities.Pending.String()),
 			},
 		}
 
 		err := s.UpdateOrder(ctx, req)
 
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "database error")
+		assert.ErrorContains(t, err, "Order cannot transition from delivered to pending")
+	})
+
+	t.Run("error status change requires a refund", func(t *testing.T) {
+		capturedAt := time.Now()
+		captured := authorizedOrder(providers.ProviderStripe, "pi_123")
+		captured.PaymentCapturedAt = &capturedAt
+
+		tests := []struct {
+			name   string
+			order  *entities.Order
+			status entities.OrderStatus
+		}{
+			{name: "refund a paid order", order: &entities.Order{Status: entities.Delivered}, status: entities.Refunded},
+			{name: "cancel a paid order", order: &entities.Order{Status: entities.Processing}, status: entities.Cancelled},
+			{name: "cancel a captured order", order: captured, status: entities.Cancelled},
+			{name: "refund pending cancellation", order: &entities.Order{Status: entities.PaymentSuccess}, status: entities.CancelRefundPending},
+			{name: "complete pending cancellation", order: &entities.Order{Status: entities.CancelRefundPending}, status: entities.Cancelled},
+			{name: "revert pending cancellation", order: &entities.Order{Status: entities.CancelRefundPending}, status: entities.PaymentSuccess},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tc := setupTestController(t)
+				s := newServiceUnderTest(tc)
+
+				tt.order.OrderReference = "ORD123456"
+				tc.mockRepo.EXPECT().GetOrderByReference(gomock.Any(), tt.order.OrderReference).Return(tt.order, nil)
+				tc.mockRepo.EXPECT().
+					Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				err := s.UpdateOrder(context.Background(), &entities.UpdateOrderRequest{
+					OrderReference: tt.order.OrderReference,
+					Body:           &entities.UpdateOrderRequestBody{Status: nullable.StringPtr(tt.status.String())},
+				})
+
+				var apiErr *sharedErrors.APIError
+				assert.True(t, errors.As(err, &apiErr))
+				assert.Equal(t, "ORDER_STATUS_REQUIRES_REFUND", apiErr.ErrorCode)
+			})
+		}
+	})
+
+	t.Run("unpaid order is cancelled", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		order := &entities.Order{ID: uuid.New(), CustomerID: uuid.New(), OrderReference: "ORD123456", Status: entities.Pending}
+
+		tc.mockRepo.EXPECT().GetOrderByReference(gomock.Any(), order.OrderReference).Return(order, nil)
+		tc.mockRepo.EXPECT().
+			Update(gomock.Any(), gomock.Any(), order.ID.String(), order.CustomerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, data map[string]interface{}, _, _ string, _ entities.OrderEventSource, _ []*entities.OutboxMessage) {
+				assert.Equal(t, entities.Cancelled, data["status"])
+			}).
+			Return(nil)
+
+		err := s.UpdateOrder(context.Background(), &entities.UpdateOrderRequest{
+			OrderReference: order.OrderReference,
+			Body:           &entities.UpdateOrderRequestBody{Status: nullable.StringPtr(entities.Cancelled.String())},
+		})
+
+		assert.NoError(t, err)
 	})
 
 	t.Run("success with same status - no notification", func(t *testing.T) {
@@ -1422,14 +2168,13 @@ func TestUpdateOrder(t *testing.T) {
 			Return(existingOrder, nil)
 
 		tc.mockRepo.EXPECT().
-			Update(gomock.Any(), gomock.Any(), orderID.String(), customerID.String()).
+			Update(gomock.Any(), gomock.Any(), orderID.String(), customerID.String(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ map[string]interface{}, _, _ string, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				// No webhook notification should be queued since status is the same
+				assert.Empty(t, outbox)
+			}).
 			Return(nil)
 
-		// No webhook notification should be called since status is the same
-		tc.mockWebhook.EXPECT().
-			NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
-			Times(0)
-
 		req := &entities.UpdateOrderRequest{
 			OrderReference: orderRef,
 			Body: &entities.UpdateOrderRequestBody{
@@ -1443,6 +2188,84 @@ func TestUpdateOrder(t *testing.T) {
 	})
 }
 
+func TestGetOrderHistory(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		customerID := uuid.New()
+		ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
+
+		events := []*entities.OrderEvent{
+			{
+				OrderID:   orderID,
+				NewStatus: entities.Pending.String(),
+				Actor:     entities.ActorCustomer,
+			},
+			{
+				OrderID:       orderID,
+				OldStatus:     nullable.StringPtr(entities.Pending.String()),
+				NewStatus:     entities.PaymentSuccess.String(),
+				Actor:         entities.ActorWebhook,
+				SourceEventID: nullable.StringPtr("evt_123"),
+			},
+		}
+
+		tc.mockRepo.EXPECT().
+			GetOrderByID(gomock.Any(), orderID).
+			Return(&entities.Order{ID: orderID, CustomerID: customerID}, nil)
+
+		tc.mockRepo.EXPECT().
+			GetOrderEvents(gomock.Any(), orderID).
+			Return(events, nil)
+
+		resp, err := s.GetOrderHistory(ctx, &entities.GetOrderHistoryRequest{OrderID: orderID})
+
+		assert.NoError(t, err)
+		assert.Equal(t, events, resp.Events)
+	})
+
+	t.Run("error order belongs to another customer", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		ctx := sharedMeta.WithXCustomerID(context.Background(), uuid.New().String())
+
+		tc.mockRepo.EXPECT().
+			GetOrderByID(gomock.Any(), orderID).
+			Return(&entities.Order{ID: orderID, CustomerID: uuid.New()}, nil)
+
+		resp, err := s.GetOrderHistory(ctx, &entities.GetOrderHistoryRequest{OrderID: orderID})
+
+		assert.Nil(t, resp)
+		assert.ErrorContains(t, err, "Order not found")
+	})
+
+	t.Run("error getting events", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		customerID := uuid.New()
+		ctx := sharedMeta.WithXCustomerID(context.Background(), customerID.String())
+
+		tc.mockRepo.EXPECT().
+			GetOrderByID(gomock.Any(), orderID).
+			Return(&entities.Order{ID: orderID, CustomerID: customerID}, nil)
+
+		tc.mockRepo.EXPECT().
+			GetOrderEvents(gomock.Any(), orderID).
+			Return(nil, errors.New("db error"))
+
+		resp, err := s.GetOrderHistory(ctx, &entities.GetOrderHistoryRequest{OrderID: orderID})
+
+		assert.Nil(t, resp)
+		assert.ErrorContains(t, err, "Error getting order history")
+	})
+}
+
 func TestRefundOrder_WithStripe(t *testing.T) {
 	t.Run("success with stripe refund", func(t *testing.T) {
 		tc := setupTestController(t)
@@ -1462,12 +2285,13 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 		ctx := context.Background()
 
 		existingOrder := &entities.Order{
-			ID:                    orderID,
-			CustomerID:            customerID,
-			OrderReference:        orderRef,
-			Status:                entities.PaymentSuccess,
-			Total:                 decimal.NewFromInt(100),
-			StripePaymentIntentID: &paymentIntentID,
+			ID:              orderID,
+			CustomerID:      customerID,
+			OrderReference:  orderRef,
+			Status:          entities.PaymentSuccess,
+			Total:           decimal.NewFromInt(100),
+			PaymentProvider: providers.ProviderStripe,
+			PaymentID:       &paymentIntentID,
 		}
 
 		orderItems := []*entities.OrderItem{
@@ -1481,10 +2305,6 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 			},
 		}
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderStripe)
-
 		tc.mockRepo.EXPECT().
 			GetOrderByReference(gomock.Any(), orderRef).
 			Return(existingOrder, nil)
@@ -1495,8 +2315,8 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 
 		tc.mockPayment.EXPECT().
 			Refund(gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, req *stripeEntities.RefundRequest) {
-				assert.Equal(t, paymentIntentID, req.PaymentIntentId)
+			Do(func(_ context.Context, req providers.RefundRequest) {
+				assert.Equal(t, paymentIntentID, req.PaymentID)
 				assert.Equal(t, expectedRefundAmount, req.Amount)
 			}).
 			Return(&providers.RefundResponse{
@@ -1505,8 +2325,9 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 			}, nil)
 
 		tc.mockRepo.EXPECT().
-			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}) {
+			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged}, outboxTopics(outbox))
 				// Order should not be marked as fully refunded since we're only refunding 1 out of 2 items
 				_, hasStatus := orderData["status"]
 				assert.False(t, hasStatus)
@@ -1517,20 +2338,8 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 
 				itemMap := itemData.(map[string]interface{})
 				assert.Equal(t, entities.ItemInitiatedRefund.String(), itemMap["status"])
-				assert.Equal(t, refundID, itemMap["stripe_refund_id"])
-				assert.Equal(t, expectedRefundAmount.InexactFloat64(), itemMap["stripe_refund_amount"])
-			}).
-			Return(nil)
-
-		notifyCallDone := make(chan struct{})
-		tc.mockWebhook.EXPECT().
-			NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, req *webhookEntities.NotifyOrderStatusChangeRequest) {
-				assert.Equal(t, customerID.String(), req.CustomerID)
-				assert.Equal(t, orderID.String(), req.OrderID)
-				assert.Equal(t, orderRef, req.OrderReference)
-				assert.Equal(t, entities.Refunded.String(), req.Status)
-				close(notifyCallDone)
+				assert.Equal(t, refundID, itemMap["refund_id"])
+				assert.Equal(t, expectedRefundAmount.InexactFloat64(), itemMap["refund_amount"])
 			}).
 			Return(nil)
 
@@ -1556,8 +2365,6 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 		assert.Equal(t, itemSKU, resp.RefundableItems[0].Sku)
 		assert.Equal(t, refundQuantity, resp.RefundableItems[0].Quantity)
 		assert.True(t, resp.RefundableItems[0].RefundInitiated)
-		// wait for async notify call to be done
-		<-notifyCallDone
 	})
 
 	t.Run("success with full order refund", func(t *testing.T) {
@@ -1578,12 +2385,13 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 		ctx := context.Background()
 
 		existingOrder := &entities.Order{
-			ID:                    orderID,
-			CustomerID:            customerID,
-			OrderReference:        orderRef,
-			Status:                entities.PaymentSuccess,
-			Total:                 decimal.NewFromInt(100),
-			StripePaymentIntentID: &paymentIntentID,
+			ID:              orderID,
+			CustomerID:      customerID,
+			OrderReference:  orderRef,
+			Status:          entities.PaymentSuccess,
+			Total:           decimal.NewFromInt(100),
+			PaymentProvider: providers.ProviderStripe,
+			PaymentID:       &paymentIntentID,
 		}
 
 		orderItems := []*entities.OrderItem{
@@ -1605,10 +2413,6 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 			GetOrderItemsByID(gomock.Any(), orderID).
 			Return(orderItems, nil)
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderStripe)
-
 		tc.mockPayment.EXPECT().
 			Refund(gomock.Any(), gomock.Any()).
 			Return(&providers.RefundResponse{
@@ -1617,18 +2421,10 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 			}, nil)
 
 		tc.mockRepo.EXPECT().
-			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}) {
-				assert.Equal(t, expectedRefundAmount, orderData["stripe_refund_total"])
-			}).
-			Return(nil)
-
-		notifyCallDone := make(chan struct{})
-		tc.mockWebhook.EXPECT().
-			NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, req *webhookEntities.NotifyOrderStatusChangeRequest) {
-				assert.Equal(t, entities.Refunded.String(), req.Status)
-				close(notifyCallDone)
+			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged}, outboxTopics(outbox))
+				assert.Equal(t, expectedRefundAmount, orderData["refund_total"])
 			}).
 			Return(nil)
 
@@ -1649,8 +2445,6 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 		assert.NoError(t, err)
 		assert.NotNil(t, resp)
 		assert.Equal(t, expectedRefundAmount, resp.TotalRefundableAmount)
-		// wait for async notify call to be done
-		<-notifyCallDone
 	})
 
 	t.Run("error order not found", func(t *testing.T) {
@@ -1660,10 +2454,6 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 		orderRef := "ORD123456"
 		ctx := context.Background()
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderStripe)
-
 		tc.mockRepo.EXPECT().
 			GetOrderByReference(gomock.Any(), orderRef).
 			Return(nil, errors.New("order not found"))
@@ -1703,10 +2493,6 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 			Status:         entities.Refunded,
 		}
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderStripe)
-
 		tc.mockRepo.EXPECT().
 			GetOrderByReference(gomock.Any(), orderRef).
 			Return(existingOrder, nil)
@@ -1758,10 +2544,6 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 			},
 		}
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderStripe)
-
 		tc.mockRepo.EXPECT().
 			GetOrderByReference(gomock.Any(), orderRef).
 			Return(existingOrder, nil)
@@ -1819,10 +2601,6 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 			},
 		}
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderStripe)
-
 		tc.mockRepo.EXPECT().
 			GetOrderByReference(gomock.Any(), orderRef).
 			Return(existingOrder, nil)
@@ -1863,12 +2641,13 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 		ctx := context.Background()
 
 		existingOrder := &entities.Order{
-			ID:                    orderID,
-			CustomerID:            customerID,
-			OrderReference:        orderRef,
-			Status:                entities.PaymentSuccess,
-			Total:                 decimal.NewFromInt(100),
-			StripePaymentIntentID: &paymentIntentID,
+			ID:              orderID,
+			CustomerID:      customerID,
+			OrderReference:  orderRef,
+			Status:          entities.PaymentSuccess,
+			Total:           decimal.NewFromInt(100),
+			PaymentProvider: providers.ProviderStripe,
+			PaymentID:       &paymentIntentID,
 		}
 
 		orderItems := []*entities.OrderItem{
@@ -1882,10 +2661,6 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 			},
 		}
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderStripe)
-
 		tc.mockRepo.EXPECT().
 			GetOrderByReference(gomock.Any(), orderRef).
 			Return(existingOrder, nil)
@@ -1917,7 +2692,7 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 		assert.Contains(t, err.Error(), "Error processing refund via Stripe")
 	})
 
-	t.Run("error unsupported payment provider", func(t *testing.T) {
+	t.Run("error payment provider not configured", func(t *testing.T) {
 		tc := setupTestController(t)
 		s := newServiceUnderTest(tc)
 
@@ -1928,12 +2703,15 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 		itemSKU := "SKU123"
 		ctx := context.Background()
 
+		paymentID := "pay_123"
 		existingOrder := &entities.Order{
-			ID:             orderID,
-			CustomerID:     customerID,
-			OrderReference: orderRef,
-			Status:         entities.PaymentSuccess,
-			Total:          decimal.NewFromInt(100),
+			ID:              orderID,
+			CustomerID:      customerID,
+			OrderReference:  orderRef,
+			Status:          entities.PaymentSuccess,
+			Total:           decimal.NewFromInt(100),
+			PaymentProvider: providers.ProviderType("random_provider"),
+			PaymentID:       &paymentID,
 		}
 
 		orderItems := []*entities.OrderItem{
@@ -1955,11 +2733,6 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 			GetOrderItemsByID(gomock.Any(), orderID).
 			Return(orderItems, nil)
 
-		randomProvider := providers.ProviderType("random_provider")
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(randomProvider)
-
 		req := &entities.RefundOrderRequest{
 			OrderReference: orderRef,
 			Body: &entities.RefundOrderRequestBody{
@@ -1976,7 +2749,7 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 
 		assert.Error(t, err)
 		assert.Nil(t, resp)
-		assert.Contains(t, err.Error(), "Payment provider not supported for refunds")
+		assert.Contains(t, err.Error(), "Order payment can't be refunded")
 	})
 
 	t.Run("skip already refunded items", func(t *testing.T) {
@@ -2018,10 +2791,6 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 			},
 		}
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderStripe)
-
 		tc.mockRepo.EXPECT().
 			GetOrderByReference(gomock.Any(), orderRef).
 			Return(existingOrder, nil)
@@ -2069,12 +2838,13 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 		ctx := context.Background()
 
 		existingOrder := &entities.Order{
-			ID:                    orderID,
-			CustomerID:            customerID,
-			OrderReference:        orderRef,
-			Status:                entities.PaymentSuccess,
-			Total:                 decimal.NewFromInt(150),
-			StripePaymentIntentID: &paymentIntentID,
+			ID:              orderID,
+			CustomerID:      customerID,
+			OrderReference:  orderRef,
+			Status:          entities.PaymentSuccess,
+			Total:           decimal.NewFromInt(150),
+			PaymentProvider: providers.ProviderStripe,
+			PaymentID:       &paymentIntentID,
 		}
 
 		// Total items: 3 (item1: 1, item2: 2), refunding only 1 item
@@ -2105,14 +2875,10 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 			GetOrderItemsByID(gomock.Any(), orderID).
 			Return(orderItems, nil)
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderStripe)
-
 		tc.mockPayment.EXPECT().
 			Refund(gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, req *stripeEntities.RefundRequest) {
-				assert.Equal(t, paymentIntentID, req.PaymentIntentId)
+			Do(func(_ context.Context, req providers.RefundRequest) {
+				assert.Equal(t, paymentIntentID, req.PaymentID)
 				assert.Equal(t, expectedRefundAmount, req.Amount)
 			}).
 			Return(&providers.RefundResponse{
@@ -2121,8 +2887,9 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 			}, nil)
 
 		tc.mockRepo.EXPECT().
-			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}) {
+			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged}, outboxTopics(outbox))
 				// Order should NOT be marked as fully refunded since we're only refunding 1 out of 3 total items
 				_, hasStatus := orderData["status"]
 				assert.False(t, hasStatus, "Order status should not be changed for partial refund")
@@ -2133,49 +2900,238 @@ func TestRefundOrder_WithStripe(t *testing.T) {
 
 				itemMap := itemData.(map[string]interface{})
 				assert.Equal(t, entities.ItemInitiatedRefund.String(), itemMap["status"])
-				assert.Equal(t, refundID, itemMap["stripe_refund_id"])
-				assert.Equal(t, expectedRefundAmount.InexactFloat64(), itemMap["stripe_refund_amount"])
+				assert.Equal(t, refundID, itemMap["refund_id"])
+				assert.Equal(t, expectedRefundAmount.InexactFloat64(), itemMap["refund_amount"])
+			}).
+			Return(nil)
+
+		req := &entities.RefundOrderRequest{
+			OrderReference: orderRef,
+			Body: &entities.RefundOrderRequestBody{
+				Items: []*entities.RefundItem{
+					{
+						Sku:      itemSKU1,
+						Quantity: refundQuantity,
+					},
+				},
+			},
+		}
+
+		resp, err := s.RefundOrder(ctx, req)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+		assert.Equal(t, expectedRefundAmount, resp.TotalRefundableAmount)
+		assert.Len(t, resp.RefundableItems, 1)
+		assert.Equal(t, orderItemID1.String(), resp.RefundableItems[0].ItemId)
+		assert.Equal(t, itemSKU1, resp.RefundableItems[0].Sku)
+		assert.Equal(t, refundQuantity, resp.RefundableItems[0].Quantity)
+		assert.True(t, resp.RefundableItems[0].RefundInitiated)
+	})
+
+	t.Run("partial refund includes allocated tax and shipping", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		orderRef := "ORD123456"
+		orderItemID1 := uuid.New()
+		paymentIntentID := "pi_123"
+		refundID := "re_123"
+		itemTax := decimal.NewFromInt(9)
+		itemShipping := decimal.NewFromInt(6)
+		otherTax := decimal.NewFromInt(3)
+		otherShipping := decimal.NewFromInt(2)
+		// 1 of 3 units: 50 + 9/3 tax + 6/3 shipping
+		expectedRefundAmount := decimal.NewFromInt(55)
+
+		ctx := context.Background()
+
+		existingOrder := &entities.Order{
+			ID:              orderID,
+			OrderReference:  orderRef,
+			Status:          entities.PaymentSuccess,
+			TaxAmount:       decimal.NewFromInt(12),
+			Total:           decimal.NewFromInt(220),
+			PaymentProvider: providers.ProviderStripe,
+			PaymentID:       &paymentIntentID,
+		}
+
+		orderItems := []*entities.OrderItem{
+			{
+				ID:             orderItemID1,
+				OrderID:        orderID,
+				SKU:            "SKU123",
+				Price:          decimal.NewFromInt(50),
+				Quantity:       3,
+				Status:         entities.ItemDelivered,
+				TaxAmount:      &itemTax,
+				ShippingAmount: &itemShipping,
+			},
+			{
+				ID:             uuid.New(),
+				OrderID:        orderID,
+				SKU:            "SKU456",
+				Price:          decimal.NewFromInt(50),
+				Quantity:       1,
+				Status:         entities.ItemDelivered,
+				TaxAmount:      &otherTax,
+				ShippingAmount: &otherShipping,
+			},
+		}
+
+		tc.mockRepo.EXPECT().
+			GetOrderByReference(gomock.Any(), orderRef).
+			Return(existingOrder, nil)
+
+		tc.mockRepo.EXPECT().
+			GetOrderItemsByID(gomock.Any(), orderID).
+			Return(orderItems, nil)
+
+		tc.mockPayment.EXPECT().
+			Refund(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, req providers.RefundRequest) {
+				assert.True(t, expectedRefundAmount.Equal(req.Amount))
+			}).
+			Return(&providers.RefundResponse{
+				ID:     refundID,
+				Status: stripeEntities.StripeRefundSucceeded,
+			}, nil)
+
+		tc.mockRepo.EXPECT().
+			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, _ map[string]interface{}, itemsData map[string]interface{}, _ entities.OrderEventSource, _ []*entities.OutboxMessage) {
+				itemMap := itemsData[orderItemID1.String()].(map[string]interface{})
+				assert.Equal(t, expectedRefundAmount.InexactFloat64(), itemMap["refund_amount"])
+			}).
+			Return(nil)
+
+		req := &entities.RefundOrderRequest{
+			OrderReference: orderRef,
+			Body: &entities.RefundOrderRequestBody{
+				Items:           []*entities.RefundItem{{Sku: "SKU123", Quantity: 1}},
+				IncludeShipping: true,
+			},
+		}
+
+		resp, err := s.RefundOrder(ctx, req)
+
+		assert.NoError(t, err)
+		assert.True(t, expectedRefundAmount.Equal(resp.TotalRefundableAmount))
+		assert.True(t, decimal.NewFromInt(50).Equal(resp.ItemsAmount))
+		assert.True(t, decimal.NewFromInt(3).Equal(resp.TaxAmount))
+		assert.True(t, decimal.NewFromInt(2).Equal(resp.ShippingAmount))
+		assert.True(t, decimal.NewFromInt(3).Equal(resp.RefundableItems[0].TaxAmount))
+		assert.True(t, decimal.NewFromInt(2).Equal(resp.RefundableItems[0].ShippingAmount))
+	})
+}
+
+func TestRefundOrder_WithAuthorizeNet(t *testing.T) {
+	t.Run("success with full order refund", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		orderRef := "ORD123456"
+		orderItemID := uuid.New()
+		transactionID := "80041310709"
+		refundID := "80041310800"
+		itemSKU := "SKU123"
+
+		ctx := context.Background()
+
+		existingOrder := &entities.Order{
+			ID:              orderID,
+			CustomerID:      uuid.New(),
+			OrderReference:  orderRef,
+			Status:          entities.Delivered,
+			Total:           decimal.NewFromInt(110),
+			PaymentProvider: providers.ProviderAuthorizeNet,
+			PaymentID:       &transactionID,
+		}
+
+		tc.mockRepo.EXPECT().
+			GetOrderByReference(gomock.Any(), orderRef).
+			Return(existingOrder, nil)
+
+		tc.mockRepo.EXPECT().
+			GetOrderItemsByID(gomock.Any(), orderID).
+			Return([]*entities.OrderItem{
+				{ID: orderItemID, OrderID: orderID, SKU: itemSKU, Price: decimal.NewFromInt(50), Quantity: 2, Status: entities.ItemDelivered},
+			}, nil)
+
+		tc.mockPayment.EXPECT().
+			Refund(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, req providers.RefundRequest) {
+				assert.Equal(t, transactionID, req.PaymentID)
+				// the whole order is refunded, including shipping and taxes
+				assert.True(t, req.Amount.IsZero())
 			}).
-			Return(nil)
+			Return(&providers.RefundResponse{ID: refundID, Status: providers.RefundStatusPending}, nil)
 
-		notifyCallDone := make(chan struct{})
-		tc.mockWebhook.EXPECT().
-			NotifyOrderStatusChange(gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, req *webhookEntities.NotifyOrderStatusChangeRequest) {
-				assert.Equal(t, customerID.String(), req.CustomerID)
-				assert.Equal(t, orderID.String(), req.OrderID)
-				assert.Equal(t, orderRef, req.OrderReference)
-				assert.Equal(t, entities.Refunded.String(), req.Status)
-				close(notifyCallDone)
+		tc.mockRepo.EXPECT().
+			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}, _ entities.OrderEventSource, _ []*entities.OutboxMessage) {
+				assert.Equal(t, entities.Refunded, orderData["status"])
+				assert.Equal(t, decimal.NewFromInt(100), orderData["refund_total"])
+
+				itemMap := itemsData[orderItemID.String()].(map[string]interface{})
+				assert.Equal(t, entities.ItemInitiatedRefund.String(), itemMap["status"])
+				assert.Equal(t, refundID, itemMap["refund_id"])
 			}).
 			Return(nil)
 
-		req := &entities.RefundOrderRequest{
+		resp, err := s.RefundOrder(ctx, &entities.RefundOrderRequest{
 			OrderReference: orderRef,
 			Body: &entities.RefundOrderRequestBody{
-				Items: []*entities.RefundItem{
-					{
-						Sku:      itemSKU1,
-						Quantity: refundQuantity,
-					},
-				},
+				Items: []*entities.RefundItem{{Sku: itemSKU, Quantity: 2}},
 			},
-		}
-
-		resp, err := s.RefundOrder(ctx, req)
+		})
 
 		assert.NoError(t, err)
-		assert.NotNil(t, resp)
-		assert.Equal(t, expectedRefundAmount, resp.TotalRefundableAmount)
-		assert.Len(t, resp.RefundableItems, 1)
-		assert.Equal(t, orderItemID1.String(), resp.RefundableItems[0].ItemId)
-		assert.Equal(t, itemSKU1, resp.RefundableItems[0].Sku)
-		assert.Equal(t, refundQuantity, resp.RefundableItems[0].Quantity)
-		assert.True(t, resp.RefundableItems[0].RefundInitiated)
-		// wait for async notify call to be done
-		<-notifyCallDone
+		assert.Equal(t, decimal.NewFromInt(100), resp.TotalRefundableAmount)
 	})
 
+	t.Run("error authorize.net refund failed", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		orderRef := "ORD123456"
+		transactionID := "80041310709"
+		itemSKU := "SKU123"
+
+		tc.mockRepo.EXPECT().
+			GetOrderByReference(gomock.Any(), orderRef).
+			Return(&entities.Order{
+				ID:              orderID,
+				OrderReference:  orderRef,
+				Status:          entities.PaymentSuccess,
+				Total:           decimal.NewFromInt(100),
+				PaymentProvider: providers.ProviderAuthorizeNet,
+				PaymentID:       &transactionID,
+			}, nil)
+
+		tc.mockRepo.EXPECT().
+			GetOrderItemsByID(gomock.Any(), orderID).
+			Return([]*entities.OrderItem{
+				{ID: uuid.New(), OrderID: orderID, SKU: itemSKU, Price: decimal.NewFromInt(50), Quantity: 2},
+			}, nil)
+
+		tc.mockPayment.EXPECT().
+			Refund(gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("partial refunds are only possible once the transaction has settled"))
+
+		resp, err := s.RefundOrder(context.Background(), &entities.RefundOrderRequest{
+			OrderReference: orderRef,
+			Body: &entities.RefundOrderRequestBody{
+				Items: []*entities.RefundItem{{Sku: itemSKU, Quantity: 1}},
+			},
+		})
+
+		assert.Nil(t, resp)
+		assert.ErrorContains(t, err, "Error processing refund via Authorize.net")
+	})
 }
 
 func TestProcessRefundSucceeded(t *testing.T) {
@@ -2200,34 +3156,31 @@ func TestProcessRefundSucceeded(t *testing.T) {
 
 		orderItems := []*entities.OrderItem{
 			{
-				ID:             orderItemID1,
-				OrderID:        orderID,
-				Status:         entities.ItemInitiatedRefund,
-				StripeRefundID: refundID,
+				ID:       orderItemID1,
+				OrderID:  orderID,
+				Status:   entities.ItemInitiatedRefund,
+				RefundID: refundID,
 			},
 			{
-				ID:             orderItemID2,
-				OrderID:        orderID,
-				Status:         entities.ItemDelivered,
-				StripeRefundID: "",
+				ID:       orderItemID2,
+				OrderID:  orderID,
+				Status:   entities.ItemDelivered,
+				RefundID: "",
 			},
 		}
 
 		tc.mockRepo.EXPECT().
-			GetOrderItemsByStripeRefundID(gomock.Any(), refundID).
+			GetOrderItemsByRefundID(gomock.Any(), refundID).
 			Return(orderItems, nil)
 
 		tc.mockRepo.EXPECT().
 			GetOrderByID(gomock.Any(), orderID).
 			Return(existingOrder, nil)
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderStripe)
-
 		tc.mockRepo.EXPECT().
-			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}) {
+			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Empty(t, outbox)
 				// Order should NOT be marked as refunded since not all items are refunded
 				_, hasStatus := orderData["status"]
 				assert.False(t, hasStatus, "Order status should not change for partial refund")
@@ -2238,7 +3191,7 @@ func TestProcessRefundSucceeded(t *testing.T) {
 
 				itemMap := itemData.(map[string]interface{})
 				assert.Equal(t, entities.ItemRefunded, itemMap["status"])
-				assert.Equal(t, refundAmount.InexactFloat64(), itemMap["stripe_refund_amount"])
+				assert.Equal(t, refundAmount.InexactFloat64(), itemMap["refund_amount"])
 			}).
 			Return(nil)
 
@@ -2262,42 +3215,39 @@ func TestProcessRefundSucceeded(t *testing.T) {
 		ctx := context.Background()
 
 		existingOrder := &entities.Order{
-			ID:                orderID,
-			CustomerID:        customerID,
-			Status:            entities.PaymentSuccess,
-			StripeRefundTotal: &existingRefundTotal,
+			ID:          orderID,
+			CustomerID:  customerID,
+			Status:      entities.PaymentSuccess,
+			RefundTotal: &existingRefundTotal,
 		}
 
 		orderItems := []*entities.OrderItem{
 			{
-				ID:             orderItemID1,
-				OrderID:        orderID,
-				Status:         entities.ItemInitiatedRefund,
-				StripeRefundID: refundID,
+				ID:       orderItemID1,
+				OrderID:  orderID,
+				Status:   entities.ItemInitiatedRefund,
+				RefundID: refundID,
 			},
 			{
-				ID:             orderItemID2,
-				OrderID:        orderID,
-				Status:         entities.ItemRefunded, // Already refunded
-				StripeRefundID: "re_456",
+				ID:       orderItemID2,
+				OrderID:  orderID,
+				Status:   entities.ItemRefunded, // Already refunded
+				RefundID: "re_456",
 			},
 		}
 
 		tc.mockRepo.EXPECT().
-			GetOrderItemsByStripeRefundID(gomock.Any(), refundID).
+			GetOrderItemsByRefundID(gomock.Any(), refundID).
 			Return(orderItems, nil)
 
 		tc.mockRepo.EXPECT().
 			GetOrderByID(gomock.Any(), orderID).
 			Return(existingOrder, nil)
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderStripe)
-
 		tc.mockRepo.EXPECT().
-			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}) {
+			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Empty(t, outbox)
 				// Order SHOULD be marked as refunded since all items are now refunded
 				status, hasStatus := orderData["status"]
 				assert.True(t, hasStatus, "Order status should change for full refund")
@@ -2305,7 +3255,7 @@ func TestProcessRefundSucceeded(t *testing.T) {
 
 				// Check updated refund total
 				expectedTotal := existingRefundTotal.Add(refundAmount)
-				assert.Equal(t, expectedTotal, orderData["stripe_refund_total"])
+				assert.Equal(t, expectedTotal, orderData["refund_total"])
 
 				// Check item refund data
 				itemData, exists := itemsData[orderItemID1.String()]
@@ -2313,7 +3263,7 @@ func TestProcessRefundSucceeded(t *testing.T) {
 
 				itemMap := itemData.(map[string]interface{})
 				assert.Equal(t, entities.ItemRefunded, itemMap["status"])
-				assert.Equal(t, refundAmount.InexactFloat64(), itemMap["stripe_refund_amount"])
+				assert.Equal(t, refundAmount.InexactFloat64(), itemMap["refund_amount"])
 			}).
 			Return(nil)
 
@@ -2332,7 +3282,7 @@ func TestProcessRefundSucceeded(t *testing.T) {
 		ctx := context.Background()
 
 		tc.mockRepo.EXPECT().
-			GetOrderItemsByStripeRefundID(gomock.Any(), refundID).
+			GetOrderItemsByRefundID(gomock.Any(), refundID).
 			Return([]*entities.OrderItem{}, nil)
 
 		err := s.ProcessRefundSucceeded(ctx, refundID, refundAmount)
@@ -2350,7 +3300,7 @@ func TestProcessRefundSucceeded(t *testing.T) {
 		ctx := context.Background()
 
 		tc.mockRepo.EXPECT().
-			GetOrderItemsByStripeRefundID(gomock.Any(), refundID).
+			GetOrderItemsByRefundID(gomock.Any(), refundID).
 			Return(nil, errors.New("database error"))
 
 		err := s.ProcessRefundSucceeded(ctx, refundID, refundAmount)
@@ -2372,15 +3322,15 @@ func TestProcessRefundSucceeded(t *testing.T) {
 
 		orderItems := []*entities.OrderItem{
 			{
-				ID:             orderItemID,
-				OrderID:        orderID,
-				Status:         entities.ItemInitiatedRefund,
-				StripeRefundID: refundID,
+				ID:       orderItemID,
+				OrderID:  orderID,
+				Status:   entities.ItemInitiatedRefund,
+				RefundID: refundID,
 			},
 		}
 
 		tc.mockRepo.EXPECT().
-			GetOrderItemsByStripeRefundID(gomock.Any(), refundID).
+			GetOrderItemsByRefundID(gomock.Any(), refundID).
 			Return(orderItems, nil)
 
 		tc.mockRepo.EXPECT().
@@ -2413,15 +3363,15 @@ func TestProcessRefundSucceeded(t *testing.T) {
 
 		orderItems := []*entities.OrderItem{
 			{
-				ID:             orderItemID,
-				OrderID:        orderID,
-				Status:         entities.ItemInitiatedRefund,
-				StripeRefundID: refundID,
+				ID:       orderItemID,
+				OrderID:  orderID,
+				Status:   entities.ItemInitiatedRefund,
+				RefundID: refundID,
 			},
 		}
 
 		tc.mockRepo.EXPECT().
-			GetOrderItemsByStripeRefundID(gomock.Any(), refundID).
+			GetOrderItemsByRefundID(gomock.Any(), refundID).
 			Return(orderItems, nil)
 
 		tc.mockRepo.EXPECT().
@@ -2446,43 +3396,40 @@ func TestProcessRefundSucceeded(t *testing.T) {
 		ctx := context.Background()
 
 		existingOrder := &entities.Order{
-			ID:                orderID,
-			CustomerID:        customerID,
-			Status:            entities.PaymentSuccess,
-			StripeRefundTotal: nil, // No existing refund
+			ID:          orderID,
+			CustomerID:  customerID,
+			Status:      entities.PaymentSuccess,
+			RefundTotal: nil, // No existing refund
 		}
 
 		orderItems := []*entities.OrderItem{
 			{
-				ID:             orderItemID,
-				OrderID:        orderID,
-				Status:         entities.ItemInitiatedRefund,
-				StripeRefundID: refundID,
+				ID:       orderItemID,
+				OrderID:  orderID,
+				Status:   entities.ItemInitiatedRefund,
+				RefundID: refundID,
 			},
 		}
 
 		tc.mockRepo.EXPECT().
-			GetOrderItemsByStripeRefundID(gomock.Any(), refundID).
+			GetOrderItemsByRefundID(gomock.Any(), refundID).
 			Return(orderItems, nil)
 
 		tc.mockRepo.EXPECT().
 			GetOrderByID(gomock.Any(), orderID).
 			Return(existingOrder, nil)
 
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(providers.ProviderStripe)
-
 		tc.mockRepo.EXPECT().
-			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any()).
-			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}) {
+			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Empty(t, outbox)
 				// Order should be marked as refunded since all items are now refunded
 				status, hasStatus := orderData["status"]
 				assert.True(t, hasStatus)
 				assert.Equal(t, entities.Refunded, status)
 
 				// Check new refund total
-				assert.Equal(t, refundAmount, orderData["stripe_refund_total"])
+				assert.Equal(t, refundAmount, orderData["refund_total"])
 
 				// Check item refund data
 				itemData, exists := itemsData[orderItemID.String()]
@@ -2490,7 +3437,7 @@ func TestProcessRefundSucceeded(t *testing.T) {
 
 				itemMap := itemData.(map[string]interface{})
 				assert.Equal(t, entities.ItemRefunded, itemMap["status"])
-				assert.Equal(t, refundAmount.InexactFloat64(), itemMap["stripe_refund_amount"])
+				assert.Equal(t, refundAmount.InexactFloat64(), itemMap["refund_amount"])
 			}).
 			Return(nil)
 
@@ -2499,13 +3446,13 @@ func TestProcessRefundSucceeded(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
-	t.Run("unsupported payment provider", func(t *testing.T) {
+	t.Run("success with authorize.net refund", func(t *testing.T) {
 		tc := setupTestController(t)
 		s := newServiceUnderTest(tc)
 
 		orderID := uuid.New()
 		customerID := uuid.New()
-		refundID := "re_123"
+		refundID := "80041310800"
 		refundAmount := decimal.NewFromInt(50)
 		orderItemID := uuid.New()
 
@@ -2519,28 +3466,401 @@ func TestProcessRefundSucceeded(t *testing.T) {
 
 		orderItems := []*entities.OrderItem{
 			{
-				ID:             orderItemID,
-				OrderID:        orderID,
-				Status:         entities.ItemInitiatedRefund,
-				StripeRefundID: refundID,
+				ID:       orderItemID,
+				OrderID:  orderID,
+				Status:   entities.ItemInitiatedRefund,
+				RefundID: refundID,
 			},
 		}
 
 		tc.mockRepo.EXPECT().
-			GetOrderItemsByStripeRefundID(gomock.Any(), refundID).
+			GetOrderItemsByRefundID(gomock.Any(), refundID).
 			Return(orderItems, nil)
 
 		tc.mockRepo.EXPECT().
 			GetOrderByID(gomock.Any(), orderID).
 			Return(existingOrder, nil)
 
-		randomProvider := providers.ProviderType("random_provider")
-		tc.mockPayment.EXPECT().
-			GetProvider().
-			Return(randomProvider)
+		tc.mockRepo.EXPECT().
+			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}, _ entities.OrderEventSource, _ []*entities.OutboxMessage) {
+				// refunds are confirmed the same way whatever the payment provider
+				assert.Equal(t, entities.Refunded, orderData["status"])
+				assert.Equal(t, refundAmount, orderData["refund_total"])
+
+				itemMap := itemsData[orderItemID.String()].(map[string]interface{})
+				assert.Equal(t, entities.ItemRefunded, itemMap["status"])
+			}).
+			Return(nil)
+
+		err := s.ProcessRefundSucceeded(ctx, refundID, refundAmount)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("refund of cancelled order completes the cancellation", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		refundID := "re_123"
+		refundAmount := decimal.NewFromInt(20)
+		ctx := context.Background()
+
+		tc.mockRepo.EXPECT().
+			GetOrderItemsByRefundID(gomock.Any(), refundID).
+			Return([]*entities.OrderItem{
+				{ID: uuid.New(), OrderID: orderID, Status: entities.ItemInitiatedRefund, RefundID: refundID},
+			}, nil)
+
+		tc.mockRepo.EXPECT().
+			GetOrderByID(gomock.Any(), orderID).
+			Return(&entities.Order{ID: orderID, CustomerID: uuid.New(), Status: entities.CancelRefundPending, RefundID: &refundID}, nil)
+
+		tc.mockRepo.EXPECT().
+			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, _ map[string]interface{}, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, entities.Cancelled, orderData["status"])
+				assert.Equal(t, refundAmount, orderData["refund_total"])
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged}, outboxTopics(outbox))
+			}).
+			Return(nil)
 
 		err := s.ProcessRefundSucceeded(ctx, refundID, refundAmount)
 
-		assert.NoError(t, err) // Should not return error but log the unsupported provider
+		assert.NoError(t, err)
+	})
+
+	t.Run("refund already processed", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		refundID := "re_123"
+		ctx := context.Background()
+
+		tc.mockRepo.EXPECT().
+			GetOrderItemsByRefundID(gomock.Any(), refundID).
+			Return([]*entities.OrderItem{
+				{ID: uuid.New(), OrderID: orderID, Status: entities.ItemRefunded, RefundID: refundID},
+			}, nil)
+
+		tc.mockRepo.EXPECT().
+			GetOrderByID(gomock.Any(), orderID).
+			Return(&entities.Order{ID: orderID, Status: entities.Cancelled, RefundID: &refundID}, nil)
+
+		err := s.ProcessRefundSucceeded(ctx, refundID, decimal.NewFromInt(20))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestProcessRefundFailed(t *testing.T) {
+	statusPtr := func(status string) *string { return &status }
+
+	t.Run("partial refund rolls the items back", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		refundID := "re_123"
+		refundedItemID := uuid.New()
+		otherItemID := uuid.New()
+		refundAmount := decimal.NewFromInt(50)
+
+		existingOrder := &entities.Order{
+			ID:         orderID,
+			CustomerID: uuid.New(),
+			Status:     entities.Delivered,
+		}
+
+		orderItems := []*entities.OrderItem{
+			{
+				ID:           refundedItemID,
+				OrderID:      orderID,
+				Status:       entities.ItemInitiatedRefund,
+				RefundID:     refundID,
+				RefundAmount: &refundAmount,
+			},
+		}
+
+		events := []*entities.OrderEvent{
+			{OrderID: orderID, OrderItemID: &refundedItemID, OldStatus: statusPtr("shipped"), NewStatus: "delivered"},
+			{OrderID: orderID, OrderItemID: &otherItemID, OldStatus: statusPtr("shipped"), NewStatus: "initiated_refund"},
+			{OrderID: orderID, OrderItemID: &refundedItemID, OldStatus: statusPtr("delivered"), NewStatus: "initiated_refund"},
+		}
+
+		tc.mockRepo.EXPECT().GetOrderItemsByRefundID(gomock.Any(), refundID).Return(orderItems, nil)
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(existingOrder, nil)
+		tc.mockRepo.EXPECT().GetOrderEvents(gomock.Any(), orderID).Return(events, nil)
+		tc.mockRepo.EXPECT().
+			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}, source entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Empty(t, orderData)
+				assert.Empty(t, outbox)
+				assert.Equal(t, entities.ActorWebhook, source.Actor)
+				assert.Equal(t, "Refund failed: lost_or_stolen_card", *source.Message)
+
+				itemMap := itemsData[refundedItemID.String()].(map[string]interface{})
+				assert.Equal(t, "delivered", itemMap["status"])
+				assert.Equal(t, "lost_or_stolen_card", itemMap["refund_failure_reason"])
+				assert.Nil(t, itemMap["refund_amount"])
+			}).
+			Return(nil)
+
+		err := s.ProcessRefundFailed(context.Background(), refundID, "lost_or_stolen_card")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("full refund rolls the order back", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		refundID := "re_123"
+		itemID := uuid.New()
+		refundAmount := decimal.NewFromInt(100)
+		refundTotal := decimal.NewFromInt(100)
+
+		existingOrder := &entities.Order{
+			ID:          orderID,
+			CustomerID:  uuid.New(),
+			Status:      entities.Refunded,
+			RefundTotal: &refundTotal,
+		}
+
+		orderItems := []*entities.OrderItem{
+			{
+				ID:           itemID,
+				OrderID:      orderID,
+				Status:       entities.ItemInitiatedRefund,
+				RefundID:     refundID,
+				RefundAmount: &refundAmount,
+			},
+		}
+
+		events := []*entities.OrderEvent{
+			{OrderID: orderID, OldStatus: statusPtr("delivered"), NewStatus: "refunded"},
+			{OrderID: orderID, OrderItemID: &itemID, OldStatus: statusPtr("delivered"), NewStatus: "initiated_refund"},
+		}
+
+		tc.mockRepo.EXPECT().GetOrderItemsByRefundID(gomock.Any(), refundID).Return(orderItems, nil)
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(existingOrder, nil)
+		tc.mockRepo.EXPECT().GetOrderEvents(gomock.Any(), orderID).Return(events, nil)
+		tc.mockRepo.EXPECT().
+			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, "delivered", orderData["status"])
+				assert.Nil(t, orderData["refund_total"])
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged}, outboxTopics(outbox))
+
+				itemMap := itemsData[itemID.String()].(map[string]interface{})
+				assert.Equal(t, "delivered", itemMap["status"])
+			}).
+			Return(nil)
+
+		err := s.ProcessRefundFailed(context.Background(), refundID, "expired_or_canceled_card")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("cancelled order goes back to payment_success", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		refundID := "re_123"
+		itemID := uuid.New()
+
+		existingOrder := &entities.Order{
+			ID:         orderID,
+			CustomerID: uuid.New(),
+			Status:     entities.CancelRefundPending,
+			RefundID:   &refundID,
+		}
+
+		orderItems := []*entities.OrderItem{
+			{ID: itemID, OrderID: orderID, Status: entities.ItemInitiatedRefund, RefundID: refundID},
+		}
+
+		tc.mockRepo.EXPECT().GetOrderItemsByRefundID(gomock.Any(), refundID).Return(orderItems, nil)
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(existingOrder, nil)
+		tc.mockRepo.EXPECT().GetOrderEvents(gomock.Any(), orderID).Return([]*entities.OrderEvent{}, nil)
+		tc.mockRepo.EXPECT().
+			UpdateOrderWithOrderItems(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ uuid.UUID, orderData map[string]interface{}, itemsData map[string]interface{}, _ entities.OrderEventSource, outbox []*entities.OutboxMessage) {
+				assert.Equal(t, entities.PaymentSuccess, orderData["status"])
+				assert.Equal(t, []entities.OutboxTopic{entities.TopicOrderStatusChanged, entities.TopicInventoryUpdateOrderStatus}, outboxTopics(outbox))
+
+				// items without history fall back to pending
+				itemMap := itemsData[itemID.String()].(map[string]interface{})
+				assert.Equal(t, entities.ItemPending.String(), itemMap["status"])
+			}).
+			Return(nil)
+
+		err := s.ProcessRefundFailed(context.Background(), refundID, "unknown")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("refund failure already processed", func(t *testing.T) {
+		tc := setupTestController(t)
+		s := newServiceUnderTest(tc)
+
+		orderID := uuid.New()
+		refundID := "re_123"
+		failureReason := "unknown"
+
+		orderItems := []*entities.OrderItem{
+			{ID: uuid.New(), OrderID: orderID, Status: entities.ItemDelivered, RefundID: refundID, RefundFailureReason: &failureReason},
+		}
+
+		tc.mockRepo.EXPECT().GetOrderItemsByRefundID(gomock.Any(), refundID).Return(orderItems, nil)
+		tc.mockRepo.EXPECT().GetOrderByID(gomock.Any(), orderID).Return(&entities.Order{ID: orderID, Status: entities.Delivered}, nil)
+		tc.mockRepo.EXPECT().GetOrderEvents(gomock.Any(), orderID).Return([]*entities.OrderEvent{}, nil)
+
+		err := s.ProcessRefundFailed(context.Background(), refundID, failureReason)
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestNewPaymentRequest(t *testing.T) {
+	stripeID, authorizeNetID := "cus_123", "123456"
+	paymentReq := entities.CreatePaymentRequest{
+		Amount:          decimal.NewFromInt(10),
+		Currency:        "USD",
+		Customer:        customerEntities.Customer{StripeID: &stripeID, AuthorizeNetID: &authorizeNetID},
+		PaymentMethodId: "pm_123",
+		PaymentNonce:    "nonce_123",
+		BillingInfo:     entities.BillingInfo{FirstName: "John", Zip: "12345"},
+		IdempotencyKey:  "key_123",
+	}
+
+	tests := []struct {
+		name           string
+		provider       providers.ProviderType
+		wantCustomerID *string
+	}{
+		{name: "stripe customer", provider: providers.ProviderStripe, wantCustomerID: &stripeID},
+		{name: "authorize.net customer", provider: providers.ProviderAuthorizeNet, wantCustomerID: &authorizeNetID},
+		{name: "fake provider has no customers", provider: providers.ProviderFake},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServiceUnderTest(setupTestController(t))
+
+			req := s.newPaymentRequest(tt.provider, paymentReq)
+
+			assert.Equal(t, providers.CreatePaymentRequest{
+				Amount:          decimal.NewFromInt(10),
+				Currency:        "USD",
+				CustomerID:      tt.wantCustomerID,
+				PaymentMethodID: "pm_123",
+				PaymentNonce:    "nonce_123",
+				BillingInfo:     providers.BillingInfo{FirstName: "John", Zip: "12345"},
+				IdempotencyKey:  "key_123",
+			}, req)
+		})
+	}
+}
+
+func TestSelectPaymentClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	stripeClient, authorizeNetClient := payment.NewMockClient(ctrl), payment.NewMockClient(ctrl)
+	payments, err := payment.NewRegistry(providers.ProviderStripe, map[providers.ProviderType]payment.Client{
+		providers.ProviderStripe:       stripeClient,
+		providers.ProviderAuthorizeNet: authorizeNetClient,
 	})
+	assert.NoError(t, err)
+
+	s := &service{payments: payments}
+	stripeID, authorizeNetID := "cus_123", "123456"
+
+	tests := []struct {
+		name     string
+		body     entities.CreateOrderRequestBody
+		customer customerEntities.Customer
+		want     payment.Client
+		wantErr  error
+	}{
+		{
+			name:     "requested provider",
+			body:     entities.CreateOrderRequestBody{PaymentProvider: providers.ProviderAuthorizeNet},
+			customer: customerEntities.Customer{StripeID: &stripeID},
+			want:     authorizeNetClient,
+		},
+		{
+			name:    "requested provider not enabled",
+			body:    entities.CreateOrderRequestBody{PaymentProvider: providers.ProviderFake},
+			wantErr: moduleErrors.NewAPIError("ORDER_PAYMENT_PROVIDER_NOT_ENABLED"),
+		},
+		{
+			name:     "provider of the payment profile",
+			body:     entities.CreateOrderRequestBody{AuthorizeNetPaymentProfileID: "535672235"},
+			customer: customerEntities.Customer{StripeID: &stripeID, AuthorizeNetID: &authorizeNetID},
+			want:     authorizeNetClient,
+		},
+		{
+			name: "provider of the payment nonce",
+			body: entities.CreateOrderRequestBody{PaymentNonce: "nonce_123"},
+			want: authorizeNetClient,
+		},
+		{
+			name:     "provider of the payment method",
+			body:     entities.CreateOrderRequestBody{StripePaymentMethodID: "pm_123"},
+			customer: customerEntities.Customer{AuthorizeNetID: &authorizeNetID},
+			want:     stripeClient,
+		},
+		{
+			name:    "payment details of more than one provider",
+			body:    entities.CreateOrderRequestBody{StripePaymentMethodID: "pm_123", PaymentNonce: "nonce_123"},
+			wantErr: moduleErrors.NewAPIError("ORDER_PAYMENT_PROVIDER_AMBIGUOUS"),
+		},
+		{
+			name: "requested provider takes precedence over the payment details",
+			body: entities.CreateOrderRequestBody{
+				PaymentProvider:       providers.ProviderStripe,
+				StripePaymentMethodID: "pm_123",
+				PaymentNonce:          "nonce_123",
+			},
+			want: stripeClient,
+		},
+		{
+			name:     "customer known to the default provider",
+			customer: customerEntities.Customer{StripeID: &stripeID, AuthorizeNetID: &authorizeNetID},
+			want:     stripeClient,
+		},
+		{
+			name:     "customer only known to another provider",
+			customer: customerEntities.Customer{AuthorizeNetID: &authorizeNetID},
+			want:     authorizeNetClient,
+		},
+		{
+			name: "new customer uses the default provider",
+			want: stripeClient,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.selectPaymentClient(&tt.body, &tt.customer)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCreateOrderRequestBody_PaymentMethodID(t *testing.T) {
+	body := entities.CreateOrderRequestBody{
+		StripePaymentMethodID:        "pm_123",
+		AuthorizeNetPaymentProfileID: "535672235",
+	}
+
+	assert.Equal(t, "pm_123", body.PaymentMethodID(providers.ProviderStripe))
+	assert.Equal(t, "535672235", body.PaymentMethodID(providers.ProviderAuthorizeNet))
+	assert.Equal(t, "pm_123", body.PaymentMethodID(providers.ProviderFake))
 }
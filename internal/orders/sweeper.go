@@ -0,0 +1,91 @@
+package orders
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	ordersConfig "github.com/nurdsoft/nurd-commerce-core/internal/orders/config"
+	"github.com/nurdsoft/nurd-commerce-core/internal/orders/service"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const sweeperBatchSize = 50
+
+// PendingOrderSweeperParams for the pending order sweeper.
+type PendingOrderSweeperParams struct {
+	fx.In
+
+	OrdersConfig ordersConfig.Config
+	Logger       *zap.SugaredLogger
+	Service      service.Service
+}
+
+// NewPendingOrderSweeper periodically settles the orders whose payment webhook never arrived
+// until the application stops.
+// nolint:gocritic
+func NewPendingOrderSweeper(lc fx.Lifecycle, p PendingOrderSweeperParams) {
+	if p.OrdersConfig.PendingOrderTTL == 0 {
+		p.Logger.Info("pending order sweeper is disabled")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			p.Logger.Info("starting pending order sweeper")
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				runPendingOrderSweeper(ctx, p.Service, p.OrdersConfig, p.Logger)
+			}()
+
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			p.Logger.Info("stopping pending order sweeper")
+			cancel()
+
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	})
+}
+
+func runPendingOrderSweeper(ctx context.Context, svc service.Service, config ordersConfig.Config, log *zap.SugaredLogger) {
+	ticker := time.NewTicker(config.PendingOrderSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		// orders still pending at the provider are looked at again on every sweep,
+		// a single batch per tick keeps them from hammering the provider
+		if _, err := svc.ExpireStaleOrders(ctx, config.PendingOrderTTL, sweeperBatchSize); err != nil && ctx.Err() == nil {
+			log.Errorf("Error expiring stale orders: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+var (
+	// ModulePendingOrderSweeper for uber fx.
+	ModulePendingOrderSweeper = fx.Options(fx.Invoke(NewPendingOrderSweeper))
+)
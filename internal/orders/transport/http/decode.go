@@ -3,8 +3,10 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -14,10 +16,16 @@ import (
 	"github.com/pkg/errors"
 )
 
+// maxIdempotencyKeyLength follows the limit Stripe applies to its own idempotency keys
+const maxIdempotencyKeyLength = 255
+
 type RequestBodyType interface {
 	entities.CreateOrderRequestBody |
 		entities.UpdateOrderRequestBody |
-		entities.RefundOrderRequestBody
+		entities.RefundOrderRequestBody |
+		entities.CreateReturnRequestBody |
+		entities.UpdateReturnRequestBody |
+		entities.RefundReturnRequestBody
 }
 
 func decodeBodyFromRequest[T RequestBodyType](req *T, r *http.Request) error {
@@ -38,8 +46,14 @@ func decodeCreateOrderRequest(_ context.Context, r *http.Request) (interface{},
 		return nil, err
 	}
 
+	idempotencyKey, err := decodeIdempotencyKey(r)
+	if err != nil {
+		return nil, err
+	}
+
 	return &entities.CreateOrderRequest{
-		Body: reqBody,
+		IdempotencyKey: idempotencyKey,
+		Body:           reqBody,
 	}, nil
 }
 
@@ -84,6 +98,18 @@ func decodeGetOrderRequest(_ context.Context, r *http.Request) (interface{}, err
 	}, nil
 }
 
+func decodeGetOrderHistoryRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	params := mux.Vars(r)
+	orderID, err := uuid.Parse(params["order_id"])
+	if err != nil {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "invalid order_id")
+	}
+
+	return &entities.GetOrderHistoryRequest{
+		OrderID: orderID,
+	}, nil
+}
+
 func decodeCancelOrderRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	params := mux.Vars(r)
 	orderID, err := uuid.Parse(params["order_id"])
@@ -136,8 +162,118 @@ func decodeRefundOrderRequest(_ context.Context, r *http.Request) (interface{},
 		}
 	}
 
+	idempotencyKey, err := decodeIdempotencyKey(r)
+	if err != nil {
+		return nil, err
+	}
+
 	return &entities.RefundOrderRequest{
 		OrderReference: orderReference,
+		IdempotencyKey: idempotencyKey,
 		Body:           reqBody,
 	}, nil
 }
+
+func decodeCreateReturnRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	params := mux.Vars(r)
+	orderID, err := uuid.Parse(params["order_id"])
+	if err != nil {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "invalid order_id")
+	}
+
+	reqBody := &entities.CreateReturnRequestBody{}
+	err = decodeBodyFromRequest(reqBody, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(reqBody.Reason) == "" {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "reason is required")
+	}
+
+	if len(reqBody.Items) == 0 {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "items are required")
+	}
+
+	for _, item := range reqBody.Items {
+		if item.Sku == "" {
+			return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "item sku cannot be empty")
+		}
+		if item.Quantity <= 0 {
+			return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "item quantity must be greater than zero")
+		}
+	}
+
+	return &entities.CreateReturnRequest{
+		OrderID: orderID,
+		Body:    reqBody,
+	}, nil
+}
+
+func decodeListReturnsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	params := mux.Vars(r)
+	orderID, err := uuid.Parse(params["order_id"])
+	if err != nil {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "invalid order_id")
+	}
+
+	return &entities.ListReturnsRequest{
+		OrderID: orderID,
+	}, nil
+}
+
+func decodeUpdateReturnRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	params := mux.Vars(r)
+	returnReference := params["return_reference"]
+	if returnReference == "" {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "invalid return_reference")
+	}
+
+	reqBody := &entities.UpdateReturnRequestBody{}
+	err := decodeBodyFromRequest(reqBody, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if !reqBody.Status.IsValid() {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "invalid status")
+	}
+
+	return &entities.UpdateReturnRequest{
+		ReturnReference: returnReference,
+		Body:            reqBody,
+	}, nil
+}
+
+func decodeRefundReturnRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	params := mux.Vars(r)
+	returnReference := params["return_reference"]
+	if returnReference == "" {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "invalid return_reference")
+	}
+
+	// the body is optional, shipping is not refunded without it
+	reqBody := &entities.RefundReturnRequestBody{}
+	if r.ContentLength != 0 {
+		err := decodeBodyFromRequest(reqBody, r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &entities.RefundReturnRequest{
+		ReturnReference: returnReference,
+		Body:            reqBody,
+	}, nil
+}
+
+// decodeIdempotencyKey reads the optional Idempotency-Key header
+func decodeIdempotencyKey(r *http.Request) (string, error) {
+	key := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+	if len(key) > maxIdempotencyKeyLength {
+		return "", moduleErrors.NewAPIError("VALIDATION_ERROR",
+			fmt.Sprintf("Idempotency-Key cannot be longer than %d characters", maxIdempotencyKeyLength))
+	}
+
+	return key, nil
+}
@@ -17,9 +17,14 @@ func RegisterTransport(
 	registerCreateOrder(server, ep.CreateOrderEndpoint, atc)
 	registerListOrders(server, ep.ListOrdersEndpoint, atc)
 	registerGetOrder(server, ep.GetOrderEndpoint, atc)
+	registerGetOrderHistory(server, ep.GetOrderHistoryEndpoint, atc)
 	registerCancelOrder(server, ep.CancelOrderEndpoint, atc)
 	registerUpdateOrder(server, ep.UpdateOrderEndpoint, atc)
 	registerRefundOrder(server, ep.RefundOrderEndpoint, atc)
+	registerCreateReturn(server, ep.CreateReturnEndpoint, atc)
+	registerListReturns(server, ep.ListReturnsEndpoint, atc)
+	registerUpdateReturn(server, ep.UpdateReturnEndpoint, atc)
+	registerRefundReturn(server, ep.RefundReturnEndpoint, atc)
 }
 
 func registerCreateOrder(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
@@ -70,6 +75,22 @@ func registerGetOrder(server *httpTransport.Server, ep goKitEndpoint.Endpoint, a
 	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
 }
 
+func registerGetOrderHistory(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "GET"
+	path := "/orders/{order_id}/history"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeGetOrderHistoryRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
+
 func registerCancelOrder(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
 	method := "DELETE"
 	path := "/orders/{order_id}"
@@ -117,3 +138,67 @@ func registerRefundOrder(server *httpTransport.Server, ep goKitEndpoint.Endpoint
 	server.Handle(method, path, handler)
 	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
 }
+
+func registerCreateReturn(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "POST"
+	path := "/orders/{order_id}/returns"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeCreateReturnRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
+
+func registerListReturns(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "GET"
+	path := "/orders/{order_id}/returns"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeListReturnsRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
+
+func registerUpdateReturn(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "PUT"
+	path := "/returns/{return_reference}"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeUpdateReturnRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
+
+func registerRefundReturn(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "POST"
+	path := "/returns/{return_reference}/refund"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeRefundReturnRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
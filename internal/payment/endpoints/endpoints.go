@@ -0,0 +1,24 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/nurdsoft/nurd-commerce-core/internal/payment/service"
+)
+
+type Endpoints struct {
+	ListPaymentProvidersEndpoint endpoint.Endpoint
+}
+
+func New(svc service.Service) *Endpoints {
+	return &Endpoints{
+		ListPaymentProvidersEndpoint: makeListPaymentProviders(svc),
+	}
+}
+
+func makeListPaymentProviders(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return svc.ListPaymentProviders(ctx)
+	}
+}
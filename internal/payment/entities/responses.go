@@ -0,0 +1,15 @@
+package entities
+
+import "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+
+// swagger:model ListPaymentProvidersResponse
+type ListPaymentProvidersResponse struct {
+	Providers []PaymentProvider `json:"providers"`
+}
+
+type PaymentProvider struct {
+	// Provider to send as the payment_provider of the order
+	Provider providers.ProviderType `json:"provider"`
+	// Default is the provider used when the order doesn't pick one
+	Default bool `json:"default"`
+}
@@ -0,0 +1,36 @@
+package payment
+
+import (
+	"github.com/nurdsoft/nurd-commerce-core/internal/payment/endpoints"
+	"github.com/nurdsoft/nurd-commerce-core/internal/payment/service"
+	"github.com/nurdsoft/nurd-commerce-core/internal/payment/transport/http"
+	svcTransport "github.com/nurdsoft/nurd-commerce-core/internal/transport"
+	httpTransport "github.com/nurdsoft/nurd-commerce-core/shared/transport/http"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment"
+	"go.uber.org/fx"
+)
+
+// ModuleParams for payment.
+type ModuleParams struct {
+	fx.In
+
+	HTTPServer   *httpTransport.Server
+	APPTransport svcTransport.Client
+	Payments     payment.Registry
+}
+
+// NewModule
+// nolint:gocritic
+func NewModule(p ModuleParams) error {
+	svc := service.New(p.Payments)
+	eps := endpoints.New(svc)
+
+	http.RegisterTransport(p.HTTPServer, eps, p.APPTransport)
+
+	return nil
+}
+
+var (
+	// ModuleHttpAPI for uber fx.
+	ModuleHttpAPI = fx.Options(fx.Invoke(NewModule))
+)
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+
+	"github.com/nurdsoft/nurd-commerce-core/internal/payment/entities"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment"
+)
+
+type Service interface {
+	ListPaymentProviders(ctx context.Context) (*entities.ListPaymentProvidersResponse, error)
+}
+
+type service struct {
+	payments payment.Registry
+}
+
+func New(payments payment.Registry) Service {
+	return &service{
+		payments: payments,
+	}
+}
+
+// swagger:route GET /payment/providers payment ListPaymentProviders
+//
+// # List Payment Providers
+// ### List the payment providers enabled to pay orders with, the default one first
+//
+// Produces:
+//   - application/json
+//
+// Responses:
+//
+//	200: ListPaymentProvidersResponse Payment providers listed successfully
+//	500: DefaultError Internal Server Error
+func (s *service) ListPaymentProviders(_ context.Context) (*entities.ListPaymentProvidersResponse, error) {
+	enabled := s.payments.Providers()
+	resp := &entities.ListPaymentProvidersResponse{
+		Providers: make([]entities.PaymentProvider, 0, len(enabled)),
+	}
+
+	for i, provider := range enabled {
+		resp.Providers = append(resp.Providers, entities.PaymentProvider{
+			Provider: provider,
+			Default:  i == 0,
+		})
+	}
+
+	return resp, nil
+}
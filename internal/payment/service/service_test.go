@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/nurdsoft/nurd-commerce-core/internal/payment/entities"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_service_ListPaymentProviders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPayments := payment.NewMockRegistry(ctrl)
+	svc := New(mockPayments)
+
+	mockPayments.EXPECT().Providers().Return([]providers.ProviderType{providers.ProviderStripe, providers.ProviderAuthorizeNet})
+
+	resp, err := svc.ListPaymentProviders(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, &entities.ListPaymentProvidersResponse{
+		Providers: []entities.PaymentProvider{
+			{Provider: providers.ProviderStripe, Default: true},
+			{Provider: providers.ProviderAuthorizeNet, Default: false},
+		},
+	}, resp)
+}
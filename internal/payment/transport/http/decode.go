@@ -0,0 +1,10 @@
+package http
+
+import (
+	"context"
+	"net/http"
+)
+
+func decodeListPaymentProvidersRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return nil, nil
+}
@@ -0,0 +1,35 @@
+package http
+
+import (
+	goKitEndpoint "github.com/go-kit/kit/endpoint"
+	goKitHTTPTransport "github.com/go-kit/kit/transport/http"
+	"github.com/nurdsoft/nurd-commerce-core/internal/payment/endpoints"
+	svcTransport "github.com/nurdsoft/nurd-commerce-core/internal/transport"
+	"github.com/nurdsoft/nurd-commerce-core/internal/transport/http/encode"
+	httpTransport "github.com/nurdsoft/nurd-commerce-core/shared/transport/http"
+)
+
+// RegisterTransport for http.
+func RegisterTransport(
+	server *httpTransport.Server,
+	ep *endpoints.Endpoints,
+	svcTransportClient svcTransport.Client,
+) {
+	registerListPaymentProviders(server, ep.ListPaymentProvidersEndpoint, svcTransportClient)
+}
+
+func registerListPaymentProviders(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "GET"
+	path := "/payment/providers"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeListPaymentProvidersRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
@@ -0,0 +1,34 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/entities"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/service"
+)
+
+type Endpoints struct {
+	CreatePromotionEndpoint endpoint.Endpoint
+	ListPromotionsEndpoint  endpoint.Endpoint
+}
+
+func New(svc service.Service) *Endpoints {
+	return &Endpoints{
+		CreatePromotionEndpoint: makeCreatePromotion(svc),
+		ListPromotionsEndpoint:  makeListPromotions(svc),
+	}
+}
+
+func makeCreatePromotion(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(*entities.CreatePromotionRequest) //nolint:errcheck
+		return svc.CreatePromotion(ctx, req)
+	}
+}
+
+func makeListPromotions(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return svc.ListPromotions(ctx)
+	}
+}
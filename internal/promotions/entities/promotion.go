@@ -0,0 +1,76 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/shared/json"
+	"github.com/shopspring/decimal"
+)
+
+type DiscountType string
+
+const (
+	// PercentOff takes Value percent off the targeted items
+	PercentOff DiscountType = "percent_off"
+	// FixedOff takes Value off the targeted items, split among them by line subtotal
+	FixedOff DiscountType = "fixed_off"
+	// FreeShipping waives the shipping of the cart
+	FreeShipping DiscountType = "free_shipping"
+)
+
+func (d DiscountType) Valid() bool {
+	switch d {
+	case PercentOff, FixedOff, FreeShipping:
+		return true
+	}
+
+	return false
+}
+
+// swagger:model Promotion
+type Promotion struct {
+	ID uuid.UUID `json:"id" gorm:"column:id"`
+	// Code the customer applies to the cart, promotions without a code apply automatically
+	Code         *string      `json:"code,omitempty" gorm:"column:code"`
+	Name         string       `json:"name" gorm:"column:name"`
+	DiscountType DiscountType `json:"discount_type" gorm:"column:discount_type"`
+	// Percent for percent_off, amount for fixed_off, unused for free_shipping
+	Value decimal.Decimal `json:"value" gorm:"column:value"`
+	// Skus the discount is limited to, every item is targeted when empty
+	TargetSKUs json.JSON `json:"target_skus,omitempty" gorm:"column:target_skus"`
+	// Product attributes the targeted items must have, e.g. {"category": "shoes"}
+	TargetAttributes      json.JSON        `json:"target_attributes,omitempty" gorm:"column:target_attributes"`
+	MinSubtotal           *decimal.Decimal `json:"min_subtotal,omitempty" gorm:"column:min_subtotal"`
+	UsageLimit            *int             `json:"usage_limit,omitempty" gorm:"column:usage_limit"`
+	UsageLimitPerCustomer *int             `json:"usage_limit_per_customer,omitempty" gorm:"column:usage_limit_per_customer"`
+	StartsAt              *time.Time       `json:"starts_at,omitempty" gorm:"column:starts_at"`
+	EndsAt                *time.Time       `json:"ends_at,omitempty" gorm:"column:ends_at"`
+	Active                bool             `json:"active" gorm:"column:active"`
+	CreatedAt             time.Time        `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt             time.Time        `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+// Automatic reports whether the promotion applies without a coupon code.
+func (p *Promotion) Automatic() bool {
+	return p.Code == nil
+}
+
+// InWindow reports whether the promotion can be used at the given time.
+func (p *Promotion) InWindow(at time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && at.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !at.Before(*p.EndsAt) {
+		return false
+	}
+
+	return true
+}
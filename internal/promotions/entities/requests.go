@@ -0,0 +1,67 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/shared/json"
+	"github.com/shopspring/decimal"
+)
+
+// swagger:parameters promotions CreatePromotionRequest
+type CreatePromotionRequest struct {
+	// Promotion to create
+	//
+	// required: true
+	// in:body
+	Body *CreatePromotionRequestBody
+}
+
+type CreatePromotionRequestBody struct {
+	// Coupon code, leave it empty for a promotion applied automatically
+	//
+	// example: SUMMER10
+	Code string `json:"code,omitempty"`
+	// required: true
+	// example: Summer sale
+	Name string `json:"name"`
+	// One of percent_off, fixed_off or free_shipping
+	//
+	// required: true
+	// example: percent_off
+	DiscountType DiscountType `json:"discount_type"`
+	// Percent for percent_off, amount for fixed_off
+	//
+	// example: 10
+	Value decimal.Decimal `json:"value"`
+	// Skus the discount is limited to
+	TargetSKUs []string `json:"target_skus,omitempty"`
+	// Product attributes the targeted items must have
+	//
+	// example: {"category": "shoes"}
+	TargetAttributes map[string]any `json:"target_attributes,omitempty"`
+	// Minimum cart subtotal for the promotion to apply
+	MinSubtotal *decimal.Decimal `json:"min_subtotal,omitempty"`
+	// Number of orders the promotion can be used on
+	UsageLimit *int `json:"usage_limit,omitempty"`
+	// Number of orders each customer can use the promotion on
+	UsageLimitPerCustomer *int       `json:"usage_limit_per_customer,omitempty"`
+	StartsAt              *time.Time `json:"starts_at,omitempty"`
+	EndsAt                *time.Time `json:"ends_at,omitempty"`
+}
+
+// CalculateDiscountsRequest holds the cart the discounts are computed for.
+type CalculateDiscountsRequest struct {
+	CustomerID uuid.UUID
+	// CouponCode applied to the cart, if any
+	CouponCode     string
+	Items          []DiscountItem
+	ShippingAmount decimal.Decimal
+}
+
+type DiscountItem struct {
+	SKU        string
+	Price      decimal.Decimal
+	Quantity   int
+	Attributes *json.JSON
+}
@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// swagger:model ListPromotionsResponse
+type ListPromotionsResponse struct {
+	Promotions []*Promotion `json:"promotions"`
+}
+
+// CalculateDiscountsResponse is what the promotions take off the cart.
+type CalculateDiscountsResponse struct {
+	// ItemDiscounts is the discount of each line, in the order of the request items
+	ItemDiscounts []decimal.Decimal
+	// ItemsDiscount adds up ItemDiscounts
+	ItemsDiscount    decimal.Decimal
+	ShippingDiscount decimal.Decimal
+	// Discounts has a line per promotion applied
+	Discounts []*Discount
+}
+
+// Total is everything taken off the cart.
+func (r *CalculateDiscountsResponse) Total() decimal.Decimal {
+	return r.ItemsDiscount.Add(r.ShippingDiscount)
+}
+
+// swagger:model Discount
+type Discount struct {
+	PromotionID  uuid.UUID       `json:"promotion_id"`
+	Code         *string         `json:"code,omitempty"`
+	Name         string          `json:"name"`
+	DiscountType DiscountType    `json:"discount_type"`
+	Amount       decimal.Decimal `json:"amount"`
+}
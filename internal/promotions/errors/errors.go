@@ -0,0 +1,41 @@
+package entities
+
+import (
+	"net/http"
+
+	"github.com/nurdsoft/nurd-commerce-core/shared/errors"
+)
+
+// Module-specific errors
+var moduleErrors = map[string]struct {
+	StatusCode int
+	Message    string
+}{
+	"PROMOTION_ERROR_CREATING":       {StatusCode: http.StatusInternalServerError, Message: "Error creating promotion."},
+	"PROMOTION_ERROR_GETTING":        {StatusCode: http.StatusInternalServerError, Message: "Error getting promotions."},
+	"PROMOTION_CODE_ALREADY_EXISTS":  {StatusCode: http.StatusConflict, Message: "A promotion with this code already exists."},
+	"PROMOTION_CODE_NOT_FOUND":       {StatusCode: http.StatusNotFound, Message: "Coupon code not found."},
+	"PROMOTION_CODE_NOT_ACTIVE":      {StatusCode: http.StatusBadRequest, Message: "Coupon code is not active."},
+	"PROMOTION_USAGE_LIMIT_REACHED":  {StatusCode: http.StatusBadRequest, Message: "Coupon code usage limit reached."},
+	"PROMOTION_MIN_SUBTOTAL_NOT_MET": {StatusCode: http.StatusBadRequest, Message: "Cart subtotal is below the minimum of the coupon code."},
+	"PROMOTION_NOT_APPLICABLE":       {StatusCode: http.StatusBadRequest, Message: "Coupon code doesn't apply to the items in the cart."},
+	"PROMOTION_CUSTOMER_REQUIRED":    {StatusCode: http.StatusBadRequest, Message: "Sign in to use this coupon code."},
+}
+
+func NewAPIError(errorCode string, customMessage ...string) *errors.APIError {
+	if err, exists := moduleErrors[errorCode]; exists {
+		message := err.Message
+		if len(customMessage) > 0 {
+			message = customMessage[0]
+		}
+
+		return &errors.APIError{
+			ErrorCode:  errorCode, // Set dynamically
+			StatusCode: err.StatusCode,
+			Message:    message,
+		}
+	}
+
+	// Fallback to global/common errors
+	return errors.NewAPIError(errorCode, customMessage...)
+}
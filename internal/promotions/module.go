@@ -0,0 +1,43 @@
+package promotions
+
+import (
+	"database/sql"
+
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/endpoints"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/repository"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/service"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/transport/http"
+	svcTransport "github.com/nurdsoft/nurd-commerce-core/internal/transport"
+	httpTransport "github.com/nurdsoft/nurd-commerce-core/shared/transport/http"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ModuleParams for promotions.
+type ModuleParams struct {
+	fx.In
+
+	DB           *sql.DB
+	GormDB       *gorm.DB
+	HTTPServer   *httpTransport.Server
+	APPTransport svcTransport.Client
+	Logger       *zap.SugaredLogger
+}
+
+// NewModule
+// nolint:gocritic
+func NewModule(p ModuleParams) error {
+	repo := repository.New(p.DB, p.GormDB)
+	svc := service.New(repo, p.Logger)
+	eps := endpoints.New(svc)
+
+	http.RegisterTransport(p.HTTPServer, eps, p.APPTransport)
+
+	return nil
+}
+
+var (
+	// ModuleHttpAPI for uber fx.
+	ModuleHttpAPI = fx.Options(fx.Invoke(NewModule))
+)
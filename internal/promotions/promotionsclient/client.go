@@ -0,0 +1,24 @@
+package promotionsclient
+
+import (
+	"context"
+
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/entities"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/service"
+)
+
+type Client interface {
+	CalculateDiscounts(ctx context.Context, req *entities.CalculateDiscountsRequest) (*entities.CalculateDiscountsResponse, error)
+}
+
+func NewClient(svc service.Service) Client {
+	return &localClient{svc}
+}
+
+type localClient struct {
+	svc service.Service
+}
+
+func (c *localClient) CalculateDiscounts(ctx context.Context, req *entities.CalculateDiscountsRequest) (*entities.CalculateDiscountsResponse, error) {
+	return c.svc.CalculateDiscounts(ctx, req)
+}
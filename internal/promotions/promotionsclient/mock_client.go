@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/promotions/promotionsclient/client.go
+
+// Package promotionsclient is a generated GoMock package.
+package promotionsclient
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/nurdsoft/nurd-commerce-core/internal/promotions/entities"
+)
+
+// MockClient is a mock of Client interface.
+type MockClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientMockRecorder
+}
+
+// MockClientMockRecorder is the mock recorder for MockClient.
+type MockClientMockRecorder struct {
+	mock *MockClient
+}
+
+// NewMockClient creates a new mock instance.
+func NewMockClient(ctrl *gomock.Controller) *MockClient {
+	mock := &MockClient{ctrl: ctrl}
+	mock.recorder = &MockClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClient) EXPECT() *MockClientMockRecorder {
+	return m.recorder
+}
+
+// CalculateDiscounts mocks base method.
+func (m *MockClient) CalculateDiscounts(ctx context.Context, req *entities.CalculateDiscountsRequest) (*entities.CalculateDiscountsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalculateDiscounts", ctx, req)
+	ret0, _ := ret[0].(*entities.CalculateDiscountsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CalculateDiscounts indicates an expected call of CalculateDiscounts.
+func (mr *MockClientMockRecorder) CalculateDiscounts(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalculateDiscounts", reflect.TypeOf((*MockClient)(nil).CalculateDiscounts), ctx, req)
+}
@@ -0,0 +1,36 @@
+package promotionsclient
+
+import (
+	"database/sql"
+
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/repository"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/service"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ModuleParams for promotionsclient.
+type ModuleParams struct {
+	fx.In
+
+	DB     *sql.DB
+	GormDB *gorm.DB
+	Logger *zap.SugaredLogger
+}
+
+// NewClientModule
+// nolint:gocritic
+func NewClientModule(p ModuleParams) Client {
+	repo := repository.New(p.DB, p.GormDB)
+	svc := service.New(repo, p.Logger)
+
+	client := NewClient(svc)
+
+	return client
+}
+
+var (
+	// ModuleClient for uber fx.
+	ModuleClient = fx.Options(fx.Provide(NewClientModule))
+)
@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/promotions/repository/repository.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	entities "github.com/nurdsoft/nurd-commerce-core/internal/promotions/entities"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountRedemptions mocks base method.
+func (m *MockRepository) CountRedemptions(ctx context.Context, promotionID uuid.UUID, customerID *uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRedemptions", ctx, promotionID, customerID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRedemptions indicates an expected call of CountRedemptions.
+func (mr *MockRepositoryMockRecorder) CountRedemptions(ctx, promotionID, customerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRedemptions", reflect.TypeOf((*MockRepository)(nil).CountRedemptions), ctx, promotionID, customerID)
+}
+
+// CreatePromotion mocks base method.
+func (m *MockRepository) CreatePromotion(ctx context.Context, promotion *entities.Promotion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePromotion", ctx, promotion)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePromotion indicates an expected call of CreatePromotion.
+func (mr *MockRepositoryMockRecorder) CreatePromotion(ctx, promotion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromotion", reflect.TypeOf((*MockRepository)(nil).CreatePromotion), ctx, promotion)
+}
+
+// GetAutomaticPromotions mocks base method.
+func (m *MockRepository) GetAutomaticPromotions(ctx context.Context) ([]*entities.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAutomaticPromotions", ctx)
+	ret0, _ := ret[0].([]*entities.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAutomaticPromotions indicates an expected call of GetAutomaticPromotions.
+func (mr *MockRepositoryMockRecorder) GetAutomaticPromotions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAutomaticPromotions", reflect.TypeOf((*MockRepository)(nil).GetAutomaticPromotions), ctx)
+}
+
+// GetPromotionByCode mocks base method.
+func (m *MockRepository) GetPromotionByCode(ctx context.Context, code string) (*entities.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromotionByCode", ctx, code)
+	ret0, _ := ret[0].(*entities.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPromotionByCode indicates an expected call of GetPromotionByCode.
+func (mr *MockRepositoryMockRecorder) GetPromotionByCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromotionByCode", reflect.TypeOf((*MockRepository)(nil).GetPromotionByCode), ctx, code)
+}
+
+// ListPromotions mocks base method.
+func (m *MockRepository) ListPromotions(ctx context.Context) ([]*entities.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPromotions", ctx)
+	ret0, _ := ret[0].([]*entities.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPromotions indicates an expected call of ListPromotions.
+func (mr *MockRepositoryMockRecorder) ListPromotions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromotions", reflect.TypeOf((*MockRepository)(nil).ListPromotions), ctx)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/entities"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	CreatePromotion(ctx context.Context, promotion *entities.Promotion) error
+	ListPromotions(ctx context.Context) ([]*entities.Promotion, error)
+	GetPromotionByCode(ctx context.Context, code string) (*entities.Promotion, error)
+	GetAutomaticPromotions(ctx context.Context) ([]*entities.Promotion, error)
+	// CountRedemptions counts the orders the promotion was used on, only the ones of the customer when given.
+	// Orders whose payment failed or that were cancelled don't count. The orders repository keeps the counters
+	// and enforces the limits when the order is stored, the count only tells the customer ahead of the checkout.
+	CountRedemptions(ctx context.Context, promotionID uuid.UUID, customerID *uuid.UUID) (int64, error)
+}
+
+// New repository for promotions.
+func New(_ *sql.DB, gormDB *gorm.DB) Repository {
+	repo := &sqlRepository{gormDB}
+	return repo
+}
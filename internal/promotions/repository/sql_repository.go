@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/entities"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/promotions/errors"
+	dbErrors "github.com/nurdsoft/nurd-commerce-core/shared/db"
+	"gorm.io/gorm"
+)
+
+type sqlRepository struct {
+	gormDB *gorm.DB
+}
+
+func (r *sqlRepository) CreatePromotion(ctx context.Context, promotion *entities.Promotion) error {
+	err := r.gormDB.WithContext(ctx).Create(promotion).Error
+	if err != nil && dbErrors.IsUniqueViolationError(err) {
+		return moduleErrors.NewAPIError("PROMOTION_CODE_ALREADY_EXISTS")
+	}
+
+	return err
+}
+
+func (r *sqlRepository) ListPromotions(ctx context.Context) ([]*entities.Promotion, error) {
+	var promotions []*entities.Promotion
+	err := r.gormDB.WithContext(ctx).Order("created_at DESC").Find(&promotions).Error
+
+	return promotions, err
+}
+
+func (r *sqlRepository) GetPromotionByCode(ctx context.Context, code string) (*entities.Promotion, error) {
+	promotion := &entities.Promotion{}
+	err := r.gormDB.WithContext(ctx).Where("code = ?", code).First(promotion).Error
+	if err != nil && dbErrors.IsNotFoundError(err) {
+		return nil, nil
+	}
+
+	return promotion, err
+}
+
+func (r *sqlRepository) GetAutomaticPromotions(ctx context.Context) ([]*entities.Promotion, error) {
+	var promotions []*entities.Promotion
+	err := r.gormDB.WithContext(ctx).
+		Where("code IS NULL AND active = ?", true).
+		Order("created_at ASC").
+		Find(&promotions).Error
+
+	return promotions, err
+}
+
+func (r *sqlRepository) CountRedemptions(ctx context.Context, promotionID uuid.UUID, customerID *uuid.UUID) (int64, error) {
+	query := r.gormDB.WithContext(ctx).Select("times_used")
+	if customerID != nil {
+		query = query.Table("promotion_redemptions").Where("promotion_id = ? AND customer_id = ?", promotionID, *customerID)
+	} else {
+		query = query.Table("promotions").Where("id = ?", promotionID)
+	}
+
+	var count int64
+	err := query.Scan(&count).Error
+
+	return count, err
+}
@@ -0,0 +1,464 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/entities"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/promotions/errors"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/repository"
+	sharedErrors "github.com/nurdsoft/nurd-commerce-core/shared/errors"
+	sharedJson "github.com/nurdsoft/nurd-commerce-core/shared/json"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// discountPlaces is the precision discounts are rounded to.
+const discountPlaces = 2
+
+var hundred = decimal.NewFromInt(100)
+
+type Service interface {
+	CreatePromotion(ctx context.Context, req *entities.CreatePromotionRequest) (*entities.Promotion, error)
+	ListPromotions(ctx context.Context) (*entities.ListPromotionsResponse, error)
+	CalculateDiscounts(ctx context.Context, req *entities.CalculateDiscountsRequest) (*entities.CalculateDiscountsResponse, error)
+}
+
+type service struct {
+	repo repository.Repository
+	log  *zap.SugaredLogger
+}
+
+func New(repo repository.Repository, log *zap.SugaredLogger) Service {
+	return &service{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// swagger:route POST /promotions promotions CreatePromotionRequest
+//
+// # Create Promotion
+// ### Create a coupon code, or a promotion applied automatically when it has no code
+//
+// Produces:
+//   - application/json
+//
+// Responses:
+//
+//	200: Promotion Promotion created successfully
+//	400: DefaultError Bad Request
+//	409: DefaultError Coupon code already exists
+//	500: DefaultError Internal Server Error
+func (s *service) CreatePromotion(ctx context.Context, req *entities.CreatePromotionRequest) (*entities.Promotion, error) {
+	body := req.Body
+	if body == nil {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "promotion is required")
+	}
+
+	if err := validatePromotion(body); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	promotion := &entities.Promotion{
+		ID:                    uuid.New(),
+		Name:                  body.Name,
+		DiscountType:          body.DiscountType,
+		Value:                 body.Value,
+		MinSubtotal:           body.MinSubtotal,
+		UsageLimit:            body.UsageLimit,
+		UsageLimitPerCustomer: body.UsageLimitPerCustomer,
+		StartsAt:              body.StartsAt,
+		EndsAt:                body.EndsAt,
+		Active:                true,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}
+
+	if code := normalizeCode(body.Code); code != "" {
+		promotion.Code = &code
+	}
+
+	if promotion.DiscountType == entities.FreeShipping {
+		promotion.Value = decimal.Zero
+	}
+
+	if len(body.TargetSKUs) > 0 {
+		skus, err := json.Marshal(body.TargetSKUs)
+		if err != nil {
+			return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "target_skus is not valid")
+		}
+		promotion.TargetSKUs = skus
+	}
+
+	if len(body.TargetAttributes) > 0 {
+		attributes, err := json.Marshal(body.TargetAttributes)
+		if err != nil {
+			return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "target_attributes is not valid")
+		}
+		promotion.TargetAttributes = attributes
+	}
+
+	if err := s.repo.CreatePromotion(ctx, promotion); err != nil {
+		s.log.Errorf("Error creating promotion: %v", err)
+		// the code is taken already
+		var apiErr *sharedErrors.APIError
+		if errors.As(err, &apiErr) {
+			return nil, err
+		}
+		return nil, moduleErrors.NewAPIError("PROMOTION_ERROR_CREATING")
+	}
+
+	return promotion, nil
+}
+
+// swagger:route GET /promotions promotions ListPromotions
+//
+// # List Promotions
+// ### List the coupon codes and the automatic promotions, newest first
+//
+// Produces:
+//   - application/json
+//
+// Responses:
+//
+//	200: ListPromotionsResponse Promotions retrieved successfully
+//	500: DefaultError Internal Server Error
+func (s *service) ListPromotions(ctx context.Context) (*entities.ListPromotionsResponse, error) {
+	promotions, err := s.repo.ListPromotions(ctx)
+	if err != nil {
+		s.log.Errorf("Error listing promotions: %v", err)
+		return nil, moduleErrors.NewAPIError("PROMOTION_ERROR_GETTING")
+	}
+
+	return &entities.ListPromotionsResponse{Promotions: promotions}, nil
+}
+
+// CalculateDiscounts applies the automatic promotions and the coupon code to the cart. The automatic
+// promotions that don't apply are skipped, a coupon code that doesn't apply is an error. Each promotion
+// discounts what the previous ones left, the coupon code goes last.
+func (s *service) CalculateDiscounts(ctx context.Context, req *entities.CalculateDiscountsRequest) (*entities.CalculateDiscountsResponse, error) {
+	promotions, err := s.repo.GetAutomaticPromotions(ctx)
+	if err != nil {
+		s.log.Errorf("Error getting automatic promotions: %v", err)
+		return nil, moduleErrors.NewAPIError("PROMOTION_ERROR_GETTING")
+	}
+
+	var coupon *entities.Promotion
+	if code := normalizeCode(req.CouponCode); code != "" {
+		coupon, err = s.repo.GetPromotionByCode(ctx, code)
+		if err != nil {
+			s.log.Errorf("Error getting promotion %s: %v", code, err)
+			return nil, moduleErrors.NewAPIError("PROMOTION_ERROR_GETTING")
+		}
+		if coupon == nil {
+			return nil, moduleErrors.NewAPIError("PROMOTION_CODE_NOT_FOUND")
+		}
+	}
+
+	cart := newDiscountedCart(req)
+	now := time.Now()
+
+	for _, promotion := range promotions {
+		reason, err := s.ineligibility(ctx, promotion, req.CustomerID, cart.subtotal, now)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			continue
+		}
+
+		cart.apply(promotion)
+	}
+
+	if coupon != nil {
+		reason, err := s.ineligibility(ctx, coupon, req.CustomerID, cart.subtotal, now)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			return nil, moduleErrors.NewAPIError(reason)
+		}
+
+		if !cart.apply(coupon) {
+			return nil, moduleErrors.NewAPIError("PROMOTION_NOT_APPLICABLE")
+		}
+	}
+
+	return cart.response(), nil
+}
+
+// ineligibility returns the error code telling why the promotion can't be used on the cart, empty when it can.
+func (s *service) ineligibility(ctx context.Context, promotion *entities.Promotion, customerID uuid.UUID, subtotal decimal.Decimal, now time.Time) (string, error) {
+	if !promotion.InWindow(now) {
+		return "PROMOTION_CODE_NOT_ACTIVE", nil
+	}
+
+	if promotion.MinSubtotal != nil && subtotal.LessThan(*promotion.MinSubtotal) {
+		return "PROMOTION_MIN_SUBTOTAL_NOT_MET", nil
+	}
+
+	if promotion.UsageLimit != nil {
+		count, err := s.repo.CountRedemptions(ctx, promotion.ID, nil)
+		if err != nil {
+			s.log.Errorf("Error counting redemptions of promotion %s: %v", promotion.ID, err)
+			return "", moduleErrors.NewAPIError("PROMOTION_ERROR_GETTING")
+		}
+		if count >= int64(*promotion.UsageLimit) {
+			return "PROMOTION_USAGE_LIMIT_REACHED", nil
+		}
+	}
+
+	if promotion.UsageLimitPerCustomer != nil {
+		// the redemptions of a guest can't be told apart from other guests'
+		if customerID == uuid.Nil {
+			return "PROMOTION_CUSTOMER_REQUIRED", nil
+		}
+
+		count, err := s.repo.CountRedemptions(ctx, promotion.ID, &customerID)
+		if err != nil {
+			s.log.Errorf("Error counting redemptions of promotion %s: %v", promotion.ID, err)
+			return "", moduleErrors.NewAPIError("PROMOTION_ERROR_GETTING")
+		}
+		if count >= int64(*promotion.UsageLimitPerCustomer) {
+			return "PROMOTION_USAGE_LIMIT_REACHED", nil
+		}
+	}
+
+	return "", nil
+}
+
+// discountedCart keeps track of what is left to discount on each line and on the shipping.
+type discountedCart struct {
+	items            []entities.DiscountItem
+	subtotal         decimal.Decimal
+	remaining        []decimal.Decimal
+	itemDiscounts    []decimal.Decimal
+	shippingLeft     decimal.Decimal
+	shippingDiscount decimal.Decimal
+	discounts        []*entities.Discount
+}
+
+func newDiscountedCart(req *entities.CalculateDiscountsRequest) *discountedCart {
+	cart := &discountedCart{
+		items:         req.Items,
+		subtotal:      decimal.Zero,
+		remaining:     make([]decimal.Decimal, len(req.Items)),
+		itemDiscounts: make([]decimal.Decimal, len(req.Items)),
+		shippingLeft:  req.ShippingAmount,
+	}
+
+	for i, item := range req.Items {
+		cart.remaining[i] = item.Price.Mul(decimal.NewFromInt(int64(item.Quantity)))
+		cart.subtotal = cart.subtotal.Add(cart.remaining[i])
+	}
+
+	return cart
+}
+
+// apply takes the promotion off the cart, it reports false when no item is targeted by the promotion.
+func (c *discountedCart) apply(promotion *entities.Promotion) bool {
+	targeted := c.targetedItems(promotion)
+	if len(targeted) == 0 {
+		return false
+	}
+
+	amount := decimal.Zero
+
+	switch promotion.DiscountType {
+	case entities.PercentOff:
+		percent := decimal.Min(promotion.Value, hundred)
+		for _, i := range targeted {
+			discount := c.remaining[i].Mul(percent).Div(hundred).Round(discountPlaces)
+			amount = amount.Add(c.discountItem(i, discount))
+		}
+	case entities.FixedOff:
+		weights := make([]decimal.Decimal, len(targeted))
+		left := decimal.Zero
+		for j, i := range targeted {
+			weights[j] = c.remaining[i]
+			left = left.Add(c.remaining[i])
+		}
+		for j, share := range splitProportionally(decimal.Min(promotion.Value, left), weights) {
+			amount = amount.Add(c.discountItem(targeted[j], share))
+		}
+	case entities.FreeShipping:
+		amount = c.shippingLeft
+		c.shippingDiscount = c.shippingDiscount.Add(amount)
+		c.shippingLeft = decimal.Zero
+	}
+
+	if amount.IsPositive() {
+		c.discounts = append(c.discounts, &entities.Discount{
+			PromotionID:  promotion.ID,
+			Code:         promotion.Code,
+			Name:         promotion.Name,
+			DiscountType: promotion.DiscountType,
+			Amount:       amount,
+		})
+	}
+
+	return true
+}
+
+// discountItem takes up to discount off the line and returns what was taken.
+func (c *discountedCart) discountItem(i int, discount decimal.Decimal) decimal.Decimal {
+	discount = decimal.Min(discount, c.remaining[i])
+	c.remaining[i] = c.remaining[i].Sub(discount)
+	c.itemDiscounts[i] = c.itemDiscounts[i].Add(discount)
+
+	return discount
+}
+
+func (c *discountedCart) targetedItems(promotion *entities.Promotion) []int {
+	skus := targetSKUs(promotion.TargetSKUs)
+	attributes := jsonObject(promotion.TargetAttributes)
+
+	var targeted []int
+	for i, item := range c.items {
+		if len(skus) > 0 {
+			if _, ok := skus[item.SKU]; !ok {
+				continue
+			}
+		}
+
+		if len(attributes) > 0 && !matchesAttributes(item.Attributes, attributes) {
+			continue
+		}
+
+		targeted = append(targeted, i)
+	}
+
+	return targeted
+}
+
+func (c *discountedCart) response() *entities.CalculateDiscountsResponse {
+	itemsDiscount := decimal.Zero
+	for _, discount := range c.itemDiscounts {
+		itemsDiscount = itemsDiscount.Add(discount)
+	}
+
+	return &entities.CalculateDiscountsResponse{
+		ItemDiscounts:    c.itemDiscounts,
+		ItemsDiscount:    itemsDiscount,
+		ShippingDiscount: c.shippingDiscount,
+		Discounts:        c.discounts,
+	}
+}
+
+func targetSKUs(raw sharedJson.JSON) map[string]struct{} {
+	skus := make(map[string]struct{})
+	if len(raw) == 0 {
+		return skus
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return skus
+	}
+
+	for _, sku := range list {
+		skus[sku] = struct{}{}
+	}
+
+	return skus
+}
+
+func jsonObject(raw sharedJson.JSON) map[string]any {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil
+	}
+
+	return object
+}
+
+// matchesAttributes reports whether the item has every targeted attribute with the same value.
+func matchesAttributes(itemAttributes *sharedJson.JSON, targeted map[string]any) bool {
+	if itemAttributes == nil {
+		return false
+	}
+
+	attributes := jsonObject(*itemAttributes)
+	for key, value := range targeted {
+		if itemValue, ok := attributes[key]; !ok || !reflect.DeepEqual(itemValue, value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitProportionally splits total by weight, the last share absorbs the rounding difference.
+func splitProportionally(total decimal.Decimal, weights []decimal.Decimal) []decimal.Decimal {
+	shares := make([]decimal.Decimal, len(weights))
+	if len(weights) == 0 || !total.IsPositive() {
+		return shares
+	}
+
+	totalWeight := decimal.Zero
+	for _, weight := range weights {
+		totalWeight = totalWeight.Add(weight)
+	}
+	if totalWeight.IsZero() {
+		return shares
+	}
+
+	allocated := decimal.Zero
+	for i, weight := range weights[:len(weights)-1] {
+		shares[i] = total.Mul(weight).Div(totalWeight).Round(discountPlaces)
+		allocated = allocated.Add(shares[i])
+	}
+	shares[len(weights)-1] = total.Sub(allocated)
+
+	return shares
+}
+
+func validatePromotion(body *entities.CreatePromotionRequestBody) error {
+	if strings.TrimSpace(body.Name) == "" {
+		return moduleErrors.NewAPIError("VALIDATION_ERROR", "name is required")
+	}
+
+	switch body.DiscountType {
+	case entities.PercentOff:
+		if !body.Value.IsPositive() || body.Value.GreaterThan(hundred) {
+			return moduleErrors.NewAPIError("VALIDATION_ERROR", "value should be a percent between 0 and 100")
+		}
+	case entities.FixedOff:
+		if !body.Value.IsPositive() {
+			return moduleErrors.NewAPIError("VALIDATION_ERROR", "value should be positive")
+		}
+	case entities.FreeShipping:
+	default:
+		return moduleErrors.NewAPIError("VALIDATION_ERROR", "discount_type should be percent_off, fixed_off or free_shipping")
+	}
+
+	if body.MinSubtotal != nil && body.MinSubtotal.IsNegative() {
+		return moduleErrors.NewAPIError("VALIDATION_ERROR", "min_subtotal shouldn't be negative")
+	}
+
+	if (body.UsageLimit != nil && *body.UsageLimit <= 0) || (body.UsageLimitPerCustomer != nil && *body.UsageLimitPerCustomer <= 0) {
+		return moduleErrors.NewAPIError("VALIDATION_ERROR", "usage limits should be positive")
+	}
+
+	if body.StartsAt != nil && body.EndsAt != nil && !body.EndsAt.After(*body.StartsAt) {
+		return moduleErrors.NewAPIError("VALIDATION_ERROR", "ends_at should be after starts_at")
+	}
+
+	return nil
+}
+
+// normalizeCode makes coupon codes case insensitive.
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
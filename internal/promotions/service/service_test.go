@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/entities"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/promotions/errors"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/repository"
+	appErrors "github.com/nurdsoft/nurd-commerce-core/shared/errors"
+	sharedJson "github.com/nurdsoft/nurd-commerce-core/shared/json"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func setup(t *testing.T) (*service, *repository.MockRepository) {
+	ctrl := gomock.NewController(t)
+	mockRepo := repository.NewMockRepository(ctrl)
+
+	return &service{repo: mockRepo, log: zap.NewExample().Sugar()}, mockRepo
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func decimalPtr(i int64) *decimal.Decimal {
+	d := decimal.NewFromInt(i)
+	return &d
+}
+
+func decimalStrings(values []decimal.Decimal) []string {
+	strs := make([]string, len(values))
+	for i, value := range values {
+		strs[i] = value.String()
+	}
+	return strs
+}
+
+func cartRequest(couponCode string) *entities.CalculateDiscountsRequest {
+	shoes := sharedJson.JSON(`{"category":"shoes","color":"red"}`)
+
+	return &entities.CalculateDiscountsRequest{
+		CustomerID: uuid.New(),
+		CouponCode: couponCode,
+		Items: []entities.DiscountItem{
+			{SKU: "SHOE-1", Price: decimal.NewFromInt(30), Quantity: 2, Attributes: &shoes},
+			{SKU: "HAT-1", Price: decimal.NewFromInt(40), Quantity: 1},
+		},
+		ShippingAmount: decimal.NewFromInt(10),
+	}
+}
+
+func TestCreatePromotion(t *testing.T) {
+	t.Run("creates a coupon code", func(t *testing.T) {
+		svc, mockRepo := setup(t)
+
+		mockRepo.EXPECT().CreatePromotion(gomock.Any(), gomock.Any()).Return(nil)
+
+		promotion, err := svc.CreatePromotion(context.Background(), &entities.CreatePromotionRequest{
+			Body: &entities.CreatePromotionRequestBody{
+				Code:             " summer10 ",
+				Name:             "Summer sale",
+				DiscountType:     entities.PercentOff,
+				Value:            decimal.NewFromInt(10),
+				TargetSKUs:       []string{"SHOE-1"},
+				TargetAttributes: map[string]any{"category": "shoes"},
+			},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "SUMMER10", *promotion.Code)
+		assert.True(t, promotion.Active)
+		assert.JSONEq(t, `["SHOE-1"]`, string(promotion.TargetSKUs))
+		assert.JSONEq(t, `{"category":"shoes"}`, string(promotion.TargetAttributes))
+	})
+
+	t.Run("creates an automatic promotion without a code", func(t *testing.T) {
+		svc, mockRepo := setup(t)
+
+		mockRepo.EXPECT().CreatePromotion(gomock.Any(), gomock.Any()).Return(nil)
+
+		promotion, err := svc.CreatePromotion(context.Background(), &entities.CreatePromotionRequest{
+			Body: &entities.CreatePromotionRequestBody{
+				Name:         "Free shipping",
+				DiscountType: entities.FreeShipping,
+				Value:        decimal.NewFromInt(5),
+			},
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, promotion.Automatic())
+		assert.True(t, promotion.Value.IsZero())
+	})
+
+	t.Run("rejects invalid promotions", func(t *testing.T) {
+		svc, _ := setup(t)
+		startsAt := time.Now()
+		endsAt := startsAt.Add(-time.Hour)
+
+		bodies := []*entities.CreatePromotionRequestBody{
+			{DiscountType: entities.PercentOff, Value: decimal.NewFromInt(10)},
+			{Name: "Too much", DiscountType: entities.PercentOff, Value: decimal.NewFromInt(101)},
+			{Name: "Nothing off", DiscountType: entities.FixedOff},
+			{Name: "Unknown", DiscountType: "buy_one_get_one", Value: decimal.NewFromInt(1)},
+			{Name: "No usage", DiscountType: entities.FixedOff, Value: decimal.NewFromInt(1), UsageLimit: intPtr(0)},
+			{Name: "Backwards", DiscountType: entities.FixedOff, Value: decimal.NewFromInt(1), StartsAt: &startsAt, EndsAt: &endsAt},
+		}
+
+		for _, body := range bodies {
+			_, err := svc.CreatePromotion(context.Background(), &entities.CreatePromotionRequest{Body: body})
+
+			var apiErr *appErrors.APIError
+			if assert.ErrorAs(t, err, &apiErr, body.Name) {
+				assert.Equal(t, "VALIDATION_ERROR", apiErr.ErrorCode, body.Name)
+			}
+		}
+	})
+
+	t.Run("code already exists", func(t *testing.T) {
+		svc, mockRepo := setup(t)
+
+		mockRepo.EXPECT().CreatePromotion(gomock.Any(), gomock.Any()).Return(moduleErrors.NewAPIError("PROMOTION_CODE_ALREADY_EXISTS"))
+
+		_, err := svc.CreatePromotion(context.Background(), &entities.CreatePromotionRequest{
+			Body: &entities.CreatePromotionRequestBody{Code: "SUMMER10", Name: "Summer sale", DiscountType: entities.FixedOff, Value: decimal.NewFromInt(5)},
+		})
+
+		assert.Equal(t, moduleErrors.NewAPIError("PROMOTION_CODE_ALREADY_EXISTS"), err)
+	})
+}
+
+func TestCalculateDiscounts(t *testing.T) {
+	t.Run("no promotions", func(t *testing.T) {
+		svc, mockRepo := setup(t)
+
+		mockRepo.EXPECT().GetAutomaticPromotions(gomock.Any()).Return(nil, nil)
+
+		resp, err := svc.CalculateDiscounts(context.Background(), cartRequest(""))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"0", "0"}, decimalStrings(resp.ItemDiscounts))
+		assert.True(t, resp.Total().IsZero())
+		assert.Empty(t, resp.Discounts)
+	})
+
+	t.Run("percent off coupon targeting attributes", func(t *testing.T) {
+		svc, mockRepo := setup(t)
+
+		mockRepo.EXPECT().GetAutomaticPromotions(gomock.Any()).Return(nil, nil)
+		mockRepo.EXPECT().GetPromotionByCode(gomock.Any(), "SHOES15").Return(&entities.Promotion{
+			ID:               uuid.New(),
+			Code:             strPtr("SHOES15"),
+			Name:             "Shoes",
+			DiscountType:     entities.PercentOff,
+			Value:            decimal.NewFromInt(15),
+			TargetAttributes: sharedJson.JSON(`{"category":"shoes"}`),
+			Active:           true,
+		}, nil)
+
+		resp, err := svc.CalculateDiscounts(context.Background(), cartRequest("shoes15"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"9", "0"}, decimalStrings(resp.ItemDiscounts))
+		assert.Equal(t, "9", resp.ItemsDiscount.String())
+		assert.Len(t, resp.Discounts, 1)
+		assert.Equal(t, "SHOES15", *resp.Discounts[0].Code)
+	})
+
+	t.Run("fixed off coupon is split by line subtotal", func(t *testing.T) {
+		svc, mockRepo := setup(t)
+
+		mockRepo.EXPECT().GetAutomaticPromotions(gomock.Any()).Return(nil, nil)
+		mockRepo.EXPECT().GetPromotionByCode(gomock.Any(), "TEN").Return(&entities.Promotion{
+			ID: uuid.New(), Code: strPtr("TEN"), DiscountType: entities.FixedOff, Value: decimal.NewFromInt(10), Active: true,
+		}, nil)
+
+		resp, err := svc.CalculateDiscounts(context.Background(), cartRequest("TEN"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"6", "4"}, decimalStrings(resp.ItemDiscounts))
+		assert.True(t, resp.ShippingDiscount.IsZero())
+	})
+
+	t.Run("fixed off never exceeds the targeted items", func(t *testing.T) {
+		svc, mockRepo := setup(t)
+
+		mockRepo.EXPECT().GetAutomaticPromotions(gomock.Any()).Return(nil, nil)
+		mockRepo.EXPECT().GetPromotionByCode(gomock.Any(), "HATS").Return(&entities.Promotion{
+			ID: uuid.New(), Code: strPtr("HATS"), DiscountType: entities.FixedOff, Value: decimal.NewFromInt(100),
+			TargetSKUs: sharedJson.JSON(`["HAT-1"]`), Active: true,
+		}, nil)
+
+		resp, err := svc.CalculateDiscounts(context.Background(), cartRequest("HATS"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"0", "40"}, decimalStrings(resp.ItemDiscounts))
+		assert.Equal(t, "40", resp.Discounts[0].Amount.String())
+	})
+
+	t.Run("automatic promotions apply before the coupon", func(t *testing.T) {
+		svc, mockRepo := setup(t)
+
+		mockRepo.EXPECT().GetAutomaticPromotions(gomock.Any()).Return([]*entities.Promotion{
+			{ID: uuid.New(), Name: "Free shipping", DiscountType: entities.FreeShipping, Active: true},
+			{ID: uuid.New(), Name: "Big spenders", DiscountType: entities.PercentOff, Value: decimal.NewFromInt(50), MinSubtotal: decimalPtr(500), Active: true},
+			{ID: uuid.New(), Name: "Half off", DiscountType: entities.PercentOff, Value: decimal.NewFromInt(50), Active: true},
+		}, nil)
+		mockRepo.EXPECT().GetPromotionByCode(gomock.Any(), "TEN").Return(&entities.Promotion{
+			ID: uuid.New(), Code: strPtr("TEN"), DiscountType: entities.FixedOff, Value: decimal.NewFromInt(10), Active: true,
+		}, nil)
+
+		resp, err := svc.CalculateDiscounts(context.Background(), cartRequest("TEN"))
+
+		assert.NoError(t, err)
+		// half off 60 and 40, then 10 split over what is left
+		assert.Equal(t, []string{"36", "24"}, decimalStrings(resp.ItemDiscounts))
+		assert.Equal(t, "10", resp.ShippingDiscount.String())
+		assert.Equal(t, "70", resp.Total().String())
+		assert.Len(t, resp.Discounts, 3)
+	})
+
+	t.Run("coupon code not found", func(t *testing.T) {
+		svc, mockRepo := setup(t)
+
+		mockRepo.EXPECT().GetAutomaticPromotions(gomock.Any()).Return(nil, nil)
+		mockRepo.EXPECT().GetPromotionByCode(gomock.Any(), "NOPE").Return(nil, nil)
+
+		_, err := svc.CalculateDiscounts(context.Background(), cartRequest("NOPE"))
+
+		assert.Equal(t, moduleErrors.NewAPIError("PROMOTION_CODE_NOT_FOUND"), err)
+	})
+
+	t.Run("coupon code expired", func(t *testing.T) {
+		svc, mockRepo := setup(t)
+		endsAt := time.Now().Add(-time.Hour)
+
+		mockRepo.EXPECT().GetAutomaticPromotions(gomock.Any()).Return(nil, nil)
+		mockRepo.EXPECT().GetPromotionByCode(gomock.Any(), "OLD").Return(&entities.Promotion{
+			ID: uuid.New(), Code: strPtr("OLD"), DiscountType: entities.FixedOff, Value: decimal.NewFromInt(10), EndsAt: &endsAt, Active: true,
+		}, nil)
+
+		_, err := svc.CalculateDiscounts(context.Background(), cartRequest("OLD"))
+
+		assert.Equal(t, moduleErrors.NewAPIError("PROMOTION_CODE_NOT_ACTIVE"), err)
+	})
+
+	t.Run("coupon code below the minimum subtotal", func(t *testing.T) {
+		svc, mockRepo := setup(t)
+
+		mockRepo.EXPECT().GetAutomaticPromotions(gomock.Any()).Return(nil, nil)
+		mockRepo.EXPECT().GetPromotionByCode(gomock.Any(), "BIG").Return(&entities.Promotion{
+			ID: uuid.New(), Code: strPtr("BIG"), DiscountType: entities.FixedOff, Value: decimal.NewFromInt(10), MinSubtotal: decimalPtr(200), Active: true,
+		}, nil)
+
+		_, err := svc.CalculateDiscounts(context.Background(), cartRequest("BIG"))
+
+		assert.Equal(t, moduleErrors.NewAPIError("PROMOTION_MIN_SUBTOTAL_NOT_MET"), err)
+	})
+
+	t.Run("coupon code used up by the customer", func(t *testing.T) {
+		svc, mockRepo := setup(t)
+		req := cartRequest("ONCE")
+		promotionID := uuid.New()
+
+		mockRepo.EXPECT().GetAutomaticPromotions(gomock.Any()).Return(nil, nil)
+		mockRepo.EXPECT().GetPromotionByCode(gomock.Any(), "ONCE").Return(&entities.Promotion{
+			ID: promotionID, Code: strPtr("ONCE"), DiscountType: entities.FixedOff, Value: decimal.NewFromInt(10),
+			UsageLimit: intPtr(100), UsageLimitPerCustomer: intPtr(1), Active: true,
+		}, nil)
+		mockRepo.EXPECT().CountRedemptions(gomock.Any(), promotionID, nil).Return(int64(5), nil)
+		mockRepo.EXPECT().CountRedemptions(gomock.Any(), promotionID, &req.CustomerID).Return(int64(1), nil)
+
+		_, err := svc.CalculateDiscounts(context.Background(), req)
+
+		assert.Equal(t, moduleErrors.NewAPIError("PROMOTION_USAGE_LIMIT_REACHED"), err)
+	})
+
+	t.Run("coupon code limited per customer needs a signed in customer", func(t *testing.T) {
+		svc, mockRepo := setup(t)
+		req := cartRequest("ONCE")
+		req.CustomerID = uuid.Nil
+		promotionID := uuid.New()
+
+		mockRepo.EXPECT().GetAutomaticPromotions(gomock.Any()).Return(nil, nil)
+		mockRepo.EXPECT().GetPromotionByCode(gomock.Any(), "ONCE").Return(&entities.Promotion{
+			ID: promotionID, Code: strPtr("ONCE"), DiscountType: entities.FixedOff, Value: decimal.NewFromInt(10),
+			UsageLimitPerCustomer: intPtr(1), Active: true,
+		}, nil)
+
+		_, err := svc.CalculateDiscounts(context.Background(), req)
+
+		assert.Equal(t, moduleErrors.NewAPIError("PROMOTION_CUSTOMER_REQUIRED"), err)
+	})
+
+	t.Run("coupon code doesn't target any item", func(t *testing.T) {
+		svc, mockRepo := setup(t)
+
+		mockRepo.EXPECT().GetAutomaticPromotions(gomock.Any()).Return(nil, nil)
+		mockRepo.EXPECT().GetPromotionByCode(gomock.Any(), "BAGS").Return(&entities.Promotion{
+			ID: uuid.New(), Code: strPtr("BAGS"), DiscountType: entities.PercentOff, Value: decimal.NewFromInt(10),
+			TargetSKUs: sharedJson.JSON(`["BAG-1"]`), Active: true,
+		}, nil)
+
+		_, err := svc.CalculateDiscounts(context.Background(), cartRequest("BAGS"))
+
+		assert.Equal(t, moduleErrors.NewAPIError("PROMOTION_NOT_APPLICABLE"), err)
+	})
+
+	t.Run("error getting automatic promotions", func(t *testing.T) {
+		svc, mockRepo := setup(t)
+
+		mockRepo.EXPECT().GetAutomaticPromotions(gomock.Any()).Return(nil, errors.New("db error"))
+
+		_, err := svc.CalculateDiscounts(context.Background(), cartRequest(""))
+
+		assert.Equal(t, moduleErrors.NewAPIError("PROMOTION_ERROR_GETTING"), err)
+	})
+}
@@ -0,0 +1,29 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/entities"
+	httpError "github.com/nurdsoft/nurd-commerce-core/shared/errors/http"
+	"github.com/pkg/errors"
+)
+
+func decodeCreatePromotionRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	reqBody := &entities.CreatePromotionRequestBody{}
+	err := json.NewDecoder(r.Body).Decode(reqBody)
+	if err != nil {
+		return nil, errors.WithMessage(httpError.ErrBadRequestBody, err.Error())
+	}
+
+	defer r.Body.Close()
+
+	return &entities.CreatePromotionRequest{
+		Body: reqBody,
+	}, nil
+}
+
+func decodeListPromotionsRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return nil, nil
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	goKitEndpoint "github.com/go-kit/kit/endpoint"
+	goKitHTTPTransport "github.com/go-kit/kit/transport/http"
+	"github.com/nurdsoft/nurd-commerce-core/internal/promotions/endpoints"
+	svcTransport "github.com/nurdsoft/nurd-commerce-core/internal/transport"
+	"github.com/nurdsoft/nurd-commerce-core/internal/transport/http/encode"
+	httpTransport "github.com/nurdsoft/nurd-commerce-core/shared/transport/http"
+)
+
+// RegisterTransport for http.
+func RegisterTransport(
+	server *httpTransport.Server,
+	ep *endpoints.Endpoints,
+	svcTransportClient svcTransport.Client,
+) {
+	registerCreatePromotion(server, ep.CreatePromotionEndpoint, svcTransportClient)
+	registerListPromotions(server, ep.ListPromotionsEndpoint, svcTransportClient)
+}
+
+func registerCreatePromotion(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "POST"
+	path := "/promotions"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeCreatePromotionRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
+
+func registerListPromotions(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "GET"
+	path := "/promotions"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeListPromotionsRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
@@ -10,19 +10,27 @@ import (
 )
 
 type Endpoints struct {
-	StripeGetPaymentMethodsEndpoint endpoint.Endpoint
-	StripeGetPaymentMethodEndpoint  endpoint.Endpoint
-	StripeGetSetupIntentEndpoint    endpoint.Endpoint
-	StripeWebhookEndpoint           endpoint.Endpoint
-	StripeRefundEndpoint            endpoint.Endpoint
+	StripeGetPaymentMethodsEndpoint       endpoint.Endpoint
+	StripeGetPaymentMethodEndpoint        endpoint.Endpoint
+	StripeDeletePaymentMethodEndpoint     endpoint.Endpoint
+	StripeSetDefaultPaymentMethodEndpoint endpoint.Endpoint
+	StripeGetSetupIntentEndpoint          endpoint.Endpoint
+	StripeWebhookEndpoint                 endpoint.Endpoint
+	StripeRefundEndpoint                  endpoint.Endpoint
+	StripeListEventsEndpoint              endpoint.Endpoint
+	StripeReplayEventEndpoint             endpoint.Endpoint
 }
 
 func New(svc service.Service) *Endpoints {
 	return &Endpoints{
-		StripeGetPaymentMethodsEndpoint: makeStripeGetPaymentMethods(svc),
-		StripeGetPaymentMethodEndpoint:  makeStripeGetPaymentMethod(svc),
-		StripeGetSetupIntentEndpoint:    makeStripeGetSetupIntent(svc),
-		StripeWebhookEndpoint:           makeStripeWebhookEndpoint(svc),
+		StripeGetPaymentMethodsEndpoint:       makeStripeGetPaymentMethods(svc),
+		StripeGetPaymentMethodEndpoint:        makeStripeGetPaymentMethod(svc),
+		StripeDeletePaymentMethodEndpoint:     makeStripeDeletePaymentMethod(svc),
+		StripeSetDefaultPaymentMethodEndpoint: makeStripeSetDefaultPaymentMethod(svc),
+		StripeGetSetupIntentEndpoint:          makeStripeGetSetupIntent(svc),
+		StripeWebhookEndpoint:                 makeStripeWebhookEndpoint(svc),
+		StripeListEventsEndpoint:              makeStripeListEventsEndpoint(svc),
+		StripeReplayEventEndpoint:             makeStripeReplayEventEndpoint(svc),
 	}
 }
 
@@ -39,6 +47,20 @@ func makeStripeGetPaymentMethod(svc service.Service) endpoint.Endpoint {
 	}
 }
 
+func makeStripeDeletePaymentMethod(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(*entities.StripeDeletePaymentMethodRequest)
+		return nil, svc.DeletePaymentMethod(ctx, req)
+	}
+}
+
+func makeStripeSetDefaultPaymentMethod(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(*entities.StripeSetDefaultPaymentMethodRequest)
+		return svc.SetDefaultPaymentMethod(ctx, req)
+	}
+}
+
 func makeStripeGetSetupIntent(svc service.Service) endpoint.Endpoint {
 	return func(ctx context.Context, _ interface{}) (interface{}, error) {
 		return svc.GetSetupIntent(ctx)
@@ -55,3 +77,17 @@ func makeStripeWebhookEndpoint(svc service.Service) endpoint.Endpoint {
 		return map[string]string{"status": "success"}, nil
 	}
 }
+
+func makeStripeListEventsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(*entities.ListPaymentEventsRequest)
+		return svc.ListPaymentEvents(ctx, req)
+	}
+}
+
+func makeStripeReplayEventEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(*entities.ReplayPaymentEventRequest)
+		return svc.ReplayPaymentEvent(ctx, req)
+	}
+}
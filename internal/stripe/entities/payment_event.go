@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	sharedJSON "github.com/nurdsoft/nurd-commerce-core/shared/json"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+)
+
+type PaymentEventStatus string
+
+func (s PaymentEventStatus) String() string {
+	return string(s)
+}
+
+const (
+	// PaymentEventPending is used for events received but not handled yet
+	PaymentEventPending PaymentEventStatus = "pending"
+	// PaymentEventProcessing is used for events claimed by a delivery or a replay that is handling them
+	PaymentEventProcessing PaymentEventStatus = "processing"
+	// PaymentEventProcessed is used for events handled successfully
+	PaymentEventProcessed PaymentEventStatus = "processed"
+	// PaymentEventFailed is used for events whose last attempt failed, they can be replayed
+	PaymentEventFailed PaymentEventStatus = "failed"
+)
+
+func (s PaymentEventStatus) IsValid() bool {
+	switch s {
+	case PaymentEventPending, PaymentEventProcessing, PaymentEventProcessed, PaymentEventFailed:
+		return true
+	}
+	return false
+}
+
+// PaymentEvent is a verified webhook event received from the payment provider.
+// An event is processed once, redeliveries of an event that is processed or being processed are skipped.
+type PaymentEvent struct {
+	ID          uuid.UUID              `json:"id" gorm:"column:id;default:gen_random_uuid()"`
+	Provider    providers.ProviderType `json:"provider" gorm:"column:provider"`
+	EventID     string                 `json:"event_id" gorm:"column:event_id"`
+	Type        string                 `json:"type" gorm:"column:type"`
+	ObjectID    string                 `json:"object_id" gorm:"column:object_id"`
+	Payload     sharedJSON.JSON        `json:"payload" gorm:"column:payload"`
+	Status      PaymentEventStatus     `json:"status" gorm:"column:status;default:pending"`
+	ProcessedAt *time.Time             `json:"processed_at" gorm:"column:processed_at"`
+	Error       *string                `json:"error,omitempty" gorm:"column:error"`
+	Attempts    int                    `json:"attempts" gorm:"column:attempts"`
+	CreatedAt   time.Time              `json:"created_at" gorm:"column:created_at;default:now()"`
+	UpdatedAt   time.Time              `json:"updated_at" gorm:"column:updated_at;default:now()"`
+}
+
+func (e *PaymentEvent) TableName() string {
+	return "payment_events"
+}
@@ -24,6 +24,26 @@ type StripeGetPaymentMethodRequest struct {
 	PaymentMethodId string `json:"payment_method_id"`
 }
 
+// swagger:parameters stripe DeletePaymentMethodRequest
+type StripeDeletePaymentMethodRequest struct {
+	// Payment Method ID
+	//
+	// required: true
+	// in:path
+	// example: pm_1J2Y3Z4A5B6C7D8E9F0G
+	PaymentMethodId string `json:"payment_method_id"`
+}
+
+// swagger:parameters stripe SetDefaultPaymentMethodRequest
+type StripeSetDefaultPaymentMethodRequest struct {
+	// Payment Method ID
+	//
+	// required: true
+	// in:path
+	// example: pm_1J2Y3Z4A5B6C7D8E9F0G
+	PaymentMethodId string `json:"payment_method_id"`
+}
+
 // swagger:parameters stripe StripeRefundRequest
 type StripeRefundRequest struct {
 	// Payment Intent ID
@@ -41,3 +61,33 @@ type StripeRefundRequest struct {
 type StripeRefundRequestBody struct {
 	Amount string `json:"amount"`
 }
+
+// swagger:parameters stripe ListPaymentEventsRequest
+type ListPaymentEventsRequest struct {
+	// Status of the events to return: pending, processing, processed or failed
+	//
+	// in:query
+	// example: failed
+	Status PaymentEventStatus `json:"status"`
+	// Limit of events to return
+	//
+	// required: true
+	// in:query
+	// example: 10
+	Limit int `json:"limit"`
+	// Cursor to paginate events
+	//
+	// in:query
+	// example: MjAyNS0wOS0wNFQwOTozMDowMC4xMjM0NTZa
+	Cursor string `json:"cursor"`
+}
+
+// swagger:parameters stripe ReplayPaymentEventRequest
+type ReplayPaymentEventRequest struct {
+	// Stripe Event ID
+	//
+	// required: true
+	// in:path
+	// example: evt_1J2Y3Z4A5B6C7D8E9F0G
+	EventID string `json:"event_id"`
+}
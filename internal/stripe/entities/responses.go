@@ -20,6 +20,8 @@ type PaymentMethod struct {
 	ExpiryYear   int64   `json:"expiry_year"`
 	Wallet       *string `json:"wallet"`
 	Created      int64   `json:"created"`
+	// Default is set for the payment method charged when an order doesn't name one
+	Default bool `json:"default"`
 }
 
 type SetupIntent struct {
@@ -41,3 +43,14 @@ type StripeRefundResponse struct {
 	Id     string `json:"id"`
 	Status string `json:"status"`
 }
+
+// swagger:model ListPaymentEventsResponse
+type ListPaymentEventsResponse struct {
+	Events     []*PaymentEvent `json:"events"`
+	NextCursor string          `json:"next_cursor"`
+}
+
+// swagger:model ReplayPaymentEventResponse
+type ReplayPaymentEventResponse struct {
+	Event *PaymentEvent `json:"event"`
+}
@@ -13,6 +13,12 @@ var moduleErrors = map[string]struct {
 }{
 	"STRIPE_SIGNATURE_VERIFICATION_FAILED": {StatusCode: http.StatusBadRequest, Message: "Stripe webhook signature verification failed"},
 	"STRIPE_INVALID_REFUND_AMOUNT":         {StatusCode: http.StatusBadRequest, Message: "Invalid refund amount specified"},
+	"STRIPE_EVENT_NOT_FOUND":               {StatusCode: http.StatusNotFound, Message: "Stripe event not found"},
+	"STRIPE_EVENT_ALREADY_PROCESSED":       {StatusCode: http.StatusConflict, Message: "Stripe event has already been processed"},
+	"STRIPE_EVENT_IN_PROGRESS":             {StatusCode: http.StatusConflict, Message: "Stripe event is being processed"},
+	"STRIPE_ERROR_SAVING_EVENT":            {StatusCode: http.StatusInternalServerError, Message: "Error saving Stripe event"},
+	"STRIPE_ERROR_LISTING_EVENTS":          {StatusCode: http.StatusInternalServerError, Message: "Error listing Stripe events"},
+	"STRIPE_EVENT_PROCESSING_FAILED":       {StatusCode: http.StatusInternalServerError, Message: "Stripe event could not be processed"},
 }
 
 func NewAPIError(errorCode string, customMessage ...string) *errors.APIError {
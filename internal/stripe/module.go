@@ -5,6 +5,7 @@ import (
 
 	"github.com/nurdsoft/nurd-commerce-core/internal/customer/customerclient"
 	"github.com/nurdsoft/nurd-commerce-core/internal/orders/ordersclient"
+	"github.com/nurdsoft/nurd-commerce-core/internal/stripe/repository"
 	"github.com/nurdsoft/nurd-commerce-core/internal/stripe/service"
 	"github.com/nurdsoft/nurd-commerce-core/internal/stripe/transport/http"
 	stripeClient "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe/client"
@@ -34,7 +35,8 @@ type ModuleParams struct {
 // NewModule
 // nolint:gocritic
 func NewModule(p ModuleParams) error {
-	svc := service.New(p.Logger, p.StripeClient, p.OrdersClient, p.CustomerClient)
+	repo := repository.New(p.DB, p.GormDB)
+	svc := service.New(p.Logger, repo, p.StripeClient, p.OrdersClient, p.CustomerClient)
 	eps := endpoints.New(svc)
 
 	http.RegisterTransport(p.HTTPServer, eps, p.APPTransport)
@@ -0,0 +1,127 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/stripe/repository/repository.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	entities "github.com/nurdsoft/nurd-commerce-core/internal/stripe/entities"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimPaymentEvent mocks base method.
+func (m *MockRepository) ClaimPaymentEvent(ctx context.Context, id uuid.UUID, lease time.Duration) (*entities.PaymentEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPaymentEvent", ctx, id, lease)
+	ret0, _ := ret[0].(*entities.PaymentEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPaymentEvent indicates an expected call of ClaimPaymentEvent.
+func (mr *MockRepositoryMockRecorder) ClaimPaymentEvent(ctx, id, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPaymentEvent", reflect.TypeOf((*MockRepository)(nil).ClaimPaymentEvent), ctx, id, lease)
+}
+
+// GetPaymentEvent mocks base method.
+func (m *MockRepository) GetPaymentEvent(ctx context.Context, eventID string) (*entities.PaymentEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentEvent", ctx, eventID)
+	ret0, _ := ret[0].(*entities.PaymentEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentEvent indicates an expected call of GetPaymentEvent.
+func (mr *MockRepositoryMockRecorder) GetPaymentEvent(ctx, eventID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentEvent", reflect.TypeOf((*MockRepository)(nil).GetPaymentEvent), ctx, eventID)
+}
+
+// ListPaymentEvents mocks base method.
+func (m *MockRepository) ListPaymentEvents(ctx context.Context, status entities.PaymentEventStatus, limit int, cursor string) ([]*entities.PaymentEvent, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPaymentEvents", ctx, status, limit, cursor)
+	ret0, _ := ret[0].([]*entities.PaymentEvent)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListPaymentEvents indicates an expected call of ListPaymentEvents.
+func (mr *MockRepositoryMockRecorder) ListPaymentEvents(ctx, status, limit, cursor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaymentEvents", reflect.TypeOf((*MockRepository)(nil).ListPaymentEvents), ctx, status, limit, cursor)
+}
+
+// MarkPaymentEventFailed mocks base method.
+func (m *MockRepository) MarkPaymentEventFailed(ctx context.Context, id uuid.UUID, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPaymentEventFailed", ctx, id, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPaymentEventFailed indicates an expected call of MarkPaymentEventFailed.
+func (mr *MockRepositoryMockRecorder) MarkPaymentEventFailed(ctx, id, lastError interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPaymentEventFailed", reflect.TypeOf((*MockRepository)(nil).MarkPaymentEventFailed), ctx, id, lastError)
+}
+
+// MarkPaymentEventProcessed mocks base method.
+func (m *MockRepository) MarkPaymentEventProcessed(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPaymentEventProcessed", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPaymentEventProcessed indicates an expected call of MarkPaymentEventProcessed.
+func (mr *MockRepositoryMockRecorder) MarkPaymentEventProcessed(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPaymentEventProcessed", reflect.TypeOf((*MockRepository)(nil).MarkPaymentEventProcessed), ctx, id)
+}
+
+// SavePaymentEvent mocks base method.
+func (m *MockRepository) SavePaymentEvent(ctx context.Context, event *entities.PaymentEvent) (*entities.PaymentEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePaymentEvent", ctx, event)
+	ret0, _ := ret[0].(*entities.PaymentEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SavePaymentEvent indicates an expected call of SavePaymentEvent.
+func (mr *MockRepositoryMockRecorder) SavePaymentEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePaymentEvent", reflect.TypeOf((*MockRepository)(nil).SavePaymentEvent), ctx, event)
+}
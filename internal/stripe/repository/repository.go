@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/stripe/entities"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	SavePaymentEvent(ctx context.Context, event *entities.PaymentEvent) (*entities.PaymentEvent, error)
+	GetPaymentEvent(ctx context.Context, eventID string) (*entities.PaymentEvent, error)
+	ListPaymentEvents(ctx context.Context, status entities.PaymentEventStatus, limit int, cursor string) ([]*entities.PaymentEvent, string, error)
+	// ClaimPaymentEvent marks the event as processing unless it's processed or another attempt holds it,
+	// nil is returned when the event couldn't be claimed. A claim older than lease can be taken over.
+	ClaimPaymentEvent(ctx context.Context, id uuid.UUID, lease time.Duration) (*entities.PaymentEvent, error)
+	MarkPaymentEventProcessed(ctx context.Context, id uuid.UUID) error
+	MarkPaymentEventFailed(ctx context.Context, id uuid.UUID, lastError string) error
+}
+
+// New repository for stripe.
+func New(_ *sql.DB, gormDB *gorm.DB) Repository {
+	repo := &sqlRepository{gormDB}
+	return repo
+}
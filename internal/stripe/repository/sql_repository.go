@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/internal/stripe/entities"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/stripe/errors"
+	dbErrors "github.com/nurdsoft/nurd-commerce-core/shared/db"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type sqlRepository struct {
+	gormDB *gorm.DB
+}
+
+// SavePaymentEvent stores the event the first time it is received and returns the stored event,
+// which for a redelivered event carries the outcome of the previous attempts.
+func (r *sqlRepository) SavePaymentEvent(ctx context.Context, event *entities.PaymentEvent) (*entities.PaymentEvent, error) {
+	err := r.gormDB.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(event).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stored := &entities.PaymentEvent{}
+	err = r.gormDB.WithContext(ctx).
+		Where("provider = ? AND event_id = ?", event.Provider, event.EventID).
+		First(stored).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+func (r *sqlRepository) GetPaymentEvent(ctx context.Context, eventID string) (*entities.PaymentEvent, error) {
+	event := &entities.PaymentEvent{}
+	err := r.gormDB.WithContext(ctx).
+		Where("provider = ? AND event_id = ?", providers.ProviderStripe, eventID).
+		First(event).Error
+	if err != nil {
+		if dbErrors.IsNotFoundError(err) {
+			return nil, moduleErrors.NewAPIError("STRIPE_EVENT_NOT_FOUND")
+		}
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func (r *sqlRepository) ListPaymentEvents(ctx context.Context, status entities.PaymentEventStatus, limit int, cursor string) ([]*entities.PaymentEvent, string, error) {
+	query := r.gormDB.WithContext(ctx).
+		Where("provider = ? AND status = ?", providers.ProviderStripe, status).
+		Order("created_at DESC").
+		Limit(limit + 1)
+
+	if cursor != "" {
+		decodedCursor, err := base64.StdEncoding.DecodeString(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("created_at < ?", string(decodedCursor))
+	}
+
+	var events []*entities.PaymentEvent
+	if err := query.Find(&events).Error; err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(events) > limit {
+		lastEvent := events[limit-1]
+		nextCursor = base64.StdEncoding.EncodeToString([]byte(lastEvent.CreatedAt.Format(time.RFC3339Nano)))
+		events = events[:limit]
+	}
+
+	return events, nextCursor, nil
+}
+
+func (r *sqlRepository) ClaimPaymentEvent(ctx context.Context, id uuid.UUID, lease time.Duration) (*entities.PaymentEvent, error) {
+	var events []*entities.PaymentEvent
+	err := r.gormDB.WithContext(ctx).Raw(`
+		UPDATE payment_events
+		SET status = ?, updated_at = now()
+		WHERE id = ?
+		  AND (status IN (?, ?) OR (status = ? AND updated_at < now() - make_interval(secs => ?)))
+		RETURNING *
+	`, entities.PaymentEventProcessing, id, entities.PaymentEventPending, entities.PaymentEventFailed,
+		entities.PaymentEventProcessing, lease.Seconds()).Scan(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	return events[0], nil
+}
+
+func (r *sqlRepository) MarkPaymentEventProcessed(ctx context.Context, id uuid.UUID) error {
+	return r.gormDB.WithContext(ctx).
+		Model(&entities.PaymentEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       entities.PaymentEventProcessed,
+			"processed_at": gorm.Expr("now()"),
+			"error":        nil,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"updated_at":   gorm.Expr("now()"),
+		}).Error
+}
+
+func (r *sqlRepository) MarkPaymentEventFailed(ctx context.Context, id uuid.UUID, lastError string) error {
+	return r.gormDB.WithContext(ctx).
+		Model(&entities.PaymentEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     entities.PaymentEventFailed,
+			"error":      lastError,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"updated_at": gorm.Expr("now()"),
+		}).Error
+}
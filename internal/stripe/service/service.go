@@ -4,12 +4,18 @@ import (
 	"context"
 
 	"strings"
+	"time"
 
 	"github.com/nurdsoft/nurd-commerce-core/internal/customer/customerclient"
+	ordersEntities "github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
 	"github.com/nurdsoft/nurd-commerce-core/internal/orders/ordersclient"
 	"github.com/nurdsoft/nurd-commerce-core/internal/stripe/entities"
-	moduleErrors "github.com/nurdsoft/nurd-commerce-core/shared/errors"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/internal/stripe/errors"
+	"github.com/nurdsoft/nurd-commerce-core/internal/stripe/repository"
+	appErrors "github.com/nurdsoft/nurd-commerce-core/shared/errors"
+	sharedJSON "github.com/nurdsoft/nurd-commerce-core/shared/json"
 	sharedMeta "github.com/nurdsoft/nurd-commerce-core/shared/meta"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 	stripeClient "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe/client"
 	stripeEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe/entities"
 	"go.uber.org/zap"
@@ -20,10 +26,15 @@ type Service interface {
 	GetSetupIntent(ctx context.Context) (*entities.GetSetupIntentResponse, error)
 	HandleStripeWebhook(ctx context.Context, req *entities.StripeWebhookRequest) error
 	GetPaymentMethod(ctx context.Context, req *entities.StripeGetPaymentMethodRequest) (*entities.GetPaymentMethodResponse, error)
+	DeletePaymentMethod(ctx context.Context, req *entities.StripeDeletePaymentMethodRequest) error
+	SetDefaultPaymentMethod(ctx context.Context, req *entities.StripeSetDefaultPaymentMethodRequest) (*entities.GetPaymentMethodResponse, error)
+	ListPaymentEvents(ctx context.Context, req *entities.ListPaymentEventsRequest) (*entities.ListPaymentEventsResponse, error)
+	ReplayPaymentEvent(ctx context.Context, req *entities.ReplayPaymentEventRequest) (*entities.ReplayPaymentEventResponse, error)
 }
 
 type service struct {
 	log            *zap.SugaredLogger
+	repo           repository.Repository
 	stripeClient   stripeClient.Client
 	ordersClient   ordersclient.Client
 	customerClient customerclient.Client
@@ -31,12 +42,14 @@ type service struct {
 
 func New(
 	logger *zap.SugaredLogger,
+	repo repository.Repository,
 	stripeClient stripeClient.Client,
 	ordersClient ordersclient.Client,
 	customerClient customerclient.Client,
 ) Service {
 	return &service{
 		log:            logger,
+		repo:           repo,
 		stripeClient:   stripeClient,
 		ordersClient:   ordersClient,
 		customerClient: customerClient,
@@ -81,6 +94,11 @@ func (s *service) GetPaymentMethods(ctx context.Context) (*entities.GetPaymentMe
 		return nil, err
 	}
 
+	defaultPaymentMethodID, err := s.stripeClient.GetCustomerDefaultPaymentMethod(ctx, stripeId)
+	if err != nil {
+		return nil, err
+	}
+
 	var paymentMethods []entities.PaymentMethod
 	for _, pm := range result.PaymentMethods {
 		paymentMethods = append(paymentMethods, entities.PaymentMethod{
@@ -93,6 +111,7 @@ func (s *service) GetPaymentMethods(ctx context.Context) (*entities.GetPaymentMe
 			ExpiryYear:   pm.ExpiryYear,
 			Wallet:       pm.Wallet,
 			Created:      pm.Created,
+			Default:      pm.Id == defaultPaymentMethodID,
 		})
 	}
 	resp := &entities.GetPaymentMethodsResponse{
@@ -188,6 +207,94 @@ func (s *service) GetPaymentMethod(ctx context.Context, req *entities.StripeGetP
 	return resp, nil
 }
 
+// swagger:route DELETE /stripe/payment-method/{payment_method_id} stripe DeletePaymentMethodRequest
+//
+// # Delete Customer Payment Method
+// ### Detach a saved payment method from the customer
+//
+// Produces:
+//   - application/json
+//
+// Responses:
+//
+//	200: DefaultResponse Payment method deleted successfully
+//	400: DefaultError Bad Request
+//	500: DefaultError Internal Server Error
+func (s *service) DeletePaymentMethod(ctx context.Context, req *entities.StripeDeletePaymentMethodRequest) error {
+	customerID := sharedMeta.XCustomerID(ctx)
+	if customerID == "" {
+		return moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	}
+	if req.PaymentMethodId == "" {
+		return moduleErrors.NewAPIError("PAYMENT_METHOD_ID_REQUIRED")
+	}
+	stripeId, _, err := s.getCustomerStripeID(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	// only the payment methods of the customer can be detached
+	_, err = s.stripeClient.GetCustomerPaymentMethodById(ctx, stripeId, &req.PaymentMethodId)
+	if err != nil {
+		return err
+	}
+
+	return s.stripeClient.DetachPaymentMethod(ctx, &req.PaymentMethodId)
+}
+
+// swagger:route PUT /stripe/payment-method/{payment_method_id}/default stripe SetDefaultPaymentMethodRequest
+//
+// # Set Default Payment Method
+// ### Set the payment method charged when an order doesn't name one
+//
+// Produces:
+//   - application/json
+//
+// Responses:
+//
+//	200: GetPaymentMethodResponse Default payment method set successfully
+//	400: DefaultError Bad Request
+//	500: DefaultError Internal Server Error
+func (s *service) SetDefaultPaymentMethod(ctx context.Context, req *entities.StripeSetDefaultPaymentMethodRequest) (*entities.GetPaymentMethodResponse, error) {
+	customerID := sharedMeta.XCustomerID(ctx)
+	if customerID == "" {
+		return nil, moduleErrors.NewAPIError("CUSTOMER_ID_REQUIRED")
+	}
+	if req.PaymentMethodId == "" {
+		return nil, moduleErrors.NewAPIError("PAYMENT_METHOD_ID_REQUIRED")
+	}
+	stripeId, _, err := s.getCustomerStripeID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentMethod, err := s.stripeClient.GetCustomerPaymentMethodById(ctx, stripeId, &req.PaymentMethodId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.stripeClient.SetCustomerDefaultPaymentMethod(ctx, stripeId, &req.PaymentMethodId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &entities.GetPaymentMethodResponse{
+		PaymentMethod: entities.PaymentMethod{
+			Id:           paymentMethod.PaymentMethod.Id,
+			Brand:        paymentMethod.PaymentMethod.Brand,
+			DisplayBrand: paymentMethod.PaymentMethod.DisplayBrand,
+			Country:      paymentMethod.PaymentMethod.Country,
+			Last4:        paymentMethod.PaymentMethod.Last4,
+			ExpiryMonth:  paymentMethod.PaymentMethod.ExpiryMonth,
+			ExpiryYear:   paymentMethod.PaymentMethod.ExpiryYear,
+			Wallet:       paymentMethod.PaymentMethod.Wallet,
+			Created:      paymentMethod.PaymentMethod.Created,
+			Default:      true,
+		},
+	}
+	return resp, nil
+}
+
 // Helper function to get the customer's stripe id or create it if it doesn't exist
 // Returns the stripe id, a boolean indicating if the stripe id was created and an error
 func (s *service) getCustomerStripeID(ctx context.Context, customerID string) (*string, bool, error) {
@@ -248,42 +355,244 @@ func (s *service) HandleStripeWebhook(ctx context.Context, req *entities.StripeW
 		return moduleErrors.NewAPIError("STRIPE_SIGNATURE_VERIFICATION_FAILED")
 	}
 
+	paymentEvent, err := s.repo.SavePaymentEvent(ctx, &entities.PaymentEvent{
+		Provider: providers.ProviderStripe,
+		EventID:  event.EventId,
+		Type:     event.Type,
+		ObjectID: event.ObjectId,
+		Payload:  sharedJSON.JSON(req.Payload),
+	})
+	if err != nil {
+		// without the event stored redeliveries can't be told apart, let Stripe send it again
+		s.log.Errorf("Error saving Stripe event %s: %v", event.EventId, err)
+		return moduleErrors.NewAPIError("STRIPE_ERROR_SAVING_EVENT")
+	}
+
+	// concurrent deliveries of the event race for the claim, only one of them handles it
+	claimed, err := s.repo.ClaimPaymentEvent(ctx, paymentEvent.ID, paymentEventLease)
+	if err != nil {
+		s.log.Errorf("Error claiming Stripe event %s: %v", event.EventId, err)
+		return moduleErrors.NewAPIError("STRIPE_EVENT_PROCESSING_FAILED")
+	}
+
+	if claimed == nil {
+		s.log.Infof("Skipping Stripe event %s, it is processed or being processed", event.EventId)
+		return nil
+	}
+
+	err = s.processEvent(ctx, claimed)
+	if err != nil && (appErrors.IsTransientError(err) || awaitsOrder(paymentEvent, err)) {
+		// a 5xx makes Stripe redeliver the event later
+		return moduleErrors.NewAPIError("STRIPE_EVENT_PROCESSING_FAILED")
+	}
+
+	// the other failures won't go away on a redelivery, the event is left failed to be replayed
+	return nil
+}
+
+// swagger:route GET /stripe/events stripe ListPaymentEventsRequest
+//
+// # List Stripe Events
+// ### List the webhook events received from Stripe, the failed ones by default
+//
+// Produces:
+//   - application/json
+//
+// Responses:
+//
+//	200: ListPaymentEventsResponse Events retrieved successfully
+//	400: DefaultError Bad Request
+//	500: DefaultError Internal Server Error
+func (s *service) ListPaymentEvents(ctx context.Context, req *entities.ListPaymentEventsRequest) (*entities.ListPaymentEventsResponse, error) {
+	events, nextCursor, err := s.repo.ListPaymentEvents(ctx, req.Status, req.Limit, req.Cursor)
+	if err != nil {
+		s.log.Errorf("Error listing Stripe events: %v", err)
+		return nil, moduleErrors.NewAPIError("STRIPE_ERROR_LISTING_EVENTS")
+	}
+
+	return &entities.ListPaymentEventsResponse{
+		Events:     events,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// swagger:route POST /stripe/events/{event_id}/replay stripe ReplayPaymentEventRequest
+//
+// # Replay Stripe Event
+// ### Process again a Stripe webhook event that failed
+//
+// Produces:
+//   - application/json
+//
+// Responses:
+//
+//	200: ReplayPaymentEventResponse Event processed successfully
+//	400: DefaultError Bad Request
+//	404: DefaultError Not Found
+//	409: DefaultError Conflict
+//	500: DefaultError Internal Server Error
+func (s *service) ReplayPaymentEvent(ctx context.Context, req *entities.ReplayPaymentEventRequest) (*entities.ReplayPaymentEventResponse, error) {
+	event, err := s.repo.GetPaymentEvent(ctx, req.EventID)
+	if err != nil {
+		if apiErr, ok := appErrors.IsAPIError(err); ok {
+			return nil, apiErr
+		}
+		s.log.Errorf("Error getting Stripe event %s: %v", req.EventID, err)
+		return nil, moduleErrors.NewAPIError("STRIPE_EVENT_NOT_FOUND")
+	}
+
+	if event.Status == entities.PaymentEventProcessed {
+		return nil, moduleErrors.NewAPIError("STRIPE_EVENT_ALREADY_PROCESSED")
+	}
+
+	claimed, err := s.repo.ClaimPaymentEvent(ctx, event.ID, paymentEventLease)
+	if err != nil {
+		s.log.Errorf("Error claiming Stripe event %s: %v", req.EventID, err)
+		return nil, moduleErrors.NewAPIError("STRIPE_EVENT_PROCESSING_FAILED")
+	}
+
+	if claimed == nil {
+		return nil, moduleErrors.NewAPIError("STRIPE_EVENT_IN_PROGRESS")
+	}
+
+	if err := s.processEvent(ctx, claimed); err != nil {
+		return nil, moduleErrors.NewAPIError("STRIPE_EVENT_PROCESSING_FAILED", err.Error())
+	}
+
+	event, err = s.repo.GetPaymentEvent(ctx, req.EventID)
+	if err != nil {
+		s.log.Errorf("Error getting Stripe event %s: %v", req.EventID, err)
+		return nil, moduleErrors.NewAPIError("STRIPE_EVENT_NOT_FOUND")
+	}
+
+	return &entities.ReplayPaymentEventResponse{
+		Event: event,
+	}, nil
+}
+
+// processEvent handles a stored event and records the outcome of the attempt.
+func (s *service) processEvent(ctx context.Context, event *entities.PaymentEvent) error {
+	// keep track of the event that triggered the order changes
+	ctx = sharedMeta.WithSourceEventID(ctx, event.EventID)
+
+	if err := s.dispatchEvent(ctx, event); err != nil {
+		if markErr := s.repo.MarkPaymentEventFailed(ctx, event.ID, err.Error()); markErr != nil {
+			s.log.Errorf("Error marking Stripe event %s as failed: %v", event.EventID, markErr)
+		}
+		return err
+	}
+
+	if err := s.repo.MarkPaymentEventProcessed(ctx, event.ID); err != nil {
+		// the order changes are done, a redelivery finds the order already updated
+		s.log.Errorf("Error marking Stripe event %s as processed: %v", event.EventID, err)
+	}
+
+	return nil
+}
+
+func (s *service) dispatchEvent(ctx context.Context, event *entities.PaymentEvent) error {
 	switch event.Type {
-	case "payment_intent.succeeded":
-		s.log.Info("Payment succeeded ", "payment_intent_id ", event.ObjectId)
-		err = s.ordersClient.ProcessPaymentSucceeded(ctx, event.ObjectId)
+	// payments authorized in manual capture mode only report the amount as capturable
+	case "payment_intent.succeeded", "payment_intent.amount_capturable_updated":
+		s.log.Info("Payment succeeded ", "event_type ", event.Type, " payment_intent_id ", event.ObjectID)
+		err := s.ordersClient.ProcessPaymentSucceeded(ctx, providers.ProviderStripe, event.ObjectID)
 		if err != nil {
 			s.log.Errorf("Error processing payment intent succeeded: %v", err)
-			return nil
+			return err
 		}
 	case "payment_intent.payment_failed":
-		s.log.Info("Payment failed ", "payment_intent_id", event.ObjectId)
-		err = s.ordersClient.ProcessPaymentFailed(ctx, event.ObjectId)
+		s.log.Info("Payment failed ", "payment_intent_id", event.ObjectID)
+		err := s.ordersClient.ProcessPaymentFailed(ctx, providers.ProviderStripe, event.ObjectID)
 		if err != nil {
 			s.log.Errorf("Error processing payment intent failed: %v", err)
-			return nil
+			return err
 		}
-
-	// TODO: handle refund.failed
-	case "refund.updated":
-		s.log.Info("Refund updated ", "refund_id ", event.ObjectId)
-		refund, err := s.stripeClient.GetRefund(ctx, event.ObjectId)
+	case "refund.updated", "refund.failed":
+		s.log.Info("Refund updated ", "event_type ", event.Type, " refund_id ", event.ObjectID)
+		refund, err := s.stripeClient.GetRefund(ctx, event.ObjectID)
 		if err != nil {
 			s.log.Errorf("Error getting refund: %v", err)
-			return nil
+			return err
 		}
 
-		if refund.Status == stripeEntities.StripeRefundSucceeded {
+		switch refund.Status {
+		case stripeEntities.StripeRefundSucceeded:
 			err = s.ordersClient.ProcessRefundSucceeded(ctx, refund.Id, refund.Amount)
 			if err != nil {
 				s.log.Errorf("Error processing refund succeeded: %v", err)
-				return nil
+				return err
+			}
+		case stripeEntities.StripeRefundFailed, stripeEntities.StripeRefundCanceled:
+			reason := refund.FailureReason
+			if reason == "" {
+				reason = refund.Status
+			}
+			err = s.ordersClient.ProcessRefundFailed(ctx, refund.Id, reason)
+			if err != nil {
+				s.log.Errorf("Error processing refund failed: %v", err)
+				return err
 			}
 		}
+	case "charge.dispute.created", "charge.dispute.updated", "charge.dispute.closed":
+		s.log.Info("Dispute updated ", "event_type ", event.Type, " dispute_id ", event.ObjectID)
+		dispute, err := s.stripeClient.GetDispute(ctx, event.ObjectID)
+		if err != nil {
+			s.log.Errorf("Error getting dispute: %v", err)
+			return err
+		}
+
+		if dispute.PaymentIntentId == "" {
+			s.log.Warnf("Dispute %s is not linked to a payment intent", dispute.Id)
+			return nil
+		}
+
+		err = s.ordersClient.ProcessPaymentDisputed(ctx, &ordersEntities.PaymentDispute{
+			ID:              dispute.Id,
+			PaymentProvider: providers.ProviderStripe,
+			PaymentID:       dispute.PaymentIntentId,
+			Status:          disputeStatus(dispute.Status),
+			Reason:          dispute.Reason,
+			Amount:          dispute.Amount,
+		})
+		if err != nil {
+			s.log.Errorf("Error processing dispute: %v", err)
+			return err
+		}
 	default:
 		s.log.Warnf("Unhandled event type: %s", event.Type)
-		return nil
 	}
 
 	return nil
 }
+
+// disputeStatus maps the Stripe dispute status to the order dispute status. Inquiries closed
+// without a chargeback count as won, every status before the decision as open.
+func disputeStatus(status string) ordersEntities.DisputeStatus {
+	switch status {
+	case stripeEntities.StripeDisputeWon, stripeEntities.StripeDisputeWarningClosed:
+		return ordersEntities.DisputeWon
+	case stripeEntities.StripeDisputeLost:
+		return ordersEntities.DisputeLost
+	default:
+		return ordersEntities.Disputed
+	}
+}
+
+// paymentEventLease is how long an event claimed by an attempt stays out of reach of the other ones,
+// an attempt that crashed leaves the event to be claimed again once it's over
+const paymentEventLease = 5 * time.Minute
+
+// orderGracePeriod is how long after an event is first received the order it refers to is waited for.
+// The payment is created before the order is stored, its events may come first.
+const orderGracePeriod = time.Hour
+
+// awaitsOrder reports whether the event failed because the order of the payment isn't stored yet
+// and is still recent enough for the order to show up.
+func awaitsOrder(event *entities.PaymentEvent, err error) bool {
+	apiErr, ok := appErrors.IsAPIError(err)
+	if !ok || apiErr.ErrorCode != "ORDER_NOT_FOUND_BY_PAYMENT_ID" {
+		return false
+	}
+
+	return time.Since(event.CreatedAt) < orderGracePeriod
+}
@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
@@ -11,10 +14,13 @@ import (
 
 	"github.com/nurdsoft/nurd-commerce-core/internal/customer/customerclient"
 	customerEntities "github.com/nurdsoft/nurd-commerce-core/internal/customer/entities"
+	ordersEntities "github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
 	"github.com/nurdsoft/nurd-commerce-core/internal/orders/ordersclient"
 	"github.com/nurdsoft/nurd-commerce-core/internal/stripe/entities"
+	"github.com/nurdsoft/nurd-commerce-core/internal/stripe/repository"
 	appErrors "github.com/nurdsoft/nurd-commerce-core/shared/errors"
 	"github.com/nurdsoft/nurd-commerce-core/shared/meta"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 	stripeClient "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe/client"
 	stripeEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe/entities"
 )
@@ -58,14 +64,23 @@ func Test_service_GetPaymentMethods(t *testing.T) {
 					Brand: "visa",
 					Last4: "4242",
 				},
+				{
+					Id:    "pm_456",
+					Brand: "mastercard",
+					Last4: "4444",
+				},
 			},
 		}, nil).Times(1)
+		mockStripeClient.EXPECT().
+			GetCustomerDefaultPaymentMethod(ctx, &stripeID).Return("pm_456", nil).Times(1)
 
 		resp, err := svc.GetPaymentMethods(ctx)
 
 		assert.NoError(t, err)
-		assert.Len(t, resp.PaymentMethods, 1)
+		assert.Len(t, resp.PaymentMethods, 2)
 		assert.Equal(t, "pm_123", resp.PaymentMethods[0].Id)
+		assert.False(t, resp.PaymentMethods[0].Default)
+		assert.True(t, resp.PaymentMethods[1].Default)
 	})
 
 	t.Run("no customer ID", func(t *testing.T) {
@@ -214,6 +229,122 @@ func Test_service_GetPaymentMethod(t *testing.T) {
 	})
 }
 
+func Test_service_DeletePaymentMethod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	setup := func() (
+		*service, context.Context,
+		*customerclient.MockClient,
+		*stripeClient.MockClient,
+	) {
+		mockCustomerClient := customerclient.NewMockClient(ctrl)
+		mockStripeClient := stripeClient.NewMockClient(ctrl)
+		userUUID := uuid.New()
+		ctx := meta.WithXCustomerID(context.Background(), userUUID.String())
+		svc := &service{
+			log:            zap.NewExample().Sugar(),
+			stripeClient:   mockStripeClient,
+			customerClient: mockCustomerClient,
+		}
+		return svc, ctx, mockCustomerClient, mockStripeClient
+	}
+
+	stripeID := "cust_123"
+	paymentMethodID := "pm_123"
+	customer := &customerEntities.Customer{
+		ID:       uuid.New(),
+		StripeID: &stripeID,
+	}
+
+	t.Run("Valid request", func(t *testing.T) {
+		svc, ctx, mockCustomerClient, mockStripeClient := setup()
+
+		mockCustomerClient.EXPECT().
+			GetCustomerByID(ctx, meta.XCustomerID(ctx)).Return(customer, nil).Times(1)
+		mockStripeClient.EXPECT().
+			GetCustomerPaymentMethodById(ctx, &stripeID, &paymentMethodID).Return(&stripeEntities.GetCustomerPaymentMethodResponse{
+			PaymentMethod: stripeEntities.PaymentMethod{Id: paymentMethodID},
+		}, nil).Times(1)
+		mockStripeClient.EXPECT().
+			DetachPaymentMethod(ctx, &paymentMethodID).Return(nil).Times(1)
+
+		err := svc.DeletePaymentMethod(ctx, &entities.StripeDeletePaymentMethodRequest{PaymentMethodId: paymentMethodID})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("payment method of another customer", func(t *testing.T) {
+		svc, ctx, mockCustomerClient, mockStripeClient := setup()
+
+		mockCustomerClient.EXPECT().
+			GetCustomerByID(ctx, meta.XCustomerID(ctx)).Return(customer, nil).Times(1)
+		mockStripeClient.EXPECT().
+			GetCustomerPaymentMethodById(ctx, &stripeID, &paymentMethodID).Return(nil, &appErrors.APIError{ErrorCode: "STRIPE_ERROR"}).Times(1)
+
+		err := svc.DeletePaymentMethod(ctx, &entities.StripeDeletePaymentMethodRequest{PaymentMethodId: paymentMethodID})
+
+		assert.IsType(t, &appErrors.APIError{}, err)
+	})
+
+	t.Run("no customer ID", func(t *testing.T) {
+		svc, _, _, _ := setup()
+		ctx := meta.WithXCustomerID(context.Background(), "")
+
+		err := svc.DeletePaymentMethod(ctx, &entities.StripeDeletePaymentMethodRequest{PaymentMethodId: paymentMethodID})
+
+		assert.IsType(t, &appErrors.APIError{}, err)
+	})
+}
+
+func Test_service_SetDefaultPaymentMethod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCustomerClient := customerclient.NewMockClient(ctrl)
+	mockStripeClient := stripeClient.NewMockClient(ctrl)
+	ctx := meta.WithXCustomerID(context.Background(), uuid.New().String())
+	svc := &service{
+		log:            zap.NewExample().Sugar(),
+		stripeClient:   mockStripeClient,
+		customerClient: mockCustomerClient,
+	}
+
+	stripeID := "cust_123"
+	paymentMethodID := "pm_123"
+	customer := &customerEntities.Customer{
+		ID:       uuid.New(),
+		StripeID: &stripeID,
+	}
+
+	t.Run("Valid request", func(t *testing.T) {
+		mockCustomerClient.EXPECT().
+			GetCustomerByID(ctx, meta.XCustomerID(ctx)).Return(customer, nil).Times(1)
+		mockStripeClient.EXPECT().
+			GetCustomerPaymentMethodById(ctx, &stripeID, &paymentMethodID).Return(&stripeEntities.GetCustomerPaymentMethodResponse{
+			PaymentMethod: stripeEntities.PaymentMethod{
+				Id:    paymentMethodID,
+				Brand: "visa",
+				Last4: "4242",
+			},
+		}, nil).Times(1)
+		mockStripeClient.EXPECT().
+			SetCustomerDefaultPaymentMethod(ctx, &stripeID, &paymentMethodID).Return(nil).Times(1)
+
+		resp, err := svc.SetDefaultPaymentMethod(ctx, &entities.StripeSetDefaultPaymentMethodRequest{PaymentMethodId: paymentMethodID})
+
+		assert.NoError(t, err)
+		assert.Equal(t, paymentMethodID, resp.PaymentMethod.Id)
+		assert.True(t, resp.PaymentMethod.Default)
+	})
+
+	t.Run("missing payment method ID", func(t *testing.T) {
+		_, err := svc.SetDefaultPaymentMethod(ctx, &entities.StripeSetDefaultPaymentMethodRequest{})
+
+		assert.IsType(t, &appErrors.APIError{}, err)
+	})
+}
+
 func Test_service_HandleStripeWebhook(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -222,32 +353,68 @@ func Test_service_HandleStripeWebhook(t *testing.T) {
 		*service, context.Context,
 		*stripeClient.MockClient,
 		*ordersclient.MockClient,
+		*repository.MockRepository,
 	) {
 		mockStripeClient := stripeClient.NewMockClient(ctrl)
 		mockOrdersClient := ordersclient.NewMockClient(ctrl)
+		mockRepo := repository.NewMockRepository(ctrl)
 		ctx := context.Background()
 		svc := &service{
 			log:          zap.NewExample().Sugar(),
+			repo:         mockRepo,
 			stripeClient: mockStripeClient,
 			ordersClient: mockOrdersClient,
 		}
-		return svc, ctx, mockStripeClient, mockOrdersClient
+		return svc, ctx, mockStripeClient, mockOrdersClient, mockRepo
+	}
+
+	// claimEvent lets the attempt claim the stored event
+	claimEvent := func(mockRepo *repository.MockRepository, stored *entities.PaymentEvent) {
+		mockRepo.EXPECT().
+			ClaimPaymentEvent(gomock.Any(), stored.ID, paymentEventLease).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, _ time.Duration) (*entities.PaymentEvent, error) {
+				claimed := *stored
+				claimed.Status = entities.PaymentEventProcessing
+				return &claimed, nil
+			}).Times(1)
+	}
+
+	// saveNewEvent stores the event the way it is received for the first time
+	saveNewEvent := func(mockRepo *repository.MockRepository, eventID uuid.UUID) {
+		mockRepo.EXPECT().
+			SavePaymentEvent(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, event *entities.PaymentEvent) (*entities.PaymentEvent, error) {
+				assert.Equal(t, providers.ProviderStripe, event.Provider)
+				stored := *event
+				stored.ID = eventID
+				stored.Status = entities.PaymentEventPending
+				stored.CreatedAt = time.Now()
+				claimEvent(mockRepo, &stored)
+				return &stored, nil
+			}).Times(1)
 	}
 
 	t.Run("payment intent succeeded", func(t *testing.T) {
-		svc, ctx, mockStripeClient, mockOrdersClient := setup()
+		svc, ctx, mockStripeClient, mockOrdersClient, mockRepo := setup()
 		req := &entities.StripeWebhookRequest{
 			Payload:   []byte("test_payload"),
 			Signature: "test_signature",
 		}
+		eventID := uuid.New()
 
 		mockStripeClient.EXPECT().
 			GetWebhookEvent(ctx, gomock.Any()).Return(&stripeEntities.HandleWebhookEventResponse{
+			EventId:  "evt_123",
 			Type:     "payment_intent.succeeded",
 			ObjectId: "pi_123",
 		}, nil).Times(1)
+		saveNewEvent(mockRepo, eventID)
 		mockOrdersClient.EXPECT().
-			ProcessPaymentSucceeded(ctx, "pi_123").Return(nil).Times(1)
+			ProcessPaymentSucceeded(gomock.Any(), providers.ProviderStripe, "pi_123").
+			Do(func(ctx context.Context, _ providers.ProviderType, _ string) {
+				assert.Equal(t, "evt_123", meta.SourceEventID(ctx))
+			}).Return(nil).Times(1)
+		mockRepo.EXPECT().MarkPaymentEventProcessed(gomock.Any(), eventID).Return(nil).Times(1)
 
 		err := svc.HandleStripeWebhook(ctx, req)
 
@@ -255,19 +422,125 @@ func Test_service_HandleStripeWebhook(t *testing.T) {
 	})
 
 	t.Run("payment intent failed", func(t *testing.T) {
-		svc, ctx, mockStripeClient, mockOrdersClient := setup()
+		svc, ctx, mockStripeClient, mockOrdersClient, mockRepo := setup()
 		req := &entities.StripeWebhookRequest{
 			Payload:   []byte("test_payload"),
 			Signature: "test_signature",
 		}
+		eventID := uuid.New()
 
 		mockStripeClient.EXPECT().
 			GetWebhookEvent(ctx, gomock.Any()).Return(&stripeEntities.HandleWebhookEventResponse{
+			EventId:  "evt_123",
 			Type:     "payment_intent.payment_failed",
 			ObjectId: "pi_123",
 		}, nil).Times(1)
+		saveNewEvent(mockRepo, eventID)
 		mockOrdersClient.EXPECT().
-			ProcessPaymentFailed(ctx, "pi_123").Return(nil).Times(1)
+			ProcessPaymentFailed(gomock.Any(), providers.ProviderStripe, "pi_123").
+			Do(func(ctx context.Context, _ providers.ProviderType, _ string) {
+				assert.Equal(t, "evt_123", meta.SourceEventID(ctx))
+			}).Return(nil).Times(1)
+		mockRepo.EXPECT().MarkPaymentEventProcessed(gomock.Any(), eventID).Return(nil).Times(1)
+
+		err := svc.HandleStripeWebhook(ctx, req)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("refund failed", func(t *testing.T) {
+		svc, ctx, mockStripeClient, mockOrdersClient, mockRepo := setup()
+		req := &entities.StripeWebhookRequest{
+			Payload:   []byte("test_payload"),
+			Signature: "test_signature",
+		}
+		eventID := uuid.New()
+
+		mockStripeClient.EXPECT().
+			GetWebhookEvent(ctx, gomock.Any()).Return(&stripeEntities.HandleWebhookEventResponse{
+			EventId:  "evt_123",
+			Type:     "refund.failed",
+			ObjectId: "re_123",
+		}, nil).Times(1)
+		saveNewEvent(mockRepo, eventID)
+		mockStripeClient.EXPECT().
+			GetRefund(gomock.Any(), "re_123").Return(&stripeEntities.RefundResponse{
+			Id:            "re_123",
+			Status:        stripeEntities.StripeRefundFailed,
+			FailureReason: "lost_or_stolen_card",
+		}, nil).Times(1)
+		mockOrdersClient.EXPECT().
+			ProcessRefundFailed(gomock.Any(), "re_123", "lost_or_stolen_card").Return(nil).Times(1)
+		mockRepo.EXPECT().MarkPaymentEventProcessed(gomock.Any(), eventID).Return(nil).Times(1)
+
+		err := svc.HandleStripeWebhook(ctx, req)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("dispute created", func(t *testing.T) {
+		svc, ctx, mockStripeClient, mockOrdersClient, mockRepo := setup()
+		req := &entities.StripeWebhookRequest{
+			Payload:   []byte("test_payload"),
+			Signature: "test_signature",
+		}
+		eventID := uuid.New()
+
+		mockStripeClient.EXPECT().
+			GetWebhookEvent(ctx, gomock.Any()).Return(&stripeEntities.HandleWebhookEventResponse{
+			EventId:  "evt_123",
+			Type:     "charge.dispute.created",
+			ObjectId: "dp_123",
+		}, nil).Times(1)
+		saveNewEvent(mockRepo, eventID)
+		mockStripeClient.EXPECT().
+			GetDispute(gomock.Any(), "dp_123").Return(&stripeEntities.GetDisputeResponse{
+			Id:              "dp_123",
+			PaymentIntentId: "pi_123",
+			Reason:          "fraudulent",
+			Status:          "needs_response",
+		}, nil).Times(1)
+		mockOrdersClient.EXPECT().
+			ProcessPaymentDisputed(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, dispute *ordersEntities.PaymentDispute) {
+				assert.Equal(t, "dp_123", dispute.ID)
+				assert.Equal(t, "pi_123", dispute.PaymentID)
+				assert.Equal(t, ordersEntities.Disputed, dispute.Status)
+			}).Return(nil).Times(1)
+		mockRepo.EXPECT().MarkPaymentEventProcessed(gomock.Any(), eventID).Return(nil).Times(1)
+
+		err := svc.HandleStripeWebhook(ctx, req)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("dispute lost", func(t *testing.T) {
+		svc, ctx, mockStripeClient, mockOrdersClient, mockRepo := setup()
+		req := &entities.StripeWebhookRequest{
+			Payload:   []byte("test_payload"),
+			Signature: "test_signature",
+		}
+		eventID := uuid.New()
+
+		mockStripeClient.EXPECT().
+			GetWebhookEvent(ctx, gomock.Any()).Return(&stripeEntities.HandleWebhookEventResponse{
+			EventId:  "evt_123",
+			Type:     "charge.dispute.closed",
+			ObjectId: "dp_123",
+		}, nil).Times(1)
+		saveNewEvent(mockRepo, eventID)
+		mockStripeClient.EXPECT().
+			GetDispute(gomock.Any(), "dp_123").Return(&stripeEntities.GetDisputeResponse{
+			Id:              "dp_123",
+			PaymentIntentId: "pi_123",
+			Status:          stripeEntities.StripeDisputeLost,
+		}, nil).Times(1)
+		mockOrdersClient.EXPECT().
+			ProcessPaymentDisputed(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, dispute *ordersEntities.PaymentDispute) {
+				assert.Equal(t, ordersEntities.DisputeLost, dispute.Status)
+			}).Return(nil).Times(1)
+		mockRepo.EXPECT().MarkPaymentEventProcessed(gomock.Any(), eventID).Return(nil).Times(1)
 
 		err := svc.HandleStripeWebhook(ctx, req)
 
@@ -275,17 +548,20 @@ func Test_service_HandleStripeWebhook(t *testing.T) {
 	})
 
 	t.Run("unhandled event type", func(t *testing.T) {
-		svc, ctx, mockStripeClient, _ := setup()
+		svc, ctx, mockStripeClient, _, mockRepo := setup()
 		req := &entities.StripeWebhookRequest{
 			Payload:   []byte("test_payload"),
 			Signature: "test_signature",
 		}
+		eventID := uuid.New()
 
 		mockStripeClient.EXPECT().
 			GetWebhookEvent(ctx, gomock.Any()).Return(&stripeEntities.HandleWebhookEventResponse{
 			Type:     "customer.created",
 			ObjectId: "cust_123",
 		}, nil).Times(1)
+		saveNewEvent(mockRepo, eventID)
+		mockRepo.EXPECT().MarkPaymentEventProcessed(gomock.Any(), eventID).Return(nil).Times(1)
 
 		err := svc.HandleStripeWebhook(ctx, req)
 
@@ -293,7 +569,7 @@ func Test_service_HandleStripeWebhook(t *testing.T) {
 	})
 
 	t.Run("invalid signature", func(t *testing.T) {
-		svc, ctx, mockStripeClient, _ := setup()
+		svc, ctx, mockStripeClient, _, _ := setup()
 		req := &entities.StripeWebhookRequest{
 			Payload:   []byte("test_payload"),
 			Signature: "invalid_signature",
@@ -305,6 +581,318 @@ func Test_service_HandleStripeWebhook(t *testing.T) {
 		err := svc.HandleStripeWebhook(ctx, req)
 
 		assert.IsType(t, &appErrors.APIError{}, err)
+		assert.Equal(t, http.StatusBadRequest, err.(*appErrors.APIError).StatusCode)
+	})
+
+	t.Run("already processed event is skipped", func(t *testing.T) {
+		svc, ctx, mockStripeClient, _, mockRepo := setup()
+		req := &entities.StripeWebhookRequest{
+			Payload:   []byte(`{"id":"evt_123"}`),
+			Signature: "test_signature",
+		}
+		processedAt := time.Now()
+
+		mockStripeClient.EXPECT().
+			GetWebhookEvent(ctx, gomock.Any()).Return(&stripeEntities.HandleWebhookEventResponse{
+			EventId:  "evt_123",
+			Type:     "payment_intent.succeeded",
+			ObjectId: "pi_123",
+		}, nil).Times(1)
+		mockRepo.EXPECT().
+			SavePaymentEvent(gomock.Any(), gomock.Any()).
+			Return(&entities.PaymentEvent{
+				ID:          uuid.New(),
+				EventID:     "evt_123",
+				Type:        "payment_intent.succeeded",
+				ObjectID:    "pi_123",
+				Status:      entities.PaymentEventProcessed,
+				ProcessedAt: &processedAt,
+			}, nil).Times(1)
+		mockRepo.EXPECT().ClaimPaymentEvent(gomock.Any(), gomock.Any(), paymentEventLease).Return(nil, nil).Times(1)
+
+		err := svc.HandleStripeWebhook(ctx, req)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("event being processed by a concurrent delivery is skipped", func(t *testing.T) {
+		svc, ctx, mockStripeClient, _, mockRepo := setup()
+		req := &entities.StripeWebhookRequest{
+			Payload:   []byte(`{"id":"evt_123"}`),
+			Signature: "test_signature",
+		}
+		eventID := uuid.New()
+
+		mockStripeClient.EXPECT().
+			GetWebhookEvent(ctx, gomock.Any()).Return(&stripeEntities.HandleWebhookEventResponse{
+			EventId:  "evt_123",
+			Type:     "payment_intent.succeeded",
+			ObjectId: "pi_123",
+		}, nil).Times(1)
+		mockRepo.EXPECT().
+			SavePaymentEvent(gomock.Any(), gomock.Any()).
+			Return(&entities.PaymentEvent{
+				ID:       eventID,
+				EventID:  "evt_123",
+				Type:     "payment_intent.succeeded",
+				ObjectID: "pi_123",
+				Status:   entities.PaymentEventPending,
+			}, nil).Times(1)
+		// the other delivery claimed it first, the order isn't touched
+		mockRepo.EXPECT().ClaimPaymentEvent(gomock.Any(), eventID, paymentEventLease).Return(nil, nil).Times(1)
+
+		err := svc.HandleStripeWebhook(ctx, req)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("transient failure is redelivered", func(t *testing.T) {
+		svc, ctx, mockStripeClient, mockOrdersClient, mockRepo := setup()
+		req := &entities.StripeWebhookRequest{
+			Payload:   []byte(`{"id":"evt_123"}`),
+			Signature: "test_signature",
+		}
+		eventID := uuid.New()
+
+		mockStripeClient.EXPECT().
+			GetWebhookEvent(ctx, gomock.Any()).Return(&stripeEntities.HandleWebhookEventResponse{
+			EventId:  "evt_123",
+			Type:     "payment_intent.succeeded",
+			ObjectId: "pi_123",
+		}, nil).Times(1)
+		saveNewEvent(mockRepo, eventID)
+		mockOrdersClient.EXPECT().
+			ProcessPaymentSucceeded(gomock.Any(), providers.ProviderStripe, "pi_123").
+			Return(errors.New("connection refused")).Times(1)
+		mockRepo.EXPECT().MarkPaymentEventFailed(gomock.Any(), eventID, "connection refused").Return(nil).Times(1)
+
+		err := svc.HandleStripeWebhook(ctx, req)
+
+		assert.IsType(t, &appErrors.APIError{}, err)
+		assert.Equal(t, http.StatusInternalServerError, err.(*appErrors.APIError).StatusCode)
+	})
+
+	t.Run("permanent failure is left for replay", func(t *testing.T) {
+		svc, ctx, mockStripeClient, mockOrdersClient, mockRepo := setup()
+		req := &entities.StripeWebhookRequest{
+			Payload:   []byte(`{"id":"evt_123"}`),
+			Signature: "test_signature",
+		}
+		eventID := uuid.New()
+
+		mockStripeClient.EXPECT().
+			GetWebhookEvent(ctx, gomock.Any()).Return(&stripeEntities.HandleWebhookEventResponse{
+			EventId:  "evt_123",
+			Type:     "payment_intent.succeeded",
+			ObjectId: "pi_123",
+		}, nil).Times(1)
+		saveNewEvent(mockRepo, eventID)
+		mockOrdersClient.EXPECT().
+			ProcessPaymentSucceeded(gomock.Any(), providers.ProviderStripe, "pi_123").
+			Return(&appErrors.APIError{StatusCode: http.StatusNotFound, Message: "order not found"}).Times(1)
+		mockRepo.EXPECT().MarkPaymentEventFailed(gomock.Any(), eventID, "order not found").Return(nil).Times(1)
+
+		err := svc.HandleStripeWebhook(ctx, req)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("payment of an order not stored yet is redelivered", func(t *testing.T) {
+		svc, ctx, mockStripeClient, mockOrdersClient, mockRepo := setup()
+		req := &entities.StripeWebhookRequest{
+			Payload:   []byte(`{"id":"evt_123"}`),
+			Signature: "test_signature",
+		}
+		eventID := uuid.New()
+		orderNotFound := &appErrors.APIError{ErrorCode: "ORDER_NOT_FOUND_BY_PAYMENT_ID", StatusCode: http.StatusNotFound, Message: "Order not found by payment ID."}
+
+		mockStripeClient.EXPECT().
+			GetWebhookEvent(ctx, gomock.Any()).Return(&stripeEntities.HandleWebhookEventResponse{
+			EventId:  "evt_123",
+			Type:     "payment_intent.succeeded",
+			ObjectId: "pi_123",
+		}, nil).Times(1)
+		saveNewEvent(mockRepo, eventID)
+		mockOrdersClient.EXPECT().
+			ProcessPaymentSucceeded(gomock.Any(), providers.ProviderStripe, "pi_123").
+			Return(orderNotFound).Times(1)
+		mockRepo.EXPECT().MarkPaymentEventFailed(gomock.Any(), eventID, orderNotFound.Error()).Return(nil).Times(1)
+
+		err := svc.HandleStripeWebhook(ctx, req)
+
+		assert.IsType(t, &appErrors.APIError{}, err)
+		assert.Equal(t, http.StatusInternalServerError, err.(*appErrors.APIError).StatusCode)
+	})
+
+	t.Run("order still not found after the grace period", func(t *testing.T) {
+		svc, ctx, mockStripeClient, mockOrdersClient, mockRepo := setup()
+		req := &entities.StripeWebhookRequest{
+			Payload:   []byte(`{"id":"evt_123"}`),
+			Signature: "test_signature",
+		}
+		eventID := uuid.New()
+		orderNotFound := &appErrors.APIError{ErrorCode: "ORDER_NOT_FOUND_BY_PAYMENT_ID", StatusCode: http.StatusNotFound, Message: "Order not found by payment ID."}
+
+		mockStripeClient.EXPECT().
+			GetWebhookEvent(ctx, gomock.Any()).Return(&stripeEntities.HandleWebhookEventResponse{
+			EventId:  "evt_123",
+			Type:     "payment_intent.succeeded",
+			ObjectId: "pi_123",
+		}, nil).Times(1)
+		mockRepo.EXPECT().
+			SavePaymentEvent(gomock.Any(), gomock.Any()).
+			Return(&entities.PaymentEvent{
+				ID:        eventID,
+				EventID:   "evt_123",
+				Type:      "payment_intent.succeeded",
+				ObjectID:  "pi_123",
+				Status:    entities.PaymentEventFailed,
+				CreatedAt: time.Now().Add(-2 * orderGracePeriod),
+			}, nil).Times(1)
+		mockRepo.EXPECT().
+			ClaimPaymentEvent(gomock.Any(), eventID, paymentEventLease).
+			Return(&entities.PaymentEvent{
+				ID:        eventID,
+				EventID:   "evt_123",
+				Type:      "payment_intent.succeeded",
+				ObjectID:  "pi_123",
+				Status:    entities.PaymentEventProcessing,
+				CreatedAt: time.Now().Add(-2 * orderGracePeriod),
+			}, nil).Times(1)
+		mockOrdersClient.EXPECT().
+			ProcessPaymentSucceeded(gomock.Any(), providers.ProviderStripe, "pi_123").
+			Return(orderNotFound).Times(1)
+		mockRepo.EXPECT().MarkPaymentEventFailed(gomock.Any(), eventID, orderNotFound.Error()).Return(nil).Times(1)
+
+		err := svc.HandleStripeWebhook(ctx, req)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("error saving event", func(t *testing.T) {
+		svc, ctx, mockStripeClient, _, mockRepo := setup()
+		req := &entities.StripeWebhookRequest{
+			Payload:   []byte(`{"id":"evt_123"}`),
+			Signature: "test_signature",
+		}
+
+		mockStripeClient.EXPECT().
+			GetWebhookEvent(ctx, gomock.Any()).Return(&stripeEntities.HandleWebhookEventResponse{
+			EventId:  "evt_123",
+			Type:     "payment_intent.succeeded",
+			ObjectId: "pi_123",
+		}, nil).Times(1)
+		mockRepo.EXPECT().SavePaymentEvent(gomock.Any(), gomock.Any()).Return(nil, errors.New("db error")).Times(1)
+
+		err := svc.HandleStripeWebhook(ctx, req)
+
+		assert.IsType(t, &appErrors.APIError{}, err)
+		assert.Equal(t, http.StatusInternalServerError, err.(*appErrors.APIError).StatusCode)
+	})
+}
+
+func Test_service_ReplayPaymentEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	setup := func() (
+		*service, context.Context,
+		*ordersclient.MockClient,
+		*repository.MockRepository,
+	) {
+		mockOrdersClient := ordersclient.NewMockClient(ctrl)
+		mockRepo := repository.NewMockRepository(ctrl)
+		svc := &service{
+			log:          zap.NewExample().Sugar(),
+			repo:         mockRepo,
+			ordersClient: mockOrdersClient,
+		}
+		return svc, context.Background(), mockOrdersClient, mockRepo
+	}
+
+	failedEvent := func() *entities.PaymentEvent {
+		lastError := "order not found"
+		return &entities.PaymentEvent{
+			ID:       uuid.New(),
+			EventID:  "evt_123",
+			Type:     "payment_intent.succeeded",
+			ObjectID: "pi_123",
+			Status:   entities.PaymentEventFailed,
+			Error:    &lastError,
+			Attempts: 1,
+		}
+	}
+
+	t.Run("failed event is processed", func(t *testing.T) {
+		svc, ctx, mockOrdersClient, mockRepo := setup()
+		event := failedEvent()
+		processedAt := time.Now()
+
+		gomock.InOrder(
+			mockRepo.EXPECT().GetPaymentEvent(ctx, "evt_123").Return(event, nil),
+			mockRepo.EXPECT().ClaimPaymentEvent(ctx, event.ID, paymentEventLease).Return(event, nil),
+			mockOrdersClient.EXPECT().
+				ProcessPaymentSucceeded(gomock.Any(), providers.ProviderStripe, "pi_123").
+				Do(func(ctx context.Context, _ providers.ProviderType, _ string) {
+					assert.Equal(t, "evt_123", meta.SourceEventID(ctx))
+				}).Return(nil),
+			mockRepo.EXPECT().MarkPaymentEventProcessed(gomock.Any(), event.ID).Return(nil),
+			mockRepo.EXPECT().GetPaymentEvent(ctx, "evt_123").Return(&entities.PaymentEvent{
+				ID:          event.ID,
+				EventID:     "evt_123",
+				Status:      entities.PaymentEventProcessed,
+				ProcessedAt: &processedAt,
+				Attempts:    2,
+			}, nil),
+		)
+
+		resp, err := svc.ReplayPaymentEvent(ctx, &entities.ReplayPaymentEventRequest{EventID: "evt_123"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.PaymentEventProcessed, resp.Event.Status)
+	})
+
+	t.Run("event already processed", func(t *testing.T) {
+		svc, ctx, _, mockRepo := setup()
+		event := failedEvent()
+		event.Status = entities.PaymentEventProcessed
+
+		mockRepo.EXPECT().GetPaymentEvent(ctx, "evt_123").Return(event, nil).Times(1)
+
+		_, err := svc.ReplayPaymentEvent(ctx, &entities.ReplayPaymentEventRequest{EventID: "evt_123"})
+
+		assert.IsType(t, &appErrors.APIError{}, err)
+		assert.Equal(t, "STRIPE_EVENT_ALREADY_PROCESSED", err.(*appErrors.APIError).ErrorCode)
+	})
+
+	t.Run("event being processed", func(t *testing.T) {
+		svc, ctx, _, mockRepo := setup()
+		event := failedEvent()
+
+		mockRepo.EXPECT().GetPaymentEvent(ctx, "evt_123").Return(event, nil).Times(1)
+		mockRepo.EXPECT().ClaimPaymentEvent(ctx, event.ID, paymentEventLease).Return(nil, nil).Times(1)
+
+		_, err := svc.ReplayPaymentEvent(ctx, &entities.ReplayPaymentEventRequest{EventID: "evt_123"})
+
+		assert.IsType(t, &appErrors.APIError{}, err)
+		assert.Equal(t, "STRIPE_EVENT_IN_PROGRESS", err.(*appErrors.APIError).ErrorCode)
+	})
+
+	t.Run("event fails again", func(t *testing.T) {
+		svc, ctx, mockOrdersClient, mockRepo := setup()
+		event := failedEvent()
+
+		mockRepo.EXPECT().GetPaymentEvent(ctx, "evt_123").Return(event, nil).Times(1)
+		mockRepo.EXPECT().ClaimPaymentEvent(ctx, event.ID, paymentEventLease).Return(event, nil).Times(1)
+		mockOrdersClient.EXPECT().
+			ProcessPaymentSucceeded(gomock.Any(), providers.ProviderStripe, "pi_123").
+			Return(&appErrors.APIError{StatusCode: http.StatusNotFound, Message: "order not found"}).Times(1)
+		mockRepo.EXPECT().MarkPaymentEventFailed(gomock.Any(), event.ID, "order not found").Return(nil).Times(1)
+
+		_, err := svc.ReplayPaymentEvent(ctx, &entities.ReplayPaymentEventRequest{EventID: "evt_123"})
+
+		assert.IsType(t, &appErrors.APIError{}, err)
+		assert.Equal(t, "STRIPE_EVENT_PROCESSING_FAILED", err.(*appErrors.APIError).ErrorCode)
 	})
 }
 
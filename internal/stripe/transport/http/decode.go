@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -67,6 +68,52 @@ func decodeStripeGetPaymentMethod(c context.Context, r *http.Request) (interface
 	}, nil
 }
 
+func decodeStripeDeletePaymentMethodRequest(c context.Context, r *http.Request) (interface{}, error) {
+	customerIDStr := sharedMeta.XCustomerID(c)
+
+	customerID, err := uuid.Parse(customerIDStr)
+	if err != nil {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "Customer ID is not valid")
+	}
+
+	if customerID == uuid.Nil {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "Customer ID not found in context")
+	}
+
+	params := mux.Vars(r)
+	paymentMethodId := params["payment_method_id"]
+	if paymentMethodId == "" {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "payment_method_id is not valid")
+	}
+
+	return &entities.StripeDeletePaymentMethodRequest{
+		PaymentMethodId: paymentMethodId,
+	}, nil
+}
+
+func decodeStripeSetDefaultPaymentMethodRequest(c context.Context, r *http.Request) (interface{}, error) {
+	customerIDStr := sharedMeta.XCustomerID(c)
+
+	customerID, err := uuid.Parse(customerIDStr)
+	if err != nil {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "Customer ID is not valid")
+	}
+
+	if customerID == uuid.Nil {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "Customer ID not found in context")
+	}
+
+	params := mux.Vars(r)
+	paymentMethodId := params["payment_method_id"]
+	if paymentMethodId == "" {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "payment_method_id is not valid")
+	}
+
+	return &entities.StripeSetDefaultPaymentMethodRequest{
+		PaymentMethodId: paymentMethodId,
+	}, nil
+}
+
 func decodeStripeWebhookRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	signature := r.Header.Get("Stripe-Signature")
 	if signature == "" {
@@ -112,3 +159,40 @@ func decodeStripeRefundRequest(c context.Context, r *http.Request) (interface{},
 		Body:            req,
 	}, nil
 }
+
+func decodeStripeListEventsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	limitStr := r.URL.Query().Get("limit")
+	if limitStr == "" {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "limit is required")
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "invalid limit")
+	}
+
+	status := entities.PaymentEventFailed
+	if statusStr := r.URL.Query().Get("status"); statusStr != "" {
+		status = entities.PaymentEventStatus(statusStr)
+		if !status.IsValid() {
+			return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "invalid status")
+		}
+	}
+
+	return &entities.ListPaymentEventsRequest{
+		Status: status,
+		Limit:  limit,
+		Cursor: r.URL.Query().Get("cursor"),
+	}, nil
+}
+
+func decodeStripeReplayEventRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	params := mux.Vars(r)
+	eventID := params["event_id"]
+	if eventID == "" {
+		return nil, moduleErrors.NewAPIError("VALIDATION_ERROR", "event_id is not valid")
+	}
+
+	return &entities.ReplayPaymentEventRequest{
+		EventID: eventID,
+	}, nil
+}
@@ -17,9 +17,13 @@ func RegisterTransport(
 ) {
 	registerStripeGetPaymentMethods(server, ep.StripeGetPaymentMethodsEndpoint, svcTransportClient)
 	registerStripeGetPaymentMethod(server, ep.StripeGetPaymentMethodEndpoint, svcTransportClient)
+	registerStripeDeletePaymentMethod(server, ep.StripeDeletePaymentMethodEndpoint, svcTransportClient)
+	registerStripeSetDefaultPaymentMethod(server, ep.StripeSetDefaultPaymentMethodEndpoint, svcTransportClient)
 	registerStripeGetSetupIntent(server, ep.StripeGetSetupIntentEndpoint, svcTransportClient)
 	registerStripeWebhook(server, ep.StripeWebhookEndpoint, svcTransportClient)
 	registerStripeRefund(server, ep.StripeRefundEndpoint, svcTransportClient)
+	registerStripeListEvents(server, ep.StripeListEventsEndpoint, svcTransportClient)
+	registerStripeReplayEvent(server, ep.StripeReplayEventEndpoint, svcTransportClient)
 }
 
 func registerStripeGetPaymentMethods(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
@@ -54,6 +58,38 @@ func registerStripeGetPaymentMethod(server *httpTransport.Server, ep goKitEndpoi
 	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
 }
 
+func registerStripeDeletePaymentMethod(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "DELETE"
+	path := "/stripe/payment-method/{payment_method_id}"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeStripeDeletePaymentMethodRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
+
+func registerStripeSetDefaultPaymentMethod(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "PUT"
+	path := "/stripe/payment-method/{payment_method_id}/default"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeStripeSetDefaultPaymentMethodRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
+
 func registerStripeGetSetupIntent(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
 	method := "GET"
 	path := "/stripe/setup-intent"
@@ -101,3 +137,35 @@ func registerStripeRefund(server *httpTransport.Server, ep goKitEndpoint.Endpoin
 	server.Handle(method, path, handler)
 	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
 }
+
+func registerStripeListEvents(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "GET"
+	path := "/stripe/events"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeStripeListEventsRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
+
+func registerStripeReplayEvent(server *httpTransport.Server, ep goKitEndpoint.Endpoint, atc svcTransport.Client) {
+	method := "POST"
+	path := "/stripe/events/{event_id}/replay"
+
+	handler := goKitHTTPTransport.NewServer(
+		ep,
+		decodeStripeReplayEventRequest,
+		atc.EncodeAccessControlHeadersWrapper(encode.Response, []string{method}),
+		goKitHTTPTransport.ServerErrorEncoder(atc.EncodeErrorControlHeadersWrapper(encode.Error, []string{method})),
+		goKitHTTPTransport.ServerErrorHandler(atc.LogErrorHandler()),
+	)
+
+	server.Handle(method, path, handler)
+	atc.RegisterAccessControlOptionsHandler(server, path, []string{method})
+}
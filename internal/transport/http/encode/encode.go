@@ -186,7 +186,7 @@ func Error(_ context.Context, err error, w http.ResponseWriter) {
 		errCode = apiErr.StatusCode
 		resp = httpInternal.Response{
 			Data:  &struct{}{},
-			Error: &httpInternal.Error{StatusCode: apiErr.StatusCode, ErrorCode: "BAPI_" + apiErr.ErrorCode, Message: apiErr.Message},
+			Error: &httpInternal.Error{StatusCode: apiErr.StatusCode, ErrorCode: "BAPI_" + apiErr.ErrorCode, Message: apiErr.Message, Details: apiErr.Details},
 		}
 	} else {
 		// Log full details
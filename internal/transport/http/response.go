@@ -19,6 +19,8 @@ type Error struct {
 	ErrorCode  string `json:"error_code"`
 	StatusCode int    `json:"status_code"`
 	Message    string `json:"message"`
+	// Details lets the client act on the error
+	Details interface{} `json:"details,omitempty"`
 }
 
 type ResponseWithFilename struct {
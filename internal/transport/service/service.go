@@ -82,6 +82,7 @@ func (s *service) addAccessControlHeaders(w http.ResponseWriter, allowMethods []
 		string(auth.AuthorizationKey),
 		string(auth.Access),
 		string(auth.CustomerIDKey),
+		string(auth.CartTokenKey),
 		"Host",
 		"Origin",
 	}, ","))
@@ -9,6 +9,8 @@ import (
 
 type Client interface {
 	NotifyOrderStatusChange(ctx context.Context, req *entities.NotifyOrderStatusChangeRequest) error
+	NotifyReturnStatusChange(ctx context.Context, req *entities.NotifyReturnStatusChangeRequest) error
+	NotifyOrderDisputeChange(ctx context.Context, req *entities.NotifyOrderDisputeChangeRequest) error
 }
 
 func NewClient(svc service.Service) Client {
@@ -22,3 +24,11 @@ type localClient struct {
 func (c *localClient) NotifyOrderStatusChange(ctx context.Context, req *entities.NotifyOrderStatusChangeRequest) error {
 	return c.svc.NotifyOrderStatusChange(ctx, req)
 }
+
+func (c *localClient) NotifyReturnStatusChange(ctx context.Context, req *entities.NotifyReturnStatusChangeRequest) error {
+	return c.svc.NotifyReturnStatusChange(ctx, req)
+}
+
+func (c *localClient) NotifyOrderDisputeChange(ctx context.Context, req *entities.NotifyOrderDisputeChangeRequest) error {
+	return c.svc.NotifyOrderDisputeChange(ctx, req)
+}
@@ -35,6 +35,20 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// NotifyOrderDisputeChange mocks base method.
+func (m *MockClient) NotifyOrderDisputeChange(ctx context.Context, req *entities.NotifyOrderDisputeChangeRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyOrderDisputeChange", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyOrderDisputeChange indicates an expected call of NotifyOrderDisputeChange.
+func (mr *MockClientMockRecorder) NotifyOrderDisputeChange(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyOrderDisputeChange", reflect.TypeOf((*MockClient)(nil).NotifyOrderDisputeChange), ctx, req)
+}
+
 // NotifyOrderStatusChange mocks base method.
 func (m *MockClient) NotifyOrderStatusChange(ctx context.Context, req *entities.NotifyOrderStatusChangeRequest) error {
 	m.ctrl.T.Helper()
@@ -48,3 +62,17 @@ func (mr *MockClientMockRecorder) NotifyOrderStatusChange(ctx, req interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyOrderStatusChange", reflect.TypeOf((*MockClient)(nil).NotifyOrderStatusChange), ctx, req)
 }
+
+// NotifyReturnStatusChange mocks base method.
+func (m *MockClient) NotifyReturnStatusChange(ctx context.Context, req *entities.NotifyReturnStatusChangeRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyReturnStatusChange", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyReturnStatusChange indicates an expected call of NotifyReturnStatusChange.
+func (mr *MockClientMockRecorder) NotifyReturnStatusChange(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyReturnStatusChange", reflect.TypeOf((*MockClient)(nil).NotifyReturnStatusChange), ctx, req)
+}
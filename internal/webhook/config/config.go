@@ -9,7 +9,11 @@ import (
 // Config is a common service config
 type Config struct {
 	OrderURL string
-	Token    string
+	// ReturnURL receives return status changes, they are sent to OrderURL when empty
+	ReturnURL string
+	// DisputeURL receives payment dispute changes, they are sent to OrderURL when empty
+	DisputeURL string
+	Token      string
 }
 
 // Validate config
@@ -6,3 +6,22 @@ type NotifyOrderStatusChangeRequest struct {
 	OrderReference string `json:"order_reference"`
 	Status         string `json:"status"`
 }
+
+type NotifyOrderDisputeChangeRequest struct {
+	OrderID        string `json:"order_id"`
+	CustomerID     string `json:"customer_id"`
+	OrderReference string `json:"order_reference"`
+	DisputeID      string `json:"dispute_id"`
+	DisputeStatus  string `json:"dispute_status"`
+	Reason         string `json:"reason"`
+	Amount         string `json:"amount"`
+}
+
+type NotifyReturnStatusChangeRequest struct {
+	ReturnID        string `json:"return_id"`
+	ReturnReference string `json:"return_reference"`
+	OrderID         string `json:"order_id"`
+	OrderReference  string `json:"order_reference"`
+	CustomerID      string `json:"customer_id"`
+	Status          string `json:"status"`
+}
@@ -15,6 +15,8 @@ import (
 
 type Service interface {
 	NotifyOrderStatusChange(ctx context.Context, req *entities.NotifyOrderStatusChangeRequest) error
+	NotifyReturnStatusChange(ctx context.Context, req *entities.NotifyReturnStatusChangeRequest) error
+	NotifyOrderDisputeChange(ctx context.Context, req *entities.NotifyOrderDisputeChangeRequest) error
 }
 
 type service struct {
@@ -36,17 +38,41 @@ func New(
 }
 
 func (s *service) NotifyOrderStatusChange(ctx context.Context, req *entities.NotifyOrderStatusChangeRequest) error {
-	operation := func() (any, error) {
+	s.log.Infof("Sending order status update: %v", s.config.OrderURL)
+	return s.sendWebhookRequest(s.postOperation(ctx, s.config.OrderURL, req))
+}
+
+func (s *service) NotifyReturnStatusChange(ctx context.Context, req *entities.NotifyReturnStatusChangeRequest) error {
+	url := s.config.ReturnURL
+	if url == "" {
+		url = s.config.OrderURL
+	}
+
+	s.log.Infof("Sending return status update: %v", url)
+	return s.sendWebhookRequest(s.postOperation(ctx, url, req))
+}
+
+func (s *service) NotifyOrderDisputeChange(ctx context.Context, req *entities.NotifyOrderDisputeChangeRequest) error {
+	url := s.config.DisputeURL
+	if url == "" {
+		url = s.config.OrderURL
+	}
+
+	s.log.Infof("Sending order dispute update: %v", url)
+	return s.sendWebhookRequest(s.postOperation(ctx, url, req))
+}
+
+// postOperation posts the JSON encoded payload to the webhook url.
+func (s *service) postOperation(ctx context.Context, url string, payload any) func() (any, error) {
+	return func() (any, error) {
 		// Marshal the request into JSON
-		requestBody, err := json.Marshal(req)
+		requestBody, err := json.Marshal(payload)
 		if err != nil {
 			s.log.Errorf("Error marshaling request body: %v", err)
 			return nil, backoff.Permanent(err) // Do not retry on JSON marshaling failure
 		}
 
 		// Create the HTTP request
-		url := s.config.OrderURL
-		s.log.Infof("Sending order status update: %v", url)
 		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(requestBody))
 		if err != nil {
 			s.log.Errorf("Error creating request: %v", err)
@@ -58,7 +84,7 @@ func (s *service) NotifyOrderStatusChange(ctx context.Context, req *entities.Not
 		// Execute the HTTP request
 		resp, err := s.httpClient.Do(req)
 		if err != nil {
-			s.log.Errorf("Error making request to order: %v", err)
+			s.log.Errorf("Error making request to webhook: %v", err)
 			return nil, err // Retry on transient network errors
 		}
 		defer resp.Body.Close()
@@ -72,13 +98,6 @@ func (s *service) NotifyOrderStatusChange(ctx context.Context, req *entities.Not
 
 		return nil, nil // Success
 	}
-
-	err := s.sendWebhookRequest(operation)
-	if err != nil {
-		return err
-	}
-
-	return nil
 }
 
 // sendWebhookRequest sends a webhook request with retry logic.
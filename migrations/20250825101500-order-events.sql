@@ -0,0 +1,23 @@
+-- +migrate Up
+CREATE TABLE order_events
+(
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders (id),
+    order_item_id UUID REFERENCES order_items (id),
+    old_status TEXT,
+    new_status TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    source_event_id TEXT,
+    message TEXT,
+    -- clock_timestamp() tells apart the events recorded in the same transaction,
+    -- the sequence orders the ones recorded at the same time
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    sequence BIGSERIAL NOT NULL
+);
+
+CREATE INDEX idx_order_events_order_id_created_at
+ON order_events (order_id, created_at, sequence);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_order_events_order_id_created_at;
+DROP TABLE IF EXISTS order_events;
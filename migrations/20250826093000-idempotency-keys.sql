@@ -0,0 +1,14 @@
+-- +migrate Up
+CREATE TABLE idempotency_keys
+(
+    scope TEXT NOT NULL,
+    key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    response JSONB,
+    claimed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (scope, key)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,21 @@
+-- +migrate Up
+CREATE TABLE order_outbox
+(
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    topic TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_order_outbox_pending
+ON order_outbox (next_attempt_at)
+WHERE status = 'pending';
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_order_outbox_pending;
+DROP TABLE IF EXISTS order_outbox;
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'cancel_refund_pending';
+
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS refund_id text NULL;
+-- +migrate Down
+ALTER TABLE orders
+DROP COLUMN IF EXISTS refund_id;
+-- There is no ALTER TYPE DELETE VALUE in Postgres. You can only add new values.
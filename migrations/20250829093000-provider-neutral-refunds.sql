@@ -0,0 +1,24 @@
+-- +migrate Up
+ALTER TABLE order_items
+RENAME COLUMN stripe_refund_id TO refund_id;
+ALTER TABLE order_items
+RENAME COLUMN stripe_refund_amount TO refund_amount;
+ALTER TABLE order_items
+RENAME COLUMN stripe_refund_created_at TO refund_created_at;
+
+ALTER TABLE orders
+RENAME COLUMN stripe_refund_total TO refund_total;
+
+CREATE INDEX IF NOT EXISTS idx_order_items_refund_id ON order_items (refund_id) WHERE refund_id IS NOT NULL;
+-- +migrate Down
+DROP INDEX IF EXISTS idx_order_items_refund_id;
+
+ALTER TABLE orders
+RENAME COLUMN refund_total TO stripe_refund_total;
+
+ALTER TABLE order_items
+RENAME COLUMN refund_created_at TO stripe_refund_created_at;
+ALTER TABLE order_items
+RENAME COLUMN refund_amount TO stripe_refund_amount;
+ALTER TABLE order_items
+RENAME COLUMN refund_id TO stripe_refund_id;
@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE order_items
+ADD COLUMN tax_amount NUMERIC(10, 2),
+ADD COLUMN shipping_amount NUMERIC(10, 2);
+-- +migrate Down
+ALTER TABLE order_items
+DROP COLUMN IF EXISTS shipping_amount,
+DROP COLUMN IF EXISTS tax_amount;
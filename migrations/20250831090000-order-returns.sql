@@ -0,0 +1,43 @@
+-- +migrate Up
+CREATE TYPE return_status AS ENUM (
+    'requested',
+    'approved',
+    'rejected',
+    'received',
+    'refunded'
+);
+
+CREATE TABLE order_returns
+(
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders (id),
+    customer_id UUID NOT NULL REFERENCES customers (id),
+    return_reference VARCHAR(255) UNIQUE NOT NULL,
+    status return_status NOT NULL DEFAULT 'requested',
+    reason TEXT NOT NULL,
+    note TEXT,
+    refund_amount NUMERIC(10, 2),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_order_returns_order_id ON order_returns (order_id);
+
+CREATE TABLE order_return_items
+(
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    return_id UUID NOT NULL REFERENCES order_returns (id) ON DELETE CASCADE,
+    order_item_id UUID NOT NULL REFERENCES order_items (id),
+    sku VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_order_return_items_return_id ON order_return_items (return_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_order_return_items_return_id;
+DROP TABLE IF EXISTS order_return_items;
+DROP INDEX IF EXISTS idx_order_returns_order_id;
+DROP TABLE IF EXISTS order_returns;
+DROP TYPE IF EXISTS return_status;
@@ -0,0 +1,18 @@
+-- +migrate Up
+ALTER TABLE orders
+ADD COLUMN payment_capture_mode VARCHAR(20) NOT NULL DEFAULT 'automatic',
+ADD COLUMN payment_capture_requested_at TIMESTAMPTZ,
+ADD COLUMN payment_captured_amount NUMERIC(10, 2),
+ADD COLUMN payment_captured_at TIMESTAMPTZ,
+ADD COLUMN payment_capture_failed_at TIMESTAMPTZ,
+ADD COLUMN payment_capture_failure_reason TEXT,
+ADD COLUMN payment_voided_at TIMESTAMPTZ;
+-- +migrate Down
+ALTER TABLE orders
+DROP COLUMN IF EXISTS payment_voided_at,
+DROP COLUMN IF EXISTS payment_capture_failure_reason,
+DROP COLUMN IF EXISTS payment_capture_failed_at,
+DROP COLUMN IF EXISTS payment_captured_at,
+DROP COLUMN IF EXISTS payment_captured_amount,
+DROP COLUMN IF EXISTS payment_capture_requested_at,
+DROP COLUMN IF EXISTS payment_capture_mode;
@@ -0,0 +1,4 @@
+-- +migrate Up
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'requires_action';
+-- +migrate Down
+-- There is no ALTER TYPE DELETE VALUE in Postgres. You can only add new values.
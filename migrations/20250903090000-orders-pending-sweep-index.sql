@@ -0,0 +1,6 @@
+-- +migrate Up
+-- the pending order sweeper looks up orders waiting for their payment by age
+CREATE INDEX IF NOT EXISTS idx_orders_status_created_at ON orders (status, created_at);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_orders_status_created_at;
@@ -0,0 +1,25 @@
+-- +migrate Up
+CREATE TABLE payment_events
+(
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    object_id TEXT,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    processed_at TIMESTAMPTZ,
+    error TEXT,
+    attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT uq_payment_events_provider_event_id UNIQUE (provider, event_id)
+);
+
+CREATE INDEX idx_payment_events_unprocessed
+ON payment_events (provider, status, created_at)
+WHERE status <> 'processed';
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_payment_events_unprocessed;
+DROP TABLE IF EXISTS payment_events;
@@ -0,0 +1,16 @@
+-- +migrate Up
+ALTER TABLE orders
+ADD COLUMN dispute_id TEXT,
+ADD COLUMN dispute_status VARCHAR(20),
+ADD COLUMN dispute_reason TEXT;
+
+ALTER TABLE order_items
+ADD COLUMN refund_failure_reason TEXT;
+-- +migrate Down
+ALTER TABLE order_items
+DROP COLUMN IF EXISTS refund_failure_reason;
+
+ALTER TABLE orders
+DROP COLUMN IF EXISTS dispute_reason,
+DROP COLUMN IF EXISTS dispute_status,
+DROP COLUMN IF EXISTS dispute_id;
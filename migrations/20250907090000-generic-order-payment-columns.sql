@@ -0,0 +1,29 @@
+-- +migrate Up
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payment_provider VARCHAR(20) NOT NULL DEFAULT 'stripe';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payment_id TEXT;
+
+UPDATE orders
+SET payment_provider = CASE
+        WHEN authorizenet_payment_id IS NOT NULL THEN 'authorizeNet'
+        ELSE 'stripe'
+    END,
+    payment_id = COALESCE(stripe_payment_intent_id, authorizenet_payment_id);
+
+ALTER TABLE orders ALTER COLUMN payment_provider DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_orders_payment_id ON orders (payment_id);
+
+ALTER TABLE orders DROP COLUMN IF EXISTS stripe_payment_intent_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS authorizenet_payment_id;
+
+-- +migrate Down
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS stripe_payment_intent_id TEXT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS authorizenet_payment_id TEXT;
+
+UPDATE orders SET stripe_payment_intent_id = payment_id WHERE payment_provider = 'stripe';
+UPDATE orders SET authorizenet_payment_id = payment_id WHERE payment_provider = 'authorizeNet';
+
+DROP INDEX IF EXISTS idx_orders_payment_id;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_provider;
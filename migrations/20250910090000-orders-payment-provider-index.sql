@@ -0,0 +1,7 @@
+-- +migrate Up
+DROP INDEX IF EXISTS idx_orders_payment_id;
+CREATE INDEX IF NOT EXISTS idx_orders_payment_provider_payment_id ON orders (payment_provider, payment_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_orders_payment_provider_payment_id;
+CREATE INDEX IF NOT EXISTS idx_orders_payment_id ON orders (payment_id);
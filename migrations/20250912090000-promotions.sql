@@ -0,0 +1,54 @@
+-- +migrate Up
+CREATE TABLE promotions
+(
+    id UUID NOT NULL PRIMARY KEY,
+    code VARCHAR(64) UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    discount_type VARCHAR(20) NOT NULL,
+    value NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    target_skus JSONB,
+    target_attributes JSONB,
+    min_subtotal NUMERIC(10, 2),
+    usage_limit INT,
+    usage_limit_per_customer INT,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_promotions_automatic ON promotions (active) WHERE code IS NULL;
+
+ALTER TABLE carts ADD COLUMN coupon_code VARCHAR(64);
+
+ALTER TABLE orders ADD COLUMN discount_amount NUMERIC(10, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE order_items ADD COLUMN discount_amount NUMERIC(10, 2);
+
+CREATE TABLE order_discounts
+(
+    id UUID NOT NULL PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders (id),
+    promotion_id UUID NOT NULL REFERENCES promotions (id),
+    code VARCHAR(64),
+    name VARCHAR(255) NOT NULL,
+    discount_type VARCHAR(20) NOT NULL,
+    amount NUMERIC(10, 2) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_order_discounts_order_id ON order_discounts (order_id);
+CREATE INDEX idx_order_discounts_promotion_id ON order_discounts (promotion_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_order_discounts_promotion_id;
+DROP INDEX IF EXISTS idx_order_discounts_order_id;
+DROP TABLE IF EXISTS order_discounts;
+
+ALTER TABLE order_items DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE orders DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE carts DROP COLUMN IF EXISTS coupon_code;
+
+DROP INDEX IF EXISTS idx_promotions_automatic;
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,17 @@
+-- +migrate Up
+ALTER TABLE cart_items ADD COLUMN price NUMERIC(10, 2);
+ALTER TABLE cart_items ADD COLUMN currency VARCHAR(3);
+
+-- the items already in the carts keep the prices of their variants
+UPDATE cart_items
+SET price    = product_variants.price,
+    currency = product_variants.currency
+FROM product_variants
+WHERE cart_items.product_variant_id = product_variants.id;
+
+ALTER TABLE cart_items ALTER COLUMN price SET NOT NULL;
+ALTER TABLE cart_items ALTER COLUMN currency SET NOT NULL;
+
+-- +migrate Down
+ALTER TABLE cart_items DROP COLUMN IF EXISTS currency;
+ALTER TABLE cart_items DROP COLUMN IF EXISTS price;
@@ -0,0 +1,25 @@
+-- +migrate Up
+-- guests checking out get a customer of their own, the same email can sign up later
+ALTER TABLE customers ADD COLUMN is_guest BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS customers_email_key;
+CREATE UNIQUE INDEX customers_email_key ON customers (email) WHERE NOT is_guest;
+CREATE UNIQUE INDEX customers_guest_email_key ON customers (email) WHERE is_guest;
+
+-- a guest cart is identified by its token until it is merged into the cart of a customer
+ALTER TABLE carts ALTER COLUMN customer_id DROP NOT NULL;
+ALTER TABLE carts ADD COLUMN guest_token UUID UNIQUE;
+ALTER TABLE carts ADD CONSTRAINT carts_owner_check CHECK (customer_id IS NOT NULL OR guest_token IS NOT NULL);
+
+ALTER TYPE cart_status ADD VALUE IF NOT EXISTS 'merged';
+
+-- guests quote shipping to an address they don't save
+ALTER TABLE cart_shipping_rates ALTER COLUMN address_id DROP NOT NULL;
+
+-- +migrate Down
+ALTER TABLE carts DROP CONSTRAINT IF EXISTS carts_owner_check;
+ALTER TABLE carts DROP COLUMN IF EXISTS guest_token;
+DROP INDEX IF EXISTS customers_guest_email_key;
+DROP INDEX IF EXISTS customers_email_key;
+ALTER TABLE customers ADD CONSTRAINT customers_email_key UNIQUE (email);
+ALTER TABLE customers DROP COLUMN IF EXISTS is_guest;
+-- There is no ALTER TYPE DELETE VALUE in Postgres. You can only add new values.
@@ -0,0 +1,30 @@
+-- +migrate Up
+-- the usage limits are enforced with conditional updates of these counters when the order is stored,
+-- orders whose payment failed or that were cancelled give their uses back
+ALTER TABLE promotions ADD COLUMN times_used INT NOT NULL DEFAULT 0;
+
+CREATE TABLE promotion_redemptions
+(
+    promotion_id UUID NOT NULL REFERENCES promotions (id),
+    customer_id UUID NOT NULL REFERENCES customers (id),
+    times_used INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (promotion_id, customer_id)
+);
+
+UPDATE promotions
+SET times_used = (SELECT COUNT(DISTINCT order_discounts.order_id)
+                  FROM order_discounts
+                           JOIN orders ON orders.id = order_discounts.order_id
+                  WHERE order_discounts.promotion_id = promotions.id
+                    AND orders.status NOT IN ('payment_failed', 'cancelled'));
+
+INSERT INTO promotion_redemptions (promotion_id, customer_id, times_used)
+SELECT order_discounts.promotion_id, orders.customer_id, COUNT(DISTINCT order_discounts.order_id)
+FROM order_discounts
+         JOIN orders ON orders.id = order_discounts.order_id
+WHERE orders.status NOT IN ('payment_failed', 'cancelled')
+GROUP BY order_discounts.promotion_id, orders.customer_id;
+
+-- +migrate Down
+DROP TABLE IF EXISTS promotion_redemptions;
+ALTER TABLE promotions DROP COLUMN IF EXISTS times_used;
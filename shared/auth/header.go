@@ -8,4 +8,5 @@ const (
 	AuthorizationKey headerKey = headerKey("Authorization")
 	Access           headerKey = headerKey("Access")
 	CustomerIDKey    headerKey = headerKey("x-customer-id")
+	CartTokenKey     headerKey = headerKey("x-cart-token")
 )
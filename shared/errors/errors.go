@@ -10,6 +10,8 @@ type APIError struct {
 	StatusCode int    `json:"status_code"`
 	ErrorCode  string `json:"error_code"`
 	Message    string `json:"message"`
+	// Details lets the client act on the error, like the lines of a cart whose prices changed
+	Details interface{} `json:"details,omitempty"`
 }
 
 // Implement the error interface
@@ -61,3 +63,10 @@ func IsAPIError(err error) (*APIError, bool) {
 	}
 	return nil, false
 }
+
+// IsTransientError tells apart the failures worth a retry, e.g. the database or a provider being
+// unavailable, from the ones caused by the request itself like an unknown payment.
+func IsTransientError(err error) bool {
+	apiErr, ok := IsAPIError(err)
+	return !ok || apiErr.StatusCode >= http.StatusInternalServerError
+}
@@ -15,6 +15,8 @@ var (
 	contextKeyUserAgentOrigin = contextKey("user_agent_origin")
 	contextKeyTransport       = contextKey("transport")
 	contextKeyCustomerID      = contextKey("customer_id")
+	contextKeyCartToken       = contextKey("cart_token")
+	contextKeySourceEventID   = contextKey("source_event_id")
 )
 
 func (c contextKey) String() string { return string(c) }
@@ -93,3 +95,37 @@ func XCustomerID(ctx context.Context) string {
 
 	return ""
 }
+
+// WithXCartToken injects the token of a guest cart to the context
+func WithXCartToken(ctx context.Context, cartToken string) context.Context {
+	return context.WithValue(ctx, contextKeyCartToken, cartToken)
+}
+
+// XCartToken extracts the token of a guest cart from the context
+func XCartToken(ctx context.Context) string {
+	if val, ok := ctx.Value(contextKeyCartToken).(string); ok {
+		// cart tokens are generated as UUIDs
+		_, err := uuid.Parse(val)
+		if err != nil {
+			return ""
+		}
+		return val
+	}
+
+	return ""
+}
+
+// WithSourceEventID injects the ID of the external event (e.g. a payment provider webhook)
+// that triggered the current operation to the context
+func WithSourceEventID(ctx context.Context, eventID string) context.Context {
+	return context.WithValue(ctx, contextKeySourceEventID, eventID)
+}
+
+// SourceEventID extracts the ID of the external event that triggered the current operation from the context
+func SourceEventID(ctx context.Context) string {
+	if val, ok := ctx.Value(contextKeySourceEventID).(string); ok {
+		return val
+	}
+
+	return ""
+}
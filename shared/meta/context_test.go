@@ -81,3 +81,27 @@ func TestWithXCustomerID(t *testing.T) {
 
 	assert.Equal(t, "test-x-customer-id", ctx.Value(contextKeyCustomerID))
 }
+
+func TestXCartToken(t *testing.T) {
+	ctx := context.Background()
+	ctx = WithXCartToken(ctx, "0b5a4c8e-2f0d-4a57-9a3c-7d1e8f6b2c41")
+
+	assert.Equal(t, "0b5a4c8e-2f0d-4a57-9a3c-7d1e8f6b2c41", XCartToken(ctx))
+	assert.Equal(t, "", XCartToken(WithXCartToken(context.Background(), "not-a-token")))
+	assert.Equal(t, "", XCartToken(context.Background()))
+}
+
+func TestSourceEventID(t *testing.T) {
+	ctx := context.Background()
+	ctx = WithSourceEventID(ctx, "evt_123")
+
+	assert.Equal(t, "evt_123", SourceEventID(ctx))
+	assert.Equal(t, "", SourceEventID(context.Background()))
+}
+
+func TestWithSourceEventID(t *testing.T) {
+	ctx := context.Background()
+	ctx = WithSourceEventID(ctx, "test-source-event-id")
+
+	assert.Equal(t, "test-source-event-id", ctx.Value(contextKeySourceEventID))
+}
@@ -17,6 +17,7 @@ func (h *authHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	userIDStr := r.Header.Get(string(auth.CustomerIDKey))
 	ctx = meta.WithXCustomerID(ctx, userIDStr)
+	ctx = meta.WithXCartToken(ctx, r.Header.Get(string(auth.CartTokenKey)))
 
 	h.next.ServeHTTP(w, r.WithContext(ctx))
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	ordersEntities "github.com/nurdsoft/nurd-commerce-core/internal/orders/entities"
 	ordersRepo "github.com/nurdsoft/nurd-commerce-core/internal/orders/repository"
 	"github.com/nurdsoft/nurd-commerce-core/internal/product/productclient"
 	inventoryEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/inventory/entities"
@@ -75,6 +76,24 @@ func (l localClient) CreateOrder(ctx context.Context, req inventoryEntities.Crea
 		return nil, errors.New("customer salesforce id is required")
 	}
 
+	// the order is delivered again when a previous attempt failed half way, the salesforce order it created is reused
+	if req.Order.SalesforceID != "" {
+		res := &entities.CreateSFOrderResponse{ID: req.Order.SalesforceID, Success: true}
+		return res, l.addOrderItems(ctx, req, res.ID)
+	}
+
+	existing, err := l.svc.GetOrdersByReference(ctx, req.Order.OrderReference)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing.Records) > 0 {
+		res := &entities.CreateSFOrderResponse{ID: existing.Records[0].ID, Success: true}
+		if err := l.setOrderSalesforceID(ctx, req.Order, res.ID); err != nil {
+			return nil, err
+		}
+		return res, l.addOrderItems(ctx, req, res.ID)
+	}
+
 	city := ""
 	if req.Address.City != nil {
 		city = *req.Address.City
@@ -126,14 +145,34 @@ func (l localClient) CreateOrder(ctx context.Context, req inventoryEntities.Crea
 		return nil, err
 	}
 	if res.Success {
-		// update order with salesforce order id
-		err = l.ordersRepo.Update(ctx, map[string]interface{}{
-			"salesforce_id": res.ID,
-		}, req.Order.ID.String(), req.Order.CustomerID.String())
-		if err != nil {
+		if err := l.setOrderSalesforceID(ctx, req.Order, res.ID); err != nil {
 			return nil, err
 		}
 
+		if err := l.addOrderItems(ctx, req, res.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// setOrderSalesforceID updates the order with the salesforce order id.
+func (l localClient) setOrderSalesforceID(ctx context.Context, order ordersEntities.Order, sfOrderID string) error {
+	return l.ordersRepo.Update(ctx, map[string]interface{}{
+		"salesforce_id": sfOrderID,
+	}, order.ID.String(), order.CustomerID.String(), ordersEntities.OrderEventSource{Actor: ordersEntities.ActorProvider}, nil)
+}
+
+// addOrderItems adds the items to the salesforce order unless an earlier attempt added them already,
+// then stores the salesforce IDs of the items.
+func (l localClient) addOrderItems(ctx context.Context, req inventoryEntities.CreateInventoryOrderRequest, sfOrderID string) error {
+	items, err := l.svc.GetOrderItems(ctx, sfOrderID)
+	if err != nil {
+		return err
+	}
+
+	if len(items.Records) == 0 {
 		productIDs := func() []string {
 			var ids []string
 			for _, item := range req.CartItems {
@@ -144,78 +183,74 @@ func (l localClient) CreateOrder(ctx context.Context, req inventoryEntities.Crea
 		// get salesforce products by product ids
 		products, err := l.productClient.GetProductsByIDs(ctx, productIDs())
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		sfOrderItems := make([]*entities.OrderItem, 0, len(req.CartItems))
+		if len(products) == 0 {
+			return errors.New("no products found for order items")
+		}
 
-		if len(products) > 0 {
-			for _, item := range req.CartItems {
+		sfOrderItems := make([]*entities.OrderItem, 0, len(req.CartItems))
+		for _, item := range req.CartItems {
 
-				description := ""
-				if item.Description != nil {
-					description = *item.Description
-				}
+			description := ""
+			if item.Description != nil {
+				description = *item.Description
+			}
 
-				sfOrderItem := entities.OrderItem{
-					OrderID:     res.ID,
-					Quantity:    item.Quantity,
-					UnitPrice:   item.Price.InexactFloat64(),
-					Description: item.Name,
-					TypeC:       description,
-				}
+			sfOrderItem := entities.OrderItem{
+				OrderID:     sfOrderID,
+				Quantity:    item.Quantity,
+				UnitPrice:   item.Price.InexactFloat64(),
+				Description: item.Name,
+				TypeC:       description,
+			}
 
-				for _, product := range products {
-					if product.ID == item.ProductID && product.SalesforcePricebookEntryId != nil {
-						sfOrderItem.PricebookEntryID = *product.SalesforcePricebookEntryId
-						break
-					}
+			for _, product := range products {
+				if product.ID == item.ProductID && product.SalesforcePricebookEntryId != nil {
+					sfOrderItem.PricebookEntryID = *product.SalesforcePricebookEntryId
+					break
 				}
-
-				sfOrderItems = append(sfOrderItems, &sfOrderItem)
 			}
 
-			// add items to order on salesforce
-			_, err = l.svc.AddOrderItems(ctx, sfOrderItems)
-			if err != nil {
-				return nil, err
-			}
+			sfOrderItems = append(sfOrderItems, &sfOrderItem)
+		}
 
-			// get order items from salesforce
-			items, err := l.svc.GetOrderItems(ctx, res.ID)
-			if err != nil {
-				return nil, err
-			}
+		// add items to order on salesforce
+		_, err = l.svc.AddOrderItems(ctx, sfOrderItems)
+		if err != nil {
+			return err
+		}
 
-			if len(items.Records) > 0 {
-				// map of order item id to salesforce order item id
-				ids := make(map[string]string)
-				// build the map based on TypeC and Description and ProductID & Product2Id
-				// salesforce order items
-				for _, item := range items.Records {
-					// order items
-					for _, orderItem := range req.OrderItems {
-						if orderItem.Description != nil &&
-							item.TypeC == *orderItem.Description &&
-							item.Description == orderItem.Name {
-							ids[orderItem.ID.String()] = item.ID
-							break
-						}
-					}
-				}
+		// get order items from salesforce
+		items, err = l.svc.GetOrderItems(ctx, sfOrderID)
+		if err != nil {
+			return err
+		}
+	}
 
-				// update order items with salesforce order item ids
-				err = l.ordersRepo.AddSalesforceIDPerOrderItem(ctx, ids)
-				if err != nil {
-					return nil, err
-				}
+	if len(items.Records) == 0 {
+		return nil
+	}
+
+	// map of order item id to salesforce order item id
+	ids := make(map[string]string)
+	// build the map based on TypeC and Description and ProductID & Product2Id
+	// salesforce order items
+	for _, item := range items.Records {
+		// order items
+		for _, orderItem := range req.OrderItems {
+			if orderItem.Description != nil &&
+				item.TypeC == *orderItem.Description &&
+				item.Description == orderItem.Name {
+				ids[orderItem.ID.String()] = item.ID
+				break
 			}
-		} else {
-			return nil, errors.New("no products found for order items")
 		}
 	}
 
-	return res, nil
+	// update order items with salesforce order item ids
+	return l.ordersRepo.AddSalesforceIDPerOrderItem(ctx, ids)
 }
 
 func (l localClient) AddOrderItems(ctx context.Context, items []*entities.OrderItem) (*entities.AddOrderItemResponse, error) {
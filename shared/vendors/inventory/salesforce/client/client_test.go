@@ -318,6 +318,7 @@ func TestClient_CreateOrder(t *testing.T) {
 	}
 
 	t.Run("Error creating order", func(t *testing.T) {
+		mockService.EXPECT().GetOrdersByReference(ctx, req.Order.OrderReference).Return(&sfEntities.GetOrdersResponse{}, nil)
 		mockService.EXPECT().CreateOrder(ctx, gomock.Any()).Return(nil, &appErrors.APIError{Message: "Error creating order"})
 
 		_, err := client.CreateOrder(ctx, req)
@@ -330,6 +331,9 @@ func TestClient_CreateOrder(t *testing.T) {
 	t.Run("Valid order creation", func(t *testing.T) {
 		salesforceOrderID := "0011N00001Gv7PQQAZ"
 		salesforcePricebookEntryID := "0011N00001Gv7PQQAZ"
+		mockService.EXPECT().
+			GetOrdersByReference(ctx, req.Order.OrderReference).
+			Return(&sfEntities.GetOrdersResponse{}, nil)
 		mockService.EXPECT().
 			CreateOrder(ctx, gomock.Any()).
 			Do(func(_ context.Context, sfReq *sfEntities.CreateSFOrderRequest) {
@@ -348,12 +352,17 @@ func TestClient_CreateOrder(t *testing.T) {
 			Return(&sfEntities.CreateSFOrderResponse{ID: salesforceOrderID, Success: true}, nil)
 
 		mockOrdersRepo.EXPECT().
-			Update(ctx, gomock.Any(), orderID.String(), customerID.String()).
-			Do(func(_ context.Context, data map[string]interface{}, orderID string, customerID string) {
+			Update(ctx, gomock.Any(), orderID.String(), customerID.String(), gomock.Any(), nil).
+			Do(func(_ context.Context, data map[string]interface{}, orderID string, customerID string, _ orderEntities.OrderEventSource, _ []*orderEntities.OutboxMessage) {
 				assert.Equal(t, salesforceOrderID, data["salesforce_id"])
 			}).
 			Return(nil)
 
+		// nothing was added to the new order yet
+		mockService.EXPECT().
+			GetOrderItems(ctx, salesforceOrderID).
+			Return(&sfEntities.GetOrderItemsResponse{}, nil)
+
 		mockProductClient.EXPECT().
 			GetProductsByIDs(ctx, []string{productID.String()}).Return([]productEntities.Product{
 			{
@@ -393,6 +402,57 @@ func TestClient_CreateOrder(t *testing.T) {
 		_, err := client.CreateOrder(ctx, req)
 		assert.NoError(t, err)
 	})
+
+	t.Run("Retried order reuses the salesforce order and its items", func(t *testing.T) {
+		salesforceOrderID := "0011N00001Gv7PQQAZ"
+		retryReq := req
+		retryReq.Order.SalesforceID = salesforceOrderID
+
+		mockService.EXPECT().
+			GetOrderItems(ctx, salesforceOrderID).
+			Return(&sfEntities.GetOrderItemsResponse{Records: []sfEntities.Records{
+				{ID: "802XX000001", TypeC: description, Description: description},
+			}}, nil)
+		mockOrdersRepo.EXPECT().
+			AddSalesforceIDPerOrderItem(ctx, map[string]string{req.OrderItems[0].ID.String(): "802XX000001"}).
+			Return(nil)
+
+		res, err := client.CreateOrder(ctx, retryReq)
+		assert.NoError(t, err)
+		assert.Equal(t, salesforceOrderID, res.(*sfEntities.CreateSFOrderResponse).ID)
+	})
+
+	t.Run("Order created by a failed attempt is found by its reference", func(t *testing.T) {
+		salesforceOrderID := "0011N00001Gv7PQQAZ"
+		salesforcePricebookEntryID := "0011N00001Gv7PQQAZ"
+
+		mockService.EXPECT().
+			GetOrdersByReference(ctx, req.Order.OrderReference).
+			Return(&sfEntities.GetOrdersResponse{Records: []sfEntities.OrderRecord{{ID: salesforceOrderID}}}, nil)
+		mockOrdersRepo.EXPECT().
+			Update(ctx, map[string]interface{}{"salesforce_id": salesforceOrderID}, orderID.String(), customerID.String(), gomock.Any(), nil).
+			Return(nil)
+		mockService.EXPECT().
+			GetOrderItems(ctx, salesforceOrderID).
+			Return(&sfEntities.GetOrderItemsResponse{}, nil)
+		mockProductClient.EXPECT().
+			GetProductsByIDs(ctx, []string{productID.String()}).
+			Return([]productEntities.Product{{ID: productID, SalesforcePricebookEntryId: &salesforcePricebookEntryID}}, nil)
+		mockService.EXPECT().
+			AddOrderItems(ctx, gomock.Any()).
+			Return(&sfEntities.AddOrderItemResponse{HasErrors: false}, nil)
+		mockService.EXPECT().
+			GetOrderItems(ctx, salesforceOrderID).
+			Return(&sfEntities.GetOrderItemsResponse{Records: []sfEntities.Records{
+				{ID: "802XX000001", TypeC: description, Description: description},
+			}}, nil)
+		mockOrdersRepo.EXPECT().
+			AddSalesforceIDPerOrderItem(ctx, gomock.Any()).
+			Return(nil)
+
+		_, err := client.CreateOrder(ctx, req)
+		assert.NoError(t, err)
+	})
 }
 
 func TestClient_AddOrderItems(t *testing.T) {
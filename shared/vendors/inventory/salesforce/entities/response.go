@@ -43,6 +43,14 @@ type GetOrderItemsResponse struct {
 	Done      bool      `json:"done"`
 	Records   []Records `json:"records"`
 }
+type GetOrdersResponse struct {
+	TotalSize int           `json:"totalSize"`
+	Done      bool          `json:"done"`
+	Records   []OrderRecord `json:"records"`
+}
+type OrderRecord struct {
+	ID string `json:"Id"`
+}
 type Attributes struct {
 	Type string `json:"type"`
 	URL  string `json:"url"`
@@ -169,6 +169,21 @@ func (mr *MockServiceMockRecorder) GetOrderItems(ctx, orderId interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderItems", reflect.TypeOf((*MockService)(nil).GetOrderItems), ctx, orderId)
 }
 
+// GetOrdersByReference mocks base method.
+func (m *MockService) GetOrdersByReference(ctx context.Context, orderReference string) (*entities.GetOrdersResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrdersByReference", ctx, orderReference)
+	ret0, _ := ret[0].(*entities.GetOrdersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrdersByReference indicates an expected call of GetOrdersByReference.
+func (mr *MockServiceMockRecorder) GetOrdersByReference(ctx, orderReference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersByReference", reflect.TypeOf((*MockService)(nil).GetOrdersByReference), ctx, orderReference)
+}
+
 // UpdateOrderStatus mocks base method.
 func (m *MockService) UpdateOrderStatus(ctx context.Context, req *entities.UpdateOrderRequest) error {
 	m.ctrl.T.Helper()
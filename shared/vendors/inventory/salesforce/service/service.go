@@ -35,6 +35,7 @@ type Service interface {
 	AddOrderItems(ctx context.Context, items []*entities.OrderItem) (*entities.AddOrderItemResponse, error)
 	UpdateOrderStatus(ctx context.Context, req *entities.UpdateOrderRequest) error
 	GetOrderItems(ctx context.Context, orderId string) (*entities.GetOrderItemsResponse, error)
+	GetOrdersByReference(ctx context.Context, orderReference string) (*entities.GetOrdersResponse, error)
 }
 type service struct {
 	config     salesforceConfig.Config
@@ -449,6 +450,36 @@ func (s *service) GetOrderItems(ctx context.Context, orderId string) (*entities.
 	return res, nil
 }
 
+// GetOrdersByReference returns the salesforce orders created for the order reference.
+func (s *service) GetOrdersByReference(ctx context.Context, orderReference string) (*entities.GetOrdersResponse, error) {
+	url := fmt.Sprintf(
+		"%s/services/data/%s/query/?q=SELECT+Id+FROM+Order+WHERE+Order_Reference__c='%s'",
+		s.config.ApiHost,
+		s.config.ApiVersion,
+		orderReference,
+	)
+
+	session, err := s.newSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := session.httpRequest(http.MethodGet, url, nil)
+	if err != nil {
+		s.log.Error(logPrefix, "http request failed,", err)
+		return nil, err
+	}
+
+	res := &entities.GetOrdersResponse{}
+	err = json.Unmarshal(data, res)
+	if err != nil {
+		s.log.Error(logPrefix, "json decode failed,", err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
 // makeUrl creates a salesforce api url with the given sObjectType and objectId
 func (s *service) makeUrl(sObjectType, objectId string) string {
 	url := fmt.Sprintf("%s/services/data/%s/sobjects/%s/%s",
@@ -13,9 +13,16 @@ type Client interface {
 	CreateCustomer(ctx context.Context, req entities.CreateCustomerRequest) (entities.CreateCustomerResponse, error)
 	CreateCustomerPaymentProfile(ctx context.Context, req entities.CreateCustomerPaymentProfileRequest) (entities.CreateCustomerPaymentProfileResponse, error)
 	GetCustomerPaymentMethods(ctx context.Context, req entities.GetPaymentProfilesRequest) (entities.GetPaymentProfilesResponse, error)
-	CreatePayment(ctx context.Context, req any) (providers.PaymentProviderResponse, error)
+	UpdateCustomerPaymentProfile(ctx context.Context, req entities.UpdateCustomerPaymentProfileRequest) error
+	DeleteCustomerPaymentProfile(ctx context.Context, req entities.DeleteCustomerPaymentProfileRequest) error
+	CreatePayment(ctx context.Context, req providers.CreatePaymentRequest) (providers.PaymentProviderResponse, error)
 	GetProvider() providers.ProviderType
-	Refund(ctx context.Context, req any) (*providers.RefundResponse, error)
+	Refund(ctx context.Context, req providers.RefundRequest) (*providers.RefundResponse, error)
+	Capture(ctx context.Context, req providers.CaptureRequest) (providers.PaymentProviderResponse, error)
+	Void(ctx context.Context, req providers.VoidRequest) error
+	GetPayment(ctx context.Context, paymentID string) (providers.PaymentProviderResponse, error)
+	GetPaymentDetails(ctx context.Context, paymentID string) (*providers.PaymentDetails, error)
+	GetRefundDetails(ctx context.Context, refundID string) (*providers.RefundDetails, error)
 }
 
 func NewClient(svc service.Service) Client {
@@ -38,13 +45,42 @@ func (c *localClient) GetCustomerPaymentMethods(ctx context.Context, req entitie
 	return c.svc.GetCustomerPaymentProfiles(ctx, req)
 }
 
-func (c *localClient) CreatePayment(ctx context.Context, req any) (providers.PaymentProviderResponse, error) {
-	authorizeNetReq, ok := req.(entities.CreatePaymentTransactionRequest)
-	if !ok {
-		return providers.PaymentProviderResponse{}, errors.New("invalid payment request type")
+func (c *localClient) UpdateCustomerPaymentProfile(ctx context.Context, req entities.UpdateCustomerPaymentProfileRequest) error {
+	return c.svc.UpdateCustomerPaymentProfile(ctx, req)
+}
+
+func (c *localClient) DeleteCustomerPaymentProfile(ctx context.Context, req entities.DeleteCustomerPaymentProfileRequest) error {
+	return c.svc.DeleteCustomerPaymentProfile(ctx, req)
+}
+
+// CreatePayment charges the payment details collected by Accept.js, or the payment profile of the customer
+// when a payment method ID is given. Authorize.net has no idempotency keys, it rejects duplicate
+// transactions on its own.
+func (c *localClient) CreatePayment(ctx context.Context, req providers.CreatePaymentRequest) (providers.PaymentProviderResponse, error) {
+	var customerProfileID string
+	if req.PaymentMethodID != "" {
+		if req.CustomerID == nil {
+			return providers.PaymentProviderResponse{}, errors.New("authorize.net customer profile ID is required to charge a payment profile")
+		}
+		customerProfileID = *req.CustomerID
 	}
 
-	res, err := c.svc.CreatePaymentTransaction(ctx, authorizeNetReq)
+	res, err := c.svc.CreatePaymentTransaction(ctx, entities.CreatePaymentTransactionRequest{
+		Amount:            req.Amount,
+		PaymentNonce:      req.PaymentNonce,
+		CustomerProfileID: customerProfileID,
+		PaymentProfileID:  req.PaymentMethodID,
+		BillingInfo: entities.BillingInfo{
+			FirstName: req.BillingInfo.FirstName,
+			LastName:  req.BillingInfo.LastName,
+			Address:   req.BillingInfo.Address,
+			City:      req.BillingInfo.City,
+			State:     req.BillingInfo.State,
+			Country:   req.BillingInfo.Country,
+			Zip:       req.BillingInfo.Zip,
+		},
+		AuthorizeOnly: req.AuthorizeOnly,
+	})
 	if err != nil {
 		return providers.PaymentProviderResponse{}, err
 	}
@@ -55,9 +91,186 @@ func (c *localClient) CreatePayment(ctx context.Context, req any) (providers.Pay
 	}, nil
 }
 
-func (c *localClient) Refund(ctx context.Context, req any) (*providers.RefundResponse, error) {
-	// return not implemented error for now
-	return &providers.RefundResponse{}, errors.New("refund not implemented for AuthorizeNet")
+// Refund returns money from a transaction. Transactions that haven't settled yet can't be refunded,
+// they are voided instead, which only works for the whole amount.
+// The refund is reported as pending until the Authorize.net webhook confirms it.
+func (c *localClient) Refund(ctx context.Context, refundReq providers.RefundRequest) (*providers.RefundResponse, error) {
+	if refundReq.PaymentID == "" {
+		return nil, errors.New("transaction ID is required for refund")
+	}
+
+	details, err := c.svc.GetTransactionDetails(ctx, entities.GetTransactionDetailsRequest{
+		TransactionID: refundReq.PaymentID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch details.Status {
+	case service.TransactionStatusSettledSuccessfully:
+		amount := refundReq.Amount
+		if amount.IsZero() {
+			amount = details.SettleAmount
+		}
+
+		res, err := c.svc.RefundTransaction(ctx, entities.RefundTransactionRequest{
+			TransactionID:  refundReq.PaymentID,
+			Amount:         amount,
+			CardNumber:     details.CardNumber,
+			ExpirationDate: details.ExpirationDate,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &providers.RefundResponse{ID: res.ID, Status: providers.RefundStatusPending}, nil
+	case service.TransactionStatusCapturedPendingSettlement, service.TransactionStatusAuthorizedPendingCapture:
+		if !refundReq.Amount.IsZero() && !refundReq.Amount.Equal(details.AuthAmount) {
+			return nil, errors.New("partial refunds are only possible once the transaction has settled")
+		}
+
+		res, err := c.svc.VoidTransaction(ctx, entities.VoidTransactionRequest{
+			TransactionID: refundReq.PaymentID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &providers.RefundResponse{ID: res.ID, Status: providers.RefundStatusPending}, nil
+	default:
+		return nil, errors.Errorf("transaction in status %s can't be refunded", details.Status)
+	}
+}
+
+// Capture collects the funds of a transaction created with AuthorizeOnly. A transaction that was
+// captured already is reported as captured, so that a retried capture doesn't fail.
+func (c *localClient) Capture(ctx context.Context, captureReq providers.CaptureRequest) (providers.PaymentProviderResponse, error) {
+	if captureReq.PaymentID == "" {
+		return providers.PaymentProviderResponse{}, errors.New("transaction ID is required for capture")
+	}
+
+	details, err := c.svc.GetTransactionDetails(ctx, entities.GetTransactionDetailsRequest{
+		TransactionID: captureReq.PaymentID,
+	})
+	if err != nil {
+		return providers.PaymentProviderResponse{}, err
+	}
+
+	switch details.Status {
+	case service.TransactionStatusAuthorizedPendingCapture:
+		res, err := c.svc.CaptureTransaction(ctx, entities.CaptureTransactionRequest{
+			TransactionID: captureReq.PaymentID,
+			Amount:        captureReq.Amount,
+		})
+		if err != nil {
+			return providers.PaymentProviderResponse{}, err
+		}
+
+		return providers.PaymentProviderResponse{
+			ID:     res.ID,
+			Status: mapAuthorizeNetStatusToPaymentStatus(res.Status),
+		}, nil
+	case service.TransactionStatusCapturedPendingSettlement, service.TransactionStatusSettledSuccessfully:
+		return providers.PaymentProviderResponse{ID: details.ID, Status: providers.PaymentStatusSuccess}, nil
+	default:
+		return providers.PaymentProviderResponse{}, errors.Errorf("transaction in status %s can't be captured", details.Status)
+	}
+}
+
+// Void releases the funds held by a transaction that wasn't captured.
+func (c *localClient) Void(ctx context.Context, voidReq providers.VoidRequest) error {
+	if voidReq.PaymentID == "" {
+		return errors.New("transaction ID is required for void")
+	}
+
+	_, err := c.svc.VoidTransaction(ctx, entities.VoidTransactionRequest{
+		TransactionID: voidReq.PaymentID,
+	})
+
+	return err
+}
+
+// GetPayment looks up the transaction details to tell the current state of the payment.
+func (c *localClient) GetPayment(ctx context.Context, paymentID string) (providers.PaymentProviderResponse, error) {
+	details, err := c.svc.GetTransactionDetails(ctx, entities.GetTransactionDetailsRequest{
+		TransactionID: paymentID,
+	})
+	if err != nil {
+		return providers.PaymentProviderResponse{}, err
+	}
+
+	return providers.PaymentProviderResponse{
+		ID:     details.ID,
+		Status: mapTransactionStatusToPaymentStatus(details.Status),
+	}, nil
+}
+
+// GetPaymentDetails looks up the transaction details along with the amounts authorized and captured.
+// Refunds are transactions of their own, they are not reported along with the payment.
+func (c *localClient) GetPaymentDetails(ctx context.Context, paymentID string) (*providers.PaymentDetails, error) {
+	details, err := c.svc.GetTransactionDetails(ctx, entities.GetTransactionDetailsRequest{
+		TransactionID: paymentID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &providers.PaymentDetails{
+		ID:             details.ID,
+		Status:         mapTransactionStatusToPaymentStatus(details.Status),
+		ProviderStatus: details.Status,
+		Amount:         details.AuthAmount,
+	}
+	// authorized transactions have no settle amount until they are captured
+	if details.Status != service.TransactionStatusAuthorizedPendingCapture && res.Status == providers.PaymentStatusSuccess {
+		res.CapturedAmount = details.SettleAmount
+	}
+
+	return res, nil
+}
+
+// GetRefundDetails looks up the details of a refund transaction. Payments refunded before they
+// settled were voided instead, the refund ID is then the one of the voided payment.
+func (c *localClient) GetRefundDetails(ctx context.Context, refundID string) (*providers.RefundDetails, error) {
+	details, err := c.svc.GetTransactionDetails(ctx, entities.GetTransactionDetailsRequest{
+		TransactionID: refundID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	status := providers.RefundStatusFailed
+	switch {
+	case details.Type != service.TransactionTypeRefund:
+		if details.Status == service.TransactionStatusVoided {
+			status = providers.RefundStatusSucceeded
+		}
+	case details.Status == service.TransactionStatusRefundSettledSuccessfully:
+		status = providers.RefundStatusSucceeded
+	case details.Status == service.TransactionStatusRefundPendingSettlement:
+		status = providers.RefundStatusPending
+	}
+
+	return &providers.RefundDetails{
+		ID:     details.ID,
+		Status: status,
+		Amount: details.AuthAmount,
+	}, nil
+}
+
+// mapTransactionStatusToPaymentStatus maps the getTransactionDetails statuses, anything that isn't
+// authorized, captured or under review (declined, voided, expired, errors) is a failed payment.
+func mapTransactionStatusToPaymentStatus(status string) providers.PaymentStatus {
+	switch status {
+	case service.TransactionStatusAuthorizedPendingCapture, service.TransactionStatusCapturedPendingSettlement,
+		service.TransactionStatusSettledSuccessfully:
+		return providers.PaymentStatusSuccess
+	case service.TransactionStatusFDSPendingReview, service.TransactionStatusFDSAuthorizedPendingReview,
+		service.TransactionStatusUnderReview:
+		return providers.PaymentStatusPending
+	}
+
+	return providers.PaymentStatusFailed
 }
 
 func mapAuthorizeNetStatusToPaymentStatus(status string) providers.PaymentStatus {
@@ -128,8 +128,22 @@ func TestClient_CreatePayment(t *testing.T) {
 	client := NewClient(mockService)
 
 	ctx := context.Background()
-	req := entities.CreatePaymentTransactionRequest{
-		Amount: decimal.NewFromInt(1000),
+	req := providers.CreatePaymentRequest{
+		Amount:       decimal.NewFromInt(1000),
+		PaymentNonce: "nonce_123",
+		BillingInfo: providers.BillingInfo{
+			FirstName: "John",
+			LastName:  "Doe",
+			Address:   "123 Main St",
+			City:      "Anytown",
+			State:     "CA",
+			Country:   "US",
+			Zip:       "12345",
+		},
+	}
+	svcReq := entities.CreatePaymentTransactionRequest{
+		Amount:       decimal.NewFromInt(1000),
+		PaymentNonce: "nonce_123",
 		BillingInfo: entities.BillingInfo{
 			FirstName: "John",
 			LastName:  "Doe",
@@ -146,7 +160,7 @@ func TestClient_CreatePayment(t *testing.T) {
 			ID:     "txn_123",
 			Status: "approved",
 		}
-		mockService.EXPECT().CreatePaymentTransaction(ctx, req).Return(svcResp, nil)
+		mockService.EXPECT().CreatePaymentTransaction(ctx, svcReq).Return(svcResp, nil)
 
 		resp, err := client.CreatePayment(ctx, req)
 		assert.NoError(t, err)
@@ -161,7 +175,7 @@ func TestClient_CreatePayment(t *testing.T) {
 			ID:     "txn_123",
 			Status: "declined",
 		}
-		mockService.EXPECT().CreatePaymentTransaction(ctx, req).Return(svcResp, nil)
+		mockService.EXPECT().CreatePaymentTransaction(ctx, svcReq).Return(svcResp, nil)
 
 		resp, err := client.CreatePayment(ctx, req)
 		assert.NoError(t, err)
@@ -172,7 +186,7 @@ func TestClient_CreatePayment(t *testing.T) {
 	})
 
 	t.Run("Error", func(t *testing.T) {
-		mockService.EXPECT().CreatePaymentTransaction(ctx, req).Return(entities.CreatePaymentTransactionResponse{}, errors.New("service error"))
+		mockService.EXPECT().CreatePaymentTransaction(ctx, svcReq).Return(entities.CreatePaymentTransactionResponse{}, errors.New("service error"))
 
 		resp, err := client.CreatePayment(ctx, req)
 
@@ -181,11 +195,269 @@ func TestClient_CreatePayment(t *testing.T) {
 		assert.Equal(t, "service error", err.Error())
 	})
 
-	t.Run("Invalid request type", func(t *testing.T) {
-		resp, err := client.CreatePayment(ctx, "invalid type")
+	t.Run("Success: Payment profile", func(t *testing.T) {
+		customerProfileID := "profile_123"
+		mockService.EXPECT().CreatePaymentTransaction(ctx, entities.CreatePaymentTransactionRequest{
+			Amount:            decimal.NewFromInt(1000),
+			CustomerProfileID: customerProfileID,
+			PaymentProfileID:  "payprof_123",
+		}).Return(entities.CreatePaymentTransactionResponse{ID: "txn_123", Status: "approved"}, nil)
+
+		resp, err := client.CreatePayment(ctx, providers.CreatePaymentRequest{
+			Amount:          decimal.NewFromInt(1000),
+			CustomerID:      &customerProfileID,
+			PaymentMethodID: "payprof_123",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, providers.PaymentStatusSuccess, resp.Status)
+	})
+
+	t.Run("Error: Payment profile without customer profile", func(t *testing.T) {
+		_, err := client.CreatePayment(ctx, providers.CreatePaymentRequest{
+			Amount:          decimal.NewFromInt(1000),
+			PaymentMethodID: "payprof_123",
+		})
+
+		assert.EqualError(t, err, "authorize.net customer profile ID is required to charge a payment profile")
+	})
+}
+
+func TestClient_Refund(t *testing.T) {
+	ctx := context.Background()
+	detailsReq := entities.GetTransactionDetailsRequest{TransactionID: "txn_123"}
+
+	t.Run("Refunds settled transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		client := NewClient(mockService)
+
+		mockService.EXPECT().GetTransactionDetails(ctx, detailsReq).Return(entities.GetTransactionDetailsResponse{
+			ID:             "txn_123",
+			Status:         service.TransactionStatusSettledSuccessfully,
+			SettleAmount:   decimal.NewFromInt(100),
+			CardNumber:     "XXXX1111",
+			ExpirationDate: "XXXX",
+		}, nil)
+		mockService.EXPECT().RefundTransaction(ctx, entities.RefundTransactionRequest{
+			TransactionID:  "txn_123",
+			Amount:         decimal.NewFromInt(100),
+			CardNumber:     "XXXX1111",
+			ExpirationDate: "XXXX",
+		}).Return(entities.RefundTransactionResponse{ID: "txn_456", Status: service.AuthorizeNetStatusApproved}, nil)
+
+		resp, err := client.Refund(ctx, providers.RefundRequest{PaymentID: "txn_123"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &providers.RefundResponse{ID: "txn_456", Status: providers.RefundStatusPending}, resp)
+	})
+
+	t.Run("Voids unsettled transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		client := NewClient(mockService)
+
+		mockService.EXPECT().GetTransactionDetails(ctx, detailsReq).Return(entities.GetTransactionDetailsResponse{
+			ID:         "txn_123",
+			Status:     service.TransactionStatusCapturedPendingSettlement,
+			AuthAmount: decimal.NewFromInt(100),
+		}, nil)
+		mockService.EXPECT().VoidTransaction(ctx, entities.VoidTransactionRequest{TransactionID: "txn_123"}).
+			Return(entities.VoidTransactionResponse{ID: "txn_123", Status: service.AuthorizeNetStatusApproved}, nil)
+
+		resp, err := client.Refund(ctx, providers.RefundRequest{PaymentID: "txn_123", Amount: decimal.NewFromInt(100)})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &providers.RefundResponse{ID: "txn_123", Status: providers.RefundStatusPending}, resp)
+	})
+
+	t.Run("Error: Partial refund of unsettled transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		client := NewClient(mockService)
+
+		mockService.EXPECT().GetTransactionDetails(ctx, detailsReq).Return(entities.GetTransactionDetailsResponse{
+			ID:         "txn_123",
+			Status:     service.TransactionStatusCapturedPendingSettlement,
+			AuthAmount: decimal.NewFromInt(100),
+		}, nil)
+
+		resp, err := client.Refund(ctx, providers.RefundRequest{PaymentID: "txn_123", Amount: decimal.NewFromInt(40)})
+
+		assert.Nil(t, resp)
+		assert.EqualError(t, err, "partial refunds are only possible once the transaction has settled")
+	})
+
+	t.Run("Error: Transaction can't be refunded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		client := NewClient(mockService)
+
+		mockService.EXPECT().GetTransactionDetails(ctx, detailsReq).Return(entities.GetTransactionDetailsResponse{
+			ID:     "txn_123",
+			Status: "voided",
+		}, nil)
+
+		resp, err := client.Refund(ctx, providers.RefundRequest{PaymentID: "txn_123"})
+
+		assert.Nil(t, resp)
+		assert.EqualError(t, err, "transaction in status voided can't be refunded")
+	})
+
+	t.Run("Error: Missing transaction ID", func(t *testing.T) {
+		client := NewClient(service.NewMockService(gomock.NewController(t)))
+
+		resp, err := client.Refund(ctx, providers.RefundRequest{})
+
+		assert.Nil(t, resp)
+		assert.EqualError(t, err, "transaction ID is required for refund")
+	})
+}
+
+func TestClient_Capture(t *testing.T) {
+	ctx := context.Background()
+	detailsReq := entities.GetTransactionDetailsRequest{TransactionID: "txn_123"}
+	captureReq := providers.CaptureRequest{PaymentID: "txn_123", Amount: decimal.NewFromInt(40)}
+
+	t.Run("Captures authorized transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		client := NewClient(mockService)
+
+		mockService.EXPECT().GetTransactionDetails(ctx, detailsReq).Return(entities.GetTransactionDetailsResponse{
+			ID:         "txn_123",
+			Status:     service.TransactionStatusAuthorizedPendingCapture,
+			AuthAmount: decimal.NewFromInt(100),
+		}, nil)
+		mockService.EXPECT().CaptureTransaction(ctx, entities.CaptureTransactionRequest{TransactionID: "txn_123", Amount: decimal.NewFromInt(40)}).
+			Return(entities.CaptureTransactionResponse{ID: "txn_123", Status: service.AuthorizeNetStatusApproved}, nil)
+
+		resp, err := client.Capture(ctx, captureReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, providers.PaymentProviderResponse{ID: "txn_123", Status: providers.PaymentStatusSuccess}, resp)
+	})
+
+	t.Run("Transaction captured already", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		client := NewClient(mockService)
+
+		mockService.EXPECT().GetTransactionDetails(ctx, detailsReq).Return(entities.GetTransactionDetailsResponse{
+			ID:     "txn_123",
+			Status: service.TransactionStatusCapturedPendingSettlement,
+		}, nil)
+
+		resp, err := client.Capture(ctx, captureReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, providers.PaymentProviderResponse{ID: "txn_123", Status: providers.PaymentStatusSuccess}, resp)
+	})
+
+	t.Run("Error: Transaction can't be captured", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		client := NewClient(mockService)
+
+		mockService.EXPECT().GetTransactionDetails(ctx, detailsReq).Return(entities.GetTransactionDetailsResponse{
+			ID:     "txn_123",
+			Status: "voided",
+		}, nil)
+
+		resp, err := client.Capture(ctx, captureReq)
+
+		assert.Equal(t, providers.PaymentProviderResponse{}, resp)
+		assert.EqualError(t, err, "transaction in status voided can't be captured")
+	})
+
+	t.Run("Error: Missing transaction ID", func(t *testing.T) {
+		client := NewClient(service.NewMockService(gomock.NewController(t)))
+
+		resp, err := client.Capture(ctx, providers.CaptureRequest{})
 
-		assert.Error(t, err)
 		assert.Equal(t, providers.PaymentProviderResponse{}, resp)
-		assert.Equal(t, "invalid payment request type", err.Error())
+		assert.EqualError(t, err, "transaction ID is required for capture")
 	})
 }
+
+func TestClient_GetPayment(t *testing.T) {
+	ctx := context.Background()
+	detailsReq := entities.GetTransactionDetailsRequest{TransactionID: "txn_123"}
+
+	tests := []struct {
+		name     string
+		status   string
+		expected providers.PaymentStatus
+	}{
+		{"Authorized", service.TransactionStatusAuthorizedPendingCapture, providers.PaymentStatusSuccess},
+		{"Settled", service.TransactionStatusSettledSuccessfully, providers.PaymentStatusSuccess},
+		{"Under review", service.TransactionStatusFDSPendingReview, providers.PaymentStatusPending},
+		{"Declined", "declined", providers.PaymentStatusFailed},
+		{"Voided", "voided", providers.PaymentStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := service.NewMockService(ctrl)
+			client := NewClient(mockService)
+
+			mockService.EXPECT().GetTransactionDetails(ctx, detailsReq).Return(entities.GetTransactionDetailsResponse{
+				ID:     "txn_123",
+				Status: tt.status,
+			}, nil)
+
+			resp, err := client.GetPayment(ctx, "txn_123")
+
+			assert.NoError(t, err)
+			assert.Equal(t, providers.PaymentProviderResponse{ID: "txn_123", Status: tt.expected}, resp)
+		})
+	}
+
+	t.Run("Error: Transaction details unavailable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := service.NewMockService(ctrl)
+		client := NewClient(mockService)
+
+		mockService.EXPECT().GetTransactionDetails(ctx, detailsReq).
+			Return(entities.GetTransactionDetailsResponse{}, errors.New("transaction not found"))
+
+		resp, err := client.GetPayment(ctx, "txn_123")
+
+		assert.Equal(t, providers.PaymentProviderResponse{}, resp)
+		assert.EqualError(t, err, "transaction not found")
+	})
+}
+
+func TestClient_GetRefundDetails(t *testing.T) {
+	ctx := context.Background()
+	detailsReq := entities.GetTransactionDetailsRequest{TransactionID: "txn_456"}
+
+	tests := []struct {
+		name     string
+		details  entities.GetTransactionDetailsResponse
+		expected string
+	}{
+		{"Refund settled", entities.GetTransactionDetailsResponse{Type: service.TransactionTypeRefund, Status: service.TransactionStatusRefundSettledSuccessfully}, providers.RefundStatusSucceeded},
+		{"Refund pending settlement", entities.GetTransactionDetailsResponse{Type: service.TransactionTypeRefund, Status: service.TransactionStatusRefundPendingSettlement}, providers.RefundStatusPending},
+		{"Refund voided", entities.GetTransactionDetailsResponse{Type: service.TransactionTypeRefund, Status: "voided"}, providers.RefundStatusFailed},
+		{"Payment voided before settlement", entities.GetTransactionDetailsResponse{Type: "authCaptureTransaction", Status: "voided"}, providers.RefundStatusSucceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := service.NewMockService(ctrl)
+			client := NewClient(mockService)
+
+			tt.details.ID = "txn_456"
+			tt.details.AuthAmount = decimal.NewFromInt(40)
+			mockService.EXPECT().GetTransactionDetails(ctx, detailsReq).Return(tt.details, nil)
+
+			resp, err := client.GetRefundDetails(ctx, "txn_456")
+
+			assert.NoError(t, err)
+			assert.Equal(t, &providers.RefundDetails{ID: "txn_456", Status: tt.expected, Amount: decimal.NewFromInt(40)}, resp)
+		})
+	}
+}
@@ -36,6 +36,21 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// Capture mocks base method.
+func (m *MockClient) Capture(ctx context.Context, req providers.CaptureRequest) (providers.PaymentProviderResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capture", ctx, req)
+	ret0, _ := ret[0].(providers.PaymentProviderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Capture indicates an expected call of Capture.
+func (mr *MockClientMockRecorder) Capture(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockClient)(nil).Capture), ctx, req)
+}
+
 // CreateCustomer mocks base method.
 func (m *MockClient) CreateCustomer(ctx context.Context, req entities.CreateCustomerRequest) (entities.CreateCustomerResponse, error) {
 	m.ctrl.T.Helper()
@@ -67,7 +82,7 @@ func (mr *MockClientMockRecorder) CreateCustomerPaymentProfile(ctx, req interfac
 }
 
 // CreatePayment mocks base method.
-func (m *MockClient) CreatePayment(ctx context.Context, req any) (providers.PaymentProviderResponse, error) {
+func (m *MockClient) CreatePayment(ctx context.Context, req providers.CreatePaymentRequest) (providers.PaymentProviderResponse, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreatePayment", ctx, req)
 	ret0, _ := ret[0].(providers.PaymentProviderResponse)
@@ -81,6 +96,20 @@ func (mr *MockClientMockRecorder) CreatePayment(ctx, req interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayment", reflect.TypeOf((*MockClient)(nil).CreatePayment), ctx, req)
 }
 
+// DeleteCustomerPaymentProfile mocks base method.
+func (m *MockClient) DeleteCustomerPaymentProfile(ctx context.Context, req entities.DeleteCustomerPaymentProfileRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCustomerPaymentProfile", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCustomerPaymentProfile indicates an expected call of DeleteCustomerPaymentProfile.
+func (mr *MockClientMockRecorder) DeleteCustomerPaymentProfile(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCustomerPaymentProfile", reflect.TypeOf((*MockClient)(nil).DeleteCustomerPaymentProfile), ctx, req)
+}
+
 // GetCustomerPaymentMethods mocks base method.
 func (m *MockClient) GetCustomerPaymentMethods(ctx context.Context, req entities.GetPaymentProfilesRequest) (entities.GetPaymentProfilesResponse, error) {
 	m.ctrl.T.Helper()
@@ -96,6 +125,36 @@ func (mr *MockClientMockRecorder) GetCustomerPaymentMethods(ctx, req interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerPaymentMethods", reflect.TypeOf((*MockClient)(nil).GetCustomerPaymentMethods), ctx, req)
 }
 
+// GetPayment mocks base method.
+func (m *MockClient) GetPayment(ctx context.Context, paymentID string) (providers.PaymentProviderResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPayment", ctx, paymentID)
+	ret0, _ := ret[0].(providers.PaymentProviderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPayment indicates an expected call of GetPayment.
+func (mr *MockClientMockRecorder) GetPayment(ctx, paymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPayment", reflect.TypeOf((*MockClient)(nil).GetPayment), ctx, paymentID)
+}
+
+// GetPaymentDetails mocks base method.
+func (m *MockClient) GetPaymentDetails(ctx context.Context, paymentID string) (*providers.PaymentDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentDetails", ctx, paymentID)
+	ret0, _ := ret[0].(*providers.PaymentDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentDetails indicates an expected call of GetPaymentDetails.
+func (mr *MockClientMockRecorder) GetPaymentDetails(ctx, paymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentDetails", reflect.TypeOf((*MockClient)(nil).GetPaymentDetails), ctx, paymentID)
+}
+
 // GetProvider mocks base method.
 func (m *MockClient) GetProvider() providers.ProviderType {
 	m.ctrl.T.Helper()
@@ -110,8 +169,23 @@ func (mr *MockClientMockRecorder) GetProvider() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProvider", reflect.TypeOf((*MockClient)(nil).GetProvider))
 }
 
+// GetRefundDetails mocks base method.
+func (m *MockClient) GetRefundDetails(ctx context.Context, refundID string) (*providers.RefundDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundDetails", ctx, refundID)
+	ret0, _ := ret[0].(*providers.RefundDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundDetails indicates an expected call of GetRefundDetails.
+func (mr *MockClientMockRecorder) GetRefundDetails(ctx, refundID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundDetails", reflect.TypeOf((*MockClient)(nil).GetRefundDetails), ctx, refundID)
+}
+
 // Refund mocks base method.
-func (m *MockClient) Refund(ctx context.Context, req any) (*providers.RefundResponse, error) {
+func (m *MockClient) Refund(ctx context.Context, req providers.RefundRequest) (*providers.RefundResponse, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Refund", ctx, req)
 	ret0, _ := ret[0].(*providers.RefundResponse)
@@ -124,3 +198,31 @@ func (mr *MockClientMockRecorder) Refund(ctx, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockClient)(nil).Refund), ctx, req)
 }
+
+// UpdateCustomerPaymentProfile mocks base method.
+func (m *MockClient) UpdateCustomerPaymentProfile(ctx context.Context, req entities.UpdateCustomerPaymentProfileRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCustomerPaymentProfile", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCustomerPaymentProfile indicates an expected call of UpdateCustomerPaymentProfile.
+func (mr *MockClientMockRecorder) UpdateCustomerPaymentProfile(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCustomerPaymentProfile", reflect.TypeOf((*MockClient)(nil).UpdateCustomerPaymentProfile), ctx, req)
+}
+
+// Void mocks base method.
+func (m *MockClient) Void(ctx context.Context, req providers.VoidRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Void", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Void indicates an expected call of Void.
+func (mr *MockClientMockRecorder) Void(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*MockClient)(nil).Void), ctx, req)
+}
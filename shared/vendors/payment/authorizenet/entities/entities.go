@@ -36,12 +36,33 @@ type PaymentProfile struct {
 	CardNumber     string
 	CardType       string
 	ExpirationDate string
+	Default        bool
+}
+
+// UpdateCustomerPaymentProfileRequest replaces the payment profile, the masked card number and
+// expiration date returned by GetCustomerPaymentProfiles keep the card as it is
+type UpdateCustomerPaymentProfileRequest struct {
+	ProfileID        string
+	PaymentProfileID string
+	CardNumber       string
+	ExpirationDate   string
+	Default          bool
+}
+
+type DeleteCustomerPaymentProfileRequest struct {
+	ProfileID        string
+	PaymentProfileID string
 }
 
 type CreatePaymentTransactionRequest struct {
 	Amount       decimal.Decimal
 	PaymentNonce string
-	BillingInfo  BillingInfo
+	// CustomerProfileID and PaymentProfileID charge a stored payment profile instead of the payment nonce
+	CustomerProfileID string
+	PaymentProfileID  string
+	BillingInfo       BillingInfo
+	// AuthorizeOnly places a hold on the amount, it has to be captured with CaptureTransaction
+	AuthorizeOnly bool
 }
 
 type BillingInfo struct {
@@ -59,5 +80,52 @@ type CreatePaymentTransactionResponse struct {
 	Status string
 }
 
+type GetTransactionDetailsRequest struct {
+	TransactionID string
+}
+
+type GetTransactionDetailsResponse struct {
+	ID             string
+	Status         string
+	Type           string
+	AuthAmount     decimal.Decimal
+	SettleAmount   decimal.Decimal
+	CardNumber     string
+	ExpirationDate string
+}
+
+type RefundTransactionRequest struct {
+	TransactionID string
+	Amount        decimal.Decimal
+	// CardNumber holds the last four digits of the card used for the original transaction
+	CardNumber     string
+	ExpirationDate string
+}
+
+type RefundTransactionResponse struct {
+	ID     string
+	Status string
+}
+
+// CaptureTransactionRequest captures an authorized transaction, a zero amount captures the whole authorized amount
+type CaptureTransactionRequest struct {
+	TransactionID string
+	Amount        decimal.Decimal
+}
+
+type CaptureTransactionResponse struct {
+	ID     string
+	Status string
+}
+
+type VoidTransactionRequest struct {
+	TransactionID string
+}
+
+type VoidTransactionResponse struct {
+	ID     string
+	Status string
+}
+
 type HandleWebhookEventRequest struct{}
 type HandleWebhookEventResponse struct{}
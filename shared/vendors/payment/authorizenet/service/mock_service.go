@@ -35,6 +35,21 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// CaptureTransaction mocks base method.
+func (m *MockService) CaptureTransaction(ctx context.Context, req entities.CaptureTransactionRequest) (entities.CaptureTransactionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureTransaction", ctx, req)
+	ret0, _ := ret[0].(entities.CaptureTransactionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureTransaction indicates an expected call of CaptureTransaction.
+func (mr *MockServiceMockRecorder) CaptureTransaction(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureTransaction", reflect.TypeOf((*MockService)(nil).CaptureTransaction), ctx, req)
+}
+
 // CreateCustomerPaymentProfile mocks base method.
 func (m *MockService) CreateCustomerPaymentProfile(ctx context.Context, req entities.CreateCustomerPaymentProfileRequest) (entities.CreateCustomerPaymentProfileResponse, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +95,20 @@ func (mr *MockServiceMockRecorder) CreatePaymentTransaction(ctx, req interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentTransaction", reflect.TypeOf((*MockService)(nil).CreatePaymentTransaction), ctx, req)
 }
 
+// DeleteCustomerPaymentProfile mocks base method.
+func (m *MockService) DeleteCustomerPaymentProfile(ctx context.Context, req entities.DeleteCustomerPaymentProfileRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCustomerPaymentProfile", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCustomerPaymentProfile indicates an expected call of DeleteCustomerPaymentProfile.
+func (mr *MockServiceMockRecorder) DeleteCustomerPaymentProfile(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCustomerPaymentProfile", reflect.TypeOf((*MockService)(nil).DeleteCustomerPaymentProfile), ctx, req)
+}
+
 // GetCustomerPaymentProfiles mocks base method.
 func (m *MockService) GetCustomerPaymentProfiles(ctx context.Context, req entities.GetPaymentProfilesRequest) (entities.GetPaymentProfilesResponse, error) {
 	m.ctrl.T.Helper()
@@ -94,3 +123,62 @@ func (mr *MockServiceMockRecorder) GetCustomerPaymentProfiles(ctx, req interface
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerPaymentProfiles", reflect.TypeOf((*MockService)(nil).GetCustomerPaymentProfiles), ctx, req)
 }
+
+// GetTransactionDetails mocks base method.
+func (m *MockService) GetTransactionDetails(ctx context.Context, req entities.GetTransactionDetailsRequest) (entities.GetTransactionDetailsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactionDetails", ctx, req)
+	ret0, _ := ret[0].(entities.GetTransactionDetailsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactionDetails indicates an expected call of GetTransactionDetails.
+func (mr *MockServiceMockRecorder) GetTransactionDetails(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionDetails", reflect.TypeOf((*MockService)(nil).GetTransactionDetails), ctx, req)
+}
+
+// RefundTransaction mocks base method.
+func (m *MockService) RefundTransaction(ctx context.Context, req entities.RefundTransactionRequest) (entities.RefundTransactionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundTransaction", ctx, req)
+	ret0, _ := ret[0].(entities.RefundTransactionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefundTransaction indicates an expected call of RefundTransaction.
+func (mr *MockServiceMockRecorder) RefundTransaction(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundTransaction", reflect.TypeOf((*MockService)(nil).RefundTransaction), ctx, req)
+}
+
+// UpdateCustomerPaymentProfile mocks base method.
+func (m *MockService) UpdateCustomerPaymentProfile(ctx context.Context, req entities.UpdateCustomerPaymentProfileRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCustomerPaymentProfile", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCustomerPaymentProfile indicates an expected call of UpdateCustomerPaymentProfile.
+func (mr *MockServiceMockRecorder) UpdateCustomerPaymentProfile(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCustomerPaymentProfile", reflect.TypeOf((*MockService)(nil).UpdateCustomerPaymentProfile), ctx, req)
+}
+
+// VoidTransaction mocks base method.
+func (m *MockService) VoidTransaction(ctx context.Context, req entities.VoidTransactionRequest) (entities.VoidTransactionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidTransaction", ctx, req)
+	ret0, _ := ret[0].(entities.VoidTransactionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoidTransaction indicates an expected call of VoidTransaction.
+func (mr *MockServiceMockRecorder) VoidTransaction(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidTransaction", reflect.TypeOf((*MockService)(nil).VoidTransaction), ctx, req)
+}
@@ -36,6 +36,32 @@ type CreditCard struct {
 	ExpirationDate string `json:"expirationDate"`
 }
 
+type UpdateCustomerPaymentProfileRequest struct {
+	Data UpdateCustomerPaymentProfileRequestData `json:"updateCustomerPaymentProfileRequest"`
+}
+
+type UpdateCustomerPaymentProfileRequestData struct {
+	MerchantAuthentication merchantAuthentication `json:"merchantAuthentication"`
+	CustomerProfileID      string                 `json:"customerProfileId"`
+	PaymentProfile         UpdatePaymentProfile   `json:"paymentProfile"`
+}
+
+type UpdatePaymentProfile struct {
+	Payment                  Payment `json:"payment"`
+	DefaultPaymentProfile    bool    `json:"defaultPaymentProfile"`
+	CustomerPaymentProfileID string  `json:"customerPaymentProfileId"`
+}
+
+type DeleteCustomerPaymentProfileRequest struct {
+	Data DeleteCustomerPaymentProfileRequestData `json:"deleteCustomerPaymentProfileRequest"`
+}
+
+type DeleteCustomerPaymentProfileRequestData struct {
+	MerchantAuthentication   merchantAuthentication `json:"merchantAuthentication"`
+	CustomerProfileID        string                 `json:"customerProfileId"`
+	CustomerPaymentProfileID string                 `json:"customerPaymentProfileId"`
+}
+
 type CreateCustomerProfileRequest struct {
 	Data CreateCustomerProfileRequestData `json:"createCustomerProfileRequest"`
 }
@@ -64,11 +90,50 @@ type TransactionRequestData struct {
 	TransactionRequest     TransactionRequest     `json:"transactionRequest"`
 }
 
+// TransactionRequest charges either the payment nonce or a payment profile of the customer.
+// Authorize.net validates the JSON against its XML schema, so the field order matters.
 type TransactionRequest struct {
-	TransactionType string       `json:"transactionType"`
-	Amount          string       `json:"amount"`
-	Payment         PaymentNonce `json:"payment"`
-	BillTo          BillTo       `json:"billTo,omitzero"`
+	TransactionType string          `json:"transactionType"`
+	Amount          string          `json:"amount"`
+	Payment         *PaymentNonce   `json:"payment,omitempty"`
+	Profile         *ProfilePayment `json:"profile,omitempty"`
+	BillTo          BillTo          `json:"billTo,omitzero"`
+}
+
+type ProfilePayment struct {
+	CustomerProfileID string                  `json:"customerProfileId"`
+	PaymentProfile    PaymentProfileReference `json:"paymentProfile"`
+}
+
+type PaymentProfileReference struct {
+	PaymentProfileID string `json:"paymentProfileId"`
+}
+
+// ReferenceTransactionRequest is used for transactions on top of an existing one (capture, refund, void).
+// Authorize.net validates the JSON against its XML schema, so the field order matters.
+type ReferenceTransactionRequest struct {
+	Data ReferenceTransactionRequestData `json:"createTransactionRequest"`
+}
+
+type ReferenceTransactionRequestData struct {
+	MerchantAuthentication merchantAuthentication      `json:"merchantAuthentication"`
+	TransactionRequest     ReferenceTransactionDetails `json:"transactionRequest"`
+}
+
+type ReferenceTransactionDetails struct {
+	TransactionType string   `json:"transactionType"`
+	Amount          string   `json:"amount,omitempty"`
+	Payment         *Payment `json:"payment,omitempty"`
+	RefTransID      string   `json:"refTransId"`
+}
+
+type GetTransactionDetailsRequest struct {
+	Data GetTransactionDetailsRequestData `json:"getTransactionDetailsRequest"`
+}
+
+type GetTransactionDetailsRequestData struct {
+	MerchantAuthentication merchantAuthentication `json:"merchantAuthentication"`
+	TransID                string                 `json:"transId"`
 }
 
 type PaymentNonce struct {
@@ -1,5 +1,7 @@
 package service
 
+import "github.com/shopspring/decimal"
+
 // BaseResponse represents the common response structure from Authorize.net
 type BaseResponse struct {
 	Messages Messages `json:"messages"`
@@ -37,6 +39,7 @@ type ProfileResponse struct {
 
 type PaymentProfileResponse struct {
 	CustomerPaymentProfileID string          `json:"customerPaymentProfileId"`
+	DefaultPaymentProfile    bool            `json:"defaultPaymentProfile"`
 	Payment                  PaymentResponse `json:"payment"`
 }
 
@@ -68,3 +71,17 @@ type Error struct {
 	ErrorCode string `json:"errorCode"`
 	ErrorText string `json:"errorText"`
 }
+
+type GetTransactionDetailsResponse struct {
+	BaseResponse
+	Transaction TransactionDetails `json:"transaction"`
+}
+
+type TransactionDetails struct {
+	TransID           string          `json:"transId"`
+	TransactionStatus string          `json:"transactionStatus"`
+	TransactionType   string          `json:"transactionType"`
+	AuthAmount        decimal.Decimal `json:"authAmount"`
+	SettleAmount      decimal.Decimal `json:"settleAmount"`
+	Payment           PaymentResponse `json:"payment"`
+}
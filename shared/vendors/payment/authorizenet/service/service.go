@@ -24,11 +24,33 @@ const (
 	AuthorizeNetStatusUnknown       = "unknown"
 )
 
+// Transaction statuses reported by getTransactionDetails
+const (
+	TransactionStatusAuthorizedPendingCapture   = "authorizedPendingCapture"
+	TransactionStatusCapturedPendingSettlement  = "capturedPendingSettlement"
+	TransactionStatusSettledSuccessfully        = "settledSuccessfully"
+	TransactionStatusFDSPendingReview           = "FDSPendingReview"
+	TransactionStatusFDSAuthorizedPendingReview = "FDSAuthorizedPendingReview"
+	TransactionStatusUnderReview                = "underReview"
+	TransactionStatusRefundSettledSuccessfully  = "refundSettledSuccessfully"
+	TransactionStatusRefundPendingSettlement    = "refundPendingSettlement"
+	TransactionStatusVoided                     = "voided"
+)
+
+// TransactionTypeRefund is the type of the transactions created by RefundTransaction
+const TransactionTypeRefund = "refundTransaction"
+
 type Service interface {
 	CreateCustomerProfile(ctx context.Context, req entities.CreateCustomerRequest) (entities.CreateCustomerResponse, error)
 	CreateCustomerPaymentProfile(ctx context.Context, req entities.CreateCustomerPaymentProfileRequest) (entities.CreateCustomerPaymentProfileResponse, error)
 	GetCustomerPaymentProfiles(ctx context.Context, req entities.GetPaymentProfilesRequest) (entities.GetPaymentProfilesResponse, error)
+	UpdateCustomerPaymentProfile(ctx context.Context, req entities.UpdateCustomerPaymentProfileRequest) error
+	DeleteCustomerPaymentProfile(ctx context.Context, req entities.DeleteCustomerPaymentProfileRequest) error
 	CreatePaymentTransaction(ctx context.Context, req entities.CreatePaymentTransactionRequest) (entities.CreatePaymentTransactionResponse, error)
+	GetTransactionDetails(ctx context.Context, req entities.GetTransactionDetailsRequest) (entities.GetTransactionDetailsResponse, error)
+	CaptureTransaction(ctx context.Context, req entities.CaptureTransactionRequest) (entities.CaptureTransactionResponse, error)
+	RefundTransaction(ctx context.Context, req entities.RefundTransactionRequest) (entities.RefundTransactionResponse, error)
+	VoidTransaction(ctx context.Context, req entities.VoidTransactionRequest) (entities.VoidTransactionResponse, error)
 }
 
 func New(cfg config.Config, logger *zap.SugaredLogger) Service {
@@ -174,6 +196,7 @@ func (s *service) GetCustomerPaymentProfiles(ctx context.Context, req entities.G
 			CardNumber:     profile.Payment.CreditCard.CardNumber,
 			CardType:       profile.Payment.CreditCard.CardType,
 			ExpirationDate: profile.Payment.CreditCard.ExpirationDate,
+			Default:        profile.DefaultPaymentProfile,
 		})
 	}
 
@@ -182,9 +205,108 @@ func (s *service) GetCustomerPaymentProfiles(ctx context.Context, req entities.G
 	}, nil
 }
 
+func (s *service) UpdateCustomerPaymentProfile(ctx context.Context, req entities.UpdateCustomerPaymentProfileRequest) error {
+	s.logger.Infof("Updating customer payment profile: profileID=%s paymentProfileID=%s", req.ProfileID, req.PaymentProfileID)
+	requestData := UpdateCustomerPaymentProfileRequest{
+		Data: UpdateCustomerPaymentProfileRequestData{
+			MerchantAuthentication: merchantAuthentication{
+				Name:           s.apiLoginID,
+				TransactionKey: s.transactionKey,
+			},
+			CustomerProfileID: req.ProfileID,
+			PaymentProfile: UpdatePaymentProfile{
+				Payment: Payment{
+					CreditCard: CreditCard{
+						CardNumber:     req.CardNumber,
+						ExpirationDate: req.ExpirationDate,
+					},
+				},
+				DefaultPaymentProfile:    req.Default,
+				CustomerPaymentProfileID: req.PaymentProfileID,
+			},
+		},
+	}
+
+	var response BaseResponse
+	if err := s.sendRequest(ctx, requestData, &response); err != nil {
+		s.logger.Errorf("Failed to update customer payment profile (sendRequest): %v", err)
+		return fmt.Errorf("failed to update customer payment profile: %w", err)
+	}
+
+	if err := checkResponseForErrors(response.Messages); err != nil {
+		s.logger.Errorf("authorize.net API error when updating customer payment profile: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) DeleteCustomerPaymentProfile(ctx context.Context, req entities.DeleteCustomerPaymentProfileRequest) error {
+	s.logger.Infof("Deleting customer payment profile: profileID=%s paymentProfileID=%s", req.ProfileID, req.PaymentProfileID)
+	requestData := DeleteCustomerPaymentProfileRequest{
+		Data: DeleteCustomerPaymentProfileRequestData{
+			MerchantAuthentication: merchantAuthentication{
+				Name:           s.apiLoginID,
+				TransactionKey: s.transactionKey,
+			},
+			CustomerProfileID:        req.ProfileID,
+			CustomerPaymentProfileID: req.PaymentProfileID,
+		},
+	}
+
+	var response BaseResponse
+	if err := s.sendRequest(ctx, requestData, &response); err != nil {
+		s.logger.Errorf("Failed to delete customer payment profile (sendRequest): %v", err)
+		return fmt.Errorf("failed to delete customer payment profile: %w", err)
+	}
+
+	if err := checkResponseForErrors(response.Messages); err != nil {
+		s.logger.Errorf("authorize.net API error when deleting customer payment profile: %v", err)
+		return err
+	}
+
+	return nil
+}
+
 func (s *service) CreatePaymentTransaction(ctx context.Context, req entities.CreatePaymentTransactionRequest) (entities.CreatePaymentTransactionResponse, error) {
 	amount := req.Amount.StringFixed(2)
-	s.logger.Infof("Creating payment transaction: amount=%s", amount)
+	s.logger.Infof("Creating payment transaction: amount=%s authorizeOnly=%t paymentProfileID=%s", amount, req.AuthorizeOnly, req.PaymentProfileID)
+
+	transactionType := "authCaptureTransaction"
+	if req.AuthorizeOnly {
+		transactionType = "authOnlyTransaction"
+	}
+
+	transactionRequest := TransactionRequest{
+		TransactionType: transactionType,
+		Amount:          amount,
+	}
+
+	// payment profiles carry their own billing address
+	if req.PaymentProfileID != "" {
+		transactionRequest.Profile = &ProfilePayment{
+			CustomerProfileID: req.CustomerProfileID,
+			PaymentProfile: PaymentProfileReference{
+				PaymentProfileID: req.PaymentProfileID,
+			},
+		}
+	} else {
+		transactionRequest.Payment = &PaymentNonce{
+			OpaqueData: OpaqueData{
+				DataDescriptor: "COMMON.ACCEPT.INAPP.PAYMENT",
+				DataValue:      req.PaymentNonce,
+			},
+		}
+		transactionRequest.BillTo = BillTo{
+			FirstName: req.BillingInfo.FirstName,
+			LastName:  req.BillingInfo.LastName,
+			Address:   req.BillingInfo.Address,
+			City:      req.BillingInfo.City,
+			State:     req.BillingInfo.State,
+			Country:   req.BillingInfo.Country,
+			Zip:       req.BillingInfo.Zip,
+		}
+	}
 
 	requestData := CreateTransactionRequest{
 		Data: TransactionRequestData{
@@ -192,25 +314,7 @@ func (s *service) CreatePaymentTransaction(ctx context.Context, req entities.Cre
 				Name:           s.apiLoginID,
 				TransactionKey: s.transactionKey,
 			},
-			TransactionRequest: TransactionRequest{
-				TransactionType: "authCaptureTransaction",
-				Amount:          amount,
-				Payment: PaymentNonce{
-					OpaqueData: OpaqueData{
-						DataDescriptor: "COMMON.ACCEPT.INAPP.PAYMENT",
-						DataValue:      req.PaymentNonce,
-					},
-				},
-				BillTo: BillTo{
-					FirstName: req.BillingInfo.FirstName,
-					LastName:  req.BillingInfo.LastName,
-					Address:   req.BillingInfo.Address,
-					City:      req.BillingInfo.City,
-					State:     req.BillingInfo.State,
-					Country:   req.BillingInfo.Country,
-					Zip:       req.BillingInfo.Zip,
-				},
-			},
+			TransactionRequest: transactionRequest,
 		},
 	}
 
@@ -232,6 +336,163 @@ func (s *service) CreatePaymentTransaction(ctx context.Context, req entities.Cre
 	}, nil
 }
 
+func (s *service) GetTransactionDetails(ctx context.Context, req entities.GetTransactionDetailsRequest) (entities.GetTransactionDetailsResponse, error) {
+	s.logger.Infof("Getting transaction details: transactionID=%s", req.TransactionID)
+
+	requestData := GetTransactionDetailsRequest{
+		Data: GetTransactionDetailsRequestData{
+			MerchantAuthentication: merchantAuthentication{
+				Name:           s.apiLoginID,
+				TransactionKey: s.transactionKey,
+			},
+			TransID: req.TransactionID,
+		},
+	}
+
+	var response GetTransactionDetailsResponse
+	if err := s.sendRequest(ctx, requestData, &response); err != nil {
+		s.logger.Errorf("Failed to get transaction details (sendRequest): %v", err)
+		return entities.GetTransactionDetailsResponse{}, fmt.Errorf("failed to get transaction details: %w", err)
+	}
+
+	if err := checkResponseForErrors(response.Messages); err != nil {
+		s.logger.Errorf("authorize.net API error when getting transaction details: %v", err)
+		return entities.GetTransactionDetailsResponse{}, err
+	}
+
+	return entities.GetTransactionDetailsResponse{
+		ID:             response.Transaction.TransID,
+		Status:         response.Transaction.TransactionStatus,
+		Type:           response.Transaction.TransactionType,
+		AuthAmount:     response.Transaction.AuthAmount,
+		SettleAmount:   response.Transaction.SettleAmount,
+		CardNumber:     response.Transaction.Payment.CreditCard.CardNumber,
+		ExpirationDate: response.Transaction.Payment.CreditCard.ExpirationDate,
+	}, nil
+}
+
+func (s *service) CaptureTransaction(ctx context.Context, req entities.CaptureTransactionRequest) (entities.CaptureTransactionResponse, error) {
+	var amount string
+	if !req.Amount.IsZero() {
+		amount = req.Amount.StringFixed(2)
+	}
+	s.logger.Infof("Capturing transaction: transactionID=%s amount=%s", req.TransactionID, amount)
+
+	requestData := ReferenceTransactionRequest{
+		Data: ReferenceTransactionRequestData{
+			MerchantAuthentication: merchantAuthentication{
+				Name:           s.apiLoginID,
+				TransactionKey: s.transactionKey,
+			},
+			TransactionRequest: ReferenceTransactionDetails{
+				TransactionType: "priorAuthCaptureTransaction",
+				Amount:          amount,
+				RefTransID:      req.TransactionID,
+			},
+		},
+	}
+
+	response, err := s.createReferenceTransaction(ctx, requestData)
+	if err != nil {
+		s.logger.Errorf("Failed to capture transaction %s: %v", req.TransactionID, err)
+		return entities.CaptureTransactionResponse{}, err
+	}
+
+	return entities.CaptureTransactionResponse{
+		ID:     response.TransID,
+		Status: mapResponseCodeToStatus(response.ResponseCode),
+	}, nil
+}
+
+func (s *service) RefundTransaction(ctx context.Context, req entities.RefundTransactionRequest) (entities.RefundTransactionResponse, error) {
+	amount := req.Amount.StringFixed(2)
+	s.logger.Infof("Refunding transaction: transactionID=%s amount=%s", req.TransactionID, amount)
+
+	requestData := ReferenceTransactionRequest{
+		Data: ReferenceTransactionRequestData{
+			MerchantAuthentication: merchantAuthentication{
+				Name:           s.apiLoginID,
+				TransactionKey: s.transactionKey,
+			},
+			TransactionRequest: ReferenceTransactionDetails{
+				TransactionType: "refundTransaction",
+				Amount:          amount,
+				Payment: &Payment{
+					CreditCard: CreditCard{
+						// only the last four digits are expected, masked numbers come prefixed with X
+						CardNumber:     strings.TrimLeft(req.CardNumber, "X"),
+						ExpirationDate: req.ExpirationDate,
+					},
+				},
+				RefTransID: req.TransactionID,
+			},
+		},
+	}
+
+	response, err := s.createReferenceTransaction(ctx, requestData)
+	if err != nil {
+		s.logger.Errorf("Failed to refund transaction %s: %v", req.TransactionID, err)
+		return entities.RefundTransactionResponse{}, err
+	}
+
+	return entities.RefundTransactionResponse{
+		ID:     response.TransID,
+		Status: mapResponseCodeToStatus(response.ResponseCode),
+	}, nil
+}
+
+func (s *service) VoidTransaction(ctx context.Context, req entities.VoidTransactionRequest) (entities.VoidTransactionResponse, error) {
+	s.logger.Infof("Voiding transaction: transactionID=%s", req.TransactionID)
+
+	requestData := ReferenceTransactionRequest{
+		Data: ReferenceTransactionRequestData{
+			MerchantAuthentication: merchantAuthentication{
+				Name:           s.apiLoginID,
+				TransactionKey: s.transactionKey,
+			},
+			TransactionRequest: ReferenceTransactionDetails{
+				TransactionType: "voidTransaction",
+				RefTransID:      req.TransactionID,
+			},
+		},
+	}
+
+	response, err := s.createReferenceTransaction(ctx, requestData)
+	if err != nil {
+		s.logger.Errorf("Failed to void transaction %s: %v", req.TransactionID, err)
+		return entities.VoidTransactionResponse{}, err
+	}
+
+	return entities.VoidTransactionResponse{
+		ID:     response.TransID,
+		Status: mapResponseCodeToStatus(response.ResponseCode),
+	}, nil
+}
+
+// createReferenceTransaction sends a transaction referencing an existing one and fails unless it was approved.
+func (s *service) createReferenceTransaction(ctx context.Context, requestData ReferenceTransactionRequest) (TransactionResponse, error) {
+	var response CreateTransactionResponse
+	if err := s.sendRequest(ctx, requestData, &response); err != nil {
+		return TransactionResponse{}, fmt.Errorf("failed to create %s: %w", requestData.Data.TransactionRequest.TransactionType, err)
+	}
+
+	// transaction errors are more specific than the generic API message
+	if len(response.TransactionResponse.Errors) > 0 {
+		txErr := response.TransactionResponse.Errors[0]
+		return TransactionResponse{}, fmt.Errorf("authorize.net transaction error: %s - %s", txErr.ErrorCode, txErr.ErrorText)
+	}
+
+	if err := checkResponseForErrors(response.Messages); err != nil {
+		return TransactionResponse{}, err
+	}
+
+	if status := mapResponseCodeToStatus(response.TransactionResponse.ResponseCode); status != AuthorizeNetStatusApproved {
+		return TransactionResponse{}, fmt.Errorf("authorize.net %s was not approved: %s", requestData.Data.TransactionRequest.TransactionType, status)
+	}
+
+	return response.TransactionResponse, nil
+}
+
 func mapResponseCodeToStatus(responseCode string) string {
 	switch responseCode {
 	case "1":
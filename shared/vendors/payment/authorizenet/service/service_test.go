@@ -347,6 +347,7 @@ func TestGetCustomerPaymentProfiles(t *testing.T) {
 		assert.Equal(t, "XXXX8888", res.PaymentProfiles[0].CardNumber)
 		assert.Equal(t, "Visa", res.PaymentProfiles[0].CardType)
 		assert.Equal(t, "2025-12", res.PaymentProfiles[0].ExpirationDate)
+		assert.True(t, res.PaymentProfiles[0].Default)
 	})
 
 	t.Run("No Payment Profiles", func(t *testing.T) {
@@ -523,6 +524,58 @@ func TestCreatePaymentTransaction(t *testing.T) {
 		assert.Equal(t, AuthorizeNetStatusApproved, res.Status)
 	})
 
+	t.Run("Success: Payment profile", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var requestBody CreateTransactionRequest
+			err := json.NewDecoder(r.Body).Decode(&requestBody)
+			assert.NoError(t, err)
+
+			transactionRequest := requestBody.Data.TransactionRequest
+			assert.Nil(t, transactionRequest.Payment)
+			assert.Equal(t, &ProfilePayment{
+				CustomerProfileID: "523520086",
+				PaymentProfile:    PaymentProfileReference{PaymentProfileID: "535672235"},
+			}, transactionRequest.Profile)
+			assert.Equal(t, BillTo{}, transactionRequest.BillTo)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write(fmt.Appendf(nil, `{
+				"transactionResponse": {
+					"responseCode": "1",
+					"transId": "%s"
+				},
+				"messages": {
+					"resultCode": "Ok",
+					"message": [{
+						"code": "I00001",
+						"text": "Successful."
+					}]
+				}
+			}`, expectedID))
+		}))
+		defer server.Close()
+
+		svc := &service{
+			apiLoginID:     apiLoginID,
+			transactionKey: transactionKey,
+			endpoint:       server.URL,
+			httpClient:     &http.Client{},
+			logger:         zap.NewExample().Sugar(),
+		}
+
+		res, err := svc.CreatePaymentTransaction(ctx, entities.CreatePaymentTransactionRequest{
+			Amount:            decimal.NewFromInt(100),
+			CustomerProfileID: "523520086",
+			PaymentProfileID:  "535672235",
+			BillingInfo:       entities.BillingInfo{FirstName: "John"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedID, res.ID)
+		assert.Equal(t, AuthorizeNetStatusApproved, res.Status)
+	})
+
 	t.Run("Success: Declined", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
@@ -633,3 +686,353 @@ func TestCreatePaymentTransaction(t *testing.T) {
 	})
 
 }
+
+func TestRefundTransaction(t *testing.T) {
+	ctx := context.TODO()
+	apiLoginID := "test-login"
+	transactionKey := "test-key"
+
+	t.Run("Success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var requestBody ReferenceTransactionRequest
+			err := json.NewDecoder(r.Body).Decode(&requestBody)
+			assert.NoError(t, err)
+
+			assert.Equal(t, "refundTransaction", requestBody.Data.TransactionRequest.TransactionType)
+			assert.Equal(t, "25.50", requestBody.Data.TransactionRequest.Amount)
+			assert.Equal(t, "80041310709", requestBody.Data.TransactionRequest.RefTransID)
+			assert.Equal(t, "1111", requestBody.Data.TransactionRequest.Payment.CreditCard.CardNumber)
+			assert.Equal(t, "XXXX", requestBody.Data.TransactionRequest.Payment.CreditCard.ExpirationDate)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{
+				"transactionResponse": {
+					"responseCode": "1",
+					"transId": "80041310800"
+				},
+				"messages": {
+					"resultCode": "Ok",
+					"message": [{
+						"code": "I00001",
+						"text": "Successful."
+					}]
+				}
+			}`))
+		}))
+		defer server.Close()
+
+		svc := &service{
+			apiLoginID:     apiLoginID,
+			transactionKey: transactionKey,
+			endpoint:       server.URL,
+			httpClient:     &http.Client{},
+			logger:         zap.NewExample().Sugar(),
+		}
+
+		res, err := svc.RefundTransaction(ctx, entities.RefundTransactionRequest{
+			TransactionID:  "80041310709",
+			Amount:         decimal.NewFromFloat(25.5),
+			CardNumber:     "XXXX1111",
+			ExpirationDate: "XXXX",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "80041310800", res.ID)
+		assert.Equal(t, AuthorizeNetStatusApproved, res.Status)
+	})
+
+	t.Run("Error: Transaction error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{
+				"transactionResponse": {
+					"responseCode": "3",
+					"transId": "0",
+					"errors": [{
+						"errorCode": "54",
+						"errorText": "The referenced transaction does not meet the criteria for issuing a credit."
+					}]
+				},
+				"messages": {
+					"resultCode": "Error",
+					"message": [{
+						"code": "E00027",
+						"text": "The transaction was unsuccessful."
+					}]
+				}
+			}`))
+		}))
+		defer server.Close()
+
+		svc := &service{
+			apiLoginID:     apiLoginID,
+			transactionKey: transactionKey,
+			endpoint:       server.URL,
+			httpClient:     &http.Client{},
+			logger:         zap.NewExample().Sugar(),
+		}
+
+		_, err := svc.RefundTransaction(ctx, entities.RefundTransactionRequest{
+			TransactionID: "80041310709",
+			Amount:        decimal.NewFromInt(10),
+		})
+
+		assert.ErrorContains(t, err, "54 - The referenced transaction does not meet the criteria for issuing a credit.")
+	})
+}
+
+func TestCaptureTransaction(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("Success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var requestBody ReferenceTransactionRequest
+			err := json.NewDecoder(r.Body).Decode(&requestBody)
+			assert.NoError(t, err)
+
+			assert.Equal(t, "priorAuthCaptureTransaction", requestBody.Data.TransactionRequest.TransactionType)
+			assert.Equal(t, "80041310709", requestBody.Data.TransactionRequest.RefTransID)
+			assert.Equal(t, "46.50", requestBody.Data.TransactionRequest.Amount)
+			assert.Nil(t, requestBody.Data.TransactionRequest.Payment)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{
+				"transactionResponse": {
+					"responseCode": "1",
+					"transId": "80041310709"
+				},
+				"messages": {
+					"resultCode": "Ok",
+					"message": [{
+						"code": "I00001",
+						"text": "Successful."
+					}]
+				}
+			}`))
+		}))
+		defer server.Close()
+
+		svc := &service{
+			apiLoginID:     "test-login",
+			transactionKey: "test-key",
+			endpoint:       server.URL,
+			httpClient:     &http.Client{},
+			logger:         zap.NewExample().Sugar(),
+		}
+
+		res, err := svc.CaptureTransaction(ctx, entities.CaptureTransactionRequest{
+			TransactionID: "80041310709",
+			Amount:        decimal.RequireFromString("46.5"),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "80041310709", res.ID)
+		assert.Equal(t, AuthorizeNetStatusApproved, res.Status)
+	})
+}
+
+func TestVoidTransaction(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("Success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var requestBody ReferenceTransactionRequest
+			err := json.NewDecoder(r.Body).Decode(&requestBody)
+			assert.NoError(t, err)
+
+			assert.Equal(t, "voidTransaction", requestBody.Data.TransactionRequest.TransactionType)
+			assert.Equal(t, "80041310709", requestBody.Data.TransactionRequest.RefTransID)
+			assert.Empty(t, requestBody.Data.TransactionRequest.Amount)
+			assert.Nil(t, requestBody.Data.TransactionRequest.Payment)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{
+				"transactionResponse": {
+					"responseCode": "1",
+					"transId": "80041310709"
+				},
+				"messages": {
+					"resultCode": "Ok",
+					"message": [{
+						"code": "I00001",
+						"text": "Successful."
+					}]
+				}
+			}`))
+		}))
+		defer server.Close()
+
+		svc := &service{
+			apiLoginID:     "test-login",
+			transactionKey: "test-key",
+			endpoint:       server.URL,
+			httpClient:     &http.Client{},
+			logger:         zap.NewExample().Sugar(),
+		}
+
+		res, err := svc.VoidTransaction(ctx, entities.VoidTransactionRequest{TransactionID: "80041310709"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "80041310709", res.ID)
+		assert.Equal(t, AuthorizeNetStatusApproved, res.Status)
+	})
+}
+
+func TestGetTransactionDetails(t *testing.T) {
+	ctx := context.TODO()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requestBody GetTransactionDetailsRequest
+		err := json.NewDecoder(r.Body).Decode(&requestBody)
+		assert.NoError(t, err)
+		assert.Equal(t, "80041310709", requestBody.Data.TransID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("\uFEFF" + `{
+			"transaction": {
+				"transId": "80041310709",
+				"transactionStatus": "settledSuccessfully",
+				"authAmount": 100.00,
+				"settleAmount": 100.00,
+				"payment": {
+					"creditCard": {
+						"cardNumber": "XXXX1111",
+						"expirationDate": "XXXX",
+						"cardType": "Visa"
+					}
+				}
+			},
+			"messages": {
+				"resultCode": "Ok",
+				"message": [{
+					"code": "I00001",
+					"text": "Successful."
+				}]
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	svc := &service{
+		apiLoginID:     "test-login",
+		transactionKey: "test-key",
+		endpoint:       server.URL,
+		httpClient:     &http.Client{},
+		logger:         zap.NewExample().Sugar(),
+	}
+
+	res, err := svc.GetTransactionDetails(ctx, entities.GetTransactionDetailsRequest{TransactionID: "80041310709"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, TransactionStatusSettledSuccessfully, res.Status)
+	assert.True(t, decimal.NewFromInt(100).Equal(res.SettleAmount))
+	assert.Equal(t, "XXXX1111", res.CardNumber)
+	assert.Equal(t, "XXXX", res.ExpirationDate)
+}
+
+func TestUpdateCustomerPaymentProfile(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("Success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var requestBody UpdateCustomerPaymentProfileRequest
+			err := json.NewDecoder(r.Body).Decode(&requestBody)
+			assert.NoError(t, err)
+
+			assert.Equal(t, "523520086", requestBody.Data.CustomerProfileID)
+			assert.Equal(t, UpdatePaymentProfile{
+				Payment: Payment{
+					CreditCard: CreditCard{CardNumber: "XXXX8888", ExpirationDate: "2025-12"},
+				},
+				DefaultPaymentProfile:    true,
+				CustomerPaymentProfileID: "535672235",
+			}, requestBody.Data.PaymentProfile)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{
+				"messages": {
+					"resultCode": "Ok",
+					"message": [{
+						"code": "I00001",
+						"text": "Successful."
+					}]
+				}
+			}`))
+		}))
+		defer server.Close()
+
+		svc := &service{
+			apiLoginID:     "test-login",
+			transactionKey: "test-key",
+			endpoint:       server.URL,
+			httpClient:     &http.Client{},
+			logger:         zap.NewExample().Sugar(),
+		}
+
+		err := svc.UpdateCustomerPaymentProfile(ctx, entities.UpdateCustomerPaymentProfileRequest{
+			ProfileID:        "523520086",
+			PaymentProfileID: "535672235",
+			CardNumber:       "XXXX8888",
+			ExpirationDate:   "2025-12",
+			Default:          true,
+		})
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestDeleteCustomerPaymentProfile(t *testing.T) {
+	ctx := context.TODO()
+
+	newService := func(response string) (*service, func()) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var requestBody DeleteCustomerPaymentProfileRequest
+			err := json.NewDecoder(r.Body).Decode(&requestBody)
+			assert.NoError(t, err)
+
+			assert.Equal(t, "523520086", requestBody.Data.CustomerProfileID)
+			assert.Equal(t, "535672235", requestBody.Data.CustomerPaymentProfileID)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(response))
+		}))
+
+		return &service{
+			apiLoginID:     "test-login",
+			transactionKey: "test-key",
+			endpoint:       server.URL,
+			httpClient:     &http.Client{},
+			logger:         zap.NewExample().Sugar(),
+		}, server.Close
+	}
+
+	req := entities.DeleteCustomerPaymentProfileRequest{
+		ProfileID:        "523520086",
+		PaymentProfileID: "535672235",
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		svc, closeServer := newService(`{"messages": {"resultCode": "Ok", "message": [{"code": "I00001", "text": "Successful."}]}}`)
+		defer closeServer()
+
+		err := svc.DeleteCustomerPaymentProfile(ctx, req)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("API Error", func(t *testing.T) {
+		svc, closeServer := newService(`{"messages": {"resultCode": "Error", "message": [{"code": "E00040", "text": "The record cannot be found."}]}}`)
+		defer closeServer()
+
+		err := svc.DeleteCustomerPaymentProfile(ctx, req)
+
+		assert.EqualError(t, err, "authorize.net API error: E00040 - The record cannot be found.")
+	})
+}
@@ -6,8 +6,19 @@ import (
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 )
 
+// Client is implemented by every payment provider, payments are referred to by the ID the provider gave them.
 type Client interface {
-	CreatePayment(ctx context.Context, req any) (providers.PaymentProviderResponse, error)
+	CreatePayment(ctx context.Context, req providers.CreatePaymentRequest) (providers.PaymentProviderResponse, error)
 	GetProvider() providers.ProviderType
-	Refund(ctx context.Context, req any) (*providers.RefundResponse, error)
+	Refund(ctx context.Context, req providers.RefundRequest) (*providers.RefundResponse, error)
+	// Capture collects funds that were only authorized when the payment was created
+	Capture(ctx context.Context, req providers.CaptureRequest) (providers.PaymentProviderResponse, error)
+	// Void releases funds that were authorized but not captured
+	Void(ctx context.Context, req providers.VoidRequest) error
+	// GetPayment queries the payment provider for the current state of a payment
+	GetPayment(ctx context.Context, paymentID string) (providers.PaymentProviderResponse, error)
+	// GetPaymentDetails returns the amounts of a payment along with its state
+	GetPaymentDetails(ctx context.Context, paymentID string) (*providers.PaymentDetails, error)
+	// GetRefundDetails returns the state and amount of a refund
+	GetRefundDetails(ctx context.Context, refundID string) (*providers.RefundDetails, error)
 }
@@ -2,7 +2,11 @@
 package payment
 
 import (
+	"slices"
+	"strings"
+
 	authorizenetConfig "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/authorizenet/config"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/fakeprovider"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 	stripeConfig "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe/config"
 	"github.com/pkg/errors"
@@ -10,19 +14,68 @@ import (
 
 // Config should be included as part of service config.
 type Config struct {
-	Provider     providers.ProviderType
+	// Provider is the default provider, it's used when the order doesn't tell which one to pay with
+	Provider providers.ProviderType
+	// Providers are the providers enabled besides the default one, comma separated e.g. "stripe,authorizeNet"
+	Providers string
+	// CaptureMode manual only authorizes the payment at checkout, it is captured when the order ships
+	CaptureMode  providers.CaptureMode
 	Stripe       stripeConfig.Config
 	AuthorizeNet authorizenetConfig.Config
+	Fake         fakeprovider.Config
+}
+
+// DefaultProvider returns the configured default provider, Stripe when none is set.
+func (c *Config) DefaultProvider() providers.ProviderType {
+	if c.Provider == "" {
+		return providers.ProviderStripe
+	}
+
+	return c.Provider
+}
+
+// EnabledProviders returns the default provider followed by the other enabled providers, without duplicates.
+func (c *Config) EnabledProviders() []providers.ProviderType {
+	enabled := []providers.ProviderType{c.DefaultProvider()}
+
+	for _, name := range strings.Split(c.Providers, ",") {
+		provider := providers.ProviderType(strings.TrimSpace(name))
+		if provider == "" || slices.Contains(enabled, provider) {
+			continue
+		}
+
+		enabled = append(enabled, provider)
+	}
+
+	return enabled
 }
 
 // Validate config.
 func (c *Config) Validate() error {
-	switch c.Provider {
-	case providers.ProviderStripe, "": // defaults to Stripe
+	switch c.CaptureMode {
+	case providers.CaptureModeAutomatic, providers.CaptureModeManual, "": // defaults to automatic
+	default:
+		return errors.Errorf("unknown capture mode: %s", c.CaptureMode)
+	}
+
+	for _, provider := range c.EnabledProviders() {
+		if err := c.validateProvider(provider); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateProvider(provider providers.ProviderType) error {
+	switch provider {
+	case providers.ProviderStripe:
 		return c.Stripe.Validate()
 	case providers.ProviderAuthorizeNet:
 		return c.AuthorizeNet.Validate()
+	case providers.ProviderFake:
+		return c.Fake.Validate()
 	default:
-		return errors.Errorf("unknown provider: %s", c.Provider)
+		return errors.Errorf("unknown provider: %s", provider)
 	}
 }
@@ -0,0 +1,389 @@
+package fakeprovider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+const defaultWebhookDelay = 2 * time.Second
+
+// Provider statuses of the fake payments, named after the Stripe payment intent statuses
+const (
+	statusSucceeded       = "succeeded"
+	statusRequiresCapture = "requires_capture"
+	statusPending         = "processing"
+	statusRequiresAction  = "requires_action"
+	statusDeclined        = "declined"
+	statusCanceled        = "canceled"
+)
+
+const refundFailureReason = "declined"
+
+// EventHandler receives the simulated webhook events
+type EventHandler func(ctx context.Context, event Event) error
+
+type Client interface {
+	CreatePayment(ctx context.Context, req providers.CreatePaymentRequest) (providers.PaymentProviderResponse, error)
+	GetProvider() providers.ProviderType
+	Refund(ctx context.Context, req providers.RefundRequest) (*providers.RefundResponse, error)
+	Capture(ctx context.Context, req providers.CaptureRequest) (providers.PaymentProviderResponse, error)
+	Void(ctx context.Context, req providers.VoidRequest) error
+	GetPayment(ctx context.Context, paymentID string) (providers.PaymentProviderResponse, error)
+	GetPaymentDetails(ctx context.Context, paymentID string) (*providers.PaymentDetails, error)
+	GetRefundDetails(ctx context.Context, refundID string) (*providers.RefundDetails, error)
+	// OnEvent registers the handler the simulated webhook events are delivered to
+	OnEvent(handler EventHandler)
+}
+
+// NewClient returns a payment provider that keeps its payments in memory, they are lost on restart.
+// How each payment behaves is decided by the magic nonces, see NonceSuccess.
+func NewClient(config Config, logger *zap.SugaredLogger) Client {
+	delay := config.WebhookDelay
+	if delay == 0 {
+		delay = defaultWebhookDelay
+	}
+
+	return &localClient{
+		log:             logger,
+		webhookDelay:    delay,
+		payments:        make(map[string]*payment),
+		refunds:         make(map[string]*refund),
+		idempotencyKeys: make(map[string]string),
+	}
+}
+
+type payment struct {
+	id             string
+	amount         decimal.Decimal
+	capturedAmount decimal.Decimal
+	// reservedAmount is refunded or waiting for the refund to complete
+	reservedAmount decimal.Decimal
+	status         providers.PaymentStatus
+	providerStatus string
+	authorizeOnly  bool
+	// amountRules is set for NonceAmount payments, their refunds fail depending on the amount
+	amountRules bool
+}
+
+type refund struct {
+	id        string
+	paymentID string
+	amount    decimal.Decimal
+	status    string
+}
+
+type localClient struct {
+	log          *zap.SugaredLogger
+	webhookDelay time.Duration
+
+	mu              sync.Mutex
+	payments        map[string]*payment
+	refunds         map[string]*refund
+	idempotencyKeys map[string]string
+	handler         EventHandler
+}
+
+func (c *localClient) GetProvider() providers.ProviderType {
+	return providers.ProviderFake
+}
+
+func (c *localClient) OnEvent(handler EventHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.handler = handler
+}
+
+func (c *localClient) CreatePayment(_ context.Context, createReq providers.CreatePaymentRequest) (providers.PaymentProviderResponse, error) {
+	if !createReq.Amount.IsPositive() {
+		return providers.PaymentProviderResponse{}, errors.New("payment amount should be positive")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if id, ok := c.idempotencyKeys[createReq.IdempotencyKey]; ok && createReq.IdempotencyKey != "" {
+		return c.payments[id].response(), nil
+	}
+
+	p := &payment{
+		id:            "fake_pay_" + uuid.NewString(),
+		amount:        createReq.Amount,
+		authorizeOnly: createReq.AuthorizeOnly,
+	}
+
+	nonce := paymentNonce(createReq)
+	if nonce == NonceAmount {
+		p.amountRules = true
+		nonce = amountNonce(createReq.Amount)
+	}
+
+	switch nonce {
+	case NonceDecline:
+		p.fail(statusDeclined)
+	case NoncePending:
+		p.setStatus(providers.PaymentStatusPending, statusPending)
+	case NonceAsyncSuccess:
+		p.setStatus(providers.PaymentStatusPending, statusPending)
+		c.sendEvent(Event{Type: EventPaymentSucceeded, ObjectID: p.id, Amount: p.amount}, p.succeed)
+	case NonceAsyncFailure:
+		p.setStatus(providers.PaymentStatusPending, statusPending)
+		c.sendEvent(Event{Type: EventPaymentFailed, ObjectID: p.id, Amount: p.amount}, func() { p.fail(statusDeclined) })
+	case NonceRequiresAction:
+		p.setStatus(providers.PaymentStatusRequiresAction, statusRequiresAction)
+		c.sendEvent(Event{Type: EventPaymentSucceeded, ObjectID: p.id, Amount: p.amount}, p.succeed)
+	default:
+		p.succeed()
+	}
+
+	c.payments[p.id] = p
+	if createReq.IdempotencyKey != "" {
+		c.idempotencyKeys[createReq.IdempotencyKey] = p.id
+	}
+
+	c.log.Infof("Fake payment %s of %s created: %s", p.id, p.amount.StringFixed(2), p.providerStatus)
+
+	return p.response(), nil
+}
+
+// Capture collects the amount authorized, a payment captured already is reported as captured.
+func (c *localClient) Capture(_ context.Context, captureReq providers.CaptureRequest) (providers.PaymentProviderResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, err := c.getPayment(captureReq.PaymentID)
+	if err != nil {
+		return providers.PaymentProviderResponse{}, err
+	}
+
+	if p.status != providers.PaymentStatusSuccess {
+		return providers.PaymentProviderResponse{}, errors.Errorf("payment in status %s can't be captured", p.providerStatus)
+	}
+
+	if p.providerStatus == statusRequiresCapture {
+		amount := captureReq.Amount
+		if amount.IsZero() {
+			amount = p.amount
+		}
+
+		if amount.GreaterThan(p.amount) {
+			return providers.PaymentProviderResponse{}, errors.New("capture amount exceeds the amount authorized")
+		}
+
+		p.capturedAmount = amount
+		p.providerStatus = statusSucceeded
+	}
+
+	return p.response(), nil
+}
+
+// Void releases a payment that wasn't captured.
+func (c *localClient) Void(_ context.Context, voidReq providers.VoidRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, err := c.getPayment(voidReq.PaymentID)
+	if err != nil {
+		return err
+	}
+
+	if p.capturedAmount.IsPositive() {
+		return errors.New("captured payments can't be voided, they have to be refunded")
+	}
+
+	p.fail(statusCanceled)
+
+	return nil
+}
+
+// Refund is reported as pending, a simulated webhook tells whether it succeeded.
+func (c *localClient) Refund(_ context.Context, refundReq providers.RefundRequest) (*providers.RefundResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, err := c.getPayment(refundReq.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	refundable := p.capturedAmount.Sub(p.reservedAmount)
+	amount := refundReq.Amount
+	if amount.IsZero() {
+		amount = refundable
+	}
+
+	if !amount.IsPositive() || amount.GreaterThan(refundable) {
+		return nil, errors.Errorf("refund amount exceeds the %s left to refund", refundable.StringFixed(2))
+	}
+
+	r := &refund{
+		id:        "fake_re_" + uuid.NewString(),
+		paymentID: p.id,
+		amount:    amount,
+		status:    providers.RefundStatusPending,
+	}
+	c.refunds[r.id] = r
+	p.reservedAmount = p.reservedAmount.Add(amount)
+
+	if p.amountRules && cents(amount) == failingRefundCents {
+		c.sendEvent(Event{Type: EventRefundFailed, ObjectID: r.id, Amount: amount, FailureReason: refundFailureReason}, func() {
+			r.status = providers.RefundStatusFailed
+			p.reservedAmount = p.reservedAmount.Sub(amount)
+		})
+	} else {
+		c.sendEvent(Event{Type: EventRefundSucceeded, ObjectID: r.id, Amount: amount}, func() {
+			r.status = providers.RefundStatusSucceeded
+		})
+	}
+
+	return &providers.RefundResponse{ID: r.id, Status: r.status}, nil
+}
+
+func (c *localClient) GetPayment(_ context.Context, paymentID string) (providers.PaymentProviderResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, err := c.getPayment(paymentID)
+	if err != nil {
+		return providers.PaymentProviderResponse{}, err
+	}
+
+	return p.response(), nil
+}
+
+func (c *localClient) GetPaymentDetails(_ context.Context, paymentID string) (*providers.PaymentDetails, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, err := c.getPayment(paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	refunded := decimal.Zero
+	for _, r := range c.refunds {
+		if r.paymentID == p.id && r.status == providers.RefundStatusSucceeded {
+			refunded = refunded.Add(r.amount)
+		}
+	}
+
+	return &providers.PaymentDetails{
+		ID:             p.id,
+		Status:         p.status,
+		ProviderStatus: p.providerStatus,
+		Amount:         p.amount,
+		CapturedAmount: p.capturedAmount,
+		RefundedAmount: &refunded,
+	}, nil
+}
+
+func (c *localClient) GetRefundDetails(_ context.Context, refundID string) (*providers.RefundDetails, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r, ok := c.refunds[refundID]
+	if !ok {
+		return nil, errors.Errorf("fake refund %s not found", refundID)
+	}
+
+	return &providers.RefundDetails{
+		ID:     r.id,
+		Status: r.status,
+		Amount: r.amount,
+	}, nil
+}
+
+func (c *localClient) getPayment(paymentID string) (*payment, error) {
+	p, ok := c.payments[paymentID]
+	if !ok {
+		return nil, errors.Errorf("fake payment %s not found", paymentID)
+	}
+
+	return p, nil
+}
+
+// sendEvent applies the change reported by the event once the webhook delay is over and delivers the event,
+// the way the payment provider would call the webhook.
+func (c *localClient) sendEvent(event Event, apply func()) {
+	event.ID = "fake_evt_" + uuid.NewString()
+
+	time.AfterFunc(c.webhookDelay, func() {
+		c.mu.Lock()
+		apply()
+		handler := c.handler
+		c.mu.Unlock()
+
+		if handler == nil {
+			c.log.Warnf("No handler for the fake payment event %s %s", event.Type, event.ObjectID)
+			return
+		}
+
+		if err := handler(context.Background(), event); err != nil {
+			c.log.Errorf("Error handling fake payment event %s %s: %v", event.Type, event.ObjectID, err)
+		}
+	})
+}
+
+func (p *payment) setStatus(status providers.PaymentStatus, providerStatus string) {
+	p.status = status
+	p.providerStatus = providerStatus
+}
+
+func (p *payment) succeed() {
+	if p.authorizeOnly {
+		p.setStatus(providers.PaymentStatusSuccess, statusRequiresCapture)
+		return
+	}
+
+	p.setStatus(providers.PaymentStatusSuccess, statusSucceeded)
+	p.capturedAmount = p.amount
+}
+
+func (p *payment) fail(providerStatus string) {
+	p.setStatus(providers.PaymentStatusFailed, providerStatus)
+}
+
+func (p *payment) response() providers.PaymentProviderResponse {
+	res := providers.PaymentProviderResponse{
+		ID:     p.id,
+		Status: p.status,
+	}
+
+	if p.status == providers.PaymentStatusRequiresAction {
+		res.NextAction = &providers.PaymentNextAction{
+			Type:        "redirect_to_url",
+			RedirectURL: "https://fake-payment.invalid/authenticate/" + p.id,
+		}
+	}
+
+	return res
+}
+
+// paymentNonce tells how the payment behaves, from the nonce or else from the payment method ID.
+func paymentNonce(req providers.CreatePaymentRequest) string {
+	if req.PaymentNonce != "" {
+		return req.PaymentNonce
+	}
+
+	return req.PaymentMethodID
+}
+
+// amountNonce tells how a NonceAmount payment behaves from the cents of its amount.
+func amountNonce(amount decimal.Decimal) string {
+	if nonce, ok := amountNonces[cents(amount)]; ok {
+		return nonce
+	}
+
+	return NonceSuccess
+}
+
+func cents(amount decimal.Decimal) int64 {
+	return amount.Shift(2).IntPart() % 100
+}
@@ -0,0 +1,292 @@
+package fakeprovider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestClient(t *testing.T) (Client, chan Event) {
+	t.Helper()
+
+	client := NewClient(Config{WebhookDelay: time.Millisecond}, zap.NewNop().Sugar())
+	events := make(chan Event, 10)
+	client.OnEvent(func(_ context.Context, event Event) error {
+		events <- event
+		return nil
+	})
+
+	return client, events
+}
+
+func receiveEvent(t *testing.T, events chan Event) Event {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return Event{}
+	}
+}
+
+func TestLocalClient_CreatePayment(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		req        providers.CreatePaymentRequest
+		wantStatus providers.PaymentStatus
+		wantEvent  EventType
+	}{
+		{
+			name:       "success nonce",
+			req:        providers.CreatePaymentRequest{Amount: decimal.NewFromFloat(10.02), PaymentNonce: NonceSuccess},
+			wantStatus: providers.PaymentStatusSuccess,
+		},
+		{
+			name:       "decline nonce",
+			req:        providers.CreatePaymentRequest{Amount: decimal.NewFromInt(10), PaymentNonce: NonceDecline},
+			wantStatus: providers.PaymentStatusFailed,
+		},
+		{
+			name:       "pending nonce",
+			req:        providers.CreatePaymentRequest{Amount: decimal.NewFromInt(10), PaymentNonce: NoncePending},
+			wantStatus: providers.PaymentStatusPending,
+		},
+		{
+			name:       "async success nonce",
+			req:        providers.CreatePaymentRequest{Amount: decimal.NewFromInt(10), PaymentNonce: NonceAsyncSuccess},
+			wantStatus: providers.PaymentStatusPending,
+			wantEvent:  EventPaymentSucceeded,
+		},
+		{
+			name:       "async failure nonce",
+			req:        providers.CreatePaymentRequest{Amount: decimal.NewFromInt(10), PaymentNonce: NonceAsyncFailure},
+			wantStatus: providers.PaymentStatusPending,
+			wantEvent:  EventPaymentFailed,
+		},
+		{
+			name:       "requires action nonce",
+			req:        providers.CreatePaymentRequest{Amount: decimal.NewFromInt(10), PaymentNonce: NonceRequiresAction},
+			wantStatus: providers.PaymentStatusRequiresAction,
+			wantEvent:  EventPaymentSucceeded,
+		},
+		{
+			name:       "declined amount",
+			req:        providers.CreatePaymentRequest{Amount: decimal.NewFromFloat(10.02), PaymentNonce: NonceAmount},
+			wantStatus: providers.PaymentStatusFailed,
+		},
+		{
+			name:       "async success amount",
+			req:        providers.CreatePaymentRequest{Amount: decimal.NewFromFloat(10.04), PaymentNonce: NonceAmount},
+			wantStatus: providers.PaymentStatusPending,
+			wantEvent:  EventPaymentSucceeded,
+		},
+		{
+			name:       "any other amount succeeds",
+			req:        providers.CreatePaymentRequest{Amount: decimal.NewFromFloat(10.06), PaymentNonce: NonceAmount},
+			wantStatus: providers.PaymentStatusSuccess,
+		},
+		{
+			name:       "amount cents are ignored without the amount nonce",
+			req:        providers.CreatePaymentRequest{Amount: decimal.NewFromFloat(10.02)},
+			wantStatus: providers.PaymentStatusSuccess,
+		},
+		{
+			name:       "payment method ID used as nonce",
+			req:        providers.CreatePaymentRequest{Amount: decimal.NewFromInt(10), PaymentMethodID: NonceDecline},
+			wantStatus: providers.PaymentStatusFailed,
+		},
+		{
+			name:       "unknown nonce succeeds",
+			req:        providers.CreatePaymentRequest{Amount: decimal.NewFromFloat(10.02), PaymentNonce: "tok_visa"},
+			wantStatus: providers.PaymentStatusSuccess,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, events := newTestClient(t)
+
+			res, err := client.CreatePayment(ctx, tt.req)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, res.Status)
+
+			if tt.wantStatus == providers.PaymentStatusRequiresAction {
+				assert.NotNil(t, res.NextAction)
+			}
+
+			if tt.wantEvent == "" {
+				return
+			}
+
+			event := receiveEvent(t, events)
+			assert.Equal(t, tt.wantEvent, event.Type)
+			assert.Equal(t, res.ID, event.ObjectID)
+
+			// the payment reflects the event once it's delivered
+			payment, err := client.GetPayment(ctx, res.ID)
+			require.NoError(t, err)
+			if tt.wantEvent == EventPaymentSucceeded {
+				assert.Equal(t, providers.PaymentStatusSuccess, payment.Status)
+			} else {
+				assert.Equal(t, providers.PaymentStatusFailed, payment.Status)
+			}
+		})
+	}
+
+	t.Run("same idempotency key returns the same payment", func(t *testing.T) {
+		client, _ := newTestClient(t)
+		req := providers.CreatePaymentRequest{Amount: decimal.NewFromInt(10), IdempotencyKey: "key_123"}
+
+		first, err := client.CreatePayment(ctx, req)
+		require.NoError(t, err)
+		second, err := client.CreatePayment(ctx, req)
+		require.NoError(t, err)
+
+		assert.Equal(t, first.ID, second.ID)
+	})
+
+	t.Run("non positive amount", func(t *testing.T) {
+		client, _ := newTestClient(t)
+
+		_, err := client.CreatePayment(ctx, providers.CreatePaymentRequest{Amount: decimal.Zero})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestLocalClient_CaptureAndVoid(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("capture authorized payment", func(t *testing.T) {
+		client, _ := newTestClient(t)
+		res, err := client.CreatePayment(ctx, providers.CreatePaymentRequest{Amount: decimal.NewFromInt(100), AuthorizeOnly: true})
+		require.NoError(t, err)
+
+		details, err := client.GetPaymentDetails(ctx, res.ID)
+		require.NoError(t, err)
+		assert.True(t, details.CapturedAmount.IsZero())
+
+		_, err = client.Capture(ctx, providers.CaptureRequest{PaymentID: res.ID, Amount: decimal.NewFromInt(80)})
+		require.NoError(t, err)
+
+		details, err = client.GetPaymentDetails(ctx, res.ID)
+		require.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(80).Equal(details.CapturedAmount))
+
+		// a retried capture is reported as captured
+		_, err = client.Capture(ctx, providers.CaptureRequest{PaymentID: res.ID})
+		assert.NoError(t, err)
+	})
+
+	t.Run("void authorized payment", func(t *testing.T) {
+		client, _ := newTestClient(t)
+		res, err := client.CreatePayment(ctx, providers.CreatePaymentRequest{Amount: decimal.NewFromInt(100), AuthorizeOnly: true})
+		require.NoError(t, err)
+
+		err = client.Void(ctx, providers.VoidRequest{PaymentID: res.ID})
+		require.NoError(t, err)
+
+		payment, err := client.GetPayment(ctx, res.ID)
+		require.NoError(t, err)
+		assert.Equal(t, providers.PaymentStatusFailed, payment.Status)
+	})
+
+	t.Run("captured payment can't be voided", func(t *testing.T) {
+		client, _ := newTestClient(t)
+		res, err := client.CreatePayment(ctx, providers.CreatePaymentRequest{Amount: decimal.NewFromInt(100)})
+		require.NoError(t, err)
+
+		err = client.Void(ctx, providers.VoidRequest{PaymentID: res.ID})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown payment", func(t *testing.T) {
+		client, _ := newTestClient(t)
+
+		_, err := client.Capture(ctx, providers.CaptureRequest{PaymentID: "fake_pay_unknown"})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestLocalClient_Refund(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("refund succeeds asynchronously", func(t *testing.T) {
+		client, events := newTestClient(t)
+		res, err := client.CreatePayment(ctx, providers.CreatePaymentRequest{Amount: decimal.NewFromInt(100)})
+		require.NoError(t, err)
+
+		refund, err := client.Refund(ctx, providers.RefundRequest{PaymentID: res.ID, Amount: decimal.NewFromInt(40)})
+		require.NoError(t, err)
+		assert.Equal(t, providers.RefundStatusPending, refund.Status)
+
+		event := receiveEvent(t, events)
+		assert.Equal(t, EventRefundSucceeded, event.Type)
+		assert.Equal(t, refund.ID, event.ObjectID)
+		assert.True(t, decimal.NewFromInt(40).Equal(event.Amount))
+
+		details, err := client.GetPaymentDetails(ctx, res.ID)
+		require.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(40).Equal(*details.RefundedAmount))
+
+		// a zero amount refunds what is left
+		refund, err = client.Refund(ctx, providers.RefundRequest{PaymentID: res.ID})
+		require.NoError(t, err)
+
+		refundDetails, err := client.GetRefundDetails(ctx, refund.ID)
+		require.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(60).Equal(refundDetails.Amount))
+	})
+
+	t.Run("refund of the failing amount fails asynchronously", func(t *testing.T) {
+		client, events := newTestClient(t)
+		res, err := client.CreatePayment(ctx, providers.CreatePaymentRequest{Amount: decimal.NewFromInt(100), PaymentNonce: NonceAmount})
+		require.NoError(t, err)
+
+		refund, err := client.Refund(ctx, providers.RefundRequest{PaymentID: res.ID, Amount: decimal.NewFromFloat(10.06)})
+		require.NoError(t, err)
+
+		event := receiveEvent(t, events)
+		assert.Equal(t, EventRefundFailed, event.Type)
+		assert.Equal(t, refund.ID, event.ObjectID)
+		assert.NotEmpty(t, event.FailureReason)
+
+		// the failed amount can be refunded again
+		_, err = client.Refund(ctx, providers.RefundRequest{PaymentID: res.ID, Amount: decimal.NewFromInt(100)})
+		assert.NoError(t, err)
+	})
+
+	t.Run("refund of the failing amount succeeds without the amount nonce", func(t *testing.T) {
+		client, events := newTestClient(t)
+		res, err := client.CreatePayment(ctx, providers.CreatePaymentRequest{Amount: decimal.NewFromInt(100)})
+		require.NoError(t, err)
+
+		_, err = client.Refund(ctx, providers.RefundRequest{PaymentID: res.ID, Amount: decimal.NewFromFloat(10.06)})
+		require.NoError(t, err)
+
+		assert.Equal(t, EventRefundSucceeded, receiveEvent(t, events).Type)
+	})
+
+	t.Run("refund exceeding the captured amount", func(t *testing.T) {
+		client, _ := newTestClient(t)
+		res, err := client.CreatePayment(ctx, providers.CreatePaymentRequest{Amount: decimal.NewFromInt(100)})
+		require.NoError(t, err)
+
+		_, err = client.Refund(ctx, providers.RefundRequest{PaymentID: res.ID, Amount: decimal.NewFromInt(101)})
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,22 @@
+package fakeprovider
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Config of the fake payment provider
+type Config struct {
+	// WebhookDelay is how long the simulated webhooks wait before they are delivered, defaults to 2s
+	WebhookDelay time.Duration
+}
+
+// Validate config
+func (c *Config) Validate() error {
+	if c.WebhookDelay < 0 {
+		return errors.New("fake payment webhookDelay shouldn't be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package fakeprovider
+
+import "github.com/shopspring/decimal"
+
+// Magic nonces deciding how a fake payment behaves. They are sent as the payment nonce
+// or the payment method ID when the order is placed.
+const (
+	// NonceSuccess approves the payment right away, it's the default for unknown nonces
+	NonceSuccess = "fake-nonce-success"
+	// NonceDecline declines the payment
+	NonceDecline = "fake-nonce-decline"
+	// NoncePending leaves the payment pending, no webhook ever settles it
+	NoncePending = "fake-nonce-pending"
+	// NonceAsyncSuccess leaves the payment pending until a simulated webhook reports it succeeded
+	NonceAsyncSuccess = "fake-nonce-async-success"
+	// NonceAsyncFailure leaves the payment pending until a simulated webhook reports it failed
+	NonceAsyncFailure = "fake-nonce-async-failure"
+	// NonceRequiresAction asks for customer authentication, the simulated webhook reports
+	// the payment succeeded as if the customer had completed it
+	NonceRequiresAction = "fake-nonce-requires-action"
+	// NonceAmount lets the cents of the amount decide how the payment behaves, e.g. 10.02 is declined.
+	// Their refunds of an amount ending in 06 cents fail, every other refund succeeds.
+	NonceAmount = "fake-nonce-amount"
+)
+
+// amountNonces are the behaviors picked by the cents of the amount of NonceAmount payments
+var amountNonces = map[int64]string{
+	2: NonceDecline,
+	3: NoncePending,
+	4: NonceAsyncSuccess,
+	5: NonceAsyncFailure,
+	7: NonceRequiresAction,
+}
+
+const failingRefundCents = 6
+
+type EventType string
+
+const (
+	EventPaymentSucceeded EventType = "payment.succeeded"
+	EventPaymentFailed    EventType = "payment.failed"
+	EventRefundSucceeded  EventType = "refund.succeeded"
+	EventRefundFailed     EventType = "refund.failed"
+)
+
+// Event is a simulated webhook event
+type Event struct {
+	ID       string
+	Type     EventType
+	ObjectID string
+	Amount   decimal.Decimal
+	// FailureReason is set for failed refunds
+	FailureReason string
+}
@@ -0,0 +1,78 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: shared/vendors/payment/registry.go
+
+// Package payment is a generated GoMock package.
+package payment
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	providers "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+)
+
+// MockRegistry is a mock of Registry interface.
+type MockRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockRegistryMockRecorder
+}
+
+// MockRegistryMockRecorder is the mock recorder for MockRegistry.
+type MockRegistryMockRecorder struct {
+	mock *MockRegistry
+}
+
+// NewMockRegistry creates a new mock instance.
+func NewMockRegistry(ctrl *gomock.Controller) *MockRegistry {
+	mock := &MockRegistry{ctrl: ctrl}
+	mock.recorder = &MockRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRegistry) EXPECT() *MockRegistryMockRecorder {
+	return m.recorder
+}
+
+// Default mocks base method.
+func (m *MockRegistry) Default() Client {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Default")
+	ret0, _ := ret[0].(Client)
+	return ret0
+}
+
+// Default indicates an expected call of Default.
+func (mr *MockRegistryMockRecorder) Default() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Default", reflect.TypeOf((*MockRegistry)(nil).Default))
+}
+
+// Get mocks base method.
+func (m *MockRegistry) Get(provider providers.ProviderType) (Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", provider)
+	ret0, _ := ret[0].(Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockRegistryMockRecorder) Get(provider interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRegistry)(nil).Get), provider)
+}
+
+// Providers mocks base method.
+func (m *MockRegistry) Providers() []providers.ProviderType {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Providers")
+	ret0, _ := ret[0].([]providers.ProviderType)
+	return ret0
+}
+
+// Providers indicates an expected call of Providers.
+func (mr *MockRegistryMockRecorder) Providers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Providers", reflect.TypeOf((*MockRegistry)(nil).Providers))
+}
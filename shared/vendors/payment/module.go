@@ -3,6 +3,7 @@ package payment
 import (
 	authorizenetClient "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/authorizenet/client"
 	authorizenetService "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/authorizenet/service"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/fakeprovider"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 	stripeClient "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe/client"
 	stripeService "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe/service"
@@ -21,23 +22,39 @@ type ModuleParams struct {
 
 // NewModule
 // nolint:gocritic
-func NewModule(p ModuleParams) (Client, error) {
-	switch p.Config.Provider {
-	case providers.ProviderStripe, "":
-		service, err := stripeService.New(p.Config.Stripe, p.Logger)
+func NewModule(p ModuleParams) (Registry, error) {
+	clients := make(map[providers.ProviderType]Client)
+
+	for _, provider := range p.Config.EnabledProviders() {
+		client, err := newClient(provider, p.Config, p.Logger)
+		if err != nil {
+			return nil, err
+		}
+
+		clients[provider] = client
+	}
+
+	return NewRegistry(p.Config.DefaultProvider(), clients)
+}
+
+func newClient(provider providers.ProviderType, config Config, logger *zap.SugaredLogger) (Client, error) {
+	switch provider {
+	case providers.ProviderStripe:
+		service, err := stripeService.New(config.Stripe, logger)
 		if err != nil {
 			return nil, err
 		}
 
-		client := stripeClient.NewClient(service)
-		return client, nil
+		return stripeClient.NewClient(service), nil
 	case providers.ProviderAuthorizeNet:
-		service := authorizenetService.New(p.Config.AuthorizeNet, p.Logger)
-		client := authorizenetClient.NewClient(service)
+		service := authorizenetService.New(config.AuthorizeNet, logger)
 
-		return client, nil
+		return authorizenetClient.NewClient(service), nil
+	case providers.ProviderFake:
+		logger.Warn("Using the fake payment provider, payments are simulated and kept in memory")
+		return fakeprovider.NewClient(config.Fake, logger), nil
 	default:
-		return nil, errors.Errorf("unknown provider: %s", p.Config.Provider)
+		return nil, errors.Errorf("unknown provider: %s", provider)
 	}
 }
 
@@ -1,5 +1,7 @@
 package providers
 
+import "github.com/shopspring/decimal"
+
 type (
 	ProviderType  string
 	PaymentStatus string
@@ -8,20 +10,119 @@ type (
 const (
 	ProviderStripe       ProviderType = "stripe"
 	ProviderAuthorizeNet ProviderType = "authorizeNet"
+	// ProviderFake simulates payments locally, for development and tests only
+	ProviderFake ProviderType = "fake"
 )
 
 const (
 	PaymentStatusSuccess PaymentStatus = "success"
 	PaymentStatusPending PaymentStatus = "pending"
 	PaymentStatusFailed  PaymentStatus = "failed"
+	// PaymentStatusRequiresAction means the customer has to complete the payment, e.g. 3-D Secure
+	PaymentStatusRequiresAction PaymentStatus = "requires_action"
 )
 
+// CreatePaymentRequest has what the payment providers need to create a payment,
+// each provider uses the fields it supports.
+type CreatePaymentRequest struct {
+	Amount   decimal.Decimal
+	Currency string
+	// CustomerID is the ID of the customer at the payment provider
+	CustomerID *string
+	// PaymentMethodID is a payment method saved at the payment provider
+	PaymentMethodID string
+	// PaymentNonce is a one-time token of the payment details collected by the storefront
+	PaymentNonce   string
+	BillingInfo    BillingInfo
+	IdempotencyKey string
+	// AuthorizeOnly places a hold on the funds, they are captured later on
+	AuthorizeOnly bool
+}
+
+type BillingInfo struct {
+	FirstName string
+	LastName  string
+	Address   string
+	City      string
+	State     string
+	Country   string
+	Zip       string
+}
+
+type CaptureRequest struct {
+	PaymentID string
+	// Amount to capture, zero captures the whole amount authorized
+	Amount         decimal.Decimal
+	IdempotencyKey string
+}
+
+type RefundRequest struct {
+	PaymentID string
+	// Amount to refund, zero refunds everything that is left on the payment
+	Amount         decimal.Decimal
+	IdempotencyKey string
+}
+
+type VoidRequest struct {
+	PaymentID      string
+	IdempotencyKey string
+}
+
 type PaymentProviderResponse struct {
 	ID     string
 	Status PaymentStatus
+	// PaymentMethodID is the saved payment method charged, set by the providers that pick a default one
+	PaymentMethodID string
+	// NextAction is set when the status is PaymentStatusRequiresAction
+	NextAction *PaymentNextAction
 }
 
+// PaymentNextAction is what the storefront needs to let the customer complete the payment.
+type PaymentNextAction struct {
+	Type         string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// Refund statuses shared by all providers, they match the Stripe refund statuses
+const (
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusPending   = "pending"
+	RefundStatusFailed    = "failed"
+)
+
 type RefundResponse struct {
 	ID     string
 	Status string
 }
+
+// PaymentDetails is the state of a payment as reported by the payment provider.
+type PaymentDetails struct {
+	ID     string
+	Status PaymentStatus
+	// ProviderStatus is the status as named by the payment provider
+	ProviderStatus string
+	// Amount is the amount authorized for the payment
+	Amount decimal.Decimal
+	// CapturedAmount is the amount actually charged
+	CapturedAmount decimal.Decimal
+	// RefundedAmount is nil when the provider doesn't report the refunds along with the payment
+	RefundedAmount *decimal.Decimal
+}
+
+// RefundDetails is the state of a refund as reported by the payment provider.
+type RefundDetails struct {
+	ID     string
+	Status string
+	Amount decimal.Decimal
+}
+
+// CaptureMode tells whether the funds are captured when the order is placed or only authorized.
+type CaptureMode string
+
+const (
+	// CaptureModeAutomatic captures the funds as soon as the payment is created
+	CaptureModeAutomatic CaptureMode = "automatic"
+	// CaptureModeManual only places a hold on the funds, they are captured later on
+	CaptureModeManual CaptureMode = "manual"
+)
@@ -0,0 +1,60 @@
+package payment
+
+import (
+	"sort"
+
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"github.com/pkg/errors"
+)
+
+// Registry holds the clients of the enabled payment providers. New payments are created with
+// the provider the order picks or else the default one, existing payments are managed by the provider that created them.
+type Registry interface {
+	Default() Client
+	Get(provider providers.ProviderType) (Client, error)
+	// Providers returns the enabled providers, the default one first
+	Providers() []providers.ProviderType
+}
+
+type registry struct {
+	defaultProvider providers.ProviderType
+	clients         map[providers.ProviderType]Client
+}
+
+// NewRegistry returns a registry of the clients keyed by their provider, the default provider has to be one of them.
+func NewRegistry(defaultProvider providers.ProviderType, clients map[providers.ProviderType]Client) (Registry, error) {
+	if _, ok := clients[defaultProvider]; !ok {
+		return nil, errors.Errorf("default payment provider %s is not configured", defaultProvider)
+	}
+
+	return &registry{
+		defaultProvider: defaultProvider,
+		clients:         clients,
+	}, nil
+}
+
+func (r *registry) Default() Client {
+	return r.clients[r.defaultProvider]
+}
+
+func (r *registry) Get(provider providers.ProviderType) (Client, error) {
+	client, ok := r.clients[provider]
+	if !ok {
+		return nil, errors.Errorf("payment provider %s is not configured", provider)
+	}
+
+	return client, nil
+}
+
+func (r *registry) Providers() []providers.ProviderType {
+	enabled := make([]providers.ProviderType, 0, len(r.clients))
+	for provider := range r.clients {
+		if provider != r.defaultProvider {
+			enabled = append(enabled, provider)
+		}
+	}
+
+	sort.Slice(enabled, func(i, j int) bool { return enabled[i] < enabled[j] })
+
+	return append([]providers.ProviderType{r.defaultProvider}, enabled...)
+}
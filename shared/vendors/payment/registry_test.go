@@ -0,0 +1,80 @@
+package payment
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	stripeClient, fakeClient := NewMockClient(ctrl), NewMockClient(ctrl)
+
+	registry, err := NewRegistry(providers.ProviderStripe, map[providers.ProviderType]Client{
+		providers.ProviderStripe: stripeClient,
+		providers.ProviderFake:   fakeClient,
+	})
+	assert.NoError(t, err)
+
+	t.Run("default client", func(t *testing.T) {
+		assert.Equal(t, stripeClient, registry.Default())
+	})
+
+	t.Run("client by provider", func(t *testing.T) {
+		client, err := registry.Get(providers.ProviderFake)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fakeClient, client)
+	})
+
+	t.Run("enabled providers", func(t *testing.T) {
+		assert.Equal(t, []providers.ProviderType{providers.ProviderStripe, providers.ProviderFake}, registry.Providers())
+	})
+
+	t.Run("provider not configured", func(t *testing.T) {
+		client, err := registry.Get(providers.ProviderAuthorizeNet)
+
+		assert.Nil(t, client)
+		assert.EqualError(t, err, "payment provider authorizeNet is not configured")
+	})
+
+	t.Run("default provider not configured", func(t *testing.T) {
+		_, err := NewRegistry(providers.ProviderAuthorizeNet, map[providers.ProviderType]Client{
+			providers.ProviderStripe: stripeClient,
+		})
+
+		assert.EqualError(t, err, "default payment provider authorizeNet is not configured")
+	})
+}
+
+func TestConfig_EnabledProviders(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   []providers.ProviderType
+	}{
+		{
+			name:   "defaults to stripe",
+			config: Config{},
+			want:   []providers.ProviderType{providers.ProviderStripe},
+		},
+		{
+			name:   "default provider first",
+			config: Config{Provider: providers.ProviderAuthorizeNet, Providers: "stripe, authorizeNet"},
+			want:   []providers.ProviderType{providers.ProviderAuthorizeNet, providers.ProviderStripe},
+		},
+		{
+			name:   "empty entries are skipped",
+			config: Config{Provider: providers.ProviderStripe, Providers: "fake,,"},
+			want:   []providers.ProviderType{providers.ProviderStripe, providers.ProviderFake},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.config.EnabledProviders())
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe/entities"
+	moduleErrors "github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe/errors"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe/service"
 	"github.com/pkg/errors"
 )
@@ -13,12 +14,21 @@ type Client interface {
 	CreateCustomer(ctx context.Context, req *entities.CreateCustomerRequest) (*entities.CreateCustomerResponse, error)
 	GetCustomerPaymentMethods(ctx context.Context, customerId *string) (*entities.GetCustomerPaymentMethodsResponse, error)
 	GetCustomerPaymentMethodById(_ context.Context, customerId, paymentMethodId *string) (*entities.GetCustomerPaymentMethodResponse, error)
+	GetCustomerDefaultPaymentMethod(ctx context.Context, customerId *string) (string, error)
+	SetCustomerDefaultPaymentMethod(ctx context.Context, customerId, paymentMethodId *string) error
+	DetachPaymentMethod(ctx context.Context, paymentMethodId *string) error
 	GetSetupIntent(ctx context.Context, customerId *string) (*entities.GetSetupIntentResponse, error)
-	CreatePayment(ctx context.Context, req any) (providers.PaymentProviderResponse, error)
+	CreatePayment(ctx context.Context, req providers.CreatePaymentRequest) (providers.PaymentProviderResponse, error)
 	GetWebhookEvent(ctx context.Context, req *entities.HandleWebhookEventRequest) (*entities.HandleWebhookEventResponse, error)
 	GetProvider() providers.ProviderType
-	Refund(ctx context.Context, req any) (*providers.RefundResponse, error)
+	Refund(ctx context.Context, req providers.RefundRequest) (*providers.RefundResponse, error)
+	Capture(ctx context.Context, req providers.CaptureRequest) (providers.PaymentProviderResponse, error)
+	Void(ctx context.Context, req providers.VoidRequest) error
+	GetPayment(ctx context.Context, paymentID string) (providers.PaymentProviderResponse, error)
+	GetPaymentDetails(ctx context.Context, paymentID string) (*providers.PaymentDetails, error)
+	GetRefundDetails(ctx context.Context, refundID string) (*providers.RefundDetails, error)
 	GetRefund(ctx context.Context, refundId string) (*entities.RefundResponse, error)
+	GetDispute(ctx context.Context, disputeId string) (*entities.GetDisputeResponse, error)
 }
 
 func NewClient(svc service.Service) Client {
@@ -41,6 +51,18 @@ func (c *localClient) GetCustomerPaymentMethodById(ctx context.Context, customer
 	return c.svc.GetCustomerPaymentMethodById(ctx, customerId, paymentMethodId)
 }
 
+func (c *localClient) GetCustomerDefaultPaymentMethod(ctx context.Context, customerId *string) (string, error) {
+	return c.svc.GetCustomerDefaultPaymentMethod(ctx, customerId)
+}
+
+func (c *localClient) SetCustomerDefaultPaymentMethod(ctx context.Context, customerId, paymentMethodId *string) error {
+	return c.svc.SetCustomerDefaultPaymentMethod(ctx, customerId, paymentMethodId)
+}
+
+func (c *localClient) DetachPaymentMethod(ctx context.Context, paymentMethodId *string) error {
+	return c.svc.DetachPaymentMethod(ctx, paymentMethodId)
+}
+
 func (c *localClient) GetSetupIntent(ctx context.Context, customerId *string) (*entities.GetSetupIntentResponse, error) {
 	return c.svc.GetSetupIntent(ctx, customerId)
 }
@@ -49,44 +71,176 @@ func (c *localClient) GetWebhookEvent(ctx context.Context, req *entities.HandleW
 	return c.svc.GetWebhookEvent(ctx, req)
 }
 
-func (c *localClient) CreatePayment(ctx context.Context, req any) (providers.PaymentProviderResponse, error) {
-	stripeReq, ok := req.(entities.CreatePaymentIntentRequest)
-	if !ok {
-		return providers.PaymentProviderResponse{}, errors.New("invalid request type")
+// CreatePayment creates a payment intent charging the customer's saved payment method,
+// the default payment method of the customer is charged when none is given.
+func (c *localClient) CreatePayment(ctx context.Context, req providers.CreatePaymentRequest) (providers.PaymentProviderResponse, error) {
+	paymentMethodID := req.PaymentMethodID
+	if paymentMethodID == "" {
+		// guests have no Stripe customer, they pay with the payment method they send
+		if req.CustomerID == nil {
+			return providers.PaymentProviderResponse{}, errors.New("stripe customer ID is required for payment")
+		}
+
+		defaultPaymentMethodID, err := c.svc.GetCustomerDefaultPaymentMethod(ctx, req.CustomerID)
+		if err != nil {
+			return providers.PaymentProviderResponse{}, err
+		}
+
+		if defaultPaymentMethodID == "" {
+			return providers.PaymentProviderResponse{}, moduleErrors.NewAPIError("STRIPE_PAYMENT_METHOD_REQUIRED")
+		}
+		paymentMethodID = defaultPaymentMethodID
 	}
 
-	res, err := c.svc.CreatePaymentIntent(ctx, &stripeReq)
+	res, err := c.svc.CreatePaymentIntent(ctx, &entities.CreatePaymentIntentRequest{
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		CustomerId:      req.CustomerID,
+		PaymentMethodId: paymentMethodID,
+		IdempotencyKey:  req.IdempotencyKey,
+		CaptureManually: req.AuthorizeOnly,
+	})
 	if err != nil {
 		return providers.PaymentProviderResponse{}, err
 	}
 
+	// the outcome of the payment is confirmed by the payment_intent webhooks
+	if res.Status == entities.StripePaymentIntentRequiresAction {
+		return providers.PaymentProviderResponse{
+			ID:              res.Id,
+			Status:          providers.PaymentStatusRequiresAction,
+			PaymentMethodID: paymentMethodID,
+			NextAction: &providers.PaymentNextAction{
+				Type:         res.NextActionType,
+				ClientSecret: res.ClientSecret,
+				RedirectURL:  res.RedirectURL,
+			},
+		}, nil
+	}
+
 	return providers.PaymentProviderResponse{
+		ID:              res.Id,
+		Status:          providers.PaymentStatusPending,
+		PaymentMethodID: paymentMethodID,
+	}, nil
+}
+
+func (c *localClient) Refund(ctx context.Context, req providers.RefundRequest) (*providers.RefundResponse, error) {
+	if req.PaymentID == "" {
+		return nil, errors.New("payment intent ID is required for refund")
+	}
+
+	res, err := c.svc.Refund(ctx, &entities.RefundRequest{
+		PaymentIntentId: req.PaymentID,
+		Amount:          req.Amount,
+		IdempotencyKey:  req.IdempotencyKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &providers.RefundResponse{
 		ID:     res.Id,
-		Status: providers.PaymentStatusPending,
+		Status: res.Status,
 	}, nil
 }
 
-func (c *localClient) Refund(ctx context.Context, req any) (*providers.RefundResponse, error) {
-	stripeReq, ok := req.(*entities.RefundRequest)
-	if !ok {
-		return nil, errors.New("invalid request type for refund")
+// Capture collects the funds held by a payment intent created with CaptureManually.
+func (c *localClient) Capture(ctx context.Context, req providers.CaptureRequest) (providers.PaymentProviderResponse, error) {
+	if req.PaymentID == "" {
+		return providers.PaymentProviderResponse{}, errors.New("payment intent ID is required for capture")
 	}
 
-	if stripeReq.PaymentIntentId == "" {
-		return nil, errors.New("payment intent ID is required for refund")
+	res, err := c.svc.CapturePaymentIntent(ctx, &entities.CapturePaymentIntentRequest{
+		PaymentIntentId: req.PaymentID,
+		Amount:          req.Amount,
+		IdempotencyKey:  req.IdempotencyKey,
+	})
+	if err != nil {
+		return providers.PaymentProviderResponse{}, err
+	}
+
+	status := providers.PaymentStatusPending
+	if res.Status == entities.StripePaymentIntentSucceeded {
+		status = providers.PaymentStatusSuccess
 	}
 
-	res, err := c.svc.Refund(ctx, stripeReq)
+	return providers.PaymentProviderResponse{
+		ID:     res.Id,
+		Status: status,
+	}, nil
+}
+
+// Void releases the funds held by a payment intent that wasn't captured.
+func (c *localClient) Void(ctx context.Context, req providers.VoidRequest) error {
+	if req.PaymentID == "" {
+		return errors.New("payment intent ID is required for void")
+	}
+
+	return c.svc.CancelPaymentIntent(ctx, &entities.CancelPaymentIntentRequest{
+		PaymentIntentId: req.PaymentID,
+		IdempotencyKey:  req.IdempotencyKey,
+	})
+}
+
+// GetPayment retrieves the payment intent to tell the current state of the payment.
+func (c *localClient) GetPayment(ctx context.Context, paymentID string) (providers.PaymentProviderResponse, error) {
+	res, err := c.svc.GetPaymentIntent(ctx, paymentID)
+	if err != nil {
+		return providers.PaymentProviderResponse{}, err
+	}
+
+	return providers.PaymentProviderResponse{
+		ID:     res.Id,
+		Status: mapPaymentIntentStatusToPaymentStatus(res.Status),
+	}, nil
+}
+
+// GetPaymentDetails retrieves the payment intent along with the amounts charged and refunded.
+func (c *localClient) GetPaymentDetails(ctx context.Context, paymentID string) (*providers.PaymentDetails, error) {
+	res, err := c.svc.GetPaymentIntent(ctx, paymentID)
 	if err != nil {
 		return nil, err
 	}
 
-	return &providers.RefundResponse{
+	return &providers.PaymentDetails{
+		ID:             res.Id,
+		Status:         mapPaymentIntentStatusToPaymentStatus(res.Status),
+		ProviderStatus: res.Status,
+		Amount:         res.Amount,
+		CapturedAmount: res.AmountReceived,
+		RefundedAmount: &res.AmountRefunded,
+	}, nil
+}
+
+// GetRefundDetails retrieves a refund, Stripe refund statuses are the provider-neutral ones.
+func (c *localClient) GetRefundDetails(ctx context.Context, refundID string) (*providers.RefundDetails, error) {
+	res, err := c.svc.GetRefund(ctx, refundID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &providers.RefundDetails{
 		ID:     res.Id,
 		Status: res.Status,
+		Amount: res.Amount,
 	}, nil
 }
 
+func mapPaymentIntentStatusToPaymentStatus(status string) providers.PaymentStatus {
+	switch status {
+	// an authorized payment waiting for capture is reported as succeeded by the webhooks too
+	case entities.StripePaymentIntentSucceeded, entities.StripePaymentIntentRequiresCapture:
+		return providers.PaymentStatusSuccess
+	case entities.StripePaymentIntentRequiresPaymentMethod, entities.StripePaymentIntentCanceled:
+		return providers.PaymentStatusFailed
+	case entities.StripePaymentIntentRequiresAction:
+		return providers.PaymentStatusRequiresAction
+	}
+
+	return providers.PaymentStatusPending
+}
+
 func (c *localClient) GetProvider() providers.ProviderType {
 	return providers.ProviderStripe
 }
@@ -94,3 +248,7 @@ func (c *localClient) GetProvider() providers.ProviderType {
 func (c *localClient) GetRefund(ctx context.Context, refundId string) (*entities.RefundResponse, error) {
 	return c.svc.GetRefund(ctx, refundId)
 }
+
+func (c *localClient) GetDispute(ctx context.Context, disputeId string) (*entities.GetDisputeResponse, error) {
+	return c.svc.GetDispute(ctx, disputeId)
+}
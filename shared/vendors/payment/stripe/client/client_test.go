@@ -7,6 +7,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	appErrors "github.com/nurdsoft/nurd-commerce-core/shared/errors"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/providers"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe/entities"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/payment/stripe/service"
 	"github.com/shopspring/decimal"
@@ -55,26 +56,96 @@ func TestClient_CreatePaymentIntent(t *testing.T) {
 
 	ctx := context.Background()
 	customerId := "cus_123"
-	// Create as a value, not a pointer
-	req := entities.CreatePaymentIntentRequest{
+	req := providers.CreatePaymentRequest{
 		Amount:          decimal.NewFromInt(1000),
 		Currency:        "usd",
-		CustomerId:      &customerId,
-		PaymentMethodId: "pm_123",
+		CustomerID:      &customerId,
+		PaymentMethodID: "pm_123",
+		IdempotencyKey:  "key_123",
+		AuthorizeOnly:   true,
 	}
 
 	t.Run("Success", func(t *testing.T) {
 		expectedResp := &entities.CreatePaymentIntentResponse{
 			Id: "pi_123",
 		}
-		// When CreatePayment receives a value, it will pass a pointer to the service
-		mockService.EXPECT().CreatePaymentIntent(ctx, gomock.Any()).Return(expectedResp, nil)
+		mockService.EXPECT().CreatePaymentIntent(ctx, &entities.CreatePaymentIntentRequest{
+			Amount:          decimal.NewFromInt(1000),
+			Currency:        "usd",
+			CustomerId:      &customerId,
+			PaymentMethodId: "pm_123",
+			IdempotencyKey:  "key_123",
+			CaptureManually: true,
+		}).Return(expectedResp, nil)
 
 		resp, err := client.CreatePayment(ctx, req)
 		assert.NoError(t, err)
 		assert.Equal(t, "pi_123", resp.ID)
 	})
 
+	t.Run("Missing customer", func(t *testing.T) {
+		resp, err := client.CreatePayment(ctx, providers.CreatePaymentRequest{Amount: decimal.NewFromInt(1000)})
+		assert.Error(t, err)
+		assert.Empty(t, resp.ID)
+	})
+
+	t.Run("Guest pays with the payment method", func(t *testing.T) {
+		mockService.EXPECT().CreatePaymentIntent(ctx, &entities.CreatePaymentIntentRequest{
+			Amount:          decimal.NewFromInt(1000),
+			Currency:        "usd",
+			PaymentMethodId: "pm_123",
+		}).Return(&entities.CreatePaymentIntentResponse{Id: "pi_123"}, nil)
+
+		resp, err := client.CreatePayment(ctx, providers.CreatePaymentRequest{Amount: decimal.NewFromInt(1000), Currency: "usd", PaymentMethodID: "pm_123"})
+		assert.NoError(t, err)
+		assert.Equal(t, "pi_123", resp.ID)
+	})
+
+	t.Run("Default payment method", func(t *testing.T) {
+		mockService.EXPECT().GetCustomerDefaultPaymentMethod(ctx, &customerId).Return("pm_default", nil)
+		mockService.EXPECT().CreatePaymentIntent(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, req *entities.CreatePaymentIntentRequest) (*entities.CreatePaymentIntentResponse, error) {
+				assert.Equal(t, "pm_default", req.PaymentMethodId)
+				return &entities.CreatePaymentIntentResponse{Id: "pi_123"}, nil
+			})
+
+		resp, err := client.CreatePayment(ctx, providers.CreatePaymentRequest{Amount: decimal.NewFromInt(1000), CustomerID: &customerId})
+		assert.NoError(t, err)
+		assert.Equal(t, "pi_123", resp.ID)
+		assert.Equal(t, "pm_default", resp.PaymentMethodID)
+	})
+
+	t.Run("No default payment method", func(t *testing.T) {
+		mockService.EXPECT().GetCustomerDefaultPaymentMethod(ctx, &customerId).Return("", nil)
+
+		_, err := client.CreatePayment(ctx, providers.CreatePaymentRequest{Amount: decimal.NewFromInt(1000), CustomerID: &customerId})
+		apiErr, ok := appErrors.IsAPIError(err)
+		assert.True(t, ok)
+		assert.Equal(t, "STRIPE_PAYMENT_METHOD_REQUIRED", apiErr.ErrorCode)
+	})
+
+	t.Run("Requires action", func(t *testing.T) {
+		expectedResp := &entities.CreatePaymentIntentResponse{
+			Id:             "pi_123",
+			Status:         entities.StripePaymentIntentRequiresAction,
+			ClientSecret:   "pi_123_secret_456",
+			NextActionType: "use_stripe_sdk",
+		}
+		mockService.EXPECT().CreatePaymentIntent(ctx, gomock.Any()).Return(expectedResp, nil)
+
+		resp, err := client.CreatePayment(ctx, req)
+		assert.NoError(t, err)
+		assert.Equal(t, providers.PaymentProviderResponse{
+			ID:              "pi_123",
+			Status:          providers.PaymentStatusRequiresAction,
+			PaymentMethodID: "pm_123",
+			NextAction: &providers.PaymentNextAction{
+				Type:         "use_stripe_sdk",
+				ClientSecret: "pi_123_secret_456",
+			},
+		}, resp)
+	})
+
 	t.Run("Payment intent authentication failure", func(t *testing.T) {
 		apiErr := &appErrors.APIError{Message: "Payment intent authentication failure"}
 		mockService.EXPECT().CreatePaymentIntent(ctx, gomock.Any()).Return(nil, apiErr)
@@ -105,3 +176,80 @@ func TestClient_CreatePaymentIntent(t *testing.T) {
 		assert.Empty(t, resp.ID)
 	})
 }
+
+func TestClient_GetPayment(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		status   string
+		expected providers.PaymentStatus
+	}{
+		{"Succeeded", entities.StripePaymentIntentSucceeded, providers.PaymentStatusSuccess},
+		{"Authorized", entities.StripePaymentIntentRequiresCapture, providers.PaymentStatusSuccess},
+		{"Processing", "processing", providers.PaymentStatusPending},
+		{"Requires action", entities.StripePaymentIntentRequiresAction, providers.PaymentStatusRequiresAction},
+		{"Declined", entities.StripePaymentIntentRequiresPaymentMethod, providers.PaymentStatusFailed},
+		{"Canceled", entities.StripePaymentIntentCanceled, providers.PaymentStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := service.NewMockService(gomock.NewController(t))
+			client := NewClient(mockService)
+
+			mockService.EXPECT().GetPaymentIntent(ctx, "pi_123").
+				Return(&entities.GetPaymentIntentResponse{Id: "pi_123", Status: tt.status}, nil)
+
+			resp, err := client.GetPayment(ctx, "pi_123")
+
+			assert.NoError(t, err)
+			assert.Equal(t, providers.PaymentProviderResponse{ID: "pi_123", Status: tt.expected}, resp)
+		})
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		mockService := service.NewMockService(gomock.NewController(t))
+		client := NewClient(mockService)
+
+		mockService.EXPECT().GetPaymentIntent(ctx, "pi_123").Return(nil, errors.New("stripe error"))
+
+		resp, err := client.GetPayment(ctx, "pi_123")
+
+		assert.Equal(t, providers.PaymentProviderResponse{}, resp)
+		assert.EqualError(t, err, "stripe error")
+	})
+}
+
+func TestClient_Refund(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := service.NewMockService(gomock.NewController(t))
+		client := NewClient(mockService)
+
+		mockService.EXPECT().Refund(ctx, &entities.RefundRequest{
+			PaymentIntentId: "pi_123",
+			Amount:          decimal.NewFromInt(10),
+			IdempotencyKey:  "key_123",
+		}).Return(&entities.RefundResponse{Id: "re_123", Status: "pending"}, nil)
+
+		resp, err := client.Refund(ctx, providers.RefundRequest{
+			PaymentID:      "pi_123",
+			Amount:         decimal.NewFromInt(10),
+			IdempotencyKey: "key_123",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &providers.RefundResponse{ID: "re_123", Status: "pending"}, resp)
+	})
+
+	t.Run("Missing payment intent", func(t *testing.T) {
+		client := NewClient(service.NewMockService(gomock.NewController(t)))
+
+		resp, err := client.Refund(ctx, providers.RefundRequest{})
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
@@ -36,6 +36,21 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// Capture mocks base method.
+func (m *MockClient) Capture(ctx context.Context, req providers.CaptureRequest) (providers.PaymentProviderResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capture", ctx, req)
+	ret0, _ := ret[0].(providers.PaymentProviderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Capture indicates an expected call of Capture.
+func (mr *MockClientMockRecorder) Capture(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockClient)(nil).Capture), ctx, req)
+}
+
 // CreateCustomer mocks base method.
 func (m *MockClient) CreateCustomer(ctx context.Context, req *entities.CreateCustomerRequest) (*entities.CreateCustomerResponse, error) {
 	m.ctrl.T.Helper()
@@ -52,7 +67,7 @@ func (mr *MockClientMockRecorder) CreateCustomer(ctx, req interface{}) *gomock.C
 }
 
 // CreatePayment mocks base method.
-func (m *MockClient) CreatePayment(ctx context.Context, req any) (providers.PaymentProviderResponse, error) {
+func (m *MockClient) CreatePayment(ctx context.Context, req providers.CreatePaymentRequest) (providers.PaymentProviderResponse, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreatePayment", ctx, req)
 	ret0, _ := ret[0].(providers.PaymentProviderResponse)
@@ -66,6 +81,35 @@ func (mr *MockClientMockRecorder) CreatePayment(ctx, req interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayment", reflect.TypeOf((*MockClient)(nil).CreatePayment), ctx, req)
 }
 
+// DetachPaymentMethod mocks base method.
+func (m *MockClient) DetachPaymentMethod(ctx context.Context, paymentMethodId *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetachPaymentMethod", ctx, paymentMethodId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DetachPaymentMethod indicates an expected call of DetachPaymentMethod.
+func (mr *MockClientMockRecorder) DetachPaymentMethod(ctx, paymentMethodId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachPaymentMethod", reflect.TypeOf((*MockClient)(nil).DetachPaymentMethod), ctx, paymentMethodId)
+}
+
+// GetCustomerDefaultPaymentMethod mocks base method.
+func (m *MockClient) GetCustomerDefaultPaymentMethod(ctx context.Context, customerId *string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomerDefaultPaymentMethod", ctx, customerId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCustomerDefaultPaymentMethod indicates an expected call of GetCustomerDefaultPaymentMethod.
+func (mr *MockClientMockRecorder) GetCustomerDefaultPaymentMethod(ctx, customerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerDefaultPaymentMethod", reflect.TypeOf((*MockClient)(nil).GetCustomerDefaultPaymentMethod), ctx, customerId)
+}
+
 // GetCustomerPaymentMethodById mocks base method.
 func (m *MockClient) GetCustomerPaymentMethodById(arg0 context.Context, customerId, paymentMethodId *string) (*entities.GetCustomerPaymentMethodResponse, error) {
 	m.ctrl.T.Helper()
@@ -96,6 +140,51 @@ func (mr *MockClientMockRecorder) GetCustomerPaymentMethods(ctx, customerId inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerPaymentMethods", reflect.TypeOf((*MockClient)(nil).GetCustomerPaymentMethods), ctx, customerId)
 }
 
+// GetDispute mocks base method.
+func (m *MockClient) GetDispute(ctx context.Context, disputeId string) (*entities.GetDisputeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDispute", ctx, disputeId)
+	ret0, _ := ret[0].(*entities.GetDisputeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDispute indicates an expected call of GetDispute.
+func (mr *MockClientMockRecorder) GetDispute(ctx, disputeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDispute", reflect.TypeOf((*MockClient)(nil).GetDispute), ctx, disputeId)
+}
+
+// GetPayment mocks base method.
+func (m *MockClient) GetPayment(ctx context.Context, paymentID string) (providers.PaymentProviderResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPayment", ctx, paymentID)
+	ret0, _ := ret[0].(providers.PaymentProviderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPayment indicates an expected call of GetPayment.
+func (mr *MockClientMockRecorder) GetPayment(ctx, paymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPayment", reflect.TypeOf((*MockClient)(nil).GetPayment), ctx, paymentID)
+}
+
+// GetPaymentDetails mocks base method.
+func (m *MockClient) GetPaymentDetails(ctx context.Context, paymentID string) (*providers.PaymentDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentDetails", ctx, paymentID)
+	ret0, _ := ret[0].(*providers.PaymentDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentDetails indicates an expected call of GetPaymentDetails.
+func (mr *MockClientMockRecorder) GetPaymentDetails(ctx, paymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentDetails", reflect.TypeOf((*MockClient)(nil).GetPaymentDetails), ctx, paymentID)
+}
+
 // GetProvider mocks base method.
 func (m *MockClient) GetProvider() providers.ProviderType {
 	m.ctrl.T.Helper()
@@ -125,6 +214,21 @@ func (mr *MockClientMockRecorder) GetRefund(ctx, refundId interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefund", reflect.TypeOf((*MockClient)(nil).GetRefund), ctx, refundId)
 }
 
+// GetRefundDetails mocks base method.
+func (m *MockClient) GetRefundDetails(ctx context.Context, refundID string) (*providers.RefundDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundDetails", ctx, refundID)
+	ret0, _ := ret[0].(*providers.RefundDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundDetails indicates an expected call of GetRefundDetails.
+func (mr *MockClientMockRecorder) GetRefundDetails(ctx, refundID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundDetails", reflect.TypeOf((*MockClient)(nil).GetRefundDetails), ctx, refundID)
+}
+
 // GetSetupIntent mocks base method.
 func (m *MockClient) GetSetupIntent(ctx context.Context, customerId *string) (*entities.GetSetupIntentResponse, error) {
 	m.ctrl.T.Helper()
@@ -156,7 +260,7 @@ func (mr *MockClientMockRecorder) GetWebhookEvent(ctx, req interface{}) *gomock.
 }
 
 // Refund mocks base method.
-func (m *MockClient) Refund(ctx context.Context, req any) (*providers.RefundResponse, error) {
+func (m *MockClient) Refund(ctx context.Context, req providers.RefundRequest) (*providers.RefundResponse, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Refund", ctx, req)
 	ret0, _ := ret[0].(*providers.RefundResponse)
@@ -169,3 +273,31 @@ func (mr *MockClientMockRecorder) Refund(ctx, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockClient)(nil).Refund), ctx, req)
 }
+
+// SetCustomerDefaultPaymentMethod mocks base method.
+func (m *MockClient) SetCustomerDefaultPaymentMethod(ctx context.Context, customerId, paymentMethodId *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCustomerDefaultPaymentMethod", ctx, customerId, paymentMethodId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCustomerDefaultPaymentMethod indicates an expected call of SetCustomerDefaultPaymentMethod.
+func (mr *MockClientMockRecorder) SetCustomerDefaultPaymentMethod(ctx, customerId, paymentMethodId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCustomerDefaultPaymentMethod", reflect.TypeOf((*MockClient)(nil).SetCustomerDefaultPaymentMethod), ctx, customerId, paymentMethodId)
+}
+
+// Void mocks base method.
+func (m *MockClient) Void(ctx context.Context, req providers.VoidRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Void", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Void indicates an expected call of Void.
+func (mr *MockClientMockRecorder) Void(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*MockClient)(nil).Void), ctx, req)
+}
@@ -7,3 +7,18 @@ const (
 	StripeRefundPending        = "pending"
 	StripeRefundRequiresAction = "requires_action"
 )
+
+const (
+	StripePaymentIntentSucceeded             = "succeeded"
+	StripePaymentIntentRequiresAction        = "requires_action"
+	StripePaymentIntentRequiresCapture       = "requires_capture"
+	StripePaymentIntentRequiresPaymentMethod = "requires_payment_method"
+	StripePaymentIntentCanceled              = "canceled"
+)
+
+const (
+	StripeDisputeWon  = "won"
+	StripeDisputeLost = "lost"
+	// StripeDisputeWarningClosed is used for inquiries closed without turning into a chargeback
+	StripeDisputeWarningClosed = "warning_closed"
+)
@@ -39,10 +39,52 @@ type CreatePaymentIntentRequest struct {
 	Currency        string
 	CustomerId      *string
 	PaymentMethodId string
+	// IdempotencyKey is sent to Stripe so retried requests don't create a second payment intent
+	IdempotencyKey string
+	// CaptureManually only authorizes the amount, it has to be captured with CapturePaymentIntent
+	CaptureManually bool
 }
 
 type CreatePaymentIntentResponse struct {
-	Id string `json:"id"`
+	Id     string `json:"id"`
+	Status string `json:"status"`
+	// ClientSecret lets the storefront complete the payment intent when it requires action
+	ClientSecret string `json:"client_secret"`
+	// NextActionType is the action the customer has to take, e.g. use_stripe_sdk for 3-D Secure
+	NextActionType string `json:"next_action_type,omitempty"`
+	// RedirectURL is set when the customer has to be redirected to authenticate the payment
+	RedirectURL string `json:"redirect_url,omitempty"`
+}
+
+// CapturePaymentIntentRequest captures an authorized payment intent, a zero amount captures the whole amount
+type CapturePaymentIntentRequest struct {
+	PaymentIntentId string
+	Amount          decimal.Decimal
+	// IdempotencyKey is sent to Stripe so retried requests don't capture the payment twice
+	IdempotencyKey string
+}
+
+type CapturePaymentIntentResponse struct {
+	Id     string
+	Status string
+	// AmountReceived is the amount that was captured
+	AmountReceived decimal.Decimal
+}
+
+type GetPaymentIntentResponse struct {
+	Id             string
+	Status         string
+	Amount         decimal.Decimal
+	AmountReceived decimal.Decimal
+	// AmountRefunded is the amount refunded from the latest charge of the payment intent
+	AmountRefunded decimal.Decimal
+}
+
+// CancelPaymentIntentRequest cancels a payment intent, releasing the funds held for it
+type CancelPaymentIntentRequest struct {
+	PaymentIntentId string
+	// IdempotencyKey is sent to Stripe so retried requests are not rejected
+	IdempotencyKey string
 }
 
 type HandleWebhookEventRequest struct {
@@ -51,6 +93,7 @@ type HandleWebhookEventRequest struct {
 }
 
 type HandleWebhookEventResponse struct {
+	EventId  string
 	ObjectId string
 	Type     string
 }
@@ -62,6 +105,8 @@ type GetCustomerPaymentMethodResponse struct {
 type RefundRequest struct {
 	PaymentIntentId string          `json:"payment_intent_id"`
 	Amount          decimal.Decimal `json:"amount"`
+	// IdempotencyKey is sent to Stripe so retried requests don't refund the payment twice
+	IdempotencyKey string `json:"-"`
 }
 
 type RefundResponse struct {
@@ -75,5 +120,20 @@ type RefundResponse struct {
 	// Learn more about [failed refunds](https://stripe.com/docs/refunds#failed-refunds).
 	Status string
 	Reason string
+	// FailureReason tells why the refund failed, e.g. `expired_or_canceled_card`
+	FailureReason string
 	// TODO Add destination details if needed
 }
+
+type GetDisputeResponse struct {
+	Id string
+	// PaymentIntentId of the disputed payment, empty for charges made without a payment intent
+	PaymentIntentId string
+	// Amount disputed
+	Amount   decimal.Decimal
+	Currency string
+	// Reason given by the cardholder, e.g. `fraudulent` or `product_not_received`
+	Reason string
+	// Status of the dispute, e.g. `needs_response`, `under_review`, `won` or `lost`
+	Status string
+}
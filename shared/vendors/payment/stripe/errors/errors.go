@@ -15,12 +15,20 @@ var moduleErrors = map[string]struct {
 	"STRIPE_UNABLE_TO_CREATE_USER":                      {StatusCode: http.StatusBadRequest, Message: "Unable to create user."},
 	"STRIPE_UNABLE_TO_FETCH_PAYMENT_METHODS":            {StatusCode: http.StatusInternalServerError, Message: "Unable to fetch payment methods."},
 	"STRIPE_UNABLE_TO_FETCH_PAYMENT_METHOD":             {StatusCode: http.StatusInternalServerError, Message: "Unable to fetch payment method."},
+	"STRIPE_UNABLE_TO_FETCH_CUSTOMER":                   {StatusCode: http.StatusInternalServerError, Message: "Unable to fetch customer."},
+	"STRIPE_UNABLE_TO_SET_DEFAULT_PAYMENT_METHOD":       {StatusCode: http.StatusInternalServerError, Message: "Unable to set default payment method."},
+	"STRIPE_UNABLE_TO_DETACH_PAYMENT_METHOD":            {StatusCode: http.StatusInternalServerError, Message: "Unable to detach payment method."},
+	"STRIPE_PAYMENT_METHOD_REQUIRED":                    {StatusCode: http.StatusBadRequest, Message: "Payment method is required, the customer has no default payment method."},
 	"STRIPE_UNABLE_TO_CREATE_SETUP_INTENT":              {StatusCode: http.StatusInternalServerError, Message: "Unable to create setup intent."},
 	"STRIPE_PAYMENT_INTENT_AUTHENTICATION_FAILURE":      {StatusCode: http.StatusBadRequest, Message: "Payment intent authentication failure."},
 	"STRIPE_PAYMENT_INTENT_INVALID_PARAMETER":           {StatusCode: http.StatusBadRequest, Message: "Payment intent invalid parameter."},
 	"STRIPE_PAYMENT_INTENT_INCOMPATIBLE_PAYMENT_METHOD": {StatusCode: http.StatusBadRequest, Message: "Payment intent incompatible payment method."},
 	"STRIPE_PAYMENT_INTENT_ERROR":                       {StatusCode: http.StatusInternalServerError, Message: "Unable to create payment intent."},
 	"STRIPE_FAILED_TO_FETCH_EPHEMERAL_KEY":              {StatusCode: http.StatusInternalServerError, Message: "Failed to fetch ephemeral key."},
+	"STRIPE_UNABLE_TO_CAPTURE_PAYMENT_INTENT":           {StatusCode: http.StatusInternalServerError, Message: "Unable to capture payment intent."},
+	"STRIPE_UNABLE_TO_CANCEL_PAYMENT_INTENT":            {StatusCode: http.StatusInternalServerError, Message: "Unable to cancel payment intent."},
+	"STRIPE_UNABLE_TO_RETRIEVE_PAYMENT_INTENT":          {StatusCode: http.StatusInternalServerError, Message: "Unable to retrieve payment intent."},
+	"STRIPE_UNABLE_TO_RETRIEVE_DISPUTE":                 {StatusCode: http.StatusInternalServerError, Message: "Unable to retrieve dispute."},
 }
 
 func NewAPIError(errorCode string, customMessage ...string) *errors.APIError {
@@ -35,6 +35,35 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// CancelPaymentIntent mocks base method.
+func (m *MockService) CancelPaymentIntent(ctx context.Context, req *entities.CancelPaymentIntentRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelPaymentIntent", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelPaymentIntent indicates an expected call of CancelPaymentIntent.
+func (mr *MockServiceMockRecorder) CancelPaymentIntent(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelPaymentIntent", reflect.TypeOf((*MockService)(nil).CancelPaymentIntent), ctx, req)
+}
+
+// CapturePaymentIntent mocks base method.
+func (m *MockService) CapturePaymentIntent(ctx context.Context, req *entities.CapturePaymentIntentRequest) (*entities.CapturePaymentIntentResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CapturePaymentIntent", ctx, req)
+	ret0, _ := ret[0].(*entities.CapturePaymentIntentResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CapturePaymentIntent indicates an expected call of CapturePaymentIntent.
+func (mr *MockServiceMockRecorder) CapturePaymentIntent(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CapturePaymentIntent", reflect.TypeOf((*MockService)(nil).CapturePaymentIntent), ctx, req)
+}
+
 // CreateCustomer mocks base method.
 func (m *MockService) CreateCustomer(ctx context.Context, req *entities.CreateCustomerRequest) (*entities.CreateCustomerResponse, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +94,35 @@ func (mr *MockServiceMockRecorder) CreatePaymentIntent(ctx, req interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentIntent", reflect.TypeOf((*MockService)(nil).CreatePaymentIntent), ctx, req)
 }
 
+// DetachPaymentMethod mocks base method.
+func (m *MockService) DetachPaymentMethod(arg0 context.Context, paymentMethodId *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetachPaymentMethod", arg0, paymentMethodId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DetachPaymentMethod indicates an expected call of DetachPaymentMethod.
+func (mr *MockServiceMockRecorder) DetachPaymentMethod(arg0, paymentMethodId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachPaymentMethod", reflect.TypeOf((*MockService)(nil).DetachPaymentMethod), arg0, paymentMethodId)
+}
+
+// GetCustomerDefaultPaymentMethod mocks base method.
+func (m *MockService) GetCustomerDefaultPaymentMethod(arg0 context.Context, customerId *string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomerDefaultPaymentMethod", arg0, customerId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCustomerDefaultPaymentMethod indicates an expected call of GetCustomerDefaultPaymentMethod.
+func (mr *MockServiceMockRecorder) GetCustomerDefaultPaymentMethod(arg0, customerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerDefaultPaymentMethod", reflect.TypeOf((*MockService)(nil).GetCustomerDefaultPaymentMethod), arg0, customerId)
+}
+
 // GetCustomerPaymentMethodById mocks base method.
 func (m *MockService) GetCustomerPaymentMethodById(arg0 context.Context, customerId, paymentMethodId *string) (*entities.GetCustomerPaymentMethodResponse, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +153,36 @@ func (mr *MockServiceMockRecorder) GetCustomerPaymentMethods(arg0, customerId in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerPaymentMethods", reflect.TypeOf((*MockService)(nil).GetCustomerPaymentMethods), arg0, customerId)
 }
 
+// GetDispute mocks base method.
+func (m *MockService) GetDispute(ctx context.Context, disputeId string) (*entities.GetDisputeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDispute", ctx, disputeId)
+	ret0, _ := ret[0].(*entities.GetDisputeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDispute indicates an expected call of GetDispute.
+func (mr *MockServiceMockRecorder) GetDispute(ctx, disputeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDispute", reflect.TypeOf((*MockService)(nil).GetDispute), ctx, disputeId)
+}
+
+// GetPaymentIntent mocks base method.
+func (m *MockService) GetPaymentIntent(ctx context.Context, paymentIntentId string) (*entities.GetPaymentIntentResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentIntent", ctx, paymentIntentId)
+	ret0, _ := ret[0].(*entities.GetPaymentIntentResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentIntent indicates an expected call of GetPaymentIntent.
+func (mr *MockServiceMockRecorder) GetPaymentIntent(ctx, paymentIntentId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentIntent", reflect.TypeOf((*MockService)(nil).GetPaymentIntent), ctx, paymentIntentId)
+}
+
 // GetRefund mocks base method.
 func (m *MockService) GetRefund(ctx context.Context, refundId string) (*entities.RefundResponse, error) {
 	m.ctrl.T.Helper()
@@ -154,3 +242,17 @@ func (mr *MockServiceMockRecorder) Refund(arg0, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockService)(nil).Refund), arg0, req)
 }
+
+// SetCustomerDefaultPaymentMethod mocks base method.
+func (m *MockService) SetCustomerDefaultPaymentMethod(arg0 context.Context, customerId, paymentMethodId *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCustomerDefaultPaymentMethod", arg0, customerId, paymentMethodId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCustomerDefaultPaymentMethod indicates an expected call of SetCustomerDefaultPaymentMethod.
+func (mr *MockServiceMockRecorder) SetCustomerDefaultPaymentMethod(arg0, customerId, paymentMethodId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCustomerDefaultPaymentMethod", reflect.TypeOf((*MockService)(nil).SetCustomerDefaultPaymentMethod), arg0, customerId, paymentMethodId)
+}
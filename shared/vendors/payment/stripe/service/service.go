@@ -11,8 +11,10 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/stripe/stripe-go/v81"
 	"github.com/stripe/stripe-go/v81/customer"
+	"github.com/stripe/stripe-go/v81/dispute"
 	"github.com/stripe/stripe-go/v81/ephemeralkey"
 	"github.com/stripe/stripe-go/v81/paymentintent"
+	"github.com/stripe/stripe-go/v81/paymentmethod"
 	"github.com/stripe/stripe-go/v81/refund"
 	"github.com/stripe/stripe-go/v81/setupintent"
 	"github.com/stripe/stripe-go/v81/webhook"
@@ -23,11 +25,18 @@ type Service interface {
 	CreateCustomer(ctx context.Context, req *entities.CreateCustomerRequest) (*entities.CreateCustomerResponse, error)
 	GetCustomerPaymentMethods(_ context.Context, customerId *string) (*entities.GetCustomerPaymentMethodsResponse, error)
 	GetCustomerPaymentMethodById(_ context.Context, customerId, paymentMethodId *string) (*entities.GetCustomerPaymentMethodResponse, error)
+	GetCustomerDefaultPaymentMethod(_ context.Context, customerId *string) (string, error)
+	SetCustomerDefaultPaymentMethod(_ context.Context, customerId, paymentMethodId *string) error
+	DetachPaymentMethod(_ context.Context, paymentMethodId *string) error
 	GetSetupIntent(ctx context.Context, customerId *string) (*entities.GetSetupIntentResponse, error)
 	CreatePaymentIntent(ctx context.Context, req *entities.CreatePaymentIntentRequest) (*entities.CreatePaymentIntentResponse, error)
+	CapturePaymentIntent(ctx context.Context, req *entities.CapturePaymentIntentRequest) (*entities.CapturePaymentIntentResponse, error)
+	CancelPaymentIntent(ctx context.Context, req *entities.CancelPaymentIntentRequest) error
+	GetPaymentIntent(ctx context.Context, paymentIntentId string) (*entities.GetPaymentIntentResponse, error)
 	GetWebhookEvent(_ context.Context, req *entities.HandleWebhookEventRequest) (*entities.HandleWebhookEventResponse, error)
 	Refund(_ context.Context, req *entities.RefundRequest) (*entities.RefundResponse, error)
 	GetRefund(ctx context.Context, refundId string) (*entities.RefundResponse, error)
+	GetDispute(ctx context.Context, disputeId string) (*entities.GetDisputeResponse, error)
 }
 
 func New(config stripeConfig.Config, logger *zap.SugaredLogger) (Service, error) {
@@ -182,10 +191,20 @@ func (s *service) CreatePaymentIntent(_ context.Context, req *entities.CreatePay
 	params := &stripe.PaymentIntentParams{
 		Amount:        stripe.Int64(integerAmount),
 		Currency:      stripe.String(req.Currency),
-		Customer:      stripe.String(*req.CustomerId),
 		PaymentMethod: stripe.String(req.PaymentMethodId),
 		Confirm:       stripe.Bool(true),
-		OffSession:    stripe.Bool(true),
+		// the customer is at checkout and can authenticate the payment (3-D Secure) if the bank asks for it
+		OffSession: stripe.Bool(false),
+	}
+	// guest payments aren't attached to a Stripe customer
+	if req.CustomerId != nil {
+		params.Customer = stripe.String(*req.CustomerId)
+	}
+	if req.CaptureManually {
+		params.CaptureMethod = stripe.String(string(stripe.PaymentIntentCaptureMethodManual))
+	}
+	if req.IdempotencyKey != "" {
+		params.SetIdempotencyKey(req.IdempotencyKey)
 	}
 	paymentIntent, err := paymentintent.New(params)
 	if err != nil {
@@ -208,13 +227,120 @@ func (s *service) CreatePaymentIntent(_ context.Context, req *entities.CreatePay
 	}
 
 	resp := &entities.CreatePaymentIntentResponse{
-		Id: paymentIntent.ID,
+		Id:           paymentIntent.ID,
+		Status:       string(paymentIntent.Status),
+		ClientSecret: paymentIntent.ClientSecret,
+	}
+	if paymentIntent.NextAction != nil {
+		resp.NextActionType = string(paymentIntent.NextAction.Type)
+		if paymentIntent.NextAction.RedirectToURL != nil {
+			resp.RedirectURL = paymentIntent.NextAction.RedirectToURL.URL
+		}
 	}
 
 	s.logger.Info("Payment intent created successfully:", resp)
 	return resp, nil
 }
 
+func (s *service) CapturePaymentIntent(_ context.Context, req *entities.CapturePaymentIntentRequest) (*entities.CapturePaymentIntentResponse, error) {
+	stripe.Key = s.config.Key
+
+	params := &stripe.PaymentIntentCaptureParams{}
+	if req.Amount.GreaterThan(decimal.Zero) {
+		// Convert the amount to the smallest currency unit (e.g., cents for USD)
+		integerAmount := req.Amount.Mul(decimal.NewFromInt(100)).IntPart()
+		params.AmountToCapture = stripe.Int64(integerAmount)
+	}
+	if req.IdempotencyKey != "" {
+		params.SetIdempotencyKey(req.IdempotencyKey)
+	}
+
+	paymentIntent, err := paymentintent.Capture(req.PaymentIntentId, params)
+	if err != nil {
+		s.logger.Error("Failed to capture payment intent from stripe-api:", err)
+		var stripeErr *stripe.Error
+		if errors.As(err, &stripeErr) {
+			switch stripeErr.Type {
+			case stripe.ErrorTypeInvalidRequest:
+				return nil, moduleErrors.NewAPIError("STRIPE_ERROR", stripeErr.Msg)
+			case stripe.ErrorTypeAPI:
+				return nil, moduleErrors.NewAPIError("STRIPE_ERROR", stripeErr.Msg)
+			}
+		}
+		return nil, moduleErrors.NewAPIError("STRIPE_UNABLE_TO_CAPTURE_PAYMENT_INTENT")
+	}
+
+	s.logger.Info("Payment intent captured successfully:", paymentIntent.ID)
+	return &entities.CapturePaymentIntentResponse{
+		Id:             paymentIntent.ID,
+		Status:         string(paymentIntent.Status),
+		AmountReceived: decimal.NewFromInt(paymentIntent.AmountReceived).Div(decimal.NewFromInt(100)),
+	}, nil
+}
+
+func (s *service) CancelPaymentIntent(_ context.Context, req *entities.CancelPaymentIntentRequest) error {
+	stripe.Key = s.config.Key
+
+	params := &stripe.PaymentIntentCancelParams{
+		CancellationReason: stripe.String(string(stripe.PaymentIntentCancellationReasonRequestedByCustomer)),
+	}
+	if req.IdempotencyKey != "" {
+		params.SetIdempotencyKey(req.IdempotencyKey)
+	}
+
+	paymentIntent, err := paymentintent.Cancel(req.PaymentIntentId, params)
+	if err != nil {
+		s.logger.Error("Failed to cancel payment intent from stripe-api:", err)
+		var stripeErr *stripe.Error
+		if errors.As(err, &stripeErr) {
+			switch stripeErr.Type {
+			case stripe.ErrorTypeInvalidRequest:
+				return moduleErrors.NewAPIError("STRIPE_ERROR", stripeErr.Msg)
+			case stripe.ErrorTypeAPI:
+				return moduleErrors.NewAPIError("STRIPE_ERROR", stripeErr.Msg)
+			}
+		}
+		return moduleErrors.NewAPIError("STRIPE_UNABLE_TO_CANCEL_PAYMENT_INTENT")
+	}
+
+	s.logger.Info("Payment intent cancelled successfully:", paymentIntent.ID)
+	return nil
+}
+
+func (s *service) GetPaymentIntent(_ context.Context, paymentIntentId string) (*entities.GetPaymentIntentResponse, error) {
+	stripe.Key = s.config.Key
+
+	params := &stripe.PaymentIntentParams{}
+	params.AddExpand("latest_charge")
+
+	paymentIntent, err := paymentintent.Get(paymentIntentId, params)
+	if err != nil {
+		s.logger.Error("Failed to retrieve payment intent from stripe-api:", err)
+		var stripeErr *stripe.Error
+		if errors.As(err, &stripeErr) {
+			switch stripeErr.Type {
+			case stripe.ErrorTypeInvalidRequest:
+				return nil, moduleErrors.NewAPIError("STRIPE_ERROR", stripeErr.Msg)
+			case stripe.ErrorTypeAPI:
+				return nil, moduleErrors.NewAPIError("STRIPE_ERROR", stripeErr.Msg)
+			}
+		}
+		return nil, moduleErrors.NewAPIError("STRIPE_UNABLE_TO_RETRIEVE_PAYMENT_INTENT")
+	}
+
+	resp := &entities.GetPaymentIntentResponse{
+		Id:             paymentIntent.ID,
+		Status:         string(paymentIntent.Status),
+		Amount:         decimal.NewFromInt(paymentIntent.Amount).Div(decimal.NewFromInt(100)),
+		AmountReceived: decimal.NewFromInt(paymentIntent.AmountReceived).Div(decimal.NewFromInt(100)),
+	}
+	if paymentIntent.LatestCharge != nil {
+		resp.AmountRefunded = decimal.NewFromInt(paymentIntent.LatestCharge.AmountRefunded).Div(decimal.NewFromInt(100))
+	}
+
+	return resp, nil
+}
+
 func (s *service) GetWebhookEvent(_ context.Context, req *entities.HandleWebhookEventRequest) (*entities.HandleWebhookEventResponse, error) {
 	event := stripe.Event{}
 
@@ -229,7 +355,7 @@ func (s *service) GetWebhookEvent(_ context.Context, req *entities.HandleWebhook
 	}
 
 	switch event.Type {
-	case "payment_intent.succeeded", "payment_intent.payment_failed":
+	case "payment_intent.succeeded", "payment_intent.payment_failed", "payment_intent.amount_capturable_updated":
 		var paymentIntent stripe.PaymentIntent
 		err = json.Unmarshal(event.Data.Raw, &paymentIntent)
 		if err != nil {
@@ -237,6 +363,7 @@ func (s *service) GetWebhookEvent(_ context.Context, req *entities.HandleWebhook
 			return nil, err
 		}
 		return &entities.HandleWebhookEventResponse{
+			EventId:  event.ID,
 			ObjectId: paymentIntent.ID,
 			Type:     string(event.Type),
 		}, nil
@@ -248,6 +375,7 @@ func (s *service) GetWebhookEvent(_ context.Context, req *entities.HandleWebhook
 			return nil, err
 		}
 		return &entities.HandleWebhookEventResponse{
+			EventId:  event.ID,
 			ObjectId: refund.ID,
 			Type:     string(event.Type),
 		}, nil
@@ -295,6 +423,84 @@ func (s *service) GetCustomerPaymentMethodById(_ context.Context, customerId, pa
 	return resp, nil
 }
 
+// GetCustomerDefaultPaymentMethod returns the ID of the default payment method of the customer, empty when there is none.
+func (s *service) GetCustomerDefaultPaymentMethod(_ context.Context, customerId *string) (string, error) {
+	stripe.Key = s.config.Key
+
+	result, err := customer.Get(*customerId, nil)
+	if err != nil {
+		s.logger.Error("Failed to fetch customer from stripe-api:", err)
+		var stripeErr *stripe.Error
+		if errors.As(err, &stripeErr) {
+			switch stripeErr.Type {
+			case stripe.ErrorTypeInvalidRequest:
+				return "", moduleErrors.NewAPIError("STRIPE_ERROR", stripeErr.Msg)
+			case stripe.ErrorTypeAPI:
+				return "", moduleErrors.NewAPIError("STRIPE_ERROR", stripeErr.Msg)
+			}
+		}
+		return "", moduleErrors.NewAPIError("STRIPE_UNABLE_TO_FETCH_CUSTOMER")
+	}
+
+	if result.InvoiceSettings == nil || result.InvoiceSettings.DefaultPaymentMethod == nil {
+		return "", nil
+	}
+
+	return result.InvoiceSettings.DefaultPaymentMethod.ID, nil
+}
+
+// SetCustomerDefaultPaymentMethod makes a payment method attached to the customer their default one.
+func (s *service) SetCustomerDefaultPaymentMethod(_ context.Context, customerId, paymentMethodId *string) error {
+	stripe.Key = s.config.Key
+
+	params := &stripe.CustomerParams{
+		InvoiceSettings: &stripe.CustomerInvoiceSettingsParams{
+			DefaultPaymentMethod: stripe.String(*paymentMethodId),
+		},
+	}
+
+	_, err := customer.Update(*customerId, params)
+	if err != nil {
+		s.logger.Error("Failed to set default payment method in stripe-api:", err)
+		var stripeErr *stripe.Error
+		if errors.As(err, &stripeErr) {
+			switch stripeErr.Type {
+			case stripe.ErrorTypeInvalidRequest:
+				return moduleErrors.NewAPIError("STRIPE_ERROR", stripeErr.Msg)
+			case stripe.ErrorTypeAPI:
+				return moduleErrors.NewAPIError("STRIPE_ERROR", stripeErr.Msg)
+			}
+		}
+		return moduleErrors.NewAPIError("STRIPE_UNABLE_TO_SET_DEFAULT_PAYMENT_METHOD")
+	}
+
+	s.logger.Info("Default payment method set for customer ID:", *customerId)
+	return nil
+}
+
+// DetachPaymentMethod removes a payment method from the customer it's attached to, it can't be used again.
+func (s *service) DetachPaymentMethod(_ context.Context, paymentMethodId *string) error {
+	stripe.Key = s.config.Key
+
+	_, err := paymentmethod.Detach(*paymentMethodId, nil)
+	if err != nil {
+		s.logger.Error("Failed to detach payment method in stripe-api:", err)
+		var stripeErr *stripe.Error
+		if errors.As(err, &stripeErr) {
+			switch stripeErr.Type {
+			case stripe.ErrorTypeInvalidRequest:
+				return moduleErrors.NewAPIError("STRIPE_ERROR", stripeErr.Msg)
+			case stripe.ErrorTypeAPI:
+				return moduleErrors.NewAPIError("STRIPE_ERROR", stripeErr.Msg)
+			}
+		}
+		return moduleErrors.NewAPIError("STRIPE_UNABLE_TO_DETACH_PAYMENT_METHOD")
+	}
+
+	s.logger.Info("Payment method detached:", *paymentMethodId)
+	return nil
+}
+
 func (s *service) Refund(_ context.Context, req *entities.RefundRequest) (*entities.RefundResponse, error) {
 	stripe.Key = s.config.Key
 
@@ -312,6 +518,10 @@ func (s *service) Refund(_ context.Context, req *entities.RefundRequest) (*entit
 		s.logger.Info("Refunding full payment intent without amount specified")
 	}
 
+	if req.IdempotencyKey != "" {
+		params.SetIdempotencyKey(req.IdempotencyKey)
+	}
+
 	res, err := refund.New(params)
 	if err != nil {
 		s.logger.Error("Failed to refund payment intent from stripe-api:", err)
@@ -357,10 +567,43 @@ func (s *service) GetRefund(ctx context.Context, refundId string) (*entities.Ref
 	}
 
 	resp := &entities.RefundResponse{
-		Id:       refund.ID,
-		Amount:   decimal.NewFromInt(refund.Amount).Div(decimal.NewFromInt(100)),
-		Currency: string(refund.Currency),
-		Status:   string(refund.Status),
+		Id:            refund.ID,
+		Amount:        decimal.NewFromInt(refund.Amount).Div(decimal.NewFromInt(100)),
+		Currency:      string(refund.Currency),
+		Status:        string(refund.Status),
+		Reason:        string(refund.Reason),
+		FailureReason: string(refund.FailureReason),
+	}
+
+	return resp, nil
+}
+
+func (s *service) GetDispute(_ context.Context, disputeId string) (*entities.GetDisputeResponse, error) {
+	stripe.Key = s.config.Key
+
+	res, err := dispute.Get(disputeId, nil)
+	if err != nil {
+		s.logger.Error("Failed to retrieve dispute from stripe-api:", err)
+		var stripeErr *stripe.Error
+		if errors.As(err, &stripeErr) {
+			switch stripeErr.Type {
+			case stripe.ErrorTypeInvalidRequest:
+				return nil, moduleErrors.NewAPIError("STRIPE_ERROR", stripeErr.Msg)
+			}
+		}
+		return nil, moduleErrors.NewAPIError("STRIPE_UNABLE_TO_RETRIEVE_DISPUTE")
+	}
+
+	resp := &entities.GetDisputeResponse{
+		Id:       res.ID,
+		Amount:   decimal.NewFromInt(res.Amount).Div(decimal.NewFromInt(100)),
+		Currency: string(res.Currency),
+		Reason:   string(res.Reason),
+		Status:   string(res.Status),
+	}
+	// disputes of charges made outside of payment intents are not linked to any order
+	if res.PaymentIntent != nil {
+		resp.PaymentIntentId = res.PaymentIntent.ID
 	}
 
 	return resp, nil
@@ -2,6 +2,7 @@
 package shipping
 
 import (
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/packaging"
 	shipengineConfig "github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/providers/shipengine/config"
 	upsConfig "github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/providers/ups/config"
 	"github.com/pkg/errors"
@@ -18,10 +19,15 @@ type Config struct {
 	Provider   string
 	Shipengine shipengineConfig.Config
 	UPS        upsConfig.Config
+	Packaging  packaging.Config
 }
 
 // Validate config.
 func (c *Config) Validate() error {
+	if err := c.Packaging.Validate(); err != nil {
+		return err
+	}
+
 	switch c.Provider {
 	case "", ProviderNone:
 		return nil
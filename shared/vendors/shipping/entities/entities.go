@@ -17,7 +17,8 @@ type Address struct {
 type Shipment struct {
 	Origin      Address
 	Destination Address
-	Dimensions  Dimensions
+	// Packages are the parcels the shipment is made of, quoted as a multi-piece shipment
+	Packages []Dimensions
 }
 
 type Dimensions struct {
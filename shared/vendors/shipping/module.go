@@ -5,6 +5,7 @@ import (
 
 	"github.com/nurdsoft/nurd-commerce-core/shared/cache"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/client"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/packaging"
 	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/providers/fakeprovider"
 	shipengineClient "github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/providers/shipengine/client"
 	shipengineService "github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/providers/shipengine/service"
@@ -50,7 +51,12 @@ func NewModule(p ModuleParams) (client.Client, error) {
 	}
 }
 
+// NewPacker packs the shipments in the configured boxes
+func NewPacker(config Config) packaging.Packer {
+	return packaging.New(config.Packaging)
+}
+
 var (
 	// Module for uber fx.
-	Module = fx.Options(fx.Provide(NewModule))
+	Module = fx.Options(fx.Provide(NewModule, NewPacker))
 )
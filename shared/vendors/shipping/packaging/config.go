@@ -0,0 +1,49 @@
+package packaging
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Config lists the boxes the warehouse ships in. Without boxes the items of a shipment are stacked in a single package.
+// MaxPackages limits the packages a shipment is quoted for, 20 when not set.
+type Config struct {
+	Boxes       []Box
+	MaxPackages int
+}
+
+// Box is a shipping box, its dimensions are in inches and its max weight in pounds.
+type Box struct {
+	Name      string
+	Length    float64
+	Width     float64
+	Height    float64
+	MaxWeight float64
+}
+
+// Validate config
+func (c *Config) Validate() error {
+	var errs []string
+
+	for i, box := range c.Boxes {
+		if box.Length <= 0 || box.Width <= 0 || box.Height <= 0 {
+			errs = append(errs, fmt.Sprintf("packaging box %d dimensions should be greater than zero", i))
+		}
+
+		if box.MaxWeight <= 0 {
+			errs = append(errs, fmt.Sprintf("packaging box %d maxweight should be greater than zero", i))
+		}
+	}
+
+	if c.MaxPackages < 0 {
+		errs = append(errs, "packaging maxpackages should not be negative")
+	}
+
+	if len(errs) > 0 {
+		return errors.Errorf("%s", strings.Join(errs, ","))
+	}
+
+	return nil
+}
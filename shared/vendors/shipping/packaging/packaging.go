@@ -0,0 +1,309 @@
+// Package packaging works out the packages the items of a shipment are shipped in.
+package packaging
+
+import (
+	"errors"
+	"sort"
+
+	sharedDecimal "github.com/nurdsoft/nurd-commerce-core/shared/decimal"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/entities"
+	"github.com/shopspring/decimal"
+)
+
+// Item is a line of the shipment, its dimensions are in inches and its weight in pounds.
+type Item struct {
+	Length   decimal.Decimal
+	Width    decimal.Decimal
+	Height   decimal.Decimal
+	Weight   decimal.Decimal
+	Quantity int
+}
+
+// ErrTooManyPackages is returned when the items need more packages than the configured maximum.
+var ErrTooManyPackages = errors.New("too many packages")
+
+// defaultMaxPackages is the number of packages a shipment is limited to when the config doesn't set one.
+const defaultMaxPackages = 20
+
+type Packer interface {
+	Pack(items []Item) ([]entities.Dimensions, error)
+}
+
+func New(config Config) Packer {
+	boxes := make([]box, 0, len(config.Boxes))
+	for _, b := range config.Boxes {
+		length := decimal.NewFromFloat(b.Length)
+		width := decimal.NewFromFloat(b.Width)
+		height := decimal.NewFromFloat(b.Height)
+
+		boxes = append(boxes, box{
+			dimensions: entities.Dimensions{Length: length, Width: width, Height: height},
+			sides:      sortedSides(length, width, height),
+			volume:     length.Mul(width).Mul(height),
+			maxWeight:  decimal.NewFromFloat(b.MaxWeight),
+		})
+	}
+
+	// smallest box first
+	sort.SliceStable(boxes, func(i, j int) bool {
+		return boxes[i].volume.LessThan(boxes[j].volume)
+	})
+
+	maxPackages := config.MaxPackages
+	if maxPackages == 0 {
+		maxPackages = defaultMaxPackages
+	}
+
+	return &packer{boxes: boxes, maxPackages: maxPackages}
+}
+
+type packer struct {
+	boxes       []box
+	maxPackages int
+}
+
+type box struct {
+	dimensions entities.Dimensions
+	sides      [3]decimal.Decimal
+	volume     decimal.Decimal
+	maxWeight  decimal.Decimal
+}
+
+// unit is a piece of an item, quantity tells how many identical pieces the item has.
+type unit struct {
+	dimensions entities.Dimensions
+	sides      [3]decimal.Decimal
+	volume     decimal.Decimal
+	weight     decimal.Decimal
+	quantity   int
+}
+
+type parcel struct {
+	box    *box
+	units  []unit
+	volume decimal.Decimal
+	weight decimal.Decimal
+}
+
+// Pack bin-packs the items into the configured boxes, first fit by decreasing volume. A box is filled while the
+// volume and the weight of its contents are within its volume and max weight, each piece has to fit in the box
+// in some orientation. Each package is then shrunk to the smallest box its contents fit in. A piece no box holds
+// is shipped in its own packaging. Identical pieces are packed in batches, and packing stops with
+// ErrTooManyPackages as soon as the pieces need more packages than allowed.
+func (p *packer) Pack(items []Item) ([]entities.Dimensions, error) {
+	units := toUnits(items)
+	if len(units) == 0 {
+		return nil, nil
+	}
+
+	if len(p.boxes) == 0 {
+		return []entities.Dimensions{stack(units)}, nil
+	}
+
+	sort.SliceStable(units, func(i, j int) bool {
+		if !units[i].volume.Equal(units[j].volume) {
+			return units[i].volume.GreaterThan(units[j].volume)
+		}
+		return units[i].weight.GreaterThan(units[j].weight)
+	})
+
+	var parcels []*parcel
+	var packages []entities.Dimensions
+
+	for _, u := range units {
+		left := u.quantity - place(parcels, u, u.quantity)
+
+		b := p.largestBoxFor(u)
+		if b == nil && left > 0 {
+			if len(parcels)+len(packages)+left > p.maxPackages {
+				return nil, ErrTooManyPackages
+			}
+			for i := 0; i < left; i++ {
+				packages = append(packages, u.dimensions)
+			}
+			continue
+		}
+
+		for left > 0 {
+			if len(parcels)+len(packages) >= p.maxPackages {
+				return nil, ErrTooManyPackages
+			}
+
+			pc := &parcel{box: b}
+			parcels = append(parcels, pc)
+			left -= place([]*parcel{pc}, u, left)
+		}
+	}
+
+	packed := make([]entities.Dimensions, 0, len(parcels)+len(packages))
+	for _, pc := range parcels {
+		b := p.smallestBoxFor(pc)
+		packed = append(packed, entities.Dimensions{
+			Length: b.dimensions.Length,
+			Width:  b.dimensions.Width,
+			Height: b.dimensions.Height,
+			Weight: pc.weight,
+		})
+	}
+
+	return append(packed, packages...), nil
+}
+
+// place adds up to quantity pieces of the unit to the parcels with room for them, the first ones first.
+// It returns the number of pieces placed.
+func place(parcels []*parcel, u unit, quantity int) int {
+	placed := 0
+	for _, pc := range parcels {
+		if placed == quantity {
+			break
+		}
+
+		n := pc.room(u, quantity-placed)
+		if n == 0 {
+			continue
+		}
+
+		count := decimal.NewFromInt(int64(n))
+		pc.units = append(pc.units, u)
+		pc.volume = pc.volume.Add(u.volume.Mul(count))
+		pc.weight = pc.weight.Add(u.weight.Mul(count))
+		placed += n
+	}
+
+	return placed
+}
+
+// room returns how many pieces of the unit, up to quantity, the parcel still has room for.
+func (pc *parcel) room(u unit, quantity int) int {
+	if !pc.box.holds(u) {
+		return 0
+	}
+
+	n := quantity
+	if u.volume.IsPositive() {
+		n = min(n, fit(pc.box.volume.Sub(pc.volume), u.volume))
+	}
+	if u.weight.IsPositive() {
+		n = min(n, fit(pc.box.maxWeight.Sub(pc.weight), u.weight))
+	}
+
+	return max(n, 0)
+}
+
+// fit returns how many times size fits in space.
+func fit(space, size decimal.Decimal) int {
+	if space.IsNegative() {
+		return 0
+	}
+
+	q, _ := space.QuoRem(size, 0)
+	return int(q.IntPart())
+}
+
+// largestBoxFor returns the largest box holding the unit, nil when none does.
+func (p *packer) largestBoxFor(u unit) *box {
+	for i := len(p.boxes) - 1; i >= 0; i-- {
+		b := &p.boxes[i]
+		if b.holds(u) && !u.weight.GreaterThan(b.maxWeight) {
+			return b
+		}
+	}
+
+	return nil
+}
+
+// smallestBoxFor returns the smallest box the contents of the parcel fit in, the box of the parcel at worst.
+func (p *packer) smallestBoxFor(pc *parcel) *box {
+	for i := range p.boxes {
+		b := &p.boxes[i]
+		if pc.volume.GreaterThan(b.volume) || pc.weight.GreaterThan(b.maxWeight) {
+			continue
+		}
+
+		fits := true
+		for _, u := range pc.units {
+			if !b.holds(u) {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return b
+		}
+	}
+
+	return pc.box
+}
+
+// holds reports whether the unit fits in the box in some orientation.
+func (b *box) holds(u unit) bool {
+	for i := range b.sides {
+		if u.sides[i].GreaterThan(b.sides[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// stack puts the units one on top of the other in a single package.
+func stack(units []unit) entities.Dimensions {
+	lengths := make([]decimal.Decimal, 0, len(units))
+	widths := make([]decimal.Decimal, 0, len(units))
+	heights := make([]decimal.Decimal, 0, len(units))
+	weights := make([]decimal.Decimal, 0, len(units))
+
+	for _, u := range units {
+		count := decimal.NewFromInt(int64(u.quantity))
+		lengths = append(lengths, u.dimensions.Length)
+		widths = append(widths, u.dimensions.Width)
+		heights = append(heights, u.dimensions.Height.Mul(count))
+		weights = append(weights, u.weight.Mul(count))
+	}
+
+	return entities.Dimensions{
+		Length: sharedDecimal.MaxDecimal(lengths...),
+		Width:  sharedDecimal.MaxDecimal(widths...),
+		Height: sharedDecimal.SumDecimals(heights...),
+		Weight: sharedDecimal.SumDecimals(weights...),
+	}
+}
+
+// toUnits returns the pieces of the items, items without dimensions nor weight aren't shipped.
+func toUnits(items []Item) []unit {
+	var units []unit
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			continue
+		}
+		if item.Length.IsZero() && item.Width.IsZero() && item.Height.IsZero() && item.Weight.IsZero() {
+			continue
+		}
+
+		u := unit{
+			dimensions: entities.Dimensions{
+				Length: item.Length,
+				Width:  item.Width,
+				Height: item.Height,
+				Weight: item.Weight,
+			},
+			sides:    sortedSides(item.Length, item.Width, item.Height),
+			volume:   item.Length.Mul(item.Width).Mul(item.Height),
+			weight:   item.Weight,
+			quantity: item.Quantity,
+		}
+		units = append(units, u)
+	}
+
+	return units
+}
+
+// sortedSides returns the sides longest first, so that a piece fits a box when each of its sides fits.
+func sortedSides(length, width, height decimal.Decimal) [3]decimal.Decimal {
+	sides := [3]decimal.Decimal{length, width, height}
+	sort.Slice(sides[:], func(i, j int) bool {
+		return sides[i].GreaterThan(sides[j])
+	})
+
+	return sides
+}
@@ -0,0 +1,148 @@
+package packaging
+
+import (
+	"testing"
+
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/entities"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func item(length, width, height, weight float64, quantity int) Item {
+	return Item{
+		Length:   decimal.NewFromFloat(length),
+		Width:    decimal.NewFromFloat(width),
+		Height:   decimal.NewFromFloat(height),
+		Weight:   decimal.NewFromFloat(weight),
+		Quantity: quantity,
+	}
+}
+
+func dimensions(length, width, height, weight float64) entities.Dimensions {
+	return entities.Dimensions{
+		Length: decimal.NewFromFloat(length),
+		Width:  decimal.NewFromFloat(width),
+		Height: decimal.NewFromFloat(height),
+		Weight: decimal.NewFromFloat(weight),
+	}
+}
+
+func assertPackages(t *testing.T, want, got []entities.Dimensions) {
+	t.Helper()
+
+	if !assert.Len(t, got, len(want)) {
+		return
+	}
+	for i := range want {
+		assert.True(t, want[i].Length.Equal(got[i].Length), "package %d length: want %s, got %s", i, want[i].Length, got[i].Length)
+		assert.True(t, want[i].Width.Equal(got[i].Width), "package %d width: want %s, got %s", i, want[i].Width, got[i].Width)
+		assert.True(t, want[i].Height.Equal(got[i].Height), "package %d height: want %s, got %s", i, want[i].Height, got[i].Height)
+		assert.True(t, want[i].Weight.Equal(got[i].Weight), "package %d weight: want %s, got %s", i, want[i].Weight, got[i].Weight)
+	}
+}
+
+func TestPack(t *testing.T) {
+	boxes := Config{
+		Boxes: []Box{
+			{Name: "large", Length: 20, Width: 20, Height: 20, MaxWeight: 50},
+			{Name: "small", Length: 10, Width: 10, Height: 10, MaxWeight: 20},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		config Config
+		items  []Item
+		want   []entities.Dimensions
+	}{
+		{
+			name:   "Items are stacked in a single package without boxes",
+			config: Config{},
+			items:  []Item{item(10, 8, 2, 1, 2), item(12, 6, 3, 2, 1)},
+			want:   []entities.Dimensions{dimensions(12, 8, 7, 4)},
+		},
+		{
+			name:   "Items fitting the small box are shipped in it",
+			config: boxes,
+			items:  []Item{item(5, 5, 5, 2, 4)},
+			want:   []entities.Dimensions{dimensions(10, 10, 10, 8)},
+		},
+		{
+			name:   "Items are split across boxes by weight",
+			config: boxes,
+			items:  []Item{item(5, 5, 5, 15, 4)},
+			want:   []entities.Dimensions{dimensions(20, 20, 20, 45), dimensions(10, 10, 10, 15)},
+		},
+		{
+			name:   "Items are split across boxes by volume",
+			config: boxes,
+			items:  []Item{item(10, 10, 10, 1, 9)},
+			want:   []entities.Dimensions{dimensions(20, 20, 20, 8), dimensions(10, 10, 10, 1)},
+		},
+		{
+			name:   "Item fits the box once turned",
+			config: boxes,
+			items:  []Item{item(4, 18, 4, 1, 1)},
+			want:   []entities.Dimensions{dimensions(20, 20, 20, 1)},
+		},
+		{
+			name:   "Item no box holds is shipped in its own packaging",
+			config: boxes,
+			items:  []Item{item(30, 10, 10, 5, 1), item(5, 5, 5, 1, 1), item(8, 8, 8, 60, 1)},
+			want:   []entities.Dimensions{dimensions(10, 10, 10, 1), dimensions(30, 10, 10, 5), dimensions(8, 8, 8, 60)},
+		},
+		{
+			name:   "Items without dimensions nor weight aren't shipped",
+			config: boxes,
+			items:  []Item{item(0, 0, 0, 0, 3)},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.config).Pack(tt.items)
+			assert.NoError(t, err)
+			assertPackages(t, tt.want, got)
+		})
+	}
+}
+
+func TestPack_Limits(t *testing.T) {
+	config := Config{
+		Boxes:       []Box{{Name: "large", Length: 20, Width: 20, Height: 20, MaxWeight: 50}},
+		MaxPackages: 3,
+	}
+
+	t.Run("Identical items are packed in batches", func(t *testing.T) {
+		got, err := New(Config{Boxes: config.Boxes, MaxPackages: 1000}).Pack([]Item{item(1, 1, 1, 0.001, 1_000_000)})
+		assert.NoError(t, err)
+		assert.Len(t, got, 125)
+		assert.True(t, decimal.NewFromInt(8000).Equal(got[0].Length.Mul(got[0].Width).Mul(got[0].Height)))
+		assert.True(t, decimal.NewFromInt(8).Equal(got[0].Weight))
+	})
+
+	t.Run("Items needing more packages than allowed", func(t *testing.T) {
+		_, err := New(config).Pack([]Item{item(10, 10, 10, 1, 1_000_000)})
+		assert.ErrorIs(t, err, ErrTooManyPackages)
+	})
+
+	t.Run("Items no box holds count toward the limit", func(t *testing.T) {
+		_, err := New(config).Pack([]Item{item(30, 30, 30, 1, 1_000_000)})
+		assert.ErrorIs(t, err, ErrTooManyPackages)
+	})
+
+	t.Run("Items are stacked in a single package without boxes", func(t *testing.T) {
+		got, err := New(Config{MaxPackages: 1}).Pack([]Item{item(2, 2, 1, 1, 1_000_000)})
+		assert.NoError(t, err)
+		assertPackages(t, []entities.Dimensions{dimensions(2, 2, 1_000_000, 1_000_000)}, got)
+	})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&Config{}).Validate())
+	assert.NoError(t, (&Config{Boxes: []Box{{Length: 10, Width: 10, Height: 10, MaxWeight: 20}}}).Validate())
+	assert.Error(t, (&Config{Boxes: []Box{{Length: 10, Width: 0, Height: 10, MaxWeight: 20}}}).Validate())
+	assert.Error(t, (&Config{Boxes: []Box{{Length: 10, Width: 10, Height: 10}}}).Validate())
+	assert.Error(t, (&Config{MaxPackages: -1}).Validate())
+}
@@ -23,7 +23,7 @@ func TestClient_GetRatesEstimate(t *testing.T) {
 	shipment := entities.Shipment{
 		Origin:      entities.Address{CountryCode: "US", PostalCode: "37086", City: "La Vergne", StateCode: "TN"},
 		Destination: entities.Address{CountryCode: "US", PostalCode: "12345", City: "City", StateCode: "State"},
-		Dimensions:  entities.Dimensions{Length: decimal.NewFromInt(10), Width: decimal.NewFromInt(10), Height: decimal.NewFromInt(10), Weight: decimal.NewFromInt(10)},
+		Packages:    []entities.Dimensions{{Length: decimal.NewFromInt(10), Width: decimal.NewFromInt(10), Height: decimal.NewFromInt(10), Weight: decimal.NewFromInt(10)}},
 	}
 
 	t.Run("Invalid delivery address postal code", func(t *testing.T) {
@@ -15,8 +15,12 @@ import (
 	moduleErrors "github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/providers/shipengine/errors"
 	shipengineErrors "github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/providers/shipengine/errors"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 )
 
+// maxConcurrentEstimates bounds the estimate requests sent at once for a multi-piece shipment
+const maxConcurrentEstimates = 4
+
 type Service interface {
 	ValidateAddress(ctx context.Context, address entities.Address) (*entities.Address, error)
 	GetShippingRates(ctx context.Context, shipment entities.Shipment) ([]entities.ShippingRate, error)
@@ -45,55 +49,28 @@ func (s *service) GetShippingRates(ctx context.Context, shipment entities.Shipme
 		return nil, moduleErrors.NewAPIError("SHIPENGINE_MISSING_CARRIERS")
 	}
 
-	req := shipengineEntities.ShippingRateRequest{
-		CarrierIds:        carriers,
-		FromCountryCode:   shipment.Origin.CountryCode,
-		FromPostalCode:    shipment.Origin.PostalCode,
-		FromCityLocality:  shipment.Origin.City,
-		FromStateProvince: shipment.Origin.StateCode,
-		ToCountryCode:     shipment.Destination.CountryCode,
-		ToPostalCode:      shipment.Destination.PostalCode,
-		ToCityLocality:    shipment.Destination.City,
-		ToStateProvince:   shipment.Destination.StateCode,
-		Weight: shipengineEntities.Weight{
-			Value: shipment.Dimensions.Weight.InexactFloat64(),
-			Unit:  "pound",
-		},
-		Dimensions: shipengineEntities.ObjectDimensions{
-			Length: shipment.Dimensions.Length.InexactFloat64(),
-			Width:  shipment.Dimensions.Width.InexactFloat64(),
-			Height: shipment.Dimensions.Height.InexactFloat64(),
-			Unit:   "inch",
-		},
+	// the estimates are for a single package, the packages of a multi-piece shipment are estimated
+	// concurrently and the shipment costs the sum of its packages
+	estimates := make([][]shipengineEntities.EstimateRatesResponse, len(shipment.Packages))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(maxConcurrentEstimates)
+	for i, pkg := range shipment.Packages {
+		group.Go(func() error {
+			estimate, err := s.estimateRates(groupCtx, carriers, shipment, pkg)
+			if err != nil {
+				return err
+			}
+			estimates[i] = estimate
+			return nil
+		})
 	}
 
-	var res []shipengineEntities.EstimateRatesResponse
-
-	err := s.httpClient.Post(ctx, s.getShipengineReqUrl("rates/estimate"), s.getShipengineApiHeaders(), req, &res)
-	if err != nil {
-		e := client.InvalidResponseError(err)
-		if e != nil {
-			var shipError shipengineErrors.ErrorObject
-			_ = json.Unmarshal([]byte(e.Description), &shipError)
-
-			if len(shipError.Errors) > 0 {
-				for _, v := range shipError.Errors {
-					if v.ErrorSource == "shipengine" {
-						switch v.Message {
-						case shipengineErrors.ErrInvalidToPostalCode, shipengineErrors.ErrEmptyToCountryCode:
-							return nil, moduleErrors.NewAPIError("SHIPENGINE_INVALID_POSTAL_CODE")
-						case shipengineErrors.ErrEmptyFromPostalCode:
-							return nil, moduleErrors.NewAPIError("SHIPENGINE_INVALID_ORIGIN_POSTAL_CODE")
-						case shipengineErrors.ErrEmptyCarrierId, shipengineErrors.ErrEmptyCarrierIds:
-							return nil, moduleErrors.NewAPIError("SHIPENGINE_MISSING_CARRIERS")
-						}
-					}
-				}
-			}
-		}
-		return nil, moduleErrors.NewAPIError("SHIPENGINE_ERROR_GETTING_SHIPPING_RATES")
+	if err := group.Wait(); err != nil {
+		return nil, err
 	}
 
+	res := combineEstimates(estimates)
+
 	// Check for any error messages in the response from individual carriers
 	// Note: Shipengine doesn't have a consistent place to put error messages in the response
 	// TODO commenting this for now, even if one the carriers has an error, we still want to show the rates from other carriers
@@ -156,6 +133,126 @@ func (s *service) GetShippingRates(ctx context.Context, shipment entities.Shipme
 	return shippingRates, nil
 }
 
+// estimateRates returns the estimated rates of a single package
+func (s *service) estimateRates(ctx context.Context, carriers []string, shipment entities.Shipment, pkg entities.Dimensions) ([]shipengineEntities.EstimateRatesResponse, error) {
+	req := shipengineEntities.ShippingRateRequest{
+		CarrierIds:        carriers,
+		FromCountryCode:   shipment.Origin.CountryCode,
+		FromPostalCode:    shipment.Origin.PostalCode,
+		FromCityLocality:  shipment.Origin.City,
+		FromStateProvince: shipment.Origin.StateCode,
+		ToCountryCode:     shipment.Destination.CountryCode,
+		ToPostalCode:      shipment.Destination.PostalCode,
+		ToCityLocality:    shipment.Destination.City,
+		ToStateProvince:   shipment.Destination.StateCode,
+		Weight: shipengineEntities.Weight{
+			Value: pkg.Weight.InexactFloat64(),
+			Unit:  "pound",
+		},
+		Dimensions: shipengineEntities.ObjectDimensions{
+			Length: pkg.Length.InexactFloat64(),
+			Width:  pkg.Width.InexactFloat64(),
+			Height: pkg.Height.InexactFloat64(),
+			Unit:   "inch",
+		},
+	}
+
+	var res []shipengineEntities.EstimateRatesResponse
+
+	err := s.httpClient.Post(ctx, s.getShipengineReqUrl("rates/estimate"), s.getShipengineApiHeaders(), req, &res)
+	if err != nil {
+		e := client.InvalidResponseError(err)
+		if e != nil {
+			var shipError shipengineErrors.ErrorObject
+			_ = json.Unmarshal([]byte(e.Description), &shipError)
+
+			if len(shipError.Errors) > 0 {
+				for _, v := range shipError.Errors {
+					if v.ErrorSource == "shipengine" {
+						switch v.Message {
+						case shipengineErrors.ErrInvalidToPostalCode, shipengineErrors.ErrEmptyToCountryCode:
+							return nil, moduleErrors.NewAPIError("SHIPENGINE_INVALID_POSTAL_CODE")
+						case shipengineErrors.ErrEmptyFromPostalCode:
+							return nil, moduleErrors.NewAPIError("SHIPENGINE_INVALID_ORIGIN_POSTAL_CODE")
+						case shipengineErrors.ErrEmptyCarrierId, shipengineErrors.ErrEmptyCarrierIds:
+							return nil, moduleErrors.NewAPIError("SHIPENGINE_MISSING_CARRIERS")
+						}
+					}
+				}
+			}
+		}
+		return nil, moduleErrors.NewAPIError("SHIPENGINE_ERROR_GETTING_SHIPPING_RATES")
+	}
+
+	return res, nil
+}
+
+// combineEstimates sums the estimates of the packages per carrier service. A service is kept when it quoted every
+// package and it's delivered when its last package is. Estimates without a price or a delivery date are left out.
+func combineEstimates(estimates [][]shipengineEntities.EstimateRatesResponse) []shipengineEntities.EstimateRatesResponse {
+	if len(estimates) == 1 {
+		var res []shipengineEntities.EstimateRatesResponse
+		for _, estimate := range estimates[0] {
+			if quoted(estimate) {
+				res = append(res, estimate)
+			}
+		}
+		return res
+	}
+
+	type quote struct {
+		estimate shipengineEntities.EstimateRatesResponse
+		packages int
+	}
+
+	var keys []string
+	quotes := make(map[string]*quote)
+
+	for _, packageEstimates := range estimates {
+		// a service quoting the package twice is counted once
+		seen := make(map[string]bool)
+		for _, estimate := range packageEstimates {
+			if !quoted(estimate) {
+				continue
+			}
+
+			key := estimate.CarrierID + "|" + estimate.ServiceCode
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			q, ok := quotes[key]
+			if !ok {
+				keys = append(keys, key)
+				quotes[key] = &quote{estimate: estimate, packages: 1}
+				continue
+			}
+
+			q.estimate.ShippingAmount.Amount = decimal.NewFromFloat(q.estimate.ShippingAmount.Amount).
+				Add(decimal.NewFromFloat(estimate.ShippingAmount.Amount)).InexactFloat64()
+			if estimate.EstimatedDeliveryDate.Time.After(q.estimate.EstimatedDeliveryDate.Time) {
+				q.estimate.EstimatedDeliveryDate = estimate.EstimatedDeliveryDate
+			}
+			q.packages++
+		}
+	}
+
+	var res []shipengineEntities.EstimateRatesResponse
+	for _, key := range keys {
+		if quotes[key].packages == len(estimates) {
+			res = append(res, quotes[key].estimate)
+		}
+	}
+
+	return res
+}
+
+// quoted reports whether the carrier service priced the package and told when it's delivered.
+func quoted(estimate shipengineEntities.EstimateRatesResponse) bool {
+	return estimate.EstimatedDeliveryDate.Valid && estimate.ShippingAmount.Amount > 0
+}
+
 // ValidateAddress return validation result for the given shipping address
 // https://shipengine.github.io/shipengine-openapi/#operation/estimate_rates
 func (s *service) ValidateAddress(ctx context.Context, address entities.Address) (*entities.Address, error) {
@@ -169,11 +266,13 @@ func (s *service) ValidateAddress(ctx context.Context, address entities.Address)
 				CountryCode: "US",
 			},
 			Destination: address,
-			Dimensions: entities.Dimensions{
-				Length: decimal.NewFromFloat(1),
-				Width:  decimal.NewFromFloat(1),
-				Height: decimal.NewFromFloat(1),
-				Weight: decimal.NewFromFloat(1),
+			Packages: []entities.Dimensions{
+				{
+					Length: decimal.NewFromFloat(1),
+					Width:  decimal.NewFromFloat(1),
+					Height: decimal.NewFromFloat(1),
+					Weight: decimal.NewFromFloat(1),
+				},
 			},
 		})
 	return nil, err
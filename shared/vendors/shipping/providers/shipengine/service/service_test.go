@@ -1,5 +1,21 @@
 package service
 
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/nurdsoft/nurd-commerce-core/internal/transport/http/client"
+	"github.com/nurdsoft/nurd-commerce-core/shared/nullable"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/entities"
+	"github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/providers/shipengine/config"
+	shipengineEntities "github.com/nurdsoft/nurd-commerce-core/shared/vendors/shipping/providers/shipengine/entities"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
 // TODO commenting this for now, need to go back and refine unit tests
 /*
 func TestGetRatesEstimate(t *testing.T) {
@@ -115,3 +131,111 @@ func TestGetRatesEstimate(t *testing.T) {
 }
 
 */
+
+func TestCombineEstimates(t *testing.T) {
+	monday := time.Date(2025, 9, 22, 0, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+
+	estimate := func(carrierID, serviceCode string, amount float64, deliveryDate time.Time) shipengineEntities.EstimateRatesResponse {
+		return shipengineEntities.EstimateRatesResponse{
+			CarrierID:             carrierID,
+			ServiceCode:           serviceCode,
+			ShippingAmount:        shipengineEntities.ShippingAmount{Amount: amount, Currency: "USD"},
+			EstimatedDeliveryDate: nullable.NewNullTime(deliveryDate),
+		}
+	}
+
+	t.Run("Single package estimates are kept as they are", func(t *testing.T) {
+		estimates := []shipengineEntities.EstimateRatesResponse{estimate("se-1", "ups_ground", 10.5, monday)}
+
+		assert.Equal(t, estimates, combineEstimates([][]shipengineEntities.EstimateRatesResponse{estimates}))
+	})
+
+	t.Run("Single package estimates without a price or a delivery date are left out", func(t *testing.T) {
+		undated := estimate("se-2", "usps_priority_mail", 8, monday)
+		undated.EstimatedDeliveryDate = nullable.NullTime{}
+
+		res := combineEstimates([][]shipengineEntities.EstimateRatesResponse{
+			{estimate("se-1", "ups_ground", 10.5, monday), estimate("se-1", "ups_next_day_air", 0, monday), undated},
+		})
+
+		assert.Equal(t, []shipengineEntities.EstimateRatesResponse{estimate("se-1", "ups_ground", 10.5, monday)}, res)
+	})
+
+	t.Run("Services quoting every package are summed", func(t *testing.T) {
+		res := combineEstimates([][]shipengineEntities.EstimateRatesResponse{
+			{estimate("se-1", "ups_ground", 10.1, monday), estimate("se-1", "ups_next_day_air", 30, monday), estimate("se-2", "usps_priority_mail", 8, monday)},
+			{estimate("se-1", "ups_ground", 12.2, tuesday), estimate("se-2", "usps_priority_mail", 9, monday)},
+		})
+
+		assert.Len(t, res, 2)
+		assert.Equal(t, "ups_ground", res[0].ServiceCode)
+		assert.Equal(t, 22.3, res[0].ShippingAmount.Amount)
+		assert.Equal(t, tuesday, res[0].EstimatedDeliveryDate.Time)
+		assert.Equal(t, "usps_priority_mail", res[1].ServiceCode)
+		assert.Equal(t, float64(17), res[1].ShippingAmount.Amount)
+	})
+}
+
+func TestGetShippingRates_MultiplePackages(t *testing.T) {
+	monday := time.Date(2025, 9, 22, 0, 0, 0, 0, time.UTC)
+	shipment := entities.Shipment{
+		Origin:      entities.Address{PostalCode: "37086", CountryCode: "US"},
+		Destination: entities.Address{PostalCode: "10001", CountryCode: "US"},
+		Packages: []entities.Dimensions{
+			{Length: decimal.NewFromInt(10), Width: decimal.NewFromInt(10), Height: decimal.NewFromInt(10), Weight: decimal.NewFromInt(2)},
+			{Length: decimal.NewFromInt(10), Width: decimal.NewFromInt(10), Height: decimal.NewFromInt(10), Weight: decimal.NewFromInt(5)},
+		},
+	}
+
+	setup := func(t *testing.T) (*service, *client.MockClient) {
+		ctrl := gomock.NewController(t)
+		mockClient := client.NewMockClient(ctrl)
+		return &service{
+			httpClient: mockClient,
+			config:     config.Config{CarrierIds: "se-1"},
+			logger:     zap.NewExample().Sugar(),
+		}, mockClient
+	}
+
+	t.Run("Packages are estimated separately and summed", func(t *testing.T) {
+		svc, mockClient := setup(t)
+
+		mockClient.EXPECT().
+			Post(gomock.Any(), "/v1/rates/estimate", gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, _ map[string]string, in, out interface{}) error {
+				// the price of the package follows its weight
+				req := in.(shipengineEntities.ShippingRateRequest)
+				*out.(*[]shipengineEntities.EstimateRatesResponse) = []shipengineEntities.EstimateRatesResponse{{
+					CarrierID:             "se-1",
+					ServiceCode:           "ups_ground",
+					ShippingAmount:        shipengineEntities.ShippingAmount{Amount: req.Weight.Value * 2, Currency: "USD"},
+					EstimatedDeliveryDate: nullable.NewNullTime(monday),
+				}}
+				return nil
+			}).
+			Times(2)
+
+		rates, err := svc.GetShippingRates(context.Background(), shipment)
+
+		assert.NoError(t, err)
+		assert.Len(t, rates, 1)
+		assert.Equal(t, "14", rates[0].Amount.String())
+		assert.Equal(t, "ups_ground", rates[0].ServiceCode)
+	})
+
+	t.Run("A package that can't be estimated fails the shipment", func(t *testing.T) {
+		svc, mockClient := setup(t)
+
+		mockClient.EXPECT().
+			Post(gomock.Any(), "/v1/rates/estimate", gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(assert.AnError).
+			MinTimes(1).
+			MaxTimes(2)
+
+		rates, err := svc.GetShippingRates(context.Background(), shipment)
+
+		assert.Nil(t, rates)
+		assert.Error(t, err)
+	})
+}
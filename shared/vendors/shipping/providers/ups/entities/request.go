@@ -39,10 +39,10 @@ type TransactionReference struct {
 }
 
 type Shipment struct {
-	Shipper     Party   `json:"Shipper"`
-	ShipTo      Party   `json:"ShipTo"`
-	NumOfPieces string  `json:"NumOfPieces"`
-	Package     Package `json:"Package"`
+	Shipper     Party     `json:"Shipper"`
+	ShipTo      Party     `json:"ShipTo"`
+	NumOfPieces string    `json:"NumOfPieces"`
+	Package     []Package `json:"Package"`
 }
 
 type Party struct {
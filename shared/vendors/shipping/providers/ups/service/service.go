@@ -98,29 +98,8 @@ func (s *service) GetShippingRates(ctx context.Context, shipment entities.Shipme
 						CountryCode:       shipment.Destination.CountryCode,
 					},
 				},
-				NumOfPieces: "1", // Assuming only one package will be shipped
-				Package: upsEntities.Package{
-					PackagingType: upsEntities.CodeDescription{
-						Code:        "02",
-						Description: "Package",
-					},
-					Dimensions: upsEntities.Dimensions{
-						UnitOfMeasurement: upsEntities.CodeDescription{
-							Code:        "IN",
-							Description: "Inches",
-						},
-						Length: shipment.Dimensions.Length.StringFixed(2),
-						Width:  shipment.Dimensions.Width.StringFixed(2),
-						Height: shipment.Dimensions.Height.StringFixed(2),
-					},
-					PackageWeight: upsEntities.PackageWeight{
-						UnitOfMeasurement: upsEntities.CodeDescription{
-							Code:        "LBS",
-							Description: "Pounds",
-						},
-						Weight: shipment.Dimensions.Weight.StringFixed(2),
-					},
-				},
+				NumOfPieces: strconv.Itoa(len(shipment.Packages)),
+				Package:     packages(shipment.Packages),
 			},
 		},
 	}
@@ -178,6 +157,37 @@ func (s *service) GetShippingRates(ctx context.Context, shipment entities.Shipme
 	}
 }
 
+// packages describes each package of a multi-piece shipment
+func packages(dimensions []entities.Dimensions) []upsEntities.Package {
+	packages := make([]upsEntities.Package, 0, len(dimensions))
+	for _, d := range dimensions {
+		packages = append(packages, upsEntities.Package{
+			PackagingType: upsEntities.CodeDescription{
+				Code:        "02",
+				Description: "Package",
+			},
+			Dimensions: upsEntities.Dimensions{
+				UnitOfMeasurement: upsEntities.CodeDescription{
+					Code:        "IN",
+					Description: "Inches",
+				},
+				Length: d.Length.StringFixed(2),
+				Width:  d.Width.StringFixed(2),
+				Height: d.Height.StringFixed(2),
+			},
+			PackageWeight: upsEntities.PackageWeight{
+				UnitOfMeasurement: upsEntities.CodeDescription{
+					Code:        "LBS",
+					Description: "Pounds",
+				},
+				Weight: d.Weight.StringFixed(2),
+			},
+		})
+	}
+
+	return packages
+}
+
 // ValidateAddress return validation result for the given shipping address
 // https://developer.ups.com/tag/Address-Validation?loc=en_US&tag=Rating#operation/AddressValidation
 func (s *service) ValidateAddress(ctx context.Context, address entities.Address) (*entities.Address, error) {
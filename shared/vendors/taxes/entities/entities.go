@@ -17,6 +17,8 @@ type TaxItem struct {
 	Quantity  int
 	Reference string
 	TaxCode   string
+	// Discount taken off the whole line, not off each unit
+	Discount decimal.Decimal
 }
 
 type Address struct {
@@ -65,8 +65,9 @@ func mapStripeTaxItems(items []entities.TaxItem) []stripeEntities.TaxItem {
 	stripeItems := make([]stripeEntities.TaxItem, len(items))
 	for i, item := range items {
 		stripeItems[i] = stripeEntities.TaxItem{
-			// Stripe requires to provide the amount of the product with the no.of pieces being bought
-			Price:     item.Price.Mul(decimal.NewFromInt(int64(item.Quantity))),
+			// Stripe requires to provide the amount of the product with the no.of pieces being bought,
+			// less the discount on the line
+			Price:     item.Price.Mul(decimal.NewFromInt(int64(item.Quantity))).Sub(item.Discount),
 			Quantity:  item.Quantity,
 			Reference: item.Reference,
 			TaxCode:   item.TaxCode,
@@ -36,6 +36,7 @@ func (c *localClient) CalculateTax(ctx context.Context, req *entities.CalculateT
 			Quantity:       item.Quantity,
 			ProductTaxCode: item.TaxCode,
 			UnitPrice:      item.Price.InexactFloat64(),
+			Discount:       item.Discount.InexactFloat64(),
 		}
 	}
 
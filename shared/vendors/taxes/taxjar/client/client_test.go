@@ -47,6 +47,7 @@ func TestCalculateTax(t *testing.T) {
 					Quantity:  2,
 					Reference: "sku-2",
 					TaxCode:   "20010",
+					Discount:  decimal.NewFromInt(5),
 				},
 			},
 		}
@@ -76,6 +77,7 @@ func TestCalculateTax(t *testing.T) {
 				assert.Equal(t, 2, params.LineItems[1].Quantity)
 				assert.Equal(t, "20010", params.LineItems[1].ProductTaxCode)
 				assert.Equal(t, 34.00, params.LineItems[1].UnitPrice)
+				assert.Equal(t, 5.00, params.LineItems[1].Discount)
 
 				return &taxjar.TaxForOrderResponse{
 					Tax: taxjar.Tax{